@@ -8,28 +8,40 @@ import (
 	"NYCU-SDC/core-system-backend/internal/auth/resolver/sectionresolver"
 	"NYCU-SDC/core-system-backend/internal/auth/resolver/slugresolver"
 	"NYCU-SDC/core-system-backend/internal/auth/resolver/unitresolver"
+	"NYCU-SDC/core-system-backend/internal/auth/resolver/webhookresolver"
 	"NYCU-SDC/core-system-backend/internal/config"
 	"NYCU-SDC/core-system-backend/internal/cors"
 	"NYCU-SDC/core-system-backend/internal/distribute"
 	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/file/blobstore"
+	"NYCU-SDC/core-system-backend/internal/file/scanner"
 	"NYCU-SDC/core-system-backend/internal/form"
 	"NYCU-SDC/core-system-backend/internal/form/answer"
+	"NYCU-SDC/core-system-backend/internal/form/exportjob"
 	"NYCU-SDC/core-system-backend/internal/form/highlight"
 	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/reminder"
 	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/sheetsync"
+	"NYCU-SDC/core-system-backend/internal/form/sheetsync/sheets"
 	"NYCU-SDC/core-system-backend/internal/form/submit"
 	"NYCU-SDC/core-system-backend/internal/form/view"
 	"NYCU-SDC/core-system-backend/internal/form/workflow"
 	"NYCU-SDC/core-system-backend/internal/inbox"
 	"NYCU-SDC/core-system-backend/internal/jwt"
 	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/notify"
+	"NYCU-SDC/core-system-backend/internal/notify/mailer"
+	"NYCU-SDC/core-system-backend/internal/outbox"
 	"NYCU-SDC/core-system-backend/internal/publish"
+	"NYCU-SDC/core-system-backend/internal/scheduler"
 	"NYCU-SDC/core-system-backend/internal/setup"
 	"NYCU-SDC/core-system-backend/internal/tenant"
 	"NYCU-SDC/core-system-backend/internal/unit"
 
 	"NYCU-SDC/core-system-backend/internal/trace"
 	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/internal/webhook"
 	"context"
 	"errors"
 	"fmt"
@@ -157,10 +169,34 @@ func main() {
 	tenantService := tenant.NewService(logger, dbPool)
 	unitService := unit.NewService(logger, dbPool, tenantService)
 
+	blobStore, err := blobstore.New(cfg.BlobStore())
+	if err != nil {
+		logger.Fatal("Failed to initialize file storage", zap.Error(err))
+	}
+
+	fileScanner, err := scanner.New(cfg.Scanner())
+	if err != nil {
+		logger.Fatal("Failed to initialize file scanner", zap.Error(err))
+	}
+
+	mailSender, err := mailer.New(cfg.Mailer())
+	if err != nil {
+		logger.Fatal("Failed to initialize mailer", zap.Error(err))
+	}
+
+	// Responses are synced to the Google Sheets linked to forms only when a service account is configured
+	var sheetsClient sheetsync.Client
+	if cfg.GoogleSheetsKeyFile != "" {
+		sheetsClient, err = sheets.NewFromCredentialsFile(context.Background(), cfg.GoogleSheetsKeyFile)
+		if err != nil {
+			logger.Fatal("Failed to initialize Google Sheets client", zap.Error(err))
+		}
+	}
+
 	//Resource handler wiring for generic file deletion
 	answerQueries := answer.New(dbPool)
-	answerFileHandler := answer.NewFileResourceHandler(logger, answerQueries)
-	fileService := file.NewService(logger, dbPool, answerFileHandler)
+	answerFileHandler := answer.NewFileResourceHandler(logger, answerQueries, unitService)
+	fileService := file.NewService(logger, dbPool, blobStore, fileScanner, answerFileHandler)
 
 	setupCfg := config.Setup{}
 	err = setupCfg.LoadSetupConfig(logger, cfg.SetupPath, cfg.SetupData)
@@ -177,10 +213,28 @@ func main() {
 	workflowService := workflow.NewService(logger, dbPool, formService, questionService)
 	answerService := answer.NewService(logger, dbPool, questionService, fileService, workflowService)
 	inboxService := inbox.NewService(logger, dbPool)
-	responseService := response.NewService(logger, dbPool, answerService, questionService, workflowService, formService, userService)
+	viewService := view.NewService(logger, dbPool, questionService)
+	webhookService := webhook.NewService(logger, dbPool, cfg.WebhookAllowPrivate)
+	outboxService := outbox.NewService(logger, dbPool)
+	notifyService := notify.NewService(logger, dbPool, markdownService, mailSender, cfg.MailLocation, cfg.DigestInterval)
+	outboxService.Subscribe(outbox.TypeResponseSubmitted, "notify.submission_receipt", notifyService.HandleResponseSubmitted)
+	for _, eventType := range webhook.Events {
+		outboxService.Subscribe(string(eventType), "webhook.delivery", webhookService.HandleEvent)
+	}
+	responseService := response.NewService(logger, dbPool, answerService, questionService, workflowService, formService, userService, fileService, outboxService)
 	highlightService := highlight.NewService(logger, dbPool, formService)
-	submitService := submit.NewService(logger, formService, questionService, responseService, answerService)
-	publishService := publish.NewService(logger, distributeService, formService, inboxService, workflowService)
+	sheetSyncService := sheetsync.NewService(logger, dbPool, sheetsClient, formService, responseService, outboxService)
+	if sheetsClient != nil {
+		for _, eventType := range []string{outbox.TypeResponseSubmitted, outbox.TypeResponseCancelled, outbox.TypeResponseDeleted} {
+			outboxService.Subscribe(eventType, "sheetsync.response", sheetSyncService.HandleResponseEvent)
+		}
+		outboxService.Subscribe(outbox.TypeFormSheetResyncRequested, "sheetsync.resync", sheetSyncService.HandleResyncRequested)
+	}
+	submitService := submit.NewService(logger, dbPool, formService, questionService, responseService, answerService, outboxService)
+	publishService := publish.NewService(logger, dbPool, distributeService, formService, inboxService, workflowService, outboxService)
+	exportJobService := exportjob.NewService(logger, dbPool, cfg.ExportWorkers, responseService, fileService, formService, inboxService)
+	reminderService := reminder.NewService(logger, dbPool, inboxService, notifyService)
+	schedulerService := scheduler.NewService(logger, dbPool, cfg.SchedulerInterval, formService, publishService, outboxService, reminderService)
 
 	setupService := setup.NewService(logger, setupCfg, unitService, userService)
 	err = setupService.Setup(context.Background())
@@ -193,19 +247,22 @@ func main() {
 
 	authHandler := auth.NewHandler(logger, validator, problemWriter, userService, jwtService, jwtService, cfg.BaseURL, cfg.OauthProxyBaseURL, Environment, cfg.Dev, cfg.AccessTokenExpiration, cfg.RefreshTokenExpiration, cfg.GoogleOauth, cfg.NYCUOauth)
 	userHandler := user.NewHandler(logger, validator, problemWriter, userService)
-	formHandler := form.NewHandler(logger, validator, problemWriter, formService, tenantService, questionService, fileService, markdownService)
+	formHandler := form.NewHandler(logger, validator, problemWriter, formService, tenantService, questionService, fileService, markdownService, publishService)
 	questionHandler := question.NewHandler(logger, validator, problemWriter, questionService)
 	answerHandler := answer.NewHandler(logger, validator, problemWriter, answerService, questionService, responseService, jwtService, cfg.GoogleOauth.ClientID, cfg.GoogleOauth.ClientSecret, cfg.GitHubOauth.ClientID, cfg.GitHubOauth.ClientSecret, cfg.BaseURL, cfg.OauthProxyBaseURL)
 	unitHandler := unit.NewHandler(logger, validator, problemWriter, unitService, submitService, tenantService, userService)
-	responseHandler := response.NewHandler(logger, validator, problemWriter, responseService, questionService)
+	responseHandler := response.NewHandler(logger, validator, problemWriter, responseService, questionService, viewService)
+	exportJobHandler := exportjob.NewHandler(logger, validator, problemWriter, exportJobService)
+	sheetSyncHandler := sheetsync.NewHandler(logger, validator, problemWriter, sheetSyncService)
 	highlightHandler := highlight.NewHandler(logger, validator, problemWriter, highlightService)
 	submitHandler := submit.NewHandler(logger, validator, problemWriter, submitService, responseService)
 	publishHandler := publish.NewHandler(logger, validator, problemWriter, publishService)
+	inboxHandler := inbox.NewHandler(logger, validator, problemWriter, inboxService, formService, unitService)
 	tenantHandler := tenant.NewHandler(logger, validator, problemWriter, tenantService)
 	workflowHandler := workflow.NewHandler(logger, validator, problemWriter, workflowService)
-	fileHandler := file.NewHandler(logger, validator, problemWriter, fileService)
-	viewService := view.NewService(logger, dbPool)
+	fileHandler := file.NewHandler(logger, validator, problemWriter, fileService, file.NewURLSigner(cfg.Secret, cfg.FileURLExpiration))
 	viewHandler := view.NewHandler(logger, validator, problemWriter, viewService)
+	webhookHandler := webhook.NewHandler(logger, validator, problemWriter, webhookService, tenantService)
 
 	// ============================================
 	// Middleware
@@ -237,6 +294,7 @@ func main() {
 	formResolver := formresolver.NewPathResolver(formService)
 	sectionResolver := sectionresolver.NewPathResolver(formService)
 	responseResolver := responseresolver.NewPathResolver(responseService)
+	webhookResolver := webhookresolver.NewPathResolver(webhookService)
 
 	// Permission Middleware
 	globalRole := authmiddleware.NewGlobalRoleMiddleware(logger, problemWriter)
@@ -390,6 +448,10 @@ func main() {
 	mux.Handle("GET /api/forms/{formId}/responses/me", authMiddleware.HandlerFunc(responseHandler.ListMe))
 	mux.Handle("POST /api/forms/{formId}/responses/export/preview", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(responseHandler.ExportPreview))
 	mux.Handle("POST /api/forms/{formId}/responses/export/download", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(responseHandler.ExportDownload))
+	mux.Handle("GET /api/forms/{formId}/responses/files", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(responseHandler.DownloadFiles))
+	mux.Handle("POST /api/forms/{formId}/responses/export/jobs", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(exportJobHandler.Create))
+	mux.Handle("GET /api/forms/{formId}/responses/export/jobs/{jobId}", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(exportJobHandler.Get))
+	mux.Handle("POST /api/forms/{formId}/responses/migrate-workflow", authMiddleware.Append(unitRole.Require(auth.RoleAdmin, formResolver)).HandlerFunc(responseHandler.MigrateWorkflow))
 	mux.Handle("GET /api/forms/{formId}/responses/{responseId}", authMiddleware.HandlerFunc(responseHandler.Get))
 	mux.Handle("POST /api/forms/{formId}/responses", authMiddleware.Append(availableByForm).HandlerFunc(responseHandler.Create))
 	mux.Handle("DELETE /api/forms/{formId}/responses/{responseId}", authMiddleware.Append(formOwner).HandlerFunc(responseHandler.Delete))
@@ -398,6 +460,11 @@ func main() {
 	mux.Handle("POST /api/responses/{responseId}/submit", authMiddleware.Append(availableByResponse).HandlerFunc(submitHandler.SubmitHandler))
 	mux.Handle("POST /api/responses/{responseId}/cancel", authMiddleware.Append(availableByResponse).HandlerFunc(responseHandler.Cancel))
 
+	// Google Sheet Sync Management
+	// ----------------------
+	mux.Handle("GET /api/forms/{formId}/sheet-sync", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(sheetSyncHandler.Get))
+	mux.Handle("POST /api/forms/{formId}/sheet-sync/resync", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(sheetSyncHandler.Resync))
+
 	// Answer Management
 	// ----------------------
 	mux.Handle("GET /api/responses/{responseId}/questions/{questionId}", authMiddleware.HandlerFunc(answerHandler.GetQuestionResponse))
@@ -412,6 +479,11 @@ func main() {
 	mux.Handle("POST /api/forms/{formId}/workflow/nodes", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).Append(availableByForm).HandlerFunc(workflowHandler.CreateNodeHandler))
 	mux.Handle("PUT /api/forms/{formId}/workflow", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).Append(availableByForm).HandlerFunc(workflowHandler.UpdateHandler))
 	mux.Handle("DELETE /api/forms/{formId}/workflow/nodes/{nodeId}", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).Append(availableByForm).HandlerFunc(workflowHandler.DeleteNodeHandler))
+	mux.Handle("POST /api/forms/{formId}/workflow/simulate", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(workflowHandler.SimulateHandler))
+	mux.Handle("GET /api/forms/{formId}/workflow/versions", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(workflowHandler.ListVersionsHandler))
+	mux.Handle("GET /api/forms/{formId}/workflow/versions/{versionId}", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(workflowHandler.GetVersionHandler))
+	mux.Handle("POST /api/forms/{formId}/workflow/versions/{versionId}/rollback", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).Append(availableByForm).HandlerFunc(workflowHandler.RollbackHandler))
+	mux.Handle("GET /api/forms/{formId}/workflow/diff", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(workflowHandler.DiffHandler))
 
 	// View Management
 	// ----------------------
@@ -423,6 +495,30 @@ func main() {
 	mux.Handle("POST /api/forms/{formId}/views/{viewId}/unlock", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(viewHandler.Unlock))
 	mux.Handle("POST /api/forms/{formId}/views/{viewId}/duplicate", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(viewHandler.Duplicate))
 	mux.Handle("DELETE /api/forms/{formId}/views/{viewId}", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(viewHandler.Delete))
+	mux.Handle("GET /api/forms/{formId}/views/{viewId}/responses", authMiddleware.Append(unitRole.Require(auth.RoleMember, formResolver)).HandlerFunc(viewHandler.ListResponses))
+
+	// Webhook Management
+	// ----------------------
+	mux.Handle("GET /api/orgs/{slug}/webhooks", tenantAuthMiddleware.Append(unitRole.Require(auth.RoleAdmin, slugResolver)).HandlerFunc(webhookHandler.ListByOrg))
+	mux.Handle("POST /api/orgs/{slug}/webhooks", tenantAuthMiddleware.Append(unitRole.Require(auth.RoleAdmin, slugResolver)).HandlerFunc(webhookHandler.CreateForOrg))
+	mux.Handle("GET /api/forms/{formId}/webhooks", authMiddleware.Append(unitAdmin).HandlerFunc(webhookHandler.ListByForm))
+	mux.Handle("POST /api/forms/{formId}/webhooks", authMiddleware.Append(unitAdmin).HandlerFunc(webhookHandler.CreateForForm))
+	mux.Handle("GET /api/webhooks/{webhookId}", authMiddleware.Append(unitRole.Require(auth.RoleAdmin, webhookResolver)).HandlerFunc(webhookHandler.Get))
+	mux.Handle("PUT /api/webhooks/{webhookId}", authMiddleware.Append(unitRole.Require(auth.RoleAdmin, webhookResolver)).HandlerFunc(webhookHandler.Update))
+	mux.Handle("DELETE /api/webhooks/{webhookId}", authMiddleware.Append(unitRole.Require(auth.RoleAdmin, webhookResolver)).HandlerFunc(webhookHandler.Delete))
+	mux.Handle("GET /api/webhooks/{webhookId}/deliveries", authMiddleware.Append(unitRole.Require(auth.RoleAdmin, webhookResolver)).HandlerFunc(webhookHandler.ListDeliveries))
+	mux.Handle("POST /api/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver", authMiddleware.Append(unitRole.Require(auth.RoleAdmin, webhookResolver)).HandlerFunc(webhookHandler.Redeliver))
+
+	// ============================================
+	// Inbox routes
+	// ============================================
+
+	// Inbox Messages
+	// ----------------------
+	mux.Handle("GET /api/inbox", authMiddleware.HandlerFunc(inboxHandler.ListHandler))
+	mux.Handle("GET /api/inbox/count", authMiddleware.HandlerFunc(inboxHandler.CountHandler))
+	mux.Handle("GET /api/inbox/{id}", authMiddleware.HandlerFunc(inboxHandler.GetHandler))
+	mux.Handle("PATCH /api/inbox/{id}", authMiddleware.HandlerFunc(inboxHandler.UpdateHandler))
 
 	// ============================================
 	// File routes
@@ -430,8 +526,9 @@ func main() {
 
 	// File Management
 	// ----------------------
-	mux.Handle("GET /api/files/{id}", basicMiddleware.HandlerFunc(fileHandler.Download))
+	mux.Handle("GET /api/files/{id}", basicMiddleware.Append(jwtMiddleware.OptionalAuthMiddleware).HandlerFunc(fileHandler.Download))
 	mux.Handle("GET /api/files/{id}/info", authMiddleware.HandlerFunc(fileHandler.Get))
+	mux.Handle("POST /api/files/{id}/signed-url", authMiddleware.HandlerFunc(fileHandler.CreateSignedURL))
 
 	// Todo: Admin only endpoint
 	mux.Handle("GET /api/files", authMiddleware.HandlerFunc(fileHandler.List))
@@ -452,6 +549,27 @@ func main() {
 		Handler: entrypoint,
 	}
 
+	// publish and close forms based on publish_time and deadline
+	go schedulerService.Run(ctx)
+
+	// build queued response exports, resuming the ones interrupted by a previous shutdown
+	go exportJobService.Run(ctx)
+
+	// deliver queued webhook events, retrying failed deliveries with backoff
+	go webhookService.Run(ctx)
+
+	// dispatch outbox events to their subscribers, retrying failed dispatches with backoff
+	go outboxService.Run(ctx)
+
+	// send queued emails, retrying failed attempts with backoff
+	go notifyService.Run(ctx)
+
+	// email form creators a digest of the responses their forms received
+	go notifyService.RunDigests(ctx, cfg.SchedulerInterval)
+
+	// rescan uploaded files whose scan is pending or failed, releasing the ones found clean
+	go fileService.RunRescans(ctx, cfg.SchedulerInterval)
+
 	go func() {
 		logger.Info("Starting listening request", zap.String("host", cfg.Host), zap.String("port", cfg.Port))
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
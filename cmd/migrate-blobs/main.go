@@ -0,0 +1,74 @@
+// Command migrate-blobs moves the contents of files that are still stored in the files.data column to
+// the configured blob store. It uses the same configuration as the backend, can run while the backend
+// serves requests, and can be stopped and run again: files that were already moved are skipped.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"NYCU-SDC/core-system-backend/internal/config"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/file/blobstore"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+func main() {
+	batchSize := flag.Int("batch_size", 100, "number of files listed per batch")
+
+	cfg, cfgLog := config.Load()
+	err := cfg.Validate()
+	if err != nil {
+		log.Fatalf("Failed to validate config: %v, exiting...", err)
+	}
+
+	loggerConfig := logutil.ZapProductionConfig()
+	if cfg.Debug {
+		loggerConfig = logutil.ZapDevelopmentConfig()
+	}
+	logger, err := loggerConfig.Build()
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v, exiting...", err)
+	}
+	cfgLog.FlushToZap(logger)
+
+	if *batchSize <= 0 {
+		logger.Fatal("batch_size must be greater than zero")
+	}
+
+	// The storage_key column is added by the migrations
+	err = databaseutil.MigrationUp(cfg.MigrationSource, cfg.DatabaseURL, logger)
+	if err != nil {
+		logger.Fatal("Failed to run database migration", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dbPool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatal("Failed to initialize database pool", zap.Error(err))
+	}
+	defer dbPool.Close()
+
+	blobStore, err := blobstore.New(cfg.BlobStore())
+	if err != nil {
+		logger.Fatal("Failed to initialize file storage", zap.Error(err))
+	}
+
+	logger.Info("Moving file contents to the blob store", zap.String("driver", cfg.FileStorageDriver))
+
+	moved, err := file.NewService(logger, dbPool, blobStore, nil).MigrateBlobs(ctx, int32(*batchSize))
+	if err != nil {
+		logger.Fatal("Failed to move file contents", zap.Int("moved", moved), zap.Error(err))
+	}
+
+	logger.Info("Moved file contents to the blob store", zap.Int("moved", moved))
+}
@@ -0,0 +1,42 @@
+package webhookresolver
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type WebhookService interface {
+	GetUnitID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+}
+
+type PathResolver struct {
+	service WebhookService
+}
+
+func NewPathResolver(service WebhookService) *PathResolver {
+	return &PathResolver{
+		service: service,
+	}
+}
+
+func (r *PathResolver) ResolveUnitID(ctx context.Context, req *http.Request) (uuid.UUID, error) {
+	webhookIDStr := req.PathValue("webhookId")
+	if webhookIDStr == "" {
+		return uuid.Nil, internal.ErrMissingWebhookID
+	}
+
+	webhookID, err := uuid.Parse(webhookIDStr)
+	if err != nil {
+		return uuid.Nil, internal.ErrInvalidWebhookID
+	}
+
+	unitID, err := r.service.GetUnitID(ctx, webhookID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return unitID, nil
+}
@@ -2,10 +2,14 @@ package config
 
 import (
 	Oauth "NYCU-SDC/core-system-backend/internal/auth/oauthprovider"
+	"NYCU-SDC/core-system-backend/internal/file/blobstore"
+	"NYCU-SDC/core-system-backend/internal/file/scanner"
+	"NYCU-SDC/core-system-backend/internal/notify/mailer"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,6 +39,29 @@ type Config struct {
 	MigrationSource           string            `yaml:"migration_source"   envconfig:"MIGRATION_SOURCE"`
 	AccessTokenExpirationStr  string            `yaml:"access_token_expiration" envconfig:"ACCESS_TOKEN_EXPIRATION"`
 	RefreshTokenExpirationStr string            `yaml:"refresh_token_expiration" envconfig:"REFRESH_TOKEN_EXPIRATION"`
+	SchedulerIntervalStr      string            `yaml:"scheduler_interval" envconfig:"SCHEDULER_INTERVAL"`
+	FileURLExpirationStr      string            `yaml:"file_url_expiration" envconfig:"FILE_URL_EXPIRATION"`
+	ExportWorkers             int               `yaml:"export_workers"     envconfig:"EXPORT_WORKERS"`
+	FileStorageDriver         string            `yaml:"file_storage_driver" envconfig:"FILE_STORAGE_DRIVER"`
+	FileStoragePath           string            `yaml:"file_storage_path"  envconfig:"FILE_STORAGE_PATH"`
+	S3Endpoint                string            `yaml:"s3_endpoint"        envconfig:"S3_ENDPOINT"`
+	S3Region                  string            `yaml:"s3_region"          envconfig:"S3_REGION"`
+	S3Bucket                  string            `yaml:"s3_bucket"          envconfig:"S3_BUCKET"`
+	S3AccessKey               string            `yaml:"s3_access_key"      envconfig:"S3_ACCESS_KEY"`
+	S3SecretKey               string            `yaml:"s3_secret_key"      envconfig:"S3_SECRET_KEY"`
+	FileScanner               string            `yaml:"file_scanner"       envconfig:"FILE_SCANNER"`
+	ClamdAddress              string            `yaml:"clamd_address"      envconfig:"CLAMD_ADDRESS"`
+	MailDriver                string            `yaml:"mail_driver"        envconfig:"MAIL_DRIVER"`
+	MailLogPath               string            `yaml:"mail_log_path"      envconfig:"MAIL_LOG_PATH"`
+	SMTPHost                  string            `yaml:"smtp_host"          envconfig:"SMTP_HOST"`
+	SMTPPort                  string            `yaml:"smtp_port"          envconfig:"SMTP_PORT"`
+	SMTPUsername              string            `yaml:"smtp_username"      envconfig:"SMTP_USERNAME"`
+	SMTPPassword              string            `yaml:"smtp_password"      envconfig:"SMTP_PASSWORD"`
+	SMTPFrom                  string            `yaml:"smtp_from"          envconfig:"SMTP_FROM"`
+	MailTimezone              string            `yaml:"mail_timezone"      envconfig:"MAIL_TIMEZONE"`
+	DigestIntervalStr         string            `yaml:"digest_interval"    envconfig:"DIGEST_INTERVAL"`
+	GoogleSheetsKeyFile       string            `yaml:"google_sheets_key_file" envconfig:"GOOGLE_SHEETS_KEY_FILE"`
+	WebhookAllowPrivate       bool              `yaml:"webhook_allow_private" envconfig:"WEBHOOK_ALLOW_PRIVATE"`
 	OtelCollectorUrl          string            `yaml:"otel_collector_url" envconfig:"OTEL_COLLECTOR_URL"`
 	AllowOrigins              []string          `yaml:"allow_origins"      envconfig:"ALLOW_ORIGINS"`
 	GoogleOauth               Oauth.GoogleOauth `yaml:"google_oauth"`
@@ -45,10 +72,14 @@ type Config struct {
 	DefaultGlobalRoles  string `yaml:"default_global_roles" envconfig:"DEFAULT_GLOBAL_ROLES"`
 	DefaultOrgRoles     string `yaml:"default_org_roles" envconfig:"DEFAULT_ORG_ROLES"`
 
-	SetupPath              string        `yaml:"setup_path" envconfig:"SETUP_PATH"`
-	SetupData              string        `yaml:"setup_data" envconfig:"SETUP_YAML"`
-	AccessTokenExpiration  time.Duration `yaml:"-"`
-	RefreshTokenExpiration time.Duration `yaml:"-"`
+	SetupPath              string         `yaml:"setup_path" envconfig:"SETUP_PATH"`
+	SetupData              string         `yaml:"setup_data" envconfig:"SETUP_YAML"`
+	AccessTokenExpiration  time.Duration  `yaml:"-"`
+	RefreshTokenExpiration time.Duration  `yaml:"-"`
+	SchedulerInterval      time.Duration  `yaml:"-"`
+	FileURLExpiration      time.Duration  `yaml:"-"`
+	DigestInterval         time.Duration  `yaml:"-"`
+	MailLocation           *time.Location `yaml:"-"`
 }
 
 type LogBuffer struct {
@@ -112,6 +143,84 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Parse scheduler_interval string into time.Duration
+	if c.SchedulerIntervalStr != "" {
+		c.SchedulerInterval, err = time.ParseDuration(c.SchedulerIntervalStr)
+		if err != nil {
+			return fmt.Errorf("invalid scheduler_interval: %w", err)
+		}
+		if c.SchedulerInterval <= 0 {
+			return fmt.Errorf("scheduler_interval must be greater than zero")
+		}
+	}
+
+	// Parse file_url_expiration string into time.Duration
+	if c.FileURLExpirationStr != "" {
+		c.FileURLExpiration, err = time.ParseDuration(c.FileURLExpirationStr)
+		if err != nil {
+			return fmt.Errorf("invalid file_url_expiration: %w", err)
+		}
+		if c.FileURLExpiration <= 0 {
+			return fmt.Errorf("file_url_expiration must be greater than zero")
+		}
+	}
+
+	// Parse digest_interval string into time.Duration
+	if c.DigestIntervalStr != "" {
+		c.DigestInterval, err = time.ParseDuration(c.DigestIntervalStr)
+		if err != nil {
+			return fmt.Errorf("invalid digest_interval: %w", err)
+		}
+		if c.DigestInterval <= 0 {
+			return fmt.Errorf("digest_interval must be greater than zero")
+		}
+	}
+
+	if c.ExportWorkers <= 0 {
+		return fmt.Errorf("export_workers must be greater than zero")
+	}
+
+	switch c.FileStorageDriver {
+	case blobstore.DriverFilesystem:
+		if c.FileStoragePath == "" {
+			return fmt.Errorf("file_storage_path must be set when file_storage_driver is filesystem")
+		}
+	case blobstore.DriverS3:
+		if c.S3Endpoint == "" || c.S3Bucket == "" {
+			return fmt.Errorf("s3_endpoint and s3_bucket must be set when file_storage_driver is s3")
+		}
+	default:
+		return fmt.Errorf("invalid file_storage_driver %q: must be filesystem or s3", c.FileStorageDriver)
+	}
+
+	switch c.FileScanner {
+	case "":
+	case scanner.DriverClamd:
+		if c.ClamdAddress == "" {
+			return fmt.Errorf("clamd_address must be set when file_scanner is clamd")
+		}
+	default:
+		return fmt.Errorf("invalid file_scanner %q: must be empty or clamd", c.FileScanner)
+	}
+
+	switch c.MailDriver {
+	case mailer.DriverLog:
+		if c.MailLogPath == "" {
+			return fmt.Errorf("mail_log_path must be set when mail_driver is log")
+		}
+	case mailer.DriverSMTP:
+		if c.SMTPHost == "" || c.SMTPPort == "" || c.SMTPFrom == "" {
+			return fmt.Errorf("smtp_host, smtp_port and smtp_from must be set when mail_driver is smtp")
+		}
+	default:
+		return fmt.Errorf("invalid mail_driver %q: must be log or smtp", c.MailDriver)
+	}
+
+	c.MailLocation, err = time.LoadLocation(c.MailTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid mail_timezone: %w", err)
+	}
+
 	if c.OauthProxyBaseURL != "" && c.OauthProxySecret == "" {
 		return fmt.Errorf("oauth_proxy_secret must be set when oauth_proxy_base_url is provided")
 	} else if c.OauthProxyBaseURL == "" && c.OauthProxySecret == "" {
@@ -127,6 +236,44 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// BlobStore returns the configuration of the store that keeps the contents of uploaded files
+func (c *Config) BlobStore() blobstore.Config {
+	return blobstore.Config{
+		Driver: c.FileStorageDriver,
+		Path:   c.FileStoragePath,
+		S3: blobstore.S3Config{
+			Endpoint:  c.S3Endpoint,
+			Region:    c.S3Region,
+			Bucket:    c.S3Bucket,
+			AccessKey: c.S3AccessKey,
+			SecretKey: c.S3SecretKey,
+		},
+	}
+}
+
+// Mailer returns the configuration of the driver that sends emails
+func (c *Config) Mailer() mailer.Config {
+	return mailer.Config{
+		Driver:  c.MailDriver,
+		LogPath: c.MailLogPath,
+		SMTP: mailer.SMTPConfig{
+			Host:     c.SMTPHost,
+			Port:     c.SMTPPort,
+			Username: c.SMTPUsername,
+			Password: c.SMTPPassword,
+			From:     c.SMTPFrom,
+		},
+	}
+}
+
+// Scanner returns the configuration of the scanner that checks uploaded files for malware
+func (c *Config) Scanner() scanner.Config {
+	return scanner.Config{
+		Driver:       c.FileScanner,
+		ClamdAddress: c.ClamdAddress,
+	}
+}
+
 func Load() (Config, *LogBuffer) {
 	logger := NewConfigLogger()
 
@@ -140,6 +287,18 @@ func Load() (Config, *LogBuffer) {
 		MigrationSource:           "file://internal/database/migrations",
 		AccessTokenExpirationStr:  "15m",
 		RefreshTokenExpirationStr: "720h",
+		SchedulerIntervalStr:      "1m",
+		FileURLExpirationStr:      "15m",
+		ExportWorkers:             2,
+		FileStorageDriver:         "filesystem",
+		FileStoragePath:           "data/files",
+		S3Region:                  "us-east-1",
+		ClamdAddress:              "localhost:3310",
+		MailDriver:                "log",
+		MailLogPath:               "data/mail.log",
+		SMTPPort:                  "587",
+		MailTimezone:              "Asia/Taipei",
+		DigestIntervalStr:         "24h",
 		OtelCollectorUrl:          "",
 		GoogleOauth:               Oauth.GoogleOauth{},
 		GitHubOauth:               Oauth.GitHubOauth{},
@@ -206,18 +365,50 @@ func FromEnv(config *Config, logger *LogBuffer) (*Config, error) {
 		config.AllowOrigins = strings.Split(allowOrigins, ",")
 	}
 
+	var exportWorkers int
+	exportWorkersStr := os.Getenv("EXPORT_WORKERS")
+	if exportWorkersStr != "" {
+		exportWorkers, err = strconv.Atoi(exportWorkersStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EXPORT_WORKERS: %w", err)
+		}
+	}
+
 	envConfig := &Config{
-		Debug:             os.Getenv("DEBUG") == "true",
-		Dev:               os.Getenv("DEV") == "true",
-		Host:              os.Getenv("HOST"),
-		Port:              os.Getenv("PORT"),
-		BaseURL:           os.Getenv("BASE_URL"),
-		OauthProxyBaseURL: os.Getenv("OAUTH_PROXY_BASE_URL"),
-		OauthProxySecret:  os.Getenv("OAUTH_PROXY_SECRET"),
-		Secret:            os.Getenv("SECRET"),
-		DatabaseURL:       os.Getenv("DATABASE_URL"),
-		MigrationSource:   os.Getenv("MIGRATION_SOURCE"),
-		OtelCollectorUrl:  os.Getenv("OTEL_COLLECTOR_URL"),
+		Debug:                os.Getenv("DEBUG") == "true",
+		Dev:                  os.Getenv("DEV") == "true",
+		Host:                 os.Getenv("HOST"),
+		Port:                 os.Getenv("PORT"),
+		BaseURL:              os.Getenv("BASE_URL"),
+		OauthProxyBaseURL:    os.Getenv("OAUTH_PROXY_BASE_URL"),
+		OauthProxySecret:     os.Getenv("OAUTH_PROXY_SECRET"),
+		Secret:               os.Getenv("SECRET"),
+		DatabaseURL:          os.Getenv("DATABASE_URL"),
+		MigrationSource:      os.Getenv("MIGRATION_SOURCE"),
+		OtelCollectorUrl:     os.Getenv("OTEL_COLLECTOR_URL"),
+		SchedulerIntervalStr: os.Getenv("SCHEDULER_INTERVAL"),
+		FileURLExpirationStr: os.Getenv("FILE_URL_EXPIRATION"),
+		ExportWorkers:        exportWorkers,
+		FileStorageDriver:    os.Getenv("FILE_STORAGE_DRIVER"),
+		FileStoragePath:      os.Getenv("FILE_STORAGE_PATH"),
+		S3Endpoint:           os.Getenv("S3_ENDPOINT"),
+		S3Region:             os.Getenv("S3_REGION"),
+		S3Bucket:             os.Getenv("S3_BUCKET"),
+		S3AccessKey:          os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:          os.Getenv("S3_SECRET_KEY"),
+		FileScanner:          os.Getenv("FILE_SCANNER"),
+		ClamdAddress:         os.Getenv("CLAMD_ADDRESS"),
+		MailDriver:           os.Getenv("MAIL_DRIVER"),
+		MailLogPath:          os.Getenv("MAIL_LOG_PATH"),
+		SMTPHost:             os.Getenv("SMTP_HOST"),
+		SMTPPort:             os.Getenv("SMTP_PORT"),
+		SMTPUsername:         os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:         os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:             os.Getenv("SMTP_FROM"),
+		MailTimezone:         os.Getenv("MAIL_TIMEZONE"),
+		DigestIntervalStr:    os.Getenv("DIGEST_INTERVAL"),
+		GoogleSheetsKeyFile:  os.Getenv("GOOGLE_SHEETS_KEY_FILE"),
+		WebhookAllowPrivate:  os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true",
 		GoogleOauth: Oauth.GoogleOauth{
 			ClientID:     os.Getenv("GOOGLE_OAUTH_CLIENT_ID"),
 			ClientSecret: os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET"),
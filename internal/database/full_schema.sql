@@ -2,19 +2,36 @@
 
 CREATE EXTENSION IF NOT EXISTS pgcrypto;
 
+CREATE TYPE file_scan_status AS ENUM(
+    'not_scanned',
+    'pending',
+    'clean',
+    'infected',
+    'failed'
+);
+
 CREATE TABLE IF NOT EXISTS files (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     original_filename VARCHAR(255) NOT NULL,
     content_type VARCHAR(100) NOT NULL,
     size BIGINT NOT NULL,
-    data BYTEA NOT NULL,
+    data BYTEA,
+    storage_key TEXT,
+    checksum TEXT,
     uploaded_by UUID,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    scan_status file_scan_status NOT NULL DEFAULT 'not_scanned',
+    scan_signature TEXT,
+    scanned_at TIMESTAMPTZ,
+    is_public BOOLEAN NOT NULL DEFAULT false,
+    CONSTRAINT files_storage_key_or_data CHECK (storage_key IS NOT NULL OR data IS NOT NULL)
 );
 
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_storage_key ON files(storage_key);
 CREATE INDEX IF NOT EXISTS idx_files_uploaded_by ON files(uploaded_by);
 CREATE INDEX IF NOT EXISTS idx_files_created_at ON files(created_at);
+CREATE INDEX IF NOT EXISTS idx_files_quarantined ON files(updated_at) WHERE scan_status IN ('pending', 'failed');
 
 CREATE TYPE resource_type AS ENUM(
     'form_answer'
@@ -32,6 +49,47 @@ CREATE TABLE IF NOT EXISTS file_attachments (
 
 CREATE INDEX IF NOT EXISTS idx_file_attachments_file_id ON file_attachments(file_id);
 CREATE INDEX IF NOT EXISTS idx_file_attachments_resource ON file_attachments(resource_type, resource_id);
+
+CREATE TYPE file_variant_size AS ENUM(
+    'small',
+    'medium',
+    'large'
+);
+
+CREATE TABLE IF NOT EXISTS file_variants (
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    size file_variant_size NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    byte_size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (file_id, size)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_file_variants_storage_key ON file_variants(storage_key);
+
+CREATE TYPE file_download_method AS ENUM(
+    'session',
+    'signed_url',
+    'public'
+);
+
+CREATE TABLE IF NOT EXISTS file_downloads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    method file_download_method NOT NULL,
+    variant_size file_variant_size,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_downloads_file_id ON file_downloads(file_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_file_downloads_user_id ON file_downloads(user_id);
 CREATE TABLE IF NOT EXISTS answers (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
@@ -43,6 +101,48 @@ CREATE TABLE IF NOT EXISTS answers (
 );
 
 CREATE INDEX IF NOT EXISTS idx_answers_question_id ON answers(question_id);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Text of the string and number values of an answer, searched by the response list
+CREATE OR REPLACE FUNCTION answer_search_text(value JSONB) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT jsonb_path_query_array(value, 'strict $.** ? (@.type() == "string" || @.type() == "number")')::text
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_answers_search_text ON answers USING gin (answer_search_text(value) gin_trgm_ops);
+CREATE TYPE export_job_status AS ENUM(
+    'pending',
+    'running',
+    'completed',
+    'failed'
+);
+
+CREATE TABLE IF NOT EXISTS response_export_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_ids UUID[] NOT NULL,
+    format TEXT NOT NULL,
+    -- response.ListFilter the export was requested with
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status export_job_status NOT NULL DEFAULT 'pending',
+    processed_count INTEGER NOT NULL DEFAULT 0,
+    total_count INTEGER NOT NULL DEFAULT 0,
+    -- Number of times a worker has claimed the job; jobs interrupted too often are failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    file_id UUID REFERENCES files(id) ON DELETE SET NULL,
+    error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched while a job runs, so a running job whose updated_at is old was left by a crashed worker
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_export_jobs_status_created_at ON response_export_jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_response_export_jobs_form_id ON response_export_jobs(form_id);
 CREATE TABLE IF NOT EXISTS form_highlights (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     form_id UUID NOT NULL UNIQUE REFERENCES forms(id) ON DELETE CASCADE,
@@ -80,7 +180,26 @@ CREATE TABLE IF NOT EXISTS questions(
     source_id UUID,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
-);CREATE TYPE response_progress AS ENUM (
+);-- A deadline reminder of a form, sent the given number of hours before its deadline; the inbox message
+-- of the reminder points at it
+CREATE TABLE IF NOT EXISTS form_deadline_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    hours_before INTEGER NOT NULL,
+    -- Deadline of the form the reminder is sent for; a moved deadline gets reminders of its own
+    deadline TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, hours_before, deadline)
+);
+
+-- Users a reminder was sent to, so that a user gets each reminder at most once
+CREATE TABLE IF NOT EXISTS form_deadline_reminder_recipients (
+    reminder_id UUID NOT NULL REFERENCES form_deadline_reminders(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reminded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (reminder_id, user_id)
+);
+CREATE TYPE response_progress AS ENUM (
     'draft',
     'submitted'
 );
@@ -91,9 +210,14 @@ CREATE TABLE IF NOT EXISTS form_responses (
     submitted_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     submitted_at TIMESTAMPTZ DEFAULT NULL,
     progress response_progress NOT NULL DEFAULT 'draft',
+    -- Workflow version the response was started under; NULL follows the latest version
+    workflow_version_id UUID REFERENCES workflow_versions(id) ON DELETE SET NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
+
+CREATE INDEX IF NOT EXISTS idx_form_responses_workflow_version_id ON form_responses(workflow_version_id);
+CREATE INDEX IF NOT EXISTS idx_form_responses_form_id_submitted_at ON form_responses(form_id, submitted_at DESC NULLS LAST, id DESC);
 CREATE TYPE status AS ENUM(
     'draft',
     'published',
@@ -128,12 +252,18 @@ CREATE TABLE IF NOT EXISTS forms (
     dressing_header_font TEXT,
     dressing_question_font TEXT,
     dressing_text_font TEXT,
-    allow_edit_response BOOLEAN NOT NULL DEFAULT false
+    allow_edit_response BOOLEAN NOT NULL DEFAULT false,
+    deadline_reminders_enabled BOOLEAN NOT NULL DEFAULT false,
+    -- How many hours before the deadline users who have not submitted a response are reminded
+    deadline_reminder_hours INTEGER[] NOT NULL DEFAULT '{72,24}'
 );
 
+CREATE INDEX IF NOT EXISTS idx_forms_draft_publish_time ON forms(publish_time) WHERE status = 'draft';
+CREATE INDEX IF NOT EXISTS idx_forms_published_deadline ON forms(deadline) WHERE status = 'published';
+
 CREATE TABLE IF NOT EXISTS form_covers (
     form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
-    image_data BYTEA NOT NULL,
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
@@ -150,12 +280,43 @@ CREATE TABLE IF NOT EXISTS sections (
 
 CREATE INDEX idx_sections_form_id ON sections(form_id);
 
+CREATE TYPE sheet_sync_status AS ENUM(
+    'pending',
+    'synced',
+    'failed'
+);
+
+-- Sync of the responses of a form to its linked Google Sheet
+CREATE TABLE IF NOT EXISTS form_sheet_syncs (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    -- Google Sheet the rows below were written to; a form linked to another sheet is synced in full again
+    sheet_url TEXT NOT NULL,
+    status sheet_sync_status NOT NULL DEFAULT 'pending',
+    -- Error of the last failed sync
+    error TEXT,
+    last_synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Until when a sync holds the lock of the sheet; a lock whose sync crashed expires
+    locked_until TIMESTAMPTZ
+);
+
+-- Sheet row of each synced response
+CREATE TABLE IF NOT EXISTS form_sheet_sync_rows (
+    form_id UUID NOT NULL REFERENCES form_sheet_syncs(form_id) ON DELETE CASCADE,
+    -- Not a foreign key: the row of a deleted response stays in the sheet, struck through
+    response_id UUID NOT NULL,
+    row_number INTEGER NOT NULL,
+    struck BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (form_id, response_id)
+);
 CREATE TABLE IF NOT EXISTS views (
     id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     form_id    UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
     title      TEXT NOT NULL,
     locked     BOOLEAN NOT NULL DEFAULT FALSE,
     "order"    INTEGER NOT NULL,
+    definition JSONB NOT NULL DEFAULT '{}'::jsonb,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
@@ -168,7 +329,8 @@ CREATE TYPE node_type AS ENUM(
     'section',
     'end',
     'start',
-    'condition'
+    'condition',
+    'switch'
 );
 
 CREATE TABLE IF NOT EXISTS workflow_versions (
@@ -190,7 +352,9 @@ CREATE INDEX idx_workflow_versions_latest ON workflow_versions(form_id, updated_
 CREATE INDEX idx_workflow_versions_seq ON workflow_versions(form_id, seq DESC);
 CREATE TYPE content_type AS ENUM(
     'text',
-    'form'
+    'form',
+    'export',
+    'reminder'
 );
 
 CREATE TABLE IF NOT EXISTS inbox_message(
@@ -202,13 +366,16 @@ CREATE TABLE IF NOT EXISTS inbox_message(
     updated_at TIMESTAMP NOT NULL DEFAULT now()
 );
 
+CREATE INDEX IF NOT EXISTS idx_inbox_message_content ON inbox_message(type, content_id);
+
 CREATE TABLE IF NOT EXISTS user_inbox_messages (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID NOT NULL references users(id) ON DELETE CASCADE,
     message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
     is_read boolean NOT NULL DEFAULT false,
     is_starred boolean NOT NULL DEFAULT false,
-    is_archived boolean NOT NULL DEFAULT false
+    is_archived boolean NOT NULL DEFAULT false,
+    UNIQUE(user_id, message_id)
 );
 CREATE EXTENSION IF NOT EXISTS pgcrypto;
 
@@ -217,7 +384,78 @@ CREATE TABLE IF NOT EXISTS refresh_tokens (
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     is_active BOOLEAN DEFAULT TRUE,
     expiration_date TIMESTAMPTZ NOT NULL
-);CREATE TYPE db_strategy AS ENUM ('shared', 'isolated');
+);CREATE TYPE notification_status AS ENUM(
+    'pending',
+    'sending',
+    'sent',
+    'failed'
+);
+
+-- Queue of emails, rendered when they are queued so that sending them needs nothing else
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Template the email was rendered from, e.g. submission_receipt
+    kind TEXT NOT NULL,
+    recipient_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    to_address TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    -- Identifies what the email is about, so that queuing it again for the same reason is a no-op
+    dedupe_key TEXT NOT NULL UNIQUE,
+    status notification_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- When a pending email is sent next
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Error of the last failed attempt
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched when an attempt starts, so a sending row whose updated_at is old was left by a crashed worker
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_status_next_attempt_at ON notifications(status, next_attempt_at);
+
+-- Responses of a form submitted up to digested_until have been reported in a new-response digest
+CREATE TABLE IF NOT EXISTS form_response_digests (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    digested_until TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TYPE event_status AS ENUM(
+    'pending',
+    'dispatching',
+    'dispatched',
+    'failed'
+);
+
+-- Outbox of domain events, written in the transaction of the change they record and then dispatched to
+-- the in-process subscribers
+CREATE TABLE IF NOT EXISTS events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Order in which the events were written; the events of an aggregate are dispatched in this order
+    seq BIGSERIAL NOT NULL UNIQUE,
+    -- Kind and id of the entity the event happened to, e.g. a form response
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status event_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- When a pending event is dispatched next
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Error of the last failed dispatch
+    error TEXT,
+    dispatched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched when a dispatch starts, so a dispatching row whose updated_at is old was left by a crashed dispatcher
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_status_next_attempt_at ON events(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_events_aggregate ON events(aggregate_type, aggregate_id, seq);
+CREATE TYPE db_strategy AS ENUM ('shared', 'isolated');
 
 CREATE TABLE IF NOT EXISTS tenants
 (
@@ -304,3 +542,54 @@ SELECT
 FROM users u
 LEFT JOIN user_emails e ON u.id = e.user_id
 GROUP BY u.id, u.name, u.username, u.avatar_url, u.role, u.is_onboarded, u.created_at, u.updated_at;
+-- Endpoints that are sent the events of the forms of an organization, or of one form when form_id is set
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Organization of the webhook, or unit of its form; its admins manage the webhook
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    form_id UUID REFERENCES forms(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    -- Key of the HMAC-SHA256 signature of every delivery
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_unit_id ON webhooks(unit_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_form_id ON webhooks(form_id);
+
+CREATE TYPE webhook_delivery_status AS ENUM(
+    'pending',
+    'delivering',
+    'succeeded',
+    'failed'
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    -- Request body sent on every attempt
+    payload JSONB NOT NULL,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- When a pending delivery is attempted next
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- HTTP status and error of the last attempt
+    response_status INTEGER,
+    error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched when an attempt starts, so a delivering row whose updated_at is old was left by a crashed worker
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Outbox event the delivery was queued for; a webhook is queued one delivery per event however often the
+    -- event is dispatched. Redeliveries have none.
+    event_id UUID REFERENCES events(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id_event_id ON webhook_deliveries(webhook_id, event_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_inbox_message_content;
+
+ALTER TABLE user_inbox_messages
+DROP CONSTRAINT IF EXISTS user_inbox_messages_user_id_message_id_key;
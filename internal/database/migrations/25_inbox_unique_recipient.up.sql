@@ -0,0 +1,10 @@
+DELETE FROM user_inbox_messages a
+USING user_inbox_messages b
+WHERE a.user_id = b.user_id
+  AND a.message_id = b.message_id
+  AND a.id > b.id;
+
+ALTER TABLE user_inbox_messages
+ADD CONSTRAINT user_inbox_messages_user_id_message_id_key UNIQUE (user_id, message_id);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_message_content ON inbox_message(type, content_id);
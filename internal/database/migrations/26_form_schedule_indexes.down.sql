@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_forms_published_deadline;
+DROP INDEX IF EXISTS idx_forms_draft_publish_time;
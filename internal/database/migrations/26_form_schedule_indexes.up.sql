@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_forms_draft_publish_time ON forms(publish_time) WHERE status = 'draft';
+CREATE INDEX IF NOT EXISTS idx_forms_published_deadline ON forms(deadline) WHERE status = 'published';
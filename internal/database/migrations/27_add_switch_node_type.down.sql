@@ -0,0 +1,10 @@
+-- node_type is only used for casts in workflow queries, so no column has to be converted.
+-- Workflows that still contain switch nodes should be edited before rolling back.
+DROP TYPE IF EXISTS node_type;
+
+CREATE TYPE node_type AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition'
+);
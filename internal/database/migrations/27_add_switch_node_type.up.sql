@@ -0,0 +1,2 @@
+ALTER TYPE node_type
+    ADD VALUE IF NOT EXISTS 'switch';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_form_responses_workflow_version_id;
+
+ALTER TABLE form_responses DROP COLUMN IF EXISTS workflow_version_id;
@@ -0,0 +1,20 @@
+-- Pin each response to the workflow version it was started under, so editing the workflow of a
+-- live form does not change the sections of responses that are already in progress.
+ALTER TABLE form_responses
+    ADD COLUMN IF NOT EXISTS workflow_version_id UUID REFERENCES workflow_versions(id) ON DELETE SET NULL;
+
+-- Backfill existing responses with the active workflow version of their form.
+-- Responses of forms without an active version stay unpinned and follow the latest version.
+UPDATE form_responses AS fr
+SET workflow_version_id = (
+    SELECT wv.id
+    FROM workflow_versions AS wv
+    WHERE wv.form_id = fr.form_id
+      AND wv.is_active = true
+    ORDER BY wv.seq DESC
+    LIMIT 1
+)
+WHERE fr.workflow_version_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_form_responses_workflow_version_id
+    ON form_responses(workflow_version_id);
@@ -0,0 +1,4 @@
+-- A view definition holds the visible question columns, filters and sort keys of a response view.
+-- Existing views get an empty definition, which shows every question without filters.
+ALTER TABLE views
+    ADD COLUMN IF NOT EXISTS definition JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_form_responses_form_id_submitted_at;
+DROP INDEX IF EXISTS idx_answers_search_text;
+DROP FUNCTION IF EXISTS answer_search_text(JSONB);
+DROP EXTENSION IF EXISTS pg_trgm;
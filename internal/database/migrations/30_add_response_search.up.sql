@@ -0,0 +1,16 @@
+-- Response list search matches answer text with trigrams, so substrings of CJK text and partial
+-- words are found without a language-specific tsvector configuration.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Text of the string and number values of an answer. Keys such as "choiceId" and "snapshot" are
+-- left out so that a search for them does not match every answer of a question type.
+CREATE OR REPLACE FUNCTION answer_search_text(value JSONB) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT jsonb_path_query_array(value, 'strict $.** ? (@.type() == "string" || @.type() == "number")')::text
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_answers_search_text ON answers USING gin (answer_search_text(value) gin_trgm_ops);
+
+-- Matches the list order of responses so that cursor pages are index range scans
+CREATE INDEX IF NOT EXISTS idx_form_responses_form_id_submitted_at ON form_responses(form_id, submitted_at DESC NULLS LAST, id DESC);
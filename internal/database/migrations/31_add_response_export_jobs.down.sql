@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS response_export_jobs;
+
+DROP TYPE IF EXISTS export_job_status;
+
+DELETE FROM inbox_message WHERE type = 'export';
+
+CREATE TYPE content_type_new AS ENUM (
+    'text',
+    'form'
+);
+
+ALTER TABLE inbox_message
+ALTER COLUMN type TYPE content_type_new
+USING type::text::content_type_new;
+
+DROP TYPE content_type;
+
+ALTER TYPE content_type_new RENAME TO content_type;
@@ -0,0 +1,9 @@
+-- Fails while any file lives only in the blob store; copy those back into files.data first
+ALTER TABLE files ALTER COLUMN data SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_files_storage_key;
+
+ALTER TABLE files DROP CONSTRAINT IF EXISTS files_storage_key_or_data;
+
+ALTER TABLE files DROP COLUMN IF EXISTS checksum;
+ALTER TABLE files DROP COLUMN IF EXISTS storage_key;
@@ -0,0 +1,9 @@
+-- File contents move out of the database into a blob store. Rows created before this migration keep
+-- their data until the migrate-blobs command has moved it to the store.
+ALTER TABLE files ADD COLUMN IF NOT EXISTS storage_key TEXT;
+ALTER TABLE files ADD COLUMN IF NOT EXISTS checksum TEXT;
+ALTER TABLE files ALTER COLUMN data DROP NOT NULL;
+
+ALTER TABLE files ADD CONSTRAINT files_storage_key_or_data CHECK (storage_key IS NOT NULL OR data IS NOT NULL);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_storage_key ON files(storage_key);
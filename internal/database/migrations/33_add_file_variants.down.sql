@@ -0,0 +1,4 @@
+-- The contents of the variants stay in the blob store; they are not referenced by anything afterwards
+DROP TABLE IF EXISTS file_variants;
+
+DROP TYPE IF EXISTS file_variant_size;
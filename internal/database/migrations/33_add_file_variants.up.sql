@@ -0,0 +1,21 @@
+-- Resized copies of uploaded images, served through GET /api/files/{id}?size=
+CREATE TYPE file_variant_size AS ENUM(
+    'small',
+    'medium',
+    'large'
+);
+
+CREATE TABLE IF NOT EXISTS file_variants (
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    size file_variant_size NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    byte_size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (file_id, size)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_file_variants_storage_key ON file_variants(storage_key);
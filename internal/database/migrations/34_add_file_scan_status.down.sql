@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_files_quarantined;
+
+ALTER TABLE files DROP COLUMN IF EXISTS scanned_at;
+ALTER TABLE files DROP COLUMN IF EXISTS scan_signature;
+ALTER TABLE files DROP COLUMN IF EXISTS scan_status;
+
+DROP TYPE IF EXISTS file_scan_status;
@@ -0,0 +1,15 @@
+-- Result of scanning the content of a file for malware. Files uploaded before scanning was introduced,
+-- or while no scanner is configured, are not_scanned; pending, infected and failed files are quarantined.
+CREATE TYPE file_scan_status AS ENUM(
+    'not_scanned',
+    'pending',
+    'clean',
+    'infected',
+    'failed'
+);
+
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scan_status file_scan_status NOT NULL DEFAULT 'not_scanned';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scan_signature TEXT;
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_files_quarantined ON files(updated_at) WHERE scan_status IN ('pending', 'failed');
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS file_downloads;
+
+DROP TYPE IF EXISTS file_download_method;
+
+ALTER TABLE files DROP COLUMN IF EXISTS is_public;
@@ -0,0 +1,32 @@
+-- Public files, such as form covers and avatars, can be downloaded by anyone; other files only by users
+-- who can access them or through a signed URL
+ALTER TABLE files ADD COLUMN IF NOT EXISTS is_public BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE files SET is_public = true
+WHERE '/api/files/' || id::text IN (
+    SELECT cover_image_url FROM forms WHERE cover_image_url IS NOT NULL
+    UNION
+    SELECT avatar_url FROM users WHERE avatar_url IS NOT NULL
+);
+
+-- How a download was authorized
+CREATE TYPE file_download_method AS ENUM(
+    'session',
+    'signed_url',
+    'public'
+);
+
+-- Audit log of file downloads. It outlives the files it refers to, so file_id has no foreign key.
+CREATE TABLE IF NOT EXISTS file_downloads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    method file_download_method NOT NULL,
+    variant_size file_variant_size,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_downloads_file_id ON file_downloads(file_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_file_downloads_user_id ON file_downloads(user_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS form_response_digests;
+
+DROP TABLE IF EXISTS notifications;
+
+DROP TYPE IF EXISTS notification_status;
@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS form_deadline_reminder_recipients;
+
+DROP TABLE IF EXISTS form_deadline_reminders;
+
+ALTER TABLE forms
+DROP COLUMN deadline_reminder_hours,
+DROP COLUMN deadline_reminders_enabled;
+
+DELETE FROM inbox_message WHERE type = 'reminder';
+
+CREATE TYPE content_type_new AS ENUM (
+    'text',
+    'form',
+    'export'
+);
+
+ALTER TABLE inbox_message
+ALTER COLUMN type TYPE content_type_new
+USING type::text::content_type_new;
+
+DROP TYPE content_type;
+
+ALTER TYPE content_type_new RENAME TO content_type;
@@ -0,0 +1,21 @@
+ALTER TYPE content_type ADD VALUE IF NOT EXISTS 'reminder';
+
+ALTER TABLE forms
+ADD COLUMN deadline_reminders_enabled BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN deadline_reminder_hours INTEGER[] NOT NULL DEFAULT '{72,24}';
+
+CREATE TABLE IF NOT EXISTS form_deadline_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    hours_before INTEGER NOT NULL,
+    deadline TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, hours_before)
+);
+
+CREATE TABLE IF NOT EXISTS form_deadline_reminder_recipients (
+    reminder_id UUID NOT NULL REFERENCES form_deadline_reminders(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reminded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (reminder_id, user_id)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS form_sheet_sync_rows;
+
+DROP TABLE IF EXISTS form_sheet_syncs;
+
+DROP TYPE IF EXISTS sheet_sync_status;
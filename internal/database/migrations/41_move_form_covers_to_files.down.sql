@@ -0,0 +1,12 @@
+ALTER TABLE form_covers ADD COLUMN IF NOT EXISTS image_data BYTEA;
+
+-- Covers whose contents were moved to the blob store cannot be copied back and are dropped
+UPDATE form_covers AS fc
+SET image_data = f.data
+FROM files AS f
+WHERE f.id = fc.file_id AND f.data IS NOT NULL;
+
+DELETE FROM form_covers WHERE image_data IS NULL;
+
+ALTER TABLE form_covers ALTER COLUMN image_data SET NOT NULL;
+ALTER TABLE form_covers DROP COLUMN IF EXISTS file_id;
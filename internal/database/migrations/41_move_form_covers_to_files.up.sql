@@ -0,0 +1,34 @@
+-- Form covers are files of the file service; form_covers keeps which file is the cover of a form
+ALTER TABLE form_covers ADD COLUMN IF NOT EXISTS file_id UUID REFERENCES files(id) ON DELETE CASCADE;
+
+-- Covers uploaded since the file service stored them are the file of their cover URL
+UPDATE form_covers AS fc
+SET file_id = f.id
+FROM forms, files AS f
+WHERE forms.id = fc.form_id
+  AND forms.cover_image_url = '/api/files/' || f.id::text;
+
+-- Older covers only exist in form_covers; they are copied to public files, whose contents the
+-- migrate-blobs command moves to the blob store
+WITH legacy AS (
+    SELECT form_id, image_data, gen_random_uuid() AS file_id
+    FROM form_covers
+    WHERE file_id IS NULL
+),
+copied AS (
+    INSERT INTO files (id, original_filename, content_type, size, data, is_public)
+    SELECT file_id, 'cover.webp', 'image/webp', length(image_data), image_data, true
+    FROM legacy
+)
+UPDATE form_covers AS fc
+SET file_id = legacy.file_id
+FROM legacy
+WHERE fc.form_id = legacy.form_id;
+
+UPDATE forms
+SET cover_image_url = '/api/files/' || fc.file_id::text
+FROM form_covers AS fc
+WHERE forms.id = fc.form_id;
+
+ALTER TABLE form_covers ALTER COLUMN file_id SET NOT NULL;
+ALTER TABLE form_covers DROP COLUMN IF EXISTS image_data;
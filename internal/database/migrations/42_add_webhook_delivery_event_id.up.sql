@@ -0,0 +1,5 @@
+-- Outbox event a delivery was queued for; a webhook is queued one delivery per event however often the
+-- event is dispatched
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS event_id UUID REFERENCES events(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id_event_id ON webhook_deliveries(webhook_id, event_id);
@@ -0,0 +1,11 @@
+ALTER TABLE form_deadline_reminders DROP CONSTRAINT IF EXISTS form_deadline_reminders_form_id_hours_before_deadline_key;
+
+-- Keeps the latest reminder of every offset
+DELETE FROM form_deadline_reminders r
+USING form_deadline_reminders newer
+WHERE newer.form_id = r.form_id
+  AND newer.hours_before = r.hours_before
+  AND (newer.created_at, newer.id) > (r.created_at, r.id);
+
+ALTER TABLE form_deadline_reminders
+ADD CONSTRAINT form_deadline_reminders_form_id_hours_before_key UNIQUE (form_id, hours_before);
@@ -0,0 +1,5 @@
+-- A reminder is sent for a deadline, so that moving the deadline of a form sends its reminders again
+ALTER TABLE form_deadline_reminders DROP CONSTRAINT IF EXISTS form_deadline_reminders_form_id_hours_before_key;
+
+ALTER TABLE form_deadline_reminders
+ADD CONSTRAINT form_deadline_reminders_form_id_hours_before_deadline_key UNIQUE (form_id, hours_before, deadline);
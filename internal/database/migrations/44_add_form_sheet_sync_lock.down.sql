@@ -0,0 +1 @@
+ALTER TABLE form_sheet_syncs DROP COLUMN IF EXISTS locked_until;
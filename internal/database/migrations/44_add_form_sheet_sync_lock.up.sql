@@ -0,0 +1,3 @@
+-- Until when a sync holds the lock of the sheet of a form; the lock is taken and released by short
+-- statements, so no transaction is held open across the Google Sheets calls of a sync
+ALTER TABLE form_sheet_syncs ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
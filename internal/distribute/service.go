@@ -2,12 +2,14 @@ package distribute
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/unit"
 	"NYCU-SDC/core-system-backend/internal/user"
 	"context"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -16,6 +18,7 @@ import (
 type UnitStore interface {
 	ListMembers(ctx context.Context, id uuid.UUID) ([]user.Profile, error)
 	ListUnitsMembers(ctx context.Context, unitIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
+	WithTx(tx pgx.Tx) *unit.Service
 }
 
 type Service struct {
@@ -32,6 +35,14 @@ func NewService(logger *zap.Logger, store UnitStore) *Service {
 	}
 }
 
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger: s.logger,
+		store:  s.store.WithTx(tx),
+		tracer: s.tracer,
+	}
+}
+
 // Todo: Need to use SimpleUser instead of uuid.UUID
 func (s *Service) GetOrgRecipients(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error) {
 	traceCtx, span := s.tracer.Start(ctx, "GetOrgRecipients")
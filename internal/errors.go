@@ -48,6 +48,8 @@ var (
 	ErrInvalidFormID     = errors.New("invalid form id")
 	ErrMissingResponseID = errors.New("missing response id")
 	ErrInvalidResponseID = errors.New("invalid response id")
+	ErrMissingWebhookID  = errors.New("missing webhook id")
+	ErrInvalidWebhookID  = errors.New("invalid webhook id")
 
 	// JWT Authentication Errors
 	ErrMissingAuthHeader       = errors.New("missing access token")
@@ -120,11 +122,15 @@ var (
 	ErrQuestionAnswerUnexpectedType     = errors.New("unexpected question answer type")
 	ErrQuestionAnswerDisplayValueFailed = errors.New("failed to get display value for question answer")
 	ErrQuestionAnswerPatternMatchFailed = errors.New("failed to match pattern for question answer")
+	ErrQuestionOperatorUnsupported      = errors.New("operator is not supported for this question type")
+	ErrQuestionOperandInvalid           = errors.New("invalid operand for condition operator")
 
 	// View Errors
-	ErrViewNotFound      = errors.New("view not found")
-	ErrViewLocked        = errors.New("view is locked and cannot be deleted")
-	ErrViewNameDuplicate = errors.New("view name is already in use")
+	ErrViewNotFound          = errors.New("view not found")
+	ErrViewLocked            = errors.New("view is locked and cannot be deleted")
+	ErrViewNameDuplicate     = errors.New("view name is already in use")
+	ErrViewDefinitionLocked  = errors.New("view is locked and its definition cannot be edited")
+	ErrViewDefinitionInvalid = errors.New("invalid view definition")
 
 	// Response Errors
 	ErrResponseNotFound       = errors.New("response not found")
@@ -133,6 +139,10 @@ var (
 	ErrResponseNotOwned       = errors.New("response does not belong to the current user")
 	ErrResponseEditNotAllowed = errors.New("response is not allowed to be edited")
 	ErrResponseNotSubmitted   = errors.New("response is not submitted")
+	ErrInvalidResponseCursor  = errors.New("invalid response cursor")
+	ErrInvalidResponseFilter  = errors.New("invalid response filter")
+	ErrInvalidExportFormat    = errors.New("invalid export format")
+	ErrExportJobNotFound      = errors.New("export job not found")
 
 	// Answer / Workflow: cannot answer questions in a section skipped by workflow
 	ErrAnswerSectionSkipped = errors.New("cannot answer questions in a section that is skipped by the form workflow")
@@ -157,17 +167,25 @@ var (
 	ErrWorkflowNodePayloadInvalid          = errors.New("invalid workflow node payload")
 
 	// File Errors
-	ErrFileNotFound       = errors.New("file not found")
-	ErrFileTooLarge       = errors.New("file exceeds maximum size")
-	ErrInvalidFileID      = errors.New("invalid file ID")
-	ErrInvalidMultipart   = errors.New("failed to parse multipart form")
-	ErrFailedToSaveFile   = errors.New("failed to save file")
-	ErrFailedToDeleteFile = errors.New("failed to delete file")
-	ErrInvalidLimit       = errors.New("invalid limit parameter")
-	ErrInvalidOffset      = errors.New("invalid offset parameter")
-	ErrInvalidFileType    = errors.New("file type is not allowed")
-	ErrCoverImageTooLarge = errors.New("cover image exceeds maximum size")
-	ErrInvalidImageFormat = errors.New("image format is invalid")
+	ErrFileNotFound           = errors.New("file not found")
+	ErrFileTooLarge           = errors.New("file exceeds maximum size")
+	ErrInvalidFileID          = errors.New("invalid file ID")
+	ErrInvalidMultipart       = errors.New("failed to parse multipart form")
+	ErrFailedToSaveFile       = errors.New("failed to save file")
+	ErrFailedToDeleteFile     = errors.New("failed to delete file")
+	ErrInvalidLimit           = errors.New("invalid limit parameter")
+	ErrInvalidOffset          = errors.New("invalid offset parameter")
+	ErrInvalidFileType        = errors.New("file type is not allowed")
+	ErrCoverImageTooLarge     = errors.New("cover image exceeds maximum size")
+	ErrInvalidImageFormat     = errors.New("image format is invalid")
+	ErrContentTypeMismatch    = errors.New("file content does not match its content type")
+	ErrImageTooLarge          = errors.New("image dimensions exceed the maximum")
+	ErrInvalidFileVariantSize = errors.New("invalid file variant size")
+	ErrFileVariantNotFound    = errors.New("file variant not found")
+	ErrFileInfected           = errors.New("file contains malware")
+	ErrFileQuarantined        = errors.New("file is quarantined until it is found clean by the malware scanner")
+	ErrInvalidSignedURL       = errors.New("invalid signed URL")
+	ErrSignedURLExpired       = errors.New("signed URL has expired")
 
 	// Markdown document errors (ProseMirror JSON validation and rendering).
 	ErrInvalidDocumentJSON          = errors.New("malformed rich text JSON")
@@ -181,6 +199,20 @@ var (
 	ErrInvalidDocumentMarshal       = errors.New("failed to canonicalize rich text JSON")
 	ErrInvalidDocumentRender        = errors.New("cannot render rich text node")
 
+	// Webhook Errors
+	ErrWebhookNotFound          = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound  = errors.New("webhook delivery not found")
+	ErrInvalidWebhookEvent      = errors.New("invalid webhook event")
+	ErrInvalidWebhookURL        = errors.New("invalid webhook URL")
+	ErrWebhookAddressNotAllowed = errors.New("webhook URL resolves to a non-public address")
+
+	// Sheet Sync Errors
+	ErrSheetSyncNotFound      = errors.New("sheet sync not found")
+	ErrSheetSyncNotConfigured = errors.New("google sheets sync is not configured")
+	ErrGoogleSheetNotLinked   = errors.New("form has no google sheet")
+	ErrInvalidGoogleSheetURL  = errors.New("invalid google sheet URL")
+	ErrGoogleSheetUnavailable = errors.New("google sheet is not found or not shared with the service account")
+
 	// Internal Handler Errors
 	ErrFailedToGetSlugFromContext = errors.New("failed to get org slug from context")
 )
@@ -223,6 +255,10 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewBadRequestProblem("response id is required")
 	case errors.Is(err, ErrInvalidResponseID):
 		return problem.NewBadRequestProblem("invalid response id")
+	case errors.Is(err, ErrMissingWebhookID):
+		return problem.NewBadRequestProblem("webhook id is required")
+	case errors.Is(err, ErrInvalidWebhookID):
+		return problem.NewBadRequestProblem("invalid webhook id")
 
 	// JWT Authentication Errors
 	case errors.Is(err, ErrMissingAuthHeader):
@@ -351,6 +387,15 @@ func ErrorHandler(err error) problem.Problem {
 		}
 	case errors.Is(err, ErrViewNameDuplicate):
 		return problem.NewBadRequestProblem("view name is already in use")
+	case errors.Is(err, ErrViewDefinitionLocked):
+		return problem.Problem{
+			Title:  "Conflict",
+			Status: 409,
+			Type:   "https://developer.mozilla.org/en-US/docs/Web/HTTP/Status/409",
+			Detail: "view is locked and its definition cannot be edited",
+		}
+	case errors.Is(err, ErrViewDefinitionInvalid):
+		return problem.NewValidateProblem("invalid view definition")
 
 	// Response Errors
 	case errors.Is(err, ErrResponseNotFound):
@@ -363,6 +408,14 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewForbiddenProblem("response is not allowed to be edited")
 	case errors.Is(err, ErrResponseNotSubmitted):
 		return problem.NewBadRequestProblem("response is not submitted")
+	case errors.Is(err, ErrInvalidResponseCursor):
+		return problem.NewBadRequestProblem("invalid response cursor")
+	case errors.Is(err, ErrInvalidResponseFilter):
+		return problem.NewBadRequestProblem("invalid response filter")
+	case errors.Is(err, ErrInvalidExportFormat):
+		return problem.NewBadRequestProblem("invalid export format, must be one of: XLSX, CSV, JSONL")
+	case errors.Is(err, ErrExportJobNotFound):
+		return problem.NewNotFoundProblem("export job not found")
 
 	// Submit Errors
 	case errors.Is(err, ErrResponseNotComplete{}):
@@ -423,6 +476,22 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewBadRequestProblem("invalid offset parameter")
 	case errors.Is(err, ErrInvalidFileType):
 		return problem.NewValidateProblem("file type is not allowed")
+	case errors.Is(err, ErrContentTypeMismatch):
+		return problem.NewValidateProblem("file content does not match its content type")
+	case errors.Is(err, ErrImageTooLarge):
+		return problem.NewValidateProblem("image dimensions exceed the maximum")
+	case errors.Is(err, ErrInvalidFileVariantSize):
+		return problem.NewBadRequestProblem("invalid size parameter, must be one of small, medium or large")
+	case errors.Is(err, ErrFileVariantNotFound):
+		return problem.NewNotFoundProblem("file variant not found")
+	case errors.Is(err, ErrFileInfected):
+		return problem.NewValidateProblem("file contains malware")
+	case errors.Is(err, ErrFileQuarantined):
+		return problem.NewForbiddenProblem("file is quarantined until it is found clean by the malware scanner")
+	case errors.Is(err, ErrInvalidSignedURL):
+		return problem.NewForbiddenProblem("invalid signed URL")
+	case errors.Is(err, ErrSignedURLExpired):
+		return problem.NewForbiddenProblem("signed URL has expired")
 
 	// Markdown document errors (ProseMirror JSON validation and rendering).
 	case errors.Is(err, ErrInvalidDocumentJSON):
@@ -446,6 +515,28 @@ func ErrorHandler(err error) problem.Problem {
 	case errors.Is(err, ErrInvalidDocumentTooLarge):
 		return problem.NewValidateProblem("rich text exceeds size limits")
 
+	// Webhook Errors
+	case errors.Is(err, ErrWebhookNotFound):
+		return problem.NewNotFoundProblem("webhook not found")
+	case errors.Is(err, ErrWebhookDeliveryNotFound):
+		return problem.NewNotFoundProblem("webhook delivery not found")
+	case errors.Is(err, ErrInvalidWebhookEvent):
+		return problem.NewValidateProblem("invalid webhook event")
+	case errors.Is(err, ErrInvalidWebhookURL):
+		return problem.NewValidateProblem("invalid webhook URL, must be an absolute http or https URL")
+	case errors.Is(err, ErrWebhookAddressNotAllowed):
+		return problem.NewValidateProblem("webhook URL must resolve to a public address")
+
+	// Sheet Sync Errors
+	case errors.Is(err, ErrSheetSyncNotFound):
+		return problem.NewNotFoundProblem("the responses of the form have not been synced to a Google Sheet")
+	case errors.Is(err, ErrSheetSyncNotConfigured):
+		return problem.NewBadRequestProblem("Google Sheets sync is not configured")
+	case errors.Is(err, ErrGoogleSheetNotLinked):
+		return problem.NewBadRequestProblem("form has no Google Sheet, set its googleSheetUrl first")
+	case errors.Is(err, ErrInvalidGoogleSheetURL):
+		return problem.NewValidateProblem("invalid Google Sheet URL, must be a https://docs.google.com/spreadsheets/d/... URL")
+
 	// Internal Handler Errors
 	case errors.Is(err, ErrFailedToGetSlugFromContext):
 		return problem.NewInternalServerProblem("failed to get org slug from context")
@@ -0,0 +1,61 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// BlobStore keeps the contents of files, addressed by the storage key of their row
+type BlobStore interface {
+	// Put stores the contents of r under key. size is -1 when it is not known up front.
+	// Nothing is left under key when reading r or storing its contents fails.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the contents stored under key. The reader can seek, so downloads can serve byte ranges.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the contents stored under key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// newStorageKey returns a key for new file contents. Keys are never reused, so a blob left behind
+// by a failed upload can never be mistaken for the contents of another file.
+func newStorageKey() string {
+	return uuid.New().String()
+}
+
+// checksumReader counts and hashes the bytes read through it
+type checksumReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+func newChecksumReader(r io.Reader) *checksumReader {
+	return &checksumReader{reader: r, hash: sha256.New()}
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+// Checksum returns the hex-encoded SHA-256 of the bytes read so far
+func (r *checksumReader) Checksum() string {
+	return hex.EncodeToString(r.hash.Sum(nil))
+}
+
+// legacyReader serves the contents of files created before contents moved to the blob store
+type legacyReader struct {
+	*bytes.Reader
+}
+
+func (legacyReader) Close() error {
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package blobstore provides the drivers that keep the contents of files outside the database
+package blobstore
+
+import (
+	"fmt"
+
+	"NYCU-SDC/core-system-backend/internal/file"
+)
+
+const (
+	DriverFilesystem = "filesystem"
+	DriverS3         = "s3"
+)
+
+type Config struct {
+	// Driver is DriverFilesystem or DriverS3
+	Driver string
+	// Path is the directory of the filesystem driver
+	Path string
+	S3   S3Config
+}
+
+// New returns the blob store of the configured driver
+func New(cfg Config) (file.BlobStore, error) {
+	switch cfg.Driver {
+	case DriverFilesystem:
+		return NewFilesystem(cfg.Path)
+	case DriverS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown file storage driver %q", cfg.Driver)
+	}
+}
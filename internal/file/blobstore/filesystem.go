@@ -0,0 +1,130 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"NYCU-SDC/core-system-backend/internal"
+)
+
+// Filesystem keeps blobs as files below a root directory, spread over subdirectories named after the
+// first two characters of their key
+type Filesystem struct {
+	root string
+}
+
+func NewFilesystem(root string) (*Filesystem, error) {
+	if root == "" {
+		return nil, errors.New("file storage path is required for the filesystem driver")
+	}
+
+	err := os.MkdirAll(root, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file storage directory: %w", err)
+	}
+
+	return &Filesystem{root: root}, nil
+}
+
+// path returns where the blob of key is kept. Keys are single path elements, so they can never
+// point outside the root directory.
+func (f *Filesystem) path(key string) (string, error) {
+	if len(key) < 2 || strings.ContainsAny(key, `/\`) || !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return filepath.Join(f.root, key[:2], key), nil
+}
+
+// Put writes the blob to a temporary file next to its final path and renames it into place once it
+// is complete, so readers never see a partial blob
+func (f *Filesystem) Put(ctx context.Context, key string, r io.Reader, _ int64, _ string) error {
+	target, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0o750)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), key+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Only left behind when storing failed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = io.Copy(tmp, &contextReader{ctx: ctx, reader: r})
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+func (f *Filesystem) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	target, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := os.Open(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no blob for storage key %s", internal.ErrFileNotFound, key)
+		}
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+func (f *Filesystem) Delete(_ context.Context, key string) error {
+	target, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// contextReader stops reading once its context is done, so a cancelled upload stops being stored
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	err := r.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+
+	return r.reader.Read(p)
+}
@@ -0,0 +1,90 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestFilesystem_PutOpenDelete(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFilesystem(root)
+	require.NoError(t, err)
+
+	key := "3f2b7c1e-0000-4000-8000-000000000001"
+	require.NoError(t, store.Put(context.Background(), key, strings.NewReader("hello, blob"), -1, "text/plain"))
+
+	blob, err := store.Open(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = blob.Seek(7, io.SeekStart)
+	require.NoError(t, err)
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.Equal(t, "blob", string(data))
+	require.NoError(t, blob.Close())
+
+	require.NoError(t, store.Delete(context.Background(), key))
+	require.NoError(t, store.Delete(context.Background(), key), "deleting a missing blob is not an error")
+
+	_, err = store.Open(context.Background(), key)
+	require.ErrorIs(t, err, internal.ErrFileNotFound)
+}
+
+func TestFilesystem_PutFailure(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFilesystem(root)
+	require.NoError(t, err)
+
+	key := "3f2b7c1e-0000-4000-8000-000000000002"
+	err = store.Put(context.Background(), key, io.MultiReader(strings.NewReader("partial"), failingReader{}), -1, "")
+	require.Error(t, err)
+
+	_, err = store.Open(context.Background(), key)
+	require.ErrorIs(t, err, internal.ErrFileNotFound)
+
+	entries, err := os.ReadDir(filepath.Join(root, key[:2]))
+	require.NoError(t, err)
+	require.Empty(t, entries, "no temporary file should be left behind")
+}
+
+func TestFilesystem_InvalidKey(t *testing.T) {
+	store, err := NewFilesystem(t.TempDir())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		key  string
+	}{
+		{name: "empty", key: ""},
+		{name: "parent directory", key: ".."},
+		{name: "nested path", key: "ab/../../etc/passwd"},
+		{name: "backslash", key: `ab\cd`},
+		{name: "absolute path", key: "/etc/passwd"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := store.Put(context.Background(), tc.key, strings.NewReader("x"), 1, "")
+			require.Error(t, err)
+
+			_, err = store.Open(context.Background(), tc.key)
+			require.Error(t, err)
+			require.NotErrorIs(t, err, internal.ErrFileNotFound)
+		})
+	}
+}
@@ -2,8 +2,10 @@ package file
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
-	"bytes"
 	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -21,11 +23,16 @@ import (
 
 type Store interface {
 	Get(ctx context.Context, id uuid.UUID) (File, error)
+	Open(ctx context.Context, file File) (io.ReadSeekCloser, error)
+	GetVariant(ctx context.Context, fileID uuid.UUID, size FileVariantSize) (FileVariant, error)
+	OpenVariant(ctx context.Context, variant FileVariant) (io.ReadSeekCloser, error)
 	GetMetadata(ctx context.Context, id uuid.UUID) (GetMetadataRow, error)
 	Delete(ctx context.Context, fileID uuid.UUID) error
 	GetAll(ctx context.Context, limit, offset int32) ([]GetAllRow, error)
 	Count(ctx context.Context) (int64, error)
 	GetByUploadedBy(ctx context.Context, userID uuid.UUID) ([]GetByUploadedByRow, error)
+	CanAccess(ctx context.Context, file File, userID uuid.UUID) (bool, error)
+	RecordDownload(ctx context.Context, download CreateDownloadParams) error
 }
 
 type Handler struct {
@@ -33,6 +40,7 @@ type Handler struct {
 	validator     *validator.Validate
 	problemWriter *problem.HttpWriter
 	store         Store
+	signer        *URLSigner
 	tracer        trace.Tracer
 }
 
@@ -41,12 +49,14 @@ func NewHandler(
 	validator *validator.Validate,
 	problemWriter *problem.HttpWriter,
 	store Store,
+	signer *URLSigner,
 ) *Handler {
 	return &Handler{
 		logger:        logger,
 		validator:     validator,
 		problemWriter: problemWriter,
 		store:         store,
+		signer:        signer,
 		tracer:        otel.Tracer("file/handler"),
 	}
 }
@@ -58,6 +68,12 @@ type Response struct {
 	Size             int64   `json:"size"`
 	UploadedBy       *string `json:"uploadedBy,omitempty"`
 	CreatedAt        string  `json:"createdAt"`
+	ScanStatus       string  `json:"scanStatus"`
+}
+
+type SignedURLResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
 }
 
 type ListFilesResponse struct {
@@ -68,7 +84,7 @@ type ListFilesResponse struct {
 }
 
 // toResponse converts file metadata to Response struct
-func toResponse(id uuid.UUID, filename, contentType string, size int64, uploadedBy pgtype.UUID, createdAt pgtype.Timestamptz) Response {
+func toResponse(id uuid.UUID, filename, contentType string, size int64, uploadedBy pgtype.UUID, createdAt pgtype.Timestamptz, scanStatus FileScanStatus) Response {
 	var uploadedByStr *string
 	if uploadedBy.Valid {
 		uid := uuid.UUID(uploadedBy.Bytes)
@@ -83,6 +99,7 @@ func toResponse(id uuid.UUID, filename, contentType string, size int64, uploaded
 		Size:             size,
 		UploadedBy:       uploadedByStr,
 		CreatedAt:        createdAt.Time.Format(time.RFC3339),
+		ScanStatus:       string(scanStatus),
 	}
 }
 
@@ -107,7 +124,11 @@ func (h *Handler) parseAndValidateFileID(w http.ResponseWriter, r *http.Request,
 	return fileID, fileIDStr, true
 }
 
-// Download handles GET /files/{id} - downloads a file
+// Download handles GET /files/{id} - downloads a file, serving byte ranges if requested.
+// With ?size=small|medium|large it serves the resized variant of an image instead; files without
+// variants are served as they are. Public files can be downloaded by anyone; other files need a signed
+// URL or a signed-in user who can access them. Quarantined files are refused, and every download is
+// recorded in the audit log.
 func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "Download")
 	defer span.End()
@@ -119,7 +140,18 @@ func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get file with data from database
+	var size FileVariantSize
+	sizeStr := r.URL.Query().Get("size")
+	if sizeStr != "" {
+		var err error
+		size, err = ParseVariantSize(sizeStr)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+	}
+
+	// Get file record from database
 	fileInfo, err := h.store.Get(traceCtx, fileID)
 	if err != nil {
 		logger.Warn("Failed to get file", zap.Error(err), zap.String("file_id", fileIDStr))
@@ -128,14 +160,177 @@ func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set headers
-	w.Header().Set("Content-Type", fileInfo.ContentType)
-	w.Header().Set("Content-Disposition", "attachment; filename=\""+fileInfo.OriginalFilename+"\"")
-	w.Header().Set("Content-Length", strconv.FormatInt(fileInfo.Size, 10))
+	download, err := h.authorizeDownload(traceCtx, r, fileInfo)
+	if err != nil {
+		logger.Warn("Refused to download file", zap.Error(err), zap.String("file_id", fileIDStr))
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	if IsQuarantined(fileInfo.ScanStatus) {
+		logger.Warn("Refused to download quarantined file", zap.String("file_id", fileIDStr), zap.String("scan_status", string(fileInfo.ScanStatus)))
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrFileQuarantined, logger)
+		return
+	}
+
+	var variant *FileVariant
+	if size != "" {
+		found, err := h.store.GetVariant(traceCtx, fileID, size)
+		if err == nil {
+			variant = &found
+			download.VariantSize = NullFileVariantSize{FileVariantSize: size, Valid: true}
+		} else if !errors.Is(err, internal.ErrFileVariantNotFound) {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			span.RecordError(err)
+			return
+		}
+	}
+
+	// Downloads that cannot be recorded are refused, so the audit log misses none
+	err = h.store.RecordDownload(traceCtx, download)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		span.RecordError(err)
+		return
+	}
+
+	if variant != nil {
+		content, err := h.store.OpenVariant(traceCtx, *variant)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			span.RecordError(err)
+			return
+		}
+
+		h.serveContent(w, r, logger, content, variant.ContentType, variantFilename(fileInfo.OriginalFilename, *variant), variant.Checksum, variant.CreatedAt.Time, fileInfo.IsPublic)
+		return
+	}
+
+	content, err := h.store.Open(traceCtx, fileInfo)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		span.RecordError(err)
+		return
+	}
+
+	h.serveContent(w, r, logger, content, fileInfo.ContentType, fileInfo.OriginalFilename, fileInfo.Checksum.String, fileInfo.CreatedAt.Time, fileInfo.IsPublic)
+}
+
+// authorizeDownload checks that a request may download a file and returns the record of the download
+// for the audit log. A signature, if present, has to be valid even for public files.
+func (h *Handler) authorizeDownload(ctx context.Context, r *http.Request, file File) (CreateDownloadParams, error) {
+	download := CreateDownloadParams{
+		FileID:    file.ID,
+		IpAddress: pgtype.Text{String: r.RemoteAddr, Valid: r.RemoteAddr != ""},
+		UserAgent: pgtype.Text{String: r.UserAgent(), Valid: r.UserAgent() != ""},
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		download.IpAddress.String = host
+	}
+
+	if IsSigned(r.URL.Query()) {
+		userID, err := h.signer.Verify(file.ID, r.URL.Query())
+		if err != nil {
+			return CreateDownloadParams{}, err
+		}
+		download.Method = FileDownloadMethodSignedUrl
+		download.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+		return download, nil
+	}
+
+	userID, signedIn := internal.GetUserIDFromContext(ctx)
+	if signedIn {
+		download.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+	}
+
+	if file.IsPublic {
+		download.Method = FileDownloadMethodPublic
+		return download, nil
+	}
+
+	if !signedIn {
+		return CreateDownloadParams{}, internal.ErrUnauthorizedError
+	}
+
+	allowed, err := h.store.CanAccess(ctx, file, userID)
+	if err != nil {
+		return CreateDownloadParams{}, err
+	}
+	if !allowed {
+		return CreateDownloadParams{}, internal.ErrPermissionDenied
+	}
+
+	download.Method = FileDownloadMethodSession
+	return download, nil
+}
+
+// CreateSignedURL handles POST /files/{id}/signed-url - issues a short-lived URL that downloads a file
+// without signing in, e.g. to share it with a tool that cannot send the session cookie
+func (h *Handler) CreateSignedURL(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateSignedURL")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUserID, ok := internal.GetUserIDFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	fileID, fileIDStr, ok := h.parseAndValidateFileID(w, r, logger)
+	if !ok {
+		return
+	}
+
+	fileInfo, err := h.store.Get(traceCtx, fileID)
+	if err != nil {
+		logger.Warn("Failed to get file", zap.Error(err), zap.String("file_id", fileIDStr))
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrFileNotFound, logger)
+		span.RecordError(err)
+		return
+	}
+
+	allowed, err := h.store.CanAccess(traceCtx, fileInfo, currentUserID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		span.RecordError(err)
+		return
+	}
+	if !allowed {
+		logger.Warn("Refused to sign a URL of a file the user cannot access", zap.String("file_id", fileIDStr))
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPermissionDenied, logger)
+		return
+	}
+
+	url, expiresAt := h.signer.Sign(fileID, currentUserID)
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, SignedURLResponse{
+		URL:       url,
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	})
+}
+
+// serveContent streams content from the blob store to the response and closes it.
+// ServeContent sets the length and handles Range and conditional requests. Files that are not public
+// must not be kept by shared caches.
+func (h *Handler) serveContent(w http.ResponseWriter, r *http.Request, logger *zap.Logger, content io.ReadSeekCloser, contentType, filename, checksum string, modified time.Time, public bool) {
+	defer func() {
+		err := content.Close()
+		if err != nil {
+			logger.Warn("Failed to close file content", zap.Error(err), zap.String("filename", filename))
+		}
+	}()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	if checksum != "" {
+		w.Header().Set("ETag", "\""+checksum+"\"")
+	}
+	if !public {
+		w.Header().Set("Cache-Control", "private")
+	}
 
-	// Stream file data from database to response
-	reader := bytes.NewReader(fileInfo.Data)
-	http.ServeContent(w, r, fileInfo.OriginalFilename, fileInfo.CreatedAt.Time, reader)
+	http.ServeContent(w, r, filename, modified, content)
 }
 
 // Get handles GET /files/{id}/info - gets file info (without binary data)
@@ -162,7 +357,7 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := toResponse(fileInfo.ID, fileInfo.OriginalFilename, fileInfo.ContentType, fileInfo.Size, fileInfo.UploadedBy, fileInfo.CreatedAt)
+	response := toResponse(fileInfo.ID, fileInfo.OriginalFilename, fileInfo.ContentType, fileInfo.Size, fileInfo.UploadedBy, fileInfo.CreatedAt, fileInfo.ScanStatus)
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
 }
@@ -246,7 +441,7 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	// Build response
 	fileResponses := make([]Response, len(files))
 	for i, f := range files {
-		fileResponses[i] = toResponse(f.ID, f.OriginalFilename, f.ContentType, f.Size, f.UploadedBy, f.CreatedAt)
+		fileResponses[i] = toResponse(f.ID, f.OriginalFilename, f.ContentType, f.Size, f.UploadedBy, f.CreatedAt, f.ScanStatus)
 	}
 
 	response := ListFilesResponse{
@@ -284,7 +479,7 @@ func (h *Handler) ListMyFiles(w http.ResponseWriter, r *http.Request) {
 	// Build response
 	fileResponses := make([]Response, len(files))
 	for i, f := range files {
-		fileResponses[i] = toResponse(f.ID, f.OriginalFilename, f.ContentType, f.Size, f.UploadedBy, f.CreatedAt)
+		fileResponses[i] = toResponse(f.ID, f.OriginalFilename, f.ContentType, f.Size, f.UploadedBy, f.CreatedAt, f.ScanStatus)
 	}
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, fileResponses)
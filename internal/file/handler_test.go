@@ -0,0 +1,87 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// accessStore lets the users in allowed access every file
+type accessStore struct {
+	Store
+	allowed map[uuid.UUID]bool
+}
+
+func (s *accessStore) CanAccess(_ context.Context, _ File, userID uuid.UUID) (bool, error) {
+	return s.allowed[userID], nil
+}
+
+// testUser identifies a signed-in user in a request context
+type testUser uuid.UUID
+
+func (u testUser) GetID() uuid.UUID {
+	return uuid.UUID(u)
+}
+
+func TestHandler_AuthorizeDownload(t *testing.T) {
+	member := uuid.New()
+	stranger := uuid.New()
+	signer := NewURLSigner("secret", time.Minute)
+	handler := NewHandler(zap.NewNop(), nil, nil, &accessStore{allowed: map[uuid.UUID]bool{member: true}}, signer)
+
+	private := File{ID: uuid.New()}
+	public := File{ID: uuid.New(), IsPublic: true}
+	signedURL, _ := signer.Sign(private.ID, member)
+	otherFileURL, _ := signer.Sign(public.ID, member)
+
+	testCases := []struct {
+		name           string
+		file           File
+		target         string
+		user           *uuid.UUID
+		expectedMethod FileDownloadMethod
+		expectedUser   *uuid.UUID
+		expectedErr    error
+	}{
+		{name: "Public file without signing in", file: public, target: "/api/files/x", expectedMethod: FileDownloadMethodPublic},
+		{name: "Public file of a signed-in user", file: public, target: "/api/files/x", user: &stranger, expectedMethod: FileDownloadMethodPublic, expectedUser: &stranger},
+		{name: "Private file without signing in", file: private, target: "/api/files/x", expectedErr: internal.ErrUnauthorizedError},
+		{name: "Private file of a user who can access it", file: private, target: "/api/files/x", user: &member, expectedMethod: FileDownloadMethodSession, expectedUser: &member},
+		{name: "Private file of a user who cannot access it", file: private, target: "/api/files/x", user: &stranger, expectedErr: internal.ErrPermissionDenied},
+		{name: "Signed URL", file: private, target: signedURL, expectedMethod: FileDownloadMethodSignedUrl, expectedUser: &member},
+		{name: "Signed URL of another file", file: private, target: otherFileURL, user: &member, expectedErr: internal.ErrInvalidSignedURL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.target, nil)
+			ctx := r.Context()
+			if tc.user != nil {
+				ctx = context.WithValue(ctx, internal.UserContextKey, testUser(*tc.user))
+			}
+
+			download, err := handler.authorizeDownload(ctx, r, tc.file)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.file.ID, download.FileID)
+			require.Equal(t, tc.expectedMethod, download.Method)
+			require.Equal(t, "192.0.2.1", download.IpAddress.String)
+
+			expectedUser := pgtype.UUID{}
+			if tc.expectedUser != nil {
+				expectedUser = pgtype.UUID{Bytes: *tc.expectedUser, Valid: true}
+			}
+			require.Equal(t, expectedUser, download.UserID)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JPEG markers the metadata stripper acts on
+const (
+	markerTEM   = 0x01
+	markerRST0  = 0xD0
+	markerRST7  = 0xD7
+	markerSOI   = 0xD8
+	markerEOI   = 0xD9
+	markerSOS   = 0xDA
+	markerAPP1  = 0xE1
+	markerAPP13 = 0xED
+	markerCOM   = 0xFE
+)
+
+// exifTagOrientation is the EXIF tag holding how the image has to be turned to be shown upright
+const exifTagOrientation = 0x0112
+
+var (
+	exifHeader   = []byte("Exif\x00\x00")
+	pngSignature = []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
+)
+
+// metadataStripper is an image stream with its metadata, such as the camera and GPS location, left out
+type metadataStripper interface {
+	io.Reader
+	// Orientation returns the EXIF orientation of the image, 1 if it has none. It is known once the stream has been read.
+	Orientation() int
+}
+
+// newMetadataStripper returns a reader of the image in r without its metadata, or nil if images of
+// mediaType are stored as they are
+func newMetadataStripper(r io.Reader, mediaType string) metadataStripper {
+	switch mediaType {
+	case "image/jpeg":
+		return &jpegStripper{src: r}
+	case "image/png":
+		return &pngStripper{src: r}
+	}
+	return nil
+}
+
+// jpegStripper copies a JPEG without its APP1 (EXIF and XMP), APP13 (IPTC) and comment segments.
+// The orientation is kept in a minimal EXIF segment of its own, so the image is still shown upright.
+// Segments are parsed up to the start of the scan; the compressed image data is copied as it is.
+type jpegStripper struct {
+	src         io.Reader
+	pending     []byte
+	started     bool
+	scanning    bool
+	orientation int
+}
+
+func (s *jpegStripper) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 && !s.scanning {
+		err := s.next()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(s.pending) > 0 {
+		n := copy(p, s.pending)
+		s.pending = s.pending[n:]
+		return n, nil
+	}
+	return s.src.Read(p)
+}
+
+func (s *jpegStripper) Orientation() int {
+	if s.orientation == 0 {
+		return 1
+	}
+	return s.orientation
+}
+
+// next reads the next segment and queues what is kept of it
+func (s *jpegStripper) next() error {
+	if !s.started {
+		soi := make([]byte, 2)
+		err := readImage(s.src, soi)
+		if err != nil {
+			return err
+		}
+		if soi[0] != 0xFF || soi[1] != markerSOI {
+			return fmt.Errorf("%w: missing JPEG start of image", internal.ErrInvalidImageFormat)
+		}
+		s.started = true
+		s.pending = soi
+		return nil
+	}
+
+	marker := make([]byte, 2)
+	err := readImage(s.src, marker)
+	if err != nil {
+		return err
+	}
+	if marker[0] != 0xFF {
+		return fmt.Errorf("%w: invalid JPEG marker", internal.ErrInvalidImageFormat)
+	}
+	// Markers may be preceded by any number of fill bytes
+	for marker[1] == 0xFF {
+		err = readImage(s.src, marker[1:])
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case marker[1] == markerEOI:
+		s.pending = marker
+		s.scanning = true
+		return nil
+	case marker[1] == markerTEM || marker[1] >= markerRST0 && marker[1] <= markerRST7:
+		// Markers without a segment
+		s.pending = marker
+		return nil
+	}
+
+	length := make([]byte, 2)
+	err = readImage(s.src, length)
+	if err != nil {
+		return err
+	}
+	size := int(binary.BigEndian.Uint16(length))
+	if size < 2 {
+		return fmt.Errorf("%w: invalid JPEG segment length", internal.ErrInvalidImageFormat)
+	}
+	payload := make([]byte, size-2)
+	err = readImage(s.src, payload)
+	if err != nil {
+		return err
+	}
+
+	switch marker[1] {
+	case markerAPP1:
+		if s.orientation == 0 && bytes.HasPrefix(payload, exifHeader) {
+			s.orientation = exifOrientation(payload[len(exifHeader):])
+			if s.orientation > 1 {
+				s.pending = orientationSegment(s.orientation)
+			}
+		}
+		return nil
+	case markerAPP13, markerCOM:
+		return nil
+	}
+
+	s.pending = append(append(marker, length...), payload...)
+	if marker[1] == markerSOS {
+		s.scanning = true
+	}
+	return nil
+}
+
+// exifOrientation returns the orientation in the TIFF structure of EXIF data, or 0 if it has none
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > int64(len(tiff)) {
+		return 0
+	}
+	count := int64(order.Uint16(tiff[ifd:]))
+	for i := int64(0); i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > int64(len(tiff)) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) != exifTagOrientation {
+			continue
+		}
+
+		// A SHORT whose value sits in the first two bytes of the value field
+		if order.Uint16(tiff[entry+2:]) != 3 {
+			return 0
+		}
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 0
+		}
+		return orientation
+	}
+
+	return 0
+}
+
+// orientationSegment returns an APP1 segment with EXIF data holding nothing but the orientation
+func orientationSegment(orientation int) []byte {
+	payload := append(bytes.Clone(exifHeader),
+		'M', 'M', 0x00, 0x2A, // big-endian TIFF header
+		0x00, 0x00, 0x00, 0x08, // first IFD right after the header
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00, // Orientation, SHORT, 1 value
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	)
+
+	segment := []byte{0xFF, markerAPP1, 0x00, 0x00}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// pngStripper copies a PNG without its text chunks (tEXt, zTXt and iTXt), its EXIF chunk (eXIf) and
+// anything after its last chunk. Kept chunks are copied as they are, without being held in memory.
+type pngStripper struct {
+	src     io.Reader
+	pending []byte
+	started bool
+	// remaining is the number of bytes of the current chunk left to copy
+	remaining int64
+	done      bool
+}
+
+func (s *pngStripper) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 && s.remaining == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		err := s.next()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(s.pending) > 0 {
+		n := copy(p, s.pending)
+		s.pending = s.pending[n:]
+		return n, nil
+	}
+
+	if int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+	n, err := s.src.Read(p)
+	s.remaining -= int64(n)
+	if errors.Is(err, io.EOF) {
+		if s.remaining > 0 {
+			return n, fmt.Errorf("%w: image ends unexpectedly", internal.ErrInvalidImageFormat)
+		}
+		err = nil
+	}
+	return n, err
+}
+
+func (s *pngStripper) Orientation() int {
+	return 1
+}
+
+// next reads the header of the next chunk and queues it if the chunk is kept
+func (s *pngStripper) next() error {
+	if !s.started {
+		signature := make([]byte, len(pngSignature))
+		err := readImage(s.src, signature)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(signature, pngSignature) {
+			return fmt.Errorf("%w: missing PNG signature", internal.ErrInvalidImageFormat)
+		}
+		s.started = true
+		s.pending = signature
+		return nil
+	}
+
+	header := make([]byte, 8)
+	err := readImage(s.src, header)
+	if err != nil {
+		return err
+	}
+	length := int64(binary.BigEndian.Uint32(header))
+	if length > 1<<31-1 {
+		return fmt.Errorf("%w: invalid PNG chunk length", internal.ErrInvalidImageFormat)
+	}
+
+	switch string(header[4:]) {
+	case "tEXt", "zTXt", "iTXt", "eXIf":
+		// Skip the data and the CRC
+		_, err = io.CopyN(io.Discard, s.src, length+4)
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: image ends unexpectedly", internal.ErrInvalidImageFormat)
+		}
+		return err
+	case "IEND":
+		s.done = true
+	}
+
+	s.pending = header
+	s.remaining = length + 4
+	return nil
+}
+
+// readImage fills p from r; an image that ends before is not a valid image
+func readImage(r io.Reader, p []byte) error {
+	_, err := io.ReadFull(r, p)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: image ends unexpectedly", internal.ErrInvalidImageFormat)
+	}
+	return err
+}
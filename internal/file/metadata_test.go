@@ -0,0 +1,152 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: uint8(y * 32), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// exifSegment returns an APP1 segment with the orientation and a GPS latitude reference in its EXIF data
+func exifSegment(orientation int) []byte {
+	tiff := []byte{
+		'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00,
+		0x02, 0x00, // two entries
+		0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, byte(orientation), 0x00, 0x00, 0x00,
+		0x25, 0x88, 0x04, 0x00, 0x01, 0x00, 0x00, 0x00, 0x26, 0x00, 0x00, 0x00, // GPS IFD pointer
+		0x00, 0x00, 0x00, 0x00,
+	}
+	payload := append(append([]byte("Exif\x00\x00"), tiff...), []byte("GPS 24.7868N 120.9967E")...)
+
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func TestJPEGStripper(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, testImage(), nil))
+	clean := encoded.Bytes()
+
+	comment := append([]byte{0xFF, 0xFE, 0x00, 0x0B}, []byte("Camera X1")...)
+	withMetadata := bytes.Join([][]byte{clean[:2], exifSegment(6), comment, clean[2:]}, nil)
+
+	testCases := []struct {
+		name                string
+		data                []byte
+		expected            []byte
+		expectedOrientation int
+	}{
+		{
+			name:                "Image without metadata is copied as it is",
+			data:                clean,
+			expected:            clean,
+			expectedOrientation: 1,
+		},
+		{
+			name:                "EXIF and comments are replaced by the orientation",
+			data:                withMetadata,
+			expected:            bytes.Join([][]byte{clean[:2], orientationSegment(6), clean[2:]}, nil),
+			expectedOrientation: 6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stripper := newMetadataStripper(bytes.NewReader(tc.data), "image/jpeg")
+			stripped, err := io.ReadAll(stripper)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, stripped)
+			require.Equal(t, tc.expectedOrientation, stripper.Orientation())
+			require.NotContains(t, string(stripped), "GPS")
+
+			_, err = jpeg.Decode(bytes.NewReader(stripped))
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedOrientation, exifOrientationOf(t, stripped))
+		})
+	}
+}
+
+// exifOrientationOf reads the orientation back from the first EXIF segment of a JPEG
+func exifOrientationOf(t *testing.T, data []byte) int {
+	index := bytes.Index(data, exifHeader)
+	if index < 0 {
+		return 1
+	}
+	orientation := exifOrientation(data[index+len(exifHeader):])
+	require.NotZero(t, orientation)
+	return orientation
+}
+
+// pngChunk encodes a PNG chunk with its CRC
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestPNGStripper(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, testImage()))
+	clean := encoded.Bytes()
+
+	// The IHDR chunk follows the signature and is 25 bytes long
+	headerEnd := len(pngSignature) + 25
+	withMetadata := bytes.Join([][]byte{
+		clean[:headerEnd],
+		pngChunk("tEXt", []byte("Author\x00Alice")),
+		pngChunk("eXIf", []byte("MM\x00\x2A\x00\x00\x00\x08\x00\x00")),
+		clean[headerEnd:],
+		[]byte("trailing data"),
+	}, nil)
+
+	stripper := newMetadataStripper(bytes.NewReader(withMetadata), "image/png")
+	stripped, err := io.ReadAll(stripper)
+	require.NoError(t, err)
+	require.Equal(t, clean, stripped)
+
+	_, err = png.Decode(bytes.NewReader(stripped))
+	require.NoError(t, err)
+}
+
+func TestMetadataStripper_Truncated(t *testing.T) {
+	var encodedJPEG, encodedPNG bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encodedJPEG, testImage(), nil))
+	require.NoError(t, png.Encode(&encodedPNG, testImage()))
+
+	testCases := []struct {
+		name      string
+		mediaType string
+		data      []byte
+	}{
+		{name: "JPEG ending in a segment", mediaType: "image/jpeg", data: encodedJPEG.Bytes()[:10]},
+		{name: "PNG ending in a chunk", mediaType: "image/png", data: encodedPNG.Bytes()[:40]},
+		{name: "PNG without its last chunk", mediaType: "image/png", data: encodedPNG.Bytes()[:encodedPNG.Len()-12]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := io.ReadAll(newMetadataStripper(bytes.NewReader(tc.data), tc.mediaType))
+			require.ErrorIs(t, err, internal.ErrInvalidImageFormat)
+		})
+	}
+}
@@ -1,27 +1,28 @@
 -- name: Create :one
-INSERT INTO files (original_filename, content_type, size, data, uploaded_by)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO files (original_filename, content_type, size, storage_key, checksum, uploaded_by, scan_status, is_public)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: Get :one
 SELECT * FROM files WHERE id = $1;
 
 -- name: GetMetadata :one
-SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at 
+SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at, scan_status
 FROM files WHERE id = $1;
 
 -- name: GetByUploadedBy :many
-SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at 
+SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at, scan_status
 FROM files WHERE uploaded_by = $1 ORDER BY created_at DESC;
 
--- name: Delete :exec
-DELETE FROM files WHERE id = $1;
+-- name: Delete :one
+DELETE FROM files WHERE id = $1
+RETURNING storage_key;
 
 -- name: Exists :one
 SELECT EXISTS(SELECT 1 FROM files WHERE id = $1);
 
 -- name: GetAll :many
-SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at 
+SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at, scan_status
 FROM files ORDER BY created_at DESC LIMIT $1 OFFSET $2;
 
 -- name: Count :one
@@ -66,4 +67,49 @@ WHERE file_id = $1;
 SELECT id
 FROM files
 WHERE id = $1
-FOR UPDATE;
\ No newline at end of file
+FOR UPDATE;
+
+-- name: ListWithoutStorageKey :many
+-- Files created before contents moved to the blob store, in pages after the last ID
+SELECT id FROM files
+WHERE storage_key IS NULL AND id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: GetLegacyData :one
+SELECT data, content_type FROM files WHERE id = $1;
+
+-- name: MoveToStorage :execrows
+UPDATE files
+SET storage_key = $2, checksum = $3, data = NULL, updated_at = now()
+WHERE id = $1 AND storage_key IS NULL;
+
+-- name: CreateVariant :one
+INSERT INTO file_variants (file_id, size, content_type, byte_size, width, height, storage_key, checksum)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetVariant :one
+SELECT * FROM file_variants WHERE file_id = $1 AND size = $2;
+
+-- name: DeleteVariants :many
+-- Returns the storage keys of the deleted variants, so their contents can be removed as well
+DELETE FROM file_variants WHERE file_id = $1
+RETURNING storage_key;
+
+-- name: UpdateScanResult :one
+UPDATE files
+SET scan_status = $2, scan_signature = $3, scanned_at = now(), updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ListQuarantinedForRescan :many
+-- Files whose scan is still pending or failed, last touched before the given time
+SELECT * FROM files
+WHERE scan_status IN ('pending', 'failed') AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2;
+
+-- name: CreateDownload :exec
+INSERT INTO file_downloads (file_id, user_id, method, variant_size, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6);
@@ -24,17 +24,20 @@ func (q *Queries) Count(ctx context.Context) (int64, error) {
 }
 
 const create = `-- name: Create :one
-INSERT INTO files (original_filename, content_type, size, data, uploaded_by)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, original_filename, content_type, size, data, uploaded_by, created_at, updated_at
+INSERT INTO files (original_filename, content_type, size, storage_key, checksum, uploaded_by, scan_status, is_public)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, original_filename, content_type, size, data, storage_key, checksum, uploaded_by, created_at, updated_at, scan_status, scan_signature, scanned_at, is_public
 `
 
 type CreateParams struct {
 	OriginalFilename string
 	ContentType      string
 	Size             int64
-	Data             []byte
+	StorageKey       pgtype.Text
+	Checksum         pgtype.Text
 	UploadedBy       pgtype.UUID
+	ScanStatus       FileScanStatus
+	IsPublic         bool
 }
 
 func (q *Queries) Create(ctx context.Context, arg CreateParams) (File, error) {
@@ -42,8 +45,11 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (File, error) {
 		arg.OriginalFilename,
 		arg.ContentType,
 		arg.Size,
-		arg.Data,
+		arg.StorageKey,
+		arg.Checksum,
 		arg.UploadedBy,
+		arg.ScanStatus,
+		arg.IsPublic,
 	)
 	var i File
 	err := row.Scan(
@@ -52,9 +58,15 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (File, error) {
 		&i.ContentType,
 		&i.Size,
 		&i.Data,
+		&i.StorageKey,
+		&i.Checksum,
 		&i.UploadedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.IsPublic,
 	)
 	return i, err
 }
@@ -91,15 +103,87 @@ func (q *Queries) CreateAttachment(ctx context.Context, arg CreateAttachmentPara
 	return i, err
 }
 
-const delete = `-- name: Delete :exec
-DELETE FROM files WHERE id = $1
+const createDownload = `-- name: CreateDownload :exec
+INSERT INTO file_downloads (file_id, user_id, method, variant_size, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5, $6)
 `
 
-func (q *Queries) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, delete, id)
+type CreateDownloadParams struct {
+	FileID      uuid.UUID
+	UserID      pgtype.UUID
+	Method      FileDownloadMethod
+	VariantSize NullFileVariantSize
+	IpAddress   pgtype.Text
+	UserAgent   pgtype.Text
+}
+
+func (q *Queries) CreateDownload(ctx context.Context, arg CreateDownloadParams) error {
+	_, err := q.db.Exec(ctx, createDownload,
+		arg.FileID,
+		arg.UserID,
+		arg.Method,
+		arg.VariantSize,
+		arg.IpAddress,
+		arg.UserAgent,
+	)
 	return err
 }
 
+const createVariant = `-- name: CreateVariant :one
+INSERT INTO file_variants (file_id, size, content_type, byte_size, width, height, storage_key, checksum)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING file_id, size, content_type, byte_size, width, height, storage_key, checksum, created_at
+`
+
+type CreateVariantParams struct {
+	FileID      uuid.UUID
+	Size        FileVariantSize
+	ContentType string
+	ByteSize    int64
+	Width       int32
+	Height      int32
+	StorageKey  string
+	Checksum    string
+}
+
+func (q *Queries) CreateVariant(ctx context.Context, arg CreateVariantParams) (FileVariant, error) {
+	row := q.db.QueryRow(ctx, createVariant,
+		arg.FileID,
+		arg.Size,
+		arg.ContentType,
+		arg.ByteSize,
+		arg.Width,
+		arg.Height,
+		arg.StorageKey,
+		arg.Checksum,
+	)
+	var i FileVariant
+	err := row.Scan(
+		&i.FileID,
+		&i.Size,
+		&i.ContentType,
+		&i.ByteSize,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.Checksum,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const delete = `-- name: Delete :one
+DELETE FROM files WHERE id = $1
+RETURNING storage_key
+`
+
+func (q *Queries) Delete(ctx context.Context, id uuid.UUID) (pgtype.Text, error) {
+	row := q.db.QueryRow(ctx, delete, id)
+	var storage_key pgtype.Text
+	err := row.Scan(&storage_key)
+	return storage_key, err
+}
+
 const deleteAttachment = `-- name: DeleteAttachment :exec
 DELETE FROM file_attachments WHERE id = $1
 `
@@ -119,6 +203,32 @@ func (q *Queries) DeleteAttachmentsByFileID(ctx context.Context, fileID uuid.UUI
 	return err
 }
 
+const deleteVariants = `-- name: DeleteVariants :many
+DELETE FROM file_variants WHERE file_id = $1
+RETURNING storage_key
+`
+
+// Returns the storage keys of the deleted variants, so their contents can be removed as well
+func (q *Queries) DeleteVariants(ctx context.Context, fileID uuid.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, deleteVariants, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var storage_key string
+		if err := rows.Scan(&storage_key); err != nil {
+			return nil, err
+		}
+		items = append(items, storage_key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const exists = `-- name: Exists :one
 SELECT EXISTS(SELECT 1 FROM files WHERE id = $1)
 `
@@ -151,7 +261,7 @@ func (q *Queries) ExistsAttachmentByFileAndResource(ctx context.Context, arg Exi
 }
 
 const get = `-- name: Get :one
-SELECT id, original_filename, content_type, size, data, uploaded_by, created_at, updated_at FROM files WHERE id = $1
+SELECT id, original_filename, content_type, size, data, storage_key, checksum, uploaded_by, created_at, updated_at, scan_status, scan_signature, scanned_at, is_public FROM files WHERE id = $1
 `
 
 func (q *Queries) Get(ctx context.Context, id uuid.UUID) (File, error) {
@@ -163,15 +273,21 @@ func (q *Queries) Get(ctx context.Context, id uuid.UUID) (File, error) {
 		&i.ContentType,
 		&i.Size,
 		&i.Data,
+		&i.StorageKey,
+		&i.Checksum,
 		&i.UploadedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.IsPublic,
 	)
 	return i, err
 }
 
 const getAll = `-- name: GetAll :many
-SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at 
+SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at, scan_status
 FROM files ORDER BY created_at DESC LIMIT $1 OFFSET $2
 `
 
@@ -188,6 +304,7 @@ type GetAllRow struct {
 	UploadedBy       pgtype.UUID
 	CreatedAt        pgtype.Timestamptz
 	UpdatedAt        pgtype.Timestamptz
+	ScanStatus       FileScanStatus
 }
 
 func (q *Queries) GetAll(ctx context.Context, arg GetAllParams) ([]GetAllRow, error) {
@@ -207,6 +324,7 @@ func (q *Queries) GetAll(ctx context.Context, arg GetAllParams) ([]GetAllRow, er
 			&i.UploadedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ScanStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -265,7 +383,7 @@ func (q *Queries) GetAttachmentByFileAndResource(ctx context.Context, arg GetAtt
 }
 
 const getByUploadedBy = `-- name: GetByUploadedBy :many
-SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at 
+SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at, scan_status
 FROM files WHERE uploaded_by = $1 ORDER BY created_at DESC
 `
 
@@ -277,6 +395,7 @@ type GetByUploadedByRow struct {
 	UploadedBy       pgtype.UUID
 	CreatedAt        pgtype.Timestamptz
 	UpdatedAt        pgtype.Timestamptz
+	ScanStatus       FileScanStatus
 }
 
 func (q *Queries) GetByUploadedBy(ctx context.Context, uploadedBy pgtype.UUID) ([]GetByUploadedByRow, error) {
@@ -296,6 +415,7 @@ func (q *Queries) GetByUploadedBy(ctx context.Context, uploadedBy pgtype.UUID) (
 			&i.UploadedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ScanStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -307,8 +427,24 @@ func (q *Queries) GetByUploadedBy(ctx context.Context, uploadedBy pgtype.UUID) (
 	return items, nil
 }
 
+const getLegacyData = `-- name: GetLegacyData :one
+SELECT data, content_type FROM files WHERE id = $1
+`
+
+type GetLegacyDataRow struct {
+	Data        []byte
+	ContentType string
+}
+
+func (q *Queries) GetLegacyData(ctx context.Context, id uuid.UUID) (GetLegacyDataRow, error) {
+	row := q.db.QueryRow(ctx, getLegacyData, id)
+	var i GetLegacyDataRow
+	err := row.Scan(&i.Data, &i.ContentType)
+	return i, err
+}
+
 const getMetadata = `-- name: GetMetadata :one
-SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at 
+SELECT id, original_filename, content_type, size, uploaded_by, created_at, updated_at, scan_status
 FROM files WHERE id = $1
 `
 
@@ -320,6 +456,7 @@ type GetMetadataRow struct {
 	UploadedBy       pgtype.UUID
 	CreatedAt        pgtype.Timestamptz
 	UpdatedAt        pgtype.Timestamptz
+	ScanStatus       FileScanStatus
 }
 
 func (q *Queries) GetMetadata(ctx context.Context, id uuid.UUID) (GetMetadataRow, error) {
@@ -333,6 +470,33 @@ func (q *Queries) GetMetadata(ctx context.Context, id uuid.UUID) (GetMetadataRow
 		&i.UploadedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ScanStatus,
+	)
+	return i, err
+}
+
+const getVariant = `-- name: GetVariant :one
+SELECT file_id, size, content_type, byte_size, width, height, storage_key, checksum, created_at FROM file_variants WHERE file_id = $1 AND size = $2
+`
+
+type GetVariantParams struct {
+	FileID uuid.UUID
+	Size   FileVariantSize
+}
+
+func (q *Queries) GetVariant(ctx context.Context, arg GetVariantParams) (FileVariant, error) {
+	row := q.db.QueryRow(ctx, getVariant, arg.FileID, arg.Size)
+	var i FileVariant
+	err := row.Scan(
+		&i.FileID,
+		&i.Size,
+		&i.ContentType,
+		&i.ByteSize,
+		&i.Width,
+		&i.Height,
+		&i.StorageKey,
+		&i.Checksum,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -405,6 +569,87 @@ func (q *Queries) ListAttachmentsByResource(ctx context.Context, arg ListAttachm
 	return items, nil
 }
 
+const listQuarantinedForRescan = `-- name: ListQuarantinedForRescan :many
+SELECT id, original_filename, content_type, size, data, storage_key, checksum, uploaded_by, created_at, updated_at, scan_status, scan_signature, scanned_at, is_public FROM files
+WHERE scan_status IN ('pending', 'failed') AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2
+`
+
+type ListQuarantinedForRescanParams struct {
+	UpdatedAt pgtype.Timestamptz
+	Limit     int32
+}
+
+// Files whose scan is still pending or failed, last touched before the given time
+func (q *Queries) ListQuarantinedForRescan(ctx context.Context, arg ListQuarantinedForRescanParams) ([]File, error) {
+	rows, err := q.db.Query(ctx, listQuarantinedForRescan, arg.UpdatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []File
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalFilename,
+			&i.ContentType,
+			&i.Size,
+			&i.Data,
+			&i.StorageKey,
+			&i.Checksum,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ScanStatus,
+			&i.ScanSignature,
+			&i.ScannedAt,
+			&i.IsPublic,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWithoutStorageKey = `-- name: ListWithoutStorageKey :many
+SELECT id FROM files
+WHERE storage_key IS NULL AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListWithoutStorageKeyParams struct {
+	ID    uuid.UUID
+	Limit int32
+}
+
+// Files created before contents moved to the blob store, in pages after the last ID
+func (q *Queries) ListWithoutStorageKey(ctx context.Context, arg ListWithoutStorageKeyParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listWithoutStorageKey, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const lockFile = `-- name: LockFile :one
 SELECT id
 FROM files
@@ -418,3 +663,58 @@ func (q *Queries) LockFile(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	err := row.Scan(&id_2)
 	return id_2, err
 }
+
+const moveToStorage = `-- name: MoveToStorage :execrows
+UPDATE files
+SET storage_key = $2, checksum = $3, data = NULL, updated_at = now()
+WHERE id = $1 AND storage_key IS NULL
+`
+
+type MoveToStorageParams struct {
+	ID         uuid.UUID
+	StorageKey pgtype.Text
+	Checksum   pgtype.Text
+}
+
+func (q *Queries) MoveToStorage(ctx context.Context, arg MoveToStorageParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveToStorage, arg.ID, arg.StorageKey, arg.Checksum)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateScanResult = `-- name: UpdateScanResult :one
+UPDATE files
+SET scan_status = $2, scan_signature = $3, scanned_at = now(), updated_at = now()
+WHERE id = $1
+RETURNING id, original_filename, content_type, size, data, storage_key, checksum, uploaded_by, created_at, updated_at, scan_status, scan_signature, scanned_at, is_public
+`
+
+type UpdateScanResultParams struct {
+	ID            uuid.UUID
+	ScanStatus    FileScanStatus
+	ScanSignature pgtype.Text
+}
+
+func (q *Queries) UpdateScanResult(ctx context.Context, arg UpdateScanResultParams) (File, error) {
+	row := q.db.QueryRow(ctx, updateScanResult, arg.ID, arg.ScanStatus, arg.ScanSignature)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalFilename,
+		&i.ContentType,
+		&i.Size,
+		&i.Data,
+		&i.StorageKey,
+		&i.Checksum,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ScanStatus,
+		&i.ScanSignature,
+		&i.ScannedAt,
+		&i.IsPublic,
+	)
+	return i, err
+}
@@ -0,0 +1,25 @@
+package file
+
+import (
+	"context"
+	"io"
+)
+
+// Scanner checks the contents of uploaded files for malware
+type Scanner interface {
+	// Scan reads r to its end and returns the name of the malware found in it, or "" if it is clean
+	Scan(ctx context.Context, r io.Reader) (string, error)
+}
+
+// IsQuarantined reports whether the content of a file is withheld because the scanner has not found it clean:
+// its scan is still pending, has failed, or found malware
+func IsQuarantined(status FileScanStatus) bool {
+	switch status {
+	case FileScanStatusPending, FileScanStatusInfected, FileScanStatusFailed:
+		return true
+	}
+	return false
+}
+
+// rescanBatchSize is the number of quarantined files RunRescans scans each interval
+const rescanBatchSize = 100
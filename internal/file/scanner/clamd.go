@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the chunks content is streamed to clamd in. It has to stay below the
+// StreamMaxLength of the daemon, which defaults to 25 MB.
+const clamdChunkSize = 64 * 1024
+
+// clamdTimeout bounds a scan whose context has no deadline, so a stuck daemon cannot hold an upload forever
+const clamdTimeout = 5 * time.Minute
+
+// Clamd scans content with the ClamAV daemon over TCP, using the INSTREAM command
+type Clamd struct {
+	address string
+	dialer  net.Dialer
+}
+
+func NewClamd(address string) *Clamd {
+	return &Clamd{
+		address: address,
+		dialer:  net.Dialer{Timeout: 10 * time.Second},
+	}
+}
+
+// Ping checks that the daemon is reachable
+func (c *Clamd) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected clamd reply to PING: %q", reply)
+	}
+	return nil
+}
+
+// Scan streams r to the daemon and returns the signature of the malware it found, or "" if r is clean
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (string, error) {
+	reply, err := c.command(ctx, "INSTREAM", r)
+	if err != nil {
+		return "", err
+	}
+
+	// Replies are "stream: OK", "stream: <signature> FOUND" or "<message> ERROR"
+	result, ok := strings.CutPrefix(reply, "stream: ")
+	switch {
+	case ok && result == "OK":
+		return "", nil
+	case ok && strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return "", fmt.Errorf("clamd failed to scan stream: %s", reply)
+	}
+}
+
+// command sends a command to the daemon, followed by the content of stream as INSTREAM chunks if it is not
+// nil, and returns the reply of the daemon
+func (c *Clamd) command(ctx context.Context, name string, stream io.Reader) (string, error) {
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(clamdTimeout)
+	}
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return "", err
+	}
+
+	// Unblock reads and writes as soon as ctx is canceled
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	// The z prefix makes the daemon terminate its reply with a null byte rather than a newline
+	_, err = io.WriteString(conn, "z"+name+"\x00")
+	if err == nil && stream != nil {
+		err = writeChunks(conn, stream)
+	}
+
+	var opErr *net.OpError
+	if err != nil && !errors.As(err, &opErr) {
+		// Reading the content failed rather than the connection
+		return "", err
+	}
+
+	// The daemon closes the connection when the stream exceeds its limit and explains why in its reply,
+	// so the reply is read even if writing failed
+	reply, readErr := bufio.NewReader(conn).ReadString(0)
+	if readErr != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to send %s to clamd: %w", name, err)
+		}
+		return "", fmt.Errorf("failed to read clamd reply to %s: %w", name, readErr)
+	}
+
+	reply = strings.TrimSuffix(reply, "\x00")
+	if err != nil {
+		return "", fmt.Errorf("failed to send %s to clamd: %s", name, reply)
+	}
+	return reply, nil
+}
+
+// writeChunks writes stream as INSTREAM chunks, each prefixed by its length as a 4-byte big-endian
+// integer, followed by the zero-length chunk that ends the stream
+func writeChunks(w io.Writer, stream io.Reader) error {
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, err := io.ReadFull(stream, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			_, writeErr := w.Write(buf[:4+n])
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read content to scan: %w", err)
+		}
+	}
+
+	_, err := w.Write(bytes.Repeat([]byte{0}, 4))
+	return err
+}
@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the EICAR test file, which every scanner reports as malware
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd answers PING and INSTREAM like clamd, reporting streams containing the EICAR test file as infected
+type fakeClamd struct {
+	listener net.Listener
+	// maxStream makes streams longer than it fail like the StreamMaxLength of clamd, if it is not zero
+	maxStream int
+	// hang makes the daemon read the command and never reply
+	hang bool
+}
+
+// startFakeClamd starts serving f on a local port
+func startFakeClamd(t *testing.T, f *fakeClamd) *fakeClamd {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	f.listener = listener
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeClamd) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	command, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+	if f.hang {
+		_, _ = io.Copy(io.Discard, reader)
+		return
+	}
+
+	switch command {
+	case "zPING\x00":
+		_, _ = io.WriteString(conn, "PONG\x00")
+	case "zINSTREAM\x00":
+		var stream bytes.Buffer
+		for {
+			var size uint32
+			err = binary.Read(reader, binary.BigEndian, &size)
+			if err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if f.maxStream > 0 && stream.Len()+int(size) > f.maxStream {
+				_, _ = io.WriteString(conn, "INSTREAM size limit exceeded. ERROR\x00")
+				return
+			}
+			_, err = io.CopyN(&stream, reader, int64(size))
+			if err != nil {
+				return
+			}
+		}
+
+		if strings.Contains(stream.String(), eicar) {
+			_, _ = io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+		} else {
+			_, _ = io.WriteString(conn, "stream: OK\x00")
+		}
+	default:
+		_, _ = io.WriteString(conn, "UNKNOWN COMMAND\x00")
+	}
+}
+
+func TestClamd_Scan(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           []byte
+		maxStream         int
+		expectedSignature string
+		expectedErr       string
+	}{
+		{
+			name:    "Clean content",
+			content: []byte("hello world"),
+		},
+		{
+			name:    "Empty content",
+			content: nil,
+		},
+		{
+			name:              "Malware",
+			content:           []byte(eicar),
+			expectedSignature: "Eicar-Test-Signature",
+		},
+		{
+			name:              "Malware spanning chunks",
+			content:           append(bytes.Repeat([]byte{'a'}, clamdChunkSize-10), eicar...),
+			expectedSignature: "Eicar-Test-Signature",
+		},
+		{
+			name:        "Content over the stream limit of the daemon",
+			content:     bytes.Repeat([]byte{'a'}, 4*clamdChunkSize),
+			maxStream:   clamdChunkSize,
+			expectedErr: "INSTREAM size limit exceeded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			daemon := startFakeClamd(t, &fakeClamd{maxStream: tc.maxStream})
+			clamd := NewClamd(daemon.listener.Addr().String())
+
+			signature, err := clamd.Scan(context.Background(), bytes.NewReader(tc.content))
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSignature, signature)
+		})
+	}
+}
+
+func TestClamd_Ping(t *testing.T) {
+	daemon := startFakeClamd(t, &fakeClamd{})
+	require.NoError(t, NewClamd(daemon.listener.Addr().String()).Ping(context.Background()))
+}
+
+func TestClamd_Unavailable(t *testing.T) {
+	daemon := startFakeClamd(t, &fakeClamd{})
+	address := daemon.listener.Addr().String()
+	require.NoError(t, daemon.listener.Close())
+
+	_, err := NewClamd(address).Scan(context.Background(), strings.NewReader("hello"))
+	require.Error(t, err)
+}
+
+func TestClamd_Timeout(t *testing.T) {
+	daemon := startFakeClamd(t, &fakeClamd{hang: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := NewClamd(daemon.listener.Addr().String()).Scan(ctx, strings.NewReader("hello"))
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+}
+
+func TestNew(t *testing.T) {
+	scanner, err := New(Config{})
+	require.NoError(t, err)
+	require.Nil(t, scanner)
+
+	scanner, err = New(Config{Driver: DriverClamd, ClamdAddress: "localhost:3310"})
+	require.NoError(t, err)
+	require.IsType(t, &Clamd{}, scanner)
+
+	_, err = New(Config{Driver: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,31 @@
+// Package scanner provides the drivers that check the contents of uploaded files for malware
+package scanner
+
+import (
+	"fmt"
+
+	"NYCU-SDC/core-system-backend/internal/file"
+)
+
+const (
+	DriverClamd = "clamd"
+)
+
+type Config struct {
+	// Driver is DriverClamd, or empty to store files without scanning them
+	Driver string
+	// ClamdAddress is the TCP address of the daemon of the clamd driver
+	ClamdAddress string
+}
+
+// New returns the scanner of the configured driver, or nil if no driver is configured
+func New(cfg Config) (file.Scanner, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case DriverClamd:
+		return NewClamd(cfg.ClamdAddress), nil
+	default:
+		return nil, fmt.Errorf("unknown file scanner driver %q", cfg.Driver)
+	}
+}
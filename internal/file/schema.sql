@@ -1,18 +1,35 @@
 CREATE EXTENSION IF NOT EXISTS pgcrypto;
 
+CREATE TYPE file_scan_status AS ENUM(
+    'not_scanned',
+    'pending',
+    'clean',
+    'infected',
+    'failed'
+);
+
 CREATE TABLE IF NOT EXISTS files (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     original_filename VARCHAR(255) NOT NULL,
     content_type VARCHAR(100) NOT NULL,
     size BIGINT NOT NULL,
-    data BYTEA NOT NULL,
+    data BYTEA,
+    storage_key TEXT,
+    checksum TEXT,
     uploaded_by UUID,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    scan_status file_scan_status NOT NULL DEFAULT 'not_scanned',
+    scan_signature TEXT,
+    scanned_at TIMESTAMPTZ,
+    is_public BOOLEAN NOT NULL DEFAULT false,
+    CONSTRAINT files_storage_key_or_data CHECK (storage_key IS NOT NULL OR data IS NOT NULL)
 );
 
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_storage_key ON files(storage_key);
 CREATE INDEX IF NOT EXISTS idx_files_uploaded_by ON files(uploaded_by);
 CREATE INDEX IF NOT EXISTS idx_files_created_at ON files(created_at);
+CREATE INDEX IF NOT EXISTS idx_files_quarantined ON files(updated_at) WHERE scan_status IN ('pending', 'failed');
 
 CREATE TYPE resource_type AS ENUM(
     'form_answer'
@@ -30,3 +47,44 @@ CREATE TABLE IF NOT EXISTS file_attachments (
 
 CREATE INDEX IF NOT EXISTS idx_file_attachments_file_id ON file_attachments(file_id);
 CREATE INDEX IF NOT EXISTS idx_file_attachments_resource ON file_attachments(resource_type, resource_id);
+
+CREATE TYPE file_variant_size AS ENUM(
+    'small',
+    'medium',
+    'large'
+);
+
+CREATE TABLE IF NOT EXISTS file_variants (
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
+    size file_variant_size NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    byte_size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (file_id, size)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_file_variants_storage_key ON file_variants(storage_key);
+
+CREATE TYPE file_download_method AS ENUM(
+    'session',
+    'signed_url',
+    'public'
+);
+
+CREATE TABLE IF NOT EXISTS file_downloads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    method file_download_method NOT NULL,
+    variant_size file_variant_size,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_downloads_file_id ON file_downloads(file_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_file_downloads_user_id ON file_downloads(user_id);
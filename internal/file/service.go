@@ -2,12 +2,16 @@ package file
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"path"
+	"time"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -25,7 +29,7 @@ type Querier interface {
 	Get(ctx context.Context, id uuid.UUID) (File, error)
 	GetMetadata(ctx context.Context, id uuid.UUID) (GetMetadataRow, error)
 	GetByUploadedBy(ctx context.Context, uploadedBy pgtype.UUID) ([]GetByUploadedByRow, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) (pgtype.Text, error)
 	Exists(ctx context.Context, id uuid.UUID) (bool, error)
 	LockFile(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	GetAll(ctx context.Context, arg GetAllParams) ([]GetAllRow, error)
@@ -38,6 +42,15 @@ type Querier interface {
 	GetAttachment(ctx context.Context, id uuid.UUID) (FileAttachment, error)
 	DeleteAttachment(ctx context.Context, id uuid.UUID) error
 	DeleteAttachmentsByFileID(ctx context.Context, fileID uuid.UUID) error
+	ListWithoutStorageKey(ctx context.Context, arg ListWithoutStorageKeyParams) ([]uuid.UUID, error)
+	GetLegacyData(ctx context.Context, id uuid.UUID) (GetLegacyDataRow, error)
+	MoveToStorage(ctx context.Context, arg MoveToStorageParams) (int64, error)
+	CreateVariant(ctx context.Context, arg CreateVariantParams) (FileVariant, error)
+	GetVariant(ctx context.Context, arg GetVariantParams) (FileVariant, error)
+	DeleteVariants(ctx context.Context, fileID uuid.UUID) ([]string, error)
+	UpdateScanResult(ctx context.Context, arg UpdateScanResultParams) (File, error)
+	ListQuarantinedForRescan(ctx context.Context, arg ListQuarantinedForRescanParams) ([]File, error)
+	CreateDownload(ctx context.Context, arg CreateDownloadParams) error
 	WithTx(tx pgx.Tx) *Queries
 }
 
@@ -53,16 +66,26 @@ type TxResourceHandler interface {
 	WithTx(tx pgx.Tx) (ResourceHandler, error)
 }
 
+// AccessChecker is implemented by handlers whose resources let users other than the uploader download
+// the files attached to them.
+type AccessChecker interface {
+	CanAccessFile(ctx context.Context, resourceID uuid.UUID, userID uuid.UUID) (bool, error)
+}
+
 type Service struct {
 	logger           *zap.Logger
 	db               DBTX
 	queries          Querier
 	tracer           trace.Tracer
 	validator        *Validator
+	blobStore        BlobStore
+	scanner          Scanner
 	resourceHandlers map[ResourceType]ResourceHandler
 }
 
-func NewService(logger *zap.Logger, db DBTX, handlers ...ResourceHandler) *Service {
+// NewService creates the file service. scanner may be nil, in which case files are stored without
+// being scanned for malware.
+func NewService(logger *zap.Logger, db DBTX, blobStore BlobStore, scanner Scanner, handlers ...ResourceHandler) *Service {
 	handlerMap := make(map[ResourceType]ResourceHandler, len(handlers))
 	for _, h := range handlers {
 		handlerMap[h.ResourceType()] = h
@@ -74,6 +97,8 @@ func NewService(logger *zap.Logger, db DBTX, handlers ...ResourceHandler) *Servi
 		queries:          New(db),
 		tracer:           otel.Tracer("file/service"),
 		validator:        NewValidator(),
+		blobStore:        blobStore,
+		scanner:          scanner,
 		resourceHandlers: handlerMap,
 	}
 }
@@ -85,6 +110,8 @@ func (s *Service) WithTx(tx pgx.Tx) *Service {
 		queries:          s.queries.WithTx(tx),
 		tracer:           s.tracer,
 		validator:        s.validator,
+		blobStore:        s.blobStore,
+		scanner:          s.scanner,
 		resourceHandlers: s.resourceHandlers,
 	}
 }
@@ -98,7 +125,11 @@ func (s *Service) withTransaction(ctx context.Context, fn func(tx pgx.Tx, qtx *Q
 	})
 }
 
-// SaveFile saves the uploaded file data to database with validation
+// SaveFile streams the uploaded file content to the blob store with validation and records it in the database.
+// The content must match its content type; JPEG and PNG images are stored without their metadata.
+// If a scanner is configured, the file is scanned before SaveFile returns: infected files are rejected
+// with internal.ErrFileInfected, and files that could not be scanned are kept quarantined until a rescan
+// finds them clean.
 // uploadedBy can be nil for system uploads
 // opts are validation options (e.g., WithWebP(), WithMaxSize(1024))
 func (s *Service) SaveFile(ctx context.Context, fileContent io.Reader, originalFilename, contentType string, uploadedBy *uuid.UUID, opts ...ValidatorOption) (File, error) {
@@ -106,30 +137,33 @@ func (s *Service) SaveFile(ctx context.Context, fileContent io.Reader, originalF
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	// Validate and read file content using internal validator
-	var data []byte
-	var err error
+	// The size is checked while the content is stored
+	stream, err := s.validator.ValidateStream(fileContent, contentType, opts...)
+	if err != nil {
+		logger.Warn("File validation failed", zap.Error(err))
+		span.RecordError(err)
+		return File{}, err
+	}
 
-	if len(opts) > 0 {
-		// Validation is requested
-		data, err = s.validator.ValidateStream(fileContent, contentType, opts...)
-		if err != nil {
+	var content io.Reader = stream
+	stripper := newMetadataStripper(stream, stream.MediaType)
+	if stripper != nil {
+		content = stripper
+	}
+
+	storageKey := newStorageKey()
+	reader := newChecksumReader(content)
+	err = s.blobStore.Put(traceCtx, storageKey, reader, -1, contentType)
+	if err != nil {
+		span.RecordError(err)
+		if IsValidationError(err) {
 			logger.Warn("File validation failed", zap.Error(err))
-			span.RecordError(err)
 			return File{}, err
 		}
-	} else {
-		// No validation, just read the stream
-		data, err = io.ReadAll(fileContent)
-		if err != nil {
-			logger.Error("Failed to read file content", zap.Error(err))
-			span.RecordError(err)
-			return File{}, fmt.Errorf("failed to read file content: %w", err)
-		}
+		logger.Error("Failed to store file content", zap.Error(err))
+		return File{}, fmt.Errorf("failed to store file content: %w", err)
 	}
 
-	size := int64(len(data))
-
 	// Convert uploadedBy to pgtype.UUID
 	var pgUploadedBy pgtype.UUID
 	if uploadedBy != nil {
@@ -139,29 +173,259 @@ func (s *Service) SaveFile(ctx context.Context, fileContent io.Reader, originalF
 		}
 	}
 
-	// Create database record with file data
+	// Files are quarantined until they are scanned
+	scanStatus := FileScanStatusNotScanned
+	if s.scanner != nil {
+		scanStatus = FileScanStatusPending
+	}
+
+	// Create database record pointing at the stored content
 	file, err := s.queries.Create(traceCtx, CreateParams{
 		OriginalFilename: originalFilename,
 		ContentType:      contentType,
-		Size:             size,
-		Data:             data,
+		Size:             reader.size,
+		StorageKey:       pgtype.Text{String: storageKey, Valid: true},
+		Checksum:         pgtype.Text{String: reader.Checksum(), Valid: true},
 		UploadedBy:       pgUploadedBy,
+		ScanStatus:       scanStatus,
+		IsPublic:         stream.public,
 	})
 	if err != nil {
 		logger.Error("Failed to create file record", zap.Error(err))
 		span.RecordError(err)
+		s.deleteBlob(traceCtx, logger, storageKey)
 		return File{}, databaseutil.WrapDBError(err, logger, "create file record")
 	}
 
+	if s.scanner != nil {
+		file, err = s.scanFile(traceCtx, logger, file)
+		if err == nil && file.ScanStatus == FileScanStatusInfected {
+			err = fmt.Errorf("%w: %s", internal.ErrFileInfected, file.ScanSignature.String)
+		}
+		if err != nil {
+			span.RecordError(err)
+			if delErr := s.DeletePhysicalFile(traceCtx, file.ID); delErr != nil {
+				logger.Warn("Failed to clean up file after scanning it",
+					zap.String("file_id", file.ID.String()),
+					zap.Error(delErr),
+				)
+			}
+			return File{}, err
+		}
+	}
+
+	if stream.imageVariants {
+		orientation := 1
+		if stripper != nil {
+			orientation = stripper.Orientation()
+		}
+
+		err = s.createVariants(traceCtx, logger, file, orientation)
+		if err != nil {
+			span.RecordError(err)
+			if delErr := s.DeletePhysicalFile(traceCtx, file.ID); delErr != nil {
+				logger.Warn("Failed to clean up file after variant creation failure",
+					zap.String("file_id", file.ID.String()),
+					zap.Error(delErr),
+				)
+			}
+			if IsValidationError(err) {
+				logger.Warn("File validation failed", zap.Error(err))
+				return File{}, err
+			}
+			logger.Error("Failed to create file variants", zap.Error(err))
+			return File{}, fmt.Errorf("failed to create file variants: %w", err)
+		}
+	}
+
 	logger.Info("File saved successfully",
 		zap.String("file_id", file.ID.String()),
 		zap.String("original_filename", originalFilename),
-		zap.Int64("size", size),
+		zap.Int64("size", file.Size),
 	)
 
 	return file, nil
 }
 
+// createVariants stores a resized copy of the image of file in every FileVariantSize, turned upright
+// according to the EXIF orientation of the original
+func (s *Service) createVariants(ctx context.Context, logger *zap.Logger, file File, orientation int) error {
+	content, err := s.blobStore.Open(ctx, file.StorageKey.String)
+	if err != nil {
+		return fmt.Errorf("failed to open content of file %s: %w", file.ID, err)
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	img, err := decodeImage(content)
+	if err != nil {
+		return err
+	}
+
+	oriented := false
+	for _, variant := range variantSizes {
+		resized := resizeImage(img, variant.edge)
+		if !oriented {
+			resized = orientImage(resized, orientation)
+			oriented = true
+		}
+		// The next, smaller variant is scaled down from this one
+		img = resized
+
+		data, variantContentType, err := encodeVariant(resized)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s variant: %w", variant.size, err)
+		}
+
+		storageKey := newStorageKey()
+		err = s.blobStore.Put(ctx, storageKey, bytes.NewReader(data), int64(len(data)), variantContentType)
+		if err != nil {
+			return fmt.Errorf("failed to store %s variant: %w", variant.size, err)
+		}
+
+		checksum := sha256.Sum256(data)
+		_, err = s.queries.CreateVariant(ctx, CreateVariantParams{
+			FileID:      file.ID,
+			Size:        variant.size,
+			ContentType: variantContentType,
+			ByteSize:    int64(len(data)),
+			Width:       int32(resized.Rect.Dx()),
+			Height:      int32(resized.Rect.Dy()),
+			StorageKey:  storageKey,
+			Checksum:    hex.EncodeToString(checksum[:]),
+		})
+		if err != nil {
+			s.deleteBlob(ctx, logger, storageKey)
+			return databaseutil.WrapDBErrorWithKeyValue(err, "file_variants", "file_id", file.ID.String(), logger, "create file variant")
+		}
+	}
+
+	return nil
+}
+
+// IsValidationError reports whether an error of SaveFile rejects the uploaded content rather than
+// being a failure to store it
+func IsValidationError(err error) bool {
+	return errors.Is(err, internal.ErrFileTooLarge) ||
+		errors.Is(err, internal.ErrInvalidFileType) ||
+		errors.Is(err, internal.ErrInvalidImageFormat) ||
+		errors.Is(err, internal.ErrContentTypeMismatch) ||
+		errors.Is(err, internal.ErrImageTooLarge) ||
+		errors.Is(err, internal.ErrFileInfected)
+}
+
+// scanFile scans the content of a file and records the result. A scan that cannot be completed is
+// recorded as failed rather than returned, so the file stays quarantined until it is rescanned.
+func (s *Service) scanFile(ctx context.Context, logger *zap.Logger, file File) (File, error) {
+	status := FileScanStatusClean
+	signature, err := s.scan(ctx, file)
+	if err != nil {
+		logger.Warn("Failed to scan file, keeping it quarantined",
+			zap.String("file_id", file.ID.String()),
+			zap.Error(err),
+		)
+		status = FileScanStatusFailed
+	} else if signature != "" {
+		logger.Warn("Malware found in uploaded file",
+			zap.String("file_id", file.ID.String()),
+			zap.String("signature", signature),
+		)
+		status = FileScanStatusInfected
+	}
+
+	updated, err := s.queries.UpdateScanResult(ctx, UpdateScanResultParams{
+		ID:            file.ID,
+		ScanStatus:    status,
+		ScanSignature: pgtype.Text{String: signature, Valid: signature != ""},
+	})
+	if err != nil {
+		return file, databaseutil.WrapDBErrorWithKeyValue(err, "files", "id", file.ID.String(), logger, "update file scan result")
+	}
+
+	return updated, nil
+}
+
+// scan streams the stored content of a file through the scanner
+func (s *Service) scan(ctx context.Context, file File) (string, error) {
+	content, err := s.blobStore.Open(ctx, file.StorageKey.String)
+	if err != nil {
+		return "", fmt.Errorf("failed to open content of file %s: %w", file.ID, err)
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	return s.scanner.Scan(ctx, content)
+}
+
+// RescanQuarantined scans up to batchSize files whose scan is still pending or has failed and that were
+// last touched more than olderThan ago, and returns how many were found clean. Files uploaded in the
+// meantime are left to the upload that is scanning them.
+func (s *Service) RescanQuarantined(ctx context.Context, olderThan time.Duration, batchSize int32) (int, error) {
+	traceCtx, span := s.tracer.Start(ctx, "RescanQuarantined")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if s.scanner == nil {
+		return 0, nil
+	}
+
+	files, err := s.queries.ListQuarantinedForRescan(traceCtx, ListQuarantinedForRescanParams{
+		UpdatedAt: pgtype.Timestamptz{Time: time.Now().Add(-olderThan), Valid: true},
+		Limit:     batchSize,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list quarantined files")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	cleared := 0
+	for _, file := range files {
+		scanned, err := s.scanFile(traceCtx, logger, file)
+		if err != nil {
+			span.RecordError(err)
+			return cleared, err
+		}
+		if scanned.ScanStatus == FileScanStatusClean {
+			cleared++
+		}
+	}
+
+	if cleared > 0 {
+		logger.Info("Released quarantined files found clean", zap.Int("count", cleared))
+	}
+
+	return cleared, nil
+}
+
+// RunRescans runs RescanQuarantined every interval until ctx is canceled. Nothing is run if no scanner is configured.
+func (s *Service) RunRescans(ctx context.Context, interval time.Duration) {
+	if s.scanner == nil {
+		return
+	}
+
+	s.logger.Info("Starting rescans of quarantined files", zap.Duration("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := s.RescanQuarantined(ctx, interval, rescanBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to rescan quarantined files", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Rescans of quarantined files stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // CreateAttachment links an existing file to a resource
 func (s *Service) CreateAttachment(ctx context.Context, fileID uuid.UUID, resourceType ResourceType, resourceID uuid.UUID, createdBy uuid.UUID) (FileAttachment, error) {
 	traceCtx, span := s.tracer.Start(ctx, "CreateAttachment")
@@ -346,19 +610,45 @@ func (s *Service) DeleteAttachment(ctx context.Context, attachmentID uuid.UUID)
 	return nil
 }
 
-// DeletePhysicalFile unconditionally deletes the file row.
+// DeletePhysicalFile unconditionally deletes the file row, its variants and their stored content.
 func (s *Service) DeletePhysicalFile(ctx context.Context, fileID uuid.UUID) error {
 	traceCtx, span := s.tracer.Start(ctx, "DeletePhysicalFile")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	err := s.queries.Delete(traceCtx, fileID)
+	var storageKeys []string
+	err := s.withTransaction(traceCtx, func(_ pgx.Tx, qtx *Queries) error {
+		variantKeys, err := qtx.DeleteVariants(traceCtx, fileID)
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "delete file variants")
+			span.RecordError(err)
+			return err
+		}
+
+		storageKey, err := qtx.Delete(traceCtx, fileID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			err = databaseutil.WrapDBError(err, logger, "delete physical file")
+			span.RecordError(err)
+			return err
+		}
+
+		storageKeys = variantKeys
+		if storageKey.Valid {
+			storageKeys = append(storageKeys, storageKey.String)
+		}
+		return nil
+	})
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "delete physical file")
-		span.RecordError(err)
 		return err
 	}
 
+	for _, storageKey := range storageKeys {
+		s.deleteBlob(traceCtx, logger, storageKey)
+	}
+
 	logger.Info("physical file deleted successfully",
 		zap.String("file_id", fileID.String()),
 	)
@@ -368,13 +658,14 @@ func (s *Service) DeletePhysicalFile(ctx context.Context, fileID uuid.UUID) erro
 
 // Delete is the generic delete orchestration entrypoint.
 // It asks the corresponding resource handler to remove the reference from each attached resource,
-// then deletes the attachment row, and finally deletes the physical file.
+// then deletes the attachment row, and finally deletes the physical file, its variants and their stored content.
 func (s *Service) Delete(ctx context.Context, fileID uuid.UUID) error {
 	traceCtx, span := s.tracer.Start(ctx, "DeleteFile")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
 	var attachmentCount int
+	var storageKeys []string
 	err := s.withTransaction(traceCtx, func(tx pgx.Tx, qtx *Queries) error {
 		_, err := qtx.LockFile(traceCtx, fileID)
 		if err != nil {
@@ -437,12 +728,23 @@ func (s *Service) Delete(ctx context.Context, fileID uuid.UUID) error {
 			}
 		}
 
-		err = qtx.Delete(traceCtx, fileID)
+		variantKeys, err := qtx.DeleteVariants(traceCtx, fileID)
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "delete file variants")
+			span.RecordError(err)
+			return err
+		}
+		storageKeys = variantKeys
+
+		storageKey, err := qtx.Delete(traceCtx, fileID)
 		if err != nil {
 			err = databaseutil.WrapDBError(err, logger, "delete file row")
 			span.RecordError(err)
 			return err
 		}
+		if storageKey.Valid {
+			storageKeys = append(storageKeys, storageKey.String)
+		}
 
 		return nil
 	})
@@ -450,6 +752,11 @@ func (s *Service) Delete(ctx context.Context, fileID uuid.UUID) error {
 		return err
 	}
 
+	// The content is removed once its row is gone, so a failed transaction never leaves a row without content
+	for _, storageKey := range storageKeys {
+		s.deleteBlob(traceCtx, logger, storageKey)
+	}
+
 	logger.Info("file deleted successfully through orchestration",
 		zap.String("file_id", fileID.String()),
 		zap.Int("attachment_count", attachmentCount),
@@ -458,7 +765,7 @@ func (s *Service) Delete(ctx context.Context, fileID uuid.UUID) error {
 	return nil
 }
 
-// Get retrieves a file record with data by ID
+// Get retrieves a file record by ID; its content is read through Open
 func (s *Service) Get(ctx context.Context, id uuid.UUID) (File, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Get")
 	defer span.End()
@@ -474,6 +781,134 @@ func (s *Service) Get(ctx context.Context, id uuid.UUID) (File, error) {
 	return file, nil
 }
 
+// CanAccess reports whether a user can download a file: public files can be downloaded by everyone,
+// other files by their uploader and by the users that a resource the file is attached to lets access it.
+func (s *Service) CanAccess(ctx context.Context, file File, userID uuid.UUID) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CanAccess")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if file.IsPublic || (file.UploadedBy.Valid && uuid.UUID(file.UploadedBy.Bytes) == userID) {
+		return true, nil
+	}
+
+	attachments, err := s.queries.ListAttachmentsByFileID(traceCtx, file.ID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "file_attachments", "file_id", file.ID.String(), logger, "list attachments by file id")
+		span.RecordError(err)
+		return false, err
+	}
+
+	for _, attachment := range attachments {
+		checker, ok := s.resourceHandlers[attachment.ResourceType].(AccessChecker)
+		if !ok {
+			continue
+		}
+
+		allowed, err := checker.CanAccessFile(traceCtx, attachment.ResourceID, userID)
+		if err != nil {
+			span.RecordError(err)
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RecordDownload adds a download to the audit log of file downloads
+func (s *Service) RecordDownload(ctx context.Context, download CreateDownloadParams) error {
+	traceCtx, span := s.tracer.Start(ctx, "RecordDownload")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.queries.CreateDownload(traceCtx, download)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "file_downloads", "file_id", download.FileID.String(), logger, "record file download")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Open returns the content of a file. The reader can seek, so downloads can serve byte ranges.
+// Files created before contents moved to the blob store are served from their row until MigrateBlobs has moved them.
+// The content of quarantined files is refused with internal.ErrFileQuarantined.
+func (s *Service) Open(ctx context.Context, file File) (io.ReadSeekCloser, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Open")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if IsQuarantined(file.ScanStatus) {
+		err := fmt.Errorf("%w: scan status of file %s is %s", internal.ErrFileQuarantined, file.ID, file.ScanStatus)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if !file.StorageKey.Valid {
+		return legacyReader{Reader: bytes.NewReader(file.Data)}, nil
+	}
+
+	reader, err := s.blobStore.Open(traceCtx, file.StorageKey.String)
+	if err != nil {
+		logger.Error("Failed to open file content",
+			zap.String("file_id", file.ID.String()),
+			zap.String("storage_key", file.StorageKey.String),
+			zap.Error(err),
+		)
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to open content of file %s: %w", file.ID, err)
+	}
+
+	return reader, nil
+}
+
+// GetVariant retrieves the variant of a file in the given size. It returns internal.ErrFileVariantNotFound
+// for files without variants, such as files that are no images or were uploaded before variants were introduced.
+func (s *Service) GetVariant(ctx context.Context, fileID uuid.UUID, size FileVariantSize) (FileVariant, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetVariant")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	variant, err := s.queries.GetVariant(traceCtx, GetVariantParams{
+		FileID: fileID,
+		Size:   size,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FileVariant{}, internal.ErrFileVariantNotFound
+		}
+		err = databaseutil.WrapDBError(err, logger, "get file variant")
+		span.RecordError(err)
+		return FileVariant{}, err
+	}
+
+	return variant, nil
+}
+
+// OpenVariant returns the content of a file variant
+func (s *Service) OpenVariant(ctx context.Context, variant FileVariant) (io.ReadSeekCloser, error) {
+	traceCtx, span := s.tracer.Start(ctx, "OpenVariant")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	reader, err := s.blobStore.Open(traceCtx, variant.StorageKey)
+	if err != nil {
+		logger.Error("Failed to open file variant content",
+			zap.String("file_id", variant.FileID.String()),
+			zap.String("size", string(variant.Size)),
+			zap.Error(err),
+		)
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to open %s variant of file %s: %w", variant.Size, variant.FileID, err)
+	}
+
+	return reader, nil
+}
+
 // GetMetadata retrieves file metadata without the binary data
 func (s *Service) GetMetadata(ctx context.Context, id uuid.UUID) (GetMetadataRow, error) {
 	traceCtx, span := s.tracer.Start(ctx, "GetMetadata")
@@ -601,3 +1036,96 @@ func (s *Service) DownloadFromURL(ctx context.Context, url string, filename stri
 	// Save the downloaded file using existing SaveFile method
 	return s.SaveFile(traceCtx, resp.Body, filename, contentType, uploadedBy, opts...)
 }
+
+// MigrateBlobs moves the contents of files created before contents moved to the blob store out of the
+// database, batchSize files at a time, and returns how many were moved. It can be stopped and run again:
+// files that were already moved are skipped.
+func (s *Service) MigrateBlobs(ctx context.Context, batchSize int32) (int, error) {
+	traceCtx, span := s.tracer.Start(ctx, "MigrateBlobs")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	moved := 0
+	var lastID uuid.UUID
+	for {
+		ids, err := s.queries.ListWithoutStorageKey(traceCtx, ListWithoutStorageKeyParams{
+			ID:    lastID,
+			Limit: batchSize,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "list files without storage key")
+			span.RecordError(err)
+			return moved, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			lastID = id
+
+			ok, err := s.migrateBlob(traceCtx, logger, id)
+			if err != nil {
+				span.RecordError(err)
+				return moved, err
+			}
+			if ok {
+				moved++
+			}
+		}
+
+		logger.Info("Moved file contents to the blob store", zap.Int("moved", moved))
+	}
+
+	return moved, nil
+}
+
+// migrateBlob moves the content of one file to the blob store; it reports false if the file was
+// deleted or moved by someone else in the meantime
+func (s *Service) migrateBlob(ctx context.Context, logger *zap.Logger, id uuid.UUID) (bool, error) {
+	row, err := s.queries.GetLegacyData(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, databaseutil.WrapDBErrorWithKeyValue(err, "files", "id", id.String(), logger, "get legacy file data")
+	}
+	if row.Data == nil {
+		return false, nil
+	}
+
+	storageKey := newStorageKey()
+	err = s.blobStore.Put(ctx, storageKey, bytes.NewReader(row.Data), int64(len(row.Data)), row.ContentType)
+	if err != nil {
+		return false, fmt.Errorf("failed to store content of file %s: %w", id, err)
+	}
+
+	checksum := sha256.Sum256(row.Data)
+	count, err := s.queries.MoveToStorage(ctx, MoveToStorageParams{
+		ID:         id,
+		StorageKey: pgtype.Text{String: storageKey, Valid: true},
+		Checksum:   pgtype.Text{String: hex.EncodeToString(checksum[:]), Valid: true},
+	})
+	if err != nil {
+		s.deleteBlob(ctx, logger, storageKey)
+		return false, databaseutil.WrapDBErrorWithKeyValue(err, "files", "id", id.String(), logger, "move file to storage")
+	}
+	if count == 0 {
+		s.deleteBlob(ctx, logger, storageKey)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// deleteBlob removes stored content that no row points at. Failures are only logged: the content is
+// unreachable either way, and the operation that dropped it has already succeeded.
+func (s *Service) deleteBlob(ctx context.Context, logger *zap.Logger, storageKey string) {
+	err := s.blobStore.Delete(ctx, storageKey)
+	if err != nil {
+		logger.Warn("Failed to delete stored file content",
+			zap.String("storage_key", storageKey),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,99 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Query parameters of a signed download URL
+const (
+	signedURLExpiresParam   = "expires"
+	signedURLUserParam      = "user"
+	signedURLSignatureParam = "signature"
+)
+
+// URLSigner issues and verifies short-lived download URLs, GET /api/files/{id}?expires=&user=&signature=,
+// that let whoever holds them download a file without signing in. The signature is an HMAC-SHA256 of
+// the file, the expiry and the user who was issued the URL, so none of them can be changed.
+type URLSigner struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewURLSigner creates a signer whose URLs expire after ttl. The signing key is derived from secret,
+// so it differs from any other key derived from the same secret.
+func NewURLSigner(secret string, ttl time.Duration) *URLSigner {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("file download URL"))
+
+	return &URLSigner{
+		key: mac.Sum(nil),
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Sign returns a download URL of a file issued to a user, and when it expires
+func (s *URLSigner) Sign(fileID, userID uuid.UUID) (string, time.Time) {
+	expiresAt := s.now().Add(s.ttl).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set(signedURLExpiresParam, expires)
+	query.Set(signedURLUserParam, userID.String())
+	query.Set(signedURLSignatureParam, s.signature(fileID, expires, userID.String()))
+
+	return fmt.Sprintf("/api/files/%s?%s", fileID, query.Encode()), expiresAt
+}
+
+// IsSigned reports whether a download request carries a signature, which then has to be verified
+func IsSigned(query url.Values) bool {
+	return query.Has(signedURLSignatureParam)
+}
+
+// Verify checks the signature of a download URL of a file and returns the user it was issued to.
+// It fails with internal.ErrInvalidSignedURL if the URL was not issued for the file, and with
+// internal.ErrSignedURLExpired once it has expired.
+func (s *URLSigner) Verify(fileID uuid.UUID, query url.Values) (uuid.UUID, error) {
+	expires := query.Get(signedURLExpiresParam)
+	user := query.Get(signedURLUserParam)
+
+	signature, err := hex.DecodeString(query.Get(signedURLSignatureParam))
+	if err != nil {
+		return uuid.Nil, internal.ErrInvalidSignedURL
+	}
+	expected, _ := hex.DecodeString(s.signature(fileID, expires, user))
+	if !hmac.Equal(signature, expected) {
+		return uuid.Nil, internal.ErrInvalidSignedURL
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return uuid.Nil, internal.ErrInvalidSignedURL
+	}
+	if !s.now().Before(time.Unix(expiresAt, 0)) {
+		return uuid.Nil, internal.ErrSignedURLExpired
+	}
+
+	userID, err := uuid.Parse(user)
+	if err != nil {
+		return uuid.Nil, internal.ErrInvalidSignedURL
+	}
+
+	return userID, nil
+}
+
+func (s *URLSigner) signature(fileID uuid.UUID, expires, user string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(fileID.String() + "\n" + expires + "\n" + user))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLSigner(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewURLSigner("secret", 15*time.Minute)
+	signer.now = func() time.Time { return now }
+
+	fileID := uuid.New()
+	userID := uuid.New()
+	signed, expiresAt := signer.Sign(fileID, userID)
+	require.Equal(t, now.Add(15*time.Minute), expiresAt)
+
+	parsed, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "/api/files/"+fileID.String(), parsed.Path)
+	require.True(t, IsSigned(parsed.Query()))
+
+	// withParam returns the query of the signed URL with one parameter replaced
+	withParam := func(key, value string) url.Values {
+		query := parsed.Query()
+		query.Set(key, value)
+		return query
+	}
+
+	testCases := []struct {
+		name        string
+		fileID      uuid.UUID
+		query       url.Values
+		at          time.Time
+		expectedErr error
+	}{
+		{name: "Valid", fileID: fileID, query: parsed.Query(), at: now.Add(14 * time.Minute)},
+		{name: "Expired", fileID: fileID, query: parsed.Query(), at: now.Add(15 * time.Minute), expectedErr: internal.ErrSignedURLExpired},
+		{name: "Other file", fileID: uuid.New(), query: parsed.Query(), at: now, expectedErr: internal.ErrInvalidSignedURL},
+		{name: "Extended expiry", fileID: fileID, query: withParam(signedURLExpiresParam, "4102444800"), at: now, expectedErr: internal.ErrInvalidSignedURL},
+		{name: "Other user", fileID: fileID, query: withParam(signedURLUserParam, uuid.NewString()), at: now, expectedErr: internal.ErrInvalidSignedURL},
+		{name: "Malformed signature", fileID: fileID, query: withParam(signedURLSignatureParam, "not hex"), at: now, expectedErr: internal.ErrInvalidSignedURL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signer.now = func() time.Time { return tc.at }
+
+			issuedTo, err := signer.Verify(tc.fileID, tc.query)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, userID, issuedTo)
+		})
+	}
+
+	// A URL signed with another secret is refused
+	otherSigner := NewURLSigner("other secret", 15*time.Minute)
+	otherSigner.now = func() time.Time { return now }
+	_, err = otherSigner.Verify(fileID, parsed.Query())
+	require.ErrorIs(t, err, internal.ErrInvalidSignedURL)
+}
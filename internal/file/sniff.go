@@ -0,0 +1,124 @@
+package file
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// mediaTypeAliases maps media types that clients commonly declare to the name the sniffer reports for them
+var mediaTypeAliases = map[string]string{
+	"image/jpg":                    "image/jpeg",
+	"image/pjpeg":                  "image/jpeg",
+	"image/x-png":                  "image/png",
+	"image/vnd.microsoft.icon":     "image/x-icon",
+	"image/x-ms-bmp":               "image/bmp",
+	"audio/wav":                    "audio/wave",
+	"audio/x-wav":                  "audio/wave",
+	"audio/vnd.wave":               "audio/wave",
+	"audio/x-aiff":                 "audio/aiff",
+	"audio/mp3":                    "audio/mpeg",
+	"audio/mp4":                    "video/mp4",
+	"audio/webm":                   "video/webm",
+	"audio/ogg":                    "application/ogg",
+	"video/ogg":                    "application/ogg",
+	"video/x-msvideo":              "video/avi",
+	"application/gzip":             "application/x-gzip",
+	"application/x-zip-compressed": "application/zip",
+	"application/vnd.rar":          "application/x-rar-compressed",
+	"application/x-rar":            "application/x-rar-compressed",
+	"application/font-woff":        "font/woff",
+}
+
+// sniffableTypes are the specific types the sniffer reliably recognizes. Content declared as one of them
+// must be recognized as it; content the sniffer cannot name is only accepted for types it could not name
+// either. MP3 and MP4 are left out, as the sniffer only knows some of their variants.
+var sniffableTypes = map[string]bool{
+	"application/ogg":               true,
+	"application/pdf":               true,
+	"application/postscript":        true,
+	"application/vnd.ms-fontobject": true,
+	"application/wasm":              true,
+	"application/x-gzip":            true,
+	"application/x-rar-compressed":  true,
+	"application/zip":               true,
+	"audio/aiff":                    true,
+	"audio/basic":                   true,
+	"audio/midi":                    true,
+	"audio/wave":                    true,
+	"font/collection":               true,
+	"font/otf":                      true,
+	"font/ttf":                      true,
+	"font/woff":                     true,
+	"font/woff2":                    true,
+	"image/bmp":                     true,
+	"image/gif":                     true,
+	"image/jpeg":                    true,
+	"image/png":                     true,
+	"image/webp":                    true,
+	"image/x-icon":                  true,
+	"text/html":                     true,
+	"text/xml":                      true,
+	"video/avi":                     true,
+	"video/webm":                    true,
+}
+
+// normalizeMediaType returns the lower-case media type of contentType without parameters, using the name
+// the sniffer reports for it. An empty content type is treated as unknown binary content.
+func normalizeMediaType(contentType string) (string, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return "application/octet-stream", nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+
+	alias, ok := mediaTypeAliases[mediaType]
+	if ok {
+		return alias, nil
+	}
+	return mediaType, nil
+}
+
+// sniffMediaType returns the media type of the content recognized from its first bytes by its magic bytes
+func sniffMediaType(header []byte) string {
+	// DetectContentType always returns a valid media type
+	mediaType, _ := normalizeMediaType(http.DetectContentType(header))
+	return mediaType
+}
+
+// mediaTypeMatches reports whether content sniffed as detected may be stored as declared.
+// Both are normalized media types.
+func mediaTypeMatches(declared, detected string) bool {
+	if declared == detected || declared == "application/octet-stream" {
+		return true
+	}
+
+	switch detected {
+	case "text/plain":
+		// Markup without a recognizable start is plain text to the sniffer
+		return !sniffableTypes[declared] || strings.HasPrefix(declared, "text/")
+	case "application/octet-stream":
+		// The sniffer could not name the content, so it can only contradict types it would have named
+		return !sniffableTypes[declared]
+	case "application/zip":
+		// Office documents, e-books and archives of other applications are zip files
+		return isZipBased(declared)
+	case "text/xml":
+		return declared == "application/xml" || strings.HasSuffix(declared, "+xml")
+	case "text/html":
+		return declared == "application/xhtml+xml"
+	}
+
+	return false
+}
+
+func isZipBased(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "application/vnd.openxmlformats-officedocument.") ||
+		strings.HasPrefix(mediaType, "application/vnd.oasis.opendocument.") ||
+		strings.HasSuffix(mediaType, "+zip") ||
+		mediaType == "application/java-archive" ||
+		mediaType == "application/vnd.android.package-archive"
+}
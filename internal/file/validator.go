@@ -2,19 +2,26 @@ package file
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"slices"
 )
 
+// formatHeaderSize is the number of leading bytes passed to format checks
+const formatHeaderSize = 512
+
 // ValidatorOption is a function that configures validation rules
 type ValidatorOption func(*validatorConfig)
 
 // validatorConfig holds the validation configuration
 type validatorConfig struct {
-	maxSize      int64
-	allowedTypes []string
-	checkFormat  func([]byte) error
+	maxSize       int64
+	allowedTypes  []string
+	checkFormat   func([]byte) error
+	imageVariants bool
+	public        bool
 }
 
 // Validator performs file validation based on configured rules
@@ -27,42 +34,91 @@ func NewValidator() *Validator {
 	return &Validator{}
 }
 
-// ValidateStream validates a file stream and returns the validated data
-// It applies the provided validation options internally
-func (v *Validator) ValidateStream(stream io.Reader, contentType string, opts ...ValidatorOption) ([]byte, error) {
+// ValidatedStream is a file stream that passed the checks a Validator makes up front
+type ValidatedStream struct {
+	io.Reader
+	// MediaType is the type of the content recognized from its first bytes, without parameters
+	MediaType string
+	// imageVariants is set when resized variants of the image should be created, see WithImageVariants
+	imageVariants bool
+	// public is set when the file can be downloaded by anyone, see WithPublicAccess
+	public bool
+}
+
+// ValidateStream validates a file stream and returns a reader of the validated data.
+// The content type, the format of the first bytes and whether those bytes match the content type are
+// checked up front; the size is checked while the returned reader is read, which fails with
+// internal.ErrFileTooLarge once it is exceeded. The stream is never read into memory as a whole.
+func (v *Validator) ValidateStream(stream io.Reader, contentType string, opts ...ValidatorOption) (ValidatedStream, error) {
 	// Apply options to build configuration
 	config := &validatorConfig{}
 	for _, opt := range opts {
 		opt(config)
 	}
 
-	// Read stream into memory
-	data, err := io.ReadAll(stream)
+	declared, err := normalizeMediaType(contentType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file stream: %w", err)
-	}
-
-	// Validate size
-	if config.maxSize > 0 && int64(len(data)) > config.maxSize {
-		return nil, internal.ErrFileTooLarge
+		return ValidatedStream{}, fmt.Errorf("%w: %s", internal.ErrInvalidFileType, err)
 	}
 
 	// Validate content type
 	if len(config.allowedTypes) > 0 {
-		allowed := slices.Contains(config.allowedTypes, contentType)
+		allowed := slices.ContainsFunc(config.allowedTypes, func(allowedType string) bool {
+			normalized, err := normalizeMediaType(allowedType)
+			return err == nil && normalized == declared
+		})
 		if !allowed {
-			return nil, internal.ErrInvalidFileType
+			return ValidatedStream{}, internal.ErrInvalidFileType
 		}
 	}
 
-	// Validate file format
+	// The first bytes stay buffered for the caller
+	buffered := bufio.NewReaderSize(stream, formatHeaderSize)
+	header, err := buffered.Peek(formatHeaderSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return ValidatedStream{}, fmt.Errorf("failed to read file stream: %w", err)
+	}
+
+	// Validate file format against the first bytes
 	if config.checkFormat != nil {
-		if err := config.checkFormat(data); err != nil {
-			return nil, err
+		if err := config.checkFormat(header); err != nil {
+			return ValidatedStream{}, err
 		}
 	}
 
-	return data, nil
+	// Validate that the content is what it claims to be
+	detected := sniffMediaType(header)
+	if !mediaTypeMatches(declared, detected) {
+		return ValidatedStream{}, fmt.Errorf("%w: declared as %s but detected as %s", internal.ErrContentTypeMismatch, declared, detected)
+	}
+
+	// Validate size
+	var validated io.Reader = buffered
+	if config.maxSize > 0 {
+		validated = &sizeLimitedReader{reader: io.LimitReader(buffered, config.maxSize+1), remaining: config.maxSize}
+	}
+
+	return ValidatedStream{
+		Reader:        validated,
+		MediaType:     detected,
+		imageVariants: config.imageVariants,
+		public:        config.public,
+	}, nil
+}
+
+// sizeLimitedReader fails with internal.ErrFileTooLarge once more than the allowed bytes are read
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return 0, internal.ErrFileTooLarge
+	}
+	return n, err
 }
 
 // WithMaxSize sets the maximum allowed file size in bytes
@@ -103,7 +159,7 @@ func WithContentType(contentTypes ...string) ValidatorOption {
 	}
 }
 
-// WithCustomValidation allows custom validation logic
+// WithCustomValidation allows custom validation logic on the first bytes of the file
 func WithCustomValidation(check func([]byte) error) ValidatorOption {
 	return func(c *validatorConfig) {
 		c.checkFormat = check
@@ -131,6 +187,22 @@ func WithImageFormats() ValidatorOption {
 	}
 }
 
+// WithImageVariants makes SaveFile create resized variants of the image in every FileVariantSize,
+// which GET /api/files/{id}?size= serves. Combine it with an image format option.
+func WithImageVariants() ValidatorOption {
+	return func(c *validatorConfig) {
+		c.imageVariants = true
+	}
+}
+
+// WithPublicAccess makes SaveFile store a file that anyone can download without signing in, such as a
+// form cover or an avatar. Other files can only be downloaded by users who can access them.
+func WithPublicAccess() ValidatorOption {
+	return func(c *validatorConfig) {
+		c.public = true
+	}
+}
+
 // validateJPEG checks if data is a valid JPEG image
 func validateJPEG(data []byte) error {
 	// JPEG validation: check magic bytes FF D8 FF
@@ -143,7 +215,6 @@ func validateJPEG(data []byte) error {
 // validatePNG checks if data is a valid PNG image
 func validatePNG(data []byte) error {
 	// PNG validation: check PNG signature
-	pngSignature := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
 	if len(data) < len(pngSignature) {
 		return internal.ErrInvalidImageFormat
 	}
@@ -0,0 +1,116 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_ValidateStream(t *testing.T) {
+	png := append([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, bytes.Repeat([]byte{0x01}, 1024)...)
+
+	testCases := []struct {
+		name        string
+		data        []byte
+		contentType string
+		opts        []ValidatorOption
+		// expectedErr is returned by ValidateStream; expectedReadErr while reading the validated stream
+		expectedErr     error
+		expectedReadErr error
+	}{
+		{
+			name:        "Image within the size limit",
+			data:        png,
+			contentType: "image/png",
+			opts:        []ValidatorOption{WithImageFormats(), WithMaxSize(int64(len(png)))},
+		},
+		{
+			name:        "Content type not allowed",
+			data:        png,
+			contentType: "application/pdf",
+			opts:        []ValidatorOption{WithImageFormats()},
+			expectedErr: internal.ErrInvalidFileType,
+		},
+		{
+			name:        "Content does not match the format",
+			data:        []byte("not an image"),
+			contentType: "image/png",
+			opts:        []ValidatorOption{WithPNG()},
+			expectedErr: internal.ErrInvalidImageFormat,
+		},
+		{
+			name:        "Content does not match the content type",
+			data:        png,
+			contentType: "image/jpeg",
+			expectedErr: internal.ErrContentTypeMismatch,
+		},
+		{
+			name:        "HTML declared as plain text",
+			data:        []byte("<html><script>alert(1)</script></html>"),
+			contentType: "text/plain",
+			expectedErr: internal.ErrContentTypeMismatch,
+		},
+		{
+			name:        "Binary content declared as PDF",
+			data:        []byte{0x00, 0x01, 0x02, 0x03},
+			contentType: "application/pdf",
+			expectedErr: internal.ErrContentTypeMismatch,
+		},
+		{
+			name:        "Office document is a zip file",
+			data:        []byte("PK\x03\x04rest of the workbook"),
+			contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		},
+		{
+			name:        "CSV is plain text",
+			data:        []byte("name,email\nalice,alice@example.com\n"),
+			contentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:        "Unknown content type accepts any content",
+			data:        png,
+			contentType: "application/octet-stream",
+		},
+		{
+			name:        "Common alias of the content type",
+			data:        []byte{0xFF, 0xD8, 0xFF, 0xE0},
+			contentType: "image/jpg",
+		},
+		{
+			name:        "Malformed content type",
+			data:        png,
+			contentType: "image/",
+			expectedErr: internal.ErrInvalidFileType,
+		},
+		{
+			name:            "Larger than the size limit",
+			data:            png,
+			contentType:     "image/png",
+			opts:            []ValidatorOption{WithPNG(), WithMaxSize(int64(len(png)) - 1)},
+			expectedReadErr: internal.ErrFileTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream, err := NewValidator().ValidateStream(bytes.NewReader(tc.data), tc.contentType, tc.opts...)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := io.ReadAll(stream)
+			if tc.expectedReadErr != nil {
+				require.ErrorIs(t, err, tc.expectedReadErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.data, data, "the format check must not consume the stream")
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+
+	"golang.org/x/image/draw"
+	// Registers the WebP decoder; JPEG and PNG are registered by their packages above
+	_ "golang.org/x/image/webp"
+)
+
+// maxImagePixels bounds the images variants are created for, as an image is decoded into memory as a whole
+const maxImagePixels = 25_000_000
+
+// variantJPEGQuality is the quality variants without transparency are encoded with
+const variantJPEGQuality = 85
+
+// variantSizes is the longest edge in pixels of each variant, largest first, so each variant can be
+// scaled down from the previous one
+var variantSizes = []struct {
+	size FileVariantSize
+	edge int
+}{
+	{size: FileVariantSizeLarge, edge: 1280},
+	{size: FileVariantSizeMedium, edge: 640},
+	{size: FileVariantSizeSmall, edge: 160},
+}
+
+// ParseVariantSize parses the size of a variant as given in the size parameter of a download
+func ParseVariantSize(size string) (FileVariantSize, error) {
+	for _, variant := range variantSizes {
+		if string(variant.size) == size {
+			return variant.size, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", internal.ErrInvalidFileVariantSize, size)
+}
+
+// decodeImage decodes a JPEG, PNG or WebP image. Its dimensions are checked against maxImagePixels
+// before the image is decoded.
+func decodeImage(r io.ReadSeeker) (image.Image, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", internal.ErrInvalidImageFormat, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("%w: image has no pixels", internal.ErrInvalidImageFormat)
+	}
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, fmt.Errorf("%w: %dx%d", internal.ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", internal.ErrInvalidImageFormat, err)
+	}
+	return img, nil
+}
+
+// resizeImage scales img down to fit a square of edge pixels; smaller images keep their size
+func resizeImage(img image.Image, edge int) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > edge || height > edge {
+		if width >= height {
+			height = max(1, height*edge/width)
+			width = edge
+		} else {
+			width = max(1, width*edge/height)
+			height = edge
+		}
+	}
+
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+	return resized
+}
+
+// orientImage turns img upright according to its EXIF orientation
+func orientImage(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	orientedWidth, orientedHeight := width, height
+	if orientation >= 5 {
+		// Orientations 5 to 8 are turned by a quarter
+		orientedWidth, orientedHeight = height, width
+	}
+
+	oriented := image.NewRGBA(image.Rect(0, 0, orientedWidth, orientedHeight))
+	for y := 0; y < orientedHeight; y++ {
+		for x := 0; x < orientedWidth; x++ {
+			// The pixel of img that ends up at x, y
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = width-1-x, y
+			case 3:
+				sx, sy = width-1-x, height-1-y
+			case 4:
+				sx, sy = x, height-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, height-1-x
+			case 7:
+				sx, sy = width-1-y, height-1-x
+			case 8:
+				sx, sy = width-1-y, x
+			}
+
+			src := img.PixOffset(img.Rect.Min.X+sx, img.Rect.Min.Y+sy)
+			dst := oriented.PixOffset(x, y)
+			copy(oriented.Pix[dst:dst+4], img.Pix[src:src+4])
+		}
+	}
+	return oriented
+}
+
+// encodeVariant encodes a variant as JPEG, or as PNG if it has transparent pixels, and returns its content type
+func encodeVariant(img *image.RGBA) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if img.Opaque() {
+		err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: variantJPEGQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+
+	err := png.Encode(&buf, img)
+	return buf.Bytes(), "image/png", err
+}
+
+// variantFilename returns the filename a variant of the file named filename is downloaded as
+func variantFilename(filename string, variant FileVariant) string {
+	extension := ".jpg"
+	if variant.ContentType == "image/png" {
+		extension = ".png"
+	}
+	return strings.TrimSuffix(filename, path.Ext(filename)) + "_" + string(variant.Size) + extension
+}
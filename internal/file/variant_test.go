@@ -0,0 +1,133 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrientImage(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	// A 2x1 image, red on the left
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+
+	testCases := []struct {
+		name        string
+		orientation int
+		// expected lists the pixels of the oriented image row by row
+		expected [][]color.RGBA
+	}{
+		{name: "Upright", orientation: 1, expected: [][]color.RGBA{{red, blue}}},
+		{name: "Mirrored", orientation: 2, expected: [][]color.RGBA{{blue, red}}},
+		{name: "Upside down", orientation: 3, expected: [][]color.RGBA{{blue, red}}},
+		{name: "Mirrored upside down", orientation: 4, expected: [][]color.RGBA{{red, blue}}},
+		{name: "Transposed", orientation: 5, expected: [][]color.RGBA{{red}, {blue}}},
+		{name: "Turned clockwise", orientation: 6, expected: [][]color.RGBA{{red}, {blue}}},
+		{name: "Transversed", orientation: 7, expected: [][]color.RGBA{{blue}, {red}}},
+		{name: "Turned counterclockwise", orientation: 8, expected: [][]color.RGBA{{blue}, {red}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oriented := orientImage(img, tc.orientation)
+			require.Equal(t, len(tc.expected), oriented.Rect.Dy())
+			require.Equal(t, len(tc.expected[0]), oriented.Rect.Dx())
+			for y, row := range tc.expected {
+				for x, pixel := range row {
+					require.Equal(t, pixel, oriented.RGBAAt(x, y), "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestResizeImage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		width, height  int
+		edge           int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{name: "Landscape", width: 2000, height: 1000, edge: 640, expectedWidth: 640, expectedHeight: 320},
+		{name: "Portrait", width: 300, height: 1200, edge: 160, expectedWidth: 40, expectedHeight: 160},
+		{name: "Smaller than the variant", width: 100, height: 50, edge: 160, expectedWidth: 100, expectedHeight: 50},
+		{name: "Very thin", width: 5000, height: 2, edge: 160, expectedWidth: 160, expectedHeight: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resized := resizeImage(image.NewRGBA(image.Rect(0, 0, tc.width, tc.height)), tc.edge)
+			require.Equal(t, tc.expectedWidth, resized.Rect.Dx())
+			require.Equal(t, tc.expectedHeight, resized.Rect.Dy())
+		})
+	}
+}
+
+func TestEncodeVariant(t *testing.T) {
+	opaque := testImage()
+	transparent := testImage()
+	transparent.Set(0, 0, color.RGBA{})
+
+	_, contentType, err := encodeVariant(opaque)
+	require.NoError(t, err)
+	require.Equal(t, "image/jpeg", contentType)
+
+	_, contentType, err = encodeVariant(transparent)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", contentType)
+}
+
+func TestDecodeImage(t *testing.T) {
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, testImage()))
+
+	// A PNG header claiming 10000x10000 pixels, which must be refused before decoding
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], 10000)
+	binary.BigEndian.PutUint32(header[4:], 10000)
+	header[8] = 8 // bit depth
+	header[9] = 6 // RGBA
+	huge := append(append(bytes.Clone(pngSignature), pngChunk("IHDR", header)...), pngChunk("IEND", nil)...)
+
+	testCases := []struct {
+		name        string
+		data        []byte
+		expectedErr error
+	}{
+		{name: "PNG", data: encoded.Bytes()},
+		{name: "Too many pixels", data: huge, expectedErr: internal.ErrImageTooLarge},
+		{name: "Not an image", data: []byte("not an image"), expectedErr: internal.ErrInvalidImageFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := decodeImage(bytes.NewReader(tc.data))
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, image.Rect(0, 0, 16, 8), img.Bounds())
+		})
+	}
+}
+
+func TestParseVariantSize(t *testing.T) {
+	size, err := ParseVariantSize("medium")
+	require.NoError(t, err)
+	require.Equal(t, FileVariantSizeMedium, size)
+
+	_, err = ParseVariantSize("huge")
+	require.ErrorIs(t, err, internal.ErrInvalidFileVariantSize)
+}
@@ -62,10 +62,10 @@ SELECT id, response_id, question_id, value, created_at, updated_at
 FROM answers
 WHERE id = $1;
 
--- name: ListForExport :many
-SELECT a.id, a.response_id, a.question_id, a.value, a.created_at, a.updated_at, fr.submitted_at
+-- name: GetUnitID :one
+-- Unit of the form whose response holds the answer
+SELECT f.unit_id
 FROM answers a
-JOIN form_responses fr ON a.response_id = fr.id
-WHERE fr.form_id = $1
-  AND fr.progress = 'submitted'
-  AND a.question_id = ANY(@question_id::uuid[]);
\ No newline at end of file
+JOIN form_responses fr ON fr.id = a.response_id
+JOIN forms f ON f.id = fr.form_id
+WHERE a.id = $1;
\ No newline at end of file
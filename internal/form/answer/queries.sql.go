@@ -160,6 +160,22 @@ func (q *Queries) GetIDByResponseIDAndQuestionID(ctx context.Context, arg GetIDB
 	return id, err
 }
 
+const getUnitID = `-- name: GetUnitID :one
+SELECT f.unit_id
+FROM answers a
+JOIN form_responses fr ON fr.id = a.response_id
+JOIN forms f ON f.id = fr.form_id
+WHERE a.id = $1
+`
+
+// Unit of the form whose response holds the answer
+func (q *Queries) GetUnitID(ctx context.Context, id uuid.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getUnitID, id)
+	var unit_id pgtype.UUID
+	err := row.Scan(&unit_id)
+	return unit_id, err
+}
+
 const listByResponseID = `-- name: ListByResponseID :many
 SELECT id, response_id, question_id, value, created_at, updated_at FROM answers
 WHERE response_id = $1
@@ -193,58 +209,6 @@ func (q *Queries) ListByResponseID(ctx context.Context, responseID uuid.UUID) ([
 	return items, nil
 }
 
-const listForExport = `-- name: ListForExport :many
-SELECT a.id, a.response_id, a.question_id, a.value, a.created_at, a.updated_at, fr.submitted_at
-FROM answers a
-JOIN form_responses fr ON a.response_id = fr.id
-WHERE fr.form_id = $1
-  AND fr.progress = 'submitted'
-  AND a.question_id = ANY($2::uuid[])
-`
-
-type ListForExportParams struct {
-	FormID     uuid.UUID
-	QuestionID []uuid.UUID
-}
-
-type ListForExportRow struct {
-	ID          uuid.UUID
-	ResponseID  uuid.UUID
-	QuestionID  uuid.UUID
-	Value       []byte
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
-	SubmittedAt pgtype.Timestamptz
-}
-
-func (q *Queries) ListForExport(ctx context.Context, arg ListForExportParams) ([]ListForExportRow, error) {
-	rows, err := q.db.Query(ctx, listForExport, arg.FormID, arg.QuestionID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ListForExportRow
-	for rows.Next() {
-		var i ListForExportRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.ResponseID,
-			&i.QuestionID,
-			&i.Value,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.SubmittedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const update = `-- name: Update :one
 UPDATE answers
 SET value = $2, updated_at = now()
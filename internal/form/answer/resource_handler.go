@@ -3,16 +3,20 @@ package answer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/auth"
 	"NYCU-SDC/core-system-backend/internal/file"
 	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/internal/unit"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -20,19 +24,28 @@ import (
 
 type FileReferenceQuerier interface {
 	Get(ctx context.Context, id uuid.UUID) (Answer, error)
+	GetUnitID(ctx context.Context, id uuid.UUID) (pgtype.UUID, error)
 	BatchUpsert(ctx context.Context, arg BatchUpsertParams) ([]Answer, error)
 }
 
+// UnitMemberStore tells who belongs to the unit of a form, the users who can see its responses
+type UnitMemberStore interface {
+	GetMemberRole(ctx context.Context, unitID uuid.UUID, memberID uuid.UUID) (unit.UnitRole, error)
+	HasAdminInAncestorUnits(ctx context.Context, unitID uuid.UUID, userID uuid.UUID) (bool, error)
+}
+
 type FileResourceHandler struct {
 	logger  *zap.Logger
 	queries FileReferenceQuerier
+	units   UnitMemberStore
 	tracer  trace.Tracer
 }
 
-func NewFileResourceHandler(logger *zap.Logger, queries FileReferenceQuerier) *FileResourceHandler {
+func NewFileResourceHandler(logger *zap.Logger, queries FileReferenceQuerier, units UnitMemberStore) *FileResourceHandler {
 	return &FileResourceHandler{
 		logger:  logger,
 		queries: queries,
+		units:   units,
 		tracer:  otel.Tracer("answer/file_resource_handler"),
 	}
 }
@@ -46,13 +59,56 @@ func (h *FileResourceHandler) WithTx(tx pgx.Tx) (file.ResourceHandler, error) {
 			h.queries,
 		)
 	}
-	return NewFileResourceHandler(h.logger, q.WithTx(tx)), nil
+	return NewFileResourceHandler(h.logger, q.WithTx(tx), h.units), nil
 }
 
 func (h *FileResourceHandler) ResourceType() file.ResourceType {
 	return file.ResourceTypeFormAnswer
 }
 
+// CanAccessFile reports whether a user can see the responses of the form that holds the answer, which
+// takes the same unit role as GET /api/forms/{formId}/responses: membership of the unit of the form, or
+// admin of one of its ancestors.
+func (h *FileResourceHandler) CanAccessFile(ctx context.Context, resourceID uuid.UUID, userID uuid.UUID) (bool, error) {
+	traceCtx, span := h.tracer.Start(ctx, "CanAccessFile")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	unitID, err := h.queries.GetUnitID(traceCtx, resourceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answers", "id", resourceID.String(), logger, "get unit id of answer")
+		span.RecordError(err)
+		return false, err
+	}
+	if !unitID.Valid {
+		return false, nil
+	}
+
+	hasAdmin, err := h.units.HasAdminInAncestorUnits(traceCtx, unitID.Bytes, userID)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+	if hasAdmin {
+		return true, nil
+	}
+
+	role, err := h.units.GetMemberRole(traceCtx, unitID.Bytes, userID)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			return false, nil
+		}
+		span.RecordError(err)
+		return false, err
+	}
+
+	parsed, ok := auth.ParseRole(string(role))
+	return ok && parsed.Allow(auth.RoleMember), nil
+}
+
 func (h *FileResourceHandler) RemoveFileReference(ctx context.Context, fileID uuid.UUID, resourceID uuid.UUID) error {
 	traceCtx, span := h.tracer.Start(ctx, "RemoveFileReference")
 	defer span.End()
@@ -9,3 +9,14 @@ CREATE TABLE IF NOT EXISTS answers (
 );
 
 CREATE INDEX IF NOT EXISTS idx_answers_question_id ON answers(question_id);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Text of the string and number values of an answer, searched by the response list
+CREATE OR REPLACE FUNCTION answer_search_text(value JSONB) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+AS $$
+    SELECT jsonb_path_query_array(value, 'strict $.** ? (@.type() == "string" || @.type() == "number")')::text
+$$;
+
+CREATE INDEX IF NOT EXISTS idx_answers_search_text ON answers USING gin (answer_search_text(value) gin_trgm_ops);
@@ -24,7 +24,6 @@ import (
 
 type Querier interface {
 	ListByResponseID(ctx context.Context, responseID uuid.UUID) ([]Answer, error)
-	ListForExport(ctx context.Context, arg ListForExportParams) ([]ListForExportRow, error)
 	Get(ctx context.Context, id uuid.UUID) (Answer, error)
 	GetByResponseIDAndQuestionID(ctx context.Context, arg GetByResponseIDAndQuestionIDParams) (Answer, error)
 	BatchUpsert(ctx context.Context, arg BatchUpsertParams) ([]Answer, error)
@@ -45,7 +44,7 @@ type FileService interface {
 }
 
 type WorkflowResolver interface {
-	ResolveSections(ctx context.Context, formID uuid.UUID, answers []Answer, answerableMap map[string]question.Answerable) ([]uuid.UUID, error)
+	ResolveSectionsForResponse(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, answers []Answer, answerableMap map[string]question.Answerable) ([]uuid.UUID, error)
 }
 
 type Answerable interface {
@@ -136,24 +135,6 @@ func (s Service) List(ctx context.Context, formID, responseID uuid.UUID) ([]Answ
 	return transformedAnswers, answerableList, answerableMap, nil
 }
 
-func (s Service) ListForExport(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) ([]ListForExportRow, error) {
-	traceCtx, span := s.tracer.Start(ctx, "ListForExport")
-	defer span.End()
-	logger := logutil.WithContext(traceCtx, s.logger)
-
-	rows, err := s.queries.ListForExport(traceCtx, ListForExportParams{
-		FormID:     formID,
-		QuestionID: questionIDs,
-	})
-	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "list answers for export")
-		span.RecordError(err)
-		return nil, err
-	}
-
-	return rows, nil
-}
-
 func (s Service) Get(ctx context.Context, formID, responseID, questionID uuid.UUID) (Answer, Answerable, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Get")
 	defer span.End()
@@ -446,9 +427,9 @@ func extractChoicesFromStoredDetailedMultiAnswer(rawValue []byte) ([]question.Ch
 // UploadFiles uploads files for an upload_file question and upserts the answer.
 // It validates that the question exists, belongs to the form, and is of type upload_file.
 //
-// Existing upload_file entries are loaded first, then new file rows, answer upsert,
-// and file attachments are all executed within the same database transaction.
-// Any failure before commit rolls back the whole operation.
+// The files are saved and scanned first, then the existing upload_file entries are loaded and
+// the answer upsert and file attachments run within one database transaction. If a file is
+// rejected or the transaction fails, the files saved so far are deleted with their content.
 func (s Service) UploadFiles(ctx context.Context, formID, responseID, questionID uuid.UUID, files []*multipart.FileHeader, uploadedBy uuid.UUID) ([]shared.UploadFileEntry, Answer, Answerable, error) {
 	traceCtx, span := s.tracer.Start(ctx, "UploadFiles")
 	defer span.End()
@@ -474,14 +455,35 @@ func (s Service) UploadFiles(ctx context.Context, formID, responseID, questionID
 		return nil, Answer{}, nil, internal.ErrQuestionTypeMismatch
 	}
 
-	// Read existing uploaded files from the existing answer (if any) for append.
+	// Files are saved and scanned before the transaction, which a scan could otherwise hold open for minutes
+	savedFiles := make([]file.File, 0, len(files))
+	for _, fh := range files {
+		savedFile, err := s.saveUploadedFile(traceCtx, logger, fh, uploadedBy)
+		if err != nil {
+			span.RecordError(err)
+			s.discardSavedFiles(traceCtx, logger, savedFiles)
+			return nil, Answer{}, nil, err
+		}
+		savedFiles = append(savedFiles, savedFile)
+	}
+
+	entries := make([]shared.UploadFileEntry, 0, len(savedFiles))
+	for _, savedFile := range savedFiles {
+		entries = append(entries, shared.UploadFileEntry{
+			FileID:           savedFile.ID,
+			OriginalFilename: savedFile.OriginalFilename,
+			ContentType:      savedFile.ContentType,
+			Size:             savedFile.Size,
+		})
+	}
+
 	var (
-		newEntries     []shared.UploadFileEntry
 		upsertedAnswer Answer
 		mergedCount    int
 	)
 
 	err = s.withFileTransaction(traceCtx, func(qtx *Queries, ftx FileService) error {
+		// Read existing uploaded files from the existing answer (if any) for append.
 		existingEntries, err := s.loadPreviousUploadFileEntries(traceCtx, qtx, responseID, questionID)
 		if err != nil {
 			logger.Error("failed to load previous upload file entries",
@@ -492,39 +494,6 @@ func (s Service) UploadFiles(ctx context.Context, formID, responseID, questionID
 			return err
 		}
 
-		entries := make([]shared.UploadFileEntry, 0, len(files))
-
-		for _, fh := range files {
-			f, err := fh.Open()
-			if err != nil {
-				logger.Error("failed to open uploaded file", zap.String("filename", fh.Filename), zap.Error(err))
-				span.RecordError(err)
-				return fmt.Errorf("failed to open uploaded file %q: %w", fh.Filename, internal.ErrFailedToSaveFile)
-			}
-
-			savedFile, saveErr := ftx.SaveFile(traceCtx, f, fh.Filename, fh.Header.Get("Content-Type"), &uploadedBy)
-			closeErr := f.Close()
-			if closeErr != nil {
-				logger.Warn("failed to close uploaded file stream",
-					zap.String("filename", fh.Filename),
-					zap.Error(closeErr),
-				)
-			}
-
-			if saveErr != nil {
-				logger.Error("failed to save uploaded file", zap.String("filename", fh.Filename), zap.Error(saveErr))
-				span.RecordError(saveErr)
-				return fmt.Errorf("failed to save file %q: %w", fh.Filename, internal.ErrFailedToSaveFile)
-			}
-
-			entries = append(entries, shared.UploadFileEntry{
-				FileID:           savedFile.ID,
-				OriginalFilename: savedFile.OriginalFilename,
-				ContentType:      savedFile.ContentType,
-				Size:             savedFile.Size,
-			})
-		}
-
 		mergedEntries := make([]shared.UploadFileEntry, 0, len(existingEntries)+len(entries))
 		mergedEntries = append(mergedEntries, existingEntries...)
 		mergedEntries = append(mergedEntries, entries...)
@@ -570,12 +539,12 @@ func (s Service) UploadFiles(ctx context.Context, formID, responseID, questionID
 			}
 		}
 
-		newEntries = entries
 		upsertedAnswer = answer
 		mergedCount = len(mergedEntries)
 		return nil
 	})
 	if err != nil {
+		s.discardSavedFiles(traceCtx, logger, savedFiles)
 		return nil, Answer{}, nil, err
 	}
 
@@ -585,7 +554,51 @@ func (s Service) UploadFiles(ctx context.Context, formID, responseID, questionID
 		zap.Int("fileCount", mergedCount),
 	)
 
-	return newEntries, upsertedAnswer, answerable, nil
+	return entries, upsertedAnswer, answerable, nil
+}
+
+// saveUploadedFile saves and scans one file of an upload
+func (s Service) saveUploadedFile(ctx context.Context, logger *zap.Logger, fh *multipart.FileHeader, uploadedBy uuid.UUID) (file.File, error) {
+	f, err := fh.Open()
+	if err != nil {
+		logger.Error("failed to open uploaded file", zap.String("filename", fh.Filename), zap.Error(err))
+		return file.File{}, fmt.Errorf("failed to open uploaded file %q: %w", fh.Filename, internal.ErrFailedToSaveFile)
+	}
+
+	savedFile, saveErr := s.fileService.SaveFile(ctx, f, fh.Filename, fh.Header.Get("Content-Type"), &uploadedBy)
+	closeErr := f.Close()
+	if closeErr != nil {
+		logger.Warn("failed to close uploaded file stream",
+			zap.String("filename", fh.Filename),
+			zap.Error(closeErr),
+		)
+	}
+
+	if saveErr != nil {
+		if file.IsValidationError(saveErr) {
+			logger.Warn("uploaded file rejected", zap.String("filename", fh.Filename), zap.Error(saveErr))
+			return file.File{}, fmt.Errorf("file %q: %w", fh.Filename, saveErr)
+		}
+		logger.Error("failed to save uploaded file", zap.String("filename", fh.Filename), zap.Error(saveErr))
+		return file.File{}, fmt.Errorf("failed to save file %q: %w", fh.Filename, internal.ErrFailedToSaveFile)
+	}
+
+	return savedFile, nil
+}
+
+// discardSavedFiles deletes the files saved for an upload that failed, with their stored content.
+// They are deleted even if the request was canceled, which is often why the upload failed.
+func (s Service) discardSavedFiles(ctx context.Context, logger *zap.Logger, savedFiles []file.File) {
+	ctx = context.WithoutCancel(ctx)
+	for _, savedFile := range savedFiles {
+		err := s.fileService.DeletePhysicalFile(ctx, savedFile.ID)
+		if err != nil {
+			logger.Warn("failed to delete file of failed upload",
+				zap.String("fileID", savedFile.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
 func (s Service) loadPreviousUploadFileEntries(ctx context.Context, q Querier, responseID uuid.UUID, questionID uuid.UUID) ([]shared.UploadFileEntry, error) {
@@ -26,7 +26,7 @@ func (s Service) ValidatePatchAnswersAgainstWorkflow(
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	sectionIDs, err := s.workflowResolver.ResolveSections(traceCtx, formID, answersForWorkflow, answerableMap)
+	sectionIDs, err := s.workflowResolver.ResolveSectionsForResponse(traceCtx, formID, responseID, answersForWorkflow, answerableMap)
 	if err != nil {
 		if errors.Is(err, internal.ErrWorkflowNotFound) {
 			return nil
@@ -71,7 +71,7 @@ func (s Service) ValidatePatchAnswersAgainstWorkflow(
 }
 
 // ValidateUploadFilesAgainstWorkflow returns nil if the file upload may proceed.
-// ErrWorkflowNotFound is treated as no section constraint. Other ResolveSections errors are wrapped.
+// ErrWorkflowNotFound is treated as no section constraint. Other ResolveSectionsForResponse errors are wrapped.
 // If the question is missing from answerableMap, returns nil so UploadFiles can validate membership.
 func (s Service) ValidateUploadFilesAgainstWorkflow(
 	ctx context.Context,
@@ -85,7 +85,7 @@ func (s Service) ValidateUploadFilesAgainstWorkflow(
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	sectionIDs, err := s.workflowResolver.ResolveSections(traceCtx, formID, answersForWorkflow, answerableMap)
+	sectionIDs, err := s.workflowResolver.ResolveSectionsForResponse(traceCtx, formID, responseID, answersForWorkflow, answerableMap)
 	if err != nil {
 		if errors.Is(err, internal.ErrWorkflowNotFound) {
 			return nil
@@ -0,0 +1,168 @@
+package exportjob
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/user"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type JobResponse struct {
+	ID     string `json:"id"`
+	FormID string `json:"formId"`
+	Format string `json:"format"`
+	// Status is PENDING, RUNNING, COMPLETED or FAILED
+	Status         string `json:"status"`
+	ProcessedCount int32  `json:"processedCount"`
+	// TotalCount is 0 until a worker has started the job
+	TotalCount int32 `json:"totalCount"`
+	// FileID is the exported file once the job is completed
+	FileID      string     `json:"fileId,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+type Store interface {
+	Create(ctx context.Context, formID uuid.UUID, requestedBy uuid.UUID, questionIDs []uuid.UUID, filter response.ListFilter, format response.ExportFormat) (ResponseExportJob, error)
+	GetByFormID(ctx context.Context, formID uuid.UUID, id uuid.UUID) (ResponseExportJob, error)
+}
+
+type Handler struct {
+	logger        *zap.Logger
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+	store         Store
+	tracer        trace.Tracer
+}
+
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store) *Handler {
+	return &Handler{
+		logger:        logger,
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tracer:        otel.Tracer("exportjob/handler"),
+	}
+}
+
+func ToJobResponse(job ResponseExportJob) JobResponse {
+	resp := JobResponse{
+		ID:             job.ID.String(),
+		FormID:         job.FormID.String(),
+		Format:         job.Format,
+		Status:         strings.ToUpper(string(job.Status)),
+		ProcessedCount: job.ProcessedCount,
+		TotalCount:     job.TotalCount,
+		CreatedAt:      job.CreatedAt.Time,
+	}
+	if job.FileID.Valid {
+		resp.FileID = uuid.UUID(job.FileID.Bytes).String()
+	}
+	if job.Error.Valid {
+		resp.Error = job.Error.String
+	}
+	if job.StartedAt.Valid {
+		startedAt := job.StartedAt.Time
+		resp.StartedAt = &startedAt
+	}
+	if job.CompletedAt.Valid {
+		completedAt := job.CompletedAt.Time
+		resp.CompletedAt = &completedAt
+	}
+	return resp
+}
+
+// Create queues an export of the responses of a form; the requester is told through their inbox when
+// the file is ready (requires org member permission)
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Create")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	var req response.ExportDownloadRequest
+	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	questionIDs, err := response.ParseQuestionIDs(req.QuestionIDs)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	filter, err := req.Filter.ToListFilter()
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	format, err := response.ParseExportFormat(req.Format)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	job, err := h.store.Create(traceCtx, formID, currentUser.ID, questionIDs, filter, format)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusAccepted, ToJobResponse(job))
+}
+
+// Get reports the status and progress of an export job (requires org member permission)
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	jobID, err := handlerutil.ParseUUID(r.PathValue("jobId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	job, err := h.store.GetByFormID(traceCtx, formID, jobID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToJobResponse(job))
+}
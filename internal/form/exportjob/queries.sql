@@ -0,0 +1,52 @@
+-- name: Create :one
+INSERT INTO response_export_jobs (form_id, requested_by, question_ids, format, filter)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetByFormID :one
+SELECT * FROM response_export_jobs
+WHERE id = @id AND form_id = @form_id;
+
+-- name: Claim :one
+-- Claims the oldest pending job. SKIP LOCKED lets the workers of every replica claim concurrently
+-- without waiting on each other or claiming the same job.
+UPDATE response_export_jobs
+SET status = 'running', attempts = attempts + 1, processed_count = 0, started_at = now(), updated_at = now()
+WHERE id = (
+    SELECT id FROM response_export_jobs
+    WHERE status = 'pending'
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateProgress :exec
+-- Also serves as the heartbeat of a running job
+UPDATE response_export_jobs
+SET processed_count = @processed_count, total_count = @total_count, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'running';
+
+-- name: Complete :one
+-- Only the worker of the current attempt can end it; a worker whose job was requeued as stale updates no row
+UPDATE response_export_jobs
+SET status = 'completed', file_id = @file_id, processed_count = @processed_count, error = NULL, completed_at = now(), updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'running'
+RETURNING *;
+
+-- name: Fail :one
+UPDATE response_export_jobs
+SET status = 'failed', error = @error, completed_at = now(), updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'running'
+RETURNING *;
+
+-- name: RequeueStale :many
+-- Puts running jobs whose worker stopped sending heartbeats back in the queue, and fails those that
+-- have already been claimed max_attempts times
+UPDATE response_export_jobs
+SET status = CASE WHEN attempts >= @max_attempts::int THEN 'failed'::export_job_status ELSE 'pending'::export_job_status END,
+    error = CASE WHEN attempts >= @max_attempts::int THEN 'export was interrupted too many times' ELSE error END,
+    completed_at = CASE WHEN attempts >= @max_attempts::int THEN now() ELSE completed_at END,
+    updated_at = now()
+WHERE status = 'running' AND updated_at < @stale_before
+RETURNING *;
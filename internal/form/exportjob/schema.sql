@@ -0,0 +1,31 @@
+CREATE TYPE export_job_status AS ENUM(
+    'pending',
+    'running',
+    'completed',
+    'failed'
+);
+
+CREATE TABLE IF NOT EXISTS response_export_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_ids UUID[] NOT NULL,
+    format TEXT NOT NULL,
+    -- response.ListFilter the export was requested with
+    filter JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status export_job_status NOT NULL DEFAULT 'pending',
+    processed_count INTEGER NOT NULL DEFAULT 0,
+    total_count INTEGER NOT NULL DEFAULT 0,
+    -- Number of times a worker has claimed the job; jobs interrupted too often are failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    file_id UUID REFERENCES files(id) ON DELETE SET NULL,
+    error TEXT,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched while a job runs, so a running job whose updated_at is old was left by a crashed worker
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_export_jobs_status_created_at ON response_export_jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_response_export_jobs_form_id ON response_export_jobs(form_id);
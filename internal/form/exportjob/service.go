@@ -0,0 +1,397 @@
+package exportjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/queue"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// heartbeatInterval is how often a running job records its progress, which also marks it as alive
+	heartbeatInterval = 5 * time.Second
+)
+
+// policy fails a job whose export failed without retrying it, and requeues a running job that went a
+// minute without a heartbeat until it has been claimed 3 times. Idle workers look for jobs created by
+// other replicas and for stale jobs every PollInterval.
+var policy = queue.Policy{
+	MaxAttempts:  3,
+	StaleAfter:   time.Minute,
+	PollInterval: 10 * time.Second,
+}
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (ResponseExportJob, error)
+	GetByFormID(ctx context.Context, arg GetByFormIDParams) (ResponseExportJob, error)
+	Claim(ctx context.Context) (ResponseExportJob, error)
+	UpdateProgress(ctx context.Context, arg UpdateProgressParams) error
+	Complete(ctx context.Context, arg CompleteParams) (ResponseExportJob, error)
+	Fail(ctx context.Context, arg FailParams) (ResponseExportJob, error)
+	RequeueStale(ctx context.Context, arg RequeueStaleParams) ([]ResponseExportJob, error)
+}
+
+type Exporter interface {
+	PrepareExport(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID, filter response.ListFilter) (response.ExportPlan, error)
+	CountExport(ctx context.Context, plan response.ExportPlan) (int32, error)
+	WriteExport(ctx context.Context, plan response.ExportPlan, format response.ExportFormat, w io.Writer) error
+}
+
+type FileStore interface {
+	SaveFile(ctx context.Context, fileContent io.Reader, originalFilename, contentType string, uploadedBy *uuid.UUID, opts ...file.ValidatorOption) (file.File, error)
+}
+
+type FormStore interface {
+	Get(ctx context.Context, id uuid.UUID) (form.GetRow, error)
+}
+
+type Notifier interface {
+	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+}
+
+// Service runs response exports in the background. Create queues a job and a pool of workers builds the
+// export into a file, then tells the requester through their inbox.
+//
+// Every replica of the backend runs its own workers. Jobs are claimed with FOR UPDATE SKIP LOCKED, so each
+// job is built by one worker. A running job sends heartbeats; a job whose heartbeats stop, because the
+// replica running it crashed or was stopped, is put back in the queue when workers start and while they run.
+type Service struct {
+	logger  *zap.Logger
+	tracer  trace.Tracer
+	queries Querier
+	workers int
+
+	exporter  Exporter
+	fileStore FileStore
+	formStore FormStore
+	notifier  Notifier
+
+	// wake lets Create start an idle worker of this replica without waiting for the next poll
+	wake chan struct{}
+}
+
+func NewService(logger *zap.Logger, db DBTX, workers int, exporter Exporter, fileStore FileStore, formStore FormStore, notifier Notifier) *Service {
+	return &Service{
+		logger:    logger,
+		tracer:    otel.Tracer("exportjob/service"),
+		queries:   New(db),
+		workers:   workers,
+		exporter:  exporter,
+		fileStore: fileStore,
+		formStore: formStore,
+		notifier:  notifier,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Create checks the export and queues it. The questions and filter are checked now so that an invalid
+// export is rejected instead of failing later in a worker.
+func (s *Service) Create(ctx context.Context, formID uuid.UUID, requestedBy uuid.UUID, questionIDs []uuid.UUID, filter response.ListFilter, format response.ExportFormat) (ResponseExportJob, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	_, err := s.exporter.PrepareExport(traceCtx, formID, questionIDs, filter)
+	if err != nil {
+		span.RecordError(err)
+		return ResponseExportJob{}, err
+	}
+
+	filterJSON, err := json.Marshal(filter)
+	if err != nil {
+		span.RecordError(err)
+		return ResponseExportJob{}, fmt.Errorf("failed to encode export filter: %w", err)
+	}
+
+	job, err := s.queries.Create(traceCtx, CreateParams{
+		FormID:      formID,
+		RequestedBy: requestedBy,
+		QuestionIds: questionIDs,
+		Format:      string(format),
+		Filter:      filterJSON,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_export_jobs", "form_id", formID.String(), logger, "create export job")
+		span.RecordError(err)
+		return ResponseExportJob{}, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	logger.Info("Queued export job",
+		zap.String("job_id", job.ID.String()),
+		zap.String("form_id", formID.String()),
+		zap.String("format", string(format)),
+	)
+
+	return job, nil
+}
+
+// GetByFormID returns an export job of a form
+func (s *Service) GetByFormID(ctx context.Context, formID uuid.UUID, id uuid.UUID) (ResponseExportJob, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetByFormID")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	job, err := s.queries.GetByFormID(traceCtx, GetByFormIDParams{ID: id, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ResponseExportJob{}, internal.ErrExportJobNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_export_jobs", "id", id.String(), logger, "get export job")
+		span.RecordError(err)
+		return ResponseExportJob{}, err
+	}
+
+	return job, nil
+}
+
+// Run requeues the jobs left by stopped workers, then runs the workers until ctx is canceled
+func (s *Service) Run(ctx context.Context) {
+	s.worker().Run(ctx, s.workers, s.wake)
+}
+
+// RequeueStale puts running jobs that stopped sending heartbeats back in the queue, or fails them once
+// they have been claimed policy.MaxAttempts times
+func (s *Service) RequeueStale(ctx context.Context) int {
+	return s.worker().RequeueStale(ctx)
+}
+
+// ProcessNext claims the oldest pending job and builds it; it returns false if there was no job to claim.
+// A job whose export failed is failed at once.
+func (s *Service) ProcessNext(ctx context.Context) bool {
+	return s.worker().ProcessNext(ctx)
+}
+
+// jobAttempt is a claimed job, and the file its export was saved to once it is built
+type jobAttempt struct {
+	ResponseExportJob
+	savedFile file.File
+	written   int32
+}
+
+// worker builds the queued export jobs and tells their requesters how they ended
+func (s *Service) worker() *queue.Worker[*jobAttempt] {
+	return &queue.Worker[*jobAttempt]{
+		Name:   "export jobs",
+		Logger: s.logger,
+		Tracer: s.tracer,
+		Policy: policy,
+		Claim: func(ctx context.Context) (*jobAttempt, error) {
+			job, err := s.queries.Claim(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &jobAttempt{ResponseExportJob: job}, nil
+		},
+		Attempts: func(attempt *jobAttempt) int32 { return attempt.Attempts },
+		Fields: func(attempt *jobAttempt) []zap.Field {
+			return []zap.Field{zap.String("job_id", attempt.ID.String()), zap.String("form_id", attempt.FormID.String())}
+		},
+		Attempt: func(ctx context.Context, attempt *jobAttempt) error {
+			var err error
+			attempt.savedFile, attempt.written, err = s.build(ctx, attempt.ResponseExportJob)
+			return err
+		},
+		Succeed: func(ctx context.Context, attempt *jobAttempt) (int64, error) {
+			completed, err := s.queries.Complete(ctx, CompleteParams{
+				FileID:         pgtype.UUID{Bytes: attempt.savedFile.ID, Valid: true},
+				ProcessedCount: attempt.written,
+				ID:             attempt.ID,
+				Attempts:       attempt.Attempts,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+			s.notify(ctx, completed)
+			return 1, nil
+		},
+		Fail: func(ctx context.Context, attempt *jobAttempt, buildErr error) (int64, error) {
+			failed, err := s.queries.Fail(ctx, FailParams{
+				Error:    pgtype.Text{String: buildErr.Error(), Valid: true},
+				ID:       attempt.ID,
+				Attempts: attempt.Attempts,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+			s.notify(ctx, failed)
+			return 1, nil
+		},
+		Requeue: func(ctx context.Context, maxAttempts int32, staleBefore time.Time) ([]queue.Stale, error) {
+			jobs, err := s.queries.RequeueStale(ctx, RequeueStaleParams{
+				MaxAttempts: maxAttempts,
+				StaleBefore: pgtype.Timestamptz{Time: staleBefore, Valid: true},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			stale := make([]queue.Stale, 0, len(jobs))
+			for _, job := range jobs {
+				if job.Status == ExportJobStatusFailed {
+					s.notify(ctx, job)
+				}
+				stale = append(stale, queue.Stale{ID: job.ID, Failed: job.Status == ExportJobStatusFailed})
+			}
+			return stale, nil
+		},
+	}
+}
+
+// build writes the export of a job to a temporary file and saves it, sending heartbeats with the
+// number of responses written until it returns
+func (s *Service) build(ctx context.Context, job ResponseExportJob) (file.File, int32, error) {
+	traceCtx, span := s.tracer.Start(ctx, "build")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	var filter response.ListFilter
+	err := json.Unmarshal(job.Filter, &filter)
+	if err != nil {
+		return file.File{}, 0, fmt.Errorf("failed to decode export filter: %w", err)
+	}
+
+	format, err := response.ParseExportFormat(job.Format)
+	if err != nil {
+		return file.File{}, 0, err
+	}
+
+	plan, err := s.exporter.PrepareExport(traceCtx, job.FormID, job.QuestionIds, filter)
+	if err != nil {
+		return file.File{}, 0, err
+	}
+
+	total, err := s.exporter.CountExport(traceCtx, plan)
+	if err != nil {
+		return file.File{}, 0, err
+	}
+
+	var written atomic.Int32
+	plan.OnProgress = func(n int) {
+		written.Store(int32(n))
+	}
+
+	stopHeartbeat := s.startHeartbeat(traceCtx, job, &written, total)
+	defer stopHeartbeat()
+
+	tmp, err := os.CreateTemp("", "export-*."+format.Extension())
+	if err != nil {
+		return file.File{}, 0, fmt.Errorf("failed to create temporary export file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		err := os.Remove(tmp.Name())
+		if err != nil {
+			logger.Warn("Failed to remove temporary export file", zap.Error(err))
+		}
+	}()
+
+	err = s.exporter.WriteExport(traceCtx, plan, format, tmp)
+	if err != nil {
+		return file.File{}, 0, err
+	}
+
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return file.File{}, 0, fmt.Errorf("failed to rewind temporary export file: %w", err)
+	}
+
+	savedFile, err := s.fileStore.SaveFile(traceCtx, tmp, plan.FormTitle+"."+format.Extension(), format.ContentType(), &job.RequestedBy)
+	if err != nil {
+		return file.File{}, 0, err
+	}
+
+	return savedFile, written.Load(), nil
+}
+
+// startHeartbeat records the progress of the current attempt of a running job every heartbeatInterval
+// until the returned function is called
+func (s *Service) startHeartbeat(ctx context.Context, job ResponseExportJob, written *atomic.Int32, total int32) func() {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	beat := func() {
+		err := s.queries.UpdateProgress(ctx, UpdateProgressParams{
+			ProcessedCount: written.Load(),
+			TotalCount:     total,
+			ID:             job.ID,
+			Attempts:       job.Attempts,
+		})
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to record export job progress", zap.String("job_id", job.ID.String()), zap.Error(err))
+		}
+	}
+	beat()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				beat()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// notify delivers a finished job to the inbox of its requester, posted by the unit of the form
+func (s *Service) notify(ctx context.Context, job ResponseExportJob) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	formRow, err := s.formStore.Get(ctx, job.FormID)
+	if err != nil {
+		logger.Error("Failed to get form of export job", zap.String("job_id", job.ID.String()), zap.Error(err))
+		return
+	}
+	if !formRow.UnitID.Valid {
+		logger.Warn("Form of export job has no unit to post the inbox message", zap.String("job_id", job.ID.String()))
+		return
+	}
+
+	_, err = s.notifier.Create(ctx, inbox.ContentTypeExport, job.ID, []uuid.UUID{job.RequestedBy}, formRow.UnitID.Bytes)
+	if err != nil {
+		logger.Error("Failed to deliver export job to inbox", zap.String("job_id", job.ID.String()), zap.Error(err))
+	}
+}
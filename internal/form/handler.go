@@ -33,6 +33,18 @@ type DressingRequest struct {
 	TextFont     string `json:"textFont" validate:"omitempty,font"`
 }
 
+// DeadlineRemindersRequest sets the deadline reminders of a form; users who have not submitted a response
+// are reminded the given numbers of hours before the deadline
+type DeadlineRemindersRequest struct {
+	Enabled     *bool   `json:"enabled"`
+	OffsetHours []int32 `json:"offsetHours" validate:"omitempty,max=5,unique,dive,min=1,max=720"`
+}
+
+type DeadlineRemindersResponse struct {
+	Enabled     bool    `json:"enabled"`
+	OffsetHours []int32 `json:"offsetHours"`
+}
+
 type Request struct {
 	Title                  string           `json:"title" validate:"required"`
 	Description            json.RawMessage  `json:"description"`
@@ -48,39 +60,41 @@ type Request struct {
 }
 
 type PatchRequest struct {
-	Title                  *string            `json:"title" validate:"omitempty"`
-	Description            OptionalRawMessage `json:"description"`
-	PreviewMessage         *string            `json:"previewMessage"`
-	Deadline               *time.Time         `json:"deadline"`
-	PublishTime            *time.Time         `json:"publishTime"`
-	MessageAfterSubmission *string            `json:"messageAfterSubmission" validate:"omitempty"`
-	GoogleSheetURL         *string            `json:"googleSheetUrl"`
-	Visibility             *string            `json:"visibility" validate:"omitempty,oneof=PUBLIC PRIVATE"`
-	CoverImageURL          *string            `json:"coverImageUrl"`
-	Dressing               *DressingRequest   `json:"dressing"`
-	AllowEditResponse      *bool              `json:"allowEditResponse"`
+	Title                  *string                   `json:"title" validate:"omitempty"`
+	Description            OptionalRawMessage        `json:"description"`
+	PreviewMessage         *string                   `json:"previewMessage"`
+	Deadline               *time.Time                `json:"deadline"`
+	PublishTime            *time.Time                `json:"publishTime"`
+	MessageAfterSubmission *string                   `json:"messageAfterSubmission" validate:"omitempty"`
+	GoogleSheetURL         *string                   `json:"googleSheetUrl"`
+	Visibility             *string                   `json:"visibility" validate:"omitempty,oneof=PUBLIC PRIVATE"`
+	CoverImageURL          *string                   `json:"coverImageUrl"`
+	Dressing               *DressingRequest          `json:"dressing"`
+	AllowEditResponse      *bool                     `json:"allowEditResponse"`
+	DeadlineReminders      *DeadlineRemindersRequest `json:"deadlineReminders"`
 }
 
 type Response struct {
-	ID                     string               `json:"id"`
-	Title                  string               `json:"title"`
-	Description            json.RawMessage      `json:"description"`
-	DescriptionHTML        string               `json:"descriptionHtml,omitempty"`
-	PreviewMessage         string               `json:"previewMessage"`
-	Status                 string               `json:"status"`
-	UnitID                 string               `json:"unitId"`
-	Creator                user.ProfileResponse `json:"creator"`
-	LastEditor             user.ProfileResponse `json:"lastEditor"`
-	Deadline               *time.Time           `json:"deadline"`
-	CreatedAt              time.Time            `json:"createdAt"`
-	UpdatedAt              time.Time            `json:"updatedAt"`
-	PublishTime            *time.Time           `json:"publishTime"`
-	MessageAfterSubmission string               `json:"messageAfterSubmission"`
-	GoogleSheetURL         string               `json:"googleSheetUrl"`
-	Visibility             string               `json:"visibility"`
-	CoverImage             string               `json:"coverImage"`
-	Dressing               DressingRequest      `json:"dressing"`
-	AllowEditResponse      bool                 `json:"allowEditResponse"`
+	ID                     string                    `json:"id"`
+	Title                  string                    `json:"title"`
+	Description            json.RawMessage           `json:"description"`
+	DescriptionHTML        string                    `json:"descriptionHtml,omitempty"`
+	PreviewMessage         string                    `json:"previewMessage"`
+	Status                 string                    `json:"status"`
+	UnitID                 string                    `json:"unitId"`
+	Creator                user.ProfileResponse      `json:"creator"`
+	LastEditor             user.ProfileResponse      `json:"lastEditor"`
+	Deadline               *time.Time                `json:"deadline"`
+	CreatedAt              time.Time                 `json:"createdAt"`
+	UpdatedAt              time.Time                 `json:"updatedAt"`
+	PublishTime            *time.Time                `json:"publishTime"`
+	MessageAfterSubmission string                    `json:"messageAfterSubmission"`
+	GoogleSheetURL         string                    `json:"googleSheetUrl"`
+	Visibility             string                    `json:"visibility"`
+	CoverImage             string                    `json:"coverImage"`
+	Dressing               DressingRequest           `json:"dressing"`
+	AllowEditResponse      bool                      `json:"allowEditResponse"`
+	DeadlineReminders      DeadlineRemindersResponse `json:"deadlineReminders"`
 }
 
 type CoverUploadResponse struct {
@@ -211,6 +225,10 @@ func ToResponse(
 			TextFont:     form.DressingTextFont.String,
 		},
 		AllowEditResponse: form.AllowEditResponse,
+		DeadlineReminders: DeadlineRemindersResponse{
+			Enabled:     form.DeadlineRemindersEnabled,
+			OffsetHours: form.DeadlineReminderHours,
+		},
 	}
 }
 
@@ -222,8 +240,8 @@ type Store interface {
 	List(ctx context.Context, status Status, visibility Visibility, excludeExpired bool) ([]ListRow, error)
 	ListByUnit(ctx context.Context, arg ListByUnitParams) ([]ListByUnitRow, error)
 	SetStatus(ctx context.Context, id uuid.UUID, status Status, userID uuid.UUID) (Form, error)
-	UploadCoverImage(ctx context.Context, id uuid.UUID, data []byte, coverImageURL string) error
-	GetCoverImage(ctx context.Context, id uuid.UUID) ([]byte, error)
+	UploadCoverImage(ctx context.Context, id uuid.UUID, fileID uuid.UUID, coverImageURL string) error
+	GetCoverFileID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 }
 
 type tenantStore interface {
@@ -238,6 +256,12 @@ type questionStore interface {
 type FileStore interface {
 	SaveFile(ctx context.Context, fileContent io.Reader, originalFilename, contentType string, uploadedBy *uuid.UUID, opts ...file.ValidatorOption) (file.File, error)
 	Get(ctx context.Context, id uuid.UUID) (file.File, error)
+	Open(ctx context.Context, file file.File) (io.ReadSeekCloser, error)
+}
+
+// Closer closes a form and records its form.closed event in the outbox in the same transaction
+type Closer interface {
+	CloseForm(ctx context.Context, id uuid.UUID, userID uuid.UUID) (Form, error)
 }
 
 type Handler struct {
@@ -252,6 +276,7 @@ type Handler struct {
 	questionStore questionStore
 	fileStore     FileStore
 	markdownStore MarkdownStore
+	closer        Closer
 }
 
 func NewHandler(
@@ -263,6 +288,7 @@ func NewHandler(
 	questionStore questionStore,
 	fileStore FileStore,
 	markdownStore MarkdownStore,
+	closer Closer,
 ) *Handler {
 	return &Handler{
 		logger:        logger,
@@ -274,141 +300,152 @@ func NewHandler(
 		questionStore: questionStore,
 		fileStore:     fileStore,
 		markdownStore: markdownStore,
+		closer:        closer,
 	}
 }
 
 func formFromCreateRow(r CreateRow) Form {
 	return Form{
-		ID:                     r.ID,
-		Title:                  r.Title,
-		DescriptionJson:        r.DescriptionJson,
-		DescriptionHtml:        r.DescriptionHtml,
-		PreviewMessage:         r.PreviewMessage,
-		Status:                 r.Status,
-		UnitID:                 r.UnitID,
-		CreatedBy:              r.CreatedBy,
-		LastEditor:             r.LastEditor,
-		Deadline:               r.Deadline,
-		CreatedAt:              r.CreatedAt,
-		UpdatedAt:              r.UpdatedAt,
-		MessageAfterSubmission: r.MessageAfterSubmission,
-		Visibility:             r.Visibility,
-		GoogleSheetUrl:         r.GoogleSheetUrl,
-		PublishTime:            r.PublishTime,
-		CoverImageUrl:          r.CoverImageUrl,
-		DressingColor:          r.DressingColor,
-		DressingHeaderFont:     r.DressingHeaderFont,
-		DressingQuestionFont:   r.DressingQuestionFont,
-		DressingTextFont:       r.DressingTextFont,
-		AllowEditResponse:      r.AllowEditResponse,
+		ID:                       r.ID,
+		Title:                    r.Title,
+		DescriptionJson:          r.DescriptionJson,
+		DescriptionHtml:          r.DescriptionHtml,
+		PreviewMessage:           r.PreviewMessage,
+		Status:                   r.Status,
+		UnitID:                   r.UnitID,
+		CreatedBy:                r.CreatedBy,
+		LastEditor:               r.LastEditor,
+		Deadline:                 r.Deadline,
+		CreatedAt:                r.CreatedAt,
+		UpdatedAt:                r.UpdatedAt,
+		MessageAfterSubmission:   r.MessageAfterSubmission,
+		Visibility:               r.Visibility,
+		GoogleSheetUrl:           r.GoogleSheetUrl,
+		PublishTime:              r.PublishTime,
+		CoverImageUrl:            r.CoverImageUrl,
+		DressingColor:            r.DressingColor,
+		DressingHeaderFont:       r.DressingHeaderFont,
+		DressingQuestionFont:     r.DressingQuestionFont,
+		DressingTextFont:         r.DressingTextFont,
+		AllowEditResponse:        r.AllowEditResponse,
+		DeadlineRemindersEnabled: r.DeadlineRemindersEnabled,
+		DeadlineReminderHours:    r.DeadlineReminderHours,
 	}
 }
 
 func formFromGetRow(r GetRow) Form {
 	return Form{
-		ID:                     r.ID,
-		Title:                  r.Title,
-		DescriptionJson:        r.DescriptionJson,
-		DescriptionHtml:        r.DescriptionHtml,
-		PreviewMessage:         r.PreviewMessage,
-		Status:                 r.Status,
-		UnitID:                 r.UnitID,
-		CreatedBy:              r.CreatedBy,
-		LastEditor:             r.LastEditor,
-		Deadline:               r.Deadline,
-		CreatedAt:              r.CreatedAt,
-		UpdatedAt:              r.UpdatedAt,
-		MessageAfterSubmission: r.MessageAfterSubmission,
-		Visibility:             r.Visibility,
-		GoogleSheetUrl:         r.GoogleSheetUrl,
-		PublishTime:            r.PublishTime,
-		CoverImageUrl:          r.CoverImageUrl,
-		DressingColor:          r.DressingColor,
-		DressingHeaderFont:     r.DressingHeaderFont,
-		DressingQuestionFont:   r.DressingQuestionFont,
-		DressingTextFont:       r.DressingTextFont,
-		AllowEditResponse:      r.AllowEditResponse,
+		ID:                       r.ID,
+		Title:                    r.Title,
+		DescriptionJson:          r.DescriptionJson,
+		DescriptionHtml:          r.DescriptionHtml,
+		PreviewMessage:           r.PreviewMessage,
+		Status:                   r.Status,
+		UnitID:                   r.UnitID,
+		CreatedBy:                r.CreatedBy,
+		LastEditor:               r.LastEditor,
+		Deadline:                 r.Deadline,
+		CreatedAt:                r.CreatedAt,
+		UpdatedAt:                r.UpdatedAt,
+		MessageAfterSubmission:   r.MessageAfterSubmission,
+		Visibility:               r.Visibility,
+		GoogleSheetUrl:           r.GoogleSheetUrl,
+		PublishTime:              r.PublishTime,
+		CoverImageUrl:            r.CoverImageUrl,
+		DressingColor:            r.DressingColor,
+		DressingHeaderFont:       r.DressingHeaderFont,
+		DressingQuestionFont:     r.DressingQuestionFont,
+		DressingTextFont:         r.DressingTextFont,
+		AllowEditResponse:        r.AllowEditResponse,
+		DeadlineRemindersEnabled: r.DeadlineRemindersEnabled,
+		DeadlineReminderHours:    r.DeadlineReminderHours,
 	}
 }
 
 func formFromPatchRow(r PatchRow) Form {
 	return Form{
-		ID:                     r.ID,
-		Title:                  r.Title,
-		DescriptionJson:        r.DescriptionJson,
-		DescriptionHtml:        r.DescriptionHtml,
-		PreviewMessage:         r.PreviewMessage,
-		Status:                 r.Status,
-		UnitID:                 r.UnitID,
-		CreatedBy:              r.CreatedBy,
-		LastEditor:             r.LastEditor,
-		Deadline:               r.Deadline,
-		CreatedAt:              r.CreatedAt,
-		UpdatedAt:              r.UpdatedAt,
-		MessageAfterSubmission: r.MessageAfterSubmission,
-		Visibility:             r.Visibility,
-		GoogleSheetUrl:         r.GoogleSheetUrl,
-		PublishTime:            r.PublishTime,
-		CoverImageUrl:          r.CoverImageUrl,
-		DressingColor:          r.DressingColor,
-		DressingHeaderFont:     r.DressingHeaderFont,
-		DressingQuestionFont:   r.DressingQuestionFont,
-		DressingTextFont:       r.DressingTextFont,
-		AllowEditResponse:      r.AllowEditResponse,
+		ID:                       r.ID,
+		Title:                    r.Title,
+		DescriptionJson:          r.DescriptionJson,
+		DescriptionHtml:          r.DescriptionHtml,
+		PreviewMessage:           r.PreviewMessage,
+		Status:                   r.Status,
+		UnitID:                   r.UnitID,
+		CreatedBy:                r.CreatedBy,
+		LastEditor:               r.LastEditor,
+		Deadline:                 r.Deadline,
+		CreatedAt:                r.CreatedAt,
+		UpdatedAt:                r.UpdatedAt,
+		MessageAfterSubmission:   r.MessageAfterSubmission,
+		Visibility:               r.Visibility,
+		GoogleSheetUrl:           r.GoogleSheetUrl,
+		PublishTime:              r.PublishTime,
+		CoverImageUrl:            r.CoverImageUrl,
+		DressingColor:            r.DressingColor,
+		DressingHeaderFont:       r.DressingHeaderFont,
+		DressingQuestionFont:     r.DressingQuestionFont,
+		DressingTextFont:         r.DressingTextFont,
+		AllowEditResponse:        r.AllowEditResponse,
+		DeadlineRemindersEnabled: r.DeadlineRemindersEnabled,
+		DeadlineReminderHours:    r.DeadlineReminderHours,
 	}
 }
 
 func formFromListRow(r ListRow) Form {
 	return Form{
-		ID:                     r.ID,
-		Title:                  r.Title,
-		DescriptionJson:        r.DescriptionJson,
-		DescriptionHtml:        r.DescriptionHtml,
-		PreviewMessage:         r.PreviewMessage,
-		Status:                 r.Status,
-		UnitID:                 r.UnitID,
-		CreatedBy:              r.CreatedBy,
-		LastEditor:             r.LastEditor,
-		Deadline:               r.Deadline,
-		CreatedAt:              r.CreatedAt,
-		UpdatedAt:              r.UpdatedAt,
-		MessageAfterSubmission: r.MessageAfterSubmission,
-		Visibility:             r.Visibility,
-		GoogleSheetUrl:         r.GoogleSheetUrl,
-		PublishTime:            r.PublishTime,
-		CoverImageUrl:          r.CoverImageUrl,
-		DressingColor:          r.DressingColor,
-		DressingHeaderFont:     r.DressingHeaderFont,
-		DressingQuestionFont:   r.DressingQuestionFont,
-		DressingTextFont:       r.DressingTextFont,
-		AllowEditResponse:      r.AllowEditResponse,
+		ID:                       r.ID,
+		Title:                    r.Title,
+		DescriptionJson:          r.DescriptionJson,
+		DescriptionHtml:          r.DescriptionHtml,
+		PreviewMessage:           r.PreviewMessage,
+		Status:                   r.Status,
+		UnitID:                   r.UnitID,
+		CreatedBy:                r.CreatedBy,
+		LastEditor:               r.LastEditor,
+		Deadline:                 r.Deadline,
+		CreatedAt:                r.CreatedAt,
+		UpdatedAt:                r.UpdatedAt,
+		MessageAfterSubmission:   r.MessageAfterSubmission,
+		Visibility:               r.Visibility,
+		GoogleSheetUrl:           r.GoogleSheetUrl,
+		PublishTime:              r.PublishTime,
+		CoverImageUrl:            r.CoverImageUrl,
+		DressingColor:            r.DressingColor,
+		DressingHeaderFont:       r.DressingHeaderFont,
+		DressingQuestionFont:     r.DressingQuestionFont,
+		DressingTextFont:         r.DressingTextFont,
+		AllowEditResponse:        r.AllowEditResponse,
+		DeadlineRemindersEnabled: r.DeadlineRemindersEnabled,
+		DeadlineReminderHours:    r.DeadlineReminderHours,
 	}
 }
 
 func formFromListByUnitRow(r ListByUnitRow) Form {
 	return Form{
-		ID:                     r.ID,
-		Title:                  r.Title,
-		DescriptionJson:        r.DescriptionJson,
-		DescriptionHtml:        r.DescriptionHtml,
-		PreviewMessage:         r.PreviewMessage,
-		Status:                 r.Status,
-		UnitID:                 r.UnitID,
-		CreatedBy:              r.CreatedBy,
-		LastEditor:             r.LastEditor,
-		Deadline:               r.Deadline,
-		CreatedAt:              r.CreatedAt,
-		UpdatedAt:              r.UpdatedAt,
-		MessageAfterSubmission: r.MessageAfterSubmission,
-		Visibility:             r.Visibility,
-		GoogleSheetUrl:         r.GoogleSheetUrl,
-		PublishTime:            r.PublishTime,
-		CoverImageUrl:          r.CoverImageUrl,
-		DressingColor:          r.DressingColor,
-		DressingHeaderFont:     r.DressingHeaderFont,
-		DressingQuestionFont:   r.DressingQuestionFont,
-		DressingTextFont:       r.DressingTextFont,
-		AllowEditResponse:      r.AllowEditResponse,
+		ID:                       r.ID,
+		Title:                    r.Title,
+		DescriptionJson:          r.DescriptionJson,
+		DescriptionHtml:          r.DescriptionHtml,
+		PreviewMessage:           r.PreviewMessage,
+		Status:                   r.Status,
+		UnitID:                   r.UnitID,
+		CreatedBy:                r.CreatedBy,
+		LastEditor:               r.LastEditor,
+		Deadline:                 r.Deadline,
+		CreatedAt:                r.CreatedAt,
+		UpdatedAt:                r.UpdatedAt,
+		MessageAfterSubmission:   r.MessageAfterSubmission,
+		Visibility:               r.Visibility,
+		GoogleSheetUrl:           r.GoogleSheetUrl,
+		PublishTime:              r.PublishTime,
+		CoverImageUrl:            r.CoverImageUrl,
+		DressingColor:            r.DressingColor,
+		DressingHeaderFont:       r.DressingHeaderFont,
+		DressingQuestionFont:     r.DressingQuestionFont,
+		DressingTextFont:         r.DressingTextFont,
+		AllowEditResponse:        r.AllowEditResponse,
+		DeadlineRemindersEnabled: r.DeadlineRemindersEnabled,
+		DeadlineReminderHours:    r.DeadlineReminderHours,
 	}
 }
 
@@ -662,7 +699,7 @@ func (h *Handler) UploadCoverImage(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	// Save to file service with WebP validation (system upload, no user attribution)
+	// Save to file service with WebP validation and resized variants (system upload, no user attribution)
 	savedFile, err := h.fileStore.SaveFile(
 		traceCtx,
 		fileData,
@@ -671,6 +708,8 @@ func (h *Handler) UploadCoverImage(w http.ResponseWriter, r *http.Request) {
 		nil, // system upload
 		file.WithWebP(),
 		file.WithMaxSize(maxBytes),
+		file.WithImageVariants(),
+		file.WithPublicAccess(),
 	)
 	if err != nil {
 		logger.Error("Failed to save cover image", zap.Error(err))
@@ -679,9 +718,10 @@ func (h *Handler) UploadCoverImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update form's cover_image_url
-	coverImageURL := fmt.Sprintf("/api/forms/%s/cover", formID.String())
-	if err := h.store.UploadCoverImage(traceCtx, formID, savedFile.Data, coverImageURL); err != nil {
+	// Update form's cover_image_url. The cover is served by the file service, which also serves its
+	// resized variants through ?size=; GET /api/forms/{formId}/cover serves the same file.
+	coverImageURL := fmt.Sprintf("/api/files/%s", savedFile.ID.String())
+	if err := h.store.UploadCoverImage(traceCtx, formID, savedFile.ID, coverImageURL); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
@@ -701,18 +741,36 @@ func (h *Handler) GetCoverImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	imageData, err := h.store.GetCoverImage(traceCtx, id)
+	fileID, err := h.store.GetCoverFileID(traceCtx, id)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	w.Header().Set("Content-Type", "image/webp")
-	_, err = w.Write(imageData)
+	cover, err := h.fileStore.Get(traceCtx, fileID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
+
+	content, err := h.fileStore.Open(traceCtx, cover)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		span.RecordError(err)
+		return
+	}
+	defer func() {
+		err := content.Close()
+		if err != nil {
+			logger.Warn("Failed to close cover image", zap.Error(err), zap.String("file_id", fileID.String()))
+		}
+	}()
+
+	w.Header().Set("Content-Type", cover.ContentType)
+	if cover.Checksum.Valid {
+		w.Header().Set("ETag", "\""+cover.Checksum.String+"\"")
+	}
+	http.ServeContent(w, r, cover.OriginalFilename, cover.CreatedAt.Time, content)
 }
 
 func (h *Handler) Archive(w http.ResponseWriter, r *http.Request) {
@@ -815,7 +873,7 @@ func (h *Handler) Close(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.store.SetStatus(traceCtx, id, StatusClosed, currentUser.ID)
+	_, err = h.closer.CloseForm(traceCtx, id, currentUser.ID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
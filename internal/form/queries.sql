@@ -87,6 +87,8 @@ WITH updated AS (
         dressing_question_font = COALESCE(sqlc.narg('dressing_question_font')::text, forms.dressing_question_font),
         dressing_text_font = COALESCE(sqlc.narg('dressing_text_font')::text, forms.dressing_text_font),
         allow_edit_response = COALESCE(sqlc.narg('allow_edit_response')::boolean, forms.allow_edit_response),
+        deadline_reminders_enabled = COALESCE(sqlc.narg('deadline_reminders_enabled')::boolean, forms.deadline_reminders_enabled),
+        deadline_reminder_hours = COALESCE(sqlc.narg('deadline_reminder_hours')::int[], forms.deadline_reminder_hours),
         updated_at = now()
     WHERE forms.id = sqlc.arg('id')
     RETURNING *
@@ -211,12 +213,31 @@ SET status = $2, last_editor = $3, updated_at = now()
 WHERE id = $1
 RETURNING *;
 
+-- name: ClaimDueForPublish :one
+SELECT id, unit_id, last_editor
+FROM forms
+WHERE status = 'draft'
+  AND publish_time IS NOT NULL
+  AND publish_time <= now()
+  AND NOT (id = ANY(@skip_ids::uuid[]))
+ORDER BY publish_time
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: CloseExpired :many
+UPDATE forms
+SET status = 'closed', updated_at = now()
+WHERE status = 'published'
+  AND deadline IS NOT NULL
+  AND deadline <= now()
+RETURNING id;
+
 -- name: UploadCoverImage :one
 WITH upsert AS (
-    INSERT INTO form_covers (form_id, image_data)
+    INSERT INTO form_covers (form_id, file_id)
     VALUES ($1, $2)
     ON CONFLICT (form_id) DO UPDATE
-        SET image_data = EXCLUDED.image_data,
+        SET file_id = EXCLUDED.file_id,
             updated_at = now()
     RETURNING form_id
 )
@@ -226,8 +247,8 @@ SET cover_image_url = $3,
 WHERE id = (SELECT form_id FROM upsert)
 RETURNING id;
 
--- name: GetCoverImage :one
-SELECT image_data FROM form_covers WHERE form_id = $1;
+-- name: GetCoverFileID :one
+SELECT file_id FROM form_covers WHERE form_id = $1;
 
 -- name: GetUnitID :one
 SELECT unit_id
@@ -12,6 +12,60 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimDueForPublish = `-- name: ClaimDueForPublish :one
+SELECT id, unit_id, last_editor
+FROM forms
+WHERE status = 'draft'
+  AND publish_time IS NOT NULL
+  AND publish_time <= now()
+  AND NOT (id = ANY($1::uuid[]))
+ORDER BY publish_time
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+type ClaimDueForPublishRow struct {
+	ID         uuid.UUID
+	UnitID     pgtype.UUID
+	LastEditor uuid.UUID
+}
+
+func (q *Queries) ClaimDueForPublish(ctx context.Context, skipIds []uuid.UUID) (ClaimDueForPublishRow, error) {
+	row := q.db.QueryRow(ctx, claimDueForPublish, skipIds)
+	var i ClaimDueForPublishRow
+	err := row.Scan(&i.ID, &i.UnitID, &i.LastEditor)
+	return i, err
+}
+
+const closeExpired = `-- name: CloseExpired :many
+UPDATE forms
+SET status = 'closed', updated_at = now()
+WHERE status = 'published'
+  AND deadline IS NOT NULL
+  AND deadline <= now()
+RETURNING id
+`
+
+func (q *Queries) CloseExpired(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, closeExpired)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const create = `-- name: Create :one
 WITH created AS (
     INSERT INTO forms (
@@ -38,7 +92,7 @@ WITH created AS (
         $6, $7, $8, $9, $10,
         $11, $12, $13, $14, $15, $16, $17
     )
-    RETURNING id, title, description_json, description_html, preview_message, message_after_submission, status, unit_id, created_by, last_editor, deadline, created_at, updated_at, visibility, google_sheet_url, publish_time, cover_image_url, dressing_color, dressing_header_font, dressing_question_font, dressing_text_font, allow_edit_response
+    RETURNING id, title, description_json, description_html, preview_message, message_after_submission, status, unit_id, created_by, last_editor, deadline, created_at, updated_at, visibility, google_sheet_url, publish_time, cover_image_url, dressing_color, dressing_header_font, dressing_question_font, dressing_text_font, allow_edit_response, deadline_reminders_enabled, deadline_reminder_hours
 ),
 workflow_created AS (
     INSERT INTO workflow_versions (form_id, last_editor, workflow)
@@ -65,7 +119,7 @@ workflow_created AS (
     ) AS node_ids
 )
 SELECT
-    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response,
+    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response, f.deadline_reminders_enabled, f.deadline_reminder_hours,
     u.name as unit_name,
     o.name as org_name,
     creator.name as creator_name,
@@ -104,38 +158,40 @@ type CreateParams struct {
 }
 
 type CreateRow struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
-	UnitName               pgtype.Text
-	OrgName                pgtype.Text
-	CreatorName            pgtype.Text
-	CreatorUsername        pgtype.Text
-	CreatorAvatarUrl       pgtype.Text
-	CreatorEmails          interface{}
-	LastEditorName         pgtype.Text
-	LastEditorUsername     pgtype.Text
-	LastEditorAvatarUrl    pgtype.Text
-	LastEditorEmails       interface{}
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
+	UnitName                 pgtype.Text
+	OrgName                  pgtype.Text
+	CreatorName              pgtype.Text
+	CreatorUsername          pgtype.Text
+	CreatorAvatarUrl         pgtype.Text
+	CreatorEmails            interface{}
+	LastEditorName           pgtype.Text
+	LastEditorUsername       pgtype.Text
+	LastEditorAvatarUrl      pgtype.Text
+	LastEditorEmails         interface{}
 }
 
 func (q *Queries) Create(ctx context.Context, arg CreateParams) (CreateRow, error) {
@@ -182,6 +238,8 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (CreateRow, erro
 		&i.DressingQuestionFont,
 		&i.DressingTextFont,
 		&i.AllowEditResponse,
+		&i.DeadlineRemindersEnabled,
+		&i.DeadlineReminderHours,
 		&i.UnitName,
 		&i.OrgName,
 		&i.CreatorName,
@@ -218,7 +276,7 @@ func (q *Queries) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
 
 const get = `-- name: Get :one
 SELECT
-    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response,
+    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response, f.deadline_reminders_enabled, f.deadline_reminder_hours,
     u.name as unit_name,
     o.name as org_name,
     creator.name as creator_name,
@@ -238,38 +296,40 @@ WHERE f.id = $1
 `
 
 type GetRow struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
-	UnitName               pgtype.Text
-	OrgName                pgtype.Text
-	CreatorName            pgtype.Text
-	CreatorUsername        pgtype.Text
-	CreatorAvatarUrl       pgtype.Text
-	CreatorEmails          interface{}
-	LastEditorName         pgtype.Text
-	LastEditorUsername     pgtype.Text
-	LastEditorAvatarUrl    pgtype.Text
-	LastEditorEmails       interface{}
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
+	UnitName                 pgtype.Text
+	OrgName                  pgtype.Text
+	CreatorName              pgtype.Text
+	CreatorUsername          pgtype.Text
+	CreatorAvatarUrl         pgtype.Text
+	CreatorEmails            interface{}
+	LastEditorName           pgtype.Text
+	LastEditorUsername       pgtype.Text
+	LastEditorAvatarUrl      pgtype.Text
+	LastEditorEmails         interface{}
 }
 
 func (q *Queries) Get(ctx context.Context, id uuid.UUID) (GetRow, error) {
@@ -298,6 +358,8 @@ func (q *Queries) Get(ctx context.Context, id uuid.UUID) (GetRow, error) {
 		&i.DressingQuestionFont,
 		&i.DressingTextFont,
 		&i.AllowEditResponse,
+		&i.DeadlineRemindersEnabled,
+		&i.DeadlineReminderHours,
 		&i.UnitName,
 		&i.OrgName,
 		&i.CreatorName,
@@ -334,7 +396,7 @@ func (q *Queries) GetAvailabilityInfo(ctx context.Context, id uuid.UUID) (GetAva
 
 const getByIDs = `-- name: GetByIDs :many
 SELECT
-    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response,
+    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response, f.deadline_reminders_enabled, f.deadline_reminder_hours,
     u.name as unit_name,
     o.name as org_name,
     creator.name as creator_name,
@@ -354,38 +416,40 @@ WHERE f.id = ANY($1::uuid[])
 `
 
 type GetByIDsRow struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
-	UnitName               pgtype.Text
-	OrgName                pgtype.Text
-	CreatorName            pgtype.Text
-	CreatorUsername        pgtype.Text
-	CreatorAvatarUrl       pgtype.Text
-	CreatorEmails          interface{}
-	LastEditorName         pgtype.Text
-	LastEditorUsername     pgtype.Text
-	LastEditorAvatarUrl    pgtype.Text
-	LastEditorEmails       interface{}
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
+	UnitName                 pgtype.Text
+	OrgName                  pgtype.Text
+	CreatorName              pgtype.Text
+	CreatorUsername          pgtype.Text
+	CreatorAvatarUrl         pgtype.Text
+	CreatorEmails            interface{}
+	LastEditorName           pgtype.Text
+	LastEditorUsername       pgtype.Text
+	LastEditorAvatarUrl      pgtype.Text
+	LastEditorEmails         interface{}
 }
 
 func (q *Queries) GetByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]GetByIDsRow, error) {
@@ -420,6 +484,8 @@ func (q *Queries) GetByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]GetByID
 			&i.DressingQuestionFont,
 			&i.DressingTextFont,
 			&i.AllowEditResponse,
+			&i.DeadlineRemindersEnabled,
+			&i.DeadlineReminderHours,
 			&i.UnitName,
 			&i.OrgName,
 			&i.CreatorName,
@@ -441,15 +507,15 @@ func (q *Queries) GetByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]GetByID
 	return items, nil
 }
 
-const getCoverImage = `-- name: GetCoverImage :one
-SELECT image_data FROM form_covers WHERE form_id = $1
+const getCoverFileID = `-- name: GetCoverFileID :one
+SELECT file_id FROM form_covers WHERE form_id = $1
 `
 
-func (q *Queries) GetCoverImage(ctx context.Context, formID uuid.UUID) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getCoverImage, formID)
-	var image_data []byte
-	err := row.Scan(&image_data)
-	return image_data, err
+func (q *Queries) GetCoverFileID(ctx context.Context, formID uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getCoverFileID, formID)
+	var file_id uuid.UUID
+	err := row.Scan(&file_id)
+	return file_id, err
 }
 
 const getCreator = `-- name: GetCreator :one
@@ -520,7 +586,7 @@ func (q *Queries) GetUnitIDBySectionID(ctx context.Context, id uuid.UUID) (pgtyp
 
 const list = `-- name: List :many
 SELECT
-    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response,
+    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response, f.deadline_reminders_enabled, f.deadline_reminder_hours,
     u.name as unit_name,
     o.name as org_name,
     creator.name as creator_name,
@@ -549,38 +615,40 @@ type ListParams struct {
 }
 
 type ListRow struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
-	UnitName               pgtype.Text
-	OrgName                pgtype.Text
-	CreatorName            pgtype.Text
-	CreatorUsername        pgtype.Text
-	CreatorAvatarUrl       pgtype.Text
-	CreatorEmails          interface{}
-	LastEditorName         pgtype.Text
-	LastEditorUsername     pgtype.Text
-	LastEditorAvatarUrl    pgtype.Text
-	LastEditorEmails       interface{}
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
+	UnitName                 pgtype.Text
+	OrgName                  pgtype.Text
+	CreatorName              pgtype.Text
+	CreatorUsername          pgtype.Text
+	CreatorAvatarUrl         pgtype.Text
+	CreatorEmails            interface{}
+	LastEditorName           pgtype.Text
+	LastEditorUsername       pgtype.Text
+	LastEditorAvatarUrl      pgtype.Text
+	LastEditorEmails         interface{}
 }
 
 func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
@@ -615,6 +683,8 @@ func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
 			&i.DressingQuestionFont,
 			&i.DressingTextFont,
 			&i.AllowEditResponse,
+			&i.DeadlineRemindersEnabled,
+			&i.DeadlineReminderHours,
 			&i.UnitName,
 			&i.OrgName,
 			&i.CreatorName,
@@ -638,7 +708,7 @@ func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
 
 const listByUnit = `-- name: ListByUnit :many
 SELECT
-    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response,
+    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response, f.deadline_reminders_enabled, f.deadline_reminder_hours,
     u.name as unit_name,
     o.name as org_name,
     creator.name as creator_name,
@@ -665,38 +735,40 @@ type ListByUnitParams struct {
 }
 
 type ListByUnitRow struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
-	UnitName               pgtype.Text
-	OrgName                pgtype.Text
-	CreatorName            pgtype.Text
-	CreatorUsername        pgtype.Text
-	CreatorAvatarUrl       pgtype.Text
-	CreatorEmails          interface{}
-	LastEditorName         pgtype.Text
-	LastEditorUsername     pgtype.Text
-	LastEditorAvatarUrl    pgtype.Text
-	LastEditorEmails       interface{}
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
+	UnitName                 pgtype.Text
+	OrgName                  pgtype.Text
+	CreatorName              pgtype.Text
+	CreatorUsername          pgtype.Text
+	CreatorAvatarUrl         pgtype.Text
+	CreatorEmails            interface{}
+	LastEditorName           pgtype.Text
+	LastEditorUsername       pgtype.Text
+	LastEditorAvatarUrl      pgtype.Text
+	LastEditorEmails         interface{}
 }
 
 func (q *Queries) ListByUnit(ctx context.Context, arg ListByUnitParams) ([]ListByUnitRow, error) {
@@ -731,6 +803,8 @@ func (q *Queries) ListByUnit(ctx context.Context, arg ListByUnitParams) ([]ListB
 			&i.DressingQuestionFont,
 			&i.DressingTextFont,
 			&i.AllowEditResponse,
+			&i.DeadlineRemindersEnabled,
+			&i.DeadlineReminderHours,
 			&i.UnitName,
 			&i.OrgName,
 			&i.CreatorName,
@@ -771,12 +845,14 @@ WITH updated AS (
         dressing_question_font = COALESCE($13::text, forms.dressing_question_font),
         dressing_text_font = COALESCE($14::text, forms.dressing_text_font),
         allow_edit_response = COALESCE($15::boolean, forms.allow_edit_response),
+        deadline_reminders_enabled = COALESCE($16::boolean, forms.deadline_reminders_enabled),
+        deadline_reminder_hours = COALESCE($17::int[], forms.deadline_reminder_hours),
         updated_at = now()
-    WHERE forms.id = $16
-    RETURNING id, title, description_json, description_html, preview_message, message_after_submission, status, unit_id, created_by, last_editor, deadline, created_at, updated_at, visibility, google_sheet_url, publish_time, cover_image_url, dressing_color, dressing_header_font, dressing_question_font, dressing_text_font, allow_edit_response
+    WHERE forms.id = $18
+    RETURNING id, title, description_json, description_html, preview_message, message_after_submission, status, unit_id, created_by, last_editor, deadline, created_at, updated_at, visibility, google_sheet_url, publish_time, cover_image_url, dressing_color, dressing_header_font, dressing_question_font, dressing_text_font, allow_edit_response, deadline_reminders_enabled, deadline_reminder_hours
 )
 SELECT
-    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response,
+    f.id, f.title, f.description_json, f.description_html, f.preview_message, f.message_after_submission, f.status, f.unit_id, f.created_by, f.last_editor, f.deadline, f.created_at, f.updated_at, f.visibility, f.google_sheet_url, f.publish_time, f.cover_image_url, f.dressing_color, f.dressing_header_font, f.dressing_question_font, f.dressing_text_font, f.allow_edit_response, f.deadline_reminders_enabled, f.deadline_reminder_hours,
     u.name as unit_name,
     o.name as org_name,
     creator.name as creator_name,
@@ -795,57 +871,61 @@ LEFT JOIN users_with_emails last_editor ON f.last_editor = last_editor.id
 `
 
 type PatchParams struct {
-	Title                  pgtype.Text
-	DescriptionJson        []byte
-	DescriptionHtml        pgtype.Text
-	PreviewMessage         pgtype.Text
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	PublishTime            pgtype.Timestamptz
-	MessageAfterSubmission pgtype.Text
-	GoogleSheetUrl         pgtype.Text
-	Visibility             NullVisibility
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      pgtype.Bool
-	ID                     uuid.UUID
+	Title                    pgtype.Text
+	DescriptionJson          []byte
+	DescriptionHtml          pgtype.Text
+	PreviewMessage           pgtype.Text
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	PublishTime              pgtype.Timestamptz
+	MessageAfterSubmission   pgtype.Text
+	GoogleSheetUrl           pgtype.Text
+	Visibility               NullVisibility
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        pgtype.Bool
+	DeadlineRemindersEnabled pgtype.Bool
+	DeadlineReminderHours    []int32
+	ID                       uuid.UUID
 }
 
 type PatchRow struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
-	UnitName               pgtype.Text
-	OrgName                pgtype.Text
-	CreatorName            pgtype.Text
-	CreatorUsername        pgtype.Text
-	CreatorAvatarUrl       pgtype.Text
-	CreatorEmails          interface{}
-	LastEditorName         pgtype.Text
-	LastEditorUsername     pgtype.Text
-	LastEditorAvatarUrl    pgtype.Text
-	LastEditorEmails       interface{}
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
+	UnitName                 pgtype.Text
+	OrgName                  pgtype.Text
+	CreatorName              pgtype.Text
+	CreatorUsername          pgtype.Text
+	CreatorAvatarUrl         pgtype.Text
+	CreatorEmails            interface{}
+	LastEditorName           pgtype.Text
+	LastEditorUsername       pgtype.Text
+	LastEditorAvatarUrl      pgtype.Text
+	LastEditorEmails         interface{}
 }
 
 func (q *Queries) Patch(ctx context.Context, arg PatchParams) (PatchRow, error) {
@@ -865,6 +945,8 @@ func (q *Queries) Patch(ctx context.Context, arg PatchParams) (PatchRow, error)
 		arg.DressingQuestionFont,
 		arg.DressingTextFont,
 		arg.AllowEditResponse,
+		arg.DeadlineRemindersEnabled,
+		arg.DeadlineReminderHours,
 		arg.ID,
 	)
 	var i PatchRow
@@ -891,6 +973,8 @@ func (q *Queries) Patch(ctx context.Context, arg PatchParams) (PatchRow, error)
 		&i.DressingQuestionFont,
 		&i.DressingTextFont,
 		&i.AllowEditResponse,
+		&i.DeadlineRemindersEnabled,
+		&i.DeadlineReminderHours,
 		&i.UnitName,
 		&i.OrgName,
 		&i.CreatorName,
@@ -909,7 +993,7 @@ const setStatus = `-- name: SetStatus :one
 UPDATE forms
 SET status = $2, last_editor = $3, updated_at = now()
 WHERE id = $1
-RETURNING id, title, description_json, description_html, preview_message, message_after_submission, status, unit_id, created_by, last_editor, deadline, created_at, updated_at, visibility, google_sheet_url, publish_time, cover_image_url, dressing_color, dressing_header_font, dressing_question_font, dressing_text_font, allow_edit_response
+RETURNING id, title, description_json, description_html, preview_message, message_after_submission, status, unit_id, created_by, last_editor, deadline, created_at, updated_at, visibility, google_sheet_url, publish_time, cover_image_url, dressing_color, dressing_header_font, dressing_question_font, dressing_text_font, allow_edit_response, deadline_reminders_enabled, deadline_reminder_hours
 `
 
 type SetStatusParams struct {
@@ -944,16 +1028,18 @@ func (q *Queries) SetStatus(ctx context.Context, arg SetStatusParams) (Form, err
 		&i.DressingQuestionFont,
 		&i.DressingTextFont,
 		&i.AllowEditResponse,
+		&i.DeadlineRemindersEnabled,
+		&i.DeadlineReminderHours,
 	)
 	return i, err
 }
 
 const uploadCoverImage = `-- name: UploadCoverImage :one
 WITH upsert AS (
-    INSERT INTO form_covers (form_id, image_data)
+    INSERT INTO form_covers (form_id, file_id)
     VALUES ($1, $2)
     ON CONFLICT (form_id) DO UPDATE
-        SET image_data = EXCLUDED.image_data,
+        SET file_id = EXCLUDED.file_id,
             updated_at = now()
     RETURNING form_id
 )
@@ -966,12 +1052,12 @@ RETURNING id
 
 type UploadCoverImageParams struct {
 	FormID        uuid.UUID
-	ImageData     []byte
+	FileID        uuid.UUID
 	CoverImageUrl pgtype.Text
 }
 
 func (q *Queries) UploadCoverImage(ctx context.Context, arg UploadCoverImageParams) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, uploadCoverImage, arg.FormID, arg.ImageData, arg.CoverImageUrl)
+	row := q.db.QueryRow(ctx, uploadCoverImage, arg.FormID, arg.FileID, arg.CoverImageUrl)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
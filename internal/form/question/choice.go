@@ -158,6 +158,37 @@ func (s SingleChoice) MatchesPattern(rawValue json.RawMessage, pattern string) (
 	return matchChoiceID(singleChoiceAnswer.ChoiceID, pattern)
 }
 
+func (s SingleChoice) SupportedOperators() []Operator { return singleChoiceOperators }
+
+func (s SingleChoice) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(singleChoiceOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareChoiceOperand(nil, s.Choices, operator, operand)
+	return err
+}
+
+func (s SingleChoice) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(singleChoiceOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := s.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	singleChoiceAnswer, ok := answer.(shared.SingleChoiceAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.SingleChoiceAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	return compareChoiceOperand([]uuid.UUID{singleChoiceAnswer.ChoiceID}, s.Choices, operator, operand)
+}
+
 type MultiChoice struct {
 	question Question
 	formID   uuid.UUID
@@ -327,6 +358,42 @@ func (m MultiChoice) MatchesPattern(rawValue json.RawMessage, pattern string) (b
 	return matchChoiceIDs(choiceIDs, pattern)
 }
 
+func (m MultiChoice) SupportedOperators() []Operator { return multiChoiceOperators }
+
+func (m MultiChoice) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(multiChoiceOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareChoiceOperand(nil, m.Choices, operator, operand)
+	return err
+}
+
+func (m MultiChoice) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(multiChoiceOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := m.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	multiChoiceAnswer, ok := answer.(shared.MultipleChoiceAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.MultipleChoiceAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	choiceIDs := make([]uuid.UUID, len(multiChoiceAnswer.Choices))
+	for i, choice := range multiChoiceAnswer.Choices {
+		choiceIDs[i] = choice.ChoiceID
+	}
+
+	return compareChoiceOperand(choiceIDs, m.Choices, operator, operand)
+}
+
 type DetailedMultiChoice struct {
 	question Question
 	formID   uuid.UUID
@@ -496,6 +563,42 @@ func (m DetailedMultiChoice) MatchesPattern(rawValue json.RawMessage, pattern st
 	return matchChoiceIDs(choiceIDs, pattern)
 }
 
+func (m DetailedMultiChoice) SupportedOperators() []Operator { return multiChoiceOperators }
+
+func (m DetailedMultiChoice) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(multiChoiceOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareChoiceOperand(nil, m.Choices, operator, operand)
+	return err
+}
+
+func (m DetailedMultiChoice) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(multiChoiceOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := m.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	detailedAnswer, ok := answer.(shared.DetailedMultipleChoiceAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.DetailedMultipleChoiceAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	choiceIDs := make([]uuid.UUID, len(detailedAnswer.Choices))
+	for i, choice := range detailedAnswer.Choices {
+		choiceIDs[i] = choice.ChoiceID
+	}
+
+	return compareChoiceOperand(choiceIDs, m.Choices, operator, operand)
+}
+
 type Ranking struct {
 	question Question
 	formID   uuid.UUID
@@ -758,6 +861,42 @@ func (r Ranking) MatchesPattern(rawValue json.RawMessage, pattern string) (bool,
 	return matchChoiceIDs(choiceIDs, pattern)
 }
 
+func (r Ranking) SupportedOperators() []Operator { return multiChoiceOperators }
+
+func (r Ranking) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(multiChoiceOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareChoiceOperand(nil, r.Rank, operator, operand)
+	return err
+}
+
+func (r Ranking) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(multiChoiceOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := r.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	rankingAnswer, ok := answer.(shared.RankingAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.RankingAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	choiceIDs := make([]uuid.UUID, len(rankingAnswer.RankedChoices))
+	for i, choice := range rankingAnswer.RankedChoices {
+		choiceIDs[i] = choice.ChoiceID
+	}
+
+	return compareChoiceOperand(choiceIDs, r.Rank, operator, operand)
+}
+
 // GenerateChoiceMetadata creates and validates metadata JSON for choice-based questions
 func GenerateChoiceMetadata(questionType string, choiceOptions []ChoiceOption) ([]byte, error) {
 	// For choice questions, require at least one choice
@@ -369,6 +369,37 @@ func (d Date) MatchesPattern(rawValue json.RawMessage, pattern string) (bool, er
 	return match, nil
 }
 
+func (d Date) SupportedOperators() []Operator { return dateOperators }
+
+func (d Date) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(dateOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareDateOperand(time.Time{}, operator, operand)
+	return err
+}
+
+func (d Date) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(dateOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := d.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	dateAnswer, ok := answer.(shared.DateAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.DateAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	return compareDateOperand(dateAnswerTime(dateAnswer.Year, dateAnswer.Month, dateAnswer.Day), operator, operand)
+}
+
 // GenerateDateMetadata creates and validates metadata JSON for date questions
 func GenerateDateMetadata(option DateOption) ([]byte, error) {
 	// Validate that at least one component is required
@@ -45,6 +45,22 @@ func (e ErrInvalidAnswerLength) Unwrap() error {
 	return internal.ErrValidationFailed
 }
 
+// ErrTextValidation is returned when a text answer breaks one of the validation rules of its question.
+// Message is the custom error message of the question when one is set.
+type ErrTextValidation struct {
+	QuestionID string
+	Rule       string
+	Message    string
+}
+
+func (e ErrTextValidation) Error() string {
+	return fmt.Sprintf("answer for question %s failed %s rule: %s", e.QuestionID, e.Rule, e.Message)
+}
+
+func (e ErrTextValidation) Unwrap() error {
+	return internal.ErrValidationFailed
+}
+
 type ErrInvalidChoiceID struct {
 	QuestionID string
 	ChoiceID   string
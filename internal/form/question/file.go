@@ -233,6 +233,37 @@ func (u UploadFile) MatchesPattern(rawValue json.RawMessage, pattern string) (bo
 	return match, nil
 }
 
+func (u UploadFile) SupportedOperators() []Operator { return uploadFileOperators }
+
+func (u UploadFile) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(uploadFileOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareCountOperand(0, operator, operand)
+	return err
+}
+
+func (u UploadFile) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(uploadFileOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := u.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	uploadFileAnswer, ok := answer.(shared.UploadFileAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.UploadFileAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	return compareCountOperand(len(uploadFileAnswer.Files), operator, operand)
+}
+
 // GenerateUploadFileMetadata generates metadata for upload file question
 func GenerateUploadFileMetadata(option UploadFileOption) ([]byte, error) {
 	if len(option.AllowedFileTypes) == 0 {
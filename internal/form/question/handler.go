@@ -21,35 +21,37 @@ import (
 )
 
 type Request struct {
-	Required     *bool            `json:"required" validate:"required"`
-	Type         string           `json:"type" validate:"required,oneof=SHORT_TEXT LONG_TEXT SINGLE_CHOICE MULTIPLE_CHOICE DATE DROPDOWN DETAILED_MULTIPLE_CHOICE UPLOAD_FILE LINEAR_SCALE RATING RANKING OAUTH_CONNECT HYPERLINK"`
-	Title        string           `json:"title" validate:"required"`
-	Description  json.RawMessage  `json:"description"`
-	Order        int32            `json:"order" validate:"required,min=1"`
-	Choices      []ChoiceOption   `json:"choices,omitempty" validate:"omitempty,required_if=Type SINGLE_CHOICE,required_if=Type MULTIPLE_CHOICE,required_if=Type DETAILED_MULTIPLE_CHOICE,required_if=Type DROPDOWN,required_if=Type RANKING,dive"`
-	Scale        ScaleOption      `json:"scale" validate:"omitempty,required_if=Type LINEAR_SCALE,required_if=Type RATING"`
-	UploadFile   UploadFileOption `json:"uploadFile" validate:"omitempty,required_if=Type UPLOAD_FILE"`
-	Date         DateOption       `json:"date"`
-	OauthConnect string           `json:"oauthConnect,omitempty" validate:"required_if=Type OAUTH_CONNECT"`
-	SourceID     uuid.UUID        `json:"sourceId,omitempty"`
+	Required       *bool                 `json:"required" validate:"required"`
+	Type           string                `json:"type" validate:"required,oneof=SHORT_TEXT LONG_TEXT SINGLE_CHOICE MULTIPLE_CHOICE DATE DROPDOWN DETAILED_MULTIPLE_CHOICE UPLOAD_FILE LINEAR_SCALE RATING RANKING OAUTH_CONNECT HYPERLINK"`
+	Title          string                `json:"title" validate:"required"`
+	Description    json.RawMessage       `json:"description"`
+	Order          int32                 `json:"order" validate:"required,min=1"`
+	Choices        []ChoiceOption        `json:"choices,omitempty" validate:"omitempty,required_if=Type SINGLE_CHOICE,required_if=Type MULTIPLE_CHOICE,required_if=Type DETAILED_MULTIPLE_CHOICE,required_if=Type DROPDOWN,required_if=Type RANKING,dive"`
+	Scale          ScaleOption           `json:"scale" validate:"omitempty,required_if=Type LINEAR_SCALE,required_if=Type RATING"`
+	UploadFile     UploadFileOption      `json:"uploadFile" validate:"omitempty,required_if=Type UPLOAD_FILE"`
+	Date           DateOption            `json:"date"`
+	TextValidation *TextValidationOption `json:"textValidation,omitempty"`
+	OauthConnect   string                `json:"oauthConnect,omitempty" validate:"required_if=Type OAUTH_CONNECT"`
+	SourceID       uuid.UUID             `json:"sourceId,omitempty"`
 }
 
 type Response struct {
-	ID              uuid.UUID         `json:"id"`
-	SectionID       uuid.UUID         `json:"sectionId"`
-	Required        bool              `json:"required"`
-	Type            string            `json:"type"`
-	Title           string            `json:"title"`
-	Description     json.RawMessage   `json:"description"`
-	DescriptionHtml string            `json:"descriptionHtml,omitempty"`
-	Choices         *[]Choice         `json:"choices,omitempty"`
-	Scale           *ScaleOption      `json:"scale,omitempty"`
-	UploadFile      *UploadFileOption `json:"uploadFile,omitempty"`
-	Date            *DateOption       `json:"date,omitempty"`
-	OauthConnect    string            `json:"oauthConnect,omitempty"`
-	SourceID        string            `json:"sourceId,omitempty"`
-	CreatedAt       time.Time         `json:"createdAt"`
-	UpdatedAt       time.Time         `json:"updatedAt"`
+	ID              uuid.UUID             `json:"id"`
+	SectionID       uuid.UUID             `json:"sectionId"`
+	Required        bool                  `json:"required"`
+	Type            string                `json:"type"`
+	Title           string                `json:"title"`
+	Description     json.RawMessage       `json:"description"`
+	DescriptionHtml string                `json:"descriptionHtml,omitempty"`
+	Choices         *[]Choice             `json:"choices,omitempty"`
+	Scale           *ScaleOption          `json:"scale,omitempty"`
+	UploadFile      *UploadFileOption     `json:"uploadFile,omitempty"`
+	Date            *DateOption           `json:"date,omitempty"`
+	TextValidation  *TextValidationOption `json:"textValidation,omitempty"`
+	OauthConnect    string                `json:"oauthConnect,omitempty"`
+	SourceID        string                `json:"sourceId,omitempty"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
 }
 
 type SectionPayload struct {
@@ -108,6 +110,18 @@ func ToResponse(answerable Answerable) (Response, error) {
 	}
 
 	switch q.Type {
+	case QuestionTypeShortText, QuestionTypeLongText:
+		textValidation, ok, err := ExtractTextValidation(q.Metadata)
+		if err != nil {
+			return response, ErrInvalidMetadata{
+				QuestionID: q.ID.String(),
+				RawData:    q.Metadata,
+				Message:    err.Error(),
+			}
+		}
+		if ok {
+			response.TextValidation = textValidationOption(textValidation)
+		}
 	case QuestionTypeSingleChoice, QuestionTypeMultipleChoice, QuestionTypeDetailedMultipleChoice, QuestionTypeRanking, QuestionTypeDropdown:
 		choices, err := ExtractChoices(q.Metadata)
 		if err != nil {
@@ -432,7 +446,9 @@ func getGenerateMetadata(req Request) ([]byte, error) {
 
 	questionType := QuestionType(req.Type)
 	switch questionType {
-	case QuestionTypeShortText, QuestionTypeLongText, QuestionTypeHyperlink:
+	case QuestionTypeShortText, QuestionTypeLongText:
+		return GenerateTextValidationMetadata(questionType, req.TextValidation)
+	case QuestionTypeHyperlink:
 		return nil, nil
 	case QuestionTypeDate:
 		return GenerateDateMetadata(req.Date)
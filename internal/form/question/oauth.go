@@ -134,6 +134,38 @@ func (o OAuthConnect) MatchesPattern(rawValue json.RawMessage, pattern string) (
 	return match, nil
 }
 
+func (o OAuthConnect) SupportedOperators() []Operator { return oauthConnectOperators }
+
+func (o OAuthConnect) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(oauthConnectOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareTextOperand("", operator, operand)
+	return err
+}
+
+func (o OAuthConnect) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(oauthConnectOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := o.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	oauthAnswer, ok := answer.(shared.OAuthConnectAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.OAuthConnectAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	// An account is connected when the provider returned an ID
+	return compareTextOperand(oauthAnswer.ProviderID, operator, operand)
+}
+
 func GenerateOauthConnectMetadata(provider string) ([]byte, error) {
 	if provider == "" {
 		return nil, ErrMetadataValidate{
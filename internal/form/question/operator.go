@@ -0,0 +1,293 @@
+package question
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/google/uuid"
+)
+
+// Operator is a typed comparison that a workflow condition rule applies to an answer.
+// Each Answerable declares the operators it supports through SupportedOperators.
+type Operator string
+
+const (
+	OperatorEq       Operator = "eq"       // answer equals the operand
+	OperatorNeq      Operator = "neq"      // answer does not equal the operand
+	OperatorGt       Operator = "gt"       // answer is greater than (later than) the operand
+	OperatorGte      Operator = "gte"      // answer is greater than (later than) or equal to the operand
+	OperatorLt       Operator = "lt"       // answer is less than (earlier than) the operand
+	OperatorLte      Operator = "lte"      // answer is less than (earlier than) or equal to the operand
+	OperatorBetween  Operator = "between"  // operand is [min, max], both bounds inclusive
+	OperatorIsEmpty  Operator = "isEmpty"  // answer has no value; takes no operand
+	OperatorContains Operator = "contains" // text contains the operand, or the operand choice ID is selected
+	OperatorCountGte Operator = "countGte" // at least operand choices are selected or files are uploaded
+)
+
+// Operator sets shared by the answerable types
+var (
+	numberOperators       = []Operator{OperatorEq, OperatorNeq, OperatorGt, OperatorGte, OperatorLt, OperatorLte, OperatorBetween}
+	dateOperators         = []Operator{OperatorEq, OperatorNeq, OperatorGt, OperatorGte, OperatorLt, OperatorLte, OperatorBetween}
+	textOperators         = []Operator{OperatorEq, OperatorNeq, OperatorContains, OperatorIsEmpty}
+	singleChoiceOperators = []Operator{OperatorEq, OperatorNeq}
+	multiChoiceOperators  = []Operator{OperatorContains, OperatorCountGte, OperatorIsEmpty}
+	uploadFileOperators   = []Operator{OperatorCountGte, OperatorIsEmpty}
+	oauthConnectOperators = []Operator{OperatorIsEmpty}
+)
+
+// operandDateLayouts are the accepted formats of a date operand, from the most to the least precise
+var operandDateLayouts = []string{"2006-01-02", "2006-01", "2006"}
+
+// FormatOperators formats operators as a comma-separated list for error messages
+func FormatOperators(operators []Operator) string {
+	names := make([]string, len(operators))
+	for i, operator := range operators {
+		names[i] = string(operator)
+	}
+	return strings.Join(names, ", ")
+}
+
+// checkOperator returns ErrQuestionOperatorUnsupported if operator is not in supported
+func checkOperator(supported []Operator, operator Operator) error {
+	if !slices.Contains(supported, operator) {
+		return fmt.Errorf("%w: '%s' (supported: %s)", internal.ErrQuestionOperatorUnsupported, operator, FormatOperators(supported))
+	}
+	return nil
+}
+
+// matchesOrdering applies an ordering operator to the results of comparing the answer with the
+// lower and upper bound of the operand. For operators other than between both bounds are the same.
+func matchesOrdering(operator Operator, vsLow int, vsHigh int) bool {
+	switch operator {
+	case OperatorEq:
+		return vsLow == 0
+	case OperatorNeq:
+		return vsLow != 0
+	case OperatorGt:
+		return vsLow > 0
+	case OperatorGte:
+		return vsLow >= 0
+	case OperatorLt:
+		return vsLow < 0
+	case OperatorLte:
+		return vsLow <= 0
+	case OperatorBetween:
+		return vsLow >= 0 && vsHigh <= 0
+	default:
+		return false
+	}
+}
+
+// parseRangeOperand unmarshals a single value, or a [min, max] pair for between, with parse
+func parseRangeOperand[T any](operator Operator, operand json.RawMessage, parse func(json.RawMessage) (T, error), compare func(a, b T) int) (T, T, error) {
+	var zero T
+	if len(operand) == 0 {
+		return zero, zero, fmt.Errorf("%w: operator '%s' requires a value", internal.ErrQuestionOperandInvalid, operator)
+	}
+
+	if operator != OperatorBetween {
+		value, err := parse(operand)
+		if err != nil {
+			return zero, zero, err
+		}
+		return value, value, nil
+	}
+
+	var bounds []json.RawMessage
+	err := json.Unmarshal(operand, &bounds)
+	if err != nil || len(bounds) != 2 {
+		return zero, zero, fmt.Errorf("%w: operator 'between' requires a value of the form [min, max]", internal.ErrQuestionOperandInvalid)
+	}
+
+	low, err := parse(bounds[0])
+	if err != nil {
+		return zero, zero, err
+	}
+	high, err := parse(bounds[1])
+	if err != nil {
+		return zero, zero, err
+	}
+	if compare(low, high) > 0 {
+		return zero, zero, fmt.Errorf("%w: operator 'between' requires min to be less than or equal to max", internal.ErrQuestionOperandInvalid)
+	}
+
+	return low, high, nil
+}
+
+// ParseNumberOperand returns the lower and upper bound of the operand of a number operator. For operators
+// other than between both bounds are the operand.
+func ParseNumberOperand(operator Operator, operand json.RawMessage) (float64, float64, error) {
+	return parseRangeOperand(operator, operand, parseNumber, cmp.Compare[float64])
+}
+
+// ParseDateOperand returns the lower and upper bound of the operand of a date operator, as dates at midnight UTC
+func ParseDateOperand(operator Operator, operand json.RawMessage) (time.Time, time.Time, error) {
+	return parseRangeOperand(operator, operand, parseDate, time.Time.Compare)
+}
+
+// ParseCountOperand returns the number of items the operand of countGte requires
+func ParseCountOperand(operand json.RawMessage) (int, error) {
+	return parseCountOperand(operand)
+}
+
+func parseNumber(raw json.RawMessage) (float64, error) {
+	var value float64
+	err := json.Unmarshal(raw, &value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: expected a number, got %s", internal.ErrQuestionOperandInvalid, raw)
+	}
+	return value, nil
+}
+
+func parseDate(raw json.RawMessage) (time.Time, error) {
+	var value string
+	err := json.Unmarshal(raw, &value)
+	if err == nil {
+		for _, layout := range operandDateLayouts {
+			t, parseErr := time.Parse(layout, value)
+			if parseErr == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: expected a date in YYYY-MM-DD, YYYY-MM or YYYY format, got %s", internal.ErrQuestionOperandInvalid, raw)
+}
+
+// compareNumberOperand compares a numeric answer with the operand of operator
+func compareNumberOperand(value float64, operator Operator, operand json.RawMessage) (bool, error) {
+	low, high, err := parseRangeOperand(operator, operand, parseNumber, cmp.Compare[float64])
+	if err != nil {
+		return false, err
+	}
+	return matchesOrdering(operator, cmp.Compare(value, low), cmp.Compare(value, high)), nil
+}
+
+// compareDateOperand compares a date answer with the operand of operator
+func compareDateOperand(value time.Time, operator Operator, operand json.RawMessage) (bool, error) {
+	low, high, err := parseRangeOperand(operator, operand, parseDate, time.Time.Compare)
+	if err != nil {
+		return false, err
+	}
+	return matchesOrdering(operator, value.Compare(low), value.Compare(high)), nil
+}
+
+// compareTextOperand compares a text answer with the operand of operator
+func compareTextOperand(value string, operator Operator, operand json.RawMessage) (bool, error) {
+	if operator == OperatorIsEmpty {
+		err := requireNoOperand(operator, operand)
+		if err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(value) == "", nil
+	}
+
+	var target string
+	err := json.Unmarshal(operand, &target)
+	if err != nil {
+		return false, fmt.Errorf("%w: operator '%s' requires a string value", internal.ErrQuestionOperandInvalid, operator)
+	}
+
+	switch operator {
+	case OperatorEq:
+		return value == target, nil
+	case OperatorNeq:
+		return value != target, nil
+	case OperatorContains:
+		return strings.Contains(value, target), nil
+	default:
+		return false, nil
+	}
+}
+
+// compareChoiceOperand compares the selected choice IDs of a choice answer with the operand of operator.
+// choices are the question's options; an operand choice ID must be one of them unless choices is empty
+// (e.g. a ranking question whose options come from another question).
+func compareChoiceOperand(selected []uuid.UUID, choices []Choice, operator Operator, operand json.RawMessage) (bool, error) {
+	switch operator {
+	case OperatorIsEmpty:
+		err := requireNoOperand(operator, operand)
+		if err != nil {
+			return false, err
+		}
+		return len(selected) == 0, nil
+	case OperatorCountGte:
+		count, err := parseCountOperand(operand)
+		if err != nil {
+			return false, err
+		}
+		return len(selected) >= count, nil
+	}
+
+	var target uuid.UUID
+	err := json.Unmarshal(operand, &target)
+	if err != nil {
+		return false, fmt.Errorf("%w: operator '%s' requires a choice option ID", internal.ErrQuestionOperandInvalid, operator)
+	}
+	if len(choices) > 0 && findChoiceByID(choices, target) == nil {
+		return false, fmt.Errorf("%w: choice option '%s' does not exist", internal.ErrQuestionOperandInvalid, target)
+	}
+
+	isSelected := slices.Contains(selected, target)
+	switch operator {
+	case OperatorEq, OperatorContains:
+		return isSelected, nil
+	case OperatorNeq:
+		return !isSelected, nil
+	default:
+		return false, nil
+	}
+}
+
+// compareCountOperand compares the number of items in an answer with the operand of operator
+func compareCountOperand(count int, operator Operator, operand json.RawMessage) (bool, error) {
+	if operator == OperatorIsEmpty {
+		err := requireNoOperand(operator, operand)
+		if err != nil {
+			return false, err
+		}
+		return count == 0, nil
+	}
+
+	target, err := parseCountOperand(operand)
+	if err != nil {
+		return false, err
+	}
+	return count >= target, nil
+}
+
+func parseCountOperand(operand json.RawMessage) (int, error) {
+	var count int
+	err := json.Unmarshal(operand, &count)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("%w: operator 'countGte' requires a non-negative integer value", internal.ErrQuestionOperandInvalid)
+	}
+	return count, nil
+}
+
+func requireNoOperand(operator Operator, operand json.RawMessage) error {
+	if len(operand) > 0 && string(operand) != "null" {
+		return fmt.Errorf("%w: operator '%s' does not take a value", internal.ErrQuestionOperandInvalid, operator)
+	}
+	return nil
+}
+
+// dateAnswerTime converts a date answer to a time, defaulting missing components the same way as Date.EncodeRequest
+func dateAnswerTime(year, month, day *int) time.Time {
+	y, m, d := 1970, 1, 1
+	if year != nil {
+		y = *year
+	}
+	if month != nil {
+		m = *month
+	}
+	if day != nil {
+		d = *day
+	}
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,121 @@
+package question
+
+import (
+	"encoding/json"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnswerable_Compare(t *testing.T) {
+	choiceA := uuid.New()
+	choiceB := uuid.New()
+	choices := []Choice{{ID: choiceA, Name: "A"}, {ID: choiceB, Name: "B"}}
+
+	rating := Rating{question: Question{ID: uuid.New()}, MinVal: 1, MaxVal: 5}
+	date := Date{question: Question{ID: uuid.New()}, HasYear: true, HasMonth: true, HasDay: true}
+	shortText := ShortText{question: Question{ID: uuid.New()}}
+	singleChoice := SingleChoice{question: Question{ID: uuid.New()}, Choices: choices}
+	multiChoice := MultiChoice{question: Question{ID: uuid.New()}, Choices: choices}
+	uploadFile := UploadFile{question: Question{ID: uuid.New()}}
+
+	testCases := []struct {
+		name        string
+		answerable  Answerable
+		rawValue    string
+		operator    Operator
+		operand     string
+		expected    bool
+		expectedErr error
+	}{
+		{name: "rating gte matches equal value", answerable: rating, rawValue: `{"value":4}`, operator: OperatorGte, operand: `4`, expected: true},
+		{name: "rating gt rejects equal value", answerable: rating, rawValue: `{"value":4}`, operator: OperatorGt, operand: `4`, expected: false},
+		{name: "rating lt", answerable: rating, rawValue: `{"value":2}`, operator: OperatorLt, operand: `3`, expected: true},
+		{name: "rating between is inclusive", answerable: rating, rawValue: `{"value":5}`, operator: OperatorBetween, operand: `[3,5]`, expected: true},
+		{name: "rating between outside range", answerable: rating, rawValue: `{"value":2}`, operator: OperatorBetween, operand: `[3,5]`, expected: false},
+		{name: "rating neq", answerable: rating, rawValue: `{"value":2}`, operator: OperatorNeq, operand: `3`, expected: true},
+		{name: "rating between with reversed bounds", answerable: rating, rawValue: `{"value":4}`, operator: OperatorBetween, operand: `[5,3]`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "rating with string operand", answerable: rating, rawValue: `{"value":4}`, operator: OperatorEq, operand: `"4"`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "rating does not support contains", answerable: rating, rawValue: `{"value":4}`, operator: OperatorContains, operand: `4`, expectedErr: internal.ErrQuestionOperatorUnsupported},
+		{name: "date lt before a day", answerable: date, rawValue: `{"year":2025,"month":12,"day":31}`, operator: OperatorLt, operand: `"2026-01-01"`, expected: true},
+		{name: "date gte with month precision operand", answerable: date, rawValue: `{"year":2026,"month":3,"day":15}`, operator: OperatorGte, operand: `"2026-03"`, expected: true},
+		{name: "date eq", answerable: date, rawValue: `{"year":2026,"month":1,"day":1}`, operator: OperatorEq, operand: `"2026-01-01"`, expected: true},
+		{name: "date between", answerable: date, rawValue: `{"year":2026,"month":7,"day":1}`, operator: OperatorBetween, operand: `["2026-01-01","2026-06-30"]`, expected: false},
+		{name: "date with malformed operand", answerable: date, rawValue: `{"year":2026,"month":1,"day":1}`, operator: OperatorLt, operand: `"01/01/2026"`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "text contains", answerable: shortText, rawValue: `{"value":"NYCU SDC"}`, operator: OperatorContains, operand: `"SDC"`, expected: true},
+		{name: "text eq is exact", answerable: shortText, rawValue: `{"value":"NYCU SDC"}`, operator: OperatorEq, operand: `"nycu sdc"`, expected: false},
+		{name: "text isEmpty on blank value", answerable: shortText, rawValue: `{"value":"  "}`, operator: OperatorIsEmpty, expected: true},
+		{name: "text isEmpty with operand", answerable: shortText, rawValue: `{"value":""}`, operator: OperatorIsEmpty, operand: `true`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "text does not support gt", answerable: shortText, rawValue: `{"value":"a"}`, operator: OperatorGt, operand: `"b"`, expectedErr: internal.ErrQuestionOperatorUnsupported},
+		{name: "single choice eq", answerable: singleChoice, rawValue: `{"choiceId":"` + choiceA.String() + `"}`, operator: OperatorEq, operand: `"` + choiceA.String() + `"`, expected: true},
+		{name: "single choice neq", answerable: singleChoice, rawValue: `{"choiceId":"` + choiceA.String() + `"}`, operator: OperatorNeq, operand: `"` + choiceB.String() + `"`, expected: true},
+		{name: "single choice with unknown choice", answerable: singleChoice, rawValue: `{"choiceId":"` + choiceA.String() + `"}`, operator: OperatorEq, operand: `"` + uuid.New().String() + `"`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "multiple choice contains", answerable: multiChoice, rawValue: `{"choices":[{"choiceId":"` + choiceB.String() + `"}]}`, operator: OperatorContains, operand: `"` + choiceB.String() + `"`, expected: true},
+		{name: "multiple choice countGte", answerable: multiChoice, rawValue: `{"choices":[{"choiceId":"` + choiceA.String() + `"},{"choiceId":"` + choiceB.String() + `"}]}`, operator: OperatorCountGte, operand: `2`, expected: true},
+		{name: "multiple choice countGte not reached", answerable: multiChoice, rawValue: `{"choices":[{"choiceId":"` + choiceA.String() + `"}]}`, operator: OperatorCountGte, operand: `2`, expected: false},
+		{name: "multiple choice negative countGte", answerable: multiChoice, rawValue: `{"choices":[]}`, operator: OperatorCountGte, operand: `-1`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "upload file isEmpty", answerable: uploadFile, rawValue: `{"files":[]}`, operator: OperatorIsEmpty, expected: true},
+		{name: "upload file countGte", answerable: uploadFile, rawValue: `{"files":[{"fileId":"` + uuid.New().String() + `"}]}`, operator: OperatorCountGte, operand: `1`, expected: true},
+		{name: "corrupted answer", answerable: rating, rawValue: `not-json`, operator: OperatorEq, operand: `1`, expectedErr: internal.ErrQuestionAnswerDecodeFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var operand json.RawMessage
+			if tc.operand != "" {
+				operand = json.RawMessage(tc.operand)
+			}
+
+			result, err := tc.answerable.Compare(json.RawMessage(tc.rawValue), tc.operator, operand)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestAnswerable_ValidateOperand(t *testing.T) {
+	choiceID := uuid.New()
+
+	testCases := []struct {
+		name        string
+		answerable  Answerable
+		operator    Operator
+		operand     string
+		expectedErr error
+	}{
+		{name: "linear scale between", answerable: LinearScale{}, operator: OperatorBetween, operand: `[1,3]`},
+		{name: "linear scale between with single value", answerable: LinearScale{}, operator: OperatorBetween, operand: `3`, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "linear scale without value", answerable: LinearScale{}, operator: OperatorGte, expectedErr: internal.ErrQuestionOperandInvalid},
+		{name: "date does not support isEmpty", answerable: Date{}, operator: OperatorIsEmpty, expectedErr: internal.ErrQuestionOperatorUnsupported},
+		{name: "long text contains", answerable: LongText{}, operator: OperatorContains, operand: `"yes"`},
+		{name: "ranking contains existing choice", answerable: Ranking{Rank: []Choice{{ID: choiceID}}}, operator: OperatorContains, operand: `"` + choiceID.String() + `"`},
+		{name: "detailed multiple choice does not support eq", answerable: DetailedMultiChoice{}, operator: OperatorEq, operand: `"` + choiceID.String() + `"`, expectedErr: internal.ErrQuestionOperatorUnsupported},
+		{name: "oauth connect isEmpty", answerable: OAuthConnect{}, operator: OperatorIsEmpty},
+		{name: "unknown operator", answerable: Hyperlink{}, operator: Operator("startsWith"), operand: `"https"`, expectedErr: internal.ErrQuestionOperatorUnsupported},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var operand json.RawMessage
+			if tc.operand != "" {
+				operand = json.RawMessage(tc.operand)
+			}
+
+			err := tc.answerable.ValidateOperand(tc.operator, operand)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
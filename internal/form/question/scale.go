@@ -183,6 +183,37 @@ func (s LinearScale) MatchesPattern(rawValue json.RawMessage, pattern string) (b
 	return match, nil
 }
 
+func (s LinearScale) SupportedOperators() []Operator { return numberOperators }
+
+func (s LinearScale) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(numberOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareNumberOperand(0, operator, operand)
+	return err
+}
+
+func (s LinearScale) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(numberOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := s.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	linearScaleAnswer, ok := answer.(shared.LinearScaleAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.LinearScaleAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	return compareNumberOperand(float64(linearScaleAnswer.Value), operator, operand)
+}
+
 type Rating struct {
 	question      Question
 	formID        uuid.UUID
@@ -317,6 +348,37 @@ func (s Rating) MatchesPattern(rawValue json.RawMessage, pattern string) (bool,
 	return match, nil
 }
 
+func (s Rating) SupportedOperators() []Operator { return numberOperators }
+
+func (s Rating) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(numberOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareNumberOperand(0, operator, operand)
+	return err
+}
+
+func (s Rating) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(numberOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := s.DecodeStorage(rawValue)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", internal.ErrQuestionAnswerDecodeFailed, err)
+	}
+
+	ratingAnswer, ok := answer.(shared.RatingAnswer)
+	if !ok {
+		return false, fmt.Errorf("%w: expected shared.RatingAnswer, got %T", internal.ErrQuestionAnswerUnexpectedType, answer)
+	}
+
+	return compareNumberOperand(float64(ratingAnswer.Value), operator, operand)
+}
+
 func GenerateLinearScaleMetadata(option ScaleOption) ([]byte, error) {
 	if option.MinVal >= option.MaxVal {
 		return nil, fmt.Errorf("%w: minVal (%d) must be less than maxVal (%d)", internal.ErrValidationFailed, option.MinVal, option.MaxVal)
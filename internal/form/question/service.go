@@ -68,6 +68,19 @@ type Answerable interface {
 	// Returns false with error if the rawValue format is invalid (data corruption).
 	// Returns false with nil if the pattern is invalid (logs error internally).
 	MatchesPattern(rawValue json.RawMessage, pattern string) (bool, error)
+
+	// SupportedOperators returns the typed operators a workflow condition can apply to the answer.
+	SupportedOperators() []Operator
+
+	// ValidateOperand checks that the operator is supported and the operand fits it.
+	// Used for workflow validation, before any answer exists.
+	ValidateOperand(operator Operator, operand json.RawMessage) error
+
+	// Compare applies a typed operator with its operand to the answer.
+	// Used for workflow condition evaluation.
+	// Returns an error wrapping internal.ErrQuestionOperatorUnsupported or internal.ErrQuestionOperandInvalid
+	// if the operator or operand does not fit the question type.
+	Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error)
 }
 
 type SectionWithAnswerableList struct {
@@ -130,6 +143,12 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (Answerable, er
 		return nil, internal.ErrSectionNotFound
 	}
 
+	err = validateStoredTextValidation(input.Type, input.Metadata)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	if s.markdownStore == nil {
 		err := fmt.Errorf("markdown store is not configured")
 		span.RecordError(err)
@@ -193,6 +212,12 @@ func (s *Service) Update(ctx context.Context, input UpdateInput, order int32) (A
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
+	err := validateStoredTextValidation(input.Type, input.Metadata)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	if s.markdownStore == nil {
 		err := fmt.Errorf("markdown store is not configured")
 		span.RecordError(err)
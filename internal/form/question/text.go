@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"unicode/utf8"
 
 	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form/shared"
@@ -13,15 +14,22 @@ import (
 )
 
 type ShortText struct {
-	question Question
-	formID   uuid.UUID
+	question   Question
+	formID     uuid.UUID
+	validation *textValidation
 }
 
-func NewShortText(q Question, formID uuid.UUID) ShortText {
-	return ShortText{
-		question: q,
-		formID:   formID,
+func NewShortText(q Question, formID uuid.UUID) (ShortText, error) {
+	validation, err := newTextValidation(q)
+	if err != nil {
+		return ShortText{}, err
 	}
+
+	return ShortText{
+		question:   q,
+		formID:     formID,
+		validation: validation,
+	}, nil
 }
 
 func (s ShortText) Question() Question {
@@ -39,14 +47,16 @@ func (s ShortText) Validate(rawValue json.RawMessage) error {
 		return fmt.Errorf("invalid short text value format: %w", err)
 	}
 
-	if len(value) > 100 {
+	// Counted in characters, like the length rules of the question
+	length := utf8.RuneCountInString(value)
+	if length > shortTextMaxLength {
 		return ErrInvalidAnswerLength{
-			Expected: 100,
-			Given:    len(value),
+			Expected: shortTextMaxLength,
+			Given:    length,
 		}
 	}
 
-	return nil
+	return s.validation.check(s.question.ID.String(), value)
 }
 
 func (s ShortText) DecodeRequest(param shared.AnswerParam) (any, error) {
@@ -106,16 +116,49 @@ func (s ShortText) MatchesPattern(rawValue json.RawMessage, pattern string) (boo
 	return match, nil
 }
 
+func (s ShortText) SupportedOperators() []Operator { return textOperators }
+
+func (s ShortText) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(textOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareTextOperand("", operator, operand)
+	return err
+}
+
+func (s ShortText) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(textOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	display, err := s.DisplayValue(rawValue)
+	if err != nil {
+		return false, err
+	}
+
+	return compareTextOperand(display, operator, operand)
+}
+
 type LongText struct {
-	question Question
-	formID   uuid.UUID
+	question   Question
+	formID     uuid.UUID
+	validation *textValidation
 }
 
-func NewLongText(q Question, formID uuid.UUID) LongText {
-	return LongText{
-		question: q,
-		formID:   formID,
+func NewLongText(q Question, formID uuid.UUID) (LongText, error) {
+	validation, err := newTextValidation(q)
+	if err != nil {
+		return LongText{}, err
 	}
+
+	return LongText{
+		question:   q,
+		formID:     formID,
+		validation: validation,
+	}, nil
 }
 
 func (l LongText) Question() Question {
@@ -133,14 +176,16 @@ func (l LongText) Validate(rawValue json.RawMessage) error {
 		return fmt.Errorf("invalid long text value format: %w", err)
 	}
 
-	if len(value) > 1000 {
+	// Counted in characters, like the length rules of the question
+	length := utf8.RuneCountInString(value)
+	if length > longTextMaxLength {
 		return ErrInvalidAnswerLength{
-			Expected: 1000,
-			Given:    len(value),
+			Expected: longTextMaxLength,
+			Given:    length,
 		}
 	}
 
-	return nil
+	return l.validation.check(l.question.ID.String(), value)
 }
 
 func (l LongText) DecodeRequest(param shared.AnswerParam) (any, error) {
@@ -210,6 +255,32 @@ func (l LongText) MatchesPattern(rawValue json.RawMessage, pattern string) (bool
 	return match, nil
 }
 
+func (l LongText) SupportedOperators() []Operator { return textOperators }
+
+func (l LongText) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(textOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareTextOperand("", operator, operand)
+	return err
+}
+
+func (l LongText) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(textOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	display, err := l.DisplayValue(rawValue)
+	if err != nil {
+		return false, err
+	}
+
+	return compareTextOperand(display, operator, operand)
+}
+
 type Hyperlink struct {
 	question Question
 	formID   uuid.UUID
@@ -308,6 +379,32 @@ func (h Hyperlink) MatchesPattern(rawValue json.RawMessage, pattern string) (boo
 	return match, nil
 }
 
+func (h Hyperlink) SupportedOperators() []Operator { return textOperators }
+
+func (h Hyperlink) ValidateOperand(operator Operator, operand json.RawMessage) error {
+	err := checkOperator(textOperators, operator)
+	if err != nil {
+		return err
+	}
+
+	_, err = compareTextOperand("", operator, operand)
+	return err
+}
+
+func (h Hyperlink) Compare(rawValue json.RawMessage, operator Operator, operand json.RawMessage) (bool, error) {
+	err := checkOperator(textOperators, operator)
+	if err != nil {
+		return false, err
+	}
+
+	display, err := h.DisplayValue(rawValue)
+	if err != nil {
+		return false, err
+	}
+
+	return compareTextOperand(display, operator, operand)
+}
+
 // validateURL checks if the value is a valid URL
 func validateURL(value string) error {
 	if value == "" {
@@ -10,7 +10,10 @@ import (
 )
 
 func TestShortText_DecodeRequest(t *testing.T) {
-	st := NewShortText(Question{ID: uuid.New()}, uuid.New())
+	st, err := NewShortText(Question{ID: uuid.New()}, uuid.New())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	testCases := []struct {
 		name          string
@@ -67,7 +70,10 @@ func TestShortText_DecodeRequest(t *testing.T) {
 }
 
 func TestShortText_DecodeStorage(t *testing.T) {
-	st := NewShortText(Question{ID: uuid.New()}, uuid.New())
+	st, err := NewShortText(Question{ID: uuid.New()}, uuid.New())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	testCases := []struct {
 		name          string
@@ -119,7 +125,10 @@ func TestShortText_DecodeStorage(t *testing.T) {
 }
 
 func TestLongText_DecodeRequest(t *testing.T) {
-	lt := NewLongText(Question{ID: uuid.New()}, uuid.New())
+	lt, err := NewLongText(Question{ID: uuid.New()}, uuid.New())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	testCases := []struct {
 		name          string
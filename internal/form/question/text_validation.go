@@ -0,0 +1,331 @@
+package question
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"NYCU-SDC/core-system-backend/internal"
+)
+
+type TextFormat string
+
+const (
+	TextFormatEmail   TextFormat = "email"
+	TextFormatURL     TextFormat = "url"
+	TextFormatNumeric TextFormat = "numeric"
+	TextFormatPhone   TextFormat = "phone"
+)
+
+var textFormats = []TextFormat{TextFormatEmail, TextFormatURL, TextFormatNumeric, TextFormatPhone}
+
+const (
+	// Built-in byte limits of short and long text answers; rule bounds may not exceed them
+	shortTextMaxLength = 100
+	longTextMaxLength  = 1000
+
+	// maxTextPatternLength bounds the custom regex an editor may store
+	maxTextPatternLength = 500
+	// maxTextErrorMessageLength bounds the custom error message shown to respondents
+	maxTextErrorMessageLength = 200
+)
+
+var (
+	numericPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+	phonePattern   = regexp.MustCompile(`^\+?[0-9 ()-]+$`)
+)
+
+// TextValidationOption is the API shape of the validation rules of short and long text questions.
+// Every rule is optional; lengths are counted in characters and words are separated by whitespace.
+// Format and Pattern are mutually exclusive. ErrorMessage replaces the default message of a failed rule.
+type TextValidationOption struct {
+	MinLength    *int   `json:"minLength,omitempty"`
+	MaxLength    *int   `json:"maxLength,omitempty"`
+	MinWords     *int   `json:"minWords,omitempty"`
+	MaxWords     *int   `json:"maxWords,omitempty"`
+	Format       string `json:"format,omitempty"`
+	Pattern      string `json:"pattern,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// TextValidationMetadata is the persisted shape inside question.Metadata, nested as {"textValidation": ...}.
+// Written by GenerateTextValidationMetadata; read by ExtractTextValidation, NewShortText and NewLongText.
+type TextValidationMetadata struct {
+	MinLength    *int       `json:"minLength,omitempty"`
+	MaxLength    *int       `json:"maxLength,omitempty"`
+	MinWords     *int       `json:"minWords,omitempty"`
+	MaxWords     *int       `json:"maxWords,omitempty"`
+	Format       TextFormat `json:"format,omitempty"`
+	Pattern      string     `json:"pattern,omitempty"`
+	ErrorMessage string     `json:"errorMessage,omitempty"`
+}
+
+// textValidation is the compiled form of TextValidationMetadata used when answers are validated
+type textValidation struct {
+	metadata TextValidationMetadata
+	pattern  *regexp.Regexp
+}
+
+// GenerateTextValidationMetadata creates and validates metadata JSON for short and long text questions.
+// A nil option means the question has no rules beyond the built-in length limit, so nil metadata is returned.
+func GenerateTextValidationMetadata(questionType QuestionType, option *TextValidationOption) ([]byte, error) {
+	if option == nil {
+		return nil, nil
+	}
+
+	metadata := TextValidationMetadata{
+		MinLength:    option.MinLength,
+		MaxLength:    option.MaxLength,
+		MinWords:     option.MinWords,
+		MaxWords:     option.MaxWords,
+		Format:       TextFormat(strings.ToLower(strings.TrimSpace(option.Format))),
+		Pattern:      option.Pattern,
+		ErrorMessage: strings.TrimSpace(option.ErrorMessage),
+	}
+
+	err := ValidateTextValidationMetadata(questionType, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", internal.ErrValidationFailed, err)
+	}
+
+	return json.Marshal(map[string]any{
+		"textValidation": metadata,
+	})
+}
+
+// ValidateTextValidationMetadata rejects rules that are malformed or contradict each other,
+// e.g. minLength above maxLength or a format combined with a custom pattern
+func ValidateTextValidationMetadata(questionType QuestionType, metadata TextValidationMetadata) error {
+	limit := shortTextMaxLength
+	if questionType == QuestionTypeLongText {
+		limit = longTextMaxLength
+	}
+
+	bounds := []struct {
+		name  string
+		value *int
+	}{
+		{name: "minLength", value: metadata.MinLength},
+		{name: "maxLength", value: metadata.MaxLength},
+		{name: "minWords", value: metadata.MinWords},
+		{name: "maxWords", value: metadata.MaxWords},
+	}
+	for _, bound := range bounds {
+		if bound.value == nil {
+			continue
+		}
+		if *bound.value < 0 {
+			return fmt.Errorf("%s cannot be negative, got: %d", bound.name, *bound.value)
+		}
+		if *bound.value > limit {
+			return fmt.Errorf("%s cannot exceed the %s limit of %d characters, got: %d", bound.name, questionType, limit, *bound.value)
+		}
+	}
+
+	if metadata.MinLength != nil && metadata.MaxLength != nil && *metadata.MinLength > *metadata.MaxLength {
+		return fmt.Errorf("minLength (%d) must be less than or equal to maxLength (%d)", *metadata.MinLength, *metadata.MaxLength)
+	}
+
+	if metadata.MinWords != nil && metadata.MaxWords != nil && *metadata.MinWords > *metadata.MaxWords {
+		return fmt.Errorf("minWords (%d) must be less than or equal to maxWords (%d)", *metadata.MinWords, *metadata.MaxWords)
+	}
+
+	// n words need at least n characters plus n-1 separators
+	if metadata.MinWords != nil && metadata.MaxLength != nil && *metadata.MinWords > 0 && 2*(*metadata.MinWords)-1 > *metadata.MaxLength {
+		return fmt.Errorf("minWords (%d) cannot fit in maxLength (%d)", *metadata.MinWords, *metadata.MaxLength)
+	}
+
+	if metadata.Format != "" && !slices.Contains(textFormats, metadata.Format) {
+		return fmt.Errorf("invalid format %q, must be one of: email, url, numeric, phone", metadata.Format)
+	}
+
+	if metadata.Format != "" && metadata.Pattern != "" {
+		return fmt.Errorf("format and pattern cannot both be set")
+	}
+
+	if len(metadata.Pattern) > maxTextPatternLength {
+		return fmt.Errorf("pattern cannot exceed %d characters, got: %d", maxTextPatternLength, len(metadata.Pattern))
+	}
+
+	if metadata.Pattern != "" {
+		_, err := regexp.Compile(metadata.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	if utf8.RuneCountInString(metadata.ErrorMessage) > maxTextErrorMessageLength {
+		return fmt.Errorf("errorMessage cannot exceed %d characters", maxTextErrorMessageLength)
+	}
+
+	return nil
+}
+
+// ExtractTextValidation extracts text validation metadata from raw JSON bytes; ok is false when the question has no rules
+func ExtractTextValidation(data []byte) (TextValidationMetadata, bool, error) {
+	if data == nil {
+		return TextValidationMetadata{}, false, nil
+	}
+
+	var partial map[string]json.RawMessage
+	if err := json.Unmarshal(data, &partial); err != nil {
+		return TextValidationMetadata{}, false, fmt.Errorf("could not parse partial json: %w", err)
+	}
+
+	raw, ok := partial["textValidation"]
+	if !ok {
+		return TextValidationMetadata{}, false, nil
+	}
+
+	var metadata TextValidationMetadata
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return TextValidationMetadata{}, false, fmt.Errorf("could not parse text validation: %w", err)
+	}
+
+	return metadata, true, nil
+}
+
+// newTextValidation builds the validation rules of a short or long text question from its metadata,
+// returning nil when the question has none
+func newTextValidation(q Question) (*textValidation, error) {
+	metadata, ok, err := ExtractTextValidation(q.Metadata)
+	if err != nil {
+		return nil, ErrMetadataBroken{QuestionID: q.ID.String(), RawData: q.Metadata, Message: "could not extract text validation from metadata"}
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	err = ValidateTextValidationMetadata(q.Type, metadata)
+	if err != nil {
+		return nil, ErrMetadataBroken{QuestionID: q.ID.String(), RawData: q.Metadata, Message: err.Error()}
+	}
+
+	validation := &textValidation{metadata: metadata}
+	if metadata.Pattern != "" {
+		// Already compiled once by ValidateTextValidationMetadata
+		validation.pattern = regexp.MustCompile(metadata.Pattern)
+	}
+
+	return validation, nil
+}
+
+// check returns ErrTextValidation for the first rule the value breaks. Blank values are left to the
+// required check so that optional questions can be left empty.
+func (v *textValidation) check(questionID string, value string) error {
+	if v == nil || strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	rule, message := v.firstBrokenRule(value)
+	if rule == "" {
+		return nil
+	}
+
+	if v.metadata.ErrorMessage != "" {
+		message = v.metadata.ErrorMessage
+	}
+
+	return ErrTextValidation{
+		QuestionID: questionID,
+		Rule:       rule,
+		Message:    message,
+	}
+}
+
+func (v *textValidation) firstBrokenRule(value string) (string, string) {
+	m := v.metadata
+
+	length := utf8.RuneCountInString(value)
+	if m.MinLength != nil && length < *m.MinLength {
+		return "minLength", fmt.Sprintf("must be at least %d characters, got %d", *m.MinLength, length)
+	}
+	if m.MaxLength != nil && length > *m.MaxLength {
+		return "maxLength", fmt.Sprintf("must be at most %d characters, got %d", *m.MaxLength, length)
+	}
+
+	words := len(strings.Fields(value))
+	if m.MinWords != nil && words < *m.MinWords {
+		return "minWords", fmt.Sprintf("must be at least %d words, got %d", *m.MinWords, words)
+	}
+	if m.MaxWords != nil && words > *m.MaxWords {
+		return "maxWords", fmt.Sprintf("must be at most %d words, got %d", *m.MaxWords, words)
+	}
+
+	if m.Format != "" && !matchesTextFormat(m.Format, value) {
+		return "format", fmt.Sprintf("must be a valid %s", m.Format)
+	}
+
+	if v.pattern != nil && !v.pattern.MatchString(value) {
+		return "pattern", "does not match the required pattern"
+	}
+
+	return "", ""
+}
+
+func matchesTextFormat(format TextFormat, value string) bool {
+	switch format {
+	case TextFormatEmail:
+		address, err := mail.ParseAddress(value)
+		// Reject display-name forms such as "Name <a@b.c>"
+		return err == nil && address.Address == value
+	case TextFormatURL:
+		return validateURL(value) == nil
+	case TextFormatNumeric:
+		return numericPattern.MatchString(value)
+	case TextFormatPhone:
+		if !phonePattern.MatchString(value) {
+			return false
+		}
+		// E.164 numbers have at most 15 digits; fewer than 7 is not a dialable number
+		digits := 0
+		for _, r := range value {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		return digits >= 7 && digits <= 15
+	}
+
+	return false
+}
+
+// textValidationOption maps stored text validation metadata back to the API shape
+func textValidationOption(metadata TextValidationMetadata) *TextValidationOption {
+	return &TextValidationOption{
+		MinLength:    metadata.MinLength,
+		MaxLength:    metadata.MaxLength,
+		MinWords:     metadata.MinWords,
+		MaxWords:     metadata.MaxWords,
+		Format:       strings.ToUpper(string(metadata.Format)),
+		Pattern:      metadata.Pattern,
+		ErrorMessage: metadata.ErrorMessage,
+	}
+}
+
+// validateStoredTextValidation rejects malformed or contradictory text validation rules in the metadata
+// of a short or long text question before it is saved
+func validateStoredTextValidation(questionType QuestionType, data []byte) error {
+	if questionType != QuestionTypeShortText && questionType != QuestionTypeLongText {
+		return nil
+	}
+
+	metadata, ok, err := ExtractTextValidation(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", internal.ErrValidationFailed, err)
+	}
+	if !ok {
+		return nil
+	}
+
+	err = ValidateTextValidationMetadata(questionType, metadata)
+	if err != nil {
+		return fmt.Errorf("%w: %w", internal.ErrValidationFailed, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package question
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestGenerateTextValidationMetadata(t *testing.T) {
+	testCases := []struct {
+		name         string
+		questionType QuestionType
+		option       *TextValidationOption
+		expectedNil  bool
+		expectedErr  bool
+	}{
+		{name: "no rules", questionType: QuestionTypeShortText, option: nil, expectedNil: true},
+		{name: "length and words", questionType: QuestionTypeLongText, option: &TextValidationOption{MinLength: intPtr(10), MaxLength: intPtr(500), MinWords: intPtr(3), MaxWords: intPtr(80)}},
+		{name: "preset format is case insensitive", questionType: QuestionTypeShortText, option: &TextValidationOption{Format: "EMAIL", ErrorMessage: "Please enter your school email"}},
+		{name: "custom pattern", questionType: QuestionTypeShortText, option: &TextValidationOption{Pattern: `^[0-9]{9}$`}},
+		{name: "minLength above maxLength", questionType: QuestionTypeShortText, option: &TextValidationOption{MinLength: intPtr(20), MaxLength: intPtr(10)}, expectedErr: true},
+		{name: "minWords above maxWords", questionType: QuestionTypeLongText, option: &TextValidationOption{MinWords: intPtr(5), MaxWords: intPtr(4)}, expectedErr: true},
+		{name: "minWords cannot fit in maxLength", questionType: QuestionTypeShortText, option: &TextValidationOption{MinWords: intPtr(6), MaxLength: intPtr(10)}, expectedErr: true},
+		{name: "maxLength above short text limit", questionType: QuestionTypeShortText, option: &TextValidationOption{MaxLength: intPtr(500)}, expectedErr: true},
+		{name: "negative minLength", questionType: QuestionTypeShortText, option: &TextValidationOption{MinLength: intPtr(-1)}, expectedErr: true},
+		{name: "format and pattern together", questionType: QuestionTypeShortText, option: &TextValidationOption{Format: "numeric", Pattern: `^\d+$`}, expectedErr: true},
+		{name: "unknown format", questionType: QuestionTypeShortText, option: &TextValidationOption{Format: "postcode"}, expectedErr: true},
+		{name: "invalid pattern", questionType: QuestionTypeShortText, option: &TextValidationOption{Pattern: `^[a-z`}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata, err := GenerateTextValidationMetadata(tc.questionType, tc.option)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, internal.ErrValidationFailed)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.expectedNil {
+				assert.Nil(t, metadata)
+				return
+			}
+
+			// The generated metadata must be accepted when the question is loaded
+			_, err = NewAnswerable(Question{ID: uuid.New(), Type: tc.questionType, Metadata: metadata}, uuid.New())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestShortText_Validate_TextValidation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		option        TextValidationOption
+		rawValue      string
+		expectedRule  string
+		expectedError string
+	}{
+		{name: "within length", option: TextValidationOption{MinLength: intPtr(2), MaxLength: intPtr(5)}, rawValue: `"abc"`},
+		{name: "length counts characters", option: TextValidationOption{MaxLength: intPtr(4)}, rawValue: `"陽明交大"`},
+		{name: "CJK answer meets a minLength above a third of the limit", option: TextValidationOption{MinLength: intPtr(40)}, rawValue: `"` + strings.Repeat("陽", 40) + `"`},
+		{name: "too short", option: TextValidationOption{MinLength: intPtr(5)}, rawValue: `"abc"`, expectedRule: "minLength"},
+		{name: "too many words", option: TextValidationOption{MaxWords: intPtr(2)}, rawValue: `"one two three"`, expectedRule: "maxWords"},
+		{name: "too few words", option: TextValidationOption{MinWords: intPtr(2)}, rawValue: `"one"`, expectedRule: "minWords"},
+		{name: "blank answer skips rules", option: TextValidationOption{MinLength: intPtr(5)}, rawValue: `""`},
+		{name: "valid email", option: TextValidationOption{Format: "email"}, rawValue: `"student@nycu.edu.tw"`},
+		{name: "invalid email", option: TextValidationOption{Format: "email"}, rawValue: `"student@"`, expectedRule: "format"},
+		{name: "email with display name", option: TextValidationOption{Format: "email"}, rawValue: `"Student <student@nycu.edu.tw>"`, expectedRule: "format"},
+		{name: "valid url", option: TextValidationOption{Format: "url"}, rawValue: `"https://sdc.nycu.club"`},
+		{name: "url without scheme", option: TextValidationOption{Format: "url"}, rawValue: `"sdc.nycu.club"`, expectedRule: "format"},
+		{name: "valid numeric", option: TextValidationOption{Format: "numeric"}, rawValue: `"-12.5"`},
+		{name: "invalid numeric", option: TextValidationOption{Format: "numeric"}, rawValue: `"12a"`, expectedRule: "format"},
+		{name: "valid phone", option: TextValidationOption{Format: "phone"}, rawValue: `"+886 912-345-678"`},
+		{name: "phone with too few digits", option: TextValidationOption{Format: "phone"}, rawValue: `"12-34"`, expectedRule: "format"},
+		{name: "custom pattern", option: TextValidationOption{Pattern: `^[0-9]{9}$`}, rawValue: `"312551000"`},
+		{name: "custom pattern mismatch", option: TextValidationOption{Pattern: `^[0-9]{9}$`}, rawValue: `"31255"`, expectedRule: "pattern"},
+		{name: "custom error message", option: TextValidationOption{Pattern: `^[0-9]{9}$`, ErrorMessage: "Student ID must be 9 digits"}, rawValue: `"abc"`, expectedRule: "pattern", expectedError: "Student ID must be 9 digits"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			option := tc.option
+			metadata, err := GenerateTextValidationMetadata(QuestionTypeShortText, &option)
+			require.NoError(t, err)
+
+			shortText, err := NewShortText(Question{ID: uuid.New(), Type: QuestionTypeShortText, Metadata: metadata}, uuid.New())
+			require.NoError(t, err)
+
+			err = shortText.Validate(json.RawMessage(tc.rawValue))
+			if tc.expectedRule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, internal.ErrValidationFailed)
+			var textErr ErrTextValidation
+			require.ErrorAs(t, err, &textErr)
+			assert.Equal(t, tc.expectedRule, textErr.Rule)
+			if tc.expectedError != "" {
+				assert.Equal(t, tc.expectedError, textErr.Message)
+			}
+		})
+	}
+}
+
+func TestShortText_Validate_MaxLength(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expectedErr bool
+	}{
+		{name: "CJK answer at the limit", value: strings.Repeat("陽", shortTextMaxLength)},
+		{name: "CJK answer over the limit", value: strings.Repeat("陽", shortTextMaxLength+1), expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shortText, err := NewShortText(Question{ID: uuid.New(), Type: QuestionTypeShortText}, uuid.New())
+			require.NoError(t, err)
+
+			rawValue, err := json.Marshal(tc.value)
+			require.NoError(t, err)
+
+			err = shortText.Validate(rawValue)
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				return
+			}
+
+			var lengthErr ErrInvalidAnswerLength
+			require.ErrorAs(t, err, &lengthErr)
+			assert.Equal(t, shortTextMaxLength+1, lengthErr.Given)
+		})
+	}
+}
+
+func TestNewLongText_BrokenTextValidation(t *testing.T) {
+	metadata := []byte(`{"textValidation":{"minLength":50,"maxLength":10}}`)
+
+	_, err := NewLongText(Question{ID: uuid.New(), Type: QuestionTypeLongText, Metadata: metadata}, uuid.New())
+	require.ErrorIs(t, err, internal.ErrInternalServerError)
+}
@@ -11,9 +11,9 @@ import (
 func NewAnswerable(q Question, formID uuid.UUID) (Answerable, error) {
 	switch q.Type {
 	case QuestionTypeShortText:
-		return NewShortText(q, formID), nil
+		return NewShortText(q, formID)
 	case QuestionTypeLongText:
-		return NewLongText(q, formID), nil
+		return NewLongText(q, formID)
 	case QuestionTypeSingleChoice:
 		return NewSingleChoice(q, formID)
 	case QuestionTypeMultipleChoice:
@@ -0,0 +1,40 @@
+-- name: ListDue :many
+-- Lists the published forms with deadline reminders whose deadline is ahead and the reminder of each that
+-- is due. When several reminders of a form are due, such as after reminders were turned on close to the
+-- deadline, only the one closest to the deadline is sent.
+SELECT f.id AS form_id, f.unit_id, f.deadline, MIN(h.hours)::int AS hours_before
+FROM forms f
+CROSS JOIN LATERAL unnest(f.deadline_reminder_hours) AS h(hours)
+WHERE f.status = 'published'
+  AND f.deadline_reminders_enabled
+  AND f.unit_id IS NOT NULL
+  AND f.deadline > now()
+  AND f.deadline - make_interval(hours => h.hours) <= now()
+GROUP BY f.id, f.unit_id, f.deadline
+ORDER BY f.deadline;
+
+-- name: UpsertReminder :one
+-- A reminder is kept per deadline, so that users are reminded again when the deadline is moved. The
+-- no-op update makes RETURNING return the reminder when it already exists
+INSERT INTO form_deadline_reminders (form_id, hours_before, deadline)
+VALUES (@form_id, @hours_before, @deadline)
+ON CONFLICT (form_id, hours_before, deadline) DO UPDATE SET deadline = form_deadline_reminders.deadline
+RETURNING *;
+
+-- name: AddRecipients :many
+-- Records the reminder for the users the form was delivered to who have not submitted a response and
+-- have not been sent the reminder yet, and returns them
+INSERT INTO form_deadline_reminder_recipients (reminder_id, user_id)
+SELECT DISTINCT @reminder_id::uuid, uim.user_id
+FROM inbox_message im
+JOIN user_inbox_messages uim ON uim.message_id = im.id
+WHERE im.type = 'form'
+  AND im.content_id = @form_id
+  AND NOT EXISTS (
+      SELECT 1 FROM form_responses r
+      WHERE r.form_id = im.content_id
+        AND r.submitted_by = uim.user_id
+        AND r.submitted_at IS NOT NULL
+  )
+ON CONFLICT (reminder_id, user_id) DO NOTHING
+RETURNING user_id;
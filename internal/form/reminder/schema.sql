@@ -0,0 +1,19 @@
+-- A deadline reminder of a form, sent the given number of hours before its deadline; the inbox message
+-- of the reminder points at it
+CREATE TABLE IF NOT EXISTS form_deadline_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    hours_before INTEGER NOT NULL,
+    -- Deadline of the form the reminder is sent for; a moved deadline gets reminders of its own
+    deadline TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, hours_before, deadline)
+);
+
+-- Users a reminder was sent to, so that a user gets each reminder at most once
+CREATE TABLE IF NOT EXISTS form_deadline_reminder_recipients (
+    reminder_id UUID NOT NULL REFERENCES form_deadline_reminders(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reminded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (reminder_id, user_id)
+);
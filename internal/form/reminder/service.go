@@ -0,0 +1,150 @@
+package reminder
+
+import (
+	"context"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/notify"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	ListDue(ctx context.Context) ([]ListDueRow, error)
+	UpsertReminder(ctx context.Context, arg UpsertReminderParams) (FormDeadlineReminder, error)
+	AddRecipients(ctx context.Context, arg AddRecipientsParams) ([]uuid.UUID, error)
+}
+
+type InboxPort interface {
+	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+	WithTx(tx pgx.Tx) *inbox.Service
+}
+
+type EmailNotifier interface {
+	EnqueueDeadlineReminder(ctx context.Context, formID uuid.UUID, userID uuid.UUID, dedupeKey string) (bool, error)
+	WithTx(tx pgx.Tx) *notify.Service
+}
+
+// Service reminds users of the deadlines of forms they have not submitted a response to.
+//
+// A form's reminders are sent the hours before its deadline that its owner set, to the users the form
+// was delivered to whose response is still a draft or missing, through their inbox and by email. Users
+// a reminder was sent to are recorded with it, so every replica of the backend can run the job and a
+// user still gets each reminder at most once. A reminder is kept per deadline, so moving the deadline
+// sends the reminders of the new one.
+type Service struct {
+	logger  *zap.Logger
+	tracer  trace.Tracer
+	db      DBTX
+	queries Querier
+	inbox   InboxPort
+	emails  EmailNotifier
+}
+
+func NewService(logger *zap.Logger, db DBTX, inbox InboxPort, emails EmailNotifier) *Service {
+	return &Service{
+		logger:  logger,
+		tracer:  otel.Tracer("reminder/service"),
+		db:      db,
+		queries: New(db),
+		inbox:   inbox,
+		emails:  emails,
+	}
+}
+
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:  s.logger,
+		tracer:  s.tracer,
+		db:      tx,
+		queries: New(tx),
+		inbox:   s.inbox.WithTx(tx),
+		emails:  s.emails.WithTx(tx),
+	}
+}
+
+// SendDue sends the due deadline reminders of every form and returns how many users were reminded.
+// Each form is reminded in its own transaction; errors are logged rather than returned so that one
+// broken form does not stop the others.
+func (s *Service) SendDue(ctx context.Context) int {
+	traceCtx, span := s.tracer.Start(ctx, "SendDue")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	due, err := s.queries.ListDue(traceCtx)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("Failed to list due deadline reminders", zap.Error(err))
+		return 0
+	}
+
+	reminded := 0
+	for _, form := range due {
+		var count int
+		err = internal.WithTransaction(traceCtx, s.db, logger, func(tx pgx.Tx) error {
+			var err error
+			count, err = s.WithTx(tx).send(traceCtx, form)
+			return err
+		})
+		if err != nil {
+			span.RecordError(err)
+			logger.Error("Failed to send deadline reminder",
+				zap.String("form_id", form.FormID.String()),
+				zap.Int32("hours_before", form.HoursBefore),
+				zap.Error(err),
+			)
+			continue
+		}
+		reminded += count
+	}
+
+	if reminded > 0 {
+		logger.Info("Sent deadline reminders", zap.Int("users", reminded))
+	}
+
+	return reminded
+}
+
+// send records the due reminder of a form for the users who have not been sent it and delivers it to
+// them, posted by the unit of the form
+func (s *Service) send(ctx context.Context, form ListDueRow) (int, error) {
+	reminder, err := s.queries.UpsertReminder(ctx, UpsertReminderParams{
+		FormID:      form.FormID,
+		HoursBefore: form.HoursBefore,
+		Deadline:    form.Deadline,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	userIDs, err := s.queries.AddRecipients(ctx, AddRecipientsParams{
+		ReminderID: reminder.ID,
+		FormID:     form.FormID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	_, err = s.inbox.Create(ctx, inbox.ContentTypeReminder, reminder.ID, userIDs, form.UnitID.Bytes)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, userID := range userIDs {
+		_, err = s.emails.EnqueueDeadlineReminder(ctx, form.FormID, userID, "deadline_reminder:"+reminder.ID.String()+":"+userID.String())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(userIDs), nil
+}
@@ -0,0 +1,311 @@
+package response
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// archiveIndexName is the entry at the root of an answer file archive that maps every file to its response
+	archiveIndexName = "index.csv"
+	// maxArchiveNameLength bounds each directory and file name in an archive, in runes
+	maxArchiveNameLength = 100
+)
+
+var archiveIndexColumns = []string{"Path", "Response ID", "Progress", "Submitter ID", "Submitter", "Question ID", "Question", "Original Filename", "Size"}
+
+// FileSelection narrows the answer files of a form in an archive. Nil fields select every question or
+// every response; an empty, non-nil field selects none.
+type FileSelection struct {
+	QuestionIDs []uuid.UUID
+	ResponseIDs []uuid.UUID
+}
+
+// FileArchive is a checked archive of the answer files of a form, ready to be written by WriteFileArchive
+type FileArchive struct {
+	FormID    uuid.UUID
+	FormTitle string
+	Entries   []FileArchiveEntry
+}
+
+// FileArchiveEntry is a file of an archive at its path, <submitter>/<question title>/<original filename>
+type FileArchiveEntry struct {
+	Path string
+	File ListAnswerFilesByFormIDRow
+}
+
+// PrepareFileArchive checks the form and questions of an archive and lists the files it contains.
+// Files are found through their form_answer attachments, so files removed from an answer are left out,
+// and quarantined files are left out until the malware scanner has found them clean.
+func (s *Service) PrepareFileArchive(ctx context.Context, formID uuid.UUID, selection FileSelection) (FileArchive, error) {
+	traceCtx, span := s.tracer.Start(ctx, "PrepareFileArchive")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	formRow, err := s.formStore.Get(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FileArchive{}, internal.ErrFormNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form", "id", formID.String(), logger, "get form by id")
+		span.RecordError(err)
+		return FileArchive{}, err
+	}
+
+	if len(selection.QuestionIDs) > 0 {
+		answerableMap, err := s.sectionWithQuestionStore.GetAnswerableMapByFormID(traceCtx, formID)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "question", "form_id", formID.String(), logger, "get answerable map by form id")
+			span.RecordError(err)
+			return FileArchive{}, err
+		}
+		for _, questionID := range selection.QuestionIDs {
+			_, ok := answerableMap[questionID.String()]
+			if !ok {
+				return FileArchive{}, internal.ErrQuestionNotFound
+			}
+		}
+	}
+
+	rows, err := s.queries.ListAnswerFilesByFormID(traceCtx, ListAnswerFilesByFormIDParams{
+		FormID:      formID,
+		QuestionIds: selection.QuestionIDs,
+		ResponseIds: selection.ResponseIDs,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "file_attachments", "form_id", formID.String(), logger, "list answer files by form id")
+		span.RecordError(err)
+		return FileArchive{}, err
+	}
+
+	rows = slices.DeleteFunc(rows, func(row ListAnswerFilesByFormIDRow) bool {
+		quarantined := file.IsQuarantined(file.FileScanStatus(row.ScanStatus))
+		if quarantined {
+			logger.Warn("Left quarantined file out of answer file archive",
+				zap.String("fileId", row.FileID.String()),
+				zap.String("scanStatus", string(row.ScanStatus)),
+			)
+		}
+		return quarantined
+	})
+
+	paths := archivePaths(rows)
+	entries := make([]FileArchiveEntry, 0, len(rows))
+	for i, row := range rows {
+		entries = append(entries, FileArchiveEntry{Path: paths[i], File: row})
+	}
+
+	return FileArchive{
+		FormID:    formID,
+		FormTitle: formRow.Title,
+		Entries:   entries,
+	}, nil
+}
+
+// WriteFileArchive streams a ZIP of the files of an archive to w, starting with an index that maps each
+// file to its response. Files are read one at a time, so the archive is never held in memory.
+func (s *Service) WriteFileArchive(ctx context.Context, archive FileArchive, w io.Writer) error {
+	traceCtx, span := s.tracer.Start(ctx, "WriteFileArchive")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	zipWriter := zip.NewWriter(w)
+
+	err := writeArchiveIndex(zipWriter, archive.Entries)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	for _, entry := range archive.Entries {
+		storedFile, err := s.fileStore.Get(traceCtx, entry.File.FileID)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to read file %s: %w", entry.File.FileID, err)
+		}
+
+		entryWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     entry.Path,
+			Method:   zip.Deflate,
+			Modified: entry.File.CreatedAt.Time,
+		})
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		err = s.copyFile(traceCtx, entryWriter, storedFile)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Wrote answer file archive",
+		zap.String("formId", archive.FormID.String()),
+		zap.Int("files", len(archive.Entries)),
+	)
+
+	return nil
+}
+
+// copyFile streams the content of a stored file to w
+func (s *Service) copyFile(ctx context.Context, w io.Writer, storedFile file.File) error {
+	content, err := s.fileStore.Open(ctx, storedFile)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", storedFile.ID, err)
+	}
+	defer func() {
+		_ = content.Close()
+	}()
+
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// writeArchiveIndex writes the index of an archive as a UTF-8 CSV file with a byte order mark for Excel
+func writeArchiveIndex(zipWriter *zip.Writer, entries []FileArchiveEntry) error {
+	indexWriter, err := zipWriter.Create(archiveIndexName)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(indexWriter, utf8BOM)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(indexWriter)
+	err = writer.Write(archiveIndexColumns)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = writer.Write([]string{
+			escapeForExcel(entry.Path),
+			entry.File.ResponseID.String(),
+			strings.ToUpper(string(entry.File.Progress)),
+			entry.File.SubmittedBy.String(),
+			escapeForExcel(entry.File.SubmitterName),
+			entry.File.QuestionID.String(),
+			escapeForExcel(entry.File.QuestionTitle),
+			escapeForExcel(entry.File.OriginalFilename),
+			strconv.FormatInt(entry.File.Size, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// archivePaths returns the path of each file in an archive, <submitter>/<question title>/<original filename>.
+// Submitters who share a name get the start of their user ID appended, questions that share a title are
+// numbered, and files that would overwrite each other are numbered before their extension. Names are
+// compared case-insensitively so that the archive also extracts cleanly on case-insensitive file systems.
+func archivePaths(rows []ListAnswerFilesByFormIDRow) []string {
+	submitterDirs := make(map[uuid.UUID]string)
+	usedSubmitterDirs := make(map[string]bool)
+	questionDirs := make(map[string]string)
+	usedQuestionDirs := make(map[string]bool)
+	usedFileNames := make(map[string]bool)
+
+	paths := make([]string, 0, len(rows))
+	for _, row := range rows {
+		submitterDir, ok := submitterDirs[row.SubmittedBy]
+		if !ok {
+			submitterDir = sanitizeArchiveName(row.SubmitterName, row.SubmittedBy.String())
+			if usedSubmitterDirs[strings.ToLower(submitterDir)] {
+				submitterDir = fmt.Sprintf("%s (%s)", submitterDir, row.SubmittedBy.String()[:8])
+			}
+			submitterDirs[row.SubmittedBy] = submitterDir
+			usedSubmitterDirs[strings.ToLower(submitterDir)] = true
+		}
+
+		questionKey := row.SubmittedBy.String() + "/" + row.QuestionID.String()
+		questionDir, ok := questionDirs[questionKey]
+		if !ok {
+			questionDir = uniqueArchiveName(submitterDir, sanitizeArchiveName(row.QuestionTitle, row.QuestionID.String()), usedQuestionDirs)
+			questionDirs[questionKey] = questionDir
+		}
+
+		dir := path.Join(submitterDir, questionDir)
+		fileName := uniqueArchiveName(dir, sanitizeArchiveName(row.OriginalFilename, row.FileID.String()), usedFileNames)
+		paths = append(paths, path.Join(dir, fileName))
+	}
+
+	return paths
+}
+
+// uniqueArchiveName returns name, or name numbered before its extension if it is already used in dir,
+// and marks the result as used
+func uniqueArchiveName(dir, name string, used map[string]bool) string {
+	ext := path.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	base := strings.TrimSuffix(name, ext)
+
+	candidate := name
+	for n := 2; used[strings.ToLower(path.Join(dir, candidate))]; n++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, n, ext)
+	}
+
+	used[strings.ToLower(path.Join(dir, candidate))] = true
+	return candidate
+}
+
+// sanitizeArchiveName makes a user-provided name safe as one path element of an archive: separators,
+// characters that Windows rejects and control characters are replaced, surrounding spaces and dots are
+// trimmed and long names are truncated before their extension. Names left empty are replaced by fallback.
+func sanitizeArchiveName(name, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+
+	if utf8.RuneCountInString(name) > maxArchiveNameLength {
+		ext := path.Ext(name)
+		if utf8.RuneCountInString(ext) > maxArchiveNameLength/2 {
+			ext = ""
+		}
+		base := truncateRunes(strings.TrimSuffix(name, ext), maxArchiveNameLength-utf8.RuneCountInString(ext))
+		name = strings.Trim(base, " .") + ext
+	}
+
+	if name == "" {
+		return fallback
+	}
+	return name
+}
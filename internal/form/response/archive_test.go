@@ -0,0 +1,155 @@
+package response
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal/file"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+type stubFileStore map[uuid.UUID][]byte
+
+type stubContent struct {
+	*bytes.Reader
+}
+
+func (stubContent) Close() error {
+	return nil
+}
+
+func (s stubFileStore) Get(_ context.Context, id uuid.UUID) (file.File, error) {
+	return file.File{ID: id}, nil
+}
+
+func (s stubFileStore) Open(_ context.Context, storedFile file.File) (io.ReadSeekCloser, error) {
+	return stubContent{Reader: bytes.NewReader(s[storedFile.ID])}, nil
+}
+
+func TestArchivePaths(t *testing.T) {
+	alice := uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000001")
+	otherAlice := uuid.MustParse("bbbbbbbb-0000-0000-0000-000000000002")
+	portfolio := uuid.New()
+	resume := uuid.New()
+
+	rows := []ListAnswerFilesByFormIDRow{
+		{FileID: uuid.New(), SubmittedBy: alice, SubmitterName: "Alice", QuestionID: portfolio, QuestionTitle: "Portfolio", OriginalFilename: "work.pdf"},
+		{FileID: uuid.New(), SubmittedBy: alice, SubmitterName: "Alice", QuestionID: portfolio, QuestionTitle: "Portfolio", OriginalFilename: "WORK.pdf"},
+		{FileID: uuid.New(), SubmittedBy: alice, SubmitterName: "Alice", QuestionID: resume, QuestionTitle: "Portfolio", OriginalFilename: "cv.pdf"},
+		{FileID: uuid.New(), SubmittedBy: otherAlice, SubmitterName: "alice", QuestionID: portfolio, QuestionTitle: "Portfolio", OriginalFilename: "work.pdf"},
+		{FileID: uuid.New(), SubmittedBy: otherAlice, SubmitterName: "alice", QuestionID: resume, QuestionTitle: "Resume/CV", OriginalFilename: "../../etc/passwd"},
+	}
+
+	assert.Equal(t, []string{
+		"Alice/Portfolio/work.pdf",
+		"Alice/Portfolio/WORK (2).pdf",
+		"Alice/Portfolio (2)/cv.pdf",
+		"alice (bbbbbbbb)/Portfolio/work.pdf",
+		"alice (bbbbbbbb)/Resume_CV/_.._etc_passwd",
+	}, archivePaths(rows))
+}
+
+func TestSanitizeArchiveName(t *testing.T) {
+	fallback := "fallback"
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain name", input: "report.pdf", expected: "report.pdf"},
+		{name: "separators and reserved characters", input: `a/b\c:d*e?f"g<h>i|j`, expected: "a_b_c_d_e_f_g_h_i_j"},
+		{name: "control characters", input: "line\nbreak\t.txt", expected: "line_break_.txt"},
+		{name: "dot names", input: "..", expected: fallback},
+		{name: "surrounding spaces and dots", input: " .hidden. ", expected: "hidden"},
+		{name: "empty", input: "   ", expected: fallback},
+		{name: "long name keeps its extension", input: strings.Repeat("長", 150) + ".pdf", expected: strings.Repeat("長", maxArchiveNameLength-4) + ".pdf"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sanitizeArchiveName(tc.input, fallback))
+		})
+	}
+}
+
+func TestService_WriteFileArchive(t *testing.T) {
+	responseID := uuid.New()
+	first := ListAnswerFilesByFormIDRow{
+		FileID:           uuid.New(),
+		OriginalFilename: "=cmd.pdf",
+		Size:             5,
+		ResponseID:       responseID,
+		SubmittedBy:      uuid.New(),
+		Progress:         ResponseProgressSubmitted,
+		SubmitterName:    "Alice",
+		QuestionID:       uuid.New(),
+		QuestionTitle:    "Portfolio",
+	}
+	second := first
+	second.FileID = uuid.New()
+	second.OriginalFilename = "notes.txt"
+
+	svc := Service{
+		logger: zap.NewNop(),
+		tracer: otel.Tracer("response/archive_test"),
+		fileStore: stubFileStore{
+			first.FileID:  []byte("first"),
+			second.FileID: []byte("second"),
+		},
+	}
+	archive := FileArchive{
+		FormID: uuid.New(),
+		Entries: []FileArchiveEntry{
+			{Path: "Alice/Portfolio/=cmd.pdf", File: first},
+			{Path: "Alice/Portfolio/notes.txt", File: second},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.WriteFileArchive(context.Background(), archive, &buf))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 3)
+
+	contents := make(map[string]string, len(reader.File))
+	for _, entry := range reader.File {
+		rc, err := entry.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		contents[entry.Name] = string(data)
+	}
+
+	assert.Equal(t, archiveIndexName, reader.File[0].Name)
+	assert.Equal(t, "first", contents["Alice/Portfolio/=cmd.pdf"])
+	assert.Equal(t, "second", contents["Alice/Portfolio/notes.txt"])
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(contents[archiveIndexName], utf8BOM))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, archiveIndexColumns, records[0])
+	assert.Equal(t, []string{
+		"Alice/Portfolio/=cmd.pdf",
+		responseID.String(),
+		"SUBMITTED",
+		first.SubmittedBy.String(),
+		"Alice",
+		first.QuestionID.String(),
+		"Portfolio",
+		"'=cmd.pdf",
+		"5",
+	}, records[1])
+}
@@ -0,0 +1,591 @@
+package response
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatXLSX      ExportFormat = "xlsx"
+	ExportFormatCSV       ExportFormat = "csv"
+	ExportFormatJSONLines ExportFormat = "jsonl"
+)
+
+const (
+	// Excel limits sheet names to 31 characters
+	maxSheetNameLength = 31
+	metadataSheetName  = "Metadata"
+)
+
+// exportProgressInterval is the number of responses between calls to ExportPlan.OnProgress
+const exportProgressInterval = 500
+
+// utf8BOM lets Excel detect that a CSV file is UTF-8 instead of the system code page
+const utf8BOM = "\xEF\xBB\xBF"
+
+// responseColumns are the columns before the question columns in CSV and XLSX exports
+var responseColumns = []string{"Response ID", "Submitter", "Email", "Submitted At", "Progress"}
+
+// exportAnswer is an entry of the answers object of an export row
+type exportAnswer struct {
+	Value     json.RawMessage `json:"value"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// ParseExportFormat parses a format case-insensitively; an empty format is XLSX
+func ParseExportFormat(s string) (ExportFormat, error) {
+	format := ExportFormat(strings.ToLower(strings.TrimSpace(s)))
+	switch format {
+	case "":
+		return ExportFormatXLSX, nil
+	case ExportFormatXLSX, ExportFormatCSV, ExportFormatJSONLines:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: %q", internal.ErrInvalidExportFormat, s)
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ExportFormatJSONLines:
+		return "application/jsonl; charset=utf-8"
+	default:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+}
+
+func (f ExportFormat) Extension() string {
+	return string(f)
+}
+
+// WriteExport streams the responses of a prepared export to w in the given format, one response at a time.
+// CSV and JSON Lines reach w as the rows are read; XLSX rows are buffered on disk by excelize and the workbook
+// is written to w once every row has been read.
+func (s *Service) WriteExport(ctx context.Context, plan ExportPlan, format ExportFormat, w io.Writer) error {
+	traceCtx, span := s.tracer.Start(ctx, "WriteExport")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	writer, err := newExportWriter(format, plan, w, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	count := 0
+	err = s.forEachExportRow(traceCtx, plan, func(row ExportRow, response exportResponse) error {
+		err := writer.WriteRow(row, response)
+		if err != nil {
+			return err
+		}
+
+		count++
+		if plan.OnProgress != nil && count%exportProgressInterval == 0 {
+			plan.OnProgress(count)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = writer.Abort()
+		span.RecordError(err)
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if plan.OnProgress != nil {
+		plan.OnProgress(count)
+	}
+
+	logger.Info("Wrote response export",
+		zap.String("formId", plan.FormID.String()),
+		zap.String("format", string(format)),
+		zap.Int("responses", count),
+	)
+
+	return nil
+}
+
+// CountExport returns the number of responses a prepared export writes
+func (s *Service) CountExport(ctx context.Context, plan ExportPlan) (int32, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CountExport")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	counts, err := s.queries.CountByFormID(traceCtx, plan.filter.countParams(plan.FormID))
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", plan.FormID.String(), logger, "count export rows by form id")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return counts.TotalCount, nil
+}
+
+// HeaderCells returns the header row of the CSV export of a prepared export, unescaped for callers that
+// write the cells as raw values
+func (p ExportPlan) HeaderCells() []string {
+	return headerCells(p.Headers, rawCell)
+}
+
+// ForEachExportCells calls fn with the ID and the cells of each response of a prepared export, in submission
+// order and in the column layout of the CSV export. The cells are unescaped, like those of HeaderCells.
+func (s *Service) ForEachExportCells(ctx context.Context, plan ExportPlan, fn func(responseID uuid.UUID, cells []string) error) error {
+	traceCtx, span := s.tracer.Start(ctx, "ForEachExportCells")
+	defer span.End()
+
+	err := s.forEachExportRow(traceCtx, plan, func(row ExportRow, response exportResponse) error {
+		responseID, err := uuid.Parse(row.ID)
+		if err != nil {
+			return err
+		}
+		return fn(responseID, rowCells(plan.Headers, row, response, rawCell))
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// forEachExportRow reads the responses of an export in submission order and calls fn with each of them
+func (s *Service) forEachExportRow(ctx context.Context, plan ExportPlan, fn func(ExportRow, exportResponse) error) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	var rowErr error
+	err := s.queries.IterateExportRowsByFormID(ctx, exportParams(plan.FormID, plan.filter, plan.questionIDs), func(dbRow ListExportRowsByFormIDRow) error {
+		row, err := plan.buildRow(dbRow)
+		if err == nil {
+			err = fn(row, exportResponse{
+				SubmittedBy:    dbRow.SubmittedBy,
+				SubmitterName:  dbRow.SubmitterName,
+				SubmitterEmail: dbRow.SubmitterEmail,
+				SubmittedAt:    dbRow.SubmittedAt,
+				Progress:       dbRow.Progress,
+			})
+		}
+		// Kept apart so that errors of fn are not reported as database errors
+		rowErr = err
+		return err
+	})
+	if rowErr != nil {
+		return rowErr
+	}
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", plan.FormID.String(), logger, "iterate export rows by form id")
+	}
+
+	return nil
+}
+
+// buildRow maps a response read by the export query to an export row with the display value of each answer
+func (p ExportPlan) buildRow(dbRow ListExportRowsByFormIDRow) (ExportRow, error) {
+	var answers map[string]exportAnswer
+	err := json.Unmarshal(dbRow.Answers, &answers)
+	if err != nil {
+		return ExportRow{}, fmt.Errorf("failed to parse answers of response %s: %w", dbRow.ID, err)
+	}
+
+	row := ExportRow{
+		ID:      dbRow.ID.String(),
+		Answers: make(map[string]*AnswerPayload, len(p.Headers)),
+	}
+	for _, header := range p.Headers {
+		answer, ok := answers[header.ID]
+		if !ok {
+			row.Answers[header.ID] = nil
+			continue
+		}
+
+		displayValue, err := p.answerables[header.ID].DisplayValue(answer.Value)
+		if err != nil {
+			return ExportRow{}, err
+		}
+		row.Answers[header.ID] = &AnswerPayload{
+			CreatedAt:    answer.CreatedAt,
+			UpdatedAt:    answer.UpdatedAt,
+			ResponseID:   row.ID,
+			Answer:       answer.Value,
+			DisplayValue: displayValue,
+		}
+	}
+
+	return row, nil
+}
+
+// exportWriter writes the rows of an export one at a time. Close finishes the file; Abort releases its
+// resources without finishing it.
+type exportWriter interface {
+	WriteRow(row ExportRow, response exportResponse) error
+	Close() error
+	Abort() error
+}
+
+func newExportWriter(format ExportFormat, plan ExportPlan, w io.Writer, exportedAt time.Time) (exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVExportWriter(plan, w)
+	case ExportFormatJSONLines:
+		return newJSONLinesExportWriter(plan, w), nil
+	default:
+		return newXLSXExportWriter(plan, w, exportedAt)
+	}
+}
+
+// responseCells returns the values of the response columns of a row
+func responseCells(row ExportRow, response exportResponse) []string {
+	submittedAt := ""
+	if response.SubmittedAt.Valid {
+		submittedAt = response.SubmittedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		row.ID,
+		response.SubmitterName,
+		response.SubmitterEmail,
+		submittedAt,
+		strings.ToUpper(string(response.Progress)),
+	}
+}
+
+// headerCells returns the response columns followed by the titles of the given question columns, each
+// passed through escape
+func headerCells(headers []ExportHeader, escape func(string) string) []string {
+	cells := make([]string, 0, len(responseColumns)+len(headers))
+	cells = append(cells, responseColumns...)
+	for _, header := range headers {
+		cells = append(cells, escape(header.Title))
+	}
+	return cells
+}
+
+// rowCells returns the response columns of a row followed by the display values of the given question
+// columns, each passed through escape; files pass escapeForExcel against formula injection
+func rowCells(headers []ExportHeader, row ExportRow, response exportResponse, escape func(string) string) []string {
+	cells := make([]string, 0, len(responseColumns)+len(headers))
+	for _, cell := range responseCells(row, response) {
+		cells = append(cells, escape(cell))
+	}
+	for _, header := range headers {
+		payload := row.Answers[header.ID]
+		if payload == nil {
+			cells = append(cells, "")
+			continue
+		}
+		cells = append(cells, escape(payload.DisplayValue))
+	}
+	return cells
+}
+
+// rawCell leaves a cell as it is, for cells written where they are never evaluated as formulas
+func rawCell(s string) string {
+	return s
+}
+
+// csvExportWriter writes every response as one row of a UTF-8 CSV file with a byte order mark for Excel
+type csvExportWriter struct {
+	writer  *csv.Writer
+	headers []ExportHeader
+}
+
+func newCSVExportWriter(plan ExportPlan, w io.Writer) (*csvExportWriter, error) {
+	_, err := io.WriteString(w, utf8BOM)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := csv.NewWriter(w)
+	err = writer.Write(headerCells(plan.Headers, escapeForExcel))
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvExportWriter{writer: writer, headers: plan.Headers}, nil
+}
+
+func (c *csvExportWriter) WriteRow(row ExportRow, response exportResponse) error {
+	return c.writer.Write(rowCells(c.headers, row, response, escapeForExcel))
+}
+
+func (c *csvExportWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvExportWriter) Abort() error {
+	return nil
+}
+
+type exportJSONLine struct {
+	ResponseID     string         `json:"responseId"`
+	SubmittedBy    string         `json:"submittedBy"`
+	SubmitterName  string         `json:"submitterName"`
+	SubmitterEmail string         `json:"submitterEmail"`
+	SubmittedAt    *time.Time     `json:"submittedAt"`
+	Progress       string         `json:"progress"`
+	Answers        map[string]any `json:"answers"`
+}
+
+// jsonLinesExportWriter writes one JSON object per response with typed answer values keyed by question ID;
+// unanswered questions are null
+type jsonLinesExportWriter struct {
+	encoder *json.Encoder
+	plan    ExportPlan
+}
+
+func newJSONLinesExportWriter(plan ExportPlan, w io.Writer) *jsonLinesExportWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &jsonLinesExportWriter{encoder: encoder, plan: plan}
+}
+
+func (j *jsonLinesExportWriter) WriteRow(row ExportRow, response exportResponse) error {
+	line := exportJSONLine{
+		ResponseID:     row.ID,
+		SubmittedBy:    response.SubmittedBy.String(),
+		SubmitterName:  response.SubmitterName,
+		SubmitterEmail: response.SubmitterEmail,
+		Progress:       strings.ToUpper(string(response.Progress)),
+		Answers:        make(map[string]any, len(j.plan.Headers)),
+	}
+	if response.SubmittedAt.Valid {
+		submittedAt := response.SubmittedAt.Time.UTC()
+		line.SubmittedAt = &submittedAt
+	}
+
+	for _, header := range j.plan.Headers {
+		payload := row.Answers[header.ID]
+		if payload == nil {
+			line.Answers[header.ID] = nil
+			continue
+		}
+
+		value, err := j.plan.answerables[header.ID].DecodeStorage(payload.Answer)
+		if err != nil {
+			return fmt.Errorf("failed to decode answer of question %s in response %s: %w", header.ID, row.ID, err)
+		}
+		line.Answers[header.ID] = value
+	}
+
+	return j.encoder.Encode(line)
+}
+
+func (j *jsonLinesExportWriter) Close() error {
+	return nil
+}
+
+func (j *jsonLinesExportWriter) Abort() error {
+	return nil
+}
+
+type xlsxSheet struct {
+	stream  *excelize.StreamWriter
+	headers []ExportHeader
+}
+
+// xlsxExportWriter writes one sheet per section with the response columns followed by the questions of the
+// section, and a metadata sheet describing the export. Rows go through excelize stream writers, which spill
+// to temporary files instead of growing in memory.
+type xlsxExportWriter struct {
+	file       *excelize.File
+	w          io.Writer
+	sheets     []xlsxSheet
+	plan       ExportPlan
+	exportedAt time.Time
+	rowCount   int
+}
+
+func newXLSXExportWriter(plan ExportPlan, w io.Writer, exportedAt time.Time) (*xlsxExportWriter, error) {
+	x := &xlsxExportWriter{
+		file:       excelize.NewFile(),
+		w:          w,
+		plan:       plan,
+		exportedAt: exportedAt,
+	}
+
+	sections := plan.sections
+	if len(sections) == 0 {
+		sections = []exportSection{{Title: "Responses"}}
+	}
+
+	usedNames := map[string]bool{strings.ToLower(metadataSheetName): true}
+	for i, section := range sections {
+		sheet := uniqueSheetName(section.Title, i, usedNames)
+		var err error
+		if i == 0 {
+			err = x.file.SetSheetName("Sheet1", sheet)
+		} else {
+			_, err = x.file.NewSheet(sheet)
+		}
+		if err != nil {
+			_ = x.Abort()
+			return nil, err
+		}
+
+		stream, err := x.file.NewStreamWriter(sheet)
+		if err != nil {
+			_ = x.Abort()
+			return nil, err
+		}
+		err = setStreamRow(stream, 1, headerCells(section.Headers, escapeForExcel))
+		if err != nil {
+			_ = x.Abort()
+			return nil, err
+		}
+
+		x.sheets = append(x.sheets, xlsxSheet{stream: stream, headers: section.Headers})
+	}
+
+	return x, nil
+}
+
+func (x *xlsxExportWriter) WriteRow(row ExportRow, response exportResponse) error {
+	x.rowCount++
+	for _, sheet := range x.sheets {
+		// The header is the first row of each sheet
+		err := setStreamRow(sheet.stream, x.rowCount+1, rowCells(sheet.headers, row, response, escapeForExcel))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *xlsxExportWriter) Close() error {
+	defer func() {
+		_ = x.file.Close()
+	}()
+
+	for _, sheet := range x.sheets {
+		err := sheet.stream.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := x.file.NewSheet(metadataSheetName)
+	if err != nil {
+		return err
+	}
+	metadata, err := x.file.NewStreamWriter(metadataSheetName)
+	if err != nil {
+		return err
+	}
+	for i, cells := range exportMetadataRows(x.plan, x.rowCount, x.exportedAt) {
+		err = setStreamRow(metadata, i+1, cells)
+		if err != nil {
+			return err
+		}
+	}
+	err = metadata.Flush()
+	if err != nil {
+		return err
+	}
+
+	return x.file.Write(x.w)
+}
+
+// Abort removes the temporary files of the stream writers
+func (x *xlsxExportWriter) Abort() error {
+	return x.file.Close()
+}
+
+func setStreamRow(stream *excelize.StreamWriter, rowNumber int, cells []string) error {
+	cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(cells))
+	for i, value := range cells {
+		values[i] = value
+	}
+	return stream.SetRow(cell, values)
+}
+
+// exportMetadataRows describes the form, the time of the export and the filter it was made with
+func exportMetadataRows(plan ExportPlan, responseCount int, exportedAt time.Time) [][]string {
+	filter := plan.filter
+	progress := "SUBMITTED"
+	if filter.Progress != nil {
+		progress = strings.ToUpper(string(*filter.Progress))
+	}
+
+	rows := [][]string{
+		{"Form", escapeForExcel(plan.FormTitle)},
+		{"Form ID", plan.FormID.String()},
+		{"Exported At", exportedAt.UTC().Format(time.RFC3339)},
+		{"Responses", strconv.Itoa(responseCount)},
+		{"Questions", strconv.Itoa(len(plan.Headers))},
+		{"Progress", progress},
+	}
+	if filter.SubmittedFrom != nil {
+		rows = append(rows, []string{"Submitted From", filter.SubmittedFrom.UTC().Format(time.RFC3339)})
+	}
+	if filter.SubmittedTo != nil {
+		rows = append(rows, []string{"Submitted To", filter.SubmittedTo.UTC().Format(time.RFC3339)})
+	}
+	if filter.SubmittedBy != nil {
+		rows = append(rows, []string{"Submitter", filter.SubmittedBy.String()})
+	}
+	if filter.Search != "" {
+		rows = append(rows, []string{"Search", escapeForExcel(filter.Search)})
+	}
+
+	return rows
+}
+
+// uniqueSheetName turns a section title into a valid sheet name that is not in usedNames, and records it.
+// Excel sheet names are case-insensitive, at most 31 characters, and cannot contain : \ / ? * [ ] or
+// start or end with an apostrophe.
+func uniqueSheetName(title string, index int, usedNames map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return ' '
+		}
+		return r
+	}, title)
+	name = strings.Trim(strings.TrimSpace(name), "'")
+	if name == "" {
+		name = fmt.Sprintf("Section %d", index+1)
+	}
+	name = truncateRunes(name, maxSheetNameLength)
+
+	candidate := name
+	for n := 2; usedNames[strings.ToLower(candidate)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = truncateRunes(name, maxSheetNameLength-len(suffix)) + suffix
+	}
+
+	usedNames[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// IterateExportRowsByFormID runs ListExportRowsByFormID and calls fn with each row as it is read from the
+// connection instead of collecting them, so that exports of large forms do not hold every response in memory.
+// Iteration stops at the first error returned by fn.
+func (q *Queries) IterateExportRowsByFormID(ctx context.Context, arg ListExportRowsByFormIDParams, fn func(ListExportRowsByFormIDRow) error) error {
+	rows, err := q.db.Query(ctx, listExportRowsByFormID,
+		arg.QuestionIds,
+		arg.FormID,
+		arg.Progress,
+		arg.SubmittedFrom,
+		arg.SubmittedTo,
+		arg.SubmittedBy,
+		arg.Search,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// Scanning by position into the generated row type keeps the scan in step with the columns of the query
+		i, err := pgx.RowToStructByPos[ListExportRowsByFormIDRow](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,62 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+var errQueryRecorded = errors.New("query recorded")
+
+// recordingDB records the SQL and arguments of the queries it is given and fails them
+type recordingDB struct {
+	sql  []string
+	args [][]any
+}
+
+func (db *recordingDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errQueryRecorded
+}
+
+func (db *recordingDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	db.sql = append(db.sql, sql)
+	db.args = append(db.args, args)
+	return nil, errQueryRecorded
+}
+
+func (db *recordingDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+func TestQueries_IterateExportRowsByFormID(t *testing.T) {
+	submittedBy := uuid.New()
+	arg := ListExportRowsByFormIDParams{
+		QuestionIds:   []uuid.UUID{uuid.New(), uuid.New()},
+		FormID:        uuid.New(),
+		Progress:      NullResponseProgress{ResponseProgress: ResponseProgressDraft, Valid: true},
+		SubmittedFrom: pgtype.Timestamptz{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		SubmittedTo:   pgtype.Timestamptz{Time: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		SubmittedBy:   pgtype.UUID{Bytes: submittedBy, Valid: true},
+		Search:        pgtype.Text{String: "NYCU", Valid: true},
+	}
+
+	db := &recordingDB{}
+	queries := New(db)
+
+	_, err := queries.ListExportRowsByFormID(context.Background(), arg)
+	require.ErrorIs(t, err, errQueryRecorded)
+	err = queries.IterateExportRowsByFormID(context.Background(), arg, func(ListExportRowsByFormIDRow) error { return nil })
+	require.ErrorIs(t, err, errQueryRecorded)
+
+	// The iterator runs the generated query with the arguments in the same order
+	require.Len(t, db.sql, 2)
+	require.Equal(t, db.sql[0], db.sql[1])
+	require.Equal(t, db.args[0], db.args[1])
+}
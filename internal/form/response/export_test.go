@@ -0,0 +1,208 @@
+package response
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportFixture is a prepared export of two short text questions in two sections with one response
+type exportFixture struct {
+	plan     ExportPlan
+	row      ExportRow
+	response exportResponse
+}
+
+func newExportFixture(t *testing.T) exportFixture {
+	t.Helper()
+
+	nameID := uuid.New()
+	noteID := uuid.New()
+	answerables := map[string]question.Answerable{}
+	for _, id := range []uuid.UUID{nameID, noteID} {
+		answerable, err := question.NewAnswerable(question.Question{ID: id, Type: question.QuestionTypeShortText}, uuid.New())
+		require.NoError(t, err)
+		answerables[id.String()] = answerable
+	}
+
+	nameHeader := ExportHeader{ID: nameID.String(), Title: "Name"}
+	noteHeader := ExportHeader{ID: noteID.String(), Title: "=Note"}
+	responseID := uuid.New()
+
+	return exportFixture{
+		plan: ExportPlan{
+			FormID:      uuid.New(),
+			FormTitle:   "Recruitment",
+			Headers:     []ExportHeader{nameHeader, noteHeader},
+			questionIDs: []uuid.UUID{nameID, noteID},
+			sections: []exportSection{
+				{Title: "Basic Info", Headers: []ExportHeader{nameHeader}},
+				{Title: "Basic/Info", Headers: []ExportHeader{noteHeader}},
+			},
+			answerables: answerables,
+		},
+		row: ExportRow{
+			ID: responseID.String(),
+			Answers: map[string]*AnswerPayload{
+				nameID.String(): {Answer: json.RawMessage(`{"value":"陳小明"}`), DisplayValue: "陳小明"},
+				noteID.String(): nil,
+			},
+		},
+		response: exportResponse{
+			SubmittedBy:    uuid.New(),
+			SubmitterName:  "@admin",
+			SubmitterEmail: "student@nycu.edu.tw",
+			SubmittedAt:    pgtype.Timestamptz{Time: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), Valid: true},
+			Progress:       ResponseProgressSubmitted,
+		},
+	}
+}
+
+// writeFixture writes the response of the fixture with a new export writer of the given format
+func writeFixture(t *testing.T, fixture exportFixture, format ExportFormat) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer, err := newExportWriter(format, fixture.plan, &buf, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRow(fixture.row, fixture.response))
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseExportFormat(t *testing.T) {
+	testCases := []struct {
+		input       string
+		expected    ExportFormat
+		expectedErr bool
+	}{
+		{input: "", expected: ExportFormatXLSX},
+		{input: "CSV", expected: ExportFormatCSV},
+		{input: "jsonl", expected: ExportFormatJSONLines},
+		{input: "pdf", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			format, err := ParseExportFormat(tc.input)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, internal.ErrInvalidExportFormat)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, format)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	fixture := newExportFixture(t)
+
+	content := writeFixture(t, fixture, ExportFormatCSV)
+	require.True(t, bytes.HasPrefix(content, []byte(utf8BOM)))
+
+	records, err := csv.NewReader(bytes.NewReader(content[len(utf8BOM):])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, []string{"Response ID", "Submitter", "Email", "Submitted At", "Progress", "Name", "'=Note"}, records[0])
+	assert.Equal(t, []string{fixture.row.ID, "'@admin", "student@nycu.edu.tw", "2026-03-01T08:00:00Z", "SUBMITTED", "陳小明", ""}, records[1])
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	fixture := newExportFixture(t)
+
+	content := writeFixture(t, fixture, ExportFormatJSONLines)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 1)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, fixture.row.ID, line["responseId"])
+	assert.Equal(t, "@admin", line["submitterName"])
+	assert.Equal(t, "2026-03-01T08:00:00Z", line["submittedAt"])
+	assert.Equal(t, map[string]any{
+		fixture.plan.Headers[0].ID: map[string]any{"value": "陳小明"},
+		fixture.plan.Headers[1].ID: nil,
+	}, line["answers"])
+}
+
+func TestWriteXLSX(t *testing.T) {
+	fixture := newExportFixture(t)
+	search := "小明"
+	fixture.plan.filter = ListFilter{Search: search}
+
+	content := writeFixture(t, fixture, ExportFormatXLSX)
+
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	assert.Equal(t, []string{"Basic Info", "Basic Info (2)", "Metadata"}, file.GetSheetList())
+
+	rows, err := file.GetRows("Basic Info")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Response ID", "Submitter", "Email", "Submitted At", "Progress", "Name"},
+		{fixture.row.ID, "'@admin", "student@nycu.edu.tw", "2026-03-01T08:00:00Z", "SUBMITTED", "陳小明"},
+	}, rows)
+
+	metadata, err := file.GetRows("Metadata")
+	require.NoError(t, err)
+	assert.Contains(t, metadata, []string{"Form", "Recruitment"})
+	assert.Contains(t, metadata, []string{"Progress", "SUBMITTED"})
+	assert.Contains(t, metadata, []string{"Responses", "1"})
+	assert.Contains(t, metadata, []string{"Search", search})
+}
+
+func TestExportPlan_BuildRow(t *testing.T) {
+	fixture := newExportFixture(t)
+	nameID := fixture.plan.Headers[0].ID
+	noteID := fixture.plan.Headers[1].ID
+	responseID := uuid.New()
+
+	row, err := fixture.plan.buildRow(ListExportRowsByFormIDRow{
+		ID:      responseID,
+		Answers: []byte(`{"` + nameID + `":{"value":{"value":"陳小明"},"createdAt":"2026-03-01T08:00:00Z","updatedAt":"2026-03-01T09:00:00Z"}}`),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, responseID.String(), row.ID)
+	require.Contains(t, row.Answers, noteID)
+	assert.Nil(t, row.Answers[noteID])
+	require.NotNil(t, row.Answers[nameID])
+	assert.Equal(t, "陳小明", row.Answers[nameID].DisplayValue)
+	assert.Equal(t, responseID.String(), row.Answers[nameID].ResponseID)
+	assert.Equal(t, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), row.Answers[nameID].UpdatedAt.UTC())
+
+	_, err = fixture.plan.buildRow(ListExportRowsByFormIDRow{ID: responseID, Answers: []byte(`not json`)})
+	require.Error(t, err)
+}
+
+func TestUniqueSheetName(t *testing.T) {
+	usedNames := map[string]bool{"metadata": true}
+
+	assert.Equal(t, "Section 1", uniqueSheetName("  ", 0, usedNames))
+	assert.Equal(t, "metadata (2)", uniqueSheetName("metadata", 1, usedNames))
+	assert.Equal(t, "Q1 Q2", uniqueSheetName("'Q1?Q2'", 2, usedNames))
+
+	long := strings.Repeat("社", 40)
+	assert.Equal(t, strings.Repeat("社", maxSheetNameLength), uniqueSheetName(long, 3, usedNames))
+	assert.Equal(t, strings.Repeat("社", maxSheetNameLength-4)+" (2)", uniqueSheetName(long, 4, usedNames))
+}
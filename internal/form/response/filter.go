@@ -0,0 +1,142 @@
+package response
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxSearchLength bounds the full-text search term of the response list
+const maxSearchLength = 100
+
+// ListFilter narrows the responses of a form in the response list and in exports.
+// Zero fields match every response. Export jobs store it as JSON.
+type ListFilter struct {
+	Progress *ResponseProgress `json:"progress,omitempty"`
+	// SubmittedFrom is inclusive and SubmittedTo exclusive; either excludes drafts, which have no submission time
+	SubmittedFrom *time.Time `json:"submittedFrom,omitempty"`
+	SubmittedTo   *time.Time `json:"submittedTo,omitempty"`
+	SubmittedBy   *uuid.UUID `json:"submittedBy,omitempty"`
+	// Search matches responses with an answer whose text contains it, case-insensitively
+	Search string `json:"search,omitempty"`
+}
+
+func (f ListFilter) validate() error {
+	if f.Progress != nil && *f.Progress != ResponseProgressDraft && *f.Progress != ResponseProgressSubmitted {
+		return fmt.Errorf("%w: unknown progress %q", internal.ErrInvalidResponseFilter, *f.Progress)
+	}
+	if f.SubmittedFrom != nil && f.SubmittedTo != nil && !f.SubmittedFrom.Before(*f.SubmittedTo) {
+		return fmt.Errorf("%w: submittedFrom must be before submittedTo", internal.ErrInvalidResponseFilter)
+	}
+	if utf8.RuneCountInString(f.Search) > maxSearchLength {
+		return fmt.Errorf("%w: search cannot exceed %d characters", internal.ErrInvalidResponseFilter, maxSearchLength)
+	}
+	return nil
+}
+
+// countParams maps the filter to query parameters
+func (f ListFilter) countParams(formID uuid.UUID) CountByFormIDParams {
+	params := CountByFormIDParams{FormID: formID}
+	if f.Progress != nil {
+		params.Progress = NullResponseProgress{ResponseProgress: *f.Progress, Valid: true}
+	}
+	if f.SubmittedFrom != nil {
+		params.SubmittedFrom = pgtype.Timestamptz{Time: *f.SubmittedFrom, Valid: true}
+	}
+	if f.SubmittedTo != nil {
+		params.SubmittedTo = pgtype.Timestamptz{Time: *f.SubmittedTo, Valid: true}
+	}
+	if f.SubmittedBy != nil {
+		params.SubmittedBy = pgtype.UUID{Bytes: *f.SubmittedBy, Valid: true}
+	}
+	search := strings.TrimSpace(f.Search)
+	if search != "" {
+		params.Search = pgtype.Text{String: escapeLikePattern(search), Valid: true}
+	}
+	return params
+}
+
+// escapeLikePattern escapes the wildcards of ILIKE so that the search term is matched literally
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// listCursor is the position after the last response of a page in list order
+type listCursor struct {
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+	ID          uuid.UUID  `json:"id"`
+}
+
+// encodeCursor returns the opaque cursor of the page that starts after the given response
+func encodeCursor(r FormResponse) string {
+	cursor := listCursor{ID: r.ID}
+	if r.SubmittedAt.Valid {
+		submittedAt := r.SubmittedAt.Time
+		cursor.SubmittedAt = &submittedAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return listCursor{}, fmt.Errorf("%w: %w", internal.ErrInvalidResponseCursor, err)
+	}
+
+	var cursor listCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return listCursor{}, fmt.Errorf("%w: %w", internal.ErrInvalidResponseCursor, err)
+	}
+	if cursor.ID == uuid.Nil {
+		return listCursor{}, fmt.Errorf("%w: missing response id", internal.ErrInvalidResponseCursor)
+	}
+
+	return cursor, nil
+}
+
+// pageParams maps the filter and cursor to the parameters of a page query that fetches one extra
+// response to tell whether another page follows
+func pageParams(formID uuid.UUID, filter ListFilter, cursor *listCursor, limit int) ListPageByFormIDParams {
+	count := filter.countParams(formID)
+	params := ListPageByFormIDParams{
+		FormID:        count.FormID,
+		Progress:      count.Progress,
+		SubmittedFrom: count.SubmittedFrom,
+		SubmittedTo:   count.SubmittedTo,
+		SubmittedBy:   count.SubmittedBy,
+		Search:        count.Search,
+		PageSize:      int32(limit + 1),
+	}
+	if cursor != nil {
+		params.CursorID = pgtype.UUID{Bytes: cursor.ID, Valid: true}
+		if cursor.SubmittedAt != nil {
+			params.CursorSubmittedAt = pgtype.Timestamptz{Time: *cursor.SubmittedAt, Valid: true}
+		}
+	}
+	return params
+}
+
+// exportParams maps the filter to the parameters of the export query for the given question columns
+func exportParams(formID uuid.UUID, filter ListFilter, questionIDs []uuid.UUID) ListExportRowsByFormIDParams {
+	count := filter.countParams(formID)
+	return ListExportRowsByFormIDParams{
+		QuestionIds:   questionIDs,
+		FormID:        count.FormID,
+		Progress:      count.Progress,
+		SubmittedFrom: count.SubmittedFrom,
+		SubmittedTo:   count.SubmittedTo,
+		SubmittedBy:   count.SubmittedBy,
+		Search:        count.Search,
+	}
+}
@@ -0,0 +1,167 @@
+package response
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+type stubQuerier struct {
+	Querier
+	listPageFn func(context.Context, ListPageByFormIDParams) ([]FormResponse, error)
+	countFn    func(context.Context, CountByFormIDParams) (CountByFormIDRow, error)
+}
+
+func (s stubQuerier) ListPageByFormID(ctx context.Context, arg ListPageByFormIDParams) ([]FormResponse, error) {
+	return s.listPageFn(ctx, arg)
+}
+
+func (s stubQuerier) CountByFormID(ctx context.Context, arg CountByFormIDParams) (CountByFormIDRow, error) {
+	return s.countFn(ctx, arg)
+}
+
+type stubFormStore struct{}
+
+func (stubFormStore) Exists(context.Context, uuid.UUID) (bool, error) { return true, nil }
+
+func (stubFormStore) Get(context.Context, uuid.UUID) (form.GetRow, error) { return form.GetRow{}, nil }
+
+func TestListCursor_RoundTrip(t *testing.T) {
+	submittedAt := time.Date(2026, 3, 1, 8, 30, 0, 123456000, time.UTC)
+
+	testCases := []struct {
+		name     string
+		response FormResponse
+	}{
+		{name: "submitted response", response: FormResponse{ID: uuid.New(), SubmittedAt: pgtype.Timestamptz{Time: submittedAt, Valid: true}}},
+		{name: "draft response", response: FormResponse{ID: uuid.New()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor, err := decodeCursor(encodeCursor(tc.response))
+			require.NoError(t, err)
+			assert.Equal(t, tc.response.ID, cursor.ID)
+
+			params := pageParams(uuid.New(), ListFilter{}, &cursor, 10)
+			assert.Equal(t, pgtype.UUID{Bytes: tc.response.ID, Valid: true}, params.CursorID)
+			assert.Equal(t, tc.response.SubmittedAt.Valid, params.CursorSubmittedAt.Valid)
+			assert.True(t, params.CursorSubmittedAt.Time.Equal(tc.response.SubmittedAt.Time))
+			assert.Equal(t, int32(11), params.PageSize)
+		})
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := decodeCursor(cursor)
+		require.ErrorIs(t, err, internal.ErrInvalidResponseCursor, cursor)
+	}
+}
+
+func TestResponseFilterRequest_ToListFilter(t *testing.T) {
+	submitter := uuid.New()
+
+	testCases := []struct {
+		name        string
+		request     ResponseFilterRequest
+		expectedErr bool
+	}{
+		{name: "no filters", request: ResponseFilterRequest{}},
+		{
+			name: "every filter",
+			request: ResponseFilterRequest{
+				Progress:      "SUBMITTED",
+				SubmittedFrom: "2026-01-01T00:00:00Z",
+				SubmittedTo:   "2026-02-01T00:00:00+08:00",
+				Submitter:     submitter.String(),
+				Query:         " 陽明交大 ",
+			},
+		},
+		{name: "unknown progress", request: ResponseFilterRequest{Progress: "ARCHIVED"}, expectedErr: true},
+		{name: "date without time", request: ResponseFilterRequest{SubmittedFrom: "2026-01-01"}, expectedErr: true},
+		{name: "empty range", request: ResponseFilterRequest{SubmittedFrom: "2026-02-01T00:00:00Z", SubmittedTo: "2026-01-01T00:00:00Z"}, expectedErr: true},
+		{name: "invalid submitter", request: ResponseFilterRequest{Submitter: "alice"}, expectedErr: true},
+		{name: "search too long", request: ResponseFilterRequest{Query: strings.Repeat("搜", maxSearchLength+1)}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.request.ToListFilter()
+			if tc.expectedErr {
+				require.ErrorIs(t, err, internal.ErrInvalidResponseFilter)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestListFilter_CountParams(t *testing.T) {
+	progress := ResponseProgressDraft
+	params := ListFilter{Progress: &progress, Search: ` 100%_done\ `}.countParams(uuid.New())
+
+	assert.Equal(t, NullResponseProgress{ResponseProgress: ResponseProgressDraft, Valid: true}, params.Progress)
+	assert.Equal(t, pgtype.Text{String: `100\%\_done\\`, Valid: true}, params.Search)
+	assert.False(t, params.SubmittedFrom.Valid)
+	assert.False(t, params.SubmittedBy.Valid)
+}
+
+func TestListPageByFormID(t *testing.T) {
+	responses := make([]FormResponse, 3)
+	for i := range responses {
+		responses[i] = FormResponse{ID: uuid.New(), Progress: ResponseProgressDraft}
+	}
+
+	testCases := []struct {
+		name           string
+		limit          int
+		expectedLen    int
+		expectedCursor bool
+	}{
+		{name: "more responses than the limit", limit: 2, expectedLen: 2, expectedCursor: true},
+		{name: "last page", limit: 3, expectedLen: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := Service{
+				logger:    zap.NewNop(),
+				tracer:    otel.Tracer("response/filter_test"),
+				formStore: stubFormStore{},
+				queries: stubQuerier{
+					listPageFn: func(_ context.Context, arg ListPageByFormIDParams) ([]FormResponse, error) {
+						return responses[:min(int(arg.PageSize), len(responses))], nil
+					},
+					countFn: func(context.Context, CountByFormIDParams) (CountByFormIDRow, error) {
+						return CountByFormIDRow{TotalCount: 3, DraftCount: 3}, nil
+					},
+				},
+			}
+
+			page, err := svc.ListPageByFormID(context.Background(), uuid.New(), ListFilter{}, "", tc.limit)
+			require.NoError(t, err)
+			assert.Len(t, page.Responses, tc.expectedLen)
+			assert.Equal(t, int32(3), page.TotalCount)
+
+			if !tc.expectedCursor {
+				assert.Empty(t, page.NextCursor)
+				return
+			}
+			cursor, err := decodeCursor(page.NextCursor)
+			require.NoError(t, err)
+			assert.Equal(t, responses[tc.limit-1].ID, cursor.ID)
+		})
+	}
+}
@@ -5,14 +5,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form/answer"
 	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/view"
 
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -46,6 +49,18 @@ type ListResponse struct {
 	DraftCount     int32      `json:"draftCount" validate:"required"`
 	SubmittedCount int32      `json:"submittedCount" validate:"required"`
 	ResponseJSONs  []Response `json:"responses" validate:"required,dive"`
+	// NextCursor fetches the next page of a paginated list; it is omitted on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ResponseFilterRequest narrows the responses of a form. The list takes the fields as query parameters
+// and the exports as the "filter" object of the request body.
+type ResponseFilterRequest struct {
+	Progress      string `json:"progress"`      // DRAFT or SUBMITTED
+	SubmittedFrom string `json:"submittedFrom"` // RFC 3339, inclusive
+	SubmittedTo   string `json:"submittedTo"`   // RFC 3339, exclusive
+	Submitter     string `json:"submitter"`     // user ID
+	Query         string `json:"q"`             // full-text search over answers
 }
 
 type GetFormResponse struct {
@@ -84,8 +99,13 @@ type CreateResponse struct {
 	ID string `json:"id" validate:"required,uuid"`
 }
 
+type MigrateWorkflowResponse struct {
+	Migrated int64 `json:"migrated"`
+}
+
 type ExportPreviewRequest struct {
-	QuestionIDs []string `json:"questionIds" validate:"required,dive,uuid"`
+	QuestionIDs []string              `json:"questionIds" validate:"required,dive,uuid"`
+	Filter      ResponseFilterRequest `json:"filter"`
 }
 
 type ExportHeader struct {
@@ -104,45 +124,63 @@ type ExportPreviewResponse struct {
 }
 
 type ExportDownloadRequest struct {
-	QuestionIDs []string `json:"questionIds" validate:"required,dive,uuid"`
+	QuestionIDs []string              `json:"questionIds" validate:"required,dive,uuid"`
+	Filter      ResponseFilterRequest `json:"filter"`
+	// Format is XLSX (default), CSV or JSONL
+	Format string `json:"format"`
 }
 
 type Store interface {
 	Get(ctx context.Context, id uuid.UUID, formID uuid.UUID) (FormResponse, []SectionWithAnswerableAndAnswer, error)
-	ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error)
+	ListPageByFormID(ctx context.Context, formID uuid.UUID, filter ListFilter, cursor string, limit int) (ListPage, error)
 	ListByFormIDAndSubmittedBy(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]FormResponse, error)
 	Create(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormResponse, error)
 	Delete(ctx context.Context, responseID uuid.UUID) error
-	ExportPreview(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) (ExportPreviewResponse, error)
-	ExportDownload(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) ([]byte, string, error)
+	ExportPreview(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID, filter ListFilter) (ExportPreviewResponse, error)
+	PrepareExport(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID, filter ListFilter) (ExportPlan, error)
+	WriteExport(ctx context.Context, plan ExportPlan, format ExportFormat, w io.Writer) error
+	PrepareFileArchive(ctx context.Context, formID uuid.UUID, selection FileSelection) (FileArchive, error)
+	WriteFileArchive(ctx context.Context, archive FileArchive, w io.Writer) error
 	CancelSubmission(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	MigrateDraftsToActiveWorkflow(ctx context.Context, formID uuid.UUID) (int64, error)
 }
 
 type QuestionStore interface {
 	Get(ctx context.Context, id uuid.UUID) (question.Answerable, error)
 }
 
+type ViewStore interface {
+	Select(ctx context.Context, formID, viewID uuid.UUID) (view.Selection, error)
+}
+
 type Handler struct {
 	logger        *zap.Logger
 	validator     *validator.Validate
 	problemWriter *problem.HttpWriter
 	store         Store
 	questionStore QuestionStore
+	viewStore     ViewStore
 	tracer        trace.Tracer
 }
 
-func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, questionStore QuestionStore) *Handler {
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, questionStore QuestionStore, viewStore ViewStore) *Handler {
 	return &Handler{
 		logger:        logger,
 		validator:     validator,
 		problemWriter: problemWriter,
 		store:         store,
 		questionStore: questionStore,
+		viewStore:     viewStore,
 		tracer:        otel.Tracer("response/handler"),
 	}
 }
 
-// List lists all responses for a form (requires org member permission)
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// List lists one page of the responses for a form matching the filter query parameters (requires org member permission)
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "List")
 	defer span.End()
@@ -155,18 +193,90 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return all responses for the form
-	allResponses, err := h.store.ListByFormID(traceCtx, formID)
+	query := r.URL.Query()
+
+	limit := defaultListLimit
+	limitStr := query.Get("limit")
+	if limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > maxListLimit {
+			h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidLimit, logger)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	filter, err := ResponseFilterRequest{
+		Progress:      query.Get("progress"),
+		SubmittedFrom: query.Get("submittedFrom"),
+		SubmittedTo:   query.Get("submittedTo"),
+		Submitter:     query.Get("submitter"),
+		Query:         query.Get("q"),
+	}.ToListFilter()
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	listResponse := buildListResponse(formID, allResponses)
+	page, err := h.store.ListPageByFormID(traceCtx, formID, filter, query.Get("cursor"), limit)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	listResponse := buildListResponse(formID, page.Responses)
+	listResponse.TotalCount = page.TotalCount
+	listResponse.DraftCount = page.DraftCount
+	listResponse.SubmittedCount = page.SubmittedCount
+	listResponse.NextCursor = page.NextCursor
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, listResponse)
 }
 
+// ToListFilter parses the filter fields; empty fields do not filter
+func (req ResponseFilterRequest) ToListFilter() (ListFilter, error) {
+	var filter ListFilter
+
+	if req.Progress != "" {
+		progress := ResponseProgress(strings.ToLower(req.Progress))
+		if progress != ResponseProgressDraft && progress != ResponseProgressSubmitted {
+			return ListFilter{}, fmt.Errorf("%w: progress must be DRAFT or SUBMITTED, got %q", internal.ErrInvalidResponseFilter, req.Progress)
+		}
+		filter.Progress = &progress
+	}
+
+	bounds := []struct {
+		name  string
+		value string
+		dest  **time.Time
+	}{
+		{name: "submittedFrom", value: req.SubmittedFrom, dest: &filter.SubmittedFrom},
+		{name: "submittedTo", value: req.SubmittedTo, dest: &filter.SubmittedTo},
+	}
+	for _, bound := range bounds {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return ListFilter{}, fmt.Errorf("%w: %s must be an RFC 3339 time: %w", internal.ErrInvalidResponseFilter, bound.name, err)
+		}
+		*bound.dest = &parsed
+	}
+
+	if req.Submitter != "" {
+		submitter, err := uuid.Parse(req.Submitter)
+		if err != nil {
+			return ListFilter{}, fmt.Errorf("%w: submitter must be a user ID: %w", internal.ErrInvalidResponseFilter, err)
+		}
+		filter.SubmittedBy = &submitter
+	}
+
+	filter.Search = strings.TrimSpace(req.Query)
+
+	return filter, filter.validate()
+}
+
 func buildListResponse(formID uuid.UUID, responses []FormResponse) ListResponse {
 	listResponse := ListResponse{
 		FormID:        formID.String(),
@@ -459,6 +569,28 @@ func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// MigrateWorkflow moves the draft responses of a form onto its active workflow version
+func (h *Handler) MigrateWorkflow(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "MigrateWorkflow")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	migrated, err := h.store.MigrateDraftsToActiveWorkflow(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, MigrateWorkflowResponse{Migrated: migrated})
+}
+
 func (h *Handler) ExportPreview(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "ExportPreview")
 	defer span.End()
@@ -478,13 +610,19 @@ func (h *Handler) ExportPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	questionIDs, err := parseQuestionIDs(req.QuestionIDs)
+	questionIDs, err := ParseQuestionIDs(req.QuestionIDs)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	filter, err := req.Filter.ToListFilter()
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	response, err := h.store.ExportPreview(traceCtx, formID, questionIDs)
+	response, err := h.store.ExportPreview(traceCtx, formID, questionIDs, filter)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -512,29 +650,107 @@ func (h *Handler) ExportDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	questionIDs, err := parseQuestionIDs(req.QuestionIDs)
+	questionIDs, err := ParseQuestionIDs(req.QuestionIDs)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	fileBytes, filename, err := h.store.ExportDownload(traceCtx, formID, questionIDs)
+	filter, err := req.Filter.ToListFilter()
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s.xlsx", url.PathEscape(filename)))
+	format, err := ParseExportFormat(req.Format)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	plan, err := h.store.PrepareExport(traceCtx, formID, questionIDs, filter)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	// The export is streamed, so errors after this point can no longer be reported as a problem response;
+	// the client sees a truncated download instead
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s.%s", url.PathEscape(plan.FormTitle), format.Extension()))
 	w.WriteHeader(http.StatusOK)
-	_, err = w.Write(fileBytes)
+
+	err = h.store.WriteExport(traceCtx, plan, format, w)
 	if err != nil {
 		logger.Error("failed to write export download response", zap.Error(err))
 		span.RecordError(err)
 	}
 }
 
-func parseQuestionIDs(questionIDStrs []string) ([]uuid.UUID, error) {
+// DownloadFiles streams a ZIP of the files uploaded in the answers of a form, limited to one question with
+// the questionId query parameter or to the columns and responses of a view with viewId (requires org member permission)
+func (h *Handler) DownloadFiles(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DownloadFiles")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	query := r.URL.Query()
+	questionIDStr := query.Get("questionId")
+	viewIDStr := query.Get("viewId")
+
+	var selection FileSelection
+	switch {
+	case questionIDStr != "" && viewIDStr != "":
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: questionId and viewId cannot be combined", internal.ErrInvalidResponseFilter), logger)
+		return
+	case questionIDStr != "":
+		questionID, err := handlerutil.ParseUUID(questionIDStr)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		selection.QuestionIDs = []uuid.UUID{questionID}
+	case viewIDStr != "":
+		viewID, err := handlerutil.ParseUUID(viewIDStr)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		viewSelection, err := h.viewStore.Select(traceCtx, formID, viewID)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		selection.QuestionIDs = viewSelection.QuestionIDs
+		selection.ResponseIDs = viewSelection.ResponseIDs
+	}
+
+	archive, err := h.store.PrepareFileArchive(traceCtx, formID, selection)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	// The archive is streamed, so errors after this point can no longer be reported as a problem response;
+	// the client sees a truncated download instead
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(archive.FormTitle+" files.zip")))
+	w.WriteHeader(http.StatusOK)
+
+	err = h.store.WriteFileArchive(traceCtx, archive, w)
+	if err != nil {
+		logger.Error("failed to write answer file archive response", zap.Error(err))
+		span.RecordError(err)
+	}
+}
+
+func ParseQuestionIDs(questionIDStrs []string) ([]uuid.UUID, error) {
 	questionIDs := make([]uuid.UUID, 0, len(questionIDStrs))
 	for _, questionIDStr := range questionIDStrs {
 		questionID, err := handlerutil.ParseUUID(questionIDStr)
@@ -1,6 +1,18 @@
 -- name: Create :one
-INSERT INTO form_responses (form_id, submitted_by)
-VALUES ($1, $2)
+-- Pins the response to the active workflow version of the form, if there is one
+INSERT INTO form_responses (form_id, submitted_by, workflow_version_id)
+VALUES (
+    $1,
+    $2,
+    (
+        SELECT wv.id
+        FROM workflow_versions AS wv
+        WHERE wv.form_id = $1
+          AND wv.is_active = true
+        ORDER BY wv.seq DESC
+        LIMIT 1
+    )
+)
 RETURNING *;
 
 -- name: Get :one
@@ -53,11 +65,116 @@ SELECT EXISTS(SELECT 1 FROM form_responses WHERE id = $1);
 -- name: ExistsByFormIDAndSubmittedBy :one
 SELECT EXISTS(SELECT 1 FROM form_responses WHERE form_id = $1 AND submitted_by = $2);
 
--- name: ListSubmittedByFormID :many
-SELECT * FROM form_responses
-WHERE form_id = $1
-  AND progress = 'SUBMITTED'
-ORDER BY submitted_at ASC, id ASC;
+-- name: ListAnswerFilesByFormID :many
+-- Files attached to the upload_file answers of the responses of a form, optionally limited to some questions
+-- and responses, for the answer file archive. Files are grouped by response in submission order, then by
+-- question in form order.
+SELECT
+    fi.id AS file_id,
+    fi.original_filename,
+    fi.size,
+    fi.created_at,
+    fi.scan_status,
+    fr.id AS response_id,
+    fr.submitted_by,
+    fr.progress,
+    COALESCE(u.name, u.username, '')::text AS submitter_name,
+    a.question_id,
+    COALESCE(q.title, '')::text AS question_title
+FROM file_attachments AS fa
+JOIN answers AS a ON a.id = fa.resource_id
+JOIN form_responses AS fr ON fr.id = a.response_id
+JOIN questions AS q ON q.id = a.question_id
+JOIN sections AS s ON s.id = q.section_id
+JOIN users AS u ON u.id = fr.submitted_by
+JOIN files AS fi ON fi.id = fa.file_id
+WHERE fa.resource_type = 'form_answer'
+  AND fr.form_id = @form_id
+  AND (sqlc.narg('question_ids')::uuid[] IS NULL OR a.question_id = ANY(sqlc.narg('question_ids')::uuid[]))
+  AND (sqlc.narg('response_ids')::uuid[] IS NULL OR fr.id = ANY(sqlc.narg('response_ids')::uuid[]))
+ORDER BY fr.submitted_at ASC NULLS LAST, fr.id ASC, s.created_at ASC, q."order" ASC, fa.created_at ASC, fi.id ASC;
+
+-- name: ListExportRowsByFormID :many
+-- Responses of a form matching the list filters in submission order with their submitter and their answers
+-- to the given questions, as an object keyed by question ID, for exports. The submitter email is the earliest
+-- one linked to the account. Without a progress only submitted responses are exported.
+SELECT
+    fr.id,
+    fr.submitted_by,
+    fr.submitted_at,
+    fr.progress,
+    COALESCE(u.name, '')::text AS submitter_name,
+    COALESCE((
+        SELECT e.value FROM user_emails AS e
+        WHERE e.user_id = fr.submitted_by
+        ORDER BY e.created_at
+        LIMIT 1
+    ), '')::text AS submitter_email,
+    COALESCE((
+        SELECT jsonb_object_agg(
+            a.question_id::text,
+            jsonb_build_object('value', a.value, 'createdAt', a.created_at, 'updatedAt', a.updated_at)
+        )
+        FROM answers AS a
+        WHERE a.response_id = fr.id
+          AND a.question_id = ANY(@question_ids::uuid[])
+    ), '{}')::jsonb AS answers
+FROM form_responses AS fr
+JOIN users AS u ON u.id = fr.submitted_by
+WHERE fr.form_id = @form_id
+  AND fr.progress = COALESCE(sqlc.narg('progress')::response_progress, 'submitted')
+  AND (sqlc.narg('submitted_from')::timestamptz IS NULL OR fr.submitted_at >= sqlc.narg('submitted_from'))
+  AND (sqlc.narg('submitted_to')::timestamptz IS NULL OR fr.submitted_at < sqlc.narg('submitted_to'))
+  AND (sqlc.narg('submitted_by')::uuid IS NULL OR fr.submitted_by = sqlc.narg('submitted_by'))
+  AND (sqlc.narg('search')::text IS NULL OR EXISTS (
+      SELECT 1 FROM answers AS a
+      WHERE a.response_id = fr.id
+        AND answer_search_text(a.value) ILIKE '%' || sqlc.narg('search') || '%'
+  ))
+ORDER BY fr.submitted_at ASC NULLS LAST, fr.id ASC;
+
+-- name: ListPageByFormID :many
+-- One page of the responses of a form matching the list filters, newest submission first and drafts last.
+-- The cursor is the (submitted_at, id) of the last response of the previous page.
+SELECT fr.* FROM form_responses AS fr
+WHERE fr.form_id = @form_id
+  AND (sqlc.narg('progress')::response_progress IS NULL OR fr.progress = sqlc.narg('progress'))
+  AND (sqlc.narg('submitted_from')::timestamptz IS NULL OR fr.submitted_at >= sqlc.narg('submitted_from'))
+  AND (sqlc.narg('submitted_to')::timestamptz IS NULL OR fr.submitted_at < sqlc.narg('submitted_to'))
+  AND (sqlc.narg('submitted_by')::uuid IS NULL OR fr.submitted_by = sqlc.narg('submitted_by'))
+  AND (sqlc.narg('search')::text IS NULL OR EXISTS (
+      SELECT 1 FROM answers AS a
+      WHERE a.response_id = fr.id
+        AND answer_search_text(a.value) ILIKE '%' || sqlc.narg('search') || '%'
+  ))
+  AND (
+      sqlc.narg('cursor_id')::uuid IS NULL
+      OR (sqlc.narg('cursor_submitted_at')::timestamptz IS NULL AND fr.submitted_at IS NULL AND fr.id < sqlc.narg('cursor_id'))
+      OR (sqlc.narg('cursor_submitted_at') IS NOT NULL AND (
+          fr.submitted_at IS NULL
+          OR (fr.submitted_at, fr.id) < (sqlc.narg('cursor_submitted_at'), sqlc.narg('cursor_id'))
+      ))
+  )
+ORDER BY fr.submitted_at DESC NULLS LAST, fr.id DESC
+LIMIT @page_size;
+
+-- name: CountByFormID :one
+-- Counts the responses of a form matching the list filters
+SELECT
+    COUNT(*)::int AS total_count,
+    (COUNT(*) FILTER (WHERE fr.progress = 'draft'))::int AS draft_count,
+    (COUNT(*) FILTER (WHERE fr.progress = 'submitted'))::int AS submitted_count
+FROM form_responses AS fr
+WHERE fr.form_id = @form_id
+  AND (sqlc.narg('progress')::response_progress IS NULL OR fr.progress = sqlc.narg('progress'))
+  AND (sqlc.narg('submitted_from')::timestamptz IS NULL OR fr.submitted_at >= sqlc.narg('submitted_from'))
+  AND (sqlc.narg('submitted_to')::timestamptz IS NULL OR fr.submitted_at < sqlc.narg('submitted_to'))
+  AND (sqlc.narg('submitted_by')::uuid IS NULL OR fr.submitted_by = sqlc.narg('submitted_by'))
+  AND (sqlc.narg('search')::text IS NULL OR EXISTS (
+      SELECT 1 FROM answers AS a
+      WHERE a.response_id = fr.id
+        AND answer_search_text(a.value) ILIKE '%' || sqlc.narg('search') || '%'
+  ));
 
 -- name: GetEditInfo :one
 SELECT
@@ -65,4 +182,20 @@ SELECT
     f.allow_edit_response
 FROM form_responses r
          JOIN forms f ON f.id = r.form_id
-WHERE r.id = $1;
\ No newline at end of file
+WHERE r.id = $1;
+
+-- name: MigrateDraftsToActiveWorkflow :execrows
+-- Moves the draft responses of a form onto its active workflow version; submitted responses keep theirs
+UPDATE form_responses AS fr
+SET workflow_version_id = active.id
+FROM (
+    SELECT wv.id
+    FROM workflow_versions AS wv
+    WHERE wv.form_id = @form_id
+      AND wv.is_active = true
+    ORDER BY wv.seq DESC
+    LIMIT 1
+) AS active
+WHERE fr.form_id = @form_id
+  AND fr.progress = 'draft'
+  AND fr.workflow_version_id IS DISTINCT FROM active.id;
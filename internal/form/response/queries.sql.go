@@ -9,12 +9,72 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countByFormID = `-- name: CountByFormID :one
+SELECT
+    COUNT(*)::int AS total_count,
+    (COUNT(*) FILTER (WHERE fr.progress = 'draft'))::int AS draft_count,
+    (COUNT(*) FILTER (WHERE fr.progress = 'submitted'))::int AS submitted_count
+FROM form_responses AS fr
+WHERE fr.form_id = $1
+  AND ($2::response_progress IS NULL OR fr.progress = $2)
+  AND ($3::timestamptz IS NULL OR fr.submitted_at >= $3)
+  AND ($4::timestamptz IS NULL OR fr.submitted_at < $4)
+  AND ($5::uuid IS NULL OR fr.submitted_by = $5)
+  AND ($6::text IS NULL OR EXISTS (
+      SELECT 1 FROM answers AS a
+      WHERE a.response_id = fr.id
+        AND answer_search_text(a.value) ILIKE '%' || $6 || '%'
+  ))
+`
+
+type CountByFormIDParams struct {
+	FormID        uuid.UUID
+	Progress      NullResponseProgress
+	SubmittedFrom pgtype.Timestamptz
+	SubmittedTo   pgtype.Timestamptz
+	SubmittedBy   pgtype.UUID
+	Search        pgtype.Text
+}
+
+type CountByFormIDRow struct {
+	TotalCount     int32
+	DraftCount     int32
+	SubmittedCount int32
+}
+
+// Counts the responses of a form matching the list filters
+func (q *Queries) CountByFormID(ctx context.Context, arg CountByFormIDParams) (CountByFormIDRow, error) {
+	row := q.db.QueryRow(ctx, countByFormID,
+		arg.FormID,
+		arg.Progress,
+		arg.SubmittedFrom,
+		arg.SubmittedTo,
+		arg.SubmittedBy,
+		arg.Search,
+	)
+	var i CountByFormIDRow
+	err := row.Scan(&i.TotalCount, &i.DraftCount, &i.SubmittedCount)
+	return i, err
+}
+
 const create = `-- name: Create :one
-INSERT INTO form_responses (form_id, submitted_by)
-VALUES ($1, $2)
-RETURNING id, form_id, submitted_by, submitted_at, progress, created_at, updated_at
+INSERT INTO form_responses (form_id, submitted_by, workflow_version_id)
+VALUES (
+    $1,
+    $2,
+    (
+        SELECT wv.id
+        FROM workflow_versions AS wv
+        WHERE wv.form_id = $1
+          AND wv.is_active = true
+        ORDER BY wv.seq DESC
+        LIMIT 1
+    )
+)
+RETURNING id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at
 `
 
 type CreateParams struct {
@@ -22,6 +82,7 @@ type CreateParams struct {
 	SubmittedBy uuid.UUID
 }
 
+// Pins the response to the active workflow version of the form, if there is one
 func (q *Queries) Create(ctx context.Context, arg CreateParams) (FormResponse, error) {
 	row := q.db.QueryRow(ctx, create, arg.FormID, arg.SubmittedBy)
 	var i FormResponse
@@ -31,6 +92,7 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (FormResponse, e
 		&i.SubmittedBy,
 		&i.SubmittedAt,
 		&i.Progress,
+		&i.WorkflowVersionID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -75,7 +137,7 @@ func (q *Queries) ExistsByFormIDAndSubmittedBy(ctx context.Context, arg ExistsBy
 }
 
 const get = `-- name: Get :one
-SELECT id, form_id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at FROM form_responses
 WHERE id = $1 AND form_id = $2
 `
 
@@ -93,6 +155,7 @@ func (q *Queries) Get(ctx context.Context, arg GetParams) (FormResponse, error)
 		&i.SubmittedBy,
 		&i.SubmittedAt,
 		&i.Progress,
+		&i.WorkflowVersionID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -132,8 +195,90 @@ func (q *Queries) GetFormID(ctx context.Context, id uuid.UUID) (uuid.UUID, error
 	return form_id, err
 }
 
+const listAnswerFilesByFormID = `-- name: ListAnswerFilesByFormID :many
+SELECT
+    fi.id AS file_id,
+    fi.original_filename,
+    fi.size,
+    fi.created_at,
+    fi.scan_status,
+    fr.id AS response_id,
+    fr.submitted_by,
+    fr.progress,
+    COALESCE(u.name, u.username, '')::text AS submitter_name,
+    a.question_id,
+    COALESCE(q.title, '')::text AS question_title
+FROM file_attachments AS fa
+JOIN answers AS a ON a.id = fa.resource_id
+JOIN form_responses AS fr ON fr.id = a.response_id
+JOIN questions AS q ON q.id = a.question_id
+JOIN sections AS s ON s.id = q.section_id
+JOIN users AS u ON u.id = fr.submitted_by
+JOIN files AS fi ON fi.id = fa.file_id
+WHERE fa.resource_type = 'form_answer'
+  AND fr.form_id = $1
+  AND ($2::uuid[] IS NULL OR a.question_id = ANY($2::uuid[]))
+  AND ($3::uuid[] IS NULL OR fr.id = ANY($3::uuid[]))
+ORDER BY fr.submitted_at ASC NULLS LAST, fr.id ASC, s.created_at ASC, q."order" ASC, fa.created_at ASC, fi.id ASC
+`
+
+type ListAnswerFilesByFormIDParams struct {
+	FormID      uuid.UUID
+	QuestionIds []uuid.UUID
+	ResponseIds []uuid.UUID
+}
+
+type ListAnswerFilesByFormIDRow struct {
+	FileID           uuid.UUID
+	OriginalFilename string
+	Size             int64
+	CreatedAt        pgtype.Timestamptz
+	ScanStatus       FileScanStatus
+	ResponseID       uuid.UUID
+	SubmittedBy      uuid.UUID
+	Progress         ResponseProgress
+	SubmitterName    string
+	QuestionID       uuid.UUID
+	QuestionTitle    string
+}
+
+// Files attached to the upload_file answers of the responses of a form, optionally limited to some questions
+// and responses, for the answer file archive. Files are grouped by response in submission order, then by
+// question in form order.
+func (q *Queries) ListAnswerFilesByFormID(ctx context.Context, arg ListAnswerFilesByFormIDParams) ([]ListAnswerFilesByFormIDRow, error) {
+	rows, err := q.db.Query(ctx, listAnswerFilesByFormID, arg.FormID, arg.QuestionIds, arg.ResponseIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAnswerFilesByFormIDRow
+	for rows.Next() {
+		var i ListAnswerFilesByFormIDRow
+		if err := rows.Scan(
+			&i.FileID,
+			&i.OriginalFilename,
+			&i.Size,
+			&i.CreatedAt,
+			&i.ScanStatus,
+			&i.ResponseID,
+			&i.SubmittedBy,
+			&i.Progress,
+			&i.SubmitterName,
+			&i.QuestionID,
+			&i.QuestionTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listByFormID = `-- name: ListByFormID :many
-SELECT id, form_id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at FROM form_responses
 WHERE form_id = $1
 ORDER BY submitted_at DESC NULLS LAST
 `
@@ -153,6 +298,7 @@ func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormRes
 			&i.SubmittedBy,
 			&i.SubmittedAt,
 			&i.Progress,
+			&i.WorkflowVersionID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -167,7 +313,7 @@ func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormRes
 }
 
 const listByFormIDAndSubmittedBy = `-- name: ListByFormIDAndSubmittedBy :many
-SELECT id, form_id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at FROM form_responses
 WHERE form_id = $1 AND submitted_by = $2
 ORDER BY submitted_at DESC NULLS LAST
 `
@@ -192,6 +338,7 @@ func (q *Queries) ListByFormIDAndSubmittedBy(ctx context.Context, arg ListByForm
 			&i.SubmittedBy,
 			&i.SubmittedAt,
 			&i.Progress,
+			&i.WorkflowVersionID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -206,7 +353,7 @@ func (q *Queries) ListByFormIDAndSubmittedBy(ctx context.Context, arg ListByForm
 }
 
 const listBySubmittedBy = `-- name: ListBySubmittedBy :many
-SELECT id, form_id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at FROM form_responses
 WHERE submitted_by = $1
 ORDER BY submitted_at DESC NULLS LAST
 `
@@ -226,6 +373,7 @@ func (q *Queries) ListBySubmittedBy(ctx context.Context, submittedBy uuid.UUID)
 			&i.SubmittedBy,
 			&i.SubmittedAt,
 			&i.Progress,
+			&i.WorkflowVersionID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -239,15 +387,152 @@ func (q *Queries) ListBySubmittedBy(ctx context.Context, submittedBy uuid.UUID)
 	return items, nil
 }
 
-const listSubmittedByFormID = `-- name: ListSubmittedByFormID :many
-SELECT id, form_id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
-WHERE form_id = $1
-  AND progress = 'SUBMITTED'
-ORDER BY submitted_at ASC, id ASC
+const listExportRowsByFormID = `-- name: ListExportRowsByFormID :many
+SELECT
+    fr.id,
+    fr.submitted_by,
+    fr.submitted_at,
+    fr.progress,
+    COALESCE(u.name, '')::text AS submitter_name,
+    COALESCE((
+        SELECT e.value FROM user_emails AS e
+        WHERE e.user_id = fr.submitted_by
+        ORDER BY e.created_at
+        LIMIT 1
+    ), '')::text AS submitter_email,
+    COALESCE((
+        SELECT jsonb_object_agg(
+            a.question_id::text,
+            jsonb_build_object('value', a.value, 'createdAt', a.created_at, 'updatedAt', a.updated_at)
+        )
+        FROM answers AS a
+        WHERE a.response_id = fr.id
+          AND a.question_id = ANY($1::uuid[])
+    ), '{}')::jsonb AS answers
+FROM form_responses AS fr
+JOIN users AS u ON u.id = fr.submitted_by
+WHERE fr.form_id = $2
+  AND fr.progress = COALESCE($3::response_progress, 'submitted')
+  AND ($4::timestamptz IS NULL OR fr.submitted_at >= $4)
+  AND ($5::timestamptz IS NULL OR fr.submitted_at < $5)
+  AND ($6::uuid IS NULL OR fr.submitted_by = $6)
+  AND ($7::text IS NULL OR EXISTS (
+      SELECT 1 FROM answers AS a
+      WHERE a.response_id = fr.id
+        AND answer_search_text(a.value) ILIKE '%' || $7 || '%'
+  ))
+ORDER BY fr.submitted_at ASC NULLS LAST, fr.id ASC
 `
 
-func (q *Queries) ListSubmittedByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error) {
-	rows, err := q.db.Query(ctx, listSubmittedByFormID, formID)
+type ListExportRowsByFormIDParams struct {
+	QuestionIds   []uuid.UUID
+	FormID        uuid.UUID
+	Progress      NullResponseProgress
+	SubmittedFrom pgtype.Timestamptz
+	SubmittedTo   pgtype.Timestamptz
+	SubmittedBy   pgtype.UUID
+	Search        pgtype.Text
+}
+
+type ListExportRowsByFormIDRow struct {
+	ID             uuid.UUID
+	SubmittedBy    uuid.UUID
+	SubmittedAt    pgtype.Timestamptz
+	Progress       ResponseProgress
+	SubmitterName  string
+	SubmitterEmail string
+	Answers        []byte
+}
+
+// Responses of a form matching the list filters in submission order with their submitter and their answers
+// to the given questions, as an object keyed by question ID, for exports. The submitter email is the earliest
+// one linked to the account. Without a progress only submitted responses are exported.
+func (q *Queries) ListExportRowsByFormID(ctx context.Context, arg ListExportRowsByFormIDParams) ([]ListExportRowsByFormIDRow, error) {
+	rows, err := q.db.Query(ctx, listExportRowsByFormID,
+		arg.QuestionIds,
+		arg.FormID,
+		arg.Progress,
+		arg.SubmittedFrom,
+		arg.SubmittedTo,
+		arg.SubmittedBy,
+		arg.Search,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListExportRowsByFormIDRow
+	for rows.Next() {
+		var i ListExportRowsByFormIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubmittedBy,
+			&i.SubmittedAt,
+			&i.Progress,
+			&i.SubmitterName,
+			&i.SubmitterEmail,
+			&i.Answers,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPageByFormID = `-- name: ListPageByFormID :many
+SELECT fr.id, fr.form_id, fr.submitted_by, fr.submitted_at, fr.progress, fr.workflow_version_id, fr.created_at, fr.updated_at FROM form_responses AS fr
+WHERE fr.form_id = $1
+  AND ($2::response_progress IS NULL OR fr.progress = $2)
+  AND ($3::timestamptz IS NULL OR fr.submitted_at >= $3)
+  AND ($4::timestamptz IS NULL OR fr.submitted_at < $4)
+  AND ($5::uuid IS NULL OR fr.submitted_by = $5)
+  AND ($6::text IS NULL OR EXISTS (
+      SELECT 1 FROM answers AS a
+      WHERE a.response_id = fr.id
+        AND answer_search_text(a.value) ILIKE '%' || $6 || '%'
+  ))
+  AND (
+      $7::uuid IS NULL
+      OR ($8::timestamptz IS NULL AND fr.submitted_at IS NULL AND fr.id < $7)
+      OR ($8 IS NOT NULL AND (
+          fr.submitted_at IS NULL
+          OR (fr.submitted_at, fr.id) < ($8, $7)
+      ))
+  )
+ORDER BY fr.submitted_at DESC NULLS LAST, fr.id DESC
+LIMIT $9
+`
+
+type ListPageByFormIDParams struct {
+	FormID            uuid.UUID
+	Progress          NullResponseProgress
+	SubmittedFrom     pgtype.Timestamptz
+	SubmittedTo       pgtype.Timestamptz
+	SubmittedBy       pgtype.UUID
+	Search            pgtype.Text
+	CursorID          pgtype.UUID
+	CursorSubmittedAt pgtype.Timestamptz
+	PageSize          int32
+}
+
+// One page of the responses of a form matching the list filters, newest submission first and drafts last.
+// The cursor is the (submitted_at, id) of the last response of the previous page.
+func (q *Queries) ListPageByFormID(ctx context.Context, arg ListPageByFormIDParams) ([]FormResponse, error) {
+	rows, err := q.db.Query(ctx, listPageByFormID,
+		arg.FormID,
+		arg.Progress,
+		arg.SubmittedFrom,
+		arg.SubmittedTo,
+		arg.SubmittedBy,
+		arg.Search,
+		arg.CursorID,
+		arg.CursorSubmittedAt,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -261,6 +546,7 @@ func (q *Queries) ListSubmittedByFormID(ctx context.Context, formID uuid.UUID) (
 			&i.SubmittedBy,
 			&i.SubmittedAt,
 			&i.Progress,
+			&i.WorkflowVersionID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -274,11 +560,36 @@ func (q *Queries) ListSubmittedByFormID(ctx context.Context, formID uuid.UUID) (
 	return items, nil
 }
 
+const migrateDraftsToActiveWorkflow = `-- name: MigrateDraftsToActiveWorkflow :execrows
+UPDATE form_responses AS fr
+SET workflow_version_id = active.id
+FROM (
+    SELECT wv.id
+    FROM workflow_versions AS wv
+    WHERE wv.form_id = $1
+      AND wv.is_active = true
+    ORDER BY wv.seq DESC
+    LIMIT 1
+) AS active
+WHERE fr.form_id = $1
+  AND fr.progress = 'draft'
+  AND fr.workflow_version_id IS DISTINCT FROM active.id
+`
+
+// Moves the draft responses of a form onto its active workflow version; submitted responses keep theirs
+func (q *Queries) MigrateDraftsToActiveWorkflow(ctx context.Context, formID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, migrateDraftsToActiveWorkflow, formID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const revertSubmission = `-- name: RevertSubmission :one
 UPDATE form_responses
 SET submitted_at = NULL, progress = 'draft', updated_at = now()
 WHERE id = $1
-RETURNING id, form_id, submitted_by, submitted_at, progress, created_at, updated_at
+RETURNING id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at
 `
 
 func (q *Queries) RevertSubmission(ctx context.Context, id uuid.UUID) (FormResponse, error) {
@@ -290,6 +601,7 @@ func (q *Queries) RevertSubmission(ctx context.Context, id uuid.UUID) (FormRespo
 		&i.SubmittedBy,
 		&i.SubmittedAt,
 		&i.Progress,
+		&i.WorkflowVersionID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -316,7 +628,7 @@ const updateSubmitted = `-- name: UpdateSubmitted :one
 UPDATE form_responses
 SET submitted_at = now(), progress = 'submitted'
 WHERE id = $1
-RETURNING id, form_id, submitted_by, submitted_at, progress, created_at, updated_at
+RETURNING id, form_id, submitted_by, submitted_at, progress, workflow_version_id, created_at, updated_at
 `
 
 func (q *Queries) UpdateSubmitted(ctx context.Context, id uuid.UUID) (FormResponse, error) {
@@ -328,6 +640,7 @@ func (q *Queries) UpdateSubmitted(ctx context.Context, id uuid.UUID) (FormRespon
 		&i.SubmittedBy,
 		&i.SubmittedAt,
 		&i.Progress,
+		&i.WorkflowVersionID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -9,6 +9,11 @@ CREATE TABLE IF NOT EXISTS form_responses (
     submitted_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     submitted_at TIMESTAMPTZ DEFAULT NULL,
     progress response_progress NOT NULL DEFAULT 'draft',
+    -- Workflow version the response was started under; NULL follows the latest version
+    workflow_version_id UUID REFERENCES workflow_versions(id) ON DELETE SET NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
+
+CREATE INDEX IF NOT EXISTS idx_form_responses_workflow_version_id ON form_responses(workflow_version_id);
+CREATE INDEX IF NOT EXISTS idx_form_responses_form_id_submitted_at ON form_responses(form_id, submitted_at DESC NULLS LAST, id DESC);
@@ -1,15 +1,17 @@
 package response
 
 import (
+	"NYCU-SDC/core-system-backend/internal/file"
 	"NYCU-SDC/core-system-backend/internal/form"
 	"context"
 	"fmt"
+	"io"
 	"sort"
-	"time"
 
 	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form/answer"
 	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/outbox"
 	"NYCU-SDC/core-system-backend/internal/user"
 	"errors"
 
@@ -18,7 +20,7 @@ import (
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/xuri/excelize/v2"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -36,17 +38,21 @@ type Querier interface {
 	ListBySubmittedBy(ctx context.Context, userID uuid.UUID) ([]FormResponse, error)
 	UpdateSubmitted(ctx context.Context, id uuid.UUID) (FormResponse, error)
 	RevertSubmission(ctx context.Context, id uuid.UUID) (FormResponse, error)
-	ListSubmittedByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error)
+	IterateExportRowsByFormID(ctx context.Context, arg ListExportRowsByFormIDParams, fn func(ListExportRowsByFormIDRow) error) error
+	ListPageByFormID(ctx context.Context, arg ListPageByFormIDParams) ([]FormResponse, error)
+	CountByFormID(ctx context.Context, arg CountByFormIDParams) (CountByFormIDRow, error)
+	ListAnswerFilesByFormID(ctx context.Context, arg ListAnswerFilesByFormIDParams) ([]ListAnswerFilesByFormIDRow, error)
 	GetEditInfo(ctx context.Context, id uuid.UUID) (GetEditInfoRow, error)
+	MigrateDraftsToActiveWorkflow(ctx context.Context, formID uuid.UUID) (int64, error)
 }
 
 type WorkflowResolver interface {
 	ResolveSections(ctx context.Context, formID uuid.UUID, answers []answer.Answer, answerableMap map[string]question.Answerable) ([]uuid.UUID, error)
+	ResolveSectionsForResponse(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, answers []answer.Answer, answerableMap map[string]question.Answerable) ([]uuid.UUID, error)
 }
 
 type AnswerStore interface {
 	List(ctx context.Context, formID, responseID uuid.UUID) ([]answer.Answer, []question.Answerable, map[string]question.Answerable, error)
-	ListForExport(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) ([]answer.ListForExportRow, error)
 }
 
 type UserStore interface {
@@ -67,10 +73,50 @@ type SectionWithAnswerableAndAnswer struct {
 	Answer     []answer.Answer
 }
 
-type exportData struct {
+// ExportPlan is an export of the responses of a form prepared by PrepareExport. The responses are read
+// when the export is written, so that they are streamed rather than held in memory.
+type ExportPlan struct {
+	FormID    uuid.UUID
 	FormTitle string
 	Headers   []ExportHeader
-	Rows      []ExportRow
+	// OnProgress, if set, is called by WriteExport with the number of responses written so far, every
+	// exportProgressInterval responses and once more when every response has been written
+	OnProgress func(written int)
+
+	questionIDs []uuid.UUID
+	filter      ListFilter
+	// sections groups Headers by section in export order
+	sections []exportSection
+	// answerables maps the question IDs of Headers to their question, to display and decode answers
+	answerables map[string]question.Answerable
+}
+
+type exportResponse struct {
+	SubmittedBy    uuid.UUID
+	SubmitterName  string
+	SubmitterEmail string
+	SubmittedAt    pgtype.Timestamptz
+	Progress       ResponseProgress
+}
+
+type exportSection struct {
+	Title   string
+	Headers []ExportHeader
+}
+
+// ListPage is one page of the responses of a form matching a ListFilter, with the counts of every
+// matching response; NextCursor is empty on the last page
+type ListPage struct {
+	Responses      []FormResponse
+	NextCursor     string
+	TotalCount     int32
+	DraftCount     int32
+	SubmittedCount int32
+}
+
+type FileStore interface {
+	Get(ctx context.Context, id uuid.UUID) (file.File, error)
+	Open(ctx context.Context, storedFile file.File) (io.ReadSeekCloser, error)
 }
 
 type FormStore interface {
@@ -78,8 +124,14 @@ type FormStore interface {
 	Get(ctx context.Context, id uuid.UUID) (form.GetRow, error)
 }
 
+type EventPublisher interface {
+	PublishResponseEvent(ctx context.Context, eventType string, payload outbox.ResponsePayload) error
+	WithTx(tx pgx.Tx) *outbox.Service
+}
+
 type Service struct {
 	logger  *zap.Logger
+	db      DBTX
 	queries Querier
 	tracer  trace.Tracer
 
@@ -88,11 +140,14 @@ type Service struct {
 	workflowResolver         WorkflowResolver
 	formStore                FormStore
 	userStore                UserStore
+	fileStore                FileStore
+	events                   EventPublisher
 }
 
-func NewService(logger *zap.Logger, db DBTX, answerStore AnswerStore, sectionStore SectionWithQuestionStore, workflowResolver WorkflowResolver, formStore FormStore, userStore UserStore) *Service {
+func NewService(logger *zap.Logger, db DBTX, answerStore AnswerStore, sectionStore SectionWithQuestionStore, workflowResolver WorkflowResolver, formStore FormStore, userStore UserStore, fileStore FileStore, events EventPublisher) *Service {
 	return &Service{
 		logger:  logger,
+		db:      db,
 		queries: New(db),
 		tracer:  otel.Tracer("response/service"),
 
@@ -101,9 +156,36 @@ func NewService(logger *zap.Logger, db DBTX, answerStore AnswerStore, sectionSto
 		workflowResolver:         workflowResolver,
 		formStore:                formStore,
 		userStore:                userStore,
+		fileStore:                fileStore,
+		events:                   events,
 	}
 }
 
+// WithTx returns a copy of the service whose responses and outbox events are written in tx
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:  s.logger,
+		db:      tx,
+		queries: New(tx),
+		tracer:  s.tracer,
+
+		answerStore:              s.answerStore,
+		sectionWithQuestionStore: s.sectionWithQuestionStore,
+		workflowResolver:         s.workflowResolver,
+		formStore:                s.formStore,
+		userStore:                s.userStore,
+		fileStore:                s.fileStore,
+		events:                   s.events.WithTx(tx),
+	}
+}
+
+// withTransaction runs fn with a copy of the service bound to a single pgx transaction
+func (s *Service) withTransaction(ctx context.Context, fn func(txService *Service) error) error {
+	return internal.WithTransaction(ctx, s.db, s.logger, func(tx pgx.Tx) error {
+		return fn(s.WithTx(tx))
+	})
+}
+
 // Create creates an empty response (draft) for a given form and user.
 // Returns an error if the user already has a response for the form.
 func (s *Service) Create(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormResponse, error) {
@@ -177,6 +259,67 @@ func (s *Service) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormRes
 	return responses, nil
 }
 
+// ListPageByFormID retrieves one page of the responses of a form matching the filter, newest submission
+// first and drafts last. An empty cursor starts from the first page.
+func (s *Service) ListPageByFormID(ctx context.Context, formID uuid.UUID, filter ListFilter, cursor string, limit int) (ListPage, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListPageByFormID")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := filter.validate()
+	if err != nil {
+		span.RecordError(err)
+		return ListPage{}, err
+	}
+
+	var after *listCursor
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil {
+			span.RecordError(err)
+			return ListPage{}, err
+		}
+		after = &decoded
+	}
+
+	exists, err := s.formStore.Exists(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "check form exists")
+		span.RecordError(err)
+		return ListPage{}, err
+	}
+	if !exists {
+		return ListPage{}, internal.ErrFormNotFound
+	}
+
+	responses, err := s.queries.ListPageByFormID(traceCtx, pageParams(formID, filter, after, limit))
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "list response page by form id")
+		span.RecordError(err)
+		return ListPage{}, err
+	}
+
+	counts, err := s.queries.CountByFormID(traceCtx, filter.countParams(formID))
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "count responses by form id")
+		span.RecordError(err)
+		return ListPage{}, err
+	}
+
+	page := ListPage{
+		Responses:      responses,
+		TotalCount:     counts.TotalCount,
+		DraftCount:     counts.DraftCount,
+		SubmittedCount: counts.SubmittedCount,
+	}
+	if len(responses) > limit {
+		page.Responses = responses[:limit]
+		page.NextCursor = encodeCursor(page.Responses[limit-1])
+	}
+
+	return page, nil
+}
+
 // ListByFormIDAndSubmittedBy retrieves all responses submitted by a given user
 func (s *Service) ListByFormIDAndSubmittedBy(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]FormResponse, error) {
 	traceCtx, span := s.tracer.Start(ctx, "ListByFormIDAndSubmittedBy")
@@ -239,143 +382,87 @@ func (s *Service) ListBySubmittedBy(ctx context.Context, userID uuid.UUID) ([]Fo
 	return responses, nil
 }
 
-func (s *Service) ExportPreview(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) (ExportPreviewResponse, error) {
+// ExportPreview returns the export rows of the responses matching the filter; see PrepareExport
+func (s *Service) ExportPreview(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID, filter ListFilter) (ExportPreviewResponse, error) {
 	traceCtx, span := s.tracer.Start(ctx, "ExportPreview")
 	defer span.End()
-	logger := logutil.WithContext(traceCtx, s.logger)
 
-	data, err := s.getExportData(ctx, formID, questionIDs)
+	plan, err := s.PrepareExport(traceCtx, formID, questionIDs, filter)
+	if err != nil {
+		span.RecordError(err)
+		return ExportPreviewResponse{}, err
+	}
+
+	rows := make([]ExportRow, 0)
+	err = s.forEachExportRow(traceCtx, plan, func(row ExportRow, _ exportResponse) error {
+		rows = append(rows, row)
+		return nil
+	})
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "get export data for preview")
 		span.RecordError(err)
 		return ExportPreviewResponse{}, err
 	}
 
 	return ExportPreviewResponse{
-		Headers: data.Headers,
-		Rows:    data.Rows,
+		Headers: plan.Headers,
+		Rows:    rows,
 	}, nil
 }
 
-func (s *Service) ExportDownload(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) ([]byte, string, error) {
-	traceCtx, span := s.tracer.Start(ctx, "ExportDownload")
+// PrepareExport checks the form, questions and filter of an export and orders the question columns by
+// section. Only submitted responses are exported unless the filter selects a progress.
+func (s *Service) PrepareExport(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID, filter ListFilter) (ExportPlan, error) {
+	traceCtx, span := s.tracer.Start(ctx, "PrepareExport")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	data, err := s.getExportData(ctx, formID, questionIDs)
+	err := filter.validate()
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "get export data for download")
 		span.RecordError(err)
-		return nil, "", err
+		return ExportPlan{}, err
 	}
-
-	file := excelize.NewFile()
-	defer func() {
-		_ = file.Close()
-	}()
-
-	const sheet = "Sheet1"
-	err = file.SetCellValue(sheet, "A1", "Response ID")
-	if err != nil {
-		span.RecordError(err)
-		return nil, "", err
+	if filter.Progress == nil {
+		submitted := ResponseProgressSubmitted
+		filter.Progress = &submitted
 	}
 
-	for i, header := range data.Headers {
-		cell, err := excelize.CoordinatesToCellName(i+2, 1)
-		if err != nil {
-			span.RecordError(err)
-			return nil, "", err
-		}
-		err = file.SetCellValue(sheet, cell, escapeForExcel(header.Title))
-		if err != nil {
-			span.RecordError(err)
-			return nil, "", err
-		}
-	}
-
-	for rowIndex, row := range data.Rows {
-		excelRow := rowIndex + 2
-		cell, err := excelize.CoordinatesToCellName(1, excelRow)
-		if err != nil {
-			span.RecordError(err)
-			return nil, "", err
-		}
-		err = file.SetCellValue(sheet, cell, row.ID)
-		if err != nil {
-			span.RecordError(err)
-			return nil, "", err
-		}
-
-		for columnIndex, header := range data.Headers {
-			payload, ok := row.Answers[header.ID]
-			if !ok || payload == nil {
-				continue
-			}
-			cell, err := excelize.CoordinatesToCellName(columnIndex+2, excelRow)
-			if err != nil {
-				span.RecordError(err)
-				return nil, "", err
-			}
-			err = file.SetCellValue(sheet, cell, escapeForExcel(payload.DisplayValue))
-			if err != nil {
-				span.RecordError(err)
-				return nil, "", err
-			}
-		}
-	}
-
-	buffer, err := file.WriteToBuffer()
-	if err != nil {
-		span.RecordError(err)
-		return nil, "", err
-	}
-
-	return buffer.Bytes(), data.FormTitle, nil
-}
-
-func (s *Service) getExportData(ctx context.Context, formID uuid.UUID, questionIDs []uuid.UUID) (exportData, error) {
-	traceCtx, span := s.tracer.Start(ctx, "getExportData")
-	defer span.End()
-	logger := logutil.WithContext(traceCtx, s.logger)
-
 	formRow, err := s.formStore.Get(traceCtx, formID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return exportData{}, internal.ErrFormNotFound
+			return ExportPlan{}, internal.ErrFormNotFound
 		}
 		err = databaseutil.WrapDBErrorWithKeyValue(err, "form", "id", formID.String(), logger, "get form by id")
 		span.RecordError(err)
-		return exportData{}, err
+		return ExportPlan{}, err
 	}
 
 	questionRows, err := s.sectionWithQuestionStore.ListByIDs(traceCtx, questionIDs)
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "list questions by ids")
 		span.RecordError(err)
-		return exportData{}, err
+		return ExportPlan{}, err
 	}
 	if len(questionRows) != len(questionIDs) {
-		return exportData{}, internal.ErrQuestionNotFound
+		return ExportPlan{}, internal.ErrQuestionNotFound
 	}
 
 	answerableMap, err := s.sectionWithQuestionStore.GetAnswerableMapByFormID(traceCtx, formID)
 	if err != nil {
 		err = databaseutil.WrapDBErrorWithKeyValue(err, "question", "form_id", formID.String(), logger, "get answerable map by form id")
 		span.RecordError(err)
-		return exportData{}, err
+		return ExportPlan{}, err
 	}
 
 	for _, questionID := range questionIDs {
 		answerable, ok := answerableMap[questionID.String()]
 		if !ok || answerable.FormID() != formID {
-			return exportData{}, internal.ErrQuestionNotFound
+			return ExportPlan{}, internal.ErrQuestionNotFound
 		}
 	}
 
 	sectionIDs, _, err := s.ResolveWorkflowSectionsForResponse(traceCtx, formID, nil, answerableMap, uuid.Nil)
 	if err != nil && !errors.Is(err, internal.ErrWorkflowNotFound) {
-		return exportData{}, err
+		return ExportPlan{}, err
 	}
 	sectionOrder := make(map[string]int, len(sectionIDs))
 	for i, sectionID := range sectionIDs {
@@ -397,89 +484,71 @@ func (s *Service) getExportData(ctx context.Context, formID uuid.UUID, questionI
 		return questionRows[i].Order < questionRows[j].Order
 	})
 
+	sections, err := s.sectionWithQuestionStore.ListSections(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "section", "form_id", formID.String(), logger, "list sections by form id")
+		span.RecordError(err)
+		return ExportPlan{}, err
+	}
+
 	headers := make([]ExportHeader, 0, len(questionRows))
-	for _, questionRow := range questionRows {
+	exportSections := make([]exportSection, 0)
+	for i, questionRow := range questionRows {
 		title := ""
 		if questionRow.Title.Valid {
 			title = questionRow.Title.String
 		}
-		headers = append(headers, ExportHeader{
+		header := ExportHeader{
 			ID:    questionRow.ID.String(),
 			Title: title,
-		})
-	}
+		}
+		headers = append(headers, header)
 
-	submittedResponses, err := s.queries.ListSubmittedByFormID(traceCtx, formID)
-	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "list submitted responses by form id")
-		span.RecordError(err)
-		return exportData{}, err
+		// Questions are sorted by section, so a new section starts whenever the section ID changes
+		if i == 0 || questionRows[i-1].SectionID != questionRow.SectionID {
+			exportSections = append(exportSections, exportSection{
+				Title: sections[questionRow.SectionID.String()].Title.String,
+			})
+		}
+		last := &exportSections[len(exportSections)-1]
+		last.Headers = append(last.Headers, header)
 	}
 
-	type responseRow struct {
-		id          uuid.UUID
-		submittedAt time.Time
-		answers     map[string]*AnswerPayload
+	answerables := make(map[string]question.Answerable, len(questionIDs))
+	for _, questionID := range questionIDs {
+		answerables[questionID.String()] = answerableMap[questionID.String()]
 	}
 
-	rowMap := make(map[string]*responseRow, len(submittedResponses))
-	rows := make([]*responseRow, 0, len(submittedResponses))
-	for _, submittedResponse := range submittedResponses {
-		row := &responseRow{
-			id:          submittedResponse.ID,
-			submittedAt: submittedResponse.SubmittedAt.Time,
-			answers:     make(map[string]*AnswerPayload, len(submittedResponses)),
-		}
-		for _, questionID := range questionIDs {
-			row.answers[questionID.String()] = nil
-		}
-		rowMap[row.id.String()] = row
-		rows = append(rows, row)
-	}
+	return ExportPlan{
+		FormID:      formID,
+		FormTitle:   formRow.Title,
+		Headers:     headers,
+		questionIDs: questionIDs,
+		filter:      filter,
+		sections:    exportSections,
+		answerables: answerables,
+	}, nil
+}
+
+// PrepareFullExport prepares an export of every question of a form; see PrepareExport
+func (s *Service) PrepareFullExport(ctx context.Context, formID uuid.UUID, filter ListFilter) (ExportPlan, error) {
+	traceCtx, span := s.tracer.Start(ctx, "PrepareFullExport")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
 
-	answerRows, err := s.answerStore.ListForExport(traceCtx, formID, questionIDs)
+	answerableMap, err := s.sectionWithQuestionStore.GetAnswerableMapByFormID(traceCtx, formID)
 	if err != nil {
-		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "form_id", formID.String(), logger, "list answers for export")
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "question", "form_id", formID.String(), logger, "get answerable map by form id")
 		span.RecordError(err)
-		return exportData{}, err
+		return ExportPlan{}, err
 	}
 
-	for _, answerRow := range answerRows {
-		row, ok := rowMap[answerRow.ResponseID.String()]
-		if !ok {
-			continue
-		}
-		answerable, ok := answerableMap[answerRow.QuestionID.String()]
-		if !ok {
-			return exportData{}, internal.ErrQuestionNotFound
-		}
-		displayValue, err := answerable.DisplayValue(answerRow.Value)
-		if err != nil {
-			span.RecordError(err)
-			return exportData{}, err
-		}
-		row.answers[answerRow.QuestionID.String()] = &AnswerPayload{
-			CreatedAt:    answerRow.CreatedAt.Time,
-			UpdatedAt:    answerRow.UpdatedAt.Time,
-			ResponseID:   answerRow.ResponseID.String(),
-			Answer:       answerRow.Value,
-			DisplayValue: displayValue,
-		}
+	questionIDs := make([]uuid.UUID, 0, len(answerableMap))
+	for _, answerable := range answerableMap {
+		questionIDs = append(questionIDs, answerable.Question().ID)
 	}
 
-	exportRows := make([]ExportRow, 0, len(rows))
-	for _, row := range rows {
-		exportRows = append(exportRows, ExportRow{
-			ID:      row.id.String(),
-			Answers: row.answers,
-		})
-	}
-
-	return exportData{
-		FormTitle: formRow.Title,
-		Headers:   headers,
-		Rows:      exportRows,
-	}, nil
+	return s.PrepareExport(traceCtx, formID, questionIDs, filter)
 }
 
 // Get retrieves a form response by ID along with its sections, questions, and answers
@@ -731,15 +800,36 @@ func (s *Service) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
 	return exists, nil
 }
 
-// Delete deletes a response by id
+// Delete deletes a response by id and records its response.deleted event in the outbox in the same
+// transaction
 func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
 	traceCtx, span := s.tracer.Start(ctx, "Delete")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	err := s.queries.Delete(traceCtx, id)
+	err := s.withTransaction(traceCtx, func(txService *Service) error {
+		formResponse, err := txService.GetByID(traceCtx, id)
+		if err != nil {
+			if errors.Is(err, internal.ErrResponseNotFound) {
+				// Nothing to delete, nor to record
+				return nil
+			}
+			return err
+		}
+
+		err = txService.events.PublishResponseEvent(traceCtx, outbox.TypeResponseDeleted,
+			outbox.NewResponsePayload(formResponse.ID, formResponse.FormID, formResponse.SubmittedBy, formResponse.SubmittedAt))
+		if err != nil {
+			return err
+		}
+
+		err = txService.queries.Delete(traceCtx, id)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", id.String(), logger, "delete response")
+		}
+		return nil
+	})
 	if err != nil {
-		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", id.String(), logger, "delete response")
 		span.RecordError(err)
 		return err
 	}
@@ -762,33 +852,74 @@ func (s *Service) UpdateSubmitted(ctx context.Context, id uuid.UUID) (FormRespon
 	return formResponse, nil
 }
 
-func (s Service) CancelSubmission(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+// CancelSubmission turns a submitted response of a user back into a draft and records its
+// response.cancelled event in the outbox in the same transaction
+func (s *Service) CancelSubmission(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
 	traceCtx, span := s.tracer.Start(ctx, "CancelSubmission")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	formResponse, err := s.GetByID(traceCtx, id)
+	err := s.withTransaction(traceCtx, func(txService *Service) error {
+		formResponse, err := txService.GetByID(traceCtx, id)
+		if err != nil {
+			return err
+		}
+		if formResponse.SubmittedBy != userID {
+			return internal.ErrResponseNotOwned
+		}
+		if formResponse.Progress != ResponseProgressSubmitted {
+			return internal.ErrResponseNotSubmitted
+		}
+
+		reverted, err := txService.queries.RevertSubmission(traceCtx, id)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", id.String(), logger, "revert response submission")
+		}
+
+		return txService.events.PublishResponseEvent(traceCtx, outbox.TypeResponseCancelled,
+			outbox.NewResponsePayload(reverted.ID, reverted.FormID, reverted.SubmittedBy, reverted.SubmittedAt))
+	})
 	if err != nil {
+		span.RecordError(err)
 		return err
 	}
-	if formResponse.SubmittedBy != userID {
-		return internal.ErrResponseNotOwned
+
+	return nil
+}
+
+// MigrateDraftsToActiveWorkflow re-pins every draft response of a form to the form's active workflow
+// version, so respondents who are still filling the form get the current branching.
+// Submitted responses keep the version they were submitted under. Returns the number of moved drafts.
+func (s *Service) MigrateDraftsToActiveWorkflow(ctx context.Context, formID uuid.UUID) (int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "MigrateDraftsToActiveWorkflow")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	exists, err := s.formStore.Exists(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "check form exists")
+		span.RecordError(err)
+		return 0, err
 	}
-	if formResponse.Progress != ResponseProgressSubmitted {
-		return internal.ErrResponseNotSubmitted
+	if !exists {
+		return 0, internal.ErrFormNotFound
 	}
 
-	_, err = s.queries.RevertSubmission(traceCtx, id)
+	migrated, err := s.queries.MigrateDraftsToActiveWorkflow(traceCtx, formID)
 	if err != nil {
-		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", id.String(), logger, "revert response submission")
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "form_id", formID.String(), logger, "migrate draft responses to active workflow")
 		span.RecordError(err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	logger.Info("Migrated draft responses to active workflow", zap.String("formID", formID.String()), zap.Int64("count", migrated))
+
+	return migrated, nil
 }
 
 // ResolveWorkflowSectionsForResponse runs ResolveSections and builds sectionActiveMap when a workflow exists.
+// A non-nil responseID resolves against the workflow version the response is pinned to; uuid.Nil
+// (e.g. for export column ordering) resolves against the latest workflow version.
 // If ErrWorkflowNotFound, it returns the error and no section ordering/active-map is produced.
 // Any other error is wrapped and returned.
 func (s *Service) ResolveWorkflowSectionsForResponse(
@@ -802,7 +933,11 @@ func (s *Service) ResolveWorkflowSectionsForResponse(
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	sectionIDs, err = s.workflowResolver.ResolveSections(traceCtx, formID, answerPayload, answerableMap)
+	if responseID == uuid.Nil {
+		sectionIDs, err = s.workflowResolver.ResolveSections(traceCtx, formID, answerPayload, answerableMap)
+	} else {
+		sectionIDs, err = s.workflowResolver.ResolveSectionsForResponse(traceCtx, formID, responseID, answerPayload, answerableMap)
+	}
 	if err != nil {
 		if errors.Is(err, internal.ErrWorkflowNotFound) {
 			logger.Error("Workflow not found for response", zap.String("responseID", responseID.String()))
@@ -32,12 +32,18 @@ CREATE TABLE IF NOT EXISTS forms (
     dressing_header_font TEXT,
     dressing_question_font TEXT,
     dressing_text_font TEXT,
-    allow_edit_response BOOLEAN NOT NULL DEFAULT false
+    allow_edit_response BOOLEAN NOT NULL DEFAULT false,
+    deadline_reminders_enabled BOOLEAN NOT NULL DEFAULT false,
+    -- How many hours before the deadline users who have not submitted a response are reminded
+    deadline_reminder_hours INTEGER[] NOT NULL DEFAULT '{72,24}'
 );
 
+CREATE INDEX IF NOT EXISTS idx_forms_draft_publish_time ON forms(publish_time) WHERE status = 'draft';
+CREATE INDEX IF NOT EXISTS idx_forms_published_deadline ON forms(deadline) WHERE status = 'published';
+
 CREATE TABLE IF NOT EXISTS form_covers (
     form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
-    image_data BYTEA NOT NULL,
+    file_id UUID NOT NULL REFERENCES files(id) ON DELETE CASCADE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
@@ -30,13 +30,15 @@ type Querier interface {
 	GetStatus(ctx context.Context, id uuid.UUID) (Status, error)
 	SetStatus(ctx context.Context, arg SetStatusParams) (Form, error)
 	UploadCoverImage(ctx context.Context, arg UploadCoverImageParams) (uuid.UUID, error)
-	GetCoverImage(ctx context.Context, id uuid.UUID) ([]byte, error)
+	GetCoverFileID(ctx context.Context, formID uuid.UUID) (uuid.UUID, error)
 	GetUnitID(ctx context.Context, id uuid.UUID) (pgtype.UUID, error)
 	GetUnitIDBySectionID(ctx context.Context, id uuid.UUID) (pgtype.UUID, error)
 	Exists(ctx context.Context, id uuid.UUID) (bool, error)
 	GetCreator(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	GetIDBySectionID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	GetAvailabilityInfo(ctx context.Context, id uuid.UUID) (GetAvailabilityInfoRow, error)
+	ClaimDueForPublish(ctx context.Context, skipIds []uuid.UUID) (ClaimDueForPublishRow, error)
+	CloseExpired(ctx context.Context) ([]uuid.UUID, error)
 }
 
 type UserFormStatus string
@@ -77,6 +79,15 @@ func NewService(logger *zap.Logger, db DBTX, markdownStore MarkdownStore) *Servi
 	}
 }
 
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:        s.logger,
+		queries:       New(tx),
+		tracer:        s.tracer,
+		markdownStore: s.markdownStore,
+	}
+}
+
 func (s *Service) Create(ctx context.Context, request Request, unitID uuid.UUID, userID uuid.UUID) (CreateRow, error) {
 	ctx, span := s.tracer.Start(ctx, "Create")
 	defer span.End()
@@ -178,6 +189,14 @@ func (s *Service) Patch(ctx context.Context, id uuid.UUID, request PatchRequest,
 		params.AllowEditResponse = pgtype.Bool{Bool: *a, Valid: true}
 	}
 
+	reminders := request.DeadlineReminders
+	if reminders != nil {
+		if reminders.Enabled != nil {
+			params.DeadlineRemindersEnabled = pgtype.Bool{Bool: *reminders.Enabled, Valid: true}
+		}
+		params.DeadlineReminderHours = reminders.OffsetHours
+	}
+
 	return s.PatchParams(ctx, params)
 }
 
@@ -307,14 +326,57 @@ func (s *Service) SetStatus(ctx context.Context, id uuid.UUID, status Status, us
 	return updated, nil
 }
 
-func (s *Service) UploadCoverImage(ctx context.Context, formID uuid.UUID, imageData []byte, coverImageURL string) error {
+// ClaimDueForPublish locks the earliest draft form whose publish_time has passed, skipping
+// forms in skipIDs and forms already locked by another transaction. It must run inside a
+// transaction (see WithTx) so the lock is held until the form is published.
+// The second return value is false when no form is due.
+func (s *Service) ClaimDueForPublish(ctx context.Context, skipIDs []uuid.UUID) (ClaimDueForPublishRow, bool, error) {
+	ctx, span := s.tracer.Start(ctx, "ClaimDueForPublish")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	// a nil slice is sent as NULL, which would make the NOT ANY filter drop every row
+	if skipIDs == nil {
+		skipIDs = []uuid.UUID{}
+	}
+
+	row, err := s.queries.ClaimDueForPublish(ctx, skipIDs)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ClaimDueForPublishRow{}, false, nil
+		}
+		err = databaseutil.WrapDBError(err, logger, "claim form due for publish")
+		span.RecordError(err)
+		return ClaimDueForPublishRow{}, false, err
+	}
+
+	return row, true, nil
+}
+
+// CloseExpired sets every published form whose deadline has passed to closed and returns their IDs
+func (s *Service) CloseExpired(ctx context.Context) ([]uuid.UUID, error) {
+	ctx, span := s.tracer.Start(ctx, "CloseExpired")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	closed, err := s.queries.CloseExpired(ctx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "close expired forms")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return closed, nil
+}
+
+func (s *Service) UploadCoverImage(ctx context.Context, formID uuid.UUID, fileID uuid.UUID, coverImageURL string) error {
 	ctx, span := s.tracer.Start(ctx, "UploadCoverImage")
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
 	_, err := s.queries.UploadCoverImage(ctx, UploadCoverImageParams{
-		FormID:    formID,
-		ImageData: imageData,
+		FormID: formID,
+		FileID: fileID,
 		CoverImageUrl: pgtype.Text{
 			String: coverImageURL,
 			Valid:  coverImageURL != "",
@@ -332,19 +394,20 @@ func (s *Service) UploadCoverImage(ctx context.Context, formID uuid.UUID, imageD
 	return nil
 }
 
-func (s *Service) GetCoverImage(ctx context.Context, id uuid.UUID) ([]byte, error) {
-	ctx, span := s.tracer.Start(ctx, "GetCoverImage")
+// GetCoverFileID returns the ID of the file of the form's cover
+func (s *Service) GetCoverFileID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	ctx, span := s.tracer.Start(ctx, "GetCoverFileID")
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
-	imageData, err := s.queries.GetCoverImage(ctx, id)
+	fileID, err := s.queries.GetCoverFileID(ctx, id)
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "get form cover image")
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_covers", "form_id", id.String(), logger, "get form cover file")
 		span.RecordError(err)
-		return nil, err
+		return uuid.Nil, err
 	}
 
-	return imageData, nil
+	return fileID, nil
 }
 
 func (s *Service) GetUnitID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
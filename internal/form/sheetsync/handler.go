@@ -0,0 +1,110 @@
+package sheetsync
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Response struct {
+	FormID   string `json:"formId"`
+	SheetURL string `json:"sheetUrl"`
+	// Status is PENDING while a resync is queued, SYNCED or FAILED
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+type Store interface {
+	Get(ctx context.Context, formID uuid.UUID) (FormSheetSync, error)
+	RequestResync(ctx context.Context, formID uuid.UUID) (FormSheetSync, error)
+}
+
+type Handler struct {
+	logger        *zap.Logger
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+	store         Store
+	tracer        trace.Tracer
+}
+
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store) *Handler {
+	return &Handler{
+		logger:        logger,
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tracer:        otel.Tracer("sheetsync/handler"),
+	}
+}
+
+func ToResponse(sync FormSheetSync) Response {
+	resp := Response{
+		FormID:    sync.FormID.String(),
+		SheetURL:  sync.SheetUrl,
+		Status:    strings.ToUpper(string(sync.Status)),
+		UpdatedAt: sync.UpdatedAt.Time,
+	}
+	if sync.Error.Valid {
+		resp.Error = sync.Error.String
+	}
+	if sync.LastSyncedAt.Valid {
+		lastSyncedAt := sync.LastSyncedAt.Time
+		resp.LastSyncedAt = &lastSyncedAt
+	}
+	return resp
+}
+
+// Get reports the sync of the responses of a form to its Google Sheet (requires org member permission)
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	sync, err := h.store.Get(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(sync))
+}
+
+// Resync queues a rewrite of the Google Sheet of a form with every submitted response (requires org member
+// permission)
+func (h *Handler) Resync(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Resync")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	sync, err := h.store.RequestResync(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusAccepted, ToResponse(sync))
+}
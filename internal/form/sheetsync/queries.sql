@@ -0,0 +1,52 @@
+-- name: Get :one
+SELECT * FROM form_sheet_syncs
+WHERE form_id = $1;
+
+-- name: Lock :one
+-- Creates the sync of a form if it has none and locks it for lock_seconds, so that the syncs of a form
+-- run one at a time across replicas. Returns no row while another sync holds the lock.
+INSERT INTO form_sheet_syncs (form_id, sheet_url, locked_until)
+VALUES (@form_id, @sheet_url, now() + make_interval(secs => @lock_seconds::int))
+ON CONFLICT (form_id) DO UPDATE SET locked_until = EXCLUDED.locked_until
+WHERE form_sheet_syncs.locked_until IS NULL OR form_sheet_syncs.locked_until < now()
+RETURNING *;
+
+-- name: RequestResync :one
+INSERT INTO form_sheet_syncs (form_id, sheet_url)
+VALUES ($1, $2)
+ON CONFLICT (form_id) DO UPDATE SET status = 'pending', error = NULL, updated_at = now()
+RETURNING *;
+
+-- name: MarkSynced :execrows
+-- Records a sync and releases its lock, given by the locked_until Lock returned. A sync whose lock
+-- expired, and may have been taken by another sync, updates no row.
+UPDATE form_sheet_syncs
+SET sheet_url = @sheet_url, status = 'synced', error = NULL, last_synced_at = now(), locked_until = NULL, updated_at = now()
+WHERE form_id = @form_id AND locked_until = @locked_until;
+
+-- name: MarkFailed :exec
+-- Records a failed sync and releases its lock, like MarkSynced
+UPDATE form_sheet_syncs
+SET status = 'failed', error = @error, locked_until = NULL, updated_at = now()
+WHERE form_id = @form_id AND locked_until = @locked_until;
+
+-- name: GetRow :one
+SELECT * FROM form_sheet_sync_rows
+WHERE form_id = $1 AND response_id = $2;
+
+-- name: CreateRow :exec
+INSERT INTO form_sheet_sync_rows (form_id, response_id, row_number)
+VALUES ($1, $2, $3);
+
+-- name: CreateRows :exec
+INSERT INTO form_sheet_sync_rows (form_id, response_id, row_number)
+SELECT @form_id, unnest(@response_ids::uuid[]), unnest(@row_numbers::int[]);
+
+-- name: SetStruck :exec
+UPDATE form_sheet_sync_rows
+SET struck = $3
+WHERE form_id = $1 AND response_id = $2;
+
+-- name: DeleteRows :exec
+DELETE FROM form_sheet_sync_rows
+WHERE form_id = $1;
@@ -0,0 +1,30 @@
+CREATE TYPE sheet_sync_status AS ENUM(
+    'pending',
+    'synced',
+    'failed'
+);
+
+-- Sync of the responses of a form to its linked Google Sheet
+CREATE TABLE IF NOT EXISTS form_sheet_syncs (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    -- Google Sheet the rows below were written to; a form linked to another sheet is synced in full again
+    sheet_url TEXT NOT NULL,
+    status sheet_sync_status NOT NULL DEFAULT 'pending',
+    -- Error of the last failed sync
+    error TEXT,
+    last_synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Until when a sync holds the lock of the sheet; a lock whose sync crashed expires
+    locked_until TIMESTAMPTZ
+);
+
+-- Sheet row of each synced response
+CREATE TABLE IF NOT EXISTS form_sheet_sync_rows (
+    form_id UUID NOT NULL REFERENCES form_sheet_syncs(form_id) ON DELETE CASCADE,
+    -- Not a foreign key: the row of a deleted response stays in the sheet, struck through
+    response_id UUID NOT NULL,
+    row_number INTEGER NOT NULL,
+    struck BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (form_id, response_id)
+);
@@ -0,0 +1,495 @@
+package sheetsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// lockTimeout is how long a sync holds the lock of a sheet at most; it is well above the time the outbox
+	// gives a handler, so only the lock of a crashed sync expires
+	lockTimeout = 5 * time.Minute
+)
+
+var (
+	// errSyncBusy is returned while another sync of the sheet runs; the outbox retries the event later
+	errSyncBusy = errors.New("another sync of the sheet is running")
+	// errLockExpired is returned by a sync that outlived its lock; the outbox retries the event later
+	errLockExpired = errors.New("sheet sync outlived its lock")
+)
+
+type Querier interface {
+	Get(ctx context.Context, formID uuid.UUID) (FormSheetSync, error)
+	Lock(ctx context.Context, arg LockParams) (FormSheetSync, error)
+	RequestResync(ctx context.Context, arg RequestResyncParams) (FormSheetSync, error)
+	MarkSynced(ctx context.Context, arg MarkSyncedParams) (int64, error)
+	MarkFailed(ctx context.Context, arg MarkFailedParams) error
+	GetRow(ctx context.Context, arg GetRowParams) (FormSheetSyncRow, error)
+	CreateRow(ctx context.Context, arg CreateRowParams) error
+	CreateRows(ctx context.Context, arg CreateRowsParams) error
+	SetStruck(ctx context.Context, arg SetStruckParams) error
+	DeleteRows(ctx context.Context, formID uuid.UUID) error
+}
+
+type FormStore interface {
+	Get(ctx context.Context, id uuid.UUID) (form.GetRow, error)
+}
+
+type Exporter interface {
+	PrepareFullExport(ctx context.Context, formID uuid.UUID, filter response.ListFilter) (response.ExportPlan, error)
+	ForEachExportCells(ctx context.Context, plan response.ExportPlan, fn func(responseID uuid.UUID, cells []string) error) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, aggregateType string, aggregateID uuid.UUID, eventType string, payload any) (outbox.Event, error)
+	WithTx(tx pgx.Tx) *outbox.Service
+}
+
+// Service keeps the Google Sheet linked to a form through its googleSheetUrl up to date with the submitted
+// responses of the form, in the column layout of the response exports.
+//
+// It handles the response events of the outbox: a submitted response is appended to the sheet, or its row
+// is rewritten if it is already there, such as a response submitted again after it was edited; the row of
+// a cancelled or deleted response is struck through. A resync, requested with RequestResync, rewrites the
+// whole sheet, which also brings in answers edited without submitting the response again and questions
+// added since the rows were written.
+//
+// The syncs of a form hold the lock of its sync row, so they run one at a time across replicas; the lock is
+// a lease taken and released by short statements, so no transaction is held open across the Google Sheets
+// calls. A form whose rows are not known to be in its sheet, because it was never synced, its last sync
+// failed or it was linked to another sheet, is synced in full by its next event. A sheet that is missing
+// or not shared with the service account fails the sync without the event being retried.
+type Service struct {
+	logger  *zap.Logger
+	tracer  trace.Tracer
+	db      DBTX
+	queries Querier
+
+	// client is nil if Google Sheets sync is not configured
+	client    Client
+	formStore FormStore
+	exporter  Exporter
+	events    EventPublisher
+}
+
+func NewService(logger *zap.Logger, db DBTX, client Client, formStore FormStore, exporter Exporter, events EventPublisher) *Service {
+	return &Service{
+		logger:    logger,
+		tracer:    otel.Tracer("sheetsync/service"),
+		db:        db,
+		queries:   New(db),
+		client:    client,
+		formStore: formStore,
+		exporter:  exporter,
+		events:    events,
+	}
+}
+
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:    s.logger,
+		tracer:    s.tracer,
+		db:        tx,
+		queries:   New(tx),
+		client:    s.client,
+		formStore: s.formStore,
+		exporter:  s.exporter,
+		events:    s.events.WithTx(tx),
+	}
+}
+
+// Get returns the sync of the responses of a form to its Google Sheet
+func (s *Service) Get(ctx context.Context, formID uuid.UUID) (FormSheetSync, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	sync, err := s.queries.Get(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FormSheetSync{}, internal.ErrSheetSyncNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_syncs", "form_id", formID.String(), logger, "get sheet sync")
+		span.RecordError(err)
+		return FormSheetSync{}, err
+	}
+
+	return sync, nil
+}
+
+// RequestResync marks the sync of a form as pending and records a form.sheet_resync_requested event in the
+// outbox in the same transaction; its handler rewrites the whole sheet
+func (s *Service) RequestResync(ctx context.Context, formID uuid.UUID) (FormSheetSync, error) {
+	traceCtx, span := s.tracer.Start(ctx, "RequestResync")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if s.client == nil {
+		return FormSheetSync{}, internal.ErrSheetSyncNotConfigured
+	}
+
+	formRow, err := s.formStore.Get(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return FormSheetSync{}, err
+	}
+	if formRow.GoogleSheetUrl.String == "" {
+		return FormSheetSync{}, internal.ErrGoogleSheetNotLinked
+	}
+	_, _, err = ParseSheetURL(formRow.GoogleSheetUrl.String)
+	if err != nil {
+		return FormSheetSync{}, err
+	}
+
+	var sync FormSheetSync
+	err = internal.WithTransaction(traceCtx, s.db, logger, func(tx pgx.Tx) error {
+		txService := s.WithTx(tx)
+
+		sync, err = txService.queries.RequestResync(traceCtx, RequestResyncParams{
+			FormID:   formID,
+			SheetUrl: formRow.GoogleSheetUrl.String,
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_syncs", "form_id", formID.String(), logger, "request sheet resync")
+		}
+
+		_, err = txService.events.Publish(traceCtx, outbox.AggregateForm, formID, outbox.TypeFormSheetResyncRequested, outbox.FormPayload{FormID: formID})
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return FormSheetSync{}, err
+	}
+
+	return sync, nil
+}
+
+// HandleResponseEvent writes a submitted response to the sheet of its form, or strikes through the row of a
+// cancelled or deleted one; it is an outbox handler of response.submitted, response.cancelled and
+// response.deleted events
+func (s *Service) HandleResponseEvent(ctx context.Context, event outbox.Event) error {
+	traceCtx, span := s.tracer.Start(ctx, "HandleResponseEvent")
+	defer span.End()
+
+	var payload outbox.ResponsePayload
+	err := json.Unmarshal(event.Payload, &payload)
+	if err != nil {
+		err = fmt.Errorf("failed to decode %s event payload: %w", event.Type, err)
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.sync(traceCtx, payload.FormID, &responseChange{
+		payload:   payload,
+		submitted: event.Type == outbox.TypeResponseSubmitted,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// HandleResyncRequested rewrites the whole sheet of a form; it is an outbox handler of
+// form.sheet_resync_requested events
+func (s *Service) HandleResyncRequested(ctx context.Context, event outbox.Event) error {
+	traceCtx, span := s.tracer.Start(ctx, "HandleResyncRequested")
+	defer span.End()
+
+	var payload outbox.FormPayload
+	err := json.Unmarshal(event.Payload, &payload)
+	if err != nil {
+		err = fmt.Errorf("failed to decode %s event payload: %w", event.Type, err)
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.sync(traceCtx, payload.FormID, nil)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// responseChange is a response event to apply to the sheet
+type responseChange struct {
+	payload outbox.ResponsePayload
+	// submitted is false for a cancelled or deleted response
+	submitted bool
+}
+
+// sync applies a response change to the sheet of a form, or rewrites the whole sheet if change is nil,
+// and records the outcome. Forms without a sheet are skipped, and so are events of a sheet that is
+// unavailable until its owner shares it again, after their failure is recorded.
+func (s *Service) sync(ctx context.Context, formID uuid.UUID, change *responseChange) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	formRow, err := s.formStore.Get(ctx, formID)
+	if err != nil {
+		if errors.Is(err, internal.ErrFormNotFound) {
+			// The form was deleted since
+			return nil
+		}
+		return err
+	}
+	sheetURL := formRow.GoogleSheetUrl.String
+	if sheetURL == "" {
+		return nil
+	}
+
+	current, err := s.queries.Lock(ctx, LockParams{
+		FormID:      formID,
+		SheetUrl:    sheetURL,
+		LockSeconds: int32(lockTimeout / time.Second),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errSyncBusy
+		}
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_syncs", "form_id", formID.String(), logger, "lock sheet sync")
+	}
+
+	err = s.syncLocked(ctx, current, sheetURL, change)
+	if err != nil {
+		markErr := s.queries.MarkFailed(ctx, MarkFailedParams{
+			Error:       pgtype.Text{String: err.Error(), Valid: true},
+			FormID:      formID,
+			LockedUntil: current.LockedUntil,
+		})
+		if markErr != nil {
+			logger.Error("Failed to record failed sheet sync", zap.String("form_id", formID.String()), zap.Error(markErr))
+		}
+		if errors.Is(err, internal.ErrGoogleSheetUnavailable) || errors.Is(err, internal.ErrInvalidGoogleSheetURL) {
+			logger.Warn("Google Sheet of form is unavailable", zap.String("form_id", formID.String()), zap.Error(err))
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// syncLocked applies a change to a sheet while holding the lock of the sync of the form, and records the
+// sync
+func (s *Service) syncLocked(ctx context.Context, current FormSheetSync, sheetURL string, change *responseChange) error {
+	spreadsheetID, sheetID, err := ParseSheetURL(sheetURL)
+	if err != nil {
+		return err
+	}
+	sheet, err := s.client.GetSheet(ctx, spreadsheetID, sheetID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case change == nil || current.Status != SheetSyncStatusSynced || current.SheetUrl != sheetURL:
+		return s.resync(ctx, current, sheetURL, sheet)
+	case change.submitted:
+		err = s.writeRow(ctx, current.FormID, sheet, change.payload)
+	default:
+		err = s.strikeRow(ctx, current.FormID, sheet, change.payload.ResponseID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.markSynced(ctx, current, sheetURL)
+}
+
+// markSynced records a sync and releases its lock
+func (s *Service) markSynced(ctx context.Context, current FormSheetSync, sheetURL string) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	updated, err := s.queries.MarkSynced(ctx, MarkSyncedParams{
+		SheetUrl:    sheetURL,
+		FormID:      current.FormID,
+		LockedUntil: current.LockedUntil,
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_syncs", "form_id", current.FormID.String(), logger, "mark sheet sync as synced")
+	}
+	if updated == 0 {
+		return errLockExpired
+	}
+
+	return nil
+}
+
+// resync replaces the contents of a sheet with the header and every submitted response of a form, then
+// replaces the recorded rows and records the sync in one transaction
+func (s *Service) resync(ctx context.Context, current FormSheetSync, sheetURL string, sheet Sheet) error {
+	logger := logutil.WithContext(ctx, s.logger)
+	formID := current.FormID
+
+	submitted := response.ResponseProgressSubmitted
+	plan, err := s.exporter.PrepareFullExport(ctx, formID, response.ListFilter{Progress: &submitted})
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{plan.HeaderCells()}
+	responseIDs := make([]uuid.UUID, 0)
+	rowNumbers := make([]int32, 0)
+	err = s.exporter.ForEachExportCells(ctx, plan, func(responseID uuid.UUID, cells []string) error {
+		rows = append(rows, cells)
+		responseIDs = append(responseIDs, responseID)
+		rowNumbers = append(rowNumbers, int32(len(rows)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.client.Clear(ctx, sheet)
+	if err != nil {
+		return err
+	}
+	err = s.client.Update(ctx, sheet, 1, rows)
+	if err != nil {
+		return err
+	}
+
+	err = internal.WithTransaction(ctx, s.db, logger, func(tx pgx.Tx) error {
+		txService := s.WithTx(tx)
+
+		err := txService.queries.DeleteRows(ctx, formID)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_sync_rows", "form_id", formID.String(), logger, "delete sheet rows")
+		}
+		err = txService.queries.CreateRows(ctx, CreateRowsParams{
+			FormID:      formID,
+			ResponseIds: responseIDs,
+			RowNumbers:  rowNumbers,
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_sync_rows", "form_id", formID.String(), logger, "create sheet rows")
+		}
+
+		return txService.markSynced(ctx, current, sheetURL)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Synced responses to Google Sheet",
+		zap.String("form_id", formID.String()),
+		zap.String("spreadsheet_id", sheet.SpreadsheetID),
+		zap.Int("responses", len(responseIDs)),
+	)
+
+	return nil
+}
+
+// writeRow appends a submitted response to a sheet, or rewrites its row and removes its strikethrough if
+// it was synced before. A response that is no longer submitted is skipped.
+func (s *Service) writeRow(ctx context.Context, formID uuid.UUID, sheet Sheet, payload outbox.ResponsePayload) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	submitted := response.ResponseProgressSubmitted
+	plan, err := s.exporter.PrepareFullExport(ctx, formID, response.ListFilter{Progress: &submitted, SubmittedBy: &payload.SubmittedBy})
+	if err != nil {
+		return err
+	}
+
+	var cells []string
+	err = s.exporter.ForEachExportCells(ctx, plan, func(responseID uuid.UUID, responseCells []string) error {
+		if responseID == payload.ResponseID {
+			cells = responseCells
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if cells == nil {
+		// The response was cancelled or deleted since; its next event strikes its row, if it has one
+		return nil
+	}
+
+	// The questions of the form may have changed since the header was written
+	err = s.client.Update(ctx, sheet, 1, [][]string{plan.HeaderCells()})
+	if err != nil {
+		return err
+	}
+
+	row, err := s.queries.GetRow(ctx, GetRowParams{FormID: formID, ResponseID: payload.ResponseID})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_sync_rows", "response_id", payload.ResponseID.String(), logger, "get sheet row")
+		}
+
+		rowNumber, err := s.client.Append(ctx, sheet, cells)
+		if err != nil {
+			return err
+		}
+		err = s.queries.CreateRow(ctx, CreateRowParams{FormID: formID, ResponseID: payload.ResponseID, RowNumber: rowNumber})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_sync_rows", "response_id", payload.ResponseID.String(), logger, "create sheet row")
+		}
+		return nil
+	}
+
+	err = s.client.Update(ctx, sheet, row.RowNumber, [][]string{cells})
+	if err != nil {
+		return err
+	}
+	if row.Struck {
+		return s.setStruck(ctx, sheet, row, false)
+	}
+
+	return nil
+}
+
+// strikeRow strikes through the row of a cancelled or deleted response, if it was synced
+func (s *Service) strikeRow(ctx context.Context, formID uuid.UUID, sheet Sheet, responseID uuid.UUID) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	row, err := s.queries.GetRow(ctx, GetRowParams{FormID: formID, ResponseID: responseID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_sync_rows", "response_id", responseID.String(), logger, "get sheet row")
+	}
+	if row.Struck {
+		return nil
+	}
+
+	return s.setStruck(ctx, sheet, row, true)
+}
+
+func (s *Service) setStruck(ctx context.Context, sheet Sheet, row FormSheetSyncRow, struck bool) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.client.Strike(ctx, sheet, row.RowNumber, struck)
+	if err != nil {
+		return err
+	}
+
+	err = s.queries.SetStruck(ctx, SetStruckParams{FormID: row.FormID, ResponseID: row.ResponseID, Struck: struck})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_sheet_sync_rows", "response_id", row.ResponseID.String(), logger, "set sheet row struck")
+	}
+
+	return nil
+}
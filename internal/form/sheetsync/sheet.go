@@ -0,0 +1,65 @@
+package sheetsync
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"NYCU-SDC/core-system-backend/internal"
+)
+
+// Sheet is a sheet (tab) of a Google spreadsheet
+type Sheet struct {
+	SpreadsheetID string
+	ID            int64
+	Title         string
+}
+
+// Client writes to Google Sheets. Rows are numbered from 1, the header row.
+type Client interface {
+	// GetSheet returns the sheet of a spreadsheet with the given ID
+	GetSheet(ctx context.Context, spreadsheetID string, sheetID int64) (Sheet, error)
+	// Append writes a row after the last row of a sheet that has values and returns its number
+	Append(ctx context.Context, sheet Sheet, cells []string) (int32, error)
+	// Update writes rows from the row with the given number on
+	Update(ctx context.Context, sheet Sheet, firstRow int32, rows [][]string) error
+	// Clear removes the values and the strikethrough of every cell of a sheet
+	Clear(ctx context.Context, sheet Sheet) error
+	// Strike sets or removes the strikethrough of a row
+	Strike(ctx context.Context, sheet Sheet, row int32, struck bool) error
+}
+
+// ParseSheetURL returns the spreadsheet ID and the sheet ID of a Google Sheets URL such as
+// https://docs.google.com/spreadsheets/d/<spreadsheetId>/edit#gid=<sheetId>. A URL without a gid is of
+// the first sheet of the spreadsheet, whose ID is 0.
+func ParseSheetURL(rawURL string) (string, int64, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Scheme != "https" || parsed.Host != "docs.google.com" {
+		return "", 0, fmt.Errorf("%w: %q", internal.ErrInvalidGoogleSheetURL, rawURL)
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 3 || segments[0] != "spreadsheets" || segments[1] != "d" || segments[2] == "" {
+		return "", 0, fmt.Errorf("%w: %q", internal.ErrInvalidGoogleSheetURL, rawURL)
+	}
+	spreadsheetID := segments[2]
+
+	// The sheet is in the fragment of the URLs of the browser and in the query of shared links
+	gid := parsed.Query().Get("gid")
+	fragment, err := url.ParseQuery(parsed.Fragment)
+	if err == nil && fragment.Get("gid") != "" {
+		gid = fragment.Get("gid")
+	}
+	if gid == "" {
+		return spreadsheetID, 0, nil
+	}
+
+	sheetID, err := strconv.ParseInt(gid, 10, 64)
+	if err != nil || sheetID < 0 {
+		return "", 0, fmt.Errorf("%w: invalid gid %q", internal.ErrInvalidGoogleSheetURL, gid)
+	}
+
+	return spreadsheetID, sheetID, nil
+}
@@ -0,0 +1,83 @@
+package sheetsync
+
+import (
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSheetURL(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		url                   string
+		expectedSpreadsheetID string
+		expectedSheetID       int64
+		expectedErr           bool
+	}{
+		{
+			name:                  "Browser URL with the gid in the fragment",
+			url:                   "https://docs.google.com/spreadsheets/d/1AbC-dEf_123/edit#gid=456789",
+			expectedSpreadsheetID: "1AbC-dEf_123",
+			expectedSheetID:       456789,
+		},
+		{
+			name:                  "Shared link with the gid in the query",
+			url:                   "https://docs.google.com/spreadsheets/d/1AbC-dEf_123/edit?usp=sharing&gid=42",
+			expectedSpreadsheetID: "1AbC-dEf_123",
+			expectedSheetID:       42,
+		},
+		{
+			name:                  "URL without a gid is of the first sheet",
+			url:                   "https://docs.google.com/spreadsheets/d/1AbC-dEf_123",
+			expectedSpreadsheetID: "1AbC-dEf_123",
+			expectedSheetID:       0,
+		},
+		{
+			name:                  "Surrounding whitespace is ignored",
+			url:                   "  https://docs.google.com/spreadsheets/d/1AbC-dEf_123/edit#gid=7 ",
+			expectedSpreadsheetID: "1AbC-dEf_123",
+			expectedSheetID:       7,
+		},
+		{
+			name:        "Plain HTTP is rejected",
+			url:         "http://docs.google.com/spreadsheets/d/1AbC-dEf_123/edit",
+			expectedErr: true,
+		},
+		{
+			name:        "Other hosts are rejected",
+			url:         "https://example.com/spreadsheets/d/1AbC-dEf_123/edit",
+			expectedErr: true,
+		},
+		{
+			name:        "Google Docs document is rejected",
+			url:         "https://docs.google.com/document/d/1AbC-dEf_123/edit",
+			expectedErr: true,
+		},
+		{
+			name:        "Missing spreadsheet ID is rejected",
+			url:         "https://docs.google.com/spreadsheets/d/",
+			expectedErr: true,
+		},
+		{
+			name:        "Non-numeric gid is rejected",
+			url:         "https://docs.google.com/spreadsheets/d/1AbC-dEf_123/edit#gid=abc",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spreadsheetID, sheetID, err := ParseSheetURL(tc.url)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, internal.ErrInvalidGoogleSheetURL)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSpreadsheetID, spreadsheetID)
+			require.Equal(t, tc.expectedSheetID, sheetID)
+		})
+	}
+}
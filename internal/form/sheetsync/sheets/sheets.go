@@ -0,0 +1,273 @@
+// Package sheets is the Google Sheets API client the sheet sync writes responses with
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/sheetsync"
+
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// DefaultBaseURL is the endpoint of the Google Sheets API
+	DefaultBaseURL = "https://sheets.googleapis.com"
+	// scope lets the service account read and write the spreadsheets shared with it
+	scope = "https://www.googleapis.com/auth/spreadsheets"
+	// requestTimeout bounds every request to the API
+	requestTimeout = 30 * time.Second
+)
+
+// firstRowOfCells matches the first row number of the cells of an A1 range, such as A5:H5 in 'Responses'!A5:H5
+var firstRowOfCells = regexp.MustCompile(`^[A-Z]*([0-9]+)`)
+
+// Client calls the Google Sheets API v4
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// New returns a client that sends requests to baseURL with httpClient, which must authorize them
+func New(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// NewFromCredentialsFile returns a client of the Google Sheets API authorized as the service account whose
+// JSON key is at path. The service account writes to the spreadsheets shared with its email as an editor.
+func NewFromCredentialsFile(ctx context.Context, path string) (*Client, error) {
+	credentials, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read google service account credentials: %w", err)
+	}
+
+	config, err := google.JWTConfigFromJSON(credentials, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse google service account credentials: %w", err)
+	}
+
+	httpClient := config.Client(ctx)
+	httpClient.Timeout = requestTimeout
+	return New(httpClient, DefaultBaseURL), nil
+}
+
+type sheetProperties struct {
+	SheetID int64  `json:"sheetId"`
+	Title   string `json:"title"`
+}
+
+type spreadsheet struct {
+	Sheets []struct {
+		Properties sheetProperties `json:"properties"`
+	} `json:"sheets"`
+}
+
+type valueRange struct {
+	Range          string     `json:"range,omitempty"`
+	MajorDimension string     `json:"majorDimension,omitempty"`
+	Values         [][]string `json:"values"`
+}
+
+type appendResponse struct {
+	Updates struct {
+		UpdatedRange string `json:"updatedRange"`
+	} `json:"updates"`
+}
+
+type gridRange struct {
+	SheetID       int64  `json:"sheetId"`
+	StartRowIndex *int32 `json:"startRowIndex,omitempty"`
+	EndRowIndex   *int32 `json:"endRowIndex,omitempty"`
+}
+
+type textFormat struct {
+	Strikethrough bool `json:"strikethrough"`
+}
+
+type cellFormat struct {
+	TextFormat textFormat `json:"textFormat"`
+}
+
+type cellData struct {
+	UserEnteredFormat cellFormat `json:"userEnteredFormat"`
+}
+
+type repeatCellRequest struct {
+	Range  gridRange `json:"range"`
+	Cell   cellData  `json:"cell"`
+	Fields string    `json:"fields"`
+}
+
+type updateCellsRequest struct {
+	Range  gridRange `json:"range"`
+	Fields string    `json:"fields"`
+}
+
+type request struct {
+	RepeatCell  *repeatCellRequest  `json:"repeatCell,omitempty"`
+	UpdateCells *updateCellsRequest `json:"updateCells,omitempty"`
+}
+
+type batchUpdateRequest struct {
+	Requests []request `json:"requests"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+func (c *Client) GetSheet(ctx context.Context, spreadsheetID string, sheetID int64) (sheetsync.Sheet, error) {
+	var result spreadsheet
+	err := c.do(ctx, http.MethodGet, c.spreadsheetURL(spreadsheetID, "", url.Values{"fields": {"sheets.properties(sheetId,title)"}}), nil, &result)
+	if err != nil {
+		return sheetsync.Sheet{}, err
+	}
+
+	for _, sheet := range result.Sheets {
+		if sheet.Properties.SheetID == sheetID {
+			return sheetsync.Sheet{SpreadsheetID: spreadsheetID, ID: sheetID, Title: sheet.Properties.Title}, nil
+		}
+	}
+
+	return sheetsync.Sheet{}, fmt.Errorf("sheet %d not found in spreadsheet %s", sheetID, spreadsheetID)
+}
+
+func (c *Client) Append(ctx context.Context, sheet sheetsync.Sheet, cells []string) (int32, error) {
+	// Values are stored as they are, never parsed as formulas, numbers or dates
+	query := url.Values{
+		"valueInputOption": {"RAW"},
+		"insertDataOption": {"INSERT_ROWS"},
+	}
+
+	var result appendResponse
+	err := c.do(ctx, http.MethodPost, c.spreadsheetURL(sheet.SpreadsheetID, "/values/"+url.PathEscape(a1Range(sheet, 1))+":append", query),
+		valueRange{MajorDimension: "ROWS", Values: [][]string{cells}}, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	// The sheet title before the last ! may itself contain one
+	updatedRange := result.Updates.UpdatedRange
+	match := firstRowOfCells.FindStringSubmatch(updatedRange[strings.LastIndex(updatedRange, "!")+1:])
+	if match == nil {
+		return 0, fmt.Errorf("unexpected range of appended row: %q", updatedRange)
+	}
+	row, err := strconv.ParseInt(match[1], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected range of appended row: %q", updatedRange)
+	}
+
+	return int32(row), nil
+}
+
+func (c *Client) Update(ctx context.Context, sheet sheetsync.Sheet, firstRow int32, rows [][]string) error {
+	rangeA1 := a1Range(sheet, firstRow)
+	query := url.Values{"valueInputOption": {"RAW"}}
+
+	return c.do(ctx, http.MethodPut, c.spreadsheetURL(sheet.SpreadsheetID, "/values/"+url.PathEscape(rangeA1), query),
+		valueRange{Range: rangeA1, MajorDimension: "ROWS", Values: rows}, nil)
+}
+
+func (c *Client) Clear(ctx context.Context, sheet sheetsync.Sheet) error {
+	return c.batchUpdate(ctx, sheet.SpreadsheetID, request{UpdateCells: &updateCellsRequest{
+		Range:  gridRange{SheetID: sheet.ID},
+		Fields: "userEnteredValue,userEnteredFormat.textFormat.strikethrough",
+	}})
+}
+
+func (c *Client) Strike(ctx context.Context, sheet sheetsync.Sheet, row int32, struck bool) error {
+	// Grid ranges are zero-based and end-exclusive
+	start, end := row-1, row
+	return c.batchUpdate(ctx, sheet.SpreadsheetID, request{RepeatCell: &repeatCellRequest{
+		Range:  gridRange{SheetID: sheet.ID, StartRowIndex: &start, EndRowIndex: &end},
+		Cell:   cellData{UserEnteredFormat: cellFormat{TextFormat: textFormat{Strikethrough: struck}}},
+		Fields: "userEnteredFormat.textFormat.strikethrough",
+	}})
+}
+
+func (c *Client) batchUpdate(ctx context.Context, spreadsheetID string, requests ...request) error {
+	return c.do(ctx, http.MethodPost, c.spreadsheetURL(spreadsheetID, ":batchUpdate", nil), batchUpdateRequest{Requests: requests}, nil)
+}
+
+func (c *Client) spreadsheetURL(spreadsheetID string, suffix string, query url.Values) string {
+	u := c.baseURL + "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + suffix
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// do sends a request with a JSON body and decodes the JSON response into result, if it is not nil
+func (c *Client) do(ctx context.Context, method string, u string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("google sheets request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr errorResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&apiErr)
+		err = fmt.Errorf("google sheets returned %d", resp.StatusCode)
+		if apiErr.Error.Message != "" {
+			err = fmt.Errorf("google sheets returned %d %s: %s", resp.StatusCode, apiErr.Error.Status, apiErr.Error.Message)
+		}
+		// Retrying does not help until the sheet is shared with the service account again
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %w", internal.ErrGoogleSheetUnavailable, err)
+		}
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("failed to decode google sheets response: %w", err)
+	}
+
+	return nil
+}
+
+// a1Range returns the A1 notation of a sheet from the first column of a row on, quoting the sheet title
+func a1Range(sheet sheetsync.Sheet, row int32) string {
+	return "'" + strings.ReplaceAll(sheet.Title, "'", "''") + "'!A" + strconv.Itoa(int(row))
+}
@@ -0,0 +1,222 @@
+package sheets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/sheetsync"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is a request the fake Google Sheets API received
+type recordedRequest struct {
+	method string
+	path   string
+	query  string
+	body   map[string]any
+}
+
+// newFakeSheetsAPI starts a server that records every request and replies to it with the status and body
+// reply returns for it
+func newFakeSheetsAPI(t *testing.T, reply func(r *http.Request) (int, string)) (*Client, *[]recordedRequest) {
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded := recordedRequest{method: r.Method, path: r.URL.EscapedPath(), query: r.URL.RawQuery}
+		data, err := io.ReadAll(r.Body)
+		if err == nil && len(data) > 0 {
+			_ = json.Unmarshal(data, &recorded.body)
+		}
+		requests = append(requests, recorded)
+
+		status, body := reply(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return New(server.Client(), server.URL+"/"), &requests
+}
+
+func TestClient_GetSheet(t *testing.T) {
+	testCases := []struct {
+		name          string
+		sheetID       int64
+		expectedTitle string
+		expectedErr   bool
+	}{
+		{
+			name:          "Sheet of the spreadsheet is found by its ID",
+			sheetID:       789,
+			expectedTitle: "Responses",
+		},
+		{
+			name:        "Missing sheet is an error",
+			sheetID:     1,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, requests := newFakeSheetsAPI(t, func(r *http.Request) (int, string) {
+				return http.StatusOK, `{"sheets":[{"properties":{"sheetId":0,"title":"Sheet1"}},{"properties":{"sheetId":789,"title":"Responses"}}]}`
+			})
+
+			sheet, err := client.GetSheet(context.Background(), "spreadsheet-1", tc.sheetID)
+			require.Len(t, *requests, 1)
+			require.Equal(t, http.MethodGet, (*requests)[0].method)
+			require.Equal(t, "/v4/spreadsheets/spreadsheet-1", (*requests)[0].path)
+			require.Contains(t, (*requests)[0].query, "fields=")
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, sheetsync.Sheet{SpreadsheetID: "spreadsheet-1", ID: tc.sheetID, Title: tc.expectedTitle}, sheet)
+		})
+	}
+}
+
+func TestClient_Append(t *testing.T) {
+	testCases := []struct {
+		name         string
+		title        string
+		updatedRange string
+		expectedPath string
+		expectedRow  int32
+		expectedErr  bool
+	}{
+		{
+			name:         "Row number is read from the updated range",
+			title:        "Responses",
+			updatedRange: "'Responses'!A12:F12",
+			expectedPath: "/v4/spreadsheets/spreadsheet-1/values/%27Responses%27%21A1:append",
+			expectedRow:  12,
+		},
+		{
+			name:         "Title with an exclamation mark and an apostrophe",
+			title:        "Q1! Members' list",
+			updatedRange: "'Q1! Members'' list'!A3:B3",
+			expectedPath: "/v4/spreadsheets/spreadsheet-1/values/%27Q1%21%20Members%27%27%20list%27%21A1:append",
+			expectedRow:  3,
+		},
+		{
+			name:         "Unexpected range is an error",
+			title:        "Responses",
+			updatedRange: "Responses",
+			expectedPath: "/v4/spreadsheets/spreadsheet-1/values/%27Responses%27%21A1:append",
+			expectedErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, requests := newFakeSheetsAPI(t, func(r *http.Request) (int, string) {
+				encoded, err := json.Marshal(map[string]any{"updates": map[string]string{"updatedRange": tc.updatedRange}})
+				require.NoError(t, err)
+				return http.StatusOK, string(encoded)
+			})
+
+			row, err := client.Append(context.Background(), sheetsync.Sheet{SpreadsheetID: "spreadsheet-1", Title: tc.title}, []string{"a", "=b"})
+			require.Len(t, *requests, 1)
+			recorded := (*requests)[0]
+			require.Equal(t, http.MethodPost, recorded.method)
+			require.Equal(t, tc.expectedPath, recorded.path)
+			require.Contains(t, recorded.query, "valueInputOption=RAW")
+			require.Contains(t, recorded.query, "insertDataOption=INSERT_ROWS")
+			require.Equal(t, []any{[]any{"a", "=b"}}, recorded.body["values"])
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRow, row)
+		})
+	}
+}
+
+func TestClient_UpdateClearStrike(t *testing.T) {
+	client, requests := newFakeSheetsAPI(t, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{}`
+	})
+	ctx := context.Background()
+	sheet := sheetsync.Sheet{SpreadsheetID: "spreadsheet-1", ID: 789, Title: "Responses"}
+
+	require.NoError(t, client.Update(ctx, sheet, 5, [][]string{{"a", "b"}, {"c", "d"}}))
+	require.NoError(t, client.Clear(ctx, sheet))
+	require.NoError(t, client.Strike(ctx, sheet, 5, true))
+	require.Len(t, *requests, 3)
+
+	update := (*requests)[0]
+	require.Equal(t, http.MethodPut, update.method)
+	require.Equal(t, "/v4/spreadsheets/spreadsheet-1/values/%27Responses%27%21A5", update.path)
+	require.Equal(t, "valueInputOption=RAW", update.query)
+	require.Equal(t, "'Responses'!A5", update.body["range"])
+	require.Equal(t, []any{[]any{"a", "b"}, []any{"c", "d"}}, update.body["values"])
+
+	clearCells := (*requests)[1]
+	require.Equal(t, http.MethodPost, clearCells.method)
+	require.Equal(t, "/v4/spreadsheets/spreadsheet-1:batchUpdate", clearCells.path)
+	updateCells := clearCells.body["requests"].([]any)[0].(map[string]any)["updateCells"].(map[string]any)
+	require.Equal(t, map[string]any{"sheetId": float64(789)}, updateCells["range"])
+
+	strike := (*requests)[2]
+	require.Equal(t, "/v4/spreadsheets/spreadsheet-1:batchUpdate", strike.path)
+	repeatCell := strike.body["requests"].([]any)[0].(map[string]any)["repeatCell"].(map[string]any)
+	require.Equal(t, map[string]any{"sheetId": float64(789), "startRowIndex": float64(4), "endRowIndex": float64(5)}, repeatCell["range"])
+	require.Equal(t, map[string]any{"userEnteredFormat": map[string]any{"textFormat": map[string]any{"strikethrough": true}}}, repeatCell["cell"])
+	require.Equal(t, "userEnteredFormat.textFormat.strikethrough", repeatCell["fields"])
+}
+
+func TestClient_Error(t *testing.T) {
+	testCases := []struct {
+		name                string
+		status              int
+		body                string
+		expectedErr         string
+		expectedUnavailable bool
+	}{
+		{
+			name:                "Message of the API error is returned",
+			status:              http.StatusForbidden,
+			body:                `{"error":{"code":403,"message":"The caller does not have permission","status":"PERMISSION_DENIED"}}`,
+			expectedErr:         "google sheet is not found or not shared with the service account: google sheets returned 403 PERMISSION_DENIED: The caller does not have permission",
+			expectedUnavailable: true,
+		},
+		{
+			name:                "Missing spreadsheet is unavailable",
+			status:              http.StatusNotFound,
+			body:                `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND"}}`,
+			expectedErr:         "google sheet is not found or not shared with the service account: google sheets returned 404 NOT_FOUND: Requested entity was not found.",
+			expectedUnavailable: true,
+		},
+		{
+			name:        "Error without a JSON body",
+			status:      http.StatusBadGateway,
+			body:        `bad gateway`,
+			expectedErr: "google sheets returned 502",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, _ := newFakeSheetsAPI(t, func(r *http.Request) (int, string) {
+				return tc.status, tc.body
+			})
+
+			err := client.Clear(context.Background(), sheetsync.Sheet{SpreadsheetID: "spreadsheet-1"})
+			require.EqualError(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedUnavailable, errors.Is(err, internal.ErrGoogleSheetUnavailable))
+		})
+	}
+}
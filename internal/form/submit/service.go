@@ -7,6 +7,7 @@ import (
 	"NYCU-SDC/core-system-backend/internal/form/question"
 	"NYCU-SDC/core-system-backend/internal/form/response"
 	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/internal/outbox"
 	"context"
 	"errors"
 	"slices"
@@ -41,31 +42,43 @@ type FormResponseStore interface {
 	GetFormID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	UpdateSubmitted(ctx context.Context, id uuid.UUID) (response.FormResponse, error)
 	ListBySubmittedBy(ctx context.Context, submittedBy uuid.UUID) ([]response.FormResponse, error)
+	WithTx(tx pgx.Tx) *response.Service
+}
+
+type EventPublisher interface {
+	PublishResponseEvent(ctx context.Context, eventType string, payload outbox.ResponsePayload) error
+	WithTx(tx pgx.Tx) *outbox.Service
 }
 
 type Service struct {
 	logger *zap.Logger
 	tracer trace.Tracer
+	db     internal.DBTX
 
 	formStore     FormStore
 	questionStore QuestionStore
 	responseStore FormResponseStore
 	answerStore   AnswerStore
+	events        EventPublisher
 }
 
-func NewService(logger *zap.Logger, formStore FormStore, questionStore QuestionStore, formResponseStore FormResponseStore, answerStore AnswerStore) *Service {
+func NewService(logger *zap.Logger, db internal.DBTX, formStore FormStore, questionStore QuestionStore, formResponseStore FormResponseStore, answerStore AnswerStore, events EventPublisher) *Service {
 	return &Service{
 		logger:        logger,
 		tracer:        otel.Tracer("submit/service"),
+		db:            db,
 		formStore:     formStore,
 		questionStore: questionStore,
 		responseStore: formResponseStore,
 		answerStore:   answerStore,
+		events:        events,
 	}
 }
 
 // Submit updates answers for a response, validates all sections are complete, and marks the response as submitted
 // Returns an error if any section is not completed or skipped
+// The response is marked as submitted and its response.submitted event is recorded in the outbox in one
+// transaction
 func (s *Service) Submit(ctx context.Context, responseID uuid.UUID, answers []shared.AnswerParam) (response.FormResponse, []error) {
 	traceCtx, span := s.tracer.Start(ctx, "Submit")
 	defer span.End()
@@ -132,7 +145,16 @@ func (s *Service) Submit(ctx context.Context, responseID uuid.UUID, answers []sh
 	}
 
 	// Mark the response as submitted
-	formResponse, err := s.responseStore.UpdateSubmitted(traceCtx, responseID)
+	var formResponse response.FormResponse
+	err = internal.WithTransaction(traceCtx, s.db, logger, func(tx pgx.Tx) error {
+		formResponse, err = s.responseStore.WithTx(tx).UpdateSubmitted(traceCtx, responseID)
+		if err != nil {
+			return err
+		}
+
+		return s.events.WithTx(tx).PublishResponseEvent(traceCtx, outbox.TypeResponseSubmitted,
+			outbox.NewResponsePayload(formResponse.ID, formID, formResponse.SubmittedBy, formResponse.SubmittedAt))
+	})
 	if err != nil {
 		logger.Error("failed to update response to submitted", zap.Error(err))
 		return response.FormResponse{}, []error{err}
@@ -0,0 +1,336 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+
+	"github.com/google/uuid"
+)
+
+// Field is the part of a response that a view filter or sort key applies to
+type Field string
+
+const (
+	FieldAnswer      Field = "answer"      // the answer to the question in QuestionID
+	FieldSubmittedAt Field = "submittedAt" // the submission time; empty for drafts
+	FieldProgress    Field = "progress"    // draft or submitted
+	FieldSubmitter   Field = "submitter"   // the user ID of the respondent
+	FieldCreatedAt   Field = "createdAt"   // sort only
+	FieldUpdatedAt   Field = "updatedAt"   // sort only
+)
+
+type Direction string
+
+const (
+	DirectionAsc  Direction = "asc"
+	DirectionDesc Direction = "desc"
+)
+
+const (
+	maxDefinitionFilters  = 20
+	maxDefinitionSortKeys = 5
+)
+
+// Operators supported by the response fields; answer filters use the operators of the question type
+var (
+	submittedAtOperators = []question.Operator{question.OperatorGt, question.OperatorGte, question.OperatorLt, question.OperatorLte, question.OperatorBetween, question.OperatorIsEmpty}
+	progressOperators    = []question.Operator{question.OperatorEq, question.OperatorNeq}
+	submitterOperators   = []question.Operator{question.OperatorEq, question.OperatorNeq}
+	sortFields           = []Field{FieldAnswer, FieldSubmittedAt, FieldProgress, FieldCreatedAt, FieldUpdatedAt}
+)
+
+// Definition is what a view shows of the responses of a form. Columns lists the visible questions in
+// order, where an empty list shows every question. Filters are combined with AND. Sort keys are applied
+// in order; without sort keys responses are sorted by submission time, newest first.
+type Definition struct {
+	Columns []uuid.UUID `json:"columns"`
+	Filters []Filter    `json:"filters"`
+	Sort    []SortKey   `json:"sort"`
+}
+
+// Filter keeps the responses whose Field matches Operator and Value. Answer filters take the operators
+// and values of workflow condition rules; responses without an answer to the question only match isEmpty.
+// submittedAt takes RFC 3339 timestamps, progress takes "draft" or "submitted" and submitter takes a user ID.
+type Filter struct {
+	Field      Field             `json:"field"`
+	QuestionID *uuid.UUID        `json:"questionId,omitempty"`
+	Operator   question.Operator `json:"operator"`
+	Value      json.RawMessage   `json:"value,omitempty"`
+}
+
+// SortKey orders responses by Field; responses without a value are placed last in either direction
+type SortKey struct {
+	Field      Field      `json:"field"`
+	QuestionID *uuid.UUID `json:"questionId,omitempty"`
+	Direction  Direction  `json:"direction"`
+}
+
+// compiledDefinition is a definition whose references and operands have been checked and parsed into SQL
+// predicates and ORDER BY terms
+type compiledDefinition struct {
+	columns []question.Answerable
+	filters []func(q *responseQuery) string
+	sort    []func(q *responseQuery) string
+}
+
+// emptyDefinition is stored for new views
+var emptyDefinition = []byte(`{"columns":[],"filters":[],"sort":[]}`)
+
+// parseDefinition decodes a stored definition, normalizing missing lists to empty ones
+func parseDefinition(data []byte) (Definition, error) {
+	var definition Definition
+	if len(data) > 0 {
+		err := json.Unmarshal(data, &definition)
+		if err != nil {
+			return Definition{}, fmt.Errorf("could not parse view definition: %w", err)
+		}
+	}
+
+	return definition.normalize(), nil
+}
+
+// normalize replaces missing lists with empty ones so they are stored and returned as []
+func (d Definition) normalize() Definition {
+	if d.Columns == nil {
+		d.Columns = []uuid.UUID{}
+	}
+	if d.Filters == nil {
+		d.Filters = []Filter{}
+	}
+	if d.Sort == nil {
+		d.Sort = []SortKey{}
+	}
+	return d
+}
+
+// prune drops the columns, filters and sort keys of questions that are no longer in the form, so a view
+// keeps working after a question it references is deleted
+func (d Definition) prune(answerableMap map[string]question.Answerable) Definition {
+	inForm := func(questionID *uuid.UUID) bool {
+		if questionID == nil {
+			return true
+		}
+		_, ok := answerableMap[questionID.String()]
+		return ok
+	}
+
+	pruned := Definition{
+		Columns: make([]uuid.UUID, 0, len(d.Columns)),
+		Filters: make([]Filter, 0, len(d.Filters)),
+		Sort:    make([]SortKey, 0, len(d.Sort)),
+	}
+	for _, column := range d.Columns {
+		if inForm(&column) {
+			pruned.Columns = append(pruned.Columns, column)
+		}
+	}
+	for _, filter := range d.Filters {
+		if inForm(filter.QuestionID) {
+			pruned.Filters = append(pruned.Filters, filter)
+		}
+	}
+	for _, key := range d.Sort {
+		if inForm(key.QuestionID) {
+			pruned.Sort = append(pruned.Sort, key)
+		}
+	}
+
+	return pruned
+}
+
+// compile checks every reference and operand of the definition against the questions of the form.
+// orderedQuestions is the column order used when the definition has no columns.
+func (d Definition) compile(orderedQuestions []question.Answerable, answerableMap map[string]question.Answerable) (compiledDefinition, error) {
+	if len(d.Filters) > maxDefinitionFilters {
+		return compiledDefinition{}, fmt.Errorf("%w: at most %d filters are allowed, got %d", internal.ErrViewDefinitionInvalid, maxDefinitionFilters, len(d.Filters))
+	}
+	if len(d.Sort) > maxDefinitionSortKeys {
+		return compiledDefinition{}, fmt.Errorf("%w: at most %d sort keys are allowed, got %d", internal.ErrViewDefinitionInvalid, maxDefinitionSortKeys, len(d.Sort))
+	}
+
+	compiled := compiledDefinition{
+		columns: orderedQuestions,
+		filters: make([]func(q *responseQuery) string, 0, len(d.Filters)),
+		sort:    make([]func(q *responseQuery) string, 0, len(d.Sort)),
+	}
+
+	if len(d.Columns) > 0 {
+		compiled.columns = make([]question.Answerable, 0, len(d.Columns))
+		seen := make(map[uuid.UUID]bool, len(d.Columns))
+		for i, column := range d.Columns {
+			if seen[column] {
+				return compiledDefinition{}, fmt.Errorf("%w: columns[%d]: question %s is listed more than once", internal.ErrViewDefinitionInvalid, i, column)
+			}
+			seen[column] = true
+
+			answerable, ok := answerableMap[column.String()]
+			if !ok {
+				return compiledDefinition{}, fmt.Errorf("%w: columns[%d]: question %s not found in form", internal.ErrViewDefinitionInvalid, i, column)
+			}
+			compiled.columns = append(compiled.columns, answerable)
+		}
+	}
+
+	for i, filter := range d.Filters {
+		match, err := compileFilter(filter, answerableMap)
+		if err != nil {
+			return compiledDefinition{}, fmt.Errorf("%w: filters[%d]: %w", internal.ErrViewDefinitionInvalid, i, err)
+		}
+		compiled.filters = append(compiled.filters, match)
+	}
+
+	for i, key := range d.Sort {
+		if !slices.Contains(sortFields, key.Field) {
+			return compiledDefinition{}, fmt.Errorf("%w: sort[%d]: unsupported field '%s'", internal.ErrViewDefinitionInvalid, i, key.Field)
+		}
+
+		err := checkQuestionReference(key.Field, key.QuestionID, answerableMap)
+		if err != nil {
+			return compiledDefinition{}, fmt.Errorf("%w: sort[%d]: %w", internal.ErrViewDefinitionInvalid, i, err)
+		}
+
+		switch key.Direction {
+		case "":
+			key.Direction = DirectionAsc
+		case DirectionAsc, DirectionDesc:
+		default:
+			return compiledDefinition{}, fmt.Errorf("%w: sort[%d]: direction must be asc or desc, got '%s'", internal.ErrViewDefinitionInvalid, i, key.Direction)
+		}
+
+		compiled.sort = append(compiled.sort, compileSortKey(key, answerableMap))
+	}
+
+	return compiled, nil
+}
+
+// checkQuestionReference requires a question of the form for answer fields and no question for the others
+func checkQuestionReference(field Field, questionID *uuid.UUID, answerableMap map[string]question.Answerable) error {
+	if field != FieldAnswer {
+		if questionID != nil {
+			return fmt.Errorf("field '%s' does not take a questionId", field)
+		}
+		return nil
+	}
+
+	if questionID == nil {
+		return fmt.Errorf("field 'answer' requires a questionId")
+	}
+	if _, ok := answerableMap[questionID.String()]; !ok {
+		return fmt.Errorf("question %s not found in form", questionID)
+	}
+
+	return nil
+}
+
+// compileFilter returns the SQL predicate of the filter after checking its field, operator and value
+func compileFilter(filter Filter, answerableMap map[string]question.Answerable) (func(q *responseQuery) string, error) {
+	err := checkQuestionReference(filter.Field, filter.QuestionID, answerableMap)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filter.Field {
+	case FieldAnswer:
+		answerable := answerableMap[filter.QuestionID.String()]
+		err := answerable.ValidateOperand(filter.Operator, filter.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return compileAnswerFilter(answerable, filter.Operator, filter.Value)
+
+	case FieldSubmittedAt:
+		err := checkFieldOperator(filter.Field, submittedAtOperators, filter.Operator)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.Operator == question.OperatorIsEmpty {
+			if len(filter.Value) > 0 {
+				return nil, fmt.Errorf("operator 'isEmpty' does not take a value")
+			}
+			return func(q *responseQuery) string {
+				return "fr.submitted_at IS NULL"
+			}, nil
+		}
+
+		low, high, err := parseTimeRange(filter.Operator, filter.Value)
+		if err != nil {
+			return nil, err
+		}
+		return func(q *responseQuery) string {
+			return compareSQL(q, "fr.submitted_at", filter.Operator, low, high)
+		}, nil
+
+	case FieldProgress:
+		err := checkFieldOperator(filter.Field, progressOperators, filter.Operator)
+		if err != nil {
+			return nil, err
+		}
+
+		var progress ResponseProgress
+		err = json.Unmarshal(filter.Value, &progress)
+		if err != nil || (progress != ResponseProgressDraft && progress != ResponseProgressSubmitted) {
+			return nil, fmt.Errorf("progress value must be \"draft\" or \"submitted\"")
+		}
+		return func(q *responseQuery) string {
+			return fmt.Sprintf("fr.progress %s %s", sqlComparisons[filter.Operator], q.arg(progress))
+		}, nil
+
+	case FieldSubmitter:
+		err := checkFieldOperator(filter.Field, submitterOperators, filter.Operator)
+		if err != nil {
+			return nil, err
+		}
+
+		var submitter uuid.UUID
+		err = json.Unmarshal(filter.Value, &submitter)
+		if err != nil {
+			return nil, fmt.Errorf("submitter value must be a user ID: %w", err)
+		}
+		return func(q *responseQuery) string {
+			return fmt.Sprintf("fr.submitted_by %s %s", sqlComparisons[filter.Operator], q.arg(submitter))
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported field '%s'", filter.Field)
+}
+
+func checkFieldOperator(field Field, supported []question.Operator, operator question.Operator) error {
+	if !slices.Contains(supported, operator) {
+		return fmt.Errorf("operator '%s' is not supported for field '%s' (supported: %s)", operator, field, question.FormatOperators(supported))
+	}
+	return nil
+}
+
+// parseTimeRange parses an RFC 3339 timestamp, or a [min, max] pair for between
+func parseTimeRange(operator question.Operator, value json.RawMessage) (time.Time, time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("operator '%s' requires a value", operator)
+	}
+
+	if operator != question.OperatorBetween {
+		var t time.Time
+		err := json.Unmarshal(value, &t)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("submittedAt value must be an RFC 3339 timestamp: %w", err)
+		}
+		return t, t, nil
+	}
+
+	var bounds []time.Time
+	err := json.Unmarshal(value, &bounds)
+	if err != nil || len(bounds) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("operator 'between' requires a value of the form [min, max] with RFC 3339 timestamps")
+	}
+	if bounds[0].After(bounds[1]) {
+		return time.Time{}, time.Time{}, fmt.Errorf("operator 'between' requires min to be before or equal to max")
+	}
+
+	return bounds[0], bounds[1], nil
+}
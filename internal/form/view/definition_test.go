@@ -0,0 +1,177 @@
+package view
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type definitionFixture struct {
+	nameID           uuid.UUID
+	scoreID          uuid.UUID
+	orderedQuestions []question.Answerable
+	answerableMap    map[string]question.Answerable
+}
+
+func newDefinitionFixture(t *testing.T) definitionFixture {
+	t.Helper()
+
+	f := definitionFixture{
+		nameID:        uuid.New(),
+		scoreID:       uuid.New(),
+		answerableMap: map[string]question.Answerable{},
+	}
+	for _, id := range []uuid.UUID{f.nameID, f.scoreID} {
+		answerable, err := question.NewAnswerable(question.Question{ID: id, Type: question.QuestionTypeShortText}, uuid.New())
+		require.NoError(t, err)
+		f.orderedQuestions = append(f.orderedQuestions, answerable)
+		f.answerableMap[id.String()] = answerable
+	}
+
+	return f
+}
+
+func TestDefinition_Compile(t *testing.T) {
+	f := newDefinitionFixture(t)
+	unknownID := uuid.New()
+
+	testCases := []struct {
+		name        string
+		definition  Definition
+		expectedErr bool
+	}{
+		{name: "empty definition", definition: Definition{}},
+		{
+			name: "answer, progress and submittedAt filters with sort",
+			definition: Definition{
+				Columns: []uuid.UUID{f.scoreID, f.nameID},
+				Filters: []Filter{
+					{Field: FieldAnswer, QuestionID: &f.nameID, Operator: question.OperatorContains, Value: json.RawMessage(`"NYCU"`)},
+					{Field: FieldProgress, Operator: question.OperatorEq, Value: json.RawMessage(`"submitted"`)},
+					{Field: FieldSubmittedAt, Operator: question.OperatorBetween, Value: json.RawMessage(`["2026-01-01T00:00:00Z","2026-12-31T23:59:59Z"]`)},
+				},
+				Sort: []SortKey{{Field: FieldAnswer, QuestionID: &f.scoreID, Direction: DirectionDesc}},
+			},
+		},
+		{name: "duplicate column", definition: Definition{Columns: []uuid.UUID{f.nameID, f.nameID}}, expectedErr: true},
+		{name: "column outside the form", definition: Definition{Columns: []uuid.UUID{unknownID}}, expectedErr: true},
+		{name: "answer filter without question", definition: Definition{Filters: []Filter{{Field: FieldAnswer, Operator: question.OperatorIsEmpty}}}, expectedErr: true},
+		{name: "operator unsupported by question type", definition: Definition{Filters: []Filter{{Field: FieldAnswer, QuestionID: &f.nameID, Operator: question.OperatorGt, Value: json.RawMessage(`"a"`)}}}, expectedErr: true},
+		{name: "unknown progress", definition: Definition{Filters: []Filter{{Field: FieldProgress, Operator: question.OperatorEq, Value: json.RawMessage(`"archived"`)}}}, expectedErr: true},
+		{name: "submittedAt between with reversed bounds", definition: Definition{Filters: []Filter{{Field: FieldSubmittedAt, Operator: question.OperatorBetween, Value: json.RawMessage(`["2026-12-31T00:00:00Z","2026-01-01T00:00:00Z"]`)}}}, expectedErr: true},
+		{name: "submitter with question", definition: Definition{Filters: []Filter{{Field: FieldSubmitter, QuestionID: &f.nameID, Operator: question.OperatorEq, Value: json.RawMessage(`"` + uuid.New().String() + `"`)}}}, expectedErr: true},
+		{name: "invalid sort direction", definition: Definition{Sort: []SortKey{{Field: FieldCreatedAt, Direction: "up"}}}, expectedErr: true},
+		{name: "sort by submitter", definition: Definition{Sort: []SortKey{{Field: FieldSubmitter}}}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.definition.compile(f.orderedQuestions, f.answerableMap)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, internal.ErrViewDefinitionInvalid)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDefinition_SQL(t *testing.T) {
+	f := newDefinitionFixture(t)
+	formID := uuid.New()
+	submitter := uuid.New()
+
+	testCases := []struct {
+		name            string
+		definition      Definition
+		expectedFrom    []string
+		expectedOrderBy string
+		expectedArgs    []any
+	}{
+		{
+			name:            "default sort is submission time, newest first, drafts last",
+			definition:      Definition{},
+			expectedOrderBy: "ORDER BY fr.submitted_at DESC NULLS LAST, fr.created_at ASC, fr.id ASC",
+			expectedArgs:    []any{formID},
+		},
+		{
+			name: "response field filters",
+			definition: Definition{Filters: []Filter{
+				{Field: FieldProgress, Operator: question.OperatorNeq, Value: json.RawMessage(`"draft"`)},
+				{Field: FieldSubmitter, Operator: question.OperatorEq, Value: json.RawMessage(`"` + submitter.String() + `"`)},
+				{Field: FieldSubmittedAt, Operator: question.OperatorIsEmpty},
+			}},
+			expectedFrom: []string{"WHERE fr.progress <> $2\nAND fr.submitted_by = $3\nAND fr.submitted_at IS NULL"},
+			expectedArgs: []any{formID, ResponseProgressDraft, submitter},
+		},
+		{
+			name: "submittedAt between",
+			definition: Definition{Filters: []Filter{
+				{Field: FieldSubmittedAt, Operator: question.OperatorBetween, Value: json.RawMessage(`["2026-01-01T00:00:00Z","2026-12-31T00:00:00Z"]`)},
+			}},
+			expectedFrom: []string{"WHERE fr.submitted_at BETWEEN $2 AND $3"},
+			expectedArgs: []any{formID, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "answer filters and sort join each question once",
+			definition: Definition{
+				Filters: []Filter{
+					{Field: FieldAnswer, QuestionID: &f.nameID, Operator: question.OperatorContains, Value: json.RawMessage(`"NYCU"`)},
+					{Field: FieldAnswer, QuestionID: &f.scoreID, Operator: question.OperatorIsEmpty},
+				},
+				Sort: []SortKey{{Field: FieldAnswer, QuestionID: &f.nameID, Direction: DirectionDesc}},
+			},
+			expectedFrom: []string{
+				"LEFT JOIN answers a0 ON a0.response_id = fr.id AND a0.question_id = $2\nLEFT JOIN answers a1 ON a1.response_id = fr.id AND a1.question_id = $4\n",
+				"((a0.value::jsonb) IS NOT NULL AND strpos(coalesce((a0.value::jsonb)->>'value', ''), $3) > 0)",
+				"((a1.value::jsonb) IS NULL OR coalesce((a1.value::jsonb)->>'value', '') ~ '^\\s*$')",
+			},
+			expectedOrderBy: "ORDER BY nullif((a0.value::jsonb)->>'value', '') IS NULL, ",
+			expectedArgs:    []any{formID, f.nameID, "NYCU", f.scoreID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, err := tc.definition.compile(f.orderedQuestions, f.answerableMap)
+			require.NoError(t, err)
+
+			query := compiled.sql(formID)
+			for _, expected := range tc.expectedFrom {
+				require.Contains(t, query.from, expected)
+			}
+			require.Contains(t, query.orderBy, tc.expectedOrderBy)
+			require.Equal(t, tc.expectedArgs, query.args)
+		})
+	}
+}
+
+func TestDefinition_Prune(t *testing.T) {
+	f := newDefinitionFixture(t)
+	deletedID := uuid.New()
+
+	definition := Definition{
+		Columns: []uuid.UUID{f.nameID, deletedID},
+		Filters: []Filter{
+			{Field: FieldAnswer, QuestionID: &deletedID, Operator: question.OperatorIsEmpty},
+			{Field: FieldProgress, Operator: question.OperatorEq, Value: json.RawMessage(`"submitted"`)},
+		},
+		Sort: []SortKey{{Field: FieldAnswer, QuestionID: &deletedID}},
+	}
+
+	pruned := definition.prune(f.answerableMap)
+
+	require.Equal(t, []uuid.UUID{f.nameID}, pruned.Columns)
+	require.Len(t, pruned.Filters, 1)
+	require.Equal(t, FieldProgress, pruned.Filters[0].Field)
+	require.Empty(t, pruned.Sort)
+
+	_, err := pruned.compile(f.orderedQuestions, f.answerableMap)
+	require.NoError(t, err)
+}
@@ -3,9 +3,14 @@ package view
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"NYCU-SDC/core-system-backend/internal"
+
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/NYCU-SDC/summer/pkg/problem"
@@ -16,19 +21,50 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	defaultResponsePageLimit = 20
+	maxResponsePageLimit     = 100
+)
+
 type ViewResponse struct {
-	ID        string    `json:"id"`
-	FormID    string    `json:"formId"`
-	Title     string    `json:"title"`
-	Locked    bool      `json:"locked"`
-	Order     int32     `json:"order"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID         string     `json:"id"`
+	FormID     string     `json:"formId"`
+	Title      string     `json:"title"`
+	Locked     bool       `json:"locked"`
+	Order      int32      `json:"order"`
+	Definition Definition `json:"definition"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
 }
 
 type UpdateViewRequest struct {
-	Title *string `json:"title"`
-	Order *int32  `json:"order"`
+	Title      *string     `json:"title"`
+	Order      *int32      `json:"order"`
+	Definition *Definition `json:"definition"`
+}
+
+type ColumnResponse struct {
+	QuestionID string `json:"questionId"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+}
+
+type ResponseRowResponse struct {
+	ID          string             `json:"id"`
+	SubmittedBy string             `json:"submittedBy"`
+	SubmittedAt *time.Time         `json:"submittedAt"`
+	Progress    string             `json:"progress"`
+	Answers     map[string]*string `json:"answers"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+type ListResponsesResponse struct {
+	Columns   []ColumnResponse      `json:"columns"`
+	Responses []ResponseRowResponse `json:"responses"`
+	Total     int                   `json:"total"`
+	Limit     int                   `json:"limit"`
+	Offset    int                   `json:"offset"`
 }
 
 type Store interface {
@@ -41,6 +77,8 @@ type Store interface {
 	Unlock(ctx context.Context, formID, viewID uuid.UUID) (View, error)
 	Duplicate(ctx context.Context, formID, viewID uuid.UUID) (View, error)
 	Delete(ctx context.Context, formID, viewID uuid.UUID) error
+	UpdateDefinition(ctx context.Context, formID, viewID uuid.UUID, definition Definition) (View, error)
+	ListResponses(ctx context.Context, formID, viewID uuid.UUID, limit, offset int) (ResponsePage, error)
 }
 
 type Handler struct {
@@ -66,16 +104,22 @@ func NewHandler(
 	}
 }
 
-func toViewResponse(v View) ViewResponse {
-	return ViewResponse{
-		ID:        v.ID.String(),
-		FormID:    v.FormID.String(),
-		Title:     v.Title,
-		Locked:    v.Locked,
-		Order:     v.Order,
-		CreatedAt: v.CreatedAt.Time,
-		UpdatedAt: v.UpdatedAt.Time,
+func toViewResponse(v View) (ViewResponse, error) {
+	definition, err := parseDefinition(v.Definition)
+	if err != nil {
+		return ViewResponse{}, fmt.Errorf("%w: %w", internal.ErrInternalServerError, err)
 	}
+
+	return ViewResponse{
+		ID:         v.ID.String(),
+		FormID:     v.FormID.String(),
+		Title:      v.Title,
+		Locked:     v.Locked,
+		Order:      v.Order,
+		Definition: definition,
+		CreatedAt:  v.CreatedAt.Time,
+		UpdatedAt:  v.UpdatedAt.Time,
+	}, nil
 }
 
 // Create handles POST /forms/{formId}/views
@@ -96,7 +140,13 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusCreated, toViewResponse(v))
+	resp, err := toViewResponse(v)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, resp)
 }
 
 // List handles GET /forms/{formId}/views
@@ -119,7 +169,12 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 
 	resp := make([]ViewResponse, 0, len(views))
 	for _, v := range views {
-		resp = append(resp, toViewResponse(v))
+		viewResponse, err := toViewResponse(v)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		resp = append(resp, viewResponse)
 	}
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
@@ -149,7 +204,13 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, toViewResponse(v))
+	resp, err := toViewResponse(v)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
 }
 
 // Update handles PATCH /forms/{formId}/views/{viewId}
@@ -186,6 +247,15 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	result := current
 
+	// Update the definition first so a locked view is rejected before anything else changes
+	if req.Definition != nil {
+		result, err = h.store.UpdateDefinition(traceCtx, formID, viewID, *req.Definition)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+	}
+
 	if req.Title != nil {
 		result, err = h.store.UpdateTitle(traceCtx, formID, viewID, *req.Title)
 		if err != nil {
@@ -202,7 +272,13 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, toViewResponse(result))
+	resp, err := toViewResponse(result)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
 }
 
 // Lock handles POST /forms/{formId}/views/{viewId}/lock
@@ -229,7 +305,13 @@ func (h *Handler) Lock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, toViewResponse(v))
+	resp, err := toViewResponse(v)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
 }
 
 // Unlock handles POST /forms/{formId}/views/{viewId}/unlock
@@ -256,7 +338,13 @@ func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, toViewResponse(v))
+	resp, err := toViewResponse(v)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
 }
 
 // Duplicate handles POST /forms/{formId}/views/{viewId}/duplicate
@@ -283,7 +371,13 @@ func (h *Handler) Duplicate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusCreated, toViewResponse(v))
+	resp, err := toViewResponse(v)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, resp)
 }
 
 // Delete handles DELETE /forms/{formId}/views/{viewId}
@@ -311,3 +405,84 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
 }
+
+// ListResponses handles GET /forms/{formId}/views/{viewId}/responses
+func (h *Handler) ListResponses(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListResponses")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	viewID, err := handlerutil.ParseUUID(r.PathValue("viewId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	limit := defaultResponsePageLimit
+	offset := 0
+
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > maxResponsePageLimit {
+			h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidLimit, logger)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	offsetStr := r.URL.Query().Get("offset")
+	if offsetStr != "" {
+		parsedOffset, err := strconv.Atoi(offsetStr)
+		if err != nil || parsedOffset < 0 {
+			h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidOffset, logger)
+			return
+		}
+		offset = parsedOffset
+	}
+
+	page, err := h.store.ListResponses(traceCtx, formID, viewID, limit, offset)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	resp := ListResponsesResponse{
+		Columns:   make([]ColumnResponse, 0, len(page.Columns)),
+		Responses: make([]ResponseRowResponse, 0, len(page.Responses)),
+		Total:     page.Total,
+		Limit:     limit,
+		Offset:    offset,
+	}
+	for _, column := range page.Columns {
+		q := column.Question()
+		resp.Columns = append(resp.Columns, ColumnResponse{
+			QuestionID: q.ID.String(),
+			Title:      q.Title.String,
+			Type:       strings.ToUpper(string(q.Type)),
+		})
+	}
+	for _, row := range page.Responses {
+		var submittedAt *time.Time
+		if row.Response.SubmittedAt.Valid {
+			submittedAt = &row.Response.SubmittedAt.Time
+		}
+		resp.Responses = append(resp.Responses, ResponseRowResponse{
+			ID:          row.Response.ID.String(),
+			SubmittedBy: row.Response.SubmittedBy.String(),
+			SubmittedAt: submittedAt,
+			Progress:    string(row.Response.Progress),
+			Answers:     row.Answers,
+			CreatedAt:   row.Response.CreatedAt.Time,
+			UpdatedAt:   row.Response.UpdatedAt.Time,
+		})
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
+}
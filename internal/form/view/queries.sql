@@ -16,8 +16,8 @@ SELECT COALESCE(MAX("order"), -1)::integer FROM views
 WHERE form_id = $1;
 
 -- name: Create :one
-INSERT INTO views (form_id, title, "order")
-VALUES ($1, $2, $3)
+INSERT INTO views (form_id, title, "order", definition)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: UpdateTitle :one
@@ -60,6 +60,12 @@ SET locked = $3, updated_at = now()
 WHERE id = $1 AND form_id = $2
 RETURNING *;
 
+-- name: UpdateDefinitionIfUnlocked :one
+UPDATE views
+SET definition = $3, updated_at = now()
+WHERE id = $1 AND form_id = $2 AND locked = FALSE
+RETURNING *;
+
 -- name: ShiftOrders :exec
 UPDATE views
 SET "order" = "order" + $3, updated_at = now()
@@ -75,3 +81,12 @@ SELECT EXISTS(SELECT 1 FROM views WHERE id = $1 AND form_id = $2);
 
 -- name: FormExists :one
 SELECT EXISTS(SELECT 1 FROM forms WHERE id = $1);
+
+-- name: ListResponsesByFormID :many
+SELECT id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
+WHERE form_id = $1;
+
+-- name: ListAnswersByResponseIDs :many
+SELECT response_id, question_id, value
+FROM answers
+WHERE response_id = ANY(@response_ids::uuid[]);
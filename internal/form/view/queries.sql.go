@@ -13,19 +13,25 @@ import (
 )
 
 const create = `-- name: Create :one
-INSERT INTO views (form_id, title, "order")
-VALUES ($1, $2, $3)
-RETURNING id, form_id, title, locked, "order", created_at, updated_at
+INSERT INTO views (form_id, title, "order", definition)
+VALUES ($1, $2, $3, $4)
+RETURNING id, form_id, title, locked, "order", definition, created_at, updated_at
 `
 
 type CreateParams struct {
-	FormID uuid.UUID
-	Title  string
-	Order  int32
+	FormID     uuid.UUID
+	Title      string
+	Order      int32
+	Definition []byte
 }
 
 func (q *Queries) Create(ctx context.Context, arg CreateParams) (View, error) {
-	row := q.db.QueryRow(ctx, create, arg.FormID, arg.Title, arg.Order)
+	row := q.db.QueryRow(ctx, create,
+		arg.FormID,
+		arg.Title,
+		arg.Order,
+		arg.Definition,
+	)
 	var i View
 	err := row.Scan(
 		&i.ID,
@@ -33,6 +39,7 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (View, error) {
 		&i.Title,
 		&i.Locked,
 		&i.Order,
+		&i.Definition,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -90,7 +97,7 @@ func (q *Queries) FormExists(ctx context.Context, id uuid.UUID) (bool, error) {
 }
 
 const get = `-- name: Get :one
-SELECT id, form_id, title, locked, "order", created_at, updated_at FROM views
+SELECT id, form_id, title, locked, "order", definition, created_at, updated_at FROM views
 WHERE id = $1 AND form_id = $2
 `
 
@@ -108,14 +115,47 @@ func (q *Queries) Get(ctx context.Context, arg GetParams) (View, error) {
 		&i.Title,
 		&i.Locked,
 		&i.Order,
+		&i.Definition,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const listAnswersByResponseIDs = `-- name: ListAnswersByResponseIDs :many
+SELECT response_id, question_id, value
+FROM answers
+WHERE response_id = ANY($1::uuid[])
+`
+
+type ListAnswersByResponseIDsRow struct {
+	ResponseID uuid.UUID
+	QuestionID uuid.UUID
+	Value      []byte
+}
+
+func (q *Queries) ListAnswersByResponseIDs(ctx context.Context, responseIds []uuid.UUID) ([]ListAnswersByResponseIDsRow, error) {
+	rows, err := q.db.Query(ctx, listAnswersByResponseIDs, responseIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAnswersByResponseIDsRow
+	for rows.Next() {
+		var i ListAnswersByResponseIDsRow
+		if err := rows.Scan(&i.ResponseID, &i.QuestionID, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listByFormID = `-- name: ListByFormID :many
-SELECT id, form_id, title, locked, "order", created_at, updated_at FROM views
+SELECT id, form_id, title, locked, "order", definition, created_at, updated_at FROM views
 WHERE form_id = $1
 ORDER BY "order" ASC
 `
@@ -135,6 +175,48 @@ func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]View, e
 			&i.Title,
 			&i.Locked,
 			&i.Order,
+			&i.Definition,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResponsesByFormID = `-- name: ListResponsesByFormID :many
+SELECT id, submitted_by, submitted_at, progress, created_at, updated_at FROM form_responses
+WHERE form_id = $1
+`
+
+type ListResponsesByFormIDRow struct {
+	ID          uuid.UUID
+	SubmittedBy uuid.UUID
+	SubmittedAt pgtype.Timestamptz
+	Progress    ResponseProgress
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+func (q *Queries) ListResponsesByFormID(ctx context.Context, formID uuid.UUID) ([]ListResponsesByFormIDRow, error) {
+	rows, err := q.db.Query(ctx, listResponsesByFormID, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListResponsesByFormIDRow
+	for rows.Next() {
+		var i ListResponsesByFormIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubmittedBy,
+			&i.SubmittedAt,
+			&i.Progress,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -202,11 +284,40 @@ func (q *Queries) ShiftOrders(ctx context.Context, arg ShiftOrdersParams) error
 	return err
 }
 
+const updateDefinitionIfUnlocked = `-- name: UpdateDefinitionIfUnlocked :one
+UPDATE views
+SET definition = $3, updated_at = now()
+WHERE id = $1 AND form_id = $2 AND locked = FALSE
+RETURNING id, form_id, title, locked, "order", definition, created_at, updated_at
+`
+
+type UpdateDefinitionIfUnlockedParams struct {
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	Definition []byte
+}
+
+func (q *Queries) UpdateDefinitionIfUnlocked(ctx context.Context, arg UpdateDefinitionIfUnlockedParams) (View, error) {
+	row := q.db.QueryRow(ctx, updateDefinitionIfUnlocked, arg.ID, arg.FormID, arg.Definition)
+	var i View
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.Title,
+		&i.Locked,
+		&i.Order,
+		&i.Definition,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateLocked = `-- name: UpdateLocked :one
 UPDATE views
 SET locked = $3, updated_at = now()
 WHERE id = $1 AND form_id = $2
-RETURNING id, form_id, title, locked, "order", created_at, updated_at
+RETURNING id, form_id, title, locked, "order", definition, created_at, updated_at
 `
 
 type UpdateLockedParams struct {
@@ -224,6 +335,7 @@ func (q *Queries) UpdateLocked(ctx context.Context, arg UpdateLockedParams) (Vie
 		&i.Title,
 		&i.Locked,
 		&i.Order,
+		&i.Definition,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -254,9 +366,9 @@ updated AS (
     UPDATE views
     SET "order" = $3, updated_at = now()
     WHERE id = $2 AND form_id = $1
-    RETURNING id, form_id, title, locked, "order", created_at, updated_at
+    RETURNING id, form_id, title, locked, "order", definition, created_at, updated_at
 )
-SELECT id, form_id, title, locked, "order", created_at, updated_at FROM updated
+SELECT id, form_id, title, locked, "order", definition, created_at, updated_at FROM updated
 `
 
 type UpdateOrderParams struct {
@@ -266,13 +378,14 @@ type UpdateOrderParams struct {
 }
 
 type UpdateOrderRow struct {
-	ID        uuid.UUID
-	FormID    uuid.UUID
-	Title     string
-	Locked    bool
-	Order     int32
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	Title      string
+	Locked     bool
+	Order      int32
+	Definition []byte
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
 }
 
 func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (UpdateOrderRow, error) {
@@ -284,6 +397,7 @@ func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (Updat
 		&i.Title,
 		&i.Locked,
 		&i.Order,
+		&i.Definition,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -294,7 +408,7 @@ const updateTitle = `-- name: UpdateTitle :one
 UPDATE views
 SET title = $3, updated_at = now()
 WHERE id = $1 AND form_id = $2
-RETURNING id, form_id, title, locked, "order", created_at, updated_at
+RETURNING id, form_id, title, locked, "order", definition, created_at, updated_at
 `
 
 type UpdateTitleParams struct {
@@ -312,6 +426,7 @@ func (q *Queries) UpdateTitle(ctx context.Context, arg UpdateTitleParams) (View,
 		&i.Title,
 		&i.Locked,
 		&i.Order,
+		&i.Definition,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -0,0 +1,297 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"NYCU-SDC/core-system-backend/internal/form/question"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// sqlComparisons are the SQL operators of the ordering operators other than between
+var sqlComparisons = map[question.Operator]string{
+	question.OperatorEq:  "=",
+	question.OperatorNeq: "<>",
+	question.OperatorGt:  ">",
+	question.OperatorGte: ">=",
+	question.OperatorLt:  "<",
+	question.OperatorLte: "<=",
+}
+
+// numericText matches text that strconv.ParseFloat reads as a decimal number
+const numericText = `'^\s*[-+]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?\s*$'`
+
+// responseQuery collects the joins and positional arguments of the SQL that selects the responses of a
+// form for a view. $1 is the form ID.
+type responseQuery struct {
+	args    []any
+	joins   []string
+	aliases map[uuid.UUID]string
+}
+
+// responseSQL is a compiled view: the FROM and WHERE clauses that match its filters and the ORDER BY
+// clause of its sort keys, with the arguments of both. The responses are selected as fr with the columns
+// of ListResponsesByFormID.
+type responseSQL struct {
+	from    string
+	orderBy string
+	args    []any
+}
+
+// arg adds a positional argument and returns its placeholder
+func (q *responseQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// answer joins the answers to a question once and returns the answer as jsonb; it is NULL for responses
+// that did not answer the question
+func (q *responseQuery) answer(questionID uuid.UUID) string {
+	alias, ok := q.aliases[questionID]
+	if !ok {
+		alias = "a" + strconv.Itoa(len(q.aliases))
+		q.aliases[questionID] = alias
+		q.joins = append(q.joins, fmt.Sprintf("LEFT JOIN answers %s ON %s.response_id = fr.id AND %s.question_id = %s", alias, alias, alias, q.arg(questionID)))
+	}
+	return "(" + alias + ".value::jsonb)"
+}
+
+// sql compiles the filters and sort keys of the definition for the responses of a form
+func (c compiledDefinition) sql(formID uuid.UUID) responseSQL {
+	q := &responseQuery{aliases: make(map[uuid.UUID]string)}
+	q.arg(formID)
+
+	predicates := make([]string, 0, len(c.filters))
+	for _, filter := range c.filters {
+		predicates = append(predicates, filter(q))
+	}
+
+	terms := make([]string, 0, len(c.sort)+2)
+	for _, key := range c.sort {
+		terms = append(terms, key(q))
+	}
+	if len(c.sort) == 0 {
+		terms = append(terms, "fr.submitted_at DESC NULLS LAST")
+	}
+	// Break ties by creation time and ID so pages are stable
+	terms = append(terms, "fr.created_at ASC", "fr.id ASC")
+
+	var from strings.Builder
+	from.WriteString("FROM (" + listResponsesByFormID + ") fr")
+	for _, join := range q.joins {
+		from.WriteString("\n" + join)
+	}
+	if len(predicates) > 0 {
+		from.WriteString("\nWHERE " + strings.Join(predicates, "\nAND "))
+	}
+
+	return responseSQL{
+		from:    from.String(),
+		orderBy: "ORDER BY " + strings.Join(terms, ", "),
+		args:    q.args,
+	}
+}
+
+// compareSQL applies an ordering operator to expr; high is only used by between
+func compareSQL(q *responseQuery, expr string, operator question.Operator, low, high any) string {
+	if operator == question.OperatorBetween {
+		return fmt.Sprintf("%s BETWEEN %s AND %s", expr, q.arg(low), q.arg(high))
+	}
+	return fmt.Sprintf("%s %s %s", expr, sqlComparisons[operator], q.arg(low))
+}
+
+// compileAnswerFilter returns the SQL predicate of an answer filter whose operand has been validated by the
+// question. The predicates decode the stored answers the same way as question.Answerable.Compare, and
+// responses without an answer only match isEmpty.
+func compileAnswerFilter(answerable question.Answerable, operator question.Operator, operand json.RawMessage) (func(q *responseQuery) string, error) {
+	var match func(q *responseQuery, answer string) string
+	var err error
+
+	switch answerable.Question().Type {
+	case question.QuestionTypeShortText, question.QuestionTypeLongText, question.QuestionTypeHyperlink:
+		match, err = textMatch("value", operator, operand)
+	case question.QuestionTypeOauthConnect:
+		// An account is connected when the provider returned an ID
+		match, err = textMatch("providerId", operator, operand)
+	case question.QuestionTypeSingleChoice, question.QuestionTypeDropdown:
+		match, err = choiceMatch(operator, operand)
+	case question.QuestionTypeMultipleChoice, question.QuestionTypeDetailedMultipleChoice:
+		match, err = listMatch("choices", operator, operand)
+	case question.QuestionTypeRanking:
+		match, err = listMatch("rankedChoices", operator, operand)
+	case question.QuestionTypeUploadFile:
+		match, err = listMatch("files", operator, operand)
+	case question.QuestionTypeLinearScale, question.QuestionTypeRating:
+		low, high, parseErr := question.ParseNumberOperand(operator, operand)
+		match, err = func(q *responseQuery, answer string) string {
+			return compareSQL(q, fmt.Sprintf("(%s->>'value')::numeric", answer), operator, low, high)
+		}, parseErr
+	case question.QuestionTypeDate:
+		low, high, parseErr := question.ParseDateOperand(operator, operand)
+		match, err = func(q *responseQuery, answer string) string {
+			return compareSQL(q, dateSQL(answer), operator, pgtype.Date{Time: low, Valid: true}, pgtype.Date{Time: high, Valid: true})
+		}, parseErr
+	default:
+		return nil, fmt.Errorf("question type '%s' cannot be filtered", answerable.Question().Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	questionID := answerable.Question().ID
+	return func(q *responseQuery) string {
+		answer := q.answer(questionID)
+		if operator == question.OperatorIsEmpty {
+			return fmt.Sprintf("(%s IS NULL OR %s)", answer, match(q, answer))
+		}
+		return fmt.Sprintf("(%s IS NOT NULL AND %s)", answer, match(q, answer))
+	}, nil
+}
+
+// textMatch compares a text field of an answer; a missing field is empty text
+func textMatch(field string, operator question.Operator, operand json.RawMessage) (func(q *responseQuery, answer string) string, error) {
+	if operator == question.OperatorIsEmpty {
+		return func(q *responseQuery, answer string) string {
+			return fmt.Sprintf(`coalesce(%s->>'%s', '') ~ '^\s*$'`, answer, field)
+		}, nil
+	}
+
+	var target string
+	err := json.Unmarshal(operand, &target)
+	if err != nil {
+		return nil, fmt.Errorf("operator '%s' requires a string value", operator)
+	}
+
+	return func(q *responseQuery, answer string) string {
+		value := fmt.Sprintf("coalesce(%s->>'%s', '')", answer, field)
+		if operator == question.OperatorContains {
+			return fmt.Sprintf("strpos(%s, %s) > 0", value, q.arg(target))
+		}
+		return fmt.Sprintf("%s %s %s", value, sqlComparisons[operator], q.arg(target))
+	}, nil
+}
+
+// choiceMatch compares the selected choice of a single choice answer
+func choiceMatch(operator question.Operator, operand json.RawMessage) (func(q *responseQuery, answer string) string, error) {
+	var target uuid.UUID
+	err := json.Unmarshal(operand, &target)
+	if err != nil {
+		return nil, fmt.Errorf("operator '%s' requires a choice option ID", operator)
+	}
+
+	return func(q *responseQuery, answer string) string {
+		return fmt.Sprintf("coalesce(%s->>'choiceId', '') %s %s", answer, sqlComparisons[operator], q.arg(target.String()))
+	}, nil
+}
+
+// listMatch compares the selected choices or uploaded files in the array field of an answer
+func listMatch(field string, operator question.Operator, operand json.RawMessage) (func(q *responseQuery, answer string) string, error) {
+	switch operator {
+	case question.OperatorIsEmpty:
+		return func(q *responseQuery, answer string) string {
+			return arrayLengthSQL(answer, field) + " = 0"
+		}, nil
+	case question.OperatorCountGte:
+		count, err := question.ParseCountOperand(operand)
+		if err != nil {
+			return nil, err
+		}
+		return func(q *responseQuery, answer string) string {
+			return arrayLengthSQL(answer, field) + " >= " + q.arg(count)
+		}, nil
+	}
+
+	var target uuid.UUID
+	err := json.Unmarshal(operand, &target)
+	if err != nil {
+		return nil, fmt.Errorf("operator '%s' requires a choice option ID", operator)
+	}
+
+	return func(q *responseQuery, answer string) string {
+		return fmt.Sprintf("coalesce(%s->'%s', '[]') @> jsonb_build_array(jsonb_build_object('choiceId', %s::text))", answer, field, q.arg(target.String()))
+	}, nil
+}
+
+// arrayLengthSQL is the length of the array field of an answer, where a missing or null field is empty
+func arrayLengthSQL(answer, field string) string {
+	array := fmt.Sprintf("%s->'%s'", answer, field)
+	return fmt.Sprintf("coalesce(jsonb_array_length(CASE WHEN jsonb_typeof(%s) = 'array' THEN %s END), 0)", array, array)
+}
+
+// dateSQL is a date answer as a date, defaulting missing components the same way as question.Date.Compare
+func dateSQL(answer string) string {
+	return fmt.Sprintf("make_date(coalesce((%s->>'year')::int, 1970), coalesce((%s->>'month')::int, 1), coalesce((%s->>'day')::int, 1))", answer, answer, answer)
+}
+
+// compileSortKey returns the ORDER BY terms of a sort key, placing responses without a value last in
+// either direction
+func compileSortKey(key SortKey, answerableMap map[string]question.Answerable) func(q *responseQuery) string {
+	direction := " ASC"
+	if key.Direction == DirectionDesc {
+		direction = " DESC"
+	}
+
+	switch key.Field {
+	case FieldSubmittedAt:
+		return func(q *responseQuery) string { return "fr.submitted_at" + direction + " NULLS LAST" }
+	case FieldCreatedAt:
+		return func(q *responseQuery) string { return "fr.created_at" + direction + " NULLS LAST" }
+	case FieldUpdatedAt:
+		return func(q *responseQuery) string { return "fr.updated_at" + direction + " NULLS LAST" }
+	case FieldProgress:
+		return func(q *responseQuery) string { return `fr.progress::text COLLATE "C"` + direction }
+	}
+
+	answerable := answerableMap[key.QuestionID.String()]
+	return func(q *responseQuery) string {
+		answer := q.answer(answerable.Question().ID)
+		value, textual := sortValueSQL(answerable.Question().Type, answer)
+		if !textual {
+			return value + direction + " NULLS LAST"
+		}
+
+		// Text is compared as numbers when it is numeric, with numbers before other text
+		return fmt.Sprintf(`%s IS NULL, CASE WHEN %s ~ %s THEN (%s)::numeric END%s NULLS LAST, %s COLLATE "C"%s`,
+			value, value, numericText, value, direction, value, direction)
+	}
+}
+
+// sortValueSQL returns the value a response is sorted by for an answer, and whether it is text. Text values
+// are the display values of question.Answerable.DisplayValue, with empty text as no value; scales and
+// ratings sort by their number, dates by their date and uploads by their number of files.
+func sortValueSQL(questionType question.QuestionType, answer string) (string, bool) {
+	choiceName := func(choice string) string {
+		return fmt.Sprintf("coalesce(nullif(%s->'snapshot'->>'otherText', ''), %s->'snapshot'->>'name', '')", choice, choice)
+	}
+	joinChoices := func(field, separator, order string) string {
+		array := fmt.Sprintf("%s->'%s'", answer, field)
+		return fmt.Sprintf("(SELECT nullif(string_agg(%s, '%s' ORDER BY %s), '') FROM jsonb_array_elements(CASE WHEN jsonb_typeof(%s) = 'array' THEN %s END) WITH ORDINALITY AS choice(c, n))",
+			choiceName("c"), separator, order, array, array)
+	}
+
+	switch questionType {
+	case question.QuestionTypeSingleChoice, question.QuestionTypeDropdown:
+		return fmt.Sprintf("nullif(%s, '')", choiceName(answer)), true
+	case question.QuestionTypeMultipleChoice, question.QuestionTypeDetailedMultipleChoice:
+		return joinChoices("choices", ", ", "n"), true
+	case question.QuestionTypeRanking:
+		return joinChoices("rankedChoices", " > ", "(c->>'rank')::int, n"), true
+	case question.QuestionTypeLinearScale, question.QuestionTypeRating:
+		return fmt.Sprintf("(%s->>'value')::numeric", answer), false
+	case question.QuestionTypeDate:
+		return fmt.Sprintf("CASE WHEN coalesce(%s->>'year', %s->>'month', %s->>'day') IS NOT NULL THEN %s END", answer, answer, answer, dateSQL(answer)), false
+	case question.QuestionTypeUploadFile:
+		return fmt.Sprintf("CASE WHEN %s IS NOT NULL THEN %s END", answer, arrayLengthSQL(answer, "files")), false
+	case question.QuestionTypeOauthConnect:
+		username := fmt.Sprintf("coalesce(%s->>'username', '')", answer)
+		email := fmt.Sprintf("coalesce(%s->>'email', '')", answer)
+		return fmt.Sprintf("nullif(CASE WHEN %s <> '' AND %s <> '' THEN %s || '(' || %s || ')' WHEN %s <> '' THEN %s ELSE %s END, '')",
+			username, email, username, email, username, username, email), true
+	default:
+		return fmt.Sprintf("nullif(%s->>'value', '')", answer), true
+	}
+}
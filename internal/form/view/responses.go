@@ -0,0 +1,222 @@
+package view
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// ResponseRow is a response of a form as shown by a view, with the display value of each visible
+// column keyed by question ID; unanswered questions have no value
+type ResponseRow struct {
+	Response ListResponsesByFormIDRow
+	Answers  map[string]*string
+}
+
+// ResponsePage is one page of the responses of a form with a view applied
+type ResponsePage struct {
+	Columns   []question.Answerable
+	Responses []ResponseRow
+	// Total is the number of responses that match the filters of the view
+	Total int
+}
+
+// Selection is the part of the responses of a form that a view shows
+type Selection struct {
+	// QuestionIDs are the visible columns in order
+	QuestionIDs []uuid.UUID
+	// ResponseIDs are the responses that match the filters of the view, in view order
+	ResponseIDs []uuid.UUID
+}
+
+// ListResponses returns the responses of a form filtered, sorted and paginated by the definition of a view.
+// References to questions deleted after the view was saved are ignored.
+func (s *Service) ListResponses(ctx context.Context, formID, viewID uuid.UUID, limit, offset int) (ResponsePage, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListResponses")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	compiled, err := s.compileView(traceCtx, formID, viewID)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePage{}, err
+	}
+	query := compiled.sql(formID)
+
+	var total int
+	err = s.db.QueryRow(traceCtx, "SELECT count(*) "+query.from, query.args...).Scan(&total)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "count responses for view")
+		span.RecordError(err)
+		return ResponsePage{}, err
+	}
+
+	args := append(slices.Clip(query.args), limit, offset)
+	rows, err := s.db.Query(traceCtx, fmt.Sprintf("SELECT fr.* %s\n%s\nLIMIT $%d OFFSET $%d", query.from, query.orderBy, len(args)-1, len(args)), args...)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "list responses for view")
+		span.RecordError(err)
+		return ResponsePage{}, err
+	}
+	responses, err := pgx.CollectRows(rows, pgx.RowToStructByPos[ListResponsesByFormIDRow])
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "list responses for view")
+		span.RecordError(err)
+		return ResponsePage{}, err
+	}
+
+	answers, err := s.listAnswers(traceCtx, formID, responses)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePage{}, err
+	}
+
+	page := ResponsePage{
+		Columns:   compiled.columns,
+		Responses: make([]ResponseRow, 0, len(responses)),
+		Total:     total,
+	}
+	for _, response := range responses {
+		responseRow := ResponseRow{
+			Response: response,
+			Answers:  make(map[string]*string, len(compiled.columns)),
+		}
+		for _, column := range compiled.columns {
+			questionID := column.Question().ID
+			raw, ok := answers[response.ID][questionID]
+			if !ok {
+				responseRow.Answers[questionID.String()] = nil
+				continue
+			}
+
+			display, err := column.DisplayValue(raw)
+			if err != nil {
+				span.RecordError(err)
+				return ResponsePage{}, err
+			}
+			responseRow.Answers[questionID.String()] = &display
+		}
+		page.Responses = append(page.Responses, responseRow)
+	}
+
+	logger.Debug("Listed responses with view",
+		zap.String("formId", formID.String()),
+		zap.String("viewId", viewID.String()),
+		zap.Int("total", page.Total),
+		zap.Int("returned", len(page.Responses)),
+	)
+
+	return page, nil
+}
+
+// Select returns the questions and responses of a form that a view shows, without paginating them
+func (s *Service) Select(ctx context.Context, formID, viewID uuid.UUID) (Selection, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Select")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	compiled, err := s.compileView(traceCtx, formID, viewID)
+	if err != nil {
+		span.RecordError(err)
+		return Selection{}, err
+	}
+	query := compiled.sql(formID)
+
+	rows, err := s.db.Query(traceCtx, "SELECT fr.id "+query.from+"\n"+query.orderBy, query.args...)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "select responses for view")
+		span.RecordError(err)
+		return Selection{}, err
+	}
+	responseIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "select responses for view")
+		span.RecordError(err)
+		return Selection{}, err
+	}
+
+	selection := Selection{
+		QuestionIDs: make([]uuid.UUID, 0, len(compiled.columns)),
+		ResponseIDs: responseIDs,
+	}
+	for _, column := range compiled.columns {
+		selection.QuestionIDs = append(selection.QuestionIDs, column.Question().ID)
+	}
+
+	return selection, nil
+}
+
+// compileView compiles the definition of a view against the questions of its form.
+// References to questions deleted after the view was saved are ignored.
+func (s *Service) compileView(ctx context.Context, formID, viewID uuid.UUID) (compiledDefinition, error) {
+	v, err := s.Get(ctx, formID, viewID)
+	if err != nil {
+		return compiledDefinition{}, err
+	}
+
+	definition, err := parseDefinition(v.Definition)
+	if err != nil {
+		return compiledDefinition{}, fmt.Errorf("%w: %w", internal.ErrInternalServerError, err)
+	}
+
+	orderedQuestions, answerableMap, err := s.listQuestions(ctx, formID)
+	if err != nil {
+		return compiledDefinition{}, err
+	}
+
+	return definition.prune(answerableMap).compile(orderedQuestions, answerableMap)
+}
+
+// listQuestions returns the questions of a form in section and question order, and keyed by question ID
+func (s *Service) listQuestions(ctx context.Context, formID uuid.UUID) ([]question.Answerable, map[string]question.Answerable, error) {
+	sections, err := s.questionStore.ListSectionsWithAnswersByFormID(ctx, formID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orderedQuestions := make([]question.Answerable, 0)
+	answerableMap := make(map[string]question.Answerable)
+	for _, section := range sections {
+		for _, answerable := range section.AnswerableList {
+			orderedQuestions = append(orderedQuestions, answerable)
+			answerableMap[answerable.Question().ID.String()] = answerable
+		}
+	}
+
+	return orderedQuestions, answerableMap, nil
+}
+
+// listAnswers returns the raw answers of a page of responses keyed by response and question ID
+func (s *Service) listAnswers(ctx context.Context, formID uuid.UUID, responses []ListResponsesByFormIDRow) (map[uuid.UUID]map[uuid.UUID][]byte, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	answers := make(map[uuid.UUID]map[uuid.UUID][]byte, len(responses))
+	if len(responses) == 0 {
+		return answers, nil
+	}
+
+	responseIDs := make([]uuid.UUID, len(responses))
+	for i, response := range responses {
+		responseIDs[i] = response.ID
+		answers[response.ID] = make(map[uuid.UUID][]byte)
+	}
+
+	rows, err := s.queries.ListAnswersByResponseIDs(ctx, responseIDs)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "answers", "form_id", formID.String(), logger, "list answers for view")
+	}
+	for _, row := range rows {
+		answers[row.ResponseID][row.QuestionID] = row.Value
+	}
+
+	return answers, nil
+}
@@ -4,6 +4,7 @@ CREATE TABLE IF NOT EXISTS views (
     title      TEXT NOT NULL,
     locked     BOOLEAN NOT NULL DEFAULT FALSE,
     "order"    INTEGER NOT NULL,
+    definition JSONB NOT NULL DEFAULT '{}'::jsonb,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
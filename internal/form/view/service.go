@@ -2,10 +2,12 @@ package view
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -30,30 +32,39 @@ type Querier interface {
 	UpdateTitle(ctx context.Context, arg UpdateTitleParams) (View, error)
 	UpdateOrder(ctx context.Context, arg UpdateOrderParams) (UpdateOrderRow, error)
 	UpdateLocked(ctx context.Context, arg UpdateLockedParams) (View, error)
+	UpdateDefinitionIfUnlocked(ctx context.Context, arg UpdateDefinitionIfUnlockedParams) (View, error)
 	DeleteIfUnlocked(ctx context.Context, arg DeleteIfUnlockedParams) (DeleteIfUnlockedRow, error)
 	Exists(ctx context.Context, arg ExistsParams) (bool, error)
 	FormExists(ctx context.Context, id uuid.UUID) (bool, error)
 	ShiftOrders(ctx context.Context, arg ShiftOrdersParams) error
+	ListResponsesByFormID(ctx context.Context, formID uuid.UUID) ([]ListResponsesByFormIDRow, error)
+	ListAnswersByResponseIDs(ctx context.Context, responseIds []uuid.UUID) ([]ListAnswersByResponseIDsRow, error)
 	WithTx(tx pgx.Tx) *Queries
 }
 
+type QuestionStore interface {
+	ListSectionsWithAnswersByFormID(ctx context.Context, formID uuid.UUID) ([]question.SectionWithAnswerableList, error)
+}
+
 type TxBeginner interface {
 	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
 }
 
 type Service struct {
-	db      DBTX
-	logger  *zap.Logger
-	queries Querier
-	tracer  trace.Tracer
+	db            DBTX
+	logger        *zap.Logger
+	queries       Querier
+	questionStore QuestionStore
+	tracer        trace.Tracer
 }
 
-func NewService(logger *zap.Logger, db DBTX) *Service {
+func NewService(logger *zap.Logger, db DBTX, questionStore QuestionStore) *Service {
 	return &Service{
-		db:      db,
-		logger:  logger,
-		queries: New(db),
-		tracer:  otel.Tracer("view/service"),
+		db:            db,
+		logger:        logger,
+		queries:       New(db),
+		questionStore: questionStore,
+		tracer:        otel.Tracer("view/service"),
 	}
 }
 
@@ -130,9 +141,10 @@ func (s *Service) Create(ctx context.Context, formID uuid.UUID) (View, error) {
 	}
 
 	newView, err := s.queries.Create(traceCtx, CreateParams{
-		FormID: formID,
-		Title:  title,
-		Order:  order + 1,
+		FormID:     formID,
+		Title:      title,
+		Order:      order + 1,
+		Definition: emptyDefinition,
 	})
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "create view")
@@ -253,7 +265,60 @@ func (s *Service) UpdateOrder(ctx context.Context, formID, viewID uuid.UUID, new
 	return View(row), nil
 }
 
-// Lock sets locked=true on the view.
+// UpdateDefinition replaces the definition of an unlocked view after checking it against the questions of the form.
+func (s *Service) UpdateDefinition(ctx context.Context, formID, viewID uuid.UUID, definition Definition) (View, error) {
+	traceCtx, span := s.tracer.Start(ctx, "UpdateDefinition")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	orderedQuestions, answerableMap, err := s.listQuestions(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return View{}, err
+	}
+
+	_, err = definition.compile(orderedQuestions, answerableMap)
+	if err != nil {
+		span.RecordError(err)
+		return View{}, err
+	}
+
+	data, err := json.Marshal(definition.normalize())
+	if err != nil {
+		span.RecordError(err)
+		return View{}, err
+	}
+
+	v, err := s.queries.UpdateDefinitionIfUnlocked(traceCtx, UpdateDefinitionIfUnlockedParams{
+		ID:         viewID,
+		FormID:     formID,
+		Definition: data,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			exist, exErr := s.queries.Exists(traceCtx, ExistsParams{
+				ID:     viewID,
+				FormID: formID,
+			})
+			if exErr != nil {
+				exErr = databaseutil.WrapDBErrorWithKeyValue(exErr, "view", "id", viewID.String(), logger, "check view exists after update definition failed")
+				span.RecordError(exErr)
+				return View{}, exErr
+			}
+			if !exist {
+				return View{}, internal.ErrViewNotFound
+			}
+			return View{}, internal.ErrViewDefinitionLocked
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "view", "id", viewID.String(), logger, "update view definition")
+		span.RecordError(err)
+		return View{}, err
+	}
+
+	return v, nil
+}
+
+// Lock sets locked=true on the view. A locked view cannot be deleted and its definition cannot be edited.
 func (s *Service) Lock(ctx context.Context, formID, viewID uuid.UUID) (View, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Lock")
 	defer span.End()
@@ -299,7 +364,7 @@ func (s *Service) Unlock(ctx context.Context, formID, viewID uuid.UUID) (View, e
 	return v, nil
 }
 
-// Duplicate creates a copy of the view with title, placed immediately after the original in order.
+// Duplicate creates a copy of the view and its definition with title, placed immediately after the original in order.
 func (s *Service) Duplicate(ctx context.Context, formID, viewID uuid.UUID) (View, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Duplicate")
 	defer span.End()
@@ -358,9 +423,10 @@ func (s *Service) Duplicate(ctx context.Context, formID, viewID uuid.UUID) (View
 	}
 
 	newView, err := qtx.Create(traceCtx, CreateParams{
-		FormID: formID,
-		Title:  newTitle,
-		Order:  original.Order + 1,
+		FormID:     formID,
+		Title:      newTitle,
+		Order:      original.Order + 1,
+		Definition: original.Definition,
 	})
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "create duplicate view")
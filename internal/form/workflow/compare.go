@@ -1,8 +1,11 @@
 package workflow
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 // nodeStructure holds the structural fields of a workflow node used for equality.
@@ -14,6 +17,9 @@ type nodeStructure struct {
 	NextTrue      string
 	NextFalse     string
 	ConditionRule conditionRuleStructure
+	// Cases is the JSON encoding of the cases of a switch node
+	Cases   string
+	Default string
 }
 
 type conditionRuleStructure struct {
@@ -21,11 +27,17 @@ type conditionRuleStructure struct {
 	Question       string
 	Pattern        string
 	ChoiceOptionID string
+	Operator       string
+	// Value is the JSON encoding of the operand of a typed operator
+	Value string
+	// Group is the JSON encoding of the all/any/not members of a compound rule
+	Group string
 }
 
 // structurallyEqual reports whether two workflow JSON payloads are
 // structurally equal: same node set (by id), same type, edges (next/nextTrue/
-// nextFalse), and conditionRule (source, question, pattern, choiceOptionId).
+// nextFalse/default), conditionRule (source, question, pattern, choiceOptionId,
+// operator, value and all/any/not groups) and switch cases.
 // Label and other display-only fields are ignored so that question/section
 // changes that only affect labels do not count as workflow changes.
 func structurallyEqual(current, incoming []byte) (bool, error) {
@@ -82,6 +94,7 @@ func buildNodeStructureMap(nodes []map[string]any) map[string]nodeStructure {
 		next, _ := node["next"].(string)
 		nextTrue, _ := node["nextTrue"].(string)
 		nextFalse, _ := node["nextFalse"].(string)
+		defaultNext, _ := node["default"].(string)
 
 		rule := conditionRuleStructure{}
 		cr, ok := node["conditionRule"].(map[string]any)
@@ -90,6 +103,9 @@ func buildNodeStructureMap(nodes []map[string]any) map[string]nodeStructure {
 			rule.Question = strVal(cr, "question")
 			rule.Pattern = strVal(cr, "pattern")
 			rule.ChoiceOptionID = strVal(cr, "choiceOptionId")
+			rule.Operator = strVal(cr, "operator")
+			rule.Value = jsonVal(cr, "value")
+			rule.Group = groupVal(cr)
 		}
 
 		out[id] = nodeStructure{
@@ -99,6 +115,8 @@ func buildNodeStructureMap(nodes []map[string]any) map[string]nodeStructure {
 			NextTrue:      nextTrue,
 			NextFalse:     nextFalse,
 			ConditionRule: rule,
+			Cases:         jsonVal(node, "cases"),
+			Default:       defaultNext,
 		}
 	}
 	return out
@@ -117,14 +135,207 @@ func strVal(m map[string]any, key string) string {
 	return s
 }
 
+// jsonVal returns the JSON encoding of m[key], or an empty string if the key is missing
+func jsonVal(m map[string]any, key string) string {
+	v, ok := m[key]
+	if !ok {
+		return ""
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// groupVal returns a canonical encoding of the all/any/not members of a condition rule,
+// or an empty string for a leaf rule. encoding/json sorts map keys, so equal groups encode equally.
+func groupVal(rule map[string]any) string {
+	group := make(map[string]any)
+	for _, key := range []string{"all", "any", "not"} {
+		v, ok := rule[key]
+		if ok {
+			group[key] = v
+		}
+	}
+	if len(group) == 0 {
+		return ""
+	}
+
+	encoded, err := json.Marshal(group)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
 func nodeStructureEqual(a, b nodeStructure) bool {
 	if a.ID != b.ID || a.Type != b.Type ||
-		a.Next != b.Next || a.NextTrue != b.NextTrue || a.NextFalse != b.NextFalse {
+		a.Next != b.Next || a.NextTrue != b.NextTrue || a.NextFalse != b.NextFalse ||
+		a.Cases != b.Cases || a.Default != b.Default {
 		return false
 	}
 	crA, crB := a.ConditionRule, b.ConditionRule
 	return crA.Source == crB.Source &&
 		crA.Question == crB.Question &&
 		crA.Pattern == crB.Pattern &&
-		crA.ChoiceOptionID == crB.ChoiceOptionID
+		crA.ChoiceOptionID == crB.ChoiceOptionID &&
+		crA.Operator == crB.Operator &&
+		crA.Value == crB.Value &&
+		crA.Group == crB.Group
+}
+
+// Diff is the structural difference between two workflow versions.
+// Like structurallyEqual it ignores labels and other display-only fields.
+type Diff struct {
+	AddedNodes   []string     `json:"addedNodes"`
+	RemovedNodes []string     `json:"removedNodes"`
+	ChangedNodes []NodeChange `json:"changedNodes"`
+	AddedEdges   []Edge       `json:"addedEdges"`
+	RemovedEdges []Edge       `json:"removedEdges"`
+}
+
+// NodeChange lists the structural fields of a node that differ between two versions
+type NodeChange struct {
+	NodeID string   `json:"nodeId"`
+	Fields []string `json:"fields"`
+}
+
+// Edge is a reference from one node to another. Field is the node field holding the
+// reference: next, nextTrue, nextFalse, default or cases[i].next.
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Field string `json:"field"`
+}
+
+// structuralDiff compares two workflow JSON payloads and returns the nodes and edges that were
+// added, removed or changed from from to to. All lists are sorted and never nil.
+func structuralDiff(from, to []byte) (Diff, error) {
+	fromNodes, err := parseForCompare(from)
+	if err != nil {
+		return Diff{}, fmt.Errorf("from workflow: %w", err)
+	}
+	toNodes, err := parseForCompare(to)
+	if err != nil {
+		return Diff{}, fmt.Errorf("to workflow: %w", err)
+	}
+
+	fromStruct := buildNodeStructureMap(fromNodes)
+	toStruct := buildNodeStructureMap(toNodes)
+
+	diff := Diff{
+		AddedNodes:   []string{},
+		RemovedNodes: []string{},
+		ChangedNodes: []NodeChange{},
+		AddedEdges:   []Edge{},
+		RemovedEdges: []Edge{},
+	}
+
+	for id, fromNode := range fromStruct {
+		toNode, ok := toStruct[id]
+		if !ok {
+			diff.RemovedNodes = append(diff.RemovedNodes, id)
+			continue
+		}
+		fields := changedFields(fromNode, toNode)
+		if len(fields) > 0 {
+			diff.ChangedNodes = append(diff.ChangedNodes, NodeChange{NodeID: id, Fields: fields})
+		}
+	}
+	for id := range toStruct {
+		_, ok := fromStruct[id]
+		if !ok {
+			diff.AddedNodes = append(diff.AddedNodes, id)
+		}
+	}
+
+	fromEdges := collectEdges(fromNodes)
+	toEdges := collectEdges(toNodes)
+	for edge := range fromEdges {
+		_, ok := toEdges[edge]
+		if !ok {
+			diff.RemovedEdges = append(diff.RemovedEdges, edge)
+		}
+	}
+	for edge := range toEdges {
+		_, ok := fromEdges[edge]
+		if !ok {
+			diff.AddedEdges = append(diff.AddedEdges, edge)
+		}
+	}
+
+	slices.Sort(diff.AddedNodes)
+	slices.Sort(diff.RemovedNodes)
+	slices.SortFunc(diff.ChangedNodes, func(a, b NodeChange) int {
+		return strings.Compare(a.NodeID, b.NodeID)
+	})
+	slices.SortFunc(diff.AddedEdges, compareEdges)
+	slices.SortFunc(diff.RemovedEdges, compareEdges)
+
+	return diff, nil
+}
+
+// changedFields returns the names of the structural fields that differ between a and b
+func changedFields(a, b nodeStructure) []string {
+	var fields []string
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if a.Next != b.Next {
+		fields = append(fields, "next")
+	}
+	if a.NextTrue != b.NextTrue {
+		fields = append(fields, "nextTrue")
+	}
+	if a.NextFalse != b.NextFalse {
+		fields = append(fields, "nextFalse")
+	}
+	if a.ConditionRule != b.ConditionRule {
+		fields = append(fields, "conditionRule")
+	}
+	if a.Cases != b.Cases {
+		fields = append(fields, "cases")
+	}
+	if a.Default != b.Default {
+		fields = append(fields, "default")
+	}
+	return fields
+}
+
+// collectEdges returns the set of edges of the nodes; empty references are skipped
+func collectEdges(nodes []map[string]any) map[Edge]struct{} {
+	edges := make(map[Edge]struct{})
+	add := func(from, to, field string) {
+		if from == "" || to == "" {
+			return
+		}
+		edges[Edge{From: from, To: to, Field: field}] = struct{}{}
+	}
+
+	for _, node := range nodes {
+		id, _ := node["id"].(string)
+		for _, field := range []string{"next", "nextTrue", "nextFalse", "default"} {
+			add(id, strVal(node, field), field)
+		}
+
+		cases, _ := node["cases"].([]any)
+		for i, c := range cases {
+			mapCase, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			add(id, strVal(mapCase, "next"), fmt.Sprintf("cases[%d].next", i))
+		}
+	}
+	return edges
+}
+
+func compareEdges(a, b Edge) int {
+	return cmp.Or(
+		strings.Compare(a.From, b.From),
+		strings.Compare(a.Field, b.Field),
+		strings.Compare(a.To, b.To),
+	)
 }
@@ -109,6 +109,88 @@ func TestWorkflowStructurallyEqual(t *testing.T) {
 			}),
 			expected: false,
 		},
+		{
+			name: "condition compound rule with different members",
+			current: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": conditionID},
+				{"id": conditionID, "type": "condition", "label": "Cond",
+					"nextTrue": endID, "nextFalse": endID,
+					"conditionRule": map[string]any{"all": []any{
+						map[string]any{"source": "CHOICE", "question": questionID, "pattern": "^a$"},
+						map[string]any{"source": "CHOICE", "question": questionID, "pattern": "^b$"},
+					}}},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			incoming: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": conditionID},
+				{"id": conditionID, "type": "condition", "label": "Cond",
+					"nextTrue": endID, "nextFalse": endID,
+					"conditionRule": map[string]any{"any": []any{
+						map[string]any{"source": "CHOICE", "question": questionID, "pattern": "^a$"},
+						map[string]any{"source": "CHOICE", "question": questionID, "pattern": "^b$"},
+					}}},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			expected: false,
+		},
+		{
+			name: "condition different operator value",
+			current: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": conditionID},
+				{"id": conditionID, "type": "condition", "label": "Cond",
+					"nextTrue": endID, "nextFalse": endID,
+					"conditionRule": map[string]any{"source": "NONCHOICE", "question": questionID, "operator": "gte", "value": 4}},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			incoming: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": conditionID},
+				{"id": conditionID, "type": "condition", "label": "Cond",
+					"nextTrue": endID, "nextFalse": endID,
+					"conditionRule": map[string]any{"source": "NONCHOICE", "question": questionID, "operator": "gte", "value": 3}},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			expected: false,
+		},
+		{
+			name: "switch same cases different labels",
+			current: mustMarshal(t, []map[string]any{
+				{"id": conditionID, "type": "switch", "label": "Route", "cases": []any{
+					map[string]any{"rule": map[string]any{"source": "CHOICE", "question": questionID, "pattern": "a"}, "next": sectionID},
+				}, "default": endID},
+			}),
+			incoming: mustMarshal(t, []map[string]any{
+				{"id": conditionID, "type": "switch", "label": "Another Route", "cases": []any{
+					map[string]any{"next": sectionID, "rule": map[string]any{"pattern": "a", "question": questionID, "source": "CHOICE"}},
+				}, "default": endID},
+			}),
+			expected: true,
+		},
+		{
+			name: "switch different case order",
+			current: mustMarshal(t, []map[string]any{
+				{"id": conditionID, "type": "switch", "cases": []any{
+					map[string]any{"rule": map[string]any{"source": "CHOICE", "question": questionID, "pattern": "a"}, "next": sectionID},
+					map[string]any{"rule": map[string]any{"source": "CHOICE", "question": questionID, "pattern": "b"}, "next": endID},
+				}, "default": endID},
+			}),
+			incoming: mustMarshal(t, []map[string]any{
+				{"id": conditionID, "type": "switch", "cases": []any{
+					map[string]any{"rule": map[string]any{"source": "CHOICE", "question": questionID, "pattern": "b"}, "next": endID},
+					map[string]any{"rule": map[string]any{"source": "CHOICE", "question": questionID, "pattern": "a"}, "next": sectionID},
+				}, "default": endID},
+			}),
+			expected: false,
+		},
+		{
+			name: "switch different default",
+			current: mustMarshal(t, []map[string]any{
+				{"id": conditionID, "type": "switch", "cases": []any{}, "default": endID},
+			}),
+			incoming: mustMarshal(t, []map[string]any{
+				{"id": conditionID, "type": "switch", "cases": []any{}, "default": sectionID},
+			}),
+			expected: false,
+		},
 		{
 			name:        "invalid current JSON",
 			current:     []byte(`not json`),
@@ -135,6 +217,141 @@ func TestWorkflowStructurallyEqual(t *testing.T) {
 	}
 }
 
+func TestStructuralDiff(t *testing.T) {
+	startID := uuid.New().String()
+	endID := uuid.New().String()
+	sectionAID := uuid.New().String()
+	sectionBID := uuid.New().String()
+	switchID := uuid.New().String()
+	questionID := uuid.New().String()
+
+	base := []map[string]any{
+		{"id": startID, "type": "start", "label": "Start", "next": sectionAID},
+		{"id": sectionAID, "type": "section", "label": "A", "next": endID},
+		{"id": endID, "type": "end", "label": "End"},
+	}
+
+	testCases := []struct {
+		name        string
+		from        []byte
+		to          []byte
+		expected    Diff
+		expectedErr bool
+	}{
+		{
+			name: "label only change is not a difference",
+			from: mustMarshal(t, base),
+			to: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Begin", "next": sectionAID},
+				{"id": sectionAID, "type": "section", "label": "Renamed", "next": endID},
+				{"id": endID, "type": "end", "label": "Finish"},
+			}),
+			expected: Diff{
+				AddedNodes:   []string{},
+				RemovedNodes: []string{},
+				ChangedNodes: []NodeChange{},
+				AddedEdges:   []Edge{},
+				RemovedEdges: []Edge{},
+			},
+		},
+		{
+			name: "inserted section",
+			from: mustMarshal(t, base),
+			to: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": sectionAID},
+				{"id": sectionAID, "type": "section", "label": "A", "next": sectionBID},
+				{"id": sectionBID, "type": "section", "label": "B", "next": endID},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			expected: Diff{
+				AddedNodes:   []string{sectionBID},
+				RemovedNodes: []string{},
+				ChangedNodes: []NodeChange{{NodeID: sectionAID, Fields: []string{"next"}}},
+				AddedEdges: []Edge{
+					{From: sectionAID, To: sectionBID, Field: "next"},
+					{From: sectionBID, To: endID, Field: "next"},
+				},
+				RemovedEdges: []Edge{{From: sectionAID, To: endID, Field: "next"}},
+			},
+		},
+		{
+			name: "section replaced by switch",
+			from: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": sectionAID},
+				{"id": sectionAID, "type": "section", "label": "A", "next": sectionBID},
+				{"id": sectionBID, "type": "section", "label": "B", "next": endID},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			to: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": sectionAID},
+				{"id": sectionAID, "type": "section", "label": "A", "next": switchID},
+				{
+					"id":    switchID,
+					"type":  "switch",
+					"label": "Switch",
+					"cases": []map[string]any{
+						{"rule": map[string]any{"question": questionID, "operator": "isEmpty"}, "next": endID},
+					},
+					"default": sectionBID,
+				},
+				{"id": sectionBID, "type": "section", "label": "B", "next": endID},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			expected: Diff{
+				AddedNodes:   []string{switchID},
+				RemovedNodes: []string{},
+				ChangedNodes: []NodeChange{{NodeID: sectionAID, Fields: []string{"next"}}},
+				AddedEdges: []Edge{
+					{From: sectionAID, To: switchID, Field: "next"},
+					{From: switchID, To: endID, Field: "cases[0].next"},
+					{From: switchID, To: sectionBID, Field: "default"},
+				},
+				RemovedEdges: []Edge{{From: sectionAID, To: sectionBID, Field: "next"}},
+			},
+		},
+		{
+			name: "removed section",
+			from: mustMarshal(t, []map[string]any{
+				{"id": startID, "type": "start", "label": "Start", "next": sectionAID},
+				{"id": sectionAID, "type": "section", "label": "A", "next": endID},
+				{"id": sectionBID, "type": "section", "label": "B", "next": endID},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			to: mustMarshal(t, base),
+			expected: Diff{
+				AddedNodes:   []string{},
+				RemovedNodes: []string{sectionBID},
+				ChangedNodes: []NodeChange{},
+				AddedEdges:   []Edge{},
+				RemovedEdges: []Edge{{From: sectionBID, To: endID, Field: "next"}},
+			},
+		},
+		{
+			name:        "invalid from JSON",
+			from:        []byte(`not json`),
+			to:          mustMarshal(t, base),
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := structuralDiff(tc.from, tc.to)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// Edge order within a node depends on node IDs, so compare edges as sets
+			assert.Equal(t, tc.expected.AddedNodes, got.AddedNodes)
+			assert.Equal(t, tc.expected.RemovedNodes, got.RemovedNodes)
+			assert.Equal(t, tc.expected.ChangedNodes, got.ChangedNodes)
+			assert.ElementsMatch(t, tc.expected.AddedEdges, got.AddedEdges)
+			assert.ElementsMatch(t, tc.expected.RemovedEdges, got.RemovedEdges)
+		})
+	}
+}
+
 func mustMarshal(t *testing.T, v any) []byte {
 	t.Helper()
 	b, err := json.Marshal(v)
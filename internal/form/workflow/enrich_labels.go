@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -58,7 +59,7 @@ func (s *Service) EnrichWorkflowResponse(
 			label := conditionLabelFromRule(ctx, nodes[i], s.questionStore)
 			nodes[i]["label"] = label
 		}
-		// START and END: leave label unchanged
+		// START, SWITCH and END: leave label unchanged
 	}
 
 	enriched, err := json.Marshal(nodes)
@@ -81,31 +82,93 @@ func conditionLabelFromRule(ctx context.Context, node map[string]any, questionSt
 		return fallback
 	}
 
+	description, ok := describeConditionRule(ctx, conditionRule, questionStore, false)
+	if !ok {
+		return fallback
+	}
+
+	return "When " + description
+}
+
+// describeConditionRule returns a human-readable description of a leaf rule, e.g.
+// "{title} matches {pattern}", or of an all/any/not group, e.g. "A matches x and not (B matches y)".
+// nested wraps group descriptions in parentheses. Returns false when any leaf cannot be described.
+func describeConditionRule(ctx context.Context, conditionRule map[string]any, questionStore QuestionStore, nested bool) (string, bool) {
+	notRule, ok := conditionRule["not"].(map[string]any)
+	if ok {
+		description, ok := describeConditionRule(ctx, notRule, questionStore, true)
+		if !ok {
+			return "", false
+		}
+		return "not " + description, true
+	}
+
+	groups := []struct {
+		operator string
+		joiner   string
+	}{
+		{operator: "all", joiner: " and "},
+		{operator: "any", joiner: " or "},
+	}
+	for _, group := range groups {
+		children, ok := conditionRule[group.operator].([]any)
+		if !ok {
+			continue
+		}
+
+		descriptions := make([]string, 0, len(children))
+		for _, child := range children {
+			childRule, ok := child.(map[string]any)
+			if !ok {
+				return "", false
+			}
+			description, ok := describeConditionRule(ctx, childRule, questionStore, true)
+			if !ok {
+				return "", false
+			}
+			descriptions = append(descriptions, description)
+		}
+		if len(descriptions) == 0 {
+			return "", false
+		}
+
+		description := strings.Join(descriptions, group.joiner)
+		if nested && len(descriptions) > 1 {
+			description = "(" + description + ")"
+		}
+		return description, true
+	}
+
+	return describeLeafConditionRule(ctx, conditionRule, questionStore)
+}
+
+func describeLeafConditionRule(ctx context.Context, conditionRule map[string]any, questionStore QuestionStore) (string, bool) {
 	// If no question, return fallback
 	questionIDStr, ok := conditionRule["question"].(string)
 	if !ok {
-		return fallback
+		return "", false
 	}
 	if questionIDStr == "" {
-		return fallback
+		return "", false
 	}
 
 	// Parse question ID
 	questionID, err := uuid.Parse(questionIDStr)
 	if err != nil {
-		return fallback
+		return "", false
 	}
 
-	// Parse pattern
+	// Parse pattern; a rule with a typed operator has no pattern
+	operator, _ := conditionRule["operator"].(string)
 	pattern, ok := conditionRule["pattern"].(string)
-	if !ok {
-		return fallback
+	if !ok && operator == "" {
+		return "", false
 	}
 
 	// Get question
 	answerable, err := questionStore.Get(ctx, questionID)
 	if err != nil {
-		return fallback
+		return "", false
 	}
 
 	// Get question title
@@ -118,11 +181,44 @@ func conditionLabelFromRule(ctx context.Context, node map[string]any, questionSt
 		title = questionIDStr
 	}
 
+	if operator != "" {
+		return fmt.Sprintf("%s %s", title, describeOperator(question.Operator(operator), conditionRule["value"])), true
+	}
+
 	// If pattern is empty, return formatted label without pattern
 	if pattern == "" {
-		return fmt.Sprintf("When %s", title)
+		return title, true
 	}
 
 	// If pattern is not empty, return formatted label with pattern
-	return fmt.Sprintf("When %s matches %s", title, pattern)
+	return fmt.Sprintf("%s matches %s", title, pattern), true
+}
+
+// describeOperator renders a typed operator and its value, e.g. "is at least 4" or "is between 2026-01-01 and 2026-06-30"
+func describeOperator(operator question.Operator, value any) string {
+	switch operator {
+	case question.OperatorIsEmpty:
+		return "is empty"
+	case question.OperatorBetween:
+		bounds, ok := value.([]any)
+		if ok && len(bounds) == 2 {
+			return fmt.Sprintf("is between %v and %v", bounds[0], bounds[1])
+		}
+	}
+
+	phrases := map[question.Operator]string{
+		question.OperatorEq:       "is",
+		question.OperatorNeq:      "is not",
+		question.OperatorGt:       "is greater than",
+		question.OperatorGte:      "is at least",
+		question.OperatorLt:       "is less than",
+		question.OperatorLte:      "is at most",
+		question.OperatorContains: "contains",
+		question.OperatorCountGte: "has at least",
+	}
+	phrase, ok := phrases[operator]
+	if !ok {
+		phrase = string(operator)
+	}
+	return fmt.Sprintf("%s %v", phrase, value)
 }
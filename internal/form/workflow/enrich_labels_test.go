@@ -72,7 +72,8 @@ func TestEnrichWorkflow_Labels(t *testing.T) {
 					},
 				})
 				q := question.Question{ID: questionID, Title: pgtype.Text{String: "Question One", Valid: true}}
-				answerable := question.NewShortText(q, formID)
+				answerable, err := question.NewShortText(q, formID)
+				require.NoError(t, err)
 				store := &mockQuestionStoreForEnrich{
 					questions: map[string]question.Answerable{
 						questionID.String(): answerable,
@@ -150,6 +151,23 @@ func TestEnrichWorkflow_Labels(t *testing.T) {
 }
 
 func TestConditionLabel_Rule(t *testing.T) {
+	formID := uuid.New()
+	question1ID := uuid.New()
+	question2ID := uuid.New()
+	question1, err := question.NewShortText(question.Question{ID: question1ID, Title: pgtype.Text{String: "Name", Valid: true}}, formID)
+	require.NoError(t, err)
+	question2, err := question.NewShortText(question.Question{ID: question2ID, Title: pgtype.Text{String: "Email", Valid: true}}, formID)
+	require.NoError(t, err)
+	store := &mockQuestionStoreForEnrich{
+		questions: map[string]question.Answerable{
+			question1ID.String(): question1,
+			question2ID.String(): question2,
+		},
+	}
+	leaf := func(questionID uuid.UUID, pattern string) map[string]any {
+		return map[string]any{"source": "NON_CHOICE", "question": questionID.String(), "pattern": pattern}
+	}
+
 	testCases := []struct {
 		name     string
 		node     map[string]any
@@ -166,6 +184,52 @@ func TestConditionLabel_Rule(t *testing.T) {
 			store:    nil,
 			expected: "No label",
 		},
+		{
+			name: "all group joins leaf descriptions with and",
+			node: map[string]any{
+				"conditionRule": map[string]any{
+					"all": []any{leaf(question1ID, "^a$"), leaf(question2ID, "@nycu")},
+				},
+			},
+			store:    store,
+			expected: "When Name matches ^a$ and Email matches @nycu",
+		},
+		{
+			name: "nested any and not groups are parenthesized",
+			node: map[string]any{
+				"conditionRule": map[string]any{
+					"all": []any{
+						leaf(question1ID, "^a$"),
+						map[string]any{"any": []any{leaf(question1ID, "^b$"), map[string]any{"not": leaf(question2ID, "@nycu")}}},
+					},
+				},
+			},
+			store:    store,
+			expected: "When Name matches ^a$ and (Name matches ^b$ or not Email matches @nycu)",
+		},
+		{
+			name: "typed operators are described in words",
+			node: map[string]any{
+				"conditionRule": map[string]any{
+					"all": []any{
+						map[string]any{"source": "NON_CHOICE", "question": question1ID.String(), "operator": "contains", "value": "Lin"},
+						map[string]any{"source": "NON_CHOICE", "question": question2ID.String(), "operator": "isEmpty"},
+					},
+				},
+			},
+			store:    store,
+			expected: "When Name contains Lin and Email is empty",
+		},
+		{
+			name: "group with unknown question keeps fallback",
+			node: map[string]any{
+				"conditionRule": map[string]any{
+					"any": []any{leaf(question1ID, "^a$"), leaf(uuid.New(), "^b$")},
+				},
+			},
+			store:    store,
+			expected: "No label",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -222,3 +286,7 @@ func (m *mockQuestionStoreForEnrich) Get(ctx context.Context, id uuid.UUID) (que
 func (m *mockQuestionStoreForEnrich) ListSections(ctx context.Context, formID uuid.UUID) (map[string]question.Section, error) {
 	return m.sections, nil
 }
+
+func (m *mockQuestionStoreForEnrich) GetAnswerableMapByFormID(ctx context.Context, formID uuid.UUID) (map[string]question.Answerable, error) {
+	return m.questions, nil
+}
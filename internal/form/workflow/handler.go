@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -28,6 +29,11 @@ type Store interface {
 	Activate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, workflow []byte) (WorkflowVersion, error)
 	GetValidationInfo(ctx context.Context, formID uuid.UUID, workflow []byte) ([]ValidationInfo, error)
 	EnrichWorkflowResponse(ctx context.Context, formID uuid.UUID, apiWorkflow []byte) ([]byte, error)
+	Simulate(ctx context.Context, formID uuid.UUID, workflow []byte, answers map[string]json.RawMessage) (SimulationResult, error)
+	ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error)
+	GetVersion(ctx context.Context, formID uuid.UUID, versionID uuid.UUID) (WorkflowVersion, error)
+	Diff(ctx context.Context, formID uuid.UUID, fromID uuid.UUID, toID uuid.UUID) (Diff, error)
+	Rollback(ctx context.Context, formID uuid.UUID, versionID uuid.UUID, userID uuid.UUID) (WorkflowVersion, error)
 }
 
 type Handler struct {
@@ -47,6 +53,8 @@ func nodeTypeToUppercase(nt NodeType) string {
 		return "SECTION"
 	case NodeTypeCondition:
 		return "CONDITION"
+	case NodeTypeSwitch:
+		return "SWITCH"
 	case NodeTypeStart:
 		return "START"
 	case NodeTypeEnd:
@@ -63,6 +71,8 @@ func nodeTypeToLowercase(apiType string) string {
 		return "section"
 	case "CONDITION":
 		return "condition"
+	case "SWITCH":
+		return "switch"
 	case "START":
 		return "start"
 	case "END":
@@ -187,7 +197,7 @@ func NewHandler(
 }
 
 type createNodeRequest struct {
-	Type    string      `json:"type" validate:"required,oneof=SECTION CONDITION"`
+	Type    string      `json:"type" validate:"required,oneof=SECTION CONDITION SWITCH"`
 	Payload NodePayload `json:"payload" validate:"required"`
 }
 
@@ -197,6 +207,24 @@ type createNodeResponse struct {
 	Label any    `json:"label"`
 }
 
+// simulateRequest holds an optional draft workflow in API format and hypothetical answers keyed by question ID.
+// When Workflow is omitted the stored workflow is simulated.
+type simulateRequest struct {
+	Workflow json.RawMessage            `json:"workflow"`
+	Answers  map[string]json.RawMessage `json:"answers"`
+}
+
+// VersionResponse describes a workflow version; Workflow is only set when a single version is fetched
+type VersionResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	Seq        int64           `json:"seq"`
+	IsActive   bool            `json:"isActive"`
+	LastEditor uuid.UUID       `json:"lastEditor"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+	Workflow   json.RawMessage `json:"workflow,omitempty"`
+}
+
 type ValidationInfo struct {
 	Type    ValidationInfoType `json:"type"`
 	NodeID  *string            `json:"nodeId,omitempty"`
@@ -413,3 +441,222 @@ func (h *Handler) DeleteNodeHandler(w http.ResponseWriter, r *http.Request) {
 
 	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
 }
+
+// SimulateHandler resolves the path and sections a respondent would get for hypothetical answers
+// against a draft workflow from the request body or the stored workflow, without saving anything.
+func (h *Handler) SimulateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SimulateWorkflow")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req simulateRequest
+	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var dbWorkflow []byte
+	if len(req.Workflow) > 0 && string(req.Workflow) != "null" {
+		// Get current workflow from database to merge type information, the same way as UpdateHandler
+		currentRow, err := h.store.Get(traceCtx, formID)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+
+		mergedWorkflow, err := mergeTypeFromDB(req.Workflow, currentRow.Workflow)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to merge type information: %w", err), logger)
+			return
+		}
+
+		dbWorkflow, err = workflowFromAPIFormat(mergedWorkflow)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to convert workflow from API format: %w", err), logger)
+			return
+		}
+	}
+
+	result, err := h.store.Simulate(traceCtx, formID, dbWorkflow, req.Answers)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// ListVersionsHandler lists the workflow versions of a form, newest first
+func (h *Handler) ListVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListWorkflowVersions")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	versions, err := h.store.ListVersions(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]VersionResponse, len(versions))
+	for i, version := range versions {
+		response[i] = VersionResponse{
+			ID:         version.ID,
+			Seq:        version.Seq,
+			IsActive:   version.IsActive,
+			LastEditor: version.LastEditor,
+			CreatedAt:  version.CreatedAt.Time,
+			UpdatedAt:  version.UpdatedAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// GetVersionHandler returns one workflow version of a form with its workflow in API format
+func (h *Handler) GetVersionHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetWorkflowVersion")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	versionIDStr := r.PathValue("versionId")
+	versionID, err := handlerutil.ParseUUID(versionIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	version, err := h.store.GetVersion(traceCtx, formID, versionID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response, err := h.versionResponse(traceCtx, formID, version)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// DiffHandler returns the structural difference between the versions given by the from and to query parameters
+func (h *Handler) DiffHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DiffWorkflowVersions")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	fromID, err := handlerutil.ParseUUID(r.URL.Query().Get("from"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	toID, err := handlerutil.ParseUUID(r.URL.Query().Get("to"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	diff, err := h.store.Diff(traceCtx, formID, fromID, toID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, diff)
+}
+
+// RollbackHandler copies an old workflow version into a new draft version and returns it
+func (h *Handler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RollbackWorkflow")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	versionIDStr := r.PathValue("versionId")
+	versionID, err := handlerutil.ParseUUID(versionIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	version, err := h.store.Rollback(traceCtx, formID, versionID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response, err := h.versionResponse(traceCtx, formID, version)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// versionResponse converts a workflow version to its response with the workflow in API format
+func (h *Handler) versionResponse(ctx context.Context, formID uuid.UUID, version WorkflowVersion) (VersionResponse, error) {
+	apiWorkflow, err := workflowToAPIFormat(version.Workflow)
+	if err != nil {
+		return VersionResponse{}, fmt.Errorf("failed to convert workflow to API format: %w", err)
+	}
+
+	apiWorkflow, err = h.store.EnrichWorkflowResponse(ctx, formID, apiWorkflow)
+	if err != nil {
+		logutil.WithContext(ctx, h.logger).Warn("failed to enrich workflow labels", zap.Error(err), zap.String("formId", formID.String()))
+	}
+
+	return VersionResponse{
+		ID:         version.ID,
+		Seq:        version.Seq,
+		IsActive:   version.IsActive,
+		LastEditor: version.LastEditor,
+		CreatedAt:  version.CreatedAt.Time,
+		UpdatedAt:  version.UpdatedAt.Time,
+		Workflow:   json.RawMessage(apiWorkflow),
+	}, nil
+}
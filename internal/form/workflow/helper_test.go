@@ -11,6 +11,7 @@ import (
 	"NYCU-SDC/core-system-backend/internal/form/question"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -48,6 +49,26 @@ func (m *mockQuerier) Activate(ctx context.Context, arg ActivateParams) (Activat
 	return args.Get(0).(ActivateRow), args.Error(1)
 }
 
+func (m *mockQuerier) ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error) {
+	args := m.Called(ctx, formID)
+	return args.Get(0).([]ListVersionsRow), args.Error(1)
+}
+
+func (m *mockQuerier) GetVersion(ctx context.Context, arg GetVersionParams) (WorkflowVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(WorkflowVersion), args.Error(1)
+}
+
+func (m *mockQuerier) GetByResponseID(ctx context.Context, arg GetByResponseIDParams) (WorkflowVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(WorkflowVersion), args.Error(1)
+}
+
+func (m *mockQuerier) CreateVersion(ctx context.Context, arg CreateVersionParams) (WorkflowVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(WorkflowVersion), args.Error(1)
+}
+
 // mockFormStore implements FormStore (form queries Patch) for workflow tests.
 type mockFormStore struct {
 	mock.Mock
@@ -58,6 +79,10 @@ func (m *mockFormStore) PatchParams(ctx context.Context, params form.PatchParams
 	return args.Get(0).(form.PatchRow), args.Error(1)
 }
 
+func (m *mockFormStore) WithTx(tx pgx.Tx) *form.Service {
+	return nil
+}
+
 // mockValidator is a mock implementation of Validator interface
 type mockValidator struct {
 	mock.Mock
@@ -105,6 +130,16 @@ func (m *mockQuestionStore) ListByFormID(ctx context.Context, formID uuid.UUID)
 	return result, nil
 }
 
+func (m *mockQuestionStore) GetAnswerableMapByFormID(ctx context.Context, formID uuid.UUID) (map[string]question.Answerable, error) {
+	result := make(map[string]question.Answerable)
+	for id, q := range m.questions {
+		if q.FormID() == formID {
+			result[id.String()] = q
+		}
+	}
+	return result, nil
+}
+
 func (m *mockQuestionStore) ListSections(ctx context.Context, formID uuid.UUID) (map[string]question.Section, error) {
 	return nil, nil
 }
@@ -783,3 +818,87 @@ func createWorkflow_ConditionNoRule(t *testing.T) ([]byte, QuestionStore) {
 	}
 	return createWorkflowJSON(t, nodes), nil
 }
+
+// createWorkflow_CompoundConditionRule returns a workflow whose condition node uses the given condition rule,
+// which may be an all/any/not group or a leaf rule with a typed operator
+func createWorkflow_CompoundConditionRule(t *testing.T, rule map[string]any) []byte {
+	t.Helper()
+	startID := uuid.New()
+	conditionID := uuid.New()
+	endID := uuid.New()
+	sectionID := uuid.New()
+
+	return createWorkflowJSON(t, []map[string]any{
+		{
+			"id":    startID.String(),
+			"type":  "start",
+			"label": "Start",
+			"next":  sectionID.String(),
+		},
+		{
+			"id":    sectionID.String(),
+			"type":  "section",
+			"label": "Section",
+			"next":  conditionID.String(),
+		},
+		{
+			"id":            conditionID.String(),
+			"type":          "condition",
+			"label":         "Condition",
+			"nextTrue":      endID.String(),
+			"nextFalse":     endID.String(),
+			"conditionRule": rule,
+		},
+		{
+			"id":    endID.String(),
+			"type":  "end",
+			"label": "End",
+		},
+	})
+}
+
+// switchWorkflowIDs holds the node IDs of a workflow built by createWorkflow_Switch
+type switchWorkflowIDs struct {
+	start    uuid.UUID
+	section  uuid.UUID
+	switchID uuid.UUID
+	sectionA uuid.UUID
+	end      uuid.UUID
+}
+
+// createWorkflow_Switch returns a workflow START -> section -> switch whose single case on questionID routes
+// to section A and whose default routes to END. modify can change the switch node before it is encoded.
+func createWorkflow_Switch(t *testing.T, questionID uuid.UUID, modify func(switchNode map[string]any, ids switchWorkflowIDs)) []byte {
+	t.Helper()
+	ids := switchWorkflowIDs{
+		start:    uuid.New(),
+		section:  uuid.New(),
+		switchID: uuid.New(),
+		sectionA: uuid.New(),
+		end:      uuid.New(),
+	}
+
+	switchNode := map[string]any{
+		"id":    ids.switchID.String(),
+		"type":  "switch",
+		"label": "Switch",
+		"cases": []any{
+			map[string]any{
+				"rule": map[string]any{"source": "nonChoice", "question": questionID.String(), "pattern": "yes"},
+				"next": ids.sectionA.String(),
+			},
+		},
+		"default": ids.end.String(),
+	}
+	if modify != nil {
+		modify(switchNode, ids)
+	}
+
+	return createWorkflowJSON(t, []map[string]any{
+		{"id": ids.start.String(), "type": "start", "label": "Start", "next": ids.section.String()},
+		{"id": ids.section.String(), "type": "section", "label": "Section", "next": ids.switchID.String()},
+		switchNode,
+		{"id": ids.sectionA.String(), "type": "section", "label": "Section A", "next": ids.end.String()},
+		{"id": ids.end.String(), "type": "end", "label": "End"},
+	})
+}
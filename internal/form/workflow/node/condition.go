@@ -124,62 +124,142 @@ func extractUUIDsFromPattern(pattern string) []string {
 }
 
 func (n *ConditionNode) validateConditionRule(ctx context.Context, formID uuid.UUID, nodeID string, rule ConditionRule, questionStore QuestionStore) error {
+	return validateRule(ctx, formID, TypeCondition, nodeID, "conditionRule", rule, 1, questionStore)
+}
+
+// validateRule validates a rule at path and, for all/any/not groups, every rule nested in it.
+// kind is the type of the node the rule belongs to and is only used in error messages.
+func validateRule(ctx context.Context, formID uuid.UUID, kind string, nodeID string, path string, rule ConditionRule, depth int, questionStore QuestionStore) error {
+	if !rule.IsGroup() {
+		return validateLeafRule(ctx, formID, kind, nodeID, path, rule, questionStore)
+	}
+
+	if depth > MaxConditionRuleDepth {
+		return fmt.Errorf("%s node '%s' %s exceeds the maximum nesting depth of %d", kind, nodeID, path, MaxConditionRuleDepth)
+	}
+
+	groupCount := 0
+	if rule.All != nil {
+		groupCount++
+	}
+	if rule.Any != nil {
+		groupCount++
+	}
+	if rule.Not != nil {
+		groupCount++
+	}
+	if groupCount > 1 {
+		return fmt.Errorf("%s node '%s' %s must have only one of 'all', 'any' or 'not'", kind, nodeID, path)
+	}
+
+	if rule.Source != "" || rule.Question != "" || rule.Pattern != "" || rule.ChoiceOptionID != "" || rule.Operator != "" || rule.Value != nil {
+		return fmt.Errorf("%s node '%s' %s cannot combine a group with leaf rule fields (source, question, pattern, choiceOptionId, operator, value)", kind, nodeID, path)
+	}
+
+	operator := groupOperator(rule)
+	if rule.Not != nil {
+		return validateRule(ctx, formID, kind, nodeID, path+".not", *rule.Not, depth+1, questionStore)
+	}
+
+	children := rule.All
+	if rule.Any != nil {
+		children = rule.Any
+	}
+	if len(children) == 0 {
+		return fmt.Errorf("%s node '%s' %s.%s must contain at least one rule", kind, nodeID, path, operator)
+	}
+
+	var errs []error
+	for i, child := range children {
+		err := validateRule(ctx, formID, kind, nodeID, fmt.Sprintf("%s.%s[%d]", path, operator, i), child, depth+1, questionStore)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// groupOperator returns the name of the group operator used by a group rule
+func groupOperator(rule ConditionRule) string {
+	switch {
+	case rule.All != nil:
+		return "all"
+	case rule.Any != nil:
+		return "any"
+	default:
+		return "not"
+	}
+}
+
+func validateLeafRule(ctx context.Context, formID uuid.UUID, kind string, nodeID string, path string, rule ConditionRule, questionStore QuestionStore) error {
 	// Normalize source to uppercase for comparison (API may send "choice" or "CHOICE")
 	rule.Source = ConditionSource(strings.ToUpper(string(rule.Source)))
 	if rule.Source != ConditionSourceChoice && rule.Source != ConditionSourceNonChoice {
-		return fmt.Errorf("condition node '%s' has invalid conditionRule.source: '%s'", nodeID, rule.Source)
+		return fmt.Errorf("%s node '%s' has invalid %s.source: '%s'", kind, nodeID, path, rule.Source)
 	}
 
 	// Validate question
 	if rule.Question == "" {
-		return fmt.Errorf("condition node '%s' conditionRule.question cannot be empty", nodeID)
+		return fmt.Errorf("%s node '%s' %s.question cannot be empty", kind, nodeID, path)
 	}
 
-	// Validate pattern (required for both choice and nonChoice sources)
-	if rule.Pattern == "" {
-		return fmt.Errorf("condition node '%s' conditionRule.pattern cannot be empty", nodeID)
-	}
+	// A leaf rule uses either a typed operator with its value or a regex pattern
+	if rule.Operator != "" {
+		if rule.Pattern != "" {
+			return fmt.Errorf("%s node '%s' %s cannot have both 'operator' and 'pattern'", kind, nodeID, path)
+		}
+	} else {
+		if rule.Value != nil {
+			return fmt.Errorf("%s node '%s' %s.value requires an 'operator'", kind, nodeID, path)
+		}
 
-	// Validate pattern is a valid regex
-	_, err := regexp.Compile(rule.Pattern)
-	if err != nil {
-		return fmt.Errorf("condition node '%s' conditionRule.pattern is not a valid regex: %w", nodeID, err)
+		// Validate pattern (required for both choice and nonChoice sources)
+		if rule.Pattern == "" {
+			return fmt.Errorf("%s node '%s' %s.pattern cannot be empty", kind, nodeID, path)
+		}
+
+		// Validate pattern is a valid regex
+		_, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s node '%s' %s.pattern is not a valid regex: %w", kind, nodeID, path, err)
+		}
 	}
 
 	// Validate question ID exists and type matches condition source
 	if questionStore != nil {
 		questionID, err := uuid.Parse(rule.Question)
 		if err != nil {
-			return fmt.Errorf("condition node '%s' conditionRule.question '%s' is not a valid UUID", nodeID, rule.Question)
+			return fmt.Errorf("%s node '%s' %s.question '%s' is not a valid UUID", kind, nodeID, path, rule.Question)
 		}
 
 		answerable, err := questionStore.Get(ctx, questionID)
 		if err != nil {
-			return fmt.Errorf("condition node '%s' references non-existent question '%s' in conditionRule.question", nodeID, rule.Question)
+			return fmt.Errorf("%s node '%s' references non-existent question '%s' in %s.question", kind, nodeID, rule.Question, path)
 		}
 
 		q := answerable.Question()
 
 		// Validate question belongs to the form
 		if answerable.FormID() != formID {
-			return fmt.Errorf("condition node '%s' references question '%s' that belongs to a different form", nodeID, rule.Question)
+			return fmt.Errorf("%s node '%s' references question '%s' that belongs to a different form", kind, nodeID, rule.Question)
 		}
 
 		// Validate question type matches condition source
 		switch rule.Source {
 		case ConditionSourceChoice:
 			if !question.ContainsType(question.ChoiceTypes, q.Type) {
-				return fmt.Errorf("condition node '%s' with source 'CHOICE' requires question type %s, but question '%s' has type '%s'", nodeID, question.FormatAllowedTypes(question.ChoiceTypes), rule.Question, q.Type)
+				return fmt.Errorf("%s node '%s' with source 'CHOICE' requires question type %s, but question '%s' has type '%s'", kind, nodeID, question.FormatAllowedTypes(question.ChoiceTypes), rule.Question, q.Type)
 			}
 			// For CHOICE: every UUID in the pattern must be a choice option ID of the question
 			uuidsInPattern := extractUUIDsFromPattern(rule.Pattern)
 			if len(uuidsInPattern) > 0 {
 				choices, extractErr := question.ExtractChoices(q.Metadata)
 				if extractErr != nil {
-					return fmt.Errorf("condition node '%s' conditionRule.pattern references choice options but question '%s' has invalid or missing choices: %w", nodeID, rule.Question, extractErr)
+					return fmt.Errorf("%s node '%s' %s.pattern references choice options but question '%s' has invalid or missing choices: %w", kind, nodeID, path, rule.Question, extractErr)
 				}
 				if len(choices) == 0 {
-					return fmt.Errorf("condition node '%s' conditionRule.pattern references choice options but question '%s' has no choices", nodeID, rule.Question)
+					return fmt.Errorf("%s node '%s' %s.pattern references choice options but question '%s' has no choices", kind, nodeID, path, rule.Question)
 				}
 				choiceIDSet := make(map[string]bool)
 				for _, c := range choices {
@@ -188,7 +268,7 @@ func (n *ConditionNode) validateConditionRule(ctx context.Context, formID uuid.U
 				var errs []error
 				for _, u := range uuidsInPattern {
 					if !choiceIDSet[u] {
-						errs = append(errs, fmt.Errorf("condition node '%s' conditionRule.pattern references non-existent choice option '%s' for question '%s'", nodeID, u, rule.Question))
+						errs = append(errs, fmt.Errorf("%s node '%s' %s.pattern references non-existent choice option '%s' for question '%s'", kind, nodeID, path, u, rule.Question))
 					}
 				}
 				if len(errs) > 0 {
@@ -197,7 +277,15 @@ func (n *ConditionNode) validateConditionRule(ctx context.Context, formID uuid.U
 			}
 		case ConditionSourceNonChoice:
 			if !question.ContainsType(question.NonChoiceTypes, q.Type) {
-				return fmt.Errorf("condition node '%s' with source 'NONCHOICE' requires question type %s, but question '%s' has type '%s'", nodeID, question.FormatAllowedTypes(question.NonChoiceTypes), rule.Question, q.Type)
+				return fmt.Errorf("%s node '%s' with source 'NONCHOICE' requires question type %s, but question '%s' has type '%s'", kind, nodeID, question.FormatAllowedTypes(question.NonChoiceTypes), rule.Question, q.Type)
+			}
+		}
+
+		// Validate the operator is supported by the question type and the value fits it
+		if rule.Operator != "" {
+			err := answerable.ValidateOperand(rule.Operator, rule.Value)
+			if err != nil {
+				return fmt.Errorf("%s node '%s' %s.operator is invalid for question '%s' of type '%s': %w", kind, nodeID, path, rule.Question, q.Type, err)
 			}
 		}
 	}
@@ -0,0 +1,121 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// SwitchNode represents a switch node, which routes to the next node of the first case
+// whose rule is true and to default when no case matches
+type SwitchNode struct {
+	node map[string]any
+}
+
+func NewSwitchNode(node map[string]any) (Validatable, error) {
+	return &SwitchNode{node: node}, nil
+}
+
+// Validate checks switch-node fields and the rule of every case; whether the case next and
+// default targets exist is enforced by validateGraphReferences in the workflow package.
+func (n *SwitchNode) Validate(ctx context.Context, formID uuid.UUID, questionStore QuestionStore) error {
+	nodeID, _ := n.node["id"].(string)
+
+	// Validate field names (check for typos and invalid fields)
+	err := n.validateFieldNames(nodeID)
+	if err != nil {
+		return err
+	}
+
+	// Switch node must have a default branch
+	defaultNext, ok := n.node["default"].(string)
+	if !ok || defaultNext == "" {
+		return fmt.Errorf("switch node '%s' must have a 'default' field", nodeID)
+	}
+
+	// Switch node must have at least one case
+	casesRaw, ok := n.node["cases"].([]any)
+	if !ok {
+		return fmt.Errorf("switch node '%s' must have a 'cases' field", nodeID)
+	}
+	if len(casesRaw) == 0 {
+		return fmt.Errorf("switch node '%s' cases must contain at least one case", nodeID)
+	}
+
+	var errs []error
+	for i, caseRaw := range casesRaw {
+		err := n.validateCase(ctx, formID, nodeID, i, caseRaw, questionStore)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateFieldNames validates that the node only contains valid field names
+func (n *SwitchNode) validateFieldNames(nodeID string) error {
+	validFields := map[string]bool{
+		"id":      true,
+		"type":    true,
+		"label":   true,
+		"cases":   true,
+		"default": true,
+		"payload": true,
+	}
+
+	var invalidFields []string
+	for fieldName := range n.node {
+		if !validFields[fieldName] {
+			invalidFields = append(invalidFields, fieldName)
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		return fmt.Errorf("switch node '%s' contains invalid field(s): %v. Valid fields are: cases, default, id, label, type", nodeID, invalidFields)
+	}
+
+	return nil
+}
+
+// validateCase validates the fields and the rule of the case at index
+func (n *SwitchNode) validateCase(ctx context.Context, formID uuid.UUID, nodeID string, index int, caseRaw any, questionStore QuestionStore) error {
+	path := fmt.Sprintf("cases[%d]", index)
+
+	mapCase, ok := caseRaw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("switch node '%s' %s must be an object", nodeID, path)
+	}
+
+	for fieldName := range mapCase {
+		if fieldName != "rule" && fieldName != "next" {
+			return fmt.Errorf("switch node '%s' %s contains invalid field '%s'. Valid fields are: next, rule", nodeID, path, fieldName)
+		}
+	}
+
+	next, ok := mapCase["next"].(string)
+	if !ok || next == "" {
+		return fmt.Errorf("switch node '%s' %s must have a 'next' field", nodeID, path)
+	}
+
+	ruleRaw, ok := mapCase["rule"]
+	if !ok {
+		return fmt.Errorf("switch node '%s' %s must have a 'rule' field", nodeID, path)
+	}
+
+	// Parse rule
+	ruleBytes, err := json.Marshal(ruleRaw)
+	if err != nil {
+		return fmt.Errorf("switch node '%s' has invalid %s.rule format: %w", nodeID, path, err)
+	}
+
+	var rule ConditionRule
+	if err := json.Unmarshal(ruleBytes, &rule); err != nil {
+		return fmt.Errorf("switch node '%s' has invalid %s.rule format: %w", nodeID, path, err)
+	}
+
+	return validateRule(ctx, formID, TypeSwitch, nodeID, path+".rule", rule, 1, questionStore)
+}
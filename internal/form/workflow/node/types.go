@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
@@ -29,12 +30,143 @@ const (
 	ConditionSourceNonChoice ConditionSource = "NONCHOICE"
 )
 
-// ConditionRule represents a condition rule for condition nodes
+// ConditionRule represents a condition rule for condition nodes.
+//
+// A rule is either a leaf rule or a group that combines other rules with exactly one of all, any or not:
+//
+//	{"all": [{"source": "CHOICE", "question": "...", "pattern": "..."}, {"any": [...]}]}
+//	{"not": {"source": "NONCHOICE", "question": "...", "pattern": "..."}}
+//
+// A leaf rule either matches the answer against a regex pattern or applies a typed operator
+// (see question.Operator) to it; the operators a question supports depend on its type:
+//
+//	{"source": "NONCHOICE", "question": "...", "operator": "gte", "value": 4}
+//	{"source": "NONCHOICE", "question": "...", "operator": "between", "value": ["2026-01-01", "2026-06-30"]}
 type ConditionRule struct {
 	Source         ConditionSource `json:"source"`
 	Question       string          `json:"question"`
 	ChoiceOptionID string          `json:"choiceOptionId,omitempty"` // For choice source
 	Pattern        string          `json:"pattern"`
+
+	Operator question.Operator `json:"operator,omitempty"` // Replaces pattern when set
+	Value    json.RawMessage   `json:"value,omitempty"`    // Operand of operator; omitted for isEmpty
+
+	All []ConditionRule `json:"all,omitempty"` // true when every rule is true
+	Any []ConditionRule `json:"any,omitempty"` // true when at least one rule is true
+	Not *ConditionRule  `json:"not,omitempty"` // true when the rule is false
+}
+
+// MaxConditionRuleDepth limits how deeply all/any/not groups can be nested
+const MaxConditionRuleDepth = 5
+
+// IsGroup reports whether the rule is an all/any/not group rather than a leaf rule
+func (r ConditionRule) IsGroup() bool {
+	return r.All != nil || r.Any != nil || r.Not != nil
+}
+
+// LeafRule is a leaf rule of a condition rule tree together with its JSON path,
+// e.g. "conditionRule" for a single rule or "conditionRule.all[1]" for a nested one.
+type LeafRule struct {
+	Path string
+	Rule ConditionRule
+}
+
+// Leaves returns the leaf rules of the rule tree in depth-first order
+func (r ConditionRule) Leaves() []LeafRule {
+	return r.LeavesAt("conditionRule")
+}
+
+// LeavesAt returns the leaf rules of the rule tree in depth-first order with paths rooted at root,
+// e.g. "cases[0].rule" for the rule of a switch case.
+func (r ConditionRule) LeavesAt(root string) []LeafRule {
+	return r.appendLeaves(nil, root)
+}
+
+func (r ConditionRule) appendLeaves(leaves []LeafRule, path string) []LeafRule {
+	if !r.IsGroup() {
+		return append(leaves, LeafRule{Path: path, Rule: r})
+	}
+
+	for i, child := range r.All {
+		leaves = child.appendLeaves(leaves, fmt.Sprintf("%s.all[%d]", path, i))
+	}
+	for i, child := range r.Any {
+		leaves = child.appendLeaves(leaves, fmt.Sprintf("%s.any[%d]", path, i))
+	}
+	if r.Not != nil {
+		leaves = r.Not.appendLeaves(leaves, path+".not")
+	}
+
+	return leaves
+}
+
+// ConditionRuleFromMap converts a parsed conditionRule object to ConditionRule without unmarshaling the whole rule.
+// Malformed all/any/not members (e.g. a non-object entry) are converted to empty leaf rules.
+func ConditionRuleFromMap(mapRule map[string]any) ConditionRule {
+	var rule ConditionRule
+	source, _ := mapRule["source"].(string)
+	rule.Source = ConditionSource(source)
+	rule.Question, _ = mapRule["question"].(string)
+	rule.ChoiceOptionID, _ = mapRule["choiceOptionId"].(string)
+	rule.Pattern, _ = mapRule["pattern"].(string)
+
+	operator, _ := mapRule["operator"].(string)
+	rule.Operator = question.Operator(operator)
+	value, ok := mapRule["value"]
+	if ok {
+		// The operand is kept as raw JSON so each question type can decode it
+		rule.Value, _ = json.Marshal(value)
+	}
+
+	rule.All = conditionRulesFromSlice(mapRule["all"])
+	rule.Any = conditionRulesFromSlice(mapRule["any"])
+
+	notRaw, ok := mapRule["not"]
+	if ok {
+		child, _ := notRaw.(map[string]any)
+		notRule := ConditionRuleFromMap(child)
+		rule.Not = &notRule
+	}
+
+	return rule
+}
+
+// SwitchCase is one case of a switch node: when Rule is true the workflow continues at Next
+type SwitchCase struct {
+	Rule ConditionRule `json:"rule"`
+	Next string        `json:"next"`
+}
+
+// SwitchCasesFromNode converts the cases of a parsed switch node to SwitchCase in order.
+// Malformed cases (e.g. a non-object entry) are converted to empty cases.
+func SwitchCasesFromNode(node map[string]any) []SwitchCase {
+	items, ok := node["cases"].([]any)
+	if !ok {
+		return nil
+	}
+
+	cases := make([]SwitchCase, 0, len(items))
+	for _, item := range items {
+		mapCase, _ := item.(map[string]any)
+		mapRule, _ := mapCase["rule"].(map[string]any)
+		next, _ := mapCase["next"].(string)
+		cases = append(cases, SwitchCase{Rule: ConditionRuleFromMap(mapRule), Next: next})
+	}
+	return cases
+}
+
+func conditionRulesFromSlice(raw any) []ConditionRule {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+
+	rules := make([]ConditionRule, 0, len(items))
+	for _, item := range items {
+		child, _ := item.(map[string]any)
+		rules = append(rules, ConditionRuleFromMap(child))
+	}
+	return rules
 }
 
 // Node type constants to avoid importing workflow package
@@ -42,6 +174,7 @@ const (
 	TypeStart     = "start"
 	TypeSection   = "section"
 	TypeCondition = "condition"
+	TypeSwitch    = "switch"
 	TypeEnd       = "end"
 )
 
@@ -63,6 +196,9 @@ func New(node map[string]any) (Validatable, string, error) {
 	case TypeCondition:
 		validatable, err := NewConditionNode(node)
 		return validatable, nodeType, err
+	case TypeSwitch:
+		validatable, err := NewSwitchNode(node)
+		return validatable, nodeType, err
 	case TypeEnd:
 		validatable, err := NewEndNode(node)
 		return validatable, nodeType, err
@@ -21,6 +21,11 @@ func TestNodeType_ToUppercase(t *testing.T) {
 			input:    NodeTypeCondition,
 			expected: "CONDITION",
 		},
+		{
+			name:     "switch to SWITCH",
+			input:    NodeTypeSwitch,
+			expected: "SWITCH",
+		},
 		{
 			name:     "start to START",
 			input:    NodeTypeStart,
@@ -59,6 +64,11 @@ func TestNodeType_ToLowercase(t *testing.T) {
 			input:    "CONDITION",
 			expected: "condition",
 		},
+		{
+			name:     "SWITCH to switch",
+			input:    "SWITCH",
+			expected: "switch",
+		},
 		{
 			name:     "START to start",
 			input:    "START",
@@ -173,30 +173,59 @@ node_fields_expanded AS (
     LATERAL jsonb_each(COALESCE(node, '{}'::jsonb)) AS node_fields(field_key, field_value)
 ),
 -- Clean each field: remove reference fields that point to the deleted node (omit them entirely).
--- For condition nodes, null out conditionRule.question when it references a question from the deleted section.
+-- For condition and switch nodes, null out the question of rules that reference a question from the deleted section.
+-- Leaf rules can be nested in all/any/not groups, so the replacement runs on the jsonb text form,
+-- which always renders members as "key": value.
 cleaned_node_fields AS (
     SELECT 
         node_id,
         field_key,
         CASE
-            WHEN field_key = 'conditionRule'
-                 AND EXISTS (SELECT 1 FROM deleted_section_question_ids d WHERE d.question_id = (node_fields_expanded.field_value->>'question'))
-            THEN jsonb_set(node_fields_expanded.field_value, '{question}', 'null'::jsonb)
+            WHEN field_key IN ('conditionRule', 'cases')
+                 AND EXISTS (SELECT 1 FROM deleted_section_question_ids d WHERE strpos(node_fields_expanded.field_value::text, d.question_id) > 0)
+            THEN regexp_replace(
+                node_fields_expanded.field_value::text,
+                '"question": "(' || (SELECT string_agg(d.question_id, '|') FROM deleted_section_question_ids d) || ')"',
+                '"question": null',
+                'g'
+            )::jsonb
             ELSE node_fields_expanded.field_value
         END AS field_value
     FROM node_fields_expanded
     WHERE NOT (
         -- Remove (omit) reference fields that point to the deleted node
-        field_key IN ('next', 'nextTrue', 'nextFalse')
+        field_key IN ('next', 'nextTrue', 'nextFalse', 'default')
         AND jsonb_typeof(field_value) = 'string'
         AND trim(both '"' from field_value::text) = (SELECT deleted_id FROM deleted_node_id)
     )
 ),
+-- For switch nodes, remove next from the cases that point to the deleted node, keeping case order
+cleaned_case_fields AS (
+    SELECT
+        node_id,
+        field_key,
+        CASE
+            WHEN field_key = 'cases' AND jsonb_typeof(field_value) = 'array'
+            THEN (
+                SELECT COALESCE(jsonb_agg(
+                    CASE
+                        WHEN switch_case->>'next' = (SELECT deleted_id FROM deleted_node_id)
+                        THEN switch_case - 'next'
+                        ELSE switch_case
+                    END
+                    ORDER BY case_index
+                ), '[]'::jsonb)
+                FROM jsonb_array_elements(field_value) WITH ORDINALITY AS switch_cases(switch_case, case_index)
+            )
+            ELSE field_value
+        END AS field_value
+    FROM cleaned_node_fields
+),
 -- Rebuild nodes from cleaned fields
 cleaned_nodes AS (
     SELECT 
         jsonb_object_agg(field_key, field_value ORDER BY field_key) AS cleaned_node
-    FROM cleaned_node_fields
+    FROM cleaned_case_fields
     GROUP BY node_id
 ),
 -- Rebuild the workflow array from cleaned nodes
@@ -360,4 +389,30 @@ result_row AS (
     WHERE wv.id IN (SELECT id FROM unchanged)
 )
 SELECT * FROM result_row
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: ListVersions :many
+SELECT id, form_id, last_editor, seq, is_active, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+ORDER BY seq DESC;
+
+-- name: GetVersion :one
+SELECT id, form_id, last_editor, seq, is_active, workflow, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = @form_id
+  AND id = @id;
+
+-- name: CreateVersion :one
+-- Always inserts a new draft version, leaving the current latest version in the history
+INSERT INTO workflow_versions (form_id, last_editor, workflow)
+VALUES ($1, $2, $3)
+RETURNING id, form_id, last_editor, seq, is_active, workflow, created_at, updated_at;
+
+-- name: GetByResponseID :one
+-- Returns the workflow version a response is pinned to; no rows when the response is not pinned
+SELECT wv.id, wv.form_id, wv.last_editor, wv.seq, wv.is_active, wv.workflow, wv.created_at, wv.updated_at
+FROM workflow_versions AS wv
+JOIN form_responses AS fr ON fr.workflow_version_id = wv.id
+WHERE fr.form_id = @form_id
+  AND fr.id = @response_id;
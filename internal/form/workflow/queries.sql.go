@@ -275,6 +275,35 @@ func (q *Queries) CreateNode(ctx context.Context, arg CreateNodeParams) (CreateN
 	return i, err
 }
 
+const createVersion = `-- name: CreateVersion :one
+INSERT INTO workflow_versions (form_id, last_editor, workflow)
+VALUES ($1, $2, $3)
+RETURNING id, form_id, last_editor, seq, is_active, workflow, created_at, updated_at
+`
+
+type CreateVersionParams struct {
+	FormID     uuid.UUID
+	LastEditor uuid.UUID
+	Workflow   []byte
+}
+
+// Always inserts a new draft version, leaving the current latest version in the history
+func (q *Queries) CreateVersion(ctx context.Context, arg CreateVersionParams) (WorkflowVersion, error) {
+	row := q.db.QueryRow(ctx, createVersion, arg.FormID, arg.LastEditor, arg.Workflow)
+	var i WorkflowVersion
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.LastEditor,
+		&i.Seq,
+		&i.IsActive,
+		&i.Workflow,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const deleteNode = `-- name: DeleteNode :one
 WITH latest_workflow AS (
     SELECT wv.id, wv.is_active, wv.form_id, wv.workflow
@@ -343,23 +372,49 @@ cleaned_node_fields AS (
         node_id,
         field_key,
         CASE
-            WHEN field_key = 'conditionRule'
-                 AND EXISTS (SELECT 1 FROM deleted_section_question_ids d WHERE d.question_id = (node_fields_expanded.field_value->>'question'))
-            THEN jsonb_set(node_fields_expanded.field_value, '{question}', 'null'::jsonb)
+            WHEN field_key IN ('conditionRule', 'cases')
+                 AND EXISTS (SELECT 1 FROM deleted_section_question_ids d WHERE strpos(node_fields_expanded.field_value::text, d.question_id) > 0)
+            THEN regexp_replace(
+                node_fields_expanded.field_value::text,
+                '"question": "(' || (SELECT string_agg(d.question_id, '|') FROM deleted_section_question_ids d) || ')"',
+                '"question": null',
+                'g'
+            )::jsonb
             ELSE node_fields_expanded.field_value
         END AS field_value
     FROM node_fields_expanded
     WHERE NOT (
         -- Remove (omit) reference fields that point to the deleted node
-        field_key IN ('next', 'nextTrue', 'nextFalse')
+        field_key IN ('next', 'nextTrue', 'nextFalse', 'default')
         AND jsonb_typeof(field_value) = 'string'
         AND trim(both '"' from field_value::text) = (SELECT deleted_id FROM deleted_node_id)
     )
 ),
+cleaned_case_fields AS (
+    SELECT
+        node_id,
+        field_key,
+        CASE
+            WHEN field_key = 'cases' AND jsonb_typeof(field_value) = 'array'
+            THEN (
+                SELECT COALESCE(jsonb_agg(
+                    CASE
+                        WHEN switch_case->>'next' = (SELECT deleted_id FROM deleted_node_id)
+                        THEN switch_case - 'next'
+                        ELSE switch_case
+                    END
+                    ORDER BY case_index
+                ), '[]'::jsonb)
+                FROM jsonb_array_elements(field_value) WITH ORDINALITY AS switch_cases(switch_case, case_index)
+            )
+            ELSE field_value
+        END AS field_value
+    FROM cleaned_node_fields
+),
 cleaned_nodes AS (
     SELECT 
         jsonb_object_agg(field_key, field_value ORDER BY field_key) AS cleaned_node
-    FROM cleaned_node_fields
+    FROM cleaned_case_fields
     GROUP BY node_id
 ),
 cleaned_workflow AS (
@@ -404,7 +459,10 @@ type DeleteNodeParams struct {
 // Expand each node into individual key-value pairs
 // --------|------------|------------
 // Clean each field: remove reference fields that point to the deleted node (omit them entirely).
-// For condition nodes, null out conditionRule.question when it references a question from the deleted section.
+// For condition and switch nodes, null out the question of rules that reference a question from the deleted section.
+// Leaf rules can be nested in all/any/not groups, so the replacement runs on the jsonb text form,
+// which always renders members as "key": value.
+// For switch nodes, remove next from the cases that point to the deleted node, keeping case order
 // Rebuild nodes from cleaned fields
 // Rebuild the workflow array from cleaned nodes
 // Update draft workflow version in place
@@ -441,6 +499,109 @@ func (q *Queries) Get(ctx context.Context, formID uuid.UUID) (WorkflowVersion, e
 	return i, err
 }
 
+const getByResponseID = `-- name: GetByResponseID :one
+SELECT wv.id, wv.form_id, wv.last_editor, wv.seq, wv.is_active, wv.workflow, wv.created_at, wv.updated_at
+FROM workflow_versions AS wv
+JOIN form_responses AS fr ON fr.workflow_version_id = wv.id
+WHERE fr.form_id = $1
+  AND fr.id = $2
+`
+
+type GetByResponseIDParams struct {
+	FormID     uuid.UUID
+	ResponseID uuid.UUID
+}
+
+// Returns the workflow version a response is pinned to; no rows when the response is not pinned
+func (q *Queries) GetByResponseID(ctx context.Context, arg GetByResponseIDParams) (WorkflowVersion, error) {
+	row := q.db.QueryRow(ctx, getByResponseID, arg.FormID, arg.ResponseID)
+	var i WorkflowVersion
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.LastEditor,
+		&i.Seq,
+		&i.IsActive,
+		&i.Workflow,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getVersion = `-- name: GetVersion :one
+SELECT id, form_id, last_editor, seq, is_active, workflow, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+  AND id = $2
+`
+
+type GetVersionParams struct {
+	FormID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) GetVersion(ctx context.Context, arg GetVersionParams) (WorkflowVersion, error) {
+	row := q.db.QueryRow(ctx, getVersion, arg.FormID, arg.ID)
+	var i WorkflowVersion
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.LastEditor,
+		&i.Seq,
+		&i.IsActive,
+		&i.Workflow,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listVersions = `-- name: ListVersions :many
+SELECT id, form_id, last_editor, seq, is_active, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+ORDER BY seq DESC
+`
+
+type ListVersionsRow struct {
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	LastEditor uuid.UUID
+	Seq        int64
+	IsActive   bool
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+func (q *Queries) ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error) {
+	rows, err := q.db.Query(ctx, listVersions, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListVersionsRow
+	for rows.Next() {
+		var i ListVersionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FormID,
+			&i.LastEditor,
+			&i.Seq,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const update = `-- name: Update :one
 WITH latest_workflow AS (
     SELECT wv.id, wv.is_active, wv.form_id
@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -20,6 +21,7 @@ import (
 // The method starts from the start node and follows the workflow path:
 // - For section nodes: records the section ID and continues to next
 // - For condition nodes: evaluates the condition based on answers and follows nextTrue or nextFalse
+// - For switch nodes: follows the next of the first case whose rule is true, or default if none is
 // - For end nodes: stops traversal
 //
 // If a condition cannot be evaluated (answer doesn't exist), the method stops and returns
@@ -38,14 +40,80 @@ func (s *Service) ResolveSections(ctx context.Context, formID uuid.UUID, answers
 		return nil, fmt.Errorf("failed to get workflow for form %s: %w", formID, err)
 	}
 
+	sectionIDs, err := s.resolveWorkflowSections(workflowRow.Workflow, answers, answerableMap)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return sectionIDs, nil
+}
+
+// ResolveSectionsForResponse works like ResolveSections but traverses the workflow version the response
+// was pinned to when it was created, so later workflow edits do not change its sections.
+// Responses that are not pinned (created before pinning or while the form had no active workflow)
+// fall back to the latest workflow version.
+func (s *Service) ResolveSectionsForResponse(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, answers []answer.Answer, answerableMap map[string]question.Answerable) ([]uuid.UUID, error) {
+	ctx, span := s.tracer.Start(ctx, "ResolveSectionsForResponse")
+	defer span.End()
+
+	workflowRow, err := s.queries.GetByResponseID(ctx, GetByResponseIDParams{
+		FormID:     formID,
+		ResponseID: responseID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return s.ResolveSections(ctx, formID, answers, answerableMap)
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get pinned workflow for response %s: %w", responseID, err)
+	}
+
+	sectionIDs, err := s.resolveWorkflowSections(workflowRow.Workflow, answers, answerableMap)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return sectionIDs, nil
+}
+
+// resolveWorkflowSections traverses a workflow in database format with the answers of a response
+func (s *Service) resolveWorkflowSections(workflow []byte, answers []answer.Answer, answerableMap map[string]question.Answerable) ([]uuid.UUID, error) {
 	// Parse workflow JSON into nodes
 	var nodes []map[string]any
-	err = json.Unmarshal(workflowRow.Workflow, &nodes)
+	err := json.Unmarshal(workflow, &nodes)
 	if err != nil {
-		span.RecordError(err)
 		return nil, fmt.Errorf("failed to unmarshal workflow: %w", err)
 	}
 
+	// Build answer map for quick lookup (questionID -> answer value)
+	answerMap := make(map[string][]byte)
+	for _, answer := range answers {
+		answerMap[answer.QuestionID.String()] = answer.Value
+	}
+
+	result, err := s.traverse(nodes, answerMap, answerableMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.SectionIDs, nil
+}
+
+// traversal is the outcome of walking a workflow from its start node with a set of answers
+type traversal struct {
+	// SectionIDs are the sections visited, in order
+	SectionIDs []uuid.UUID
+	// Steps are the nodes visited, in order, with the outcome of each condition and switch node
+	Steps []SimulationStep
+	// Completed is false when the walk stopped at a node that cannot be evaluated yet
+	Completed bool
+}
+
+// traverse walks the workflow nodes from the start node as described on ResolveSections.
+// answerMap maps question IDs to storage-shaped answer values.
+func (s *Service) traverse(nodes []map[string]any, answerMap map[string][]byte, answerableMap map[string]question.Answerable) (traversal, error) {
 	// Build node map for quick lookup
 	nodeMap := make(map[string]map[string]any)
 	for _, node := range nodes {
@@ -55,12 +123,6 @@ func (s *Service) ResolveSections(ctx context.Context, formID uuid.UUID, answers
 		}
 	}
 
-	// Build answer map for quick lookup (questionID -> answer value)
-	answerMap := make(map[string][]byte)
-	for _, answer := range answers {
-		answerMap[answer.QuestionID.String()] = answer.Value
-	}
-
 	// Find start node
 	var currentNodeID string
 	for _, node := range nodes {
@@ -72,30 +134,32 @@ func (s *Service) ResolveSections(ctx context.Context, formID uuid.UUID, answers
 	}
 
 	if currentNodeID == "" {
-		return nil, fmt.Errorf("start node not found in workflow")
+		return traversal{}, fmt.Errorf("start node not found in workflow")
 	}
 
 	// Traverse the workflow and collect section IDs
-	var sectionIDs []uuid.UUID
+	var result traversal
 	visited := make(map[string]bool) // Prevent infinite loops
 
 	for currentNodeID != "" {
 		// Check for cycles
 		if visited[currentNodeID] {
-			return nil, fmt.Errorf("cycle detected in workflow at node %s", currentNodeID)
+			return traversal{}, fmt.Errorf("cycle detected in workflow at node %s", currentNodeID)
 		}
 		visited[currentNodeID] = true
 
 		currentNode, exists := nodeMap[currentNodeID]
 		if !exists {
-			return nil, fmt.Errorf("node %s not found in workflow", currentNodeID)
+			return traversal{}, fmt.Errorf("node %s not found in workflow", currentNodeID)
 		}
 
 		nodeType, ok := currentNode["type"].(string)
 		if !ok {
-			return nil, fmt.Errorf("node %s has no type", currentNodeID)
+			return traversal{}, fmt.Errorf("node %s has no type", currentNodeID)
 		}
 
+		step := SimulationStep{NodeID: currentNodeID, Type: nodeTypeToUppercase(NodeType(nodeType))}
+
 		switch nodeType {
 		case string(NodeTypeStart):
 			// Move to next node
@@ -106,9 +170,9 @@ func (s *Service) ResolveSections(ctx context.Context, formID uuid.UUID, answers
 			// Record this section ID
 			sectionID, err := uuid.Parse(currentNodeID)
 			if err != nil {
-				return nil, fmt.Errorf("invalid section node ID %s: %w", currentNodeID, err)
+				return traversal{}, fmt.Errorf("invalid section node ID %s: %w", currentNodeID, err)
 			}
-			sectionIDs = append(sectionIDs, sectionID)
+			result.SectionIDs = append(result.SectionIDs, sectionID)
 
 			// Move to next node
 			next, _ := currentNode["next"].(string)
@@ -116,84 +180,200 @@ func (s *Service) ResolveSections(ctx context.Context, formID uuid.UUID, answers
 
 		case string(NodeTypeCondition):
 			// Evaluate condition and determine next node
-			nextNodeID, canEvaluate, err := s.evaluateCondition(currentNode, answerMap, answerableMap)
+			nextNodeID, conditionResult, canEvaluate, err := s.evaluateCondition(currentNode, answerMap, answerableMap)
 			if err != nil {
-				span.RecordError(err)
-				return nil, fmt.Errorf("failed to evaluate condition at node %s: %w", currentNodeID, err)
+				return traversal{}, fmt.Errorf("failed to evaluate condition at node %s: %w", currentNodeID, err)
 			}
 
 			// If cannot evaluate (answer doesn't exist), stop here
 			if !canEvaluate {
-				return sectionIDs, nil
+				result.Steps = append(result.Steps, step)
+				return result, nil
 			}
 
+			step.Result = &conditionResult
+			currentNodeID = nextNodeID
+
+		case string(NodeTypeSwitch):
+			// Evaluate cases in order and determine next node
+			nextNodeID, matchedCase, canEvaluate, err := s.evaluateSwitch(currentNode, answerMap, answerableMap)
+			if err != nil {
+				return traversal{}, fmt.Errorf("failed to evaluate switch at node %s: %w", currentNodeID, err)
+			}
+
+			// If a case before the matching one cannot be evaluated, stop here
+			if !canEvaluate {
+				result.Steps = append(result.Steps, step)
+				return result, nil
+			}
+
+			if matchedCase >= 0 {
+				step.MatchedCase = &matchedCase
+			}
 			currentNodeID = nextNodeID
 
 		case string(NodeTypeEnd):
 			// Reached the end, stop traversal
-			return sectionIDs, nil
+			result.Steps = append(result.Steps, step)
+			result.Completed = true
+			return result, nil
 
 		default:
-			return nil, fmt.Errorf("unknown node type %s at node %s", nodeType, currentNodeID)
+			return traversal{}, fmt.Errorf("unknown node type %s at node %s", nodeType, currentNodeID)
 		}
+
+		step.Next = currentNodeID
+		result.Steps = append(result.Steps, step)
 	}
 
-	return sectionIDs, nil
+	return result, nil
 }
 
 // evaluateCondition evaluates a condition node and returns the next node ID to follow.
-// Returns (nextNodeID, canEvaluate, error).
+// Returns (nextNodeID, result, canEvaluate, error).
 // If canEvaluate is false, it means the answer needed for evaluation doesn't exist.
-func (s *Service) evaluateCondition(conditionNode map[string]any, answerMap map[string][]byte, answerableMap map[string]question.Answerable) (string, bool, error) {
+func (s *Service) evaluateCondition(conditionNode map[string]any, answerMap map[string][]byte, answerableMap map[string]question.Answerable) (string, bool, bool, error) {
 	// Extract conditionRule
 	conditionRuleRaw, ok := conditionNode["conditionRule"]
 	if !ok {
-		return "", false, fmt.Errorf("condition node missing conditionRule")
+		return "", false, false, fmt.Errorf("condition node missing conditionRule")
 	}
 
 	// Parse conditionRule
 	conditionRuleBytes, err := json.Marshal(conditionRuleRaw)
 	if err != nil {
-		return "", false, fmt.Errorf("failed to marshal conditionRule: %w", err)
+		return "", false, false, fmt.Errorf("failed to marshal conditionRule: %w", err)
 	}
 
-	var conditionRule struct {
-		Source   string `json:"source"`   // "CHOICE" or "NONCHOICE"
-		Question string `json:"question"` // Question ID
-		Pattern  string `json:"pattern"`  // Regex pattern
+	var conditionRule node.ConditionRule
+	err = json.Unmarshal(conditionRuleBytes, &conditionRule)
+	if err != nil {
+		return "", false, false, fmt.Errorf("failed to unmarshal conditionRule: %w", err)
 	}
 
-	err = json.Unmarshal(conditionRuleBytes, &conditionRule)
+	conditionResult, canEvaluate, err := s.evaluateRule(conditionRule, answerMap, answerableMap)
 	if err != nil {
-		return "", false, fmt.Errorf("failed to unmarshal conditionRule: %w", err)
+		return "", false, false, err
+	}
+	if !canEvaluate {
+		return "", false, false, nil
 	}
 
-	// Get the answer for this question
-	answerValue, answerExists := answerMap[conditionRule.Question]
-	if !answerExists {
-		// Answer doesn't exist, cannot evaluate
-		return "", false, nil
+	// Return the appropriate next node based on condition result
+	if conditionResult {
+		nextTrue, _ := conditionNode["nextTrue"].(string)
+		return nextTrue, true, true, nil
+	}
+
+	nextFalse, _ := conditionNode["nextFalse"].(string)
+	return nextFalse, false, true, nil
+}
+
+// evaluateSwitch evaluates the cases of a switch node in order and returns the next node ID of the
+// first case whose rule is true, or default when no case matches.
+// Returns (nextNodeID, matchedCase, canEvaluate, error); matchedCase is -1 when default is followed.
+// canEvaluate is false when a case cannot be evaluated before a matching case is found, since that
+// case might still match once its answer exists.
+func (s *Service) evaluateSwitch(switchNode map[string]any, answerMap map[string][]byte, answerableMap map[string]question.Answerable) (string, int, bool, error) {
+	for i, switchCase := range node.SwitchCasesFromNode(switchNode) {
+		result, canEvaluate, err := s.evaluateRule(switchCase.Rule, answerMap, answerableMap)
+		if err != nil {
+			return "", -1, false, fmt.Errorf("cases[%d]: %w", i, err)
+		}
+		if !canEvaluate {
+			return "", -1, false, nil
+		}
+		if result {
+			return switchCase.Next, i, true, nil
+		}
+	}
+
+	defaultNext, _ := switchNode["default"].(string)
+	return defaultNext, -1, true, nil
+}
+
+// evaluateRule evaluates a leaf rule or an all/any/not group.
+// Returns (result, canEvaluate, error). A group can still be evaluated when some of its
+// answers are missing if the known rules already decide it, e.g. one false rule in an
+// 'all' group or one true rule in an 'any' group.
+func (s *Service) evaluateRule(rule node.ConditionRule, answerMap map[string][]byte, answerableMap map[string]question.Answerable) (bool, bool, error) {
+	switch {
+	case rule.Not != nil:
+		result, canEvaluate, err := s.evaluateRule(*rule.Not, answerMap, answerableMap)
+		return !result, canEvaluate, err
+
+	case rule.All != nil:
+		undecided := false
+		for _, child := range rule.All {
+			result, canEvaluate, err := s.evaluateRule(child, answerMap, answerableMap)
+			if err != nil {
+				return false, false, err
+			}
+			if !canEvaluate {
+				undecided = true
+				continue
+			}
+			if !result {
+				return false, true, nil
+			}
+		}
+		return !undecided, !undecided, nil
+
+	case rule.Any != nil:
+		undecided := false
+		for _, child := range rule.Any {
+			result, canEvaluate, err := s.evaluateRule(child, answerMap, answerableMap)
+			if err != nil {
+				return false, false, err
+			}
+			if !canEvaluate {
+				undecided = true
+				continue
+			}
+			if result {
+				return true, true, nil
+			}
+		}
+		return false, !undecided, nil
 	}
 
 	// Get the answerable for this question
-	answerable, answerableExists := answerableMap[conditionRule.Question]
+	answerable, answerableExists := answerableMap[rule.Question]
 	if !answerableExists {
 		// Question doesn't exist, cannot evaluate
-		return "", false, nil
+		return false, false, nil
 	}
 
-	// Use MatchesPattern from the Answerable interface
-	conditionResult, err := answerable.MatchesPattern(answerValue, conditionRule.Pattern)
-	if err != nil {
-		return "", false, fmt.Errorf("failed to match pattern for question %s: %w", conditionRule.Question, err)
+	// Get the answer for this question
+	answerValue, answerExists := answerMap[rule.Question]
+	if !answerExists {
+		if rule.Operator == question.OperatorIsEmpty {
+			// An unanswered question is empty
+			err := answerable.ValidateOperand(rule.Operator, rule.Value)
+			if err != nil {
+				return false, false, fmt.Errorf("failed to compare answer for question %s: %w", rule.Question, err)
+			}
+			return true, true, nil
+		}
+
+		// Answer doesn't exist, cannot evaluate
+		return false, false, nil
 	}
 
-	// Return the appropriate next node based on condition result
-	if conditionResult {
-		nextTrue, _ := conditionNode["nextTrue"].(string)
-		return nextTrue, true, nil
+	// Typed operators are evaluated by Compare, regex patterns by MatchesPattern
+	if rule.Operator != "" {
+		result, err := answerable.Compare(answerValue, rule.Operator, rule.Value)
+		if err != nil {
+			return false, false, fmt.Errorf("failed to compare answer for question %s: %w", rule.Question, err)
+		}
+
+		return result, true, nil
 	}
 
-	nextFalse, _ := conditionNode["nextFalse"].(string)
-	return nextFalse, true, nil
+	result, err := answerable.MatchesPattern(answerValue, rule.Pattern)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to match pattern for question %s: %w", rule.Question, err)
+	}
+
+	return result, true, nil
 }
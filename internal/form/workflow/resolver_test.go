@@ -1,15 +1,18 @@
 package workflow
 
 import (
+	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form/answer"
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
 	"NYCU-SDC/core-system-backend/internal/form/shared"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -71,6 +74,126 @@ func TestService_ResolveSections(t *testing.T) {
 				require.Equal(t, setup.expected, result)
 			},
 		},
+		{
+			name: "all group with every rule true -> nextTrue",
+			setup: func(t *testing.T) setupParams {
+				return buildCompoundConditionSetup(t, func(q1, c1, q2, c2 string) map[string]any {
+					return map[string]any{"all": []any{choiceRule(q1, c1), choiceRule(q2, c2)}}
+				}, true, "true")
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "any group with one rule true -> nextTrue",
+			setup: func(t *testing.T) setupParams {
+				return buildCompoundConditionSetup(t, func(q1, c1, q2, c2 string) map[string]any {
+					return map[string]any{"any": []any{choiceRule(q1, uuid.New().String()), choiceRule(q2, c2)}}
+				}, true, "true")
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "not group with true rule -> nextFalse",
+			setup: func(t *testing.T) setupParams {
+				return buildCompoundConditionSetup(t, func(q1, c1, q2, c2 string) map[string]any {
+					return map[string]any{"not": choiceRule(q1, c1)}
+				}, true, "false")
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "any group decided by answered rule while other answer is missing -> nextTrue",
+			setup: func(t *testing.T) setupParams {
+				return buildCompoundConditionSetup(t, func(q1, c1, q2, c2 string) map[string]any {
+					return map[string]any{"any": []any{choiceRule(q1, c1), choiceRule(q2, c2)}}
+				}, false, "true")
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "all group with missing answer cannot be evaluated -> stop",
+			setup: func(t *testing.T) setupParams {
+				return buildCompoundConditionSetup(t, func(q1, c1, q2, c2 string) map[string]any {
+					return map[string]any{"all": []any{choiceRule(q1, c1), map[string]any{"not": choiceRule(q2, c2)}}}
+				}, false, "undecided")
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "typed operator on rating answer -> nextTrue",
+			setup: func(t *testing.T) setupParams {
+				return buildRatingOperatorSetup(t, 4, map[string]any{"operator": "gte", "value": 4}, true)
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "typed operator between on rating answer outside range -> nextFalse",
+			setup: func(t *testing.T) setupParams {
+				return buildRatingOperatorSetup(t, 2, map[string]any{"operator": "between", "value": []any{3, 5}}, false)
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "switch on rating answer -> first matching case",
+			setup: func(t *testing.T) setupParams {
+				return buildRatingSwitchSetup(t, 5)
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "switch on rating answer -> later matching case",
+			setup: func(t *testing.T) setupParams {
+				return buildRatingSwitchSetup(t, 3)
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "switch on rating answer without matching case -> default",
+			setup: func(t *testing.T) setupParams {
+				return buildRatingSwitchSetup(t, 1)
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
+		{
+			name: "switch on missing answer -> stop before switch",
+			setup: func(t *testing.T) setupParams {
+				return buildRatingSwitchSetup(t, 0)
+			},
+			validate: func(t *testing.T, setup setupParams, result []uuid.UUID, err error) {
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			},
+		},
 		{
 			name:  "empty workflow error",
 			setup: buildEmptyWorkflowErrorSetup,
@@ -107,6 +230,147 @@ func TestService_ResolveSections(t *testing.T) {
 	}
 }
 
+func TestService_ResolveSections_UnansweredQuestion(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	questionTypes := []question.QuestionType{
+		question.QuestionTypeShortText,
+		question.QuestionTypeLongText,
+		question.QuestionTypeSingleChoice,
+		question.QuestionTypeMultipleChoice,
+		question.QuestionTypeRating,
+		question.QuestionTypeDate,
+		question.QuestionTypeUploadFile,
+		question.QuestionTypeOauthConnect,
+	}
+
+	type unansweredCase struct {
+		name         string
+		questionType question.QuestionType
+		operator     question.Operator
+		negate       bool
+		outcome      string
+	}
+
+	// An unanswered question is empty; no other operator can be evaluated until it is answered
+	var testCases []unansweredCase
+	for _, questionType := range questionTypes {
+		answerable := createMockAnswerable(t, uuid.New(), questionType)
+		for _, operator := range answerable.SupportedOperators() {
+			outcome, expectation := "undecided", "stop"
+			if operator == question.OperatorIsEmpty {
+				outcome, expectation = "true", "nextTrue"
+				testCases = append(testCases, unansweredCase{
+					name:         fmt.Sprintf("%s not %s -> nextFalse", questionType, operator),
+					questionType: questionType,
+					operator:     operator,
+					negate:       true,
+					outcome:      "false",
+				})
+			}
+			testCases = append(testCases, unansweredCase{
+				name:         fmt.Sprintf("%s %s -> %s", questionType, operator, expectation),
+				questionType: questionType,
+				operator:     operator,
+				outcome:      outcome,
+			})
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup := buildUnansweredOperatorSetup(t, tc.questionType, tc.operator, tc.negate, tc.outcome)
+
+			mockQuerier := new(mockQuerier)
+			service := createTestService(t, logger, tracer, mockQuerier, new(mockValidator), nil)
+			mockQuerier.On("Get", mock.Anything, setup.formID).Return(WorkflowVersion{
+				ID:       setup.formID,
+				FormID:   setup.formID,
+				Workflow: setup.workflowJSON,
+			}, nil).Once()
+
+			result, err := service.ResolveSections(ctx, setup.formID, setup.answers, setup.answerableMap)
+			require.NoError(t, err)
+			require.Equal(t, setup.expected, result)
+		})
+	}
+}
+
+func TestService_ResolveSectionsForResponse(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	// The latest workflow has no start node, so resolving against it fails
+	latestWorkflow := []byte(`[]`)
+
+	testCases := []struct {
+		name            string
+		setupMock       func(mq *mockQuerier, setup setupParams, responseID uuid.UUID)
+		expectedErr     error
+		expectLatestErr bool
+	}{
+		{
+			name: "pinned response uses its workflow version",
+			setupMock: func(mq *mockQuerier, setup setupParams, responseID uuid.UUID) {
+				mq.On("GetByResponseID", mock.Anything, GetByResponseIDParams{FormID: setup.formID, ResponseID: responseID}).
+					Return(WorkflowVersion{FormID: setup.formID, Workflow: setup.workflowJSON}, nil).Once()
+			},
+		},
+		{
+			name: "unpinned response falls back to the latest workflow",
+			setupMock: func(mq *mockQuerier, setup setupParams, responseID uuid.UUID) {
+				mq.On("GetByResponseID", mock.Anything, GetByResponseIDParams{FormID: setup.formID, ResponseID: responseID}).
+					Return(WorkflowVersion{}, pgx.ErrNoRows).Once()
+				mq.On("Get", mock.Anything, setup.formID).
+					Return(WorkflowVersion{FormID: setup.formID, Workflow: latestWorkflow}, nil).Once()
+			},
+			expectLatestErr: true,
+		},
+		{
+			name: "unpinned response of a form without workflow",
+			setupMock: func(mq *mockQuerier, setup setupParams, responseID uuid.UUID) {
+				mq.On("GetByResponseID", mock.Anything, GetByResponseIDParams{FormID: setup.formID, ResponseID: responseID}).
+					Return(WorkflowVersion{}, pgx.ErrNoRows).Once()
+				mq.On("Get", mock.Anything, setup.formID).
+					Return(WorkflowVersion{}, pgx.ErrNoRows).Once()
+			},
+			expectedErr: internal.ErrWorkflowNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setup := buildChoiceConditionTrueNextTrueSetup(t)
+			responseID := uuid.New()
+
+			mockQuerier := new(mockQuerier)
+			service := createTestService(t, logger, tracer, mockQuerier, new(mockValidator), nil)
+			tc.setupMock(mockQuerier, setup, responseID)
+
+			result, err := service.ResolveSectionsForResponse(ctx, setup.formID, responseID, setup.answers, setup.answerableMap)
+
+			switch {
+			case tc.expectedErr != nil:
+				require.ErrorIs(t, err, tc.expectedErr)
+			case tc.expectLatestErr:
+				require.ErrorContains(t, err, "start node not found")
+			default:
+				require.NoError(t, err)
+				require.Equal(t, setup.expected, result)
+			}
+
+			mockQuerier.AssertExpectations(t)
+		})
+	}
+}
+
 func buildSimpleSetup(t *testing.T) setupParams {
 	t.Helper()
 
@@ -438,6 +702,267 @@ func buildEmptyWorkflowErrorSetup(t *testing.T) setupParams {
 	}
 }
 
+// choiceRule returns a leaf CHOICE rule matching exactly choiceID
+func choiceRule(questionID, choiceID string) map[string]any {
+	return map[string]any{
+		"source":   "choice",
+		"question": questionID,
+		"pattern":  "^" + choiceID + "$",
+	}
+}
+
+// buildCompoundConditionSetup builds START -> A -> condition -> (B | C) -> END with two single choice
+// questions in section A. The first question is always answered with its choice; the second only
+// when answerSecond is set. outcome is "true", "false" or "undecided" and selects the expected sections.
+func buildCompoundConditionSetup(t *testing.T, makeRule func(q1, c1, q2, c2 string) map[string]any, answerSecond bool, outcome string) setupParams {
+	t.Helper()
+
+	formID := uuid.New()
+	startID := uuid.New()
+	sectionAID := uuid.New()
+	sectionBID := uuid.New()
+	sectionCID := uuid.New()
+	endID := uuid.New()
+	conditionID := uuid.New()
+	question1ID := uuid.New()
+	question2ID := uuid.New()
+	choice1ID := uuid.New()
+	choice2ID := uuid.New()
+	responseID := uuid.New()
+
+	workflowJSON := createWorkflowJSON(t, []map[string]any{
+		{"id": startID.String(), "type": "start", "label": "Start", "next": sectionAID.String()},
+		{"id": sectionAID.String(), "type": "section", "label": "Section A", "next": conditionID.String()},
+		{
+			"id":            conditionID.String(),
+			"type":          "condition",
+			"label":         "Condition",
+			"nextTrue":      sectionBID.String(),
+			"nextFalse":     sectionCID.String(),
+			"conditionRule": makeRule(question1ID.String(), choice1ID.String(), question2ID.String(), choice2ID.String()),
+		},
+		{"id": sectionBID.String(), "type": "section", "label": "Section B", "next": endID.String()},
+		{"id": sectionCID.String(), "type": "section", "label": "Section C", "next": endID.String()},
+		{"id": endID.String(), "type": "end", "label": "End"},
+	})
+
+	answers := []answer.Answer{
+		buildAnswer(t, question1ID, responseID, shared.SingleChoiceAnswer{
+			ChoiceID: choice1ID,
+			Snapshot: shared.ChoiceSnapshot{Name: "Option A", Description: ""},
+		}),
+	}
+	if answerSecond {
+		answers = append(answers, buildAnswer(t, question2ID, responseID, shared.SingleChoiceAnswer{
+			ChoiceID: choice2ID,
+			Snapshot: shared.ChoiceSnapshot{Name: "Option A", Description: ""},
+		}))
+	}
+
+	answerableMap := map[string]question.Answerable{
+		question1ID.String(): createSingleChoiceAnswerable(t, question1ID, sectionAID, formID, choice1ID),
+		question2ID.String(): createSingleChoiceAnswerable(t, question2ID, sectionAID, formID, choice2ID),
+	}
+
+	expected := []uuid.UUID{sectionAID}
+	switch outcome {
+	case "true":
+		expected = append(expected, sectionBID)
+	case "false":
+		expected = append(expected, sectionCID)
+	}
+
+	return setupParams{
+		formID:        formID,
+		workflowJSON:  workflowJSON,
+		answers:       answers,
+		answerableMap: answerableMap,
+		sections:      []uuid.UUID{sectionAID, sectionBID, sectionCID},
+		expected:      expected,
+	}
+}
+
+// buildRatingOperatorSetup builds START -> A -> condition -> (B | C) -> END where the condition applies
+// operatorRule (operator and value) to a rating question in section A answered with value.
+func buildRatingOperatorSetup(t *testing.T, value int, operatorRule map[string]any, expectTrue bool) setupParams {
+	t.Helper()
+
+	formID := uuid.New()
+	startID := uuid.New()
+	sectionAID := uuid.New()
+	sectionBID := uuid.New()
+	sectionCID := uuid.New()
+	endID := uuid.New()
+	conditionID := uuid.New()
+	responseID := uuid.New()
+
+	answerable := createMockAnswerable(t, formID, question.QuestionTypeRating)
+	questionID := answerable.Question().ID
+
+	rule := map[string]any{"source": "nonChoice", "question": questionID.String()}
+	for key, v := range operatorRule {
+		rule[key] = v
+	}
+
+	workflowJSON := createWorkflowJSON(t, []map[string]any{
+		{"id": startID.String(), "type": "start", "label": "Start", "next": sectionAID.String()},
+		{"id": sectionAID.String(), "type": "section", "label": "Section A", "next": conditionID.String()},
+		{
+			"id":            conditionID.String(),
+			"type":          "condition",
+			"label":         "Condition",
+			"nextTrue":      sectionBID.String(),
+			"nextFalse":     sectionCID.String(),
+			"conditionRule": rule,
+		},
+		{"id": sectionBID.String(), "type": "section", "label": "Section B", "next": endID.String()},
+		{"id": sectionCID.String(), "type": "section", "label": "Section C", "next": endID.String()},
+		{"id": endID.String(), "type": "end", "label": "End"},
+	})
+
+	expected := []uuid.UUID{sectionAID, sectionCID}
+	if expectTrue {
+		expected = []uuid.UUID{sectionAID, sectionBID}
+	}
+
+	return setupParams{
+		formID:        formID,
+		workflowJSON:  workflowJSON,
+		answers:       []answer.Answer{buildAnswer(t, questionID, responseID, shared.RatingAnswer{Value: value})},
+		answerableMap: map[string]question.Answerable{questionID.String(): answerable},
+		sections:      []uuid.UUID{sectionAID, sectionBID, sectionCID},
+		expected:      expected,
+	}
+}
+
+// buildUnansweredOperatorSetup builds START -> A -> condition -> (B | C) -> END where the condition applies
+// operator, negated if negate is set, to a question of questionType in section A that is left unanswered.
+// outcome is "true", "false" or "undecided" and selects the expected sections.
+func buildUnansweredOperatorSetup(t *testing.T, questionType question.QuestionType, operator question.Operator, negate bool, outcome string) setupParams {
+	t.Helper()
+
+	formID := uuid.New()
+	startID := uuid.New()
+	sectionAID := uuid.New()
+	sectionBID := uuid.New()
+	sectionCID := uuid.New()
+	endID := uuid.New()
+	conditionID := uuid.New()
+
+	answerable := createMockAnswerable(t, formID, questionType)
+	questionID := answerable.Question().ID
+
+	// The operand is never compared, since there is no answer to compare it with
+	rule := map[string]any{"source": "nonChoice", "question": questionID.String(), "operator": string(operator)}
+	if operator != question.OperatorIsEmpty {
+		rule["value"] = 1
+	}
+	if negate {
+		rule = map[string]any{"not": rule}
+	}
+
+	workflowJSON := createWorkflowJSON(t, []map[string]any{
+		{"id": startID.String(), "type": "start", "label": "Start", "next": sectionAID.String()},
+		{"id": sectionAID.String(), "type": "section", "label": "Section A", "next": conditionID.String()},
+		{
+			"id":            conditionID.String(),
+			"type":          "condition",
+			"label":         "Condition",
+			"nextTrue":      sectionBID.String(),
+			"nextFalse":     sectionCID.String(),
+			"conditionRule": rule,
+		},
+		{"id": sectionBID.String(), "type": "section", "label": "Section B", "next": endID.String()},
+		{"id": sectionCID.String(), "type": "section", "label": "Section C", "next": endID.String()},
+		{"id": endID.String(), "type": "end", "label": "End"},
+	})
+
+	expected := []uuid.UUID{sectionAID}
+	switch outcome {
+	case "true":
+		expected = append(expected, sectionBID)
+	case "false":
+		expected = append(expected, sectionCID)
+	}
+
+	return setupParams{
+		formID:        formID,
+		workflowJSON:  workflowJSON,
+		answers:       nil,
+		answerableMap: map[string]question.Answerable{questionID.String(): answerable},
+		sections:      []uuid.UUID{sectionAID, sectionBID, sectionCID},
+		expected:      expected,
+	}
+}
+
+// buildRatingSwitchSetup builds START -> A -> switch -> (B | C | D) -> END where the switch routes a
+// rating question in section A to B when it is at least 4, to C when it is at least 2 and to D otherwise.
+// A value of 0 leaves the question unanswered, so the switch cannot be evaluated.
+func buildRatingSwitchSetup(t *testing.T, value int) setupParams {
+	t.Helper()
+
+	formID := uuid.New()
+	startID := uuid.New()
+	sectionAID := uuid.New()
+	sectionBID := uuid.New()
+	sectionCID := uuid.New()
+	sectionDID := uuid.New()
+	endID := uuid.New()
+	switchID := uuid.New()
+	responseID := uuid.New()
+
+	answerable := createMockAnswerable(t, formID, question.QuestionTypeRating)
+	questionID := answerable.Question().ID
+
+	ratingRule := func(minValue int) map[string]any {
+		return map[string]any{"source": "nonChoice", "question": questionID.String(), "operator": "gte", "value": minValue}
+	}
+
+	workflowJSON := createWorkflowJSON(t, []map[string]any{
+		{"id": startID.String(), "type": "start", "label": "Start", "next": sectionAID.String()},
+		{"id": sectionAID.String(), "type": "section", "label": "Section A", "next": switchID.String()},
+		{
+			"id":    switchID.String(),
+			"type":  "switch",
+			"label": "Switch",
+			"cases": []any{
+				map[string]any{"rule": ratingRule(4), "next": sectionBID.String()},
+				map[string]any{"rule": ratingRule(2), "next": sectionCID.String()},
+			},
+			"default": sectionDID.String(),
+		},
+		{"id": sectionBID.String(), "type": "section", "label": "Section B", "next": endID.String()},
+		{"id": sectionCID.String(), "type": "section", "label": "Section C", "next": endID.String()},
+		{"id": sectionDID.String(), "type": "section", "label": "Section D", "next": endID.String()},
+		{"id": endID.String(), "type": "end", "label": "End"},
+	})
+
+	var answers []answer.Answer
+	var expected []uuid.UUID
+	switch {
+	case value == 0:
+		expected = []uuid.UUID{sectionAID}
+	case value >= 4:
+		expected = []uuid.UUID{sectionAID, sectionBID}
+	case value >= 2:
+		expected = []uuid.UUID{sectionAID, sectionCID}
+	default:
+		expected = []uuid.UUID{sectionAID, sectionDID}
+	}
+	if value != 0 {
+		answers = []answer.Answer{buildAnswer(t, questionID, responseID, shared.RatingAnswer{Value: value})}
+	}
+
+	return setupParams{
+		formID:        formID,
+		workflowJSON:  workflowJSON,
+		answers:       answers,
+		answerableMap: map[string]question.Answerable{questionID.String(): answerable},
+		sections:      []uuid.UUID{sectionAID, sectionBID, sectionCID, sectionDID},
+		expected:      expected,
+	}
+}
+
 func buildAnswer(t *testing.T, questionID, responseID uuid.UUID, value any) answer.Answer {
 	t.Helper()
 
@@ -3,7 +3,8 @@ CREATE TYPE node_type AS ENUM(
     'section',
     'end',
     'start',
-    'condition'
+    'condition',
+    'switch'
 );
 
 CREATE TABLE IF NOT EXISTS workflow_versions (
@@ -24,10 +24,15 @@ type Querier interface {
 	CreateNode(ctx context.Context, arg CreateNodeParams) (CreateNodeRow, error)
 	DeleteNode(ctx context.Context, arg DeleteNodeParams) ([]byte, error)
 	Activate(ctx context.Context, arg ActivateParams) (ActivateRow, error)
+	ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error)
+	GetVersion(ctx context.Context, arg GetVersionParams) (WorkflowVersion, error)
+	CreateVersion(ctx context.Context, arg CreateVersionParams) (WorkflowVersion, error)
+	GetByResponseID(ctx context.Context, arg GetByResponseIDParams) (WorkflowVersion, error)
 }
 
 type FormStore interface {
 	PatchParams(ctx context.Context, params form.PatchParams) (form.PatchRow, error)
+	WithTx(tx pgx.Tx) *form.Service
 }
 
 type Validator interface {
@@ -49,6 +54,7 @@ type NodePayload struct {
 
 type Service struct {
 	logger        *zap.Logger
+	db            DBTX
 	queries       Querier
 	formStore     FormStore
 	tracer        trace.Tracer
@@ -59,6 +65,7 @@ type Service struct {
 func NewService(logger *zap.Logger, db DBTX, formStore FormStore, questionStore QuestionStore) *Service {
 	return &Service{
 		logger:        logger,
+		db:            db,
 		queries:       New(db),
 		formStore:     formStore,
 		tracer:        otel.Tracer("workflow/service"),
@@ -67,6 +74,27 @@ func NewService(logger *zap.Logger, db DBTX, formStore FormStore, questionStore
 	}
 }
 
+// WithTx returns a copy of the service whose queries and form store run on tx.
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:        s.logger,
+		db:            tx,
+		queries:       New(tx),
+		formStore:     s.formStore.WithTx(tx),
+		tracer:        s.tracer,
+		validator:     s.validator,
+		questionStore: s.questionStore,
+	}
+}
+
+// withTransaction runs fn with a copy of the service bound to a single pgx transaction, so every write
+// made by fn is committed or rolled back together.
+func (s *Service) withTransaction(ctx context.Context, fn func(txService *Service) error) error {
+	return internal.WithTransaction(ctx, s.db, s.logger, func(tx pgx.Tx) error {
+		return fn(s.WithTx(tx))
+	})
+}
+
 // NewServiceForTesting creates a Service with injected dependencies for testing.
 // This allows unit tests to mock the Querier and Validator interfaces.
 func NewServiceForTesting(logger *zap.Logger, tracer trace.Tracer, queries Querier, formStore FormStore, validator Validator, questionStore QuestionStore) *Service {
@@ -189,6 +217,7 @@ func (s *Service) CreateNode(ctx context.Context, formID uuid.UUID, nodeType Nod
 	switch nodeType {
 	case NodeTypeSection:
 	case NodeTypeCondition:
+	case NodeTypeSwitch:
 		break
 	default:
 		err := fmt.Errorf("invalid node type: %s", nodeType)
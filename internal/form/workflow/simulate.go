@@ -0,0 +1,171 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// SimulationStep is a node visited while simulating a workflow
+type SimulationStep struct {
+	NodeID string `json:"nodeId"`
+	Type   string `json:"type"`
+	// Result is the outcome of the conditionRule of a condition node
+	Result *bool `json:"result,omitempty"`
+	// MatchedCase is the index of the first matching case of a switch node; absent when default was followed
+	MatchedCase *int `json:"matchedCase,omitempty"`
+	// Next is the node visited after this one; absent for the end node and for a node that cannot be evaluated
+	Next string `json:"next,omitempty"`
+}
+
+// SimulationResult is the path a respondent with a given set of answers takes through a workflow
+type SimulationResult struct {
+	Path     []SimulationStep `json:"path"`
+	Sections []uuid.UUID      `json:"sections"`
+	// Completed is false when the path stops at a condition or switch node whose answers are missing,
+	// in which case Sections only lists the sections that are certain to be filled
+	Completed bool `json:"completed"`
+}
+
+// Simulate resolves the sections a respondent would see for the hypothetical answers without saving anything.
+// workflow is a draft workflow in database format; when it is empty the stored workflow of the form is used.
+// answers maps question IDs to answer values in the same format as the answer API. Answers to questions that
+// are not in the form or whose value does not fit the question are returned as validation errors.
+func (s *Service) Simulate(ctx context.Context, formID uuid.UUID, workflow []byte, answers map[string]json.RawMessage) (SimulationResult, error) {
+	methodName := "Simulate"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	if len(workflow) == 0 {
+		row, err := s.queries.Get(ctx, formID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return SimulationResult{}, internal.ErrWorkflowNotFound
+			}
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "get workflow for simulation")
+			span.RecordError(err)
+			return SimulationResult{}, err
+		}
+		workflow = row.Workflow
+	} else {
+		err := s.validator.Validate(ctx, formID, workflow, s.questionStore)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+			span.RecordError(err)
+			return SimulationResult{}, err
+		}
+	}
+
+	answerableMap, err := s.questionStore.GetAnswerableMapByFormID(ctx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return SimulationResult{}, err
+	}
+
+	answerMap, err := simulationAnswerMap(answers, answerableMap)
+	if err != nil {
+		span.RecordError(err)
+		return SimulationResult{}, err
+	}
+
+	var nodes []map[string]any
+	err = json.Unmarshal(workflow, &nodes)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrUnmarshalDBWorkflow, err)
+		span.RecordError(err)
+		return SimulationResult{}, err
+	}
+
+	result, err := s.traverse(nodes, answerMap, answerableMap)
+	if err != nil {
+		// The workflow may be an unsaved draft, so a broken graph is the caller's mistake
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return SimulationResult{}, err
+	}
+
+	simulation := SimulationResult{
+		Path:      result.Steps,
+		Sections:  result.SectionIDs,
+		Completed: result.Completed,
+	}
+	if simulation.Path == nil {
+		simulation.Path = []SimulationStep{}
+	}
+	if simulation.Sections == nil {
+		simulation.Sections = []uuid.UUID{}
+	}
+
+	logger.Debug("Simulated workflow",
+		zap.String("formId", formID.String()),
+		zap.Int("answerCount", len(answers)),
+		zap.Int("sectionCount", len(simulation.Sections)),
+		zap.Bool("completed", simulation.Completed),
+	)
+
+	return simulation, nil
+}
+
+// simulationAnswerMap converts API answer values to storage-shaped values keyed by question ID, using the
+// same DecodeRequest + JSON marshal path as answer.Service.MergeAnswersForWorkflowResolution.
+// All invalid answers are reported together.
+func simulationAnswerMap(answers map[string]json.RawMessage, answerableMap map[string]question.Answerable) (map[string][]byte, error) {
+	// Iterate in a stable order so the reported errors are deterministic
+	questionIDs := make([]string, 0, len(answers))
+	for questionID := range answers {
+		questionIDs = append(questionIDs, questionID)
+	}
+	slices.Sort(questionIDs)
+
+	answerMap := make(map[string][]byte, len(answers))
+	var errs []error
+	for _, questionID := range questionIDs {
+		_, err := uuid.Parse(questionID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: invalid questionId %q: %w", internal.ErrWorkflowMergeInvalidQuestionID, questionID, err))
+			continue
+		}
+
+		answerable, ok := answerableMap[questionID]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: question %s not found in form", internal.ErrWorkflowMergeQuestionNotInForm, questionID))
+			continue
+		}
+
+		decoded, err := answerable.DecodeRequest(shared.AnswerParam{
+			QuestionID: questionID,
+			Value:      answers[questionID],
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: answer value for question %s: %w", internal.ErrWorkflowMergeAnswerValueInvalid, questionID, err))
+			continue
+		}
+
+		storageBytes, err := json.Marshal(decoded)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: encode answer for question %s: %w", internal.ErrWorkflowMergeAnswerEncodeFailed, questionID, err))
+			continue
+		}
+
+		answerMap[questionID] = storageBytes
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return answerMap, nil
+}
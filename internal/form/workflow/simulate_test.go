@@ -0,0 +1,227 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// simulationFixture is START -> A -> condition -> (B | C) -> END where the condition matches
+// a short text question in section A against "^yes$"
+type simulationFixture struct {
+	formID      uuid.UUID
+	questionID  uuid.UUID
+	startID     uuid.UUID
+	sectionAID  uuid.UUID
+	sectionBID  uuid.UUID
+	sectionCID  uuid.UUID
+	conditionID uuid.UUID
+	endID       uuid.UUID
+	workflow    []byte
+	store       *mockQuestionStore
+}
+
+func newSimulationFixture(t *testing.T) simulationFixture {
+	t.Helper()
+
+	f := simulationFixture{
+		formID:      uuid.New(),
+		startID:     uuid.New(),
+		sectionAID:  uuid.New(),
+		sectionBID:  uuid.New(),
+		sectionCID:  uuid.New(),
+		conditionID: uuid.New(),
+		endID:       uuid.New(),
+	}
+
+	answerable := createMockAnswerable(t, f.formID, question.QuestionTypeShortText)
+	f.questionID = answerable.Question().ID
+	f.store = &mockQuestionStore{questions: map[uuid.UUID]question.Answerable{f.questionID: answerable}}
+
+	f.workflow = createWorkflowJSON(t, []map[string]any{
+		{"id": f.startID.String(), "type": "start", "label": "Start", "next": f.sectionAID.String()},
+		{"id": f.sectionAID.String(), "type": "section", "label": "Section A", "next": f.conditionID.String()},
+		{
+			"id":        f.conditionID.String(),
+			"type":      "condition",
+			"label":     "Condition",
+			"nextTrue":  f.sectionBID.String(),
+			"nextFalse": f.sectionCID.String(),
+			"conditionRule": map[string]any{
+				"source":   "nonChoice",
+				"question": f.questionID.String(),
+				"pattern":  "^yes$",
+			},
+		},
+		{"id": f.sectionBID.String(), "type": "section", "label": "Section B", "next": f.endID.String()},
+		{"id": f.sectionCID.String(), "type": "section", "label": "Section C", "next": f.endID.String()},
+		{"id": f.endID.String(), "type": "end", "label": "End"},
+	})
+
+	return f
+}
+
+func TestService_Simulate(t *testing.T) {
+	t.Parallel()
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	boolPtr := func(b bool) *bool { return &b }
+
+	type testCase struct {
+		name        string
+		draft       bool
+		answers     func(f simulationFixture) map[string]json.RawMessage
+		setupMock   func(mq *mockQuerier, mv *mockValidator, f simulationFixture)
+		expected    func(f simulationFixture) SimulationResult
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			name: "stored workflow follows nextTrue",
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return map[string]json.RawMessage{f.questionID.String(): json.RawMessage(`"yes"`)}
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mq.On("Get", mock.Anything, f.formID).Return(WorkflowVersion{FormID: f.formID, Workflow: f.workflow}, nil).Once()
+			},
+			expected: func(f simulationFixture) SimulationResult {
+				return SimulationResult{
+					Path: []SimulationStep{
+						{NodeID: f.startID.String(), Type: "START", Next: f.sectionAID.String()},
+						{NodeID: f.sectionAID.String(), Type: "SECTION", Next: f.conditionID.String()},
+						{NodeID: f.conditionID.String(), Type: "CONDITION", Result: boolPtr(true), Next: f.sectionBID.String()},
+						{NodeID: f.sectionBID.String(), Type: "SECTION", Next: f.endID.String()},
+						{NodeID: f.endID.String(), Type: "END"},
+					},
+					Sections:  []uuid.UUID{f.sectionAID, f.sectionBID},
+					Completed: true,
+				}
+			},
+		},
+		{
+			name:  "draft workflow follows nextFalse without reading the stored workflow",
+			draft: true,
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return map[string]json.RawMessage{f.questionID.String(): json.RawMessage(`"no"`)}
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mv.On("Validate", mock.Anything, f.formID, f.workflow, mock.Anything).Return(nil).Once()
+			},
+			expected: func(f simulationFixture) SimulationResult {
+				return SimulationResult{
+					Path: []SimulationStep{
+						{NodeID: f.startID.String(), Type: "START", Next: f.sectionAID.String()},
+						{NodeID: f.sectionAID.String(), Type: "SECTION", Next: f.conditionID.String()},
+						{NodeID: f.conditionID.String(), Type: "CONDITION", Result: boolPtr(false), Next: f.sectionCID.String()},
+						{NodeID: f.sectionCID.String(), Type: "SECTION", Next: f.endID.String()},
+						{NodeID: f.endID.String(), Type: "END"},
+					},
+					Sections:  []uuid.UUID{f.sectionAID, f.sectionCID},
+					Completed: true,
+				}
+			},
+		},
+		{
+			name: "missing answer stops at the condition",
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return nil
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mq.On("Get", mock.Anything, f.formID).Return(WorkflowVersion{FormID: f.formID, Workflow: f.workflow}, nil).Once()
+			},
+			expected: func(f simulationFixture) SimulationResult {
+				return SimulationResult{
+					Path: []SimulationStep{
+						{NodeID: f.startID.String(), Type: "START", Next: f.sectionAID.String()},
+						{NodeID: f.sectionAID.String(), Type: "SECTION", Next: f.conditionID.String()},
+						{NodeID: f.conditionID.String(), Type: "CONDITION"},
+					},
+					Sections:  []uuid.UUID{f.sectionAID},
+					Completed: false,
+				}
+			},
+		},
+		{
+			name: "answer to a question outside the form",
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return map[string]json.RawMessage{uuid.New().String(): json.RawMessage(`"yes"`)}
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mq.On("Get", mock.Anything, f.formID).Return(WorkflowVersion{FormID: f.formID, Workflow: f.workflow}, nil).Once()
+			},
+			expectedErr: internal.ErrWorkflowMergeQuestionNotInForm,
+		},
+		{
+			name: "answer value that does not fit the question",
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return map[string]json.RawMessage{f.questionID.String(): json.RawMessage(`42`)}
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mq.On("Get", mock.Anything, f.formID).Return(WorkflowVersion{FormID: f.formID, Workflow: f.workflow}, nil).Once()
+			},
+			expectedErr: internal.ErrWorkflowMergeAnswerValueInvalid,
+		},
+		{
+			name:  "invalid draft workflow",
+			draft: true,
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return nil
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mv.On("Validate", mock.Anything, f.formID, f.workflow, mock.Anything).Return(errors.New("graph validation failed")).Once()
+			},
+			expectedErr: internal.ErrWorkflowValidationFailed,
+		},
+		{
+			name: "form without a stored workflow",
+			answers: func(f simulationFixture) map[string]json.RawMessage {
+				return nil
+			},
+			setupMock: func(mq *mockQuerier, mv *mockValidator, f simulationFixture) {
+				mq.On("Get", mock.Anything, f.formID).Return(WorkflowVersion{}, pgx.ErrNoRows).Once()
+			},
+			expectedErr: internal.ErrWorkflowNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newSimulationFixture(t)
+			mockQuerier := new(mockQuerier)
+			mockValidator := new(mockValidator)
+			service := createTestService(t, zap.NewNop(), tracer, mockQuerier, mockValidator, f.store)
+			tc.setupMock(mockQuerier, mockValidator, f)
+
+			var draft []byte
+			if tc.draft {
+				draft = f.workflow
+			}
+
+			result, err := service.Simulate(context.Background(), f.formID, draft, tc.answers(f))
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expected(f), result)
+			}
+
+			mockQuerier.AssertExpectations(t)
+			mockValidator.AssertExpectations(t)
+		})
+	}
+}
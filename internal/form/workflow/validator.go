@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"strings"
 
 	"NYCU-SDC/core-system-backend/internal"
@@ -28,6 +29,7 @@ var nodeIDPatternTemplates = []string{
 	node.TypeStart + " node '%s'",
 	node.TypeSection + " node '%s'",
 	node.TypeCondition + " node '%s'",
+	node.TypeSwitch + " node '%s'",
 	node.TypeEnd + " node '%s'",
 	// Generic node pattern: "node 'uuid' ..."
 	"node '%s'",
@@ -60,6 +62,7 @@ const (
 type QuestionStore interface {
 	Get(ctx context.Context, id uuid.UUID) (question.Answerable, error)
 	ListSections(ctx context.Context, formID uuid.UUID) (map[string]question.Section, error)
+	GetAnswerableMapByFormID(ctx context.Context, formID uuid.UUID) (map[string]question.Answerable, error)
 }
 
 type workflowValidator struct{}
@@ -95,7 +98,7 @@ func NewValidator() Validator {
 // - Array structure
 // - Node structure and required fields
 // - Valid node types
-// - Graph references (next, nextTrue, nextFalse point to existing nodes)
+// - Graph references (next, nextTrue, nextFalse, switch cases and default point to existing nodes)
 // - Condition rule question IDs exist and types match
 // Returns all validation errors if validation fails
 func (v workflowValidator) Activate(ctx context.Context, formID uuid.UUID, workflow []byte, questionStore QuestionStore) error {
@@ -170,20 +173,24 @@ func (v workflowValidator) Validate(ctx context.Context, formID uuid.UUID, workf
 		if questionStore != nil {
 			for _, n := range nodes {
 				nodeType, _ := n["type"].(string)
-				if nodeType != string(NodeTypeCondition) {
-					continue
-				}
-
-				rawRule, ok := n["conditionRule"]
-				if !ok {
-					continue
-				}
-
 				nodeID, _ := n["id"].(string)
 
-				err := validateDraftConditionQuestion(ctx, formID, nodeID, rawRule, questionStore)
-				if err != nil {
-					validationErrors = append(validationErrors, err)
+				switch nodeType {
+				case string(NodeTypeCondition):
+					rawRule, ok := n["conditionRule"]
+					if !ok {
+						continue
+					}
+
+					err := validateDraftConditionQuestion(ctx, formID, nodeID, rawRule, questionStore)
+					if err != nil {
+						validationErrors = append(validationErrors, err)
+					}
+				case string(NodeTypeSwitch):
+					err := validateDraftSwitchQuestions(ctx, formID, nodeID, n, questionStore)
+					if err != nil {
+						validationErrors = append(validationErrors, err)
+					}
 				}
 			}
 		}
@@ -482,7 +489,7 @@ func validateRequiredNodeTypes(startNodeCount, endNodeCount int) []error {
 func validateAllNodesReachableFromStart(nodes []map[string]any) error {
 	graph := make(map[string][]string)
 
-	// Build adjacency list from next / nextTrue / nextFalse
+	// Build adjacency list from next / nextTrue / nextFalse / switch cases and default
 	for _, node := range nodes {
 		nodeID, _ := node["id"].(string)
 		nodeType, _ := node["type"].(string)
@@ -500,6 +507,9 @@ func validateAllNodesReachableFromStart(nodes []map[string]any) error {
 			if ok && nextFalse != "" {
 				nextNodes = append(nextNodes, nextFalse)
 			}
+		} else if nodeType == string(NodeTypeSwitch) {
+			// Switch nodes have the next of every case and default
+			nextNodes = switchNextNodes(node)
 		} else {
 			// Other nodes have next field
 			next, ok := node["next"].(string)
@@ -567,7 +577,35 @@ func validateAllNodesReachableFromStart(nodes []map[string]any) error {
 	return nil
 }
 
-// validateGraphReferences ensures next, nextTrue, and nextFalse reference existing node IDs.
+// switchCaseNextNodes returns the next node ID of every case of a switch node, in case order.
+// Cases without a next yield an empty string so indexes match the cases array.
+func switchCaseNextNodes(n map[string]any) []string {
+	cases := node.SwitchCasesFromNode(n)
+	nextNodes := make([]string, 0, len(cases))
+	for _, switchCase := range cases {
+		nextNodes = append(nextNodes, switchCase.Next)
+	}
+	return nextNodes
+}
+
+// switchNextNodes returns the non-empty next node IDs of a switch node: every case next followed by default
+func switchNextNodes(n map[string]any) []string {
+	var nextNodes []string
+	for _, next := range switchCaseNextNodes(n) {
+		if next != "" {
+			nextNodes = append(nextNodes, next)
+		}
+	}
+
+	defaultNext, ok := n["default"].(string)
+	if ok && defaultNext != "" {
+		nextNodes = append(nextNodes, defaultNext)
+	}
+	return nextNodes
+}
+
+// validateGraphReferences ensures next, nextTrue, nextFalse and the switch case next and default
+// fields reference existing node IDs.
 // This is the single place for edge-target existence checks (Activate and Validate both use it).
 func validateGraphReferences(nodes []map[string]any, nodeMap map[string]map[string]any) error {
 	var referenceErrors []error
@@ -592,6 +630,24 @@ func validateGraphReferences(nodes []map[string]any, nodeMap map[string]map[stri
 					referenceErrors = append(referenceErrors, fmt.Errorf("condition node '%s' references non-existent node '%s' in nextFalse", nodeID, nextFalse))
 				}
 			}
+		} else if nodeType == string(NodeTypeSwitch) {
+			for i, next := range switchCaseNextNodes(node) {
+				if next == "" {
+					continue
+				}
+				_, exists := nodeMap[next]
+				if !exists {
+					referenceErrors = append(referenceErrors, fmt.Errorf("switch node '%s' references non-existent node '%s' in cases[%d].next", nodeID, next, i))
+				}
+			}
+
+			defaultNext, ok := node["default"].(string)
+			if ok && defaultNext != "" {
+				_, exists := nodeMap[defaultNext]
+				if !exists {
+					referenceErrors = append(referenceErrors, fmt.Errorf("switch node '%s' references non-existent node '%s' in default", nodeID, defaultNext))
+				}
+			}
 		} else {
 			next, ok := node["next"].(string)
 			if ok && next != "" {
@@ -609,10 +665,11 @@ func validateGraphReferences(nodes []map[string]any, nodeMap map[string]map[stri
 	return nil
 }
 
-// validateConditionSectionOrder checks that condition nodes reference sections
-// that are visited before the condition in the workflow traversal. The referenced
+// validateConditionSectionOrder checks that condition and switch nodes reference sections
+// that are visited before the node in the workflow traversal. The referenced
 // section is derived from conditionRule.question: the question's section ID (which
-// matches the workflow section node ID) must be visited before the condition.
+// matches the workflow section node ID) must be visited before the condition. For
+// all/any/not groups and switch cases this holds for the question of every leaf rule.
 // Returns error if any condition's question section comes after the condition in traversal.
 func validateConditionSectionOrder(ctx context.Context, formID uuid.UUID, nodes []map[string]any, questionStore QuestionStore) error {
 	if questionStore == nil {
@@ -649,6 +706,8 @@ func validateConditionSectionOrder(ctx context.Context, formID uuid.UUID, nodes
 			if ok && nextFalse != "" {
 				nextNodes = append(nextNodes, nextFalse)
 			}
+		case string(NodeTypeSwitch):
+			nextNodes = switchNextNodes(n)
 		default:
 			next, ok := n["next"].(string)
 			if !ok || next == "" {
@@ -668,57 +727,64 @@ func validateConditionSectionOrder(ctx context.Context, formID uuid.UUID, nodes
 		}
 	}
 
-	// Collect condition nodes with their referenced section ID (from question's section)
+	// Collect condition and switch nodes with their referenced section ID (from question's section)
 	type conditionInfo struct {
 		conditionNodeID  string
+		nodeType         string
 		referencedNodeID string
 	}
 	var conditionsToCheck []conditionInfo
 
-	for _, node := range nodes {
-		nodeType, _ := node["type"].(string)
-		if nodeType != string(NodeTypeCondition) {
-			continue
-		}
+	for _, n := range nodes {
+		nodeType, _ := n["type"].(string)
+		nodeID, _ := n["id"].(string)
 
-		nodeID, _ := node["id"].(string)
-		rawRule, ok := node["conditionRule"]
-		if !ok {
+		var leaves []node.LeafRule
+		switch nodeType {
+		case string(NodeTypeCondition):
+			ruleMap, ok := n["conditionRule"].(map[string]any)
+			if !ok {
+				continue
+			}
+			leaves = node.ConditionRuleFromMap(ruleMap).Leaves()
+		case string(NodeTypeSwitch):
+			leaves = switchLeaves(n)
+		default:
 			continue
 		}
 
-		ruleMap, ok := rawRule.(map[string]any)
-		if !ok {
-			continue
-		}
+		// Every leaf of an all/any/not group must reference a section visited before the condition
+		referenced := make(map[string]bool)
+		for _, leaf := range leaves {
+			if leaf.Rule.Question == "" {
+				continue
+			}
 
-		questionStr, _ := ruleMap["question"].(string)
-		if questionStr == "" {
-			continue
-		}
+			questionID, err := uuid.Parse(leaf.Rule.Question)
+			if err != nil {
+				continue
+			}
 
-		questionID, err := uuid.Parse(questionStr)
-		if err != nil {
-			continue
-		}
+			answerable, err := questionStore.Get(ctx, questionID)
+			if err != nil {
+				continue
+			}
+			if answerable.FormID() != formID {
+				continue
+			}
 
-		answerable, err := questionStore.Get(ctx, questionID)
-		if err != nil {
-			continue
-		}
-		if answerable.FormID() != formID {
-			continue
-		}
+			sectionID := answerable.Question().SectionID.String()
+			if !nodeIDSet[sectionID] || referenced[sectionID] {
+				continue
+			}
+			referenced[sectionID] = true
 
-		sectionID := answerable.Question().SectionID.String()
-		if !nodeIDSet[sectionID] {
-			continue
+			conditionsToCheck = append(conditionsToCheck, conditionInfo{
+				conditionNodeID:  nodeID,
+				nodeType:         nodeType,
+				referencedNodeID: sectionID,
+			})
 		}
-
-		conditionsToCheck = append(conditionsToCheck, conditionInfo{
-			conditionNodeID:  nodeID,
-			referencedNodeID: sectionID,
-		})
 	}
 
 	if len(conditionsToCheck) == 0 {
@@ -759,8 +825,8 @@ func validateConditionSectionOrder(ctx context.Context, formID uuid.UUID, nodes
 		// If referenced node is not visited or comes after the condition
 		if !refVisited || refOrder >= condOrder {
 			orderErrors = append(orderErrors, fmt.Errorf(
-				"condition node '%s' references section '%s' (question's section) that has not been visited yet; condition will always evaluate to false",
-				cond.conditionNodeID, cond.referencedNodeID))
+				"%s node '%s' references section '%s' (question's section) that has not been visited yet; condition will always evaluate to false",
+				cond.nodeType, cond.conditionNodeID, cond.referencedNodeID))
 		}
 	}
 
@@ -771,20 +837,10 @@ func validateConditionSectionOrder(ctx context.Context, formID uuid.UUID, nodes
 	return nil
 }
 
-// mapToConditionRule converts a parsed conditionRule map to ConditionRule without marshal/unmarshal.
-func mapToConditionRule(mapRule map[string]any) node.ConditionRule {
-	var rule node.ConditionRule
-	source, _ := mapRule["source"].(string)
-	rule.Source = node.ConditionSource(source)
-	rule.Question, _ = mapRule["question"].(string)
-	rule.ChoiceOptionID, _ = mapRule["choiceOptionId"].(string)
-	rule.Pattern, _ = mapRule["pattern"].(string)
-	return rule
-}
-
 // validateDraftConditionQuestion performs the subset of conditionRule validation that must hold
 // even for draft workflows: that the referenced question exists, belongs to the form, and its
 // type is compatible with the condition source. It deliberately skips regex validation and
+// checks every leaf rule of all/any/not groups.
 func validateDraftConditionQuestion(
 	ctx context.Context,
 	formID uuid.UUID,
@@ -797,8 +853,56 @@ func validateDraftConditionQuestion(
 		return fmt.Errorf("condition node '%s' has invalid conditionRule format: expected object", nodeID)
 	}
 
-	rule := mapToConditionRule(ruleMap)
+	var errs []error
+	for _, leaf := range node.ConditionRuleFromMap(ruleMap).Leaves() {
+		err := validateDraftConditionLeaf(ctx, formID, node.TypeCondition, nodeID, leaf.Path, leaf.Rule, questionStore)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 
+	return errors.Join(errs...)
+}
+
+// validateDraftSwitchQuestions performs the draft subset of rule validation described on
+// validateDraftConditionQuestion for every leaf rule of every case of a switch node.
+func validateDraftSwitchQuestions(
+	ctx context.Context,
+	formID uuid.UUID,
+	nodeID string,
+	switchNode map[string]any,
+	questionStore QuestionStore,
+) error {
+	var errs []error
+	for _, leaf := range switchLeaves(switchNode) {
+		err := validateDraftConditionLeaf(ctx, formID, node.TypeSwitch, nodeID, leaf.Path, leaf.Rule, questionStore)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// switchLeaves returns the leaf rules of every case of a switch node, with paths such as "cases[0].rule.all[1]"
+func switchLeaves(switchNode map[string]any) []node.LeafRule {
+	var leaves []node.LeafRule
+	for i, switchCase := range node.SwitchCasesFromNode(switchNode) {
+		leaves = append(leaves, switchCase.Rule.LeavesAt(fmt.Sprintf("cases[%d].rule", i))...)
+	}
+	return leaves
+}
+
+// validateDraftConditionLeaf validates a leaf rule of a node of type kind; kind is only used in error messages
+func validateDraftConditionLeaf(
+	ctx context.Context,
+	formID uuid.UUID,
+	kind string,
+	nodeID string,
+	path string,
+	rule node.ConditionRule,
+	questionStore QuestionStore,
+) error {
 	// Only validate question existence and type compatibility in draft mode.
 	// Empty question is allowed in draft (e.g. after section delete we null out the reference).
 	if rule.Question == "" {
@@ -807,32 +911,37 @@ func validateDraftConditionQuestion(
 
 	questionID, err := uuid.Parse(rule.Question)
 	if err != nil {
-		return fmt.Errorf("condition node '%s' conditionRule.question '%s' is not a valid UUID", nodeID, rule.Question)
+		return fmt.Errorf("%s node '%s' %s.question '%s' is not a valid UUID", kind, nodeID, path, rule.Question)
 	}
 
 	answerable, err := questionStore.Get(ctx, questionID)
 	if err != nil {
-		return fmt.Errorf("condition node '%s' references non-existent question '%s' in conditionRule.question", nodeID, rule.Question)
+		return fmt.Errorf("%s node '%s' references non-existent question '%s' in %s.question", kind, nodeID, rule.Question, path)
 	}
 
 	q := answerable.Question()
 
 	// Validate question belongs to the form
 	if answerable.FormID() != formID {
-		return fmt.Errorf("condition node '%s' references question '%s' that belongs to a different form", nodeID, rule.Question)
+		return fmt.Errorf("%s node '%s' references question '%s' that belongs to a different form", kind, nodeID, rule.Question)
 	}
 
-	// Validate question type matches condition source (source normalized to uppercase in mapToConditionRule).
+	// Validate question type matches condition source
 	switch rule.Source {
 	case node.ConditionSourceChoice:
 		if !question.ContainsType(question.ChoiceTypes, q.Type) {
-			return fmt.Errorf("condition node '%s' with source 'CHOICE' requires question type %s, but question '%s' has type '%s'", nodeID, question.FormatAllowedTypes(question.ChoiceTypes), rule.Question, q.Type)
+			return fmt.Errorf("%s node '%s' with source 'CHOICE' requires question type %s, but question '%s' has type '%s'", kind, nodeID, question.FormatAllowedTypes(question.ChoiceTypes), rule.Question, q.Type)
 		}
 	case node.ConditionSourceNonChoice:
 		if !question.ContainsType(question.NonChoiceTypes, q.Type) {
-			return fmt.Errorf("condition node '%s' with source 'NONCHOICE' requires question type %s, but question '%s' has type '%s'", nodeID, question.FormatAllowedTypes(question.NonChoiceTypes), rule.Question, q.Type)
+			return fmt.Errorf("%s node '%s' with source 'NONCHOICE' requires question type %s, but question '%s' has type '%s'", kind, nodeID, question.FormatAllowedTypes(question.NonChoiceTypes), rule.Question, q.Type)
 		}
 	}
+
+	// Validate the operator is supported by the question type; the value is only checked on activation
+	if rule.Operator != "" && !slices.Contains(answerable.SupportedOperators(), rule.Operator) {
+		return fmt.Errorf("%s node '%s' %s.operator '%s' is not supported for question '%s' of type '%s' (supported: %s)", kind, nodeID, path, rule.Operator, rule.Question, q.Type, question.FormatOperators(answerable.SupportedOperators()))
+	}
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -482,6 +483,233 @@ func TestActivate_ConditionRuleValidation(t *testing.T) {
 	}
 }
 
+// TestActivate_CompoundConditionRule tests validation of all/any/not groups in condition rules
+func TestActivate_CompoundConditionRule(t *testing.T) {
+	t.Parallel()
+
+	formID := uuid.New()
+
+	nonChoiceRule := func(questionID uuid.UUID) map[string]any {
+		return map[string]any{"source": "nonChoice", "question": questionID.String(), "pattern": "yes"}
+	}
+
+	nested := func(rule map[string]any, depth int) map[string]any {
+		for i := 0; i < depth; i++ {
+			rule = map[string]any{"not": rule}
+		}
+		return rule
+	}
+
+	type testCase struct {
+		name        string
+		rule        func(q1, q2 uuid.UUID) map[string]any
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "valid all group with two leaf rules",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return map[string]any{"all": []any{nonChoiceRule(q1), nonChoiceRule(q2)}}
+			},
+			expectedErr: false,
+		},
+		{
+			name: "valid nested any and not groups",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return map[string]any{"any": []any{nonChoiceRule(q1), map[string]any{"not": nonChoiceRule(q2)}}}
+			},
+			expectedErr: false,
+		},
+		{
+			name: "empty all group",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return map[string]any{"all": []any{}}
+			},
+			expectedErr: true,
+		},
+		{
+			name: "group mixed with leaf fields",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				rule := nonChoiceRule(q1)
+				rule["any"] = []any{nonChoiceRule(q2)}
+				return rule
+			},
+			expectedErr: true,
+		},
+		{
+			name: "more than one group operator",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return map[string]any{"all": []any{nonChoiceRule(q1)}, "not": nonChoiceRule(q2)}
+			},
+			expectedErr: true,
+		},
+		{
+			name: "nesting at the maximum depth",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return nested(nonChoiceRule(q1), node.MaxConditionRuleDepth)
+			},
+			expectedErr: false,
+		},
+		{
+			name: "nesting deeper than the maximum depth",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return nested(nonChoiceRule(q1), node.MaxConditionRuleDepth+1)
+			},
+			expectedErr: true,
+		},
+		{
+			name: "nested leaf rule with non-existent question",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return map[string]any{"all": []any{nonChoiceRule(q1), map[string]any{"any": []any{nonChoiceRule(uuid.New())}}}}
+			},
+			expectedErr: true,
+		},
+		{
+			name: "nested leaf rule with mismatched source",
+			rule: func(q1, q2 uuid.UUID) map[string]any {
+				return map[string]any{"any": []any{
+					nonChoiceRule(q1),
+					map[string]any{"source": "choice", "question": q2.String(), "pattern": "yes"},
+				}}
+			},
+			expectedErr: true,
+		},
+	}
+
+	validator := NewValidator()
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			q1 := uuid.New()
+			q2 := uuid.New()
+			store := &mockQuestionStore{
+				questions: map[uuid.UUID]question.Answerable{
+					q1: createMockAnswerable(t, formID, question.QuestionTypeShortText),
+					q2: createMockAnswerable(t, formID, question.QuestionTypeShortText),
+				},
+			}
+
+			err := validator.Activate(ctx, formID, createWorkflow_CompoundConditionRule(t, tc.rule(q1, q2)), store)
+
+			if tc.expectedErr {
+				require.Error(t, err, "expected validation error but got nil")
+			} else {
+				require.NoError(t, err, "expected validation to pass but got error: %v", err)
+			}
+		})
+	}
+}
+
+// TestActivate_ConditionRuleOperator tests validation of typed operators in condition rules
+func TestActivate_ConditionRuleOperator(t *testing.T) {
+	t.Parallel()
+
+	formID := uuid.New()
+
+	type testCase struct {
+		name         string
+		questionType question.QuestionType
+		rule         map[string]any
+		expectedErr  bool
+	}
+
+	testCases := []testCase{
+		{
+			name:         "gte on rating question",
+			questionType: question.QuestionTypeRating,
+			rule:         map[string]any{"source": "nonChoice", "operator": "gte", "value": 4},
+			expectedErr:  false,
+		},
+		{
+			name:         "between on date question",
+			questionType: question.QuestionTypeDate,
+			rule:         map[string]any{"source": "nonChoice", "operator": "between", "value": []any{"2026-01-01", "2026-06-30"}},
+			expectedErr:  false,
+		},
+		{
+			name:         "isEmpty on short text question",
+			questionType: question.QuestionTypeShortText,
+			rule:         map[string]any{"source": "nonChoice", "operator": "isEmpty"},
+			expectedErr:  false,
+		},
+		{
+			name:         "countGte on multiple choice question",
+			questionType: question.QuestionTypeMultipleChoice,
+			rule:         map[string]any{"source": "choice", "operator": "countGte", "value": 2},
+			expectedErr:  false,
+		},
+		{
+			name:         "gt on short text question",
+			questionType: question.QuestionTypeShortText,
+			rule:         map[string]any{"source": "nonChoice", "operator": "gt", "value": "a"},
+			expectedErr:  true,
+		},
+		{
+			name:         "contains on rating question",
+			questionType: question.QuestionTypeRating,
+			rule:         map[string]any{"source": "nonChoice", "operator": "contains", "value": 4},
+			expectedErr:  true,
+		},
+		{
+			name:         "unknown operator",
+			questionType: question.QuestionTypeLinearScale,
+			rule:         map[string]any{"source": "nonChoice", "operator": "approximately", "value": 4},
+			expectedErr:  true,
+		},
+		{
+			name:         "value that does not fit the operator",
+			questionType: question.QuestionTypeLinearScale,
+			rule:         map[string]any{"source": "nonChoice", "operator": "between", "value": 4},
+			expectedErr:  true,
+		},
+		{
+			name:         "operator combined with pattern",
+			questionType: question.QuestionTypeRating,
+			rule:         map[string]any{"source": "nonChoice", "operator": "gte", "value": 4, "pattern": "^5$"},
+			expectedErr:  true,
+		},
+		{
+			name:         "value without operator",
+			questionType: question.QuestionTypeRating,
+			rule:         map[string]any{"source": "nonChoice", "pattern": "^5$", "value": 4},
+			expectedErr:  true,
+		},
+	}
+
+	validator := NewValidator()
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			questionID := uuid.New()
+			store := &mockQuestionStore{
+				questions: map[uuid.UUID]question.Answerable{
+					questionID: createMockAnswerable(t, formID, tc.questionType),
+				},
+			}
+
+			rule := map[string]any{"question": questionID.String()}
+			for key, value := range tc.rule {
+				rule[key] = value
+			}
+
+			err := validator.Activate(ctx, formID, createWorkflow_CompoundConditionRule(t, rule), store)
+
+			if tc.expectedErr {
+				require.Error(t, err, "expected validation error but got nil")
+			} else {
+				require.NoError(t, err, "expected validation to pass but got error: %v", err)
+			}
+		})
+	}
+}
+
 // TestValidateUpdateNodeIDs tests the ValidateUpdateNodeIDs method
 func TestValidateUpdateNodeIDs(t *testing.T) {
 	t.Parallel()
@@ -733,3 +961,158 @@ func TestValidateDraft_ConditionSectionOrder(t *testing.T) {
 		})
 	}
 }
+
+// TestActivate_SwitchNode tests validation of switch nodes, their cases and their graph references
+func TestActivate_SwitchNode(t *testing.T) {
+	t.Parallel()
+
+	formID := uuid.New()
+
+	type testCase struct {
+		name          string
+		modify        func(switchNode map[string]any, ids switchWorkflowIDs)
+		expectedErr   bool
+		expectedInErr string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "valid switch with a single case and default",
+			expectedErr: false,
+		},
+		{
+			name: "valid switch with a typed operator case",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				cases := switchNode["cases"].([]any)
+				rule := cases[0].(map[string]any)["rule"].(map[string]any)
+				switchNode["cases"] = append(cases, map[string]any{
+					"rule": map[string]any{"source": "nonChoice", "question": rule["question"], "operator": "isEmpty"},
+					"next": ids.end.String(),
+				})
+			},
+			expectedErr: false,
+		},
+		{
+			name: "missing default",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				delete(switchNode, "default")
+			},
+			expectedErr:   true,
+			expectedInErr: "must have a 'default' field",
+		},
+		{
+			name: "empty cases",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				switchNode["cases"] = []any{}
+			},
+			expectedErr:   true,
+			expectedInErr: "must contain at least one case",
+		},
+		{
+			name: "case without next",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				delete(switchNode["cases"].([]any)[0].(map[string]any), "next")
+			},
+			expectedErr:   true,
+			expectedInErr: "cases[0] must have a 'next' field",
+		},
+		{
+			name: "case with invalid field",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				switchNode["cases"].([]any)[0].(map[string]any)["nextTrue"] = ids.end.String()
+			},
+			expectedErr:   true,
+			expectedInErr: "contains invalid field 'nextTrue'",
+		},
+		{
+			name: "case rule with non-existent question",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				switchNode["cases"].([]any)[0].(map[string]any)["rule"] = map[string]any{"source": "nonChoice", "question": uuid.New().String(), "pattern": "yes"}
+			},
+			expectedErr:   true,
+			expectedInErr: "in cases[0].rule.question",
+		},
+		{
+			name: "case next references non-existent node",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				switchNode["cases"].([]any)[0].(map[string]any)["next"] = uuid.New().String()
+			},
+			expectedErr:   true,
+			expectedInErr: "in cases[0].next",
+		},
+		{
+			name: "default references non-existent node",
+			modify: func(switchNode map[string]any, ids switchWorkflowIDs) {
+				switchNode["default"] = uuid.New().String()
+			},
+			expectedErr:   true,
+			expectedInErr: "in default",
+		},
+	}
+
+	validator := NewValidator()
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			answerable := createMockAnswerable(t, formID, question.QuestionTypeShortText)
+			questionID := answerable.Question().ID
+			store := &mockQuestionStore{
+				questions: map[uuid.UUID]question.Answerable{questionID: answerable},
+			}
+
+			err := validator.Activate(ctx, formID, createWorkflow_Switch(t, questionID, tc.modify), store)
+
+			if tc.expectedErr {
+				require.Error(t, err, "expected validation error but got nil")
+				require.Contains(t, err.Error(), tc.expectedInErr)
+			} else {
+				require.NoError(t, err, "expected validation to pass but got error: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateConditionSectionOrder_SwitchCase tests that a switch case referencing a question whose
+// section is only visited after the switch is reported, and that sections reached only through a case
+// are reachable from the start node.
+func TestValidateConditionSectionOrder_SwitchCase(t *testing.T) {
+	t.Parallel()
+
+	formID := uuid.New()
+	var ids switchWorkflowIDs
+	captureIDs := func(switchNode map[string]any, switchIDs switchWorkflowIDs) {
+		ids = switchIDs
+	}
+
+	// The question belongs to section A, which is only reached through the switch itself
+	questionID := uuid.New()
+	workflowJSON := createWorkflow_Switch(t, questionID, captureIDs)
+
+	answerable, err := question.NewAnswerable(question.Question{
+		ID:        questionID,
+		SectionID: ids.sectionA,
+		Type:      question.QuestionTypeShortText,
+		Title:     pgtype.Text{String: "Q1", Valid: true},
+		Metadata:  []byte("{}"),
+		Order:     1,
+	}, formID)
+	require.NoError(t, err)
+	store := &mockQuestionStore{
+		questions: map[uuid.UUID]question.Answerable{questionID: answerable},
+	}
+
+	var nodes []map[string]any
+	err = json.Unmarshal(workflowJSON, &nodes)
+	require.NoError(t, err)
+
+	err = validateAllNodesReachableFromStart(nodes)
+	require.NoError(t, err)
+
+	err = validateConditionSectionOrder(context.Background(), formID, nodes, store)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "switch node '"+ids.switchID.String()+"'")
+	require.Contains(t, err.Error(), ids.sectionA.String())
+}
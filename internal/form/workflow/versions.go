@@ -0,0 +1,146 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ListVersions returns every workflow version of a form without the workflow body, newest first
+func (s *Service) ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error) {
+	methodName := "ListVersions"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	versions, err := s.queries.ListVersions(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "list workflow versions")
+		span.RecordError(err)
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, internal.ErrWorkflowNotFound
+	}
+
+	return versions, nil
+}
+
+// GetVersion retrieves one workflow version of a form
+func (s *Service) GetVersion(ctx context.Context, formID uuid.UUID, versionID uuid.UUID) (WorkflowVersion, error) {
+	methodName := "GetVersion"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	version, err := s.queries.GetVersion(ctx, GetVersionParams{
+		FormID: formID,
+		ID:     versionID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow_version", "id", versionID.String(), logger, "get workflow version")
+		span.RecordError(err)
+		return WorkflowVersion{}, err
+	}
+
+	return version, nil
+}
+
+// Diff returns the structural difference from one workflow version of a form to another
+func (s *Service) Diff(ctx context.Context, formID uuid.UUID, fromID uuid.UUID, toID uuid.UUID) (Diff, error) {
+	methodName := "Diff"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+
+	from, err := s.GetVersion(ctx, formID, fromID)
+	if err != nil {
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	to, err := s.GetVersion(ctx, formID, toID)
+	if err != nil {
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	diff, err := structuralDiff(from.Workflow, to.Workflow)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrUnmarshalDBWorkflow, err)
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	return diff, nil
+}
+
+// Rollback copies the workflow of an old version into a new draft version. The latest version is kept
+// in the history, and the copy must pass the same draft and node ID validation as Update, so a rollback
+// is rejected when nodes were added or removed (e.g. sections created or deleted) since the old version.
+func (s *Service) Rollback(ctx context.Context, formID uuid.UUID, versionID uuid.UUID, userID uuid.UUID) (WorkflowVersion, error) {
+	methodName := "Rollback"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	target, err := s.GetVersion(ctx, formID, versionID)
+	if err != nil {
+		span.RecordError(err)
+		return WorkflowVersion{}, err
+	}
+
+	latest, err := s.Get(ctx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return WorkflowVersion{}, err
+	}
+
+	err = s.validator.ValidateUpdateNodeIDs(ctx, latest.Workflow, target.Workflow)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return WorkflowVersion{}, err
+	}
+
+	err = s.validator.Validate(ctx, formID, target.Workflow, s.questionStore)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return WorkflowVersion{}, err
+	}
+
+	// The new version and the last editor of the form are written together
+	var created WorkflowVersion
+	err = s.withTransaction(ctx, func(txService *Service) error {
+		var err error
+		created, err = txService.queries.CreateVersion(ctx, CreateVersionParams{
+			FormID:     formID,
+			LastEditor: userID,
+			Workflow:   target.Workflow,
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "create workflow version for rollback")
+		}
+
+		return txService.patchFormLastEditor(ctx, formID, userID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return WorkflowVersion{}, err
+	}
+
+	logger.Info("Rolled back workflow",
+		zap.String("formId", formID.String()),
+		zap.String("fromVersionId", versionID.String()),
+		zap.Int64("fromSeq", target.Seq),
+		zap.String("newVersionId", created.ID.String()),
+	)
+
+	return created, nil
+}
@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+func TestService_Rollback(t *testing.T) {
+	t.Parallel()
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	type Params struct {
+		formID    uuid.UUID
+		versionID uuid.UUID
+		userID    uuid.UUID
+		old       []byte
+		latest    []byte
+	}
+
+	type testCase struct {
+		name        string
+		setupMock   func(mq *mockQuerier, mv *mockValidator, mfp *mockFormStore, p Params)
+		expectedErr error
+	}
+
+	// A rollback that passes validation writes in a transaction, which test/integration/workflow covers
+	testCases := []testCase{
+		{
+			name: "version of another form",
+			setupMock: func(mq *mockQuerier, mv *mockValidator, mfp *mockFormStore, p Params) {
+				mq.On("GetVersion", mock.Anything, GetVersionParams{FormID: p.formID, ID: p.versionID}).
+					Return(WorkflowVersion{}, pgx.ErrNoRows).Once()
+			},
+			expectedErr: handlerutil.ErrNotFound,
+		},
+		{
+			name: "node IDs changed since the old version",
+			setupMock: func(mq *mockQuerier, mv *mockValidator, mfp *mockFormStore, p Params) {
+				mq.On("GetVersion", mock.Anything, GetVersionParams{FormID: p.formID, ID: p.versionID}).
+					Return(WorkflowVersion{ID: p.versionID, FormID: p.formID, Workflow: p.old}, nil).Once()
+				mq.On("Get", mock.Anything, p.formID).
+					Return(WorkflowVersion{FormID: p.formID, Workflow: p.latest}, nil).Once()
+				mv.On("ValidateUpdateNodeIDs", mock.Anything, p.latest, p.old).Return(errors.New("node IDs have changed")).Once()
+			},
+			expectedErr: internal.ErrWorkflowValidationFailed,
+		},
+		{
+			name: "old version fails draft validation",
+			setupMock: func(mq *mockQuerier, mv *mockValidator, mfp *mockFormStore, p Params) {
+				mq.On("GetVersion", mock.Anything, GetVersionParams{FormID: p.formID, ID: p.versionID}).
+					Return(WorkflowVersion{ID: p.versionID, FormID: p.formID, Workflow: p.old}, nil).Once()
+				mq.On("Get", mock.Anything, p.formID).
+					Return(WorkflowVersion{FormID: p.formID, Workflow: p.latest}, nil).Once()
+				mv.On("ValidateUpdateNodeIDs", mock.Anything, p.latest, p.old).Return(nil).Once()
+				mv.On("Validate", mock.Anything, p.formID, p.old, mock.Anything).Return(errors.New("question not found")).Once()
+			},
+			expectedErr: internal.ErrWorkflowValidationFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := Params{
+				formID:    uuid.New(),
+				versionID: uuid.New(),
+				userID:    uuid.New(),
+				old:       createWorkflow_SimpleValid(t),
+				latest:    createWorkflow_ComplexValid(t),
+			}
+			mockQuerier := new(mockQuerier)
+			mockValidator := new(mockValidator)
+			mfp := new(mockFormStore)
+			service := NewServiceForTesting(zap.NewNop(), tracer, mockQuerier, mfp, mockValidator, nil)
+			tc.setupMock(mockQuerier, mockValidator, mfp, p)
+
+			result, err := service.Rollback(context.Background(), p.formID, p.versionID, p.userID)
+
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Empty(t, result)
+
+			mockQuerier.AssertExpectations(t)
+			mockValidator.AssertExpectations(t)
+			mfp.AssertExpectations(t)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
@@ -27,6 +28,8 @@ type Store interface {
 	List(ctx context.Context, userID uuid.UUID, filter *FilterRequest, page int, size int) ([]ListRow, error)
 	Count(ctx context.Context, userID uuid.UUID, filter *FilterRequest) (int64, error)
 	Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (GetRow, error)
+	GetExportContent(ctx context.Context, jobID uuid.UUID) (GetExportContentRow, error)
+	GetReminderContent(ctx context.Context, reminderID uuid.UUID) (GetReminderContentRow, error)
 	Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, arg UserInboxMessageFilter) (UpdateRow, error)
 }
 
@@ -62,6 +65,31 @@ type ResponseDetail struct {
 	UserInboxMessageFilter
 }
 
+// ExportContent is the content of an export message; FileID is set when the export completed
+type ExportContent struct {
+	JobID       string     `json:"jobId"`
+	FormID      string     `json:"formId"`
+	FormTitle   string     `json:"formTitle"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	FileID      string     `json:"fileId,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+// ReminderContent is the content of a deadline reminder message; Deadline is the current deadline of the form
+type ReminderContent struct {
+	ReminderID  string     `json:"reminderId"`
+	FormID      string     `json:"formId"`
+	FormTitle   string     `json:"formTitle"`
+	Deadline    *time.Time `json:"deadline"`
+	HoursBefore int32      `json:"hoursBefore"`
+}
+
+type CountResponse struct {
+	Count int64 `json:"count"`
+}
+
 type Handler struct {
 	logger        *zap.Logger
 	tracer        trace.Tracer
@@ -188,6 +216,47 @@ func (h *Handler) GetMessageContent(ctx context.Context, contentType ContentType
 			user.ConvertEmailsToSlice(currentForm.LastEditorEmails),
 		)
 		return response, nil
+	case ContentTypeExport:
+		exportContent, err := h.store.GetExportContent(traceCtx, contentID)
+		if err != nil {
+			span.RecordError(err)
+			return ExportContent{}, err
+		}
+		content := ExportContent{
+			JobID:     exportContent.ID.String(),
+			FormID:    exportContent.FormID.String(),
+			FormTitle: exportContent.FormTitle,
+			Format:    exportContent.Format,
+			Status:    strings.ToUpper(string(exportContent.Status)),
+		}
+		if exportContent.FileID.Valid {
+			content.FileID = uuid.UUID(exportContent.FileID.Bytes).String()
+		}
+		if exportContent.Error.Valid {
+			content.Error = exportContent.Error.String
+		}
+		if exportContent.CompletedAt.Valid {
+			completedAt := exportContent.CompletedAt.Time
+			content.CompletedAt = &completedAt
+		}
+		return content, nil
+	case ContentTypeReminder:
+		reminderContent, err := h.store.GetReminderContent(traceCtx, contentID)
+		if err != nil {
+			span.RecordError(err)
+			return ReminderContent{}, err
+		}
+		content := ReminderContent{
+			ReminderID:  reminderContent.ID.String(),
+			FormID:      reminderContent.FormID.String(),
+			FormTitle:   reminderContent.FormTitle,
+			HoursBefore: reminderContent.HoursBefore,
+		}
+		if reminderContent.Deadline.Valid {
+			deadline := reminderContent.Deadline.Time
+			content.Deadline = &deadline
+		}
+		return content, nil
 	case ContentTypeText:
 		return nil, nil
 	}
@@ -247,13 +316,41 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
 }
 
+// CountHandler returns the number of inbox messages of the current user that match
+// the filter query parameters, e.g. `?isRead=false` for the unread count.
+func (h *Handler) CountHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CountHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	filter, err := ParseFilterRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	total, err := h.store.Count(traceCtx, currentUser.ID, filter)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, CountResponse{Count: total})
+}
+
 func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, h.logger)
 
 	pathID := r.PathValue("id")
-	id, err := internal.ParseUUID(pathID)
+	id, err := handlerutil.ParseUUID(pathID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -318,7 +415,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	logger := logutil.WithContext(traceCtx, h.logger)
 
 	pathID := r.PathValue("id")
-	id, err := internal.ParseUUID(pathID)
+	id, err := handlerutil.ParseUUID(pathID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -181,6 +181,138 @@ func (_c *MockStore_Get_Call) RunAndReturn(run func(ctx context.Context, id uuid
 	return _c
 }
 
+// GetExportContent provides a mock function for the type MockStore
+func (_mock *MockStore) GetExportContent(ctx context.Context, jobID uuid.UUID) (inbox.GetExportContentRow, error) {
+	ret := _mock.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExportContent")
+	}
+
+	var r0 inbox.GetExportContentRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (inbox.GetExportContentRow, error)); ok {
+		return returnFunc(ctx, jobID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) inbox.GetExportContentRow); ok {
+		r0 = returnFunc(ctx, jobID)
+	} else {
+		r0 = ret.Get(0).(inbox.GetExportContentRow)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_GetExportContent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetExportContent'
+type MockStore_GetExportContent_Call struct {
+	*mock.Call
+}
+
+// GetExportContent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jobID uuid.UUID
+func (_e *MockStore_Expecter) GetExportContent(ctx interface{}, jobID interface{}) *MockStore_GetExportContent_Call {
+	return &MockStore_GetExportContent_Call{Call: _e.mock.On("GetExportContent", ctx, jobID)}
+}
+
+func (_c *MockStore_GetExportContent_Call) Run(run func(ctx context.Context, jobID uuid.UUID)) *MockStore_GetExportContent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_GetExportContent_Call) Return(getExportContentRow inbox.GetExportContentRow, err error) *MockStore_GetExportContent_Call {
+	_c.Call.Return(getExportContentRow, err)
+	return _c
+}
+
+func (_c *MockStore_GetExportContent_Call) RunAndReturn(run func(ctx context.Context, jobID uuid.UUID) (inbox.GetExportContentRow, error)) *MockStore_GetExportContent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetReminderContent provides a mock function for the type MockStore
+func (_mock *MockStore) GetReminderContent(ctx context.Context, reminderID uuid.UUID) (inbox.GetReminderContentRow, error) {
+	ret := _mock.Called(ctx, reminderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReminderContent")
+	}
+
+	var r0 inbox.GetReminderContentRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (inbox.GetReminderContentRow, error)); ok {
+		return returnFunc(ctx, reminderID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) inbox.GetReminderContentRow); ok {
+		r0 = returnFunc(ctx, reminderID)
+	} else {
+		r0 = ret.Get(0).(inbox.GetReminderContentRow)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, reminderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_GetReminderContent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetReminderContent'
+type MockStore_GetReminderContent_Call struct {
+	*mock.Call
+}
+
+// GetReminderContent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - reminderID uuid.UUID
+func (_e *MockStore_Expecter) GetReminderContent(ctx interface{}, reminderID interface{}) *MockStore_GetReminderContent_Call {
+	return &MockStore_GetReminderContent_Call{Call: _e.mock.On("GetReminderContent", ctx, reminderID)}
+}
+
+func (_c *MockStore_GetReminderContent_Call) Run(run func(ctx context.Context, reminderID uuid.UUID)) *MockStore_GetReminderContent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_GetReminderContent_Call) Return(getReminderContentRow inbox.GetReminderContentRow, err error) *MockStore_GetReminderContent_Call {
+	_c.Call.Return(getReminderContentRow, err)
+	return _c
+}
+
+func (_c *MockStore_GetReminderContent_Call) RunAndReturn(run func(ctx context.Context, reminderID uuid.UUID) (inbox.GetReminderContentRow, error)) *MockStore_GetReminderContent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function for the type MockStore
 func (_mock *MockStore) List(ctx context.Context, userID uuid.UUID, filter *inbox.FilterRequest, page int, size int) ([]inbox.ListRow, error) {
 	ret := _mock.Called(ctx, userID, filter, page, size)
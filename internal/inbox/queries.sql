@@ -3,9 +3,16 @@ INSERT INTO inbox_message (posted_by, type, content_id)
 VALUES (@posted_by, @type, @content_id)
 RETURNING *;
 
+-- name: GetMessageByContent :one
+SELECT * FROM inbox_message
+WHERE type = @type AND content_id = @content_id
+ORDER BY created_at
+LIMIT 1;
+
 -- name: CreateUserInboxBulk :many
 INSERT INTO user_inbox_messages (user_id, message_id)
 SELECT unnest(@user_ids::uuid[]), @message_id::uuid
+ON CONFLICT (user_id, message_id) DO NOTHING
 RETURNING *;
 
 -- name: Get :one
@@ -13,27 +20,43 @@ SELECT
     uim.*,
     im.*,
     CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title END AS title,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') AND u.type = 'unit' THEN u.name END AS unit_name
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.id = @user_inbox_message_id AND uim.user_id = @user_id;
 
+-- name: GetExportContent :one
+SELECT ej.id, ej.form_id, f.title AS form_title, ej.format, ej.status, ej.file_id, ej.error, ej.completed_at
+FROM response_export_jobs ej
+JOIN forms f ON ej.form_id = f.id
+WHERE ej.id = @id;
+
+-- name: GetReminderContent :one
+SELECT dr.id, dr.form_id, f.title AS form_title, f.deadline, dr.hours_before
+FROM form_deadline_reminders dr
+JOIN forms f ON dr.form_id = f.id
+WHERE dr.id = @id;
+
 -- name: List :many
 SELECT 
     uim.*,
     im.*,
     CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title END AS title,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') AND u.type = 'unit' THEN u.name END AS unit_name
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = @user_id
@@ -41,7 +64,7 @@ WHERE uim.user_id = @user_id
   AND (sqlc.narg(is_starred)::boolean IS NULL OR uim.is_starred = sqlc.narg(is_starred))
   AND (uim.is_archived = COALESCE(sqlc.narg(is_archived)::boolean, false))
   AND (@search::text = '' OR @search::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || @search::text || '%'
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g') ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) ELSE '' END ILIKE '%' || @search::text || '%'
   ))
@@ -53,7 +76,9 @@ SELECT
     COUNT(*) AS total
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = @user_id
@@ -61,7 +86,7 @@ WHERE uim.user_id = @user_id
   AND (sqlc.narg(is_starred)::boolean IS NULL OR uim.is_starred = sqlc.narg(is_starred))
   AND (uim.is_archived = COALESCE(sqlc.narg(is_archived)::boolean, false))
   AND (@search::text = '' OR @search::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || @search::text || '%'
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g') ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) ELSE '' END ILIKE '%' || @search::text || '%'
   ));
@@ -70,12 +95,14 @@ WHERE uim.user_id = @user_id
 UPDATE user_inbox_messages AS uim
 SET is_read = @is_read, is_starred = @is_starred, is_archived = @is_archived
 FROM inbox_message AS im
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.message_id = im.id AND uim.id = @id AND uim.user_id = @user_id
 RETURNING uim.*, im.*,
 CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) END AS preview_message,
-CASE WHEN im.type = 'form' THEN f.title END AS title,
-CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name;
+CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title END AS title,
+CASE WHEN im.type IN ('form', 'export', 'reminder') THEN COALESCE(o.name, u.name) END AS org_name,
+CASE WHEN im.type IN ('form', 'export', 'reminder') AND u.type = 'unit' THEN u.name END AS unit_name;
@@ -41,6 +41,7 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (I
 const createUserInboxBulk = `-- name: CreateUserInboxBulk :many
 INSERT INTO user_inbox_messages (user_id, message_id)
 SELECT unnest($1::uuid[]), $2::uuid
+ON CONFLICT (user_id, message_id) DO NOTHING
 RETURNING id, user_id, message_id, is_read, is_starred, is_archived
 `
 
@@ -81,12 +82,14 @@ SELECT
     uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived,
     im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at,
     CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title END AS title,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') AND u.type = 'unit' THEN u.name END AS unit_name
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.id = $1 AND uim.user_id = $2
@@ -140,17 +143,107 @@ func (q *Queries) Get(ctx context.Context, arg GetParams) (GetRow, error) {
 	return i, err
 }
 
+const getExportContent = `-- name: GetExportContent :one
+SELECT ej.id, ej.form_id, f.title AS form_title, ej.format, ej.status, ej.file_id, ej.error, ej.completed_at
+FROM response_export_jobs ej
+JOIN forms f ON ej.form_id = f.id
+WHERE ej.id = $1
+`
+
+type GetExportContentRow struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	FormTitle   string
+	Format      string
+	Status      ExportJobStatus
+	FileID      pgtype.UUID
+	Error       pgtype.Text
+	CompletedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetExportContent(ctx context.Context, id uuid.UUID) (GetExportContentRow, error) {
+	row := q.db.QueryRow(ctx, getExportContent, id)
+	var i GetExportContentRow
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.FormTitle,
+		&i.Format,
+		&i.Status,
+		&i.FileID,
+		&i.Error,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getMessageByContent = `-- name: GetMessageByContent :one
+SELECT id, posted_by, type, content_id, created_at, updated_at FROM inbox_message
+WHERE type = $1 AND content_id = $2
+ORDER BY created_at
+LIMIT 1
+`
+
+type GetMessageByContentParams struct {
+	Type      ContentType
+	ContentID uuid.UUID
+}
+
+func (q *Queries) GetMessageByContent(ctx context.Context, arg GetMessageByContentParams) (InboxMessage, error) {
+	row := q.db.QueryRow(ctx, getMessageByContent, arg.Type, arg.ContentID)
+	var i InboxMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PostedBy,
+		&i.Type,
+		&i.ContentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReminderContent = `-- name: GetReminderContent :one
+SELECT dr.id, dr.form_id, f.title AS form_title, f.deadline, dr.hours_before
+FROM form_deadline_reminders dr
+JOIN forms f ON dr.form_id = f.id
+WHERE dr.id = $1
+`
+
+type GetReminderContentRow struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	FormTitle   string
+	Deadline    pgtype.Timestamptz
+	HoursBefore int32
+}
+
+func (q *Queries) GetReminderContent(ctx context.Context, id uuid.UUID) (GetReminderContentRow, error) {
+	row := q.db.QueryRow(ctx, getReminderContent, id)
+	var i GetReminderContentRow
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.FormTitle,
+		&i.Deadline,
+		&i.HoursBefore,
+	)
+	return i, err
+}
+
 const list = `-- name: List :many
 SELECT 
     uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived,
     im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at,
     CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title END AS title,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'export', 'reminder') AND u.type = 'unit' THEN u.name END AS unit_name
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = $1
@@ -158,7 +251,7 @@ WHERE uim.user_id = $1
   AND ($3::boolean IS NULL OR uim.is_starred = $3)
   AND (uim.is_archived = COALESCE($4::boolean, false))
   AND ($5::text = '' OR $5::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || $5::text || '%'
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title ELSE '' END ILIKE '%' || $5::text || '%'
     OR CASE WHEN im.type = 'form' THEN regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g') ELSE '' END ILIKE '%' || $5::text || '%'
     OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) ELSE '' END ILIKE '%' || $5::text || '%'
   ))
@@ -245,7 +338,9 @@ SELECT
     COUNT(*) AS total
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = $1
@@ -253,7 +348,7 @@ WHERE uim.user_id = $1
   AND ($3::boolean IS NULL OR uim.is_starred = $3)
   AND (uim.is_archived = COALESCE($4::boolean, false))
   AND ($5::text = '' OR $5::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || $5::text || '%'
+    CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title ELSE '' END ILIKE '%' || $5::text || '%'
     OR CASE WHEN im.type = 'form' THEN regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g') ELSE '' END ILIKE '%' || $5::text || '%'
     OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) ELSE '' END ILIKE '%' || $5::text || '%'
   ))
@@ -284,15 +379,17 @@ const update = `-- name: Update :one
 UPDATE user_inbox_messages AS uim
 SET is_read = $1, is_starred = $2, is_archived = $3
 FROM inbox_message AS im
-LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
+LEFT JOIN response_export_jobs ej ON im.type = 'export' AND im.content_id = ej.id
+LEFT JOIN form_deadline_reminders dr ON im.type = 'reminder' AND im.content_id = dr.id
+LEFT JOIN forms f ON (im.type = 'form' AND im.content_id = f.id) OR (im.type = 'export' AND ej.form_id = f.id) OR (im.type = 'reminder' AND dr.form_id = f.id)
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.message_id = im.id AND uim.id = $4 AND uim.user_id = $5
 RETURNING uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived, im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at,
 CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(regexp_replace(COALESCE(f.description_html, ''), '<[^>]*>', '', 'g'), 25)) END AS preview_message,
-CASE WHEN im.type = 'form' THEN f.title END AS title,
-CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+CASE WHEN im.type IN ('form', 'export', 'reminder') THEN f.title END AS title,
+CASE WHEN im.type IN ('form', 'export', 'reminder') THEN COALESCE(o.name, u.name) END AS org_name,
+CASE WHEN im.type IN ('form', 'export', 'reminder') AND u.type = 'unit' THEN u.name END AS unit_name
 `
 
 type UpdateParams struct {
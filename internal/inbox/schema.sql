@@ -1,6 +1,8 @@
 CREATE TYPE content_type AS ENUM(
     'text',
-    'form'
+    'form',
+    'export',
+    'reminder'
 );
 
 CREATE TABLE IF NOT EXISTS inbox_message(
@@ -12,11 +14,14 @@ CREATE TABLE IF NOT EXISTS inbox_message(
     updated_at TIMESTAMP NOT NULL DEFAULT now()
 );
 
+CREATE INDEX IF NOT EXISTS idx_inbox_message_content ON inbox_message(type, content_id);
+
 CREATE TABLE IF NOT EXISTS user_inbox_messages (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID NOT NULL references users(id) ON DELETE CASCADE,
     message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
     is_read boolean NOT NULL DEFAULT false,
     is_starred boolean NOT NULL DEFAULT false,
-    is_archived boolean NOT NULL DEFAULT false
+    is_archived boolean NOT NULL DEFAULT false,
+    UNIQUE(user_id, message_id)
 );
@@ -2,10 +2,12 @@ package inbox
 
 import (
 	"context"
+	"errors"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
@@ -14,10 +16,13 @@ import (
 
 type Querier interface {
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (InboxMessage, error)
+	GetMessageByContent(ctx context.Context, arg GetMessageByContentParams) (InboxMessage, error)
 	CreateUserInboxBulk(ctx context.Context, arg CreateUserInboxBulkParams) ([]UserInboxMessage, error)
 	List(ctx context.Context, arg ListParams) ([]ListRow, error)
 	ListCount(ctx context.Context, arg ListCountParams) (int64, error)
 	Get(ctx context.Context, arg GetParams) (GetRow, error)
+	GetExportContent(ctx context.Context, id uuid.UUID) (GetExportContentRow, error)
+	GetReminderContent(ctx context.Context, id uuid.UUID) (GetReminderContentRow, error)
 	Update(ctx context.Context, arg UpdateParams) (UpdateRow, error)
 }
 
@@ -35,28 +40,51 @@ func NewService(logger *zap.Logger, db DBTX) *Service {
 	}
 }
 
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:  s.logger,
+		queries: New(tx),
+		tracer:  s.tracer,
+	}
+}
+
 // Create registers a new inbox message and delivers it to the given set of users.
 //
 // The purpose of this function is to provide a single entry point for creating
 // a message entity and ensuring it is visible in the inbox of all target users.
-// On success, it returns the unique identifier of the created message.
+// If a message for the same content already exists it is reused, and users who
+// already have it in their inbox are skipped, so calling Create again for the
+// same content only delivers to new recipients.
+// On success, it returns the unique identifier of the message.
 func (s *Service) Create(ctx context.Context, contentType ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Create")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	message, err := s.queries.CreateMessage(traceCtx, CreateMessageParams{
+	message, err := s.queries.GetMessageByContent(traceCtx, GetMessageByContentParams{
 		Type:      contentType,
 		ContentID: contentID,
-		PostedBy:  postByUnitID,
 	})
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "create inbox message")
-		span.RecordError(err)
-		return uuid.Nil, err
+		if !errors.Is(err, pgx.ErrNoRows) {
+			err = databaseutil.WrapDBError(err, logger, "get inbox message by content")
+			span.RecordError(err)
+			return uuid.Nil, err
+		}
+
+		message, err = s.queries.CreateMessage(traceCtx, CreateMessageParams{
+			Type:      contentType,
+			ContentID: contentID,
+			PostedBy:  postByUnitID,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "create inbox message")
+			span.RecordError(err)
+			return uuid.Nil, err
+		}
 	}
 
-	_, err = s.queries.CreateUserInboxBulk(traceCtx, CreateUserInboxBulkParams{
+	delivered, err := s.queries.CreateUserInboxBulk(traceCtx, CreateUserInboxBulkParams{
 		UserIds:   userIDs,
 		MessageID: message.ID,
 	})
@@ -69,6 +97,7 @@ func (s *Service) Create(ctx context.Context, contentType ContentType, contentID
 	logger.Info("Created inbox message",
 		zap.String("message_id", message.ID.String()),
 		zap.Int("recipients", len(userIDs)),
+		zap.Int("delivered", len(delivered)),
 	)
 
 	return message.ID, nil
@@ -186,6 +215,38 @@ func (s *Service) Get(ctx context.Context, id uuid.UUID, userID uuid.UUID) (GetR
 	return message, err
 }
 
+// GetExportContent returns the export job an export message points at
+func (s *Service) GetExportContent(ctx context.Context, jobID uuid.UUID) (GetExportContentRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetExportContent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	content, err := s.queries.GetExportContent(traceCtx, jobID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_export_jobs", "id", jobID.String(), logger, "get export job of inbox message")
+		span.RecordError(err)
+		return GetExportContentRow{}, err
+	}
+
+	return content, nil
+}
+
+// GetReminderContent returns the deadline reminder a reminder message points at
+func (s *Service) GetReminderContent(ctx context.Context, reminderID uuid.UUID) (GetReminderContentRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetReminderContent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	content, err := s.queries.GetReminderContent(traceCtx, reminderID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_deadline_reminders", "id", reminderID.String(), logger, "get deadline reminder of inbox message")
+		span.RecordError(err)
+		return GetReminderContentRow{}, err
+	}
+
+	return content, nil
+}
+
 func (s *Service) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, arg UserInboxMessageFilter) (UpdateRow, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Update")
 	defer span.End()
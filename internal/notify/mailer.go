@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrRejected marks a send that failed for good, such as an address the mail server refuses; it is not
+// retried
+var ErrRejected = errors.New("message rejected")
+
+// Message is a rendered email
+type Message struct {
+	// ID identifies the email; drivers derive its Message-ID from it
+	ID      uuid.UUID
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
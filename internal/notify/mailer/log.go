@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/notify"
+)
+
+// Log appends emails to a file instead of sending them, so that they can be read in development
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLog(path string) (*Log, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required for the log driver")
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail log directory: %w", err)
+	}
+
+	return &Log{path: path}, nil
+}
+
+func (l *Log) Send(_ context.Context, msg notify.Message) error {
+	var entry strings.Builder
+	entry.WriteString(strings.Repeat("=", 72) + "\n")
+	fmt.Fprintf(&entry, "Date: %s\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&entry, "Message-ID: %s\n", msg.ID)
+	fmt.Fprintf(&entry, "To: %s\n", msg.To)
+	fmt.Fprintf(&entry, "Subject: %s\n", msg.Subject)
+	entry.WriteString("\n" + msg.Text + "\n")
+	entry.WriteString("\n--- HTML ---\n" + msg.HTML + "\n\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+
+	_, err = file.WriteString(entry.String())
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write mail log: %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,33 @@
+// Package mailer provides the drivers that send the emails queued by the notify service
+package mailer
+
+import (
+	"fmt"
+
+	"NYCU-SDC/core-system-backend/internal/notify"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverLog  = "log"
+)
+
+type Config struct {
+	// Driver is DriverSMTP, or DriverLog to write emails to a file instead of sending them in development
+	Driver string
+	// LogPath is the file the log driver appends emails to
+	LogPath string
+	SMTP    SMTPConfig
+}
+
+// New returns the mailer of the configured driver
+func New(cfg Config) (notify.Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTP(cfg.SMTP)
+	case DriverLog:
+		return NewLog(cfg.LogPath)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
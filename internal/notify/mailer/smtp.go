@@ -0,0 +1,235 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/notify"
+)
+
+// implicitTLSPort is the submission port whose connections are TLS from the start (RFC 8314); on other
+// ports the connection is upgraded with STARTTLS when the server offers it
+const implicitTLSPort = "465"
+
+type SMTPConfig struct {
+	Host string
+	Port string
+	// Username and Password authenticate with PLAIN auth, which is only done over TLS or to localhost;
+	// leave Username empty for servers that do not require authentication
+	Username string
+	Password string
+	// From is the sender, e.g. "Core System <noreply@example.com>"
+	From string
+}
+
+// SMTP sends emails through a mail submission server, one connection per email
+type SMTP struct {
+	host     string
+	port     string
+	auth     smtp.Auth
+	from     *mail.Address
+	hostname string
+	dialer   net.Dialer
+	// tlsConfig verifies the server for implicit TLS and STARTTLS
+	tlsConfig *tls.Config
+}
+
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" || cfg.Port == "" {
+		return nil, errors.New("host and port are required for the smtp driver")
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp sender %q: %w", cfg.From, err)
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	// The sender's domain names the client in HELO and qualifies Message-IDs
+	hostname := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	return &SMTP{
+		host:      cfg.Host,
+		port:      cfg.Port,
+		auth:      auth,
+		from:      from,
+		hostname:  hostname,
+		tlsConfig: &tls.Config{ServerName: cfg.Host},
+	}, nil
+}
+
+// Send delivers msg to the server. Addresses and messages that the server refuses with a permanent
+// (5xx) reply are reported as notify.ErrRejected.
+func (s *SMTP) Send(ctx context.Context, msg notify.Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%w: invalid recipient %q: %w", notify.ErrRejected, msg.To, err)
+	}
+
+	body, err := s.compose(msg, to)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	deadline, ok := ctx.Deadline()
+	if ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return fmt.Errorf("failed to greet smtp server: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	err = client.Hello(s.hostname)
+	if err != nil {
+		return fmt.Errorf("smtp HELO failed: %w", err)
+	}
+
+	_, isTLS := conn.(*tls.Conn)
+	if !isTLS {
+		startTLS, _ := client.Extension("STARTTLS")
+		if startTLS {
+			err = client.StartTLS(s.tlsConfig)
+			if err != nil {
+				return fmt.Errorf("smtp STARTTLS failed: %w", err)
+			}
+		}
+	}
+
+	if s.auth != nil {
+		err = client.Auth(s.auth)
+		if err != nil {
+			return fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+
+	err = client.Mail(s.from.Address)
+	if err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+
+	err = client.Rcpt(to.Address)
+	if err != nil {
+		return rejected(fmt.Errorf("smtp RCPT TO failed: %w", err))
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	_, err = writer.Write(body)
+	if err != nil {
+		return fmt.Errorf("failed to write smtp message: %w", err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return rejected(fmt.Errorf("smtp server did not accept the message: %w", err))
+	}
+
+	// The message is accepted once DATA is acknowledged; a failed QUIT does not undo that
+	_ = client.Quit()
+	return nil
+}
+
+func (s *SMTP) dial(ctx context.Context) (net.Conn, error) {
+	address := net.JoinHostPort(s.host, s.port)
+	if s.port == implicitTLSPort {
+		dialer := tls.Dialer{NetDialer: &s.dialer, Config: s.tlsConfig}
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+	return s.dialer.DialContext(ctx, "tcp", address)
+}
+
+// compose builds a multipart/alternative message with the text and HTML bodies of msg
+func (s *SMTP) compose(msg notify.Message, to *mail.Address) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	var message bytes.Buffer
+	for _, field := range [][2]string{
+		{"From", s.from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", msg.ID, s.hostname)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()})},
+	} {
+		message.WriteString(field[0] + ": " + field[1] + "\r\n")
+	}
+	message.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=utf-8", body: msg.Text},
+		{contentType: "text/html; charset=utf-8", body: msg.HTML},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(writer)
+		_, err = encoder.Write([]byte(part.body))
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := parts.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	message.Write(buf.Bytes())
+	return message.Bytes(), nil
+}
+
+// rejected marks err as notify.ErrRejected if the server replied with a permanent failure
+func rejected(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %w", notify.ErrRejected, err)
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer is a minimal SMTP server that accepts or refuses every recipient and records the
+// messages it accepts
+type fakeSMTPServer struct {
+	listener net.Listener
+	// rcptReply is the reply to RCPT TO
+	rcptReply string
+
+	mu        sync.Mutex
+	envelopes []envelope
+}
+
+type envelope struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T, rcptReply string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener, rcptReply: rcptReply}
+	go server.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = io.WriteString(conn, line+"\r\n")
+	}
+
+	reply("220 fake.example.com ESMTP")
+	var current envelope
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 fake.example.com")
+		case "MAIL":
+			current = envelope{from: strings.TrimPrefix(command, "MAIL FROM:")}
+			reply("250 OK")
+		case "RCPT":
+			current.to = append(current.to, strings.TrimPrefix(command, "RCPT TO:"))
+			reply(s.rcptReply)
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.envelopes = append(s.envelopes, current)
+			s.mu.Unlock()
+			reply("250 OK: queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) received() []envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]envelope(nil), s.envelopes...)
+}
+
+func TestSMTP_Send(t *testing.T) {
+	testCases := []struct {
+		name        string
+		rcptReply   string
+		to          string
+		expectedErr error
+		validate    func(t *testing.T, received []envelope)
+	}{
+		{
+			name:      "Message with text and HTML bodies is accepted",
+			rcptReply: "250 OK",
+			to:        "Alice <alice@example.com>",
+			validate: func(t *testing.T, received []envelope) {
+				require.Len(t, received, 1)
+				require.Equal(t, "<noreply@core.example.com>", received[0].from)
+				require.Equal(t, []string{"<alice@example.com>"}, received[0].to)
+
+				msg, err := mail.ReadMessage(strings.NewReader(received[0].data))
+				require.NoError(t, err)
+
+				subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+				require.NoError(t, err)
+				require.Equal(t, "Your response to 社團招新 has been received", subject)
+				require.Equal(t, `"Core System" <noreply@core.example.com>`, msg.Header.Get("From"))
+				require.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@core.example.com>"))
+
+				mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+				require.NoError(t, err)
+				require.Equal(t, "multipart/alternative", mediaType)
+
+				parts := multipart.NewReader(msg.Body, params["boundary"])
+				bodies := map[string]string{}
+				for {
+					part, err := parts.NextPart()
+					if err == io.EOF {
+						break
+					}
+					require.NoError(t, err)
+					body, err := io.ReadAll(part)
+					require.NoError(t, err)
+					// Lines end with CRLF on the wire
+					bodies[strings.SplitN(part.Header.Get("Content-Type"), ";", 2)[0]] = strings.ReplaceAll(string(body), "\r\n", "\n")
+				}
+				require.Equal(t, "Hi Alice,\nWe received your response.", bodies["text/plain"])
+				require.Equal(t, "<p>Hi Alice,</p><p>We received your response.</p>", bodies["text/html"])
+			},
+		},
+		{
+			name:        "Recipient refused by the server is rejected",
+			rcptReply:   "550 No such user",
+			to:          "nobody@example.com",
+			expectedErr: notify.ErrRejected,
+		},
+		{
+			name:        "Invalid recipient is rejected without connecting",
+			rcptReply:   "250 OK",
+			to:          "not an address",
+			expectedErr: notify.ErrRejected,
+			validate: func(t *testing.T, received []envelope) {
+				require.Empty(t, received)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tc.rcptReply)
+			host, port, err := net.SplitHostPort(server.listener.Addr().String())
+			require.NoError(t, err)
+
+			mailer, err := NewSMTP(SMTPConfig{Host: host, Port: port, From: "Core System <noreply@core.example.com>"})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err = mailer.Send(ctx, notify.Message{
+				ID:      uuid.New(),
+				To:      tc.to,
+				Subject: "Your response to 社團招新 has been received",
+				Text:    "Hi Alice,\nWe received your response.",
+				HTML:    "<p>Hi Alice,</p><p>We received your response.</p>",
+			})
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.validate != nil {
+				tc.validate(t, server.received())
+			}
+		})
+	}
+}
+
+func TestSMTP_SendToUnreachableServerIsRetried(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	mailer, err := NewSMTP(SMTPConfig{Host: host, Port: port, From: "noreply@core.example.com"})
+	require.NoError(t, err)
+
+	err = mailer.Send(context.Background(), notify.Message{ID: uuid.New(), To: "alice@example.com", Subject: "Hi"})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, notify.ErrRejected)
+}
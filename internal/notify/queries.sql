@@ -0,0 +1,93 @@
+-- name: Enqueue :execrows
+INSERT INTO notifications (kind, recipient_id, to_address, subject, text_body, html_body, dedupe_key)
+VALUES (@kind, @recipient_id, @to_address, @subject, @text_body, @html_body, @dedupe_key)
+ON CONFLICT (dedupe_key) DO NOTHING;
+
+-- name: GetByDedupeKey :one
+SELECT * FROM notifications WHERE dedupe_key = @dedupe_key;
+
+-- name: GetRecipient :one
+-- Returns the name and the oldest email address of a user
+SELECT u.id, u.name, u.username, e.value AS email
+FROM users u
+JOIN LATERAL (
+    SELECT value FROM user_emails
+    WHERE user_id = u.id
+    ORDER BY created_at, value
+    LIMIT 1
+) e ON true
+WHERE u.id = @id;
+
+-- name: GetForm :one
+SELECT id, title, message_after_submission, deadline, created_by FROM forms WHERE id = @id;
+
+-- name: ListDueDigests :many
+-- Lists the forms with responses submitted since their last digest, up to until, whose last digest was
+-- made up to due_before
+SELECT f.id, f.title, f.created_by, d.digested_until,
+    COUNT(r.id)::int AS new_responses,
+    (SELECT COUNT(*) FROM form_responses t WHERE t.form_id = f.id AND t.progress = 'submitted')::int AS total_responses
+FROM forms f
+LEFT JOIN form_response_digests d ON d.form_id = f.id
+JOIN form_responses r ON r.form_id = f.id
+    AND r.progress = 'submitted'
+    AND r.submitted_at > COALESCE(d.digested_until, '-infinity'::timestamptz)
+    AND r.submitted_at <= @until
+WHERE COALESCE(d.digested_until, '-infinity'::timestamptz) <= @due_before
+GROUP BY f.id, f.title, f.created_by, d.digested_until
+ORDER BY f.id;
+
+-- name: ClaimFirstDigest :execrows
+-- Claims the first digest of a form; no row is inserted if another replica claimed it first
+INSERT INTO form_response_digests (form_id, digested_until)
+VALUES (@form_id, @digested_until)
+ON CONFLICT (form_id) DO NOTHING;
+
+-- name: ClaimDigest :execrows
+-- Claims the next digest of a form; no row is updated if another replica claimed it since the last
+-- digest was listed
+UPDATE form_response_digests
+SET digested_until = @digested_until, updated_at = now()
+WHERE form_id = @form_id AND digested_until = @previous;
+
+-- name: Claim :one
+-- Claims the pending email that is due the longest. SKIP LOCKED lets the workers of every replica
+-- claim concurrently without waiting on each other or claiming the same email.
+UPDATE notifications
+SET status = 'sending', attempts = attempts + 1, updated_at = now()
+WHERE id = (
+    SELECT id FROM notifications
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkSent :execrows
+-- Only the worker of the current attempt can end it; a worker whose email was requeued as stale
+-- updates no row
+UPDATE notifications
+SET status = 'sent', error = NULL, sent_at = now(), updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'sending';
+
+-- name: Retry :execrows
+UPDATE notifications
+SET status = 'pending', error = @error, next_attempt_at = @next_attempt_at, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'sending';
+
+-- name: Fail :execrows
+UPDATE notifications
+SET status = 'failed', error = @error, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'sending';
+
+-- name: RequeueStale :many
+-- Puts emails whose worker stopped while sending them back in the queue, and fails those that have
+-- already been attempted max_attempts times
+UPDATE notifications
+SET status = CASE WHEN attempts >= @max_attempts::int THEN 'failed'::notification_status ELSE 'pending'::notification_status END,
+    error = CASE WHEN attempts >= @max_attempts::int THEN 'sending was interrupted too many times' ELSE error END,
+    next_attempt_at = now(),
+    updated_at = now()
+WHERE status = 'sending' AND updated_at < @stale_before
+RETURNING id, status;
@@ -0,0 +1,39 @@
+CREATE TYPE notification_status AS ENUM(
+    'pending',
+    'sending',
+    'sent',
+    'failed'
+);
+
+-- Queue of emails, rendered when they are queued so that sending them needs nothing else
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Template the email was rendered from, e.g. submission_receipt
+    kind TEXT NOT NULL,
+    recipient_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    to_address TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    -- Identifies what the email is about, so that queuing it again for the same reason is a no-op
+    dedupe_key TEXT NOT NULL UNIQUE,
+    status notification_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- When a pending email is sent next
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Error of the last failed attempt
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched when an attempt starts, so a sending row whose updated_at is old was left by a crashed worker
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_status_next_attempt_at ON notifications(status, next_attempt_at);
+
+-- Responses of a form submitted up to digested_until have been reported in a new-response digest
+CREATE TABLE IF NOT EXISTS form_response_digests (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    digested_until TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
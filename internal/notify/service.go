@@ -0,0 +1,453 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/queue"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// sendTimeout is how long the mail server has to accept an email
+	sendTimeout = 30 * time.Second
+	// timeLayout formats the times shown in emails
+	timeLayout = "2006-01-02 15:04 MST"
+)
+
+// policy retries a failed email 8 times, waiting from a minute up to two hours. StaleAfter is well above
+// sendTimeout, so only emails left by a crashed worker are requeued.
+var policy = queue.Policy{
+	MaxAttempts:    8,
+	BaseRetryDelay: time.Minute,
+	MaxRetryDelay:  2 * time.Hour,
+	StaleAfter:     5 * time.Minute,
+	PollInterval:   5 * time.Second,
+}
+
+type Querier interface {
+	Enqueue(ctx context.Context, arg EnqueueParams) (int64, error)
+	GetRecipient(ctx context.Context, id uuid.UUID) (GetRecipientRow, error)
+	GetForm(ctx context.Context, id uuid.UUID) (GetFormRow, error)
+	ListDueDigests(ctx context.Context, arg ListDueDigestsParams) ([]ListDueDigestsRow, error)
+	ClaimFirstDigest(ctx context.Context, arg ClaimFirstDigestParams) (int64, error)
+	ClaimDigest(ctx context.Context, arg ClaimDigestParams) (int64, error)
+	Claim(ctx context.Context) (Notification, error)
+	MarkSent(ctx context.Context, arg MarkSentParams) (int64, error)
+	Retry(ctx context.Context, arg RetryParams) (int64, error)
+	Fail(ctx context.Context, arg FailParams) (int64, error)
+	RequeueStale(ctx context.Context, arg RequeueStaleParams) ([]RequeueStaleRow, error)
+}
+
+// Email is an email to render from a template and queue for a user
+type Email struct {
+	Kind        Kind
+	RecipientID uuid.UUID
+	// DedupeKey identifies what the email is about; an email whose key was already queued is not queued again
+	DedupeKey string
+	// Vars fill in the placeholders of the template; recipientName is filled in from the recipient
+	Vars map[string]string
+}
+
+// Service renders emails from templates, queues them in the database and sends them with a Mailer.
+//
+// Emails are rendered when they are queued, for the user's oldest email address, and sent by Run, which
+// retries failed attempts with exponential backoff. Every replica of the backend runs its own worker;
+// emails are claimed with FOR UPDATE SKIP LOCKED, so each attempt is made by one worker.
+type Service struct {
+	logger   *zap.Logger
+	tracer   trace.Tracer
+	db       DBTX
+	queries  Querier
+	renderer Renderer
+	mailer   Mailer
+	// location is the time zone of the times shown in emails
+	location *time.Location
+	// digestInterval is the least time between two new-response digests of a form
+	digestInterval time.Duration
+}
+
+func NewService(logger *zap.Logger, db DBTX, renderer Renderer, mailer Mailer, location *time.Location, digestInterval time.Duration) *Service {
+	return &Service{
+		logger:         logger,
+		tracer:         otel.Tracer("notify/service"),
+		db:             db,
+		queries:        New(db),
+		renderer:       renderer,
+		mailer:         mailer,
+		location:       location,
+		digestInterval: digestInterval,
+	}
+}
+
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:         s.logger,
+		tracer:         s.tracer,
+		db:             tx,
+		queries:        New(tx),
+		renderer:       s.renderer,
+		mailer:         s.mailer,
+		location:       s.location,
+		digestInterval: s.digestInterval,
+	}
+}
+
+// Enqueue renders an email and queues it for its recipient. It returns false without an error if the
+// email was not queued because its dedupe key was already queued or the recipient has no email address.
+func (s *Service) Enqueue(ctx context.Context, email Email) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Enqueue")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger).With(
+		zap.String("kind", string(email.Kind)),
+		zap.String("recipient_id", email.RecipientID.String()),
+	)
+
+	recipient, err := s.queries.GetRecipient(traceCtx, email.RecipientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Warn("Skipped email to a user without an email address")
+			return false, nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "users", "id", email.RecipientID.String(), logger, "get email recipient")
+		span.RecordError(err)
+		return false, err
+	}
+
+	vars := make(map[string]string, len(email.Vars)+1)
+	for name, value := range email.Vars {
+		vars[name] = value
+	}
+	vars["recipientName"] = recipientName(recipient)
+
+	subject, text, html, err := render(traceCtx, s.renderer, email.Kind, vars)
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("Failed to render email", zap.Error(err))
+		return false, err
+	}
+
+	queued, err := s.queries.Enqueue(traceCtx, EnqueueParams{
+		Kind:        string(email.Kind),
+		RecipientID: pgtype.UUID{Bytes: email.RecipientID, Valid: true},
+		ToAddress:   recipient.Email,
+		Subject:     subject,
+		TextBody:    text,
+		HtmlBody:    html,
+		DedupeKey:   email.DedupeKey,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "notifications", "dedupe_key", email.DedupeKey, logger, "enqueue email")
+		span.RecordError(err)
+		return false, err
+	}
+
+	return queued > 0, nil
+}
+
+// HandleResponseSubmitted queues the receipt of a submitted response for its respondent; it is an outbox
+// handler of response.submitted events
+func (s *Service) HandleResponseSubmitted(ctx context.Context, event outbox.Event) error {
+	traceCtx, span := s.tracer.Start(ctx, "HandleResponseSubmitted")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	var payload outbox.ResponsePayload
+	err := json.Unmarshal(event.Payload, &payload)
+	if err != nil {
+		err = fmt.Errorf("failed to decode %s event payload: %w", event.Type, err)
+		span.RecordError(err)
+		return err
+	}
+
+	form, err := s.queries.GetForm(traceCtx, payload.FormID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The form was deleted since
+			return nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", payload.FormID.String(), logger, "get form of submitted response")
+		span.RecordError(err)
+		return err
+	}
+
+	submittedAt := event.CreatedAt.Time
+	if payload.SubmittedAt != nil {
+		submittedAt = *payload.SubmittedAt
+	}
+
+	_, err = s.Enqueue(traceCtx, Email{
+		Kind:        KindSubmissionReceipt,
+		RecipientID: payload.SubmittedBy,
+		// A response can be submitted again after a cancellation, so the receipt is of the event
+		DedupeKey: string(KindSubmissionReceipt) + ":" + event.ID.String(),
+		Vars: map[string]string{
+			"formTitle":              form.Title,
+			"submittedAt":            s.formatTime(submittedAt),
+			"messageAfterSubmission": form.MessageAfterSubmission,
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// EnqueueDeadlineReminder queues a reminder of a form's deadline for a user who has not submitted a
+// response to it. dedupeKey identifies the reminder, so that a user gets each reminder once.
+func (s *Service) EnqueueDeadlineReminder(ctx context.Context, formID uuid.UUID, userID uuid.UUID, dedupeKey string) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "EnqueueDeadlineReminder")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	form, err := s.queries.GetForm(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form of deadline reminder")
+		span.RecordError(err)
+		return false, err
+	}
+	if !form.Deadline.Valid {
+		return false, nil
+	}
+
+	queued, err := s.Enqueue(traceCtx, Email{
+		Kind:        KindDeadlineReminder,
+		RecipientID: userID,
+		DedupeKey:   dedupeKey,
+		Vars: map[string]string{
+			"formTitle": form.Title,
+			"deadline":  s.formatTime(form.Deadline.Time),
+		},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	return queued, nil
+}
+
+// QueueDigests queues a new-response digest for the creator of every form that has received responses
+// since its last digest, at most once per digest interval, and returns how many were queued
+func (s *Service) QueueDigests(ctx context.Context) int {
+	traceCtx, span := s.tracer.Start(ctx, "QueueDigests")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	now := time.Now()
+	until := pgtype.Timestamptz{Time: now, Valid: true}
+	digests, err := s.queries.ListDueDigests(traceCtx, ListDueDigestsParams{
+		Until:     until,
+		DueBefore: pgtype.Timestamptz{Time: now.Add(-s.digestInterval), Valid: true},
+	})
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("Failed to list due response digests", zap.Error(err))
+		return 0
+	}
+
+	queued := 0
+	for _, digest := range digests {
+		// The digest is claimed and queued together, so that a response is reported once even when the
+		// digests of several replicas overlap
+		claimed := false
+		err = internal.WithTransaction(traceCtx, s.db, logger, func(tx pgx.Tx) error {
+			txService := s.WithTx(tx)
+			var err error
+			claimed, err = txService.claimDigest(traceCtx, digest, until)
+			if err != nil || !claimed {
+				return err
+			}
+
+			_, err = txService.Enqueue(traceCtx, Email{
+				Kind:        KindResponseDigest,
+				RecipientID: digest.CreatedBy,
+				DedupeKey:   digestDedupeKey(digest),
+				Vars: map[string]string{
+					"formTitle":      digest.Title,
+					"newResponses":   strconv.Itoa(int(digest.NewResponses)),
+					"totalResponses": strconv.Itoa(int(digest.TotalResponses)),
+				},
+			})
+			return err
+		})
+		if err != nil {
+			span.RecordError(err)
+			logger.Error("Failed to queue response digest", zap.String("form_id", digest.ID.String()), zap.Error(err))
+			continue
+		}
+		if claimed {
+			queued++
+		}
+	}
+
+	if queued > 0 {
+		logger.Info("Queued response digests", zap.Int("count", queued))
+	}
+
+	return queued
+}
+
+// claimDigest moves the progress of the form's digests from where it was listed to until. It reports
+// false if another replica has moved it since, and so queues the digest instead.
+func (s *Service) claimDigest(ctx context.Context, digest ListDueDigestsRow, until pgtype.Timestamptz) (bool, error) {
+	var (
+		claimed int64
+		err     error
+	)
+	if digest.DigestedUntil.Valid {
+		claimed, err = s.queries.ClaimDigest(ctx, ClaimDigestParams{
+			DigestedUntil: until,
+			FormID:        digest.ID,
+			Previous:      digest.DigestedUntil,
+		})
+	} else {
+		claimed, err = s.queries.ClaimFirstDigest(ctx, ClaimFirstDigestParams{FormID: digest.ID, DigestedUntil: until})
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return claimed > 0, nil
+}
+
+// digestDedupeKey identifies a digest by the form and the digest before it, so that a digest is queued
+// once however many replicas list it
+func digestDedupeKey(digest ListDueDigestsRow) string {
+	previous := "first"
+	if digest.DigestedUntil.Valid {
+		previous = strconv.FormatInt(digest.DigestedUntil.Time.UnixMicro(), 10)
+	}
+	return string(KindResponseDigest) + ":" + digest.ID.String() + ":" + previous
+}
+
+// RunDigests queues the due new-response digests every interval until ctx is canceled
+func (s *Service) RunDigests(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting response digest job", zap.Duration("interval", interval), zap.Duration("digest_interval", s.digestInterval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.QueueDigests(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Response digest job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run requeues the emails left by stopped workers, then sends due emails until ctx is canceled
+func (s *Service) Run(ctx context.Context) {
+	s.worker().Run(ctx, 1, nil)
+}
+
+// RequeueStale puts emails that a stopped worker was sending back in the queue, or fails them once they
+// have been attempted policy.MaxAttempts times
+func (s *Service) RequeueStale(ctx context.Context) int {
+	return s.worker().RequeueStale(ctx)
+}
+
+// ProcessNext claims the due email that has waited the longest and sends it; it returns false if there
+// was no email to claim. A failed attempt is retried after backoff until policy.MaxAttempts attempts
+// have been made, unless the mail server rejected the email for good.
+func (s *Service) ProcessNext(ctx context.Context) bool {
+	return s.worker().ProcessNext(ctx)
+}
+
+// worker sends the queued emails
+func (s *Service) worker() *queue.Worker[Notification] {
+	return &queue.Worker[Notification]{
+		Name:     "emails",
+		Logger:   s.logger,
+		Tracer:   s.tracer,
+		Policy:   policy,
+		Claim:    s.queries.Claim,
+		Attempts: func(notification Notification) int32 { return notification.Attempts },
+		Fields: func(notification Notification) []zap.Field {
+			return []zap.Field{
+				zap.String("notification_id", notification.ID.String()),
+				zap.String("kind", notification.Kind),
+			}
+		},
+		Attempt: func(ctx context.Context, notification Notification) error {
+			sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+			defer cancel()
+			return s.mailer.Send(sendCtx, Message{
+				ID:      notification.ID,
+				To:      notification.ToAddress,
+				Subject: notification.Subject,
+				Text:    notification.TextBody,
+				HTML:    notification.HtmlBody,
+			})
+		},
+		Permanent: func(err error) bool { return errors.Is(err, ErrRejected) },
+		Succeed: func(ctx context.Context, notification Notification) (int64, error) {
+			return s.queries.MarkSent(ctx, MarkSentParams{ID: notification.ID, Attempts: notification.Attempts})
+		},
+		Retry: func(ctx context.Context, notification Notification, sendErr error, nextAttemptAt time.Time) (int64, error) {
+			return s.queries.Retry(ctx, RetryParams{
+				Error:         pgtype.Text{String: sendErr.Error(), Valid: true},
+				NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+				ID:            notification.ID,
+				Attempts:      notification.Attempts,
+			})
+		},
+		Fail: func(ctx context.Context, notification Notification, sendErr error) (int64, error) {
+			return s.queries.Fail(ctx, FailParams{
+				Error:    pgtype.Text{String: sendErr.Error(), Valid: true},
+				ID:       notification.ID,
+				Attempts: notification.Attempts,
+			})
+		},
+		Requeue: func(ctx context.Context, maxAttempts int32, staleBefore time.Time) ([]queue.Stale, error) {
+			notifications, err := s.queries.RequeueStale(ctx, RequeueStaleParams{
+				MaxAttempts: maxAttempts,
+				StaleBefore: pgtype.Timestamptz{Time: staleBefore, Valid: true},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			stale := make([]queue.Stale, 0, len(notifications))
+			for _, notification := range notifications {
+				stale = append(stale, queue.Stale{ID: notification.ID, Failed: notification.Status == NotificationStatusFailed})
+			}
+			return stale, nil
+		},
+	}
+}
+
+func (s *Service) formatTime(t time.Time) string {
+	return t.In(s.location).Format(timeLayout)
+}
+
+// recipientName returns how an email greets its recipient
+func recipientName(recipient GetRecipientRow) string {
+	if recipient.Name.Valid && recipient.Name.String != "" {
+		return recipient.Name.String
+	}
+	if recipient.Username.Valid && recipient.Username.String != "" {
+		return recipient.Username.String
+	}
+	return recipient.Email
+}
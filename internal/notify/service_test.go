@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// sendQuerier hands out one claimed email and records how its sending ended
+type sendQuerier struct {
+	Querier
+	claimed Notification
+
+	sent    bool
+	retried *RetryParams
+	failed  *FailParams
+}
+
+func (q *sendQuerier) Claim(_ context.Context) (Notification, error) {
+	return q.claimed, nil
+}
+
+func (q *sendQuerier) MarkSent(_ context.Context, arg MarkSentParams) (int64, error) {
+	q.sent = arg.ID == q.claimed.ID && arg.Attempts == q.claimed.Attempts
+	return 1, nil
+}
+
+func (q *sendQuerier) Retry(_ context.Context, arg RetryParams) (int64, error) {
+	q.retried = &arg
+	return 1, nil
+}
+
+func (q *sendQuerier) Fail(_ context.Context, arg FailParams) (int64, error) {
+	q.failed = &arg
+	return 1, nil
+}
+
+// recordingMailer records the emails it is asked to send and fails them with err
+type recordingMailer struct {
+	err  error
+	sent []Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg Message) error {
+	m.sent = append(m.sent, msg)
+	return m.err
+}
+
+func TestService_ProcessNext(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts int32
+		sendErr  error
+		validate func(t *testing.T, q *sendQuerier)
+	}{
+		{
+			name:     "Sent email is marked as sent",
+			attempts: 1,
+			validate: func(t *testing.T, q *sendQuerier) {
+				require.True(t, q.sent)
+				require.Nil(t, q.retried)
+				require.Nil(t, q.failed)
+			},
+		},
+		{
+			name:     "Failed attempt is retried after backoff",
+			attempts: 2,
+			sendErr:  errors.New("connection refused"),
+			validate: func(t *testing.T, q *sendQuerier) {
+				require.False(t, q.sent)
+				require.NotNil(t, q.retried)
+				require.Equal(t, "connection refused", q.retried.Error.String)
+				require.Equal(t, int32(2), q.retried.Attempts)
+				require.WithinDuration(t, time.Now().Add(policy.Backoff(2)), q.retried.NextAttemptAt.Time, 5*time.Second)
+			},
+		},
+		{
+			name:     "Rejected email fails without a retry",
+			attempts: 1,
+			sendErr:  fmt.Errorf("%w: 550 no such user", ErrRejected),
+			validate: func(t *testing.T, q *sendQuerier) {
+				require.Nil(t, q.retried)
+				require.NotNil(t, q.failed)
+				require.Contains(t, q.failed.Error.String, "550 no such user")
+			},
+		},
+		{
+			name:     "Last failed attempt fails the email",
+			attempts: policy.MaxAttempts,
+			sendErr:  errors.New("connection refused"),
+			validate: func(t *testing.T, q *sendQuerier) {
+				require.Nil(t, q.retried)
+				require.NotNil(t, q.failed)
+				require.Equal(t, policy.MaxAttempts, q.failed.Attempts)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &sendQuerier{claimed: Notification{
+				ID:        uuid.New(),
+				Kind:      string(KindSubmissionReceipt),
+				ToAddress: "alice@example.com",
+				Subject:   "Your response to Club Recruitment has been received",
+				TextBody:  "Hi Alice,",
+				HtmlBody:  "<p>Hi Alice,</p>",
+				Status:    NotificationStatusSending,
+				Attempts:  tc.attempts,
+			}}
+			mailer := &recordingMailer{err: tc.sendErr}
+			service := NewService(zap.NewNop(), nil, nil, mailer, time.UTC, time.Hour)
+			service.queries = q
+
+			require.True(t, service.ProcessNext(context.Background()))
+			require.Equal(t, []Message{{
+				ID:      q.claimed.ID,
+				To:      "alice@example.com",
+				Subject: "Your response to Club Recruitment has been received",
+				Text:    "Hi Alice,",
+				HTML:    "<p>Hi Alice,</p>",
+			}}, mailer.sent)
+			tc.validate(t, q)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		attempts int32
+		expected time.Duration
+	}{
+		{attempts: 1, expected: time.Minute},
+		{attempts: 2, expected: 2 * time.Minute},
+		{attempts: 5, expected: 16 * time.Minute},
+		{attempts: 7, expected: 64 * time.Minute},
+		{attempts: 8, expected: 2 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(int(tc.attempts)), func(t *testing.T) {
+			require.Equal(t, tc.expected, policy.Backoff(tc.attempts))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Kind names the template an email is rendered from
+type Kind string
+
+const (
+	KindSubmissionReceipt Kind = "submission_receipt"
+	KindResponseDigest    Kind = "response_digest"
+	KindDeadlineReminder  Kind = "deadline_reminder"
+)
+
+// subjects are the subject lines of the templates; like the bodies, they may use {{variable}} placeholders
+var subjects = map[Kind]string{
+	KindSubmissionReceipt: "Your response to {{formTitle}} has been received",
+	KindResponseDigest:    "New responses to {{formTitle}}",
+	KindDeadlineReminder:  "Reminder: {{formTitle}} closes on {{deadline}}",
+}
+
+// The body of each template is a rich text document, in the ProseMirror JSON that form descriptions use,
+// whose variable nodes are filled in when an email is rendered. The layouts wrap the rendered body.
+//
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlLayout = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html"))
+	textLayout = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/layout.txt"))
+)
+
+// placeholderPattern matches the placeholders the markdown service renders for variable nodes
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// Renderer renders rich text documents; it is implemented by the markdown service
+type Renderer interface {
+	ProcessProseMirrorJSON(ctx context.Context, raw []byte) (canonicalJSON []byte, cleanHTML string, err error)
+	PlainText(ctx context.Context, raw []byte) (string, error)
+}
+
+type layoutData struct {
+	Subject string
+	Body    any
+}
+
+// render renders the subject, text body and HTML body of a template with vars. Every placeholder of the
+// template must have a value, so that a typo in a template fails loudly instead of reaching users.
+func render(ctx context.Context, renderer Renderer, kind Kind, vars map[string]string) (subject, text, htmlBody string, err error) {
+	subjectTemplate, ok := subjects[kind]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown notification template %q", kind)
+	}
+
+	doc, err := templateFS.ReadFile("templates/" + string(kind) + ".json")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read notification template %q: %w", kind, err)
+	}
+
+	_, bodyHTML, err := renderer.ProcessProseMirrorJSON(ctx, doc)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to render notification template %q: %w", kind, err)
+	}
+	bodyText, err := renderer.PlainText(ctx, doc)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to render notification template %q: %w", kind, err)
+	}
+
+	// Placeholders are checked before they are filled in, as values may contain text that looks like one
+	for _, source := range []string{subjectTemplate, bodyText} {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(source, -1) {
+			_, ok := vars[match[1]]
+			if !ok {
+				return "", "", "", fmt.Errorf("notification template %q uses variable %q, which has no value", kind, match[1])
+			}
+		}
+	}
+
+	textPairs := make([]string, 0, 2*len(vars))
+	htmlPairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		textPairs = append(textPairs, "{{"+name+"}}", value)
+		htmlPairs = append(htmlPairs, "{{"+html.EscapeString(name)+"}}", html.EscapeString(value))
+	}
+	textReplacer := strings.NewReplacer(textPairs...)
+	htmlReplacer := strings.NewReplacer(htmlPairs...)
+
+	subject = textReplacer.Replace(subjectTemplate)
+
+	var textBuf bytes.Buffer
+	err = textLayout.Execute(&textBuf, layoutData{Subject: subject, Body: textReplacer.Replace(bodyText)})
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to render text layout: %w", err)
+	}
+
+	var htmlBuf bytes.Buffer
+	// The body is sanitized by the markdown service and the values are escaped above
+	err = htmlLayout.Execute(&htmlBuf, layoutData{Subject: subject, Body: htmltemplate.HTML(htmlReplacer.Replace(bodyHTML))})
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to render HTML layout: %w", err)
+	}
+
+	return subject, textBuf.String(), htmlBuf.String(), nil
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal/markdown"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name        string
+		kind        Kind
+		vars        map[string]string
+		expectedErr bool
+		validate    func(t *testing.T, subject, text, html string)
+	}{
+		{
+			name: "Submission receipt fills in every placeholder",
+			kind: KindSubmissionReceipt,
+			vars: map[string]string{
+				"recipientName":          "Alice",
+				"formTitle":              "Club Recruitment",
+				"submittedAt":            "2026-03-01 10:00 CST",
+				"messageAfterSubmission": "See you at the interview!",
+			},
+			validate: func(t *testing.T, subject, text, html string) {
+				require.Equal(t, "Your response to Club Recruitment has been received", subject)
+				require.Contains(t, text, "Hi Alice,")
+				require.Contains(t, text, "We received your response to Club Recruitment on 2026-03-01 10:00 CST.")
+				require.Contains(t, text, "See you at the interview!")
+				require.Contains(t, text, "This is an automated message from Core System.")
+				require.Contains(t, html, "Club Recruitment")
+				require.NotContains(t, text+html, "{{")
+			},
+		},
+		{
+			name: "Values are escaped in the HTML body only",
+			kind: KindDeadlineReminder,
+			vars: map[string]string{
+				"recipientName": "<script>alert(1)</script>",
+				"formTitle":     "Q&A",
+				"deadline":      "2026-03-01 23:59 CST",
+			},
+			validate: func(t *testing.T, subject, text, html string) {
+				require.Equal(t, "Reminder: Q&A closes on 2026-03-01 23:59 CST", subject)
+				require.Contains(t, text, "<script>alert(1)</script>")
+				require.NotContains(t, html, "<script>")
+				require.Contains(t, html, "&lt;script&gt;")
+				require.Contains(t, html, "Q&amp;A")
+			},
+		},
+		{
+			name: "Value that looks like a placeholder is not filled in",
+			kind: KindDeadlineReminder,
+			vars: map[string]string{
+				"recipientName": "{{formTitle}}",
+				"formTitle":     "Club Recruitment",
+				"deadline":      "2026-03-01 23:59 CST",
+			},
+			validate: func(t *testing.T, _, text, _ string) {
+				require.Contains(t, text, "Hi {{formTitle}},")
+			},
+		},
+		{
+			name: "Missing variable fails",
+			kind: KindResponseDigest,
+			vars: map[string]string{
+				"recipientName": "Alice",
+				"formTitle":     "Club Recruitment",
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "Unknown template fails",
+			kind:        Kind("unknown"),
+			expectedErr: true,
+		},
+	}
+
+	renderer := markdown.NewService(zap.NewNop())
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject, text, html, err := render(context.Background(), renderer, tc.kind, tc.vars)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.validate(t, subject, text, html)
+		})
+	}
+}
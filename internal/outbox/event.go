@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AggregateResponse is the aggregate type of the events of a form response
+const AggregateResponse = "form_response"
+
+// Types of the events of a form response
+const (
+	TypeResponseSubmitted = "response.submitted"
+	TypeResponseCancelled = "response.cancelled"
+	TypeResponseDeleted   = "response.deleted"
+)
+
+// ResponsePayload is the payload of the events of a form response
+type ResponsePayload struct {
+	ResponseID  uuid.UUID  `json:"responseId"`
+	FormID      uuid.UUID  `json:"formId"`
+	SubmittedBy uuid.UUID  `json:"submittedBy"`
+	SubmittedAt *time.Time `json:"submittedAt,omitempty"`
+}
+
+func NewResponsePayload(id uuid.UUID, formID uuid.UUID, submittedBy uuid.UUID, submittedAt pgtype.Timestamptz) ResponsePayload {
+	payload := ResponsePayload{
+		ResponseID:  id,
+		FormID:      formID,
+		SubmittedBy: submittedBy,
+	}
+	if submittedAt.Valid {
+		at := submittedAt.Time
+		payload.SubmittedAt = &at
+	}
+	return payload
+}
+
+// AggregateForm is the aggregate type of the events of a form
+const AggregateForm = "form"
+
+// Types of the events of a form
+const (
+	TypeFormPublished = "form.published"
+	TypeFormClosed    = "form.closed"
+	// TypeFormSheetResyncRequested is the event of a request to write every response of a form to its
+	// Google Sheet again
+	TypeFormSheetResyncRequested = "form.sheet_resync_requested"
+)
+
+// FormPayload is the payload of the events of a form
+type FormPayload struct {
+	FormID uuid.UUID `json:"formId"`
+}
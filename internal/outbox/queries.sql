@@ -0,0 +1,58 @@
+-- name: Create :one
+INSERT INTO events (aggregate_type, aggregate_id, type, payload)
+VALUES (@aggregate_type, @aggregate_id, @type, @payload)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM events WHERE id = @id;
+
+-- name: Claim :one
+-- Claims the due pending event that was written first, skipping events whose aggregate has an earlier
+-- event that is pending or dispatching, so the events of an aggregate are dispatched one at a time and
+-- in order. SKIP LOCKED lets the dispatchers of every replica claim concurrently; an earlier event that
+-- another dispatcher is claiming is still pending, so its later events are skipped as well.
+UPDATE events
+SET status = 'dispatching', attempts = attempts + 1, updated_at = now()
+WHERE id = (
+    SELECT e.id FROM events e
+    WHERE e.status = 'pending' AND e.next_attempt_at <= now()
+      AND NOT EXISTS (
+          SELECT 1 FROM events earlier
+          WHERE earlier.aggregate_type = e.aggregate_type
+            AND earlier.aggregate_id = e.aggregate_id
+            AND earlier.seq < e.seq
+            AND earlier.status IN ('pending', 'dispatching')
+      )
+    ORDER BY e.seq
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkDispatched :execrows
+-- Only the dispatcher of the current attempt can end it; a dispatcher whose event was requeued as stale
+-- updates no row
+UPDATE events
+SET status = 'dispatched', error = NULL, dispatched_at = now(), updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'dispatching';
+
+-- name: Retry :execrows
+UPDATE events
+SET status = 'pending', error = @error, next_attempt_at = @next_attempt_at, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'dispatching';
+
+-- name: Fail :execrows
+UPDATE events
+SET status = 'failed', error = @error, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'dispatching';
+
+-- name: RequeueStale :many
+-- Puts events whose dispatcher stopped while dispatching them back in the queue, and fails those that
+-- have already been attempted max_attempts times
+UPDATE events
+SET status = CASE WHEN attempts >= @max_attempts::int THEN 'failed'::event_status ELSE 'pending'::event_status END,
+    error = CASE WHEN attempts >= @max_attempts::int THEN 'dispatch was interrupted too many times' ELSE error END,
+    next_attempt_at = now(),
+    updated_at = now()
+WHERE status = 'dispatching' AND updated_at < @stale_before
+RETURNING id, status;
@@ -0,0 +1,32 @@
+CREATE TYPE event_status AS ENUM(
+    'pending',
+    'dispatching',
+    'dispatched',
+    'failed'
+);
+
+-- Outbox of domain events, written in the transaction of the change they record and then dispatched to
+-- the in-process subscribers
+CREATE TABLE IF NOT EXISTS events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Order in which the events were written; the events of an aggregate are dispatched in this order
+    seq BIGSERIAL NOT NULL UNIQUE,
+    -- Kind and id of the entity the event happened to, e.g. a form response
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status event_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- When a pending event is dispatched next
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Error of the last failed dispatch
+    error TEXT,
+    dispatched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched when a dispatch starts, so a dispatching row whose updated_at is old was left by a crashed dispatcher
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_status_next_attempt_at ON events(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_events_aggregate ON events(aggregate_type, aggregate_id, seq);
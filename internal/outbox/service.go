@@ -0,0 +1,248 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/queue"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// dispatchTimeout is how long the subscribers of an event have to handle it
+	dispatchTimeout = time.Minute
+)
+
+// policy retries a failed dispatch 10 times, waiting from 5 seconds up to 30 minutes. StaleAfter is well
+// above dispatchTimeout, so only events left by a crashed dispatcher are requeued.
+var policy = queue.Policy{
+	MaxAttempts:    10,
+	BaseRetryDelay: 5 * time.Second,
+	MaxRetryDelay:  30 * time.Minute,
+	StaleAfter:     5 * time.Minute,
+	PollInterval:   2 * time.Second,
+}
+
+// Handler handles an event for a subscriber. Events are delivered at least once, so a handler must be
+// idempotent; the event's ID identifies it across attempts.
+type Handler func(ctx context.Context, event Event) error
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (Event, error)
+	GetByID(ctx context.Context, id uuid.UUID) (Event, error)
+	Claim(ctx context.Context) (Event, error)
+	MarkDispatched(ctx context.Context, arg MarkDispatchedParams) (int64, error)
+	Retry(ctx context.Context, arg RetryParams) (int64, error)
+	Fail(ctx context.Context, arg FailParams) (int64, error)
+	RequeueStale(ctx context.Context, arg RequeueStaleParams) ([]RequeueStaleRow, error)
+}
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// subscriptions is shared by a Service and the copies WithTx makes of it
+type subscriptions struct {
+	mu     sync.RWMutex
+	byType map[string][]subscription
+}
+
+// Service is the transactional outbox of domain events.
+//
+// Services record what happened through a Service bound to the transaction of their change with WithTx,
+// so an event is written if and only if the change is committed. Run then dispatches each event to the
+// in-process subscribers of its type.
+//
+// Dispatch is at least once: an event whose subscribers fail, or whose dispatcher crashes, is dispatched
+// again to every subscriber. The events of an aggregate are dispatched one at a time and in the order
+// they were written; an event that is failed for good no longer holds back the later ones.
+type Service struct {
+	logger        *zap.Logger
+	tracer        trace.Tracer
+	queries       Querier
+	subscriptions *subscriptions
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:        logger,
+		tracer:        otel.Tracer("outbox/service"),
+		queries:       New(db),
+		subscriptions: &subscriptions{byType: make(map[string][]subscription)},
+	}
+}
+
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:        s.logger,
+		tracer:        s.tracer,
+		queries:       New(tx),
+		subscriptions: s.subscriptions,
+	}
+}
+
+// Subscribe registers a handler for every event of a type; name identifies the subscriber in logs.
+// Subscribers are registered at startup, before Run.
+func (s *Service) Subscribe(eventType string, name string, handler Handler) {
+	s.subscriptions.mu.Lock()
+	defer s.subscriptions.mu.Unlock()
+
+	s.subscriptions.byType[eventType] = append(s.subscriptions.byType[eventType], subscription{name: name, handler: handler})
+}
+
+// Publish writes an event with a JSON payload to the outbox
+func (s *Service) Publish(ctx context.Context, aggregateType string, aggregateID uuid.UUID, eventType string, payload any) (Event, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Publish")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		err = fmt.Errorf("failed to marshal %s event payload: %w", eventType, err)
+		span.RecordError(err)
+		return Event{}, err
+	}
+
+	event, err := s.queries.Create(traceCtx, CreateParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       body,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "events", "aggregate_id", aggregateID.String(), logger, "create event")
+		span.RecordError(err)
+		return Event{}, err
+	}
+
+	return event, nil
+}
+
+// PublishResponseEvent writes an event of a form response to the outbox
+func (s *Service) PublishResponseEvent(ctx context.Context, eventType string, payload ResponsePayload) error {
+	_, err := s.Publish(ctx, AggregateResponse, payload.ResponseID, eventType, payload)
+	return err
+}
+
+// PublishFormEvent writes an event of a form to the outbox
+func (s *Service) PublishFormEvent(ctx context.Context, eventType string, formID uuid.UUID) error {
+	_, err := s.Publish(ctx, AggregateForm, formID, eventType, FormPayload{FormID: formID})
+	return err
+}
+
+// Run requeues the events left by stopped dispatchers, then dispatches due events until ctx is canceled
+func (s *Service) Run(ctx context.Context) {
+	s.worker().Run(ctx, 1, nil)
+}
+
+// RequeueStale puts events that a stopped dispatcher was dispatching back in the queue, or fails them
+// once they have been attempted policy.MaxAttempts times
+func (s *Service) RequeueStale(ctx context.Context) int {
+	return s.worker().RequeueStale(ctx)
+}
+
+// DispatchNext claims the next due event and hands it to its subscribers; it returns false if there was
+// no event to claim. If a subscriber fails, the event is dispatched again after backoff until
+// policy.MaxAttempts attempts have been made.
+func (s *Service) DispatchNext(ctx context.Context) bool {
+	return s.worker().ProcessNext(ctx)
+}
+
+// worker dispatches the events of the outbox
+func (s *Service) worker() *queue.Worker[Event] {
+	return &queue.Worker[Event]{
+		Name:     "events",
+		Logger:   s.logger,
+		Tracer:   s.tracer,
+		Policy:   policy,
+		Claim:    s.queries.Claim,
+		Attempts: func(event Event) int32 { return event.Attempts },
+		Fields: func(event Event) []zap.Field {
+			return []zap.Field{
+				zap.String("event_id", event.ID.String()),
+				zap.String("event_type", event.Type),
+				zap.String("aggregate_id", event.AggregateID.String()),
+			}
+		},
+		Attempt: s.dispatch,
+		Succeed: func(ctx context.Context, event Event) (int64, error) {
+			return s.queries.MarkDispatched(ctx, MarkDispatchedParams{ID: event.ID, Attempts: event.Attempts})
+		},
+		Retry: func(ctx context.Context, event Event, dispatchErr error, nextAttemptAt time.Time) (int64, error) {
+			return s.queries.Retry(ctx, RetryParams{
+				Error:         pgtype.Text{String: dispatchErr.Error(), Valid: true},
+				NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+				ID:            event.ID,
+				Attempts:      event.Attempts,
+			})
+		},
+		Fail: func(ctx context.Context, event Event, dispatchErr error) (int64, error) {
+			return s.queries.Fail(ctx, FailParams{
+				Error:    pgtype.Text{String: dispatchErr.Error(), Valid: true},
+				ID:       event.ID,
+				Attempts: event.Attempts,
+			})
+		},
+		Requeue: func(ctx context.Context, maxAttempts int32, staleBefore time.Time) ([]queue.Stale, error) {
+			events, err := s.queries.RequeueStale(ctx, RequeueStaleParams{
+				MaxAttempts: maxAttempts,
+				StaleBefore: pgtype.Timestamptz{Time: staleBefore, Valid: true},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			stale := make([]queue.Stale, 0, len(events))
+			for _, event := range events {
+				stale = append(stale, queue.Stale{ID: event.ID, Failed: event.Status == EventStatusFailed})
+			}
+			return stale, nil
+		},
+	}
+}
+
+// dispatch hands an event to every subscriber of its type and joins their errors. Every subscriber is
+// called even if an earlier one fails, so that one failing subscriber does not hold back the others.
+func (s *Service) dispatch(ctx context.Context, event Event) error {
+	s.subscriptions.mu.RLock()
+	subscribers := s.subscriptions.byType[event.Type]
+	s.subscriptions.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, dispatchTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, subscriber := range subscribers {
+		err := handle(ctx, subscriber.handler, event)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", subscriber.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// handle calls a handler, turning a panic into an error so that it is retried like any other failure
+func handle(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return handler(ctx, event)
+}
@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// dispatchQuerier hands out one claimed event and records how its dispatch ended
+type dispatchQuerier struct {
+	Querier
+	claimed Event
+
+	dispatched *MarkDispatchedParams
+	retried    *RetryParams
+	failed     *FailParams
+}
+
+func (q *dispatchQuerier) Claim(_ context.Context) (Event, error) {
+	return q.claimed, nil
+}
+
+func (q *dispatchQuerier) MarkDispatched(_ context.Context, arg MarkDispatchedParams) (int64, error) {
+	q.dispatched = &arg
+	return 1, nil
+}
+
+func (q *dispatchQuerier) Retry(_ context.Context, arg RetryParams) (int64, error) {
+	q.retried = &arg
+	return 1, nil
+}
+
+func (q *dispatchQuerier) Fail(_ context.Context, arg FailParams) (int64, error) {
+	q.failed = &arg
+	return 1, nil
+}
+
+func TestService_DispatchNext(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts int32
+		handlers map[string]Handler
+		validate func(t *testing.T, q *dispatchQuerier, calls map[string]int)
+	}{
+		{
+			name:     "Every subscriber handles the event",
+			attempts: 1,
+			handlers: map[string]Handler{
+				"receipt": func(context.Context, Event) error { return nil },
+				"sheets":  func(context.Context, Event) error { return nil },
+			},
+			validate: func(t *testing.T, q *dispatchQuerier, calls map[string]int) {
+				require.NotNil(t, q.dispatched)
+				require.Equal(t, q.claimed.Attempts, q.dispatched.Attempts)
+				require.Equal(t, map[string]int{"receipt": 1, "sheets": 1}, calls)
+			},
+		},
+		{
+			name:     "Event without subscribers is dispatched",
+			attempts: 1,
+			validate: func(t *testing.T, q *dispatchQuerier, _ map[string]int) {
+				require.NotNil(t, q.dispatched)
+			},
+		},
+		{
+			name:     "Failing subscriber retries the event after backoff without stopping the others",
+			attempts: 3,
+			handlers: map[string]Handler{
+				"receipt": func(context.Context, Event) error { return errors.New("smtp unavailable") },
+				"sheets":  func(context.Context, Event) error { return nil },
+			},
+			validate: func(t *testing.T, q *dispatchQuerier, calls map[string]int) {
+				require.Nil(t, q.dispatched)
+				require.NotNil(t, q.retried)
+				require.Equal(t, int32(3), q.retried.Attempts)
+				require.Contains(t, q.retried.Error.String, "receipt: smtp unavailable")
+				require.WithinDuration(t, time.Now().Add(policy.Backoff(3)), q.retried.NextAttemptAt.Time, 5*time.Second)
+				require.Equal(t, 1, calls["sheets"])
+			},
+		},
+		{
+			name:     "Panicking subscriber retries the event",
+			attempts: 1,
+			handlers: map[string]Handler{
+				"receipt": func(context.Context, Event) error { panic("nil template") },
+			},
+			validate: func(t *testing.T, q *dispatchQuerier, _ map[string]int) {
+				require.NotNil(t, q.retried)
+				require.Contains(t, q.retried.Error.String, "panic: nil template")
+			},
+		},
+		{
+			name:     "Last failed attempt fails the event",
+			attempts: policy.MaxAttempts,
+			handlers: map[string]Handler{
+				"receipt": func(context.Context, Event) error { return errors.New("smtp unavailable") },
+			},
+			validate: func(t *testing.T, q *dispatchQuerier, _ map[string]int) {
+				require.Nil(t, q.retried)
+				require.NotNil(t, q.failed)
+				require.Equal(t, policy.MaxAttempts, q.failed.Attempts)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &dispatchQuerier{claimed: Event{
+				ID:            uuid.New(),
+				AggregateType: AggregateResponse,
+				AggregateID:   uuid.New(),
+				Type:          TypeResponseSubmitted,
+				Payload:       []byte(`{}`),
+				Status:        EventStatusDispatching,
+				Attempts:      tc.attempts,
+			}}
+			service := NewService(zap.NewNop(), nil)
+			service.queries = q
+
+			calls := make(map[string]int)
+			for name, handler := range tc.handlers {
+				service.Subscribe(TypeResponseSubmitted, name, func(ctx context.Context, event Event) error {
+					calls[name]++
+					require.Equal(t, q.claimed.ID, event.ID)
+					return handler(ctx, event)
+				})
+			}
+			service.Subscribe(TypeResponseDeleted, "other", func(context.Context, Event) error {
+				t.Fatal("subscriber of another event type was called")
+				return nil
+			})
+
+			require.True(t, service.DispatchNext(context.Background()))
+			tc.validate(t, q, calls)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		attempts int32
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 5 * time.Second},
+		{attempts: 2, expected: 10 * time.Second},
+		{attempts: 5, expected: 80 * time.Second},
+		{attempts: 9, expected: 1280 * time.Second},
+		{attempts: 10, expected: 30 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(int(tc.attempts)), func(t *testing.T) {
+			require.Equal(t, tc.expected, policy.Backoff(tc.attempts))
+		})
+	}
+}
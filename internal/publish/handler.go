@@ -23,8 +23,8 @@ type PreviewResponse struct {
 }
 
 type Request struct {
-	OrgID   uuid.UUID   `json:"orgId"`
-	UnitIDs []uuid.UUID `json:"unitIds"`
+	OrgID   uuid.UUID   `json:"orgId" validate:"required_without=UnitIDs"`
+	UnitIDs []uuid.UUID `json:"unitIds" validate:"required_without=OrgID"`
 }
 
 type Response struct {
@@ -32,7 +32,7 @@ type Response struct {
 }
 
 type Store interface {
-	PublishForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID) (form.Visibility, error)
+	PublishForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID, selection Selection) (form.Visibility, error)
 }
 
 type Handler struct {
@@ -76,7 +76,21 @@ func (h *Handler) PublishForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	visibility, err := h.store.PublishForm(ctx, formID, currentUser.ID)
+	// The recipient selection is optional; without a body the form is published
+	// without being delivered to any inbox.
+	var request Request
+	if r.ContentLength != 0 {
+		err = handlerutil.ParseAndValidateRequestBody(ctx, h.validator, r, &request)
+		if err != nil {
+			h.problemWriter.WriteError(ctx, w, err, logger)
+			return
+		}
+	}
+
+	visibility, err := h.store.PublishForm(ctx, formID, currentUser.ID, Selection{
+		OrgID:   request.OrgID,
+		UnitIDs: request.UnitIDs,
+	})
 	if err != nil {
 		h.problemWriter.WriteError(ctx, w, err, logger)
 		return
@@ -5,9 +5,11 @@ import (
 	"errors"
 
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/distribute"
 	"NYCU-SDC/core-system-backend/internal/form"
 	"NYCU-SDC/core-system-backend/internal/form/workflow"
 	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/outbox"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -21,20 +23,29 @@ import (
 type Distributor interface {
 	GetOrgRecipients(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error)
 	GetRecipients(ctx context.Context, unitIDs []uuid.UUID) ([]uuid.UUID, error)
+	WithTx(tx pgx.Tx) *distribute.Service
 }
 
 type FormStore interface {
 	Get(ctx context.Context, id uuid.UUID) (form.GetRow, error)
 	SetStatus(ctx context.Context, id uuid.UUID, status form.Status, userID uuid.UUID) (form.Form, error)
+	WithTx(tx pgx.Tx) *form.Service
 }
 
 type InboxPort interface {
 	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+	WithTx(tx pgx.Tx) *inbox.Service
+}
+
+type EventPublisher interface {
+	PublishFormEvent(ctx context.Context, eventType string, formID uuid.UUID) error
+	WithTx(tx pgx.Tx) *outbox.Service
 }
 
 type WorkflowStore interface {
 	Get(ctx context.Context, formID uuid.UUID) (workflow.WorkflowVersion, error)
 	Activate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, workflow []byte) (workflow.WorkflowVersion, error)
+	WithTx(tx pgx.Tx) *workflow.Service
 }
 
 type Selection struct {
@@ -45,26 +56,32 @@ type Selection struct {
 type Service struct {
 	logger      *zap.Logger
 	tracer      trace.Tracer
+	db          internal.DBTX
 	distributor Distributor
 	store       FormStore
 	inbox       InboxPort
 	workflow    WorkflowStore
+	events      EventPublisher
 }
 
 func NewService(
 	logger *zap.Logger,
+	db internal.DBTX,
 	distributor Distributor,
 	store FormStore,
 	inbox InboxPort,
 	workflow WorkflowStore,
+	events EventPublisher,
 ) *Service {
 	return &Service{
 		logger:      logger,
 		tracer:      otel.Tracer("publish/service"),
+		db:          db,
 		distributor: distributor,
 		store:       store,
 		inbox:       inbox,
 		workflow:    workflow,
+		events:      events,
 	}
 }
 
@@ -97,33 +114,76 @@ func (s *Service) GetRecipients(ctx context.Context, selection Selection) ([]uui
 	return users, nil
 }
 
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:      s.logger,
+		tracer:      s.tracer,
+		db:          tx,
+		distributor: s.distributor.WithTx(tx),
+		store:       s.store.WithTx(tx),
+		inbox:       s.inbox.WithTx(tx),
+		workflow:    s.workflow.WithTx(tx),
+		events:      s.events.WithTx(tx),
+	}
+}
+
+// withTransaction runs fn with copies of the service's stores bound to a single
+// pgx transaction, so every write made by fn is committed or rolled back together.
+func (s *Service) withTransaction(ctx context.Context, fn func(txService *Service) error) error {
+	return internal.WithTransaction(ctx, s.db, s.logger, func(tx pgx.Tx) error {
+		return fn(s.WithTx(tx))
+	})
+}
+
 // PublishForm not Publish is because maybe we will publish something else in future
 // This method is responsible for:
 //  1. Ensuring the form is in draft status
 //  2. Ensuring there is a latest workflow stored for the form
 //  3. Activating that latest workflow from DB
 //  4. Publishing the form
-func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID) (form.Visibility, error) {
+//  5. Delivering the form to the inbox of every recipient in selection
+//  6. Recording the form.published event in the outbox
+//
+// All steps run in one transaction; if any of them fails nothing is persisted.
+// Recipients who already have the form in their inbox (e.g. on re-publish) are skipped.
+func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID, selection Selection) (form.Visibility, error) {
 	ctx, span := s.tracer.Start(ctx, "PublishForm")
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
+	var visibility form.Visibility
+	err := s.withTransaction(ctx, func(txService *Service) error {
+		var err error
+		visibility, err = txService.publishForm(ctx, formID, editor, selection)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	logger.Info("Form published",
+		zap.String("form_id", formID.String()),
+		zap.String("editor", editor.String()),
+	)
+	return visibility, nil
+}
+
+func (s *Service) publishForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID, selection Selection) (form.Visibility, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
 	// check form existence and status
 	targetForm, err := s.store.Get(ctx, formID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, internal.ErrFormNotFound) {
-			span.RecordError(internal.ErrFormNotFound)
 			return "", internal.ErrFormNotFound
 		}
 		err = databaseutil.WrapDBError(err, logger, "getting form by id")
-		span.RecordError(err)
 		return "", err
 	}
 
 	if targetForm.Status != form.StatusDraft {
-		err = internal.ErrFormNotDraft
-		span.RecordError(err)
-		return "", err
+		return "", internal.ErrFormNotDraft
 	}
 
 	// Always activate the latest stored workflow before publishing.
@@ -131,14 +191,12 @@ func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, editor uuid
 	// to provide workflow JSON.
 	latestWorkflow, err := s.workflow.Get(ctx, formID)
 	if err != nil {
-		span.RecordError(err)
 		return "", err
 	}
 
 	activatedVersion, err := s.workflow.Activate(ctx, formID, editor, latestWorkflow.Workflow)
 	if err != nil {
 		logger.Error("failed to activate workflow during publish", zap.Error(err), zap.String("formId", formID.String()))
-		span.RecordError(err)
 		return "", err
 	}
 
@@ -147,20 +205,60 @@ func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, editor uuid
 			zap.String("formId", formID.String()),
 			zap.String("versionId", activatedVersion.ID.String()),
 			zap.Bool("isActive", activatedVersion.IsActive))
-		span.RecordError(internal.ErrWorkflowNotActive)
 		return "", internal.ErrWorkflowNotActive
 	}
 
 	updatedForm, err := s.store.SetStatus(ctx, formID, form.StatusPublished, editor)
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "setting form status = published")
-		span.RecordError(err)
 		return "", err
 	}
 
-	logger.Info("Form published",
+	recipients, err := s.GetRecipients(ctx, selection)
+	if err != nil {
+		return "", err
+	}
+
+	if len(recipients) > 0 {
+		_, err = s.inbox.Create(ctx, inbox.ContentTypeForm, formID, recipients, targetForm.UnitID.Bytes)
+		if err != nil {
+			logger.Error("failed to deliver form to inbox during publish", zap.Error(err), zap.String("formId", formID.String()))
+			return "", err
+		}
+	}
+
+	err = s.events.PublishFormEvent(ctx, outbox.TypeFormPublished, formID)
+	if err != nil {
+		return "", err
+	}
+
+	return updatedForm.Visibility, nil
+}
+
+// CloseForm closes a form and records its form.closed event in the outbox in one transaction
+func (s *Service) CloseForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID) (form.Form, error) {
+	ctx, span := s.tracer.Start(ctx, "CloseForm")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	var closed form.Form
+	err := s.withTransaction(ctx, func(txService *Service) error {
+		var err error
+		closed, err = txService.store.SetStatus(ctx, formID, form.StatusClosed, editor)
+		if err != nil {
+			return err
+		}
+
+		return txService.events.PublishFormEvent(ctx, outbox.TypeFormClosed, formID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return form.Form{}, err
+	}
+
+	logger.Info("Form closed",
 		zap.String("form_id", formID.String()),
 		zap.String("editor", editor.String()),
 	)
-	return updatedForm.Visibility, nil
+	return closed, nil
 }
@@ -0,0 +1,212 @@
+// Package queue runs the workers of the queues kept in database tables: outbox events, webhook
+// deliveries, emails and export jobs.
+//
+// A queue claims its due items with FOR UPDATE SKIP LOCKED, so every replica of the backend can run its
+// own workers. A claimed item is attempted, then its attempt is ended by Succeed, Retry or Fail; an item
+// whose worker stopped before ending it is put back in the queue by RequeueStale.
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Policy is how a queue retries failed attempts and requeues the items of stopped workers
+type Policy struct {
+	// MaxAttempts is how many times an item is attempted before it is failed
+	MaxAttempts int32
+	// BaseRetryDelay is the wait after the first failed attempt; it doubles after every further attempt
+	BaseRetryDelay time.Duration
+	// MaxRetryDelay caps the wait between two attempts
+	MaxRetryDelay time.Duration
+	// StaleAfter is how long an item may stay claimed before it is requeued; it must be well above the
+	// time an attempt may take, so that only items left by a crashed worker are requeued
+	StaleAfter time.Duration
+	// PollInterval is how often idle workers look for due items and stale ones
+	PollInterval time.Duration
+}
+
+// Backoff returns how long to wait after a failed attempt before the next one
+func (p Policy) Backoff(attempts int32) time.Duration {
+	delay := p.BaseRetryDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxRetryDelay {
+			return p.MaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Stale is an item that Requeue put back in the queue, or failed because it was attempted too many times
+type Stale struct {
+	ID     uuid.UUID
+	Failed bool
+}
+
+// Worker claims the items of a queue and attempts them; T is a claimed item.
+//
+// Succeed, Retry and Fail end the attempt of a claimed item and return how many rows they updated. No
+// row means the item was requeued as stale while it was attempted; the attempt that claimed it again
+// ends it.
+type Worker[T any] struct {
+	// Name is the queue's name in logs
+	Name   string
+	Logger *zap.Logger
+	Tracer trace.Tracer
+	Policy Policy
+
+	// Claim claims the due item that has waited the longest; pgx.ErrNoRows means none is due
+	Claim func(ctx context.Context) (T, error)
+	// Attempts is how many times a claimed item has been claimed, including this time
+	Attempts func(item T) int32
+	// Fields describe a claimed item in logs
+	Fields func(item T) []zap.Field
+	// Attempt attempts a claimed item
+	Attempt func(ctx context.Context, item T) error
+	// Permanent reports whether a failed attempt must not be retried; nil means every failure is retried
+	Permanent func(err error) bool
+
+	Succeed func(ctx context.Context, item T) (int64, error)
+	// Retry puts an item back in the queue until nextAttemptAt; nil means a failed item is never retried
+	Retry func(ctx context.Context, item T, attemptErr error, nextAttemptAt time.Time) (int64, error)
+	Fail  func(ctx context.Context, item T, attemptErr error) (int64, error)
+
+	// Requeue puts the items claimed before staleBefore back in the queue, and fails those that have been
+	// attempted maxAttempts times
+	Requeue func(ctx context.Context, maxAttempts int32, staleBefore time.Time) ([]Stale, error)
+}
+
+// Run requeues the items left by stopped workers, then runs the given number of workers until ctx is
+// canceled. A worker that ran out of items waits for the next poll, or for wake, which may be nil.
+func (w *Worker[T]) Run(ctx context.Context, workers int, wake <-chan struct{}) {
+	logger := w.Logger.With(zap.String("queue", w.Name))
+	logger.Info("Starting queue workers", zap.Int("workers", workers), zap.Duration("interval", w.Policy.PollInterval))
+
+	w.RequeueStale(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx, wake)
+		}()
+	}
+
+	ticker := time.NewTicker(w.Policy.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			logger.Info("Queue workers stopped")
+			return
+		case <-ticker.C:
+			w.RequeueStale(ctx)
+		}
+	}
+}
+
+// work processes items until the queue is empty, then waits to be woken or for the next poll
+func (w *Worker[T]) work(ctx context.Context, wake <-chan struct{}) {
+	ticker := time.NewTicker(w.Policy.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && w.ProcessNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// RequeueStale puts items that a stopped worker was attempting back in the queue, or fails them once
+// they have been attempted MaxAttempts times
+func (w *Worker[T]) RequeueStale(ctx context.Context) int {
+	traceCtx, span := w.Tracer.Start(ctx, "RequeueStale")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, w.Logger).With(zap.String("queue", w.Name))
+
+	items, err := w.Requeue(traceCtx, w.Policy.MaxAttempts, time.Now().Add(-w.Policy.StaleAfter))
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("Failed to requeue stale items", zap.Error(err))
+		return 0
+	}
+
+	for _, item := range items {
+		if item.Failed {
+			logger.Warn("Failed item whose attempt was interrupted too many times", zap.String("id", item.ID.String()))
+			continue
+		}
+		logger.Info("Requeued interrupted item", zap.String("id", item.ID.String()))
+	}
+
+	return len(items)
+}
+
+// ProcessNext claims the next due item and attempts it; it returns false if there was no item to claim.
+// A failed attempt is retried after backoff until MaxAttempts attempts have been made, unless it failed
+// for good. Errors are logged rather than returned, so that one broken item does not stop the worker.
+func (w *Worker[T]) ProcessNext(ctx context.Context) bool {
+	traceCtx, span := w.Tracer.Start(ctx, "ProcessNext")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, w.Logger).With(zap.String("queue", w.Name))
+
+	item, err := w.Claim(traceCtx)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			span.RecordError(err)
+			logger.Error("Failed to claim item", zap.Error(err))
+		}
+		return false
+	}
+
+	attempts := w.Attempts(item)
+	logger = logger.With(append(w.Fields(item), zap.Int32("attempt", attempts))...)
+
+	var updated int64
+	err = w.Attempt(traceCtx, item)
+	switch {
+	case err == nil:
+		updated, err = w.Succeed(traceCtx, item)
+		if err == nil && updated > 0 {
+			logger.Info("Attempt succeeded")
+		}
+	case ctx.Err() != nil:
+		// Left claimed so that it is requeued once it is stale
+		logger.Warn("Attempt interrupted by shutdown", zap.Error(err))
+		return true
+	case w.Retry == nil || attempts >= w.Policy.MaxAttempts || (w.Permanent != nil && w.Permanent(err)):
+		logger.Warn("Attempt failed for the last time", zap.Error(err))
+		updated, err = w.Fail(traceCtx, item, err)
+	default:
+		nextAttemptAt := time.Now().Add(w.Policy.Backoff(attempts))
+		logger.Warn("Attempt failed, will retry", zap.Error(err), zap.Time("next_attempt_at", nextAttemptAt))
+		updated, err = w.Retry(traceCtx, item, err, nextAttemptAt)
+	}
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("Failed to end attempt", zap.Error(err))
+		return true
+	}
+	if updated == 0 {
+		logger.Warn("Item was requeued while it was attempted; the attempt that claimed it again ends it")
+	}
+
+	return true
+}
@@ -0,0 +1,177 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+var errGone = errors.New("gone for good")
+
+// item is a claimed item of the test queue
+type item struct {
+	attempts int32
+}
+
+// ending records how the attempt of an item was ended
+type ending struct {
+	succeeded     bool
+	retried       bool
+	failed        bool
+	attemptErr    error
+	nextAttemptAt time.Time
+}
+
+func newWorker(claimed *item, attemptErr error, retries bool) (*Worker[item], *ending) {
+	ended := &ending{}
+	worker := &Worker[item]{
+		Name:   "test",
+		Logger: zap.NewNop(),
+		Tracer: noop.NewTracerProvider().Tracer("queue"),
+		Policy: Policy{MaxAttempts: 3, BaseRetryDelay: time.Minute, MaxRetryDelay: time.Hour},
+		Claim: func(context.Context) (item, error) {
+			if claimed == nil {
+				return item{}, pgx.ErrNoRows
+			}
+			return *claimed, nil
+		},
+		Attempts:  func(i item) int32 { return i.attempts },
+		Fields:    func(item) []zap.Field { return nil },
+		Attempt:   func(context.Context, item) error { return attemptErr },
+		Permanent: func(err error) bool { return errors.Is(err, errGone) },
+		Succeed: func(context.Context, item) (int64, error) {
+			ended.succeeded = true
+			return 1, nil
+		},
+		Fail: func(_ context.Context, _ item, err error) (int64, error) {
+			ended.failed = true
+			ended.attemptErr = err
+			return 1, nil
+		},
+	}
+	if retries {
+		worker.Retry = func(_ context.Context, _ item, err error, nextAttemptAt time.Time) (int64, error) {
+			ended.retried = true
+			ended.attemptErr = err
+			ended.nextAttemptAt = nextAttemptAt
+			return 1, nil
+		}
+	}
+	return worker, ended
+}
+
+func TestWorker_ProcessNext(t *testing.T) {
+	testCases := []struct {
+		name       string
+		claimed    *item
+		attemptErr error
+		retries    bool
+		processed  bool
+		validate   func(t *testing.T, ended *ending)
+	}{
+		{
+			name:      "Empty queue processes nothing",
+			processed: false,
+			validate: func(t *testing.T, ended *ending) {
+				require.Equal(t, ending{}, *ended)
+			},
+		},
+		{
+			name:      "Successful attempt succeeds",
+			claimed:   &item{attempts: 1},
+			retries:   true,
+			processed: true,
+			validate: func(t *testing.T, ended *ending) {
+				require.Equal(t, ending{succeeded: true}, *ended)
+			},
+		},
+		{
+			name:       "Failed attempt is retried after backoff",
+			claimed:    &item{attempts: 2},
+			attemptErr: errors.New("unavailable"),
+			retries:    true,
+			processed:  true,
+			validate: func(t *testing.T, ended *ending) {
+				require.True(t, ended.retried)
+				require.False(t, ended.failed)
+				require.EqualError(t, ended.attemptErr, "unavailable")
+				require.WithinDuration(t, time.Now().Add(2*time.Minute), ended.nextAttemptAt, 5*time.Second)
+			},
+		},
+		{
+			name:       "Last failed attempt fails the item",
+			claimed:    &item{attempts: 3},
+			attemptErr: errors.New("unavailable"),
+			retries:    true,
+			processed:  true,
+			validate: func(t *testing.T, ended *ending) {
+				require.False(t, ended.retried)
+				require.True(t, ended.failed)
+			},
+		},
+		{
+			name:       "Permanent failure fails the item without a retry",
+			claimed:    &item{attempts: 1},
+			attemptErr: errGone,
+			retries:    true,
+			processed:  true,
+			validate: func(t *testing.T, ended *ending) {
+				require.False(t, ended.retried)
+				require.True(t, ended.failed)
+				require.ErrorIs(t, ended.attemptErr, errGone)
+			},
+		},
+		{
+			name:       "Queue without retries fails the item at once",
+			claimed:    &item{attempts: 1},
+			attemptErr: errors.New("unavailable"),
+			processed:  true,
+			validate: func(t *testing.T, ended *ending) {
+				require.True(t, ended.failed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			worker, ended := newWorker(tc.claimed, tc.attemptErr, tc.retries)
+			require.Equal(t, tc.processed, worker.ProcessNext(context.Background()))
+			tc.validate(t, ended)
+		})
+	}
+}
+
+func TestWorker_ProcessNext_Shutdown(t *testing.T) {
+	worker, ended := newWorker(&item{attempts: 1}, context.Canceled, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.True(t, worker.ProcessNext(ctx))
+	require.Equal(t, ending{}, *ended, "an interrupted attempt is left for RequeueStale")
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	policy := Policy{BaseRetryDelay: 5 * time.Second, MaxRetryDelay: 30 * time.Minute}
+	testCases := []struct {
+		attempts int32
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 5 * time.Second},
+		{attempts: 2, expected: 10 * time.Second},
+		{attempts: 5, expected: 80 * time.Second},
+		{attempts: 10, expected: 30 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(int(tc.attempts)), func(t *testing.T) {
+			require.Equal(t, tc.expected, policy.Backoff(tc.attempts))
+		})
+	}
+}
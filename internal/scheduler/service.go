@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/publish"
+	"context"
+	"time"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type FormStore interface {
+	ClaimDueForPublish(ctx context.Context, skipIDs []uuid.UUID) (form.ClaimDueForPublishRow, bool, error)
+	CloseExpired(ctx context.Context) ([]uuid.UUID, error)
+	WithTx(tx pgx.Tx) *form.Service
+}
+
+type Publisher interface {
+	PublishForm(ctx context.Context, formID uuid.UUID, editor uuid.UUID, selection publish.Selection) (form.Visibility, error)
+	WithTx(tx pgx.Tx) *publish.Service
+}
+
+type EventPublisher interface {
+	PublishFormEvent(ctx context.Context, eventType string, formID uuid.UUID) error
+	WithTx(tx pgx.Tx) *outbox.Service
+}
+
+type Reminder interface {
+	SendDue(ctx context.Context) int
+}
+
+// Service moves forms through their lifecycle based on time: draft forms are published once
+// their publish_time has passed, users are reminded of the deadlines of published forms and
+// published forms are closed once their deadline has passed.
+//
+// Every replica of the backend runs its own Service. Due forms are claimed with
+// FOR UPDATE SKIP LOCKED, so a form is published by exactly one replica, and closing is a
+// single conditional UPDATE, so running it concurrently is harmless.
+type Service struct {
+	logger    *zap.Logger
+	tracer    trace.Tracer
+	db        internal.DBTX
+	interval  time.Duration
+	formStore FormStore
+	publisher Publisher
+	events    EventPublisher
+	reminders Reminder
+}
+
+func NewService(logger *zap.Logger, db internal.DBTX, interval time.Duration, formStore FormStore, publisher Publisher, events EventPublisher, reminders Reminder) *Service {
+	return &Service{
+		logger:    logger,
+		tracer:    otel.Tracer("scheduler/service"),
+		db:        db,
+		interval:  interval,
+		formStore: formStore,
+		publisher: publisher,
+		events:    events,
+		reminders: reminders,
+	}
+}
+
+// Run executes Tick every interval until ctx is canceled
+func (s *Service) Run(ctx context.Context) {
+	s.logger.Info("Starting form scheduler", zap.Duration("interval", s.interval))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Form scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick publishes every draft form that is due, sends the due deadline reminders and closes every
+// published form that is expired. Errors are logged rather than returned so that one broken form
+// does not stop the scheduler.
+func (s *Service) Tick(ctx context.Context) {
+	traceCtx, span := s.tracer.Start(ctx, "Tick")
+	defer span.End()
+
+	s.PublishDue(traceCtx)
+	s.reminders.SendDue(traceCtx)
+	s.CloseExpired(traceCtx)
+}
+
+// PublishDue publishes draft forms whose publish_time has passed through publish.Service.PublishForm,
+// delivering them to every member of the form's unit on behalf of the form's last editor.
+// Each form is claimed and published in its own transaction; a form that fails to publish is
+// skipped for the rest of this run and retried on the next one.
+func (s *Service) PublishDue(ctx context.Context) int {
+	traceCtx, span := s.tracer.Start(ctx, "PublishDue")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	var failed []uuid.UUID
+	published := 0
+	for traceCtx.Err() == nil {
+		var claimed form.ClaimDueForPublishRow
+		var found bool
+		err := internal.WithTransaction(traceCtx, s.db, logger, func(tx pgx.Tx) error {
+			var err error
+			claimed, found, err = s.formStore.WithTx(tx).ClaimDueForPublish(traceCtx, failed)
+			if err != nil || !found {
+				return err
+			}
+
+			selection := publish.Selection{}
+			if claimed.UnitID.Valid {
+				selection.UnitIDs = []uuid.UUID{claimed.UnitID.Bytes}
+			}
+
+			_, err = s.publisher.WithTx(tx).PublishForm(traceCtx, claimed.ID, claimed.LastEditor, selection)
+			return err
+		})
+		if err != nil && !found {
+			span.RecordError(err)
+			logger.Error("Failed to claim form due for publish", zap.Error(err))
+			break
+		}
+		if !found {
+			break
+		}
+		if err != nil {
+			span.RecordError(err)
+			logger.Error("Failed to publish scheduled form", zap.Error(err), zap.String("form_id", claimed.ID.String()))
+			failed = append(failed, claimed.ID)
+			continue
+		}
+
+		published++
+	}
+
+	if published > 0 {
+		logger.Info("Published scheduled forms", zap.Int("count", published))
+	}
+
+	return published
+}
+
+// CloseExpired closes published forms whose deadline has passed and records their form.closed events
+// in the outbox in the same transaction
+func (s *Service) CloseExpired(ctx context.Context) int {
+	traceCtx, span := s.tracer.Start(ctx, "CloseExpired")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	var closed []uuid.UUID
+	err := internal.WithTransaction(traceCtx, s.db, logger, func(tx pgx.Tx) error {
+		var err error
+		closed, err = s.formStore.WithTx(tx).CloseExpired(traceCtx)
+		if err != nil {
+			return err
+		}
+
+		events := s.events.WithTx(tx)
+		for _, id := range closed {
+			err = events.PublishFormEvent(traceCtx, outbox.TypeFormClosed, id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		logger.Error("Failed to close expired forms", zap.Error(err))
+		return 0
+	}
+
+	if len(closed) > 0 {
+		logger.Info("Closed expired forms", zap.Int("count", len(closed)))
+	}
+
+	return len(closed)
+}
@@ -92,6 +92,16 @@ func NewService(logger *zap.Logger, db DBTX, tenantStore tenantStore) *Service {
 	}
 }
 
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		db:          tx,
+		logger:      s.logger,
+		queries:     s.queries.WithTx(tx),
+		tracer:      s.tracer,
+		tenantStore: s.tenantStore,
+	}
+}
+
 func (s *Service) withTransaction(ctx context.Context, fn func(*Queries) error) error {
 	return internal.WithTransaction(ctx, s.db, s.logger, func(tx pgx.Tx) error {
 		return fn(s.queries.WithTx(tx))
@@ -15,8 +15,10 @@ import (
 type ContentType string
 
 const (
-	ContentTypeText ContentType = "text"
-	ContentTypeForm ContentType = "form"
+	ContentTypeText     ContentType = "text"
+	ContentTypeForm     ContentType = "form"
+	ContentTypeExport   ContentType = "export"
+	ContentTypeReminder ContentType = "reminder"
 )
 
 func (e *ContentType) Scan(src interface{}) error {
@@ -96,6 +98,225 @@ func (ns NullDbStrategy) Value() (driver.Value, error) {
 	return string(ns.DbStrategy), nil
 }
 
+type EventStatus string
+
+const (
+	EventStatusPending     EventStatus = "pending"
+	EventStatusDispatching EventStatus = "dispatching"
+	EventStatusDispatched  EventStatus = "dispatched"
+	EventStatusFailed      EventStatus = "failed"
+)
+
+func (e *EventStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EventStatus(s)
+	case string:
+		*e = EventStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EventStatus: %T", src)
+	}
+	return nil
+}
+
+type NullEventStatus struct {
+	EventStatus EventStatus
+	Valid       bool // Valid is true if EventStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEventStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.EventStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EventStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEventStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EventStatus), nil
+}
+
+type ExportJobStatus string
+
+const (
+	ExportJobStatusPending   ExportJobStatus = "pending"
+	ExportJobStatusRunning   ExportJobStatus = "running"
+	ExportJobStatusCompleted ExportJobStatus = "completed"
+	ExportJobStatusFailed    ExportJobStatus = "failed"
+)
+
+func (e *ExportJobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportJobStatus(s)
+	case string:
+		*e = ExportJobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportJobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullExportJobStatus struct {
+	ExportJobStatus ExportJobStatus
+	Valid           bool // Valid is true if ExportJobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportJobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportJobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportJobStatus), nil
+}
+
+type FileDownloadMethod string
+
+const (
+	FileDownloadMethodSession   FileDownloadMethod = "session"
+	FileDownloadMethodSignedUrl FileDownloadMethod = "signed_url"
+	FileDownloadMethodPublic    FileDownloadMethod = "public"
+)
+
+func (e *FileDownloadMethod) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FileDownloadMethod(s)
+	case string:
+		*e = FileDownloadMethod(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FileDownloadMethod: %T", src)
+	}
+	return nil
+}
+
+type NullFileDownloadMethod struct {
+	FileDownloadMethod FileDownloadMethod
+	Valid              bool // Valid is true if FileDownloadMethod is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFileDownloadMethod) Scan(value interface{}) error {
+	if value == nil {
+		ns.FileDownloadMethod, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FileDownloadMethod.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFileDownloadMethod) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FileDownloadMethod), nil
+}
+
+type FileScanStatus string
+
+const (
+	FileScanStatusNotScanned FileScanStatus = "not_scanned"
+	FileScanStatusPending    FileScanStatus = "pending"
+	FileScanStatusClean      FileScanStatus = "clean"
+	FileScanStatusInfected   FileScanStatus = "infected"
+	FileScanStatusFailed     FileScanStatus = "failed"
+)
+
+func (e *FileScanStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FileScanStatus(s)
+	case string:
+		*e = FileScanStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FileScanStatus: %T", src)
+	}
+	return nil
+}
+
+type NullFileScanStatus struct {
+	FileScanStatus FileScanStatus
+	Valid          bool // Valid is true if FileScanStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFileScanStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.FileScanStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FileScanStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFileScanStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FileScanStatus), nil
+}
+
+type FileVariantSize string
+
+const (
+	FileVariantSizeSmall  FileVariantSize = "small"
+	FileVariantSizeMedium FileVariantSize = "medium"
+	FileVariantSizeLarge  FileVariantSize = "large"
+)
+
+func (e *FileVariantSize) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FileVariantSize(s)
+	case string:
+		*e = FileVariantSize(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FileVariantSize: %T", src)
+	}
+	return nil
+}
+
+type NullFileVariantSize struct {
+	FileVariantSize FileVariantSize
+	Valid           bool // Valid is true if FileVariantSize is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFileVariantSize) Scan(value interface{}) error {
+	if value == nil {
+		ns.FileVariantSize, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FileVariantSize.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFileVariantSize) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FileVariantSize), nil
+}
+
 type NodeType string
 
 const (
@@ -103,6 +324,7 @@ const (
 	NodeTypeEnd       NodeType = "end"
 	NodeTypeStart     NodeType = "start"
 	NodeTypeCondition NodeType = "condition"
+	NodeTypeSwitch    NodeType = "switch"
 )
 
 func (e *NodeType) Scan(src interface{}) error {
@@ -140,6 +362,50 @@ func (ns NullNodeType) Value() (driver.Value, error) {
 	return string(ns.NodeType), nil
 }
 
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending"
+	NotificationStatusSending NotificationStatus = "sending"
+	NotificationStatusSent    NotificationStatus = "sent"
+	NotificationStatusFailed  NotificationStatus = "failed"
+)
+
+func (e *NotificationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationStatus(s)
+	case string:
+		*e = NotificationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationStatus struct {
+	NotificationStatus NotificationStatus
+	Valid              bool // Valid is true if NotificationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationStatus), nil
+}
+
 type QuestionType string
 
 const (
@@ -276,6 +542,49 @@ func (ns NullResponseProgress) Value() (driver.Value, error) {
 	return string(ns.ResponseProgress), nil
 }
 
+type SheetSyncStatus string
+
+const (
+	SheetSyncStatusPending SheetSyncStatus = "pending"
+	SheetSyncStatusSynced  SheetSyncStatus = "synced"
+	SheetSyncStatusFailed  SheetSyncStatus = "failed"
+)
+
+func (e *SheetSyncStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SheetSyncStatus(s)
+	case string:
+		*e = SheetSyncStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SheetSyncStatus: %T", src)
+	}
+	return nil
+}
+
+type NullSheetSyncStatus struct {
+	SheetSyncStatus SheetSyncStatus
+	Valid           bool // Valid is true if SheetSyncStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSheetSyncStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.SheetSyncStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SheetSyncStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSheetSyncStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SheetSyncStatus), nil
+}
+
 type Status string
 
 const (
@@ -446,6 +755,50 @@ func (ns NullVisibility) Value() (driver.Value, error) {
 	return string(ns.Visibility), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending    WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivering WebhookDeliveryStatus = "delivering"
+	WebhookDeliveryStatusSucceeded  WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed     WebhookDeliveryStatus = "failed"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus
+	Valid                 bool // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
 type Answer struct {
 	ID         uuid.UUID
 	ResponseID uuid.UUID
@@ -464,15 +817,37 @@ type Auth struct {
 	UpdatedAt  pgtype.Timestamptz
 }
 
+type Event struct {
+	ID            uuid.UUID
+	Seq           int64
+	AggregateType string
+	AggregateID   uuid.UUID
+	Type          string
+	Payload       []byte
+	Status        EventStatus
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	Error         pgtype.Text
+	DispatchedAt  pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
 type File struct {
 	ID               uuid.UUID
 	OriginalFilename string
 	ContentType      string
 	Size             int64
 	Data             []byte
+	StorageKey       pgtype.Text
+	Checksum         pgtype.Text
 	UploadedBy       pgtype.UUID
 	CreatedAt        pgtype.Timestamptz
 	UpdatedAt        pgtype.Timestamptz
+	ScanStatus       FileScanStatus
+	ScanSignature    pgtype.Text
+	ScannedAt        pgtype.Timestamptz
+	IsPublic         bool
 }
 
 type FileAttachment struct {
@@ -484,38 +859,77 @@ type FileAttachment struct {
 	CreatedAt    pgtype.Timestamptz
 }
 
+type FileDownload struct {
+	ID          uuid.UUID
+	FileID      uuid.UUID
+	UserID      pgtype.UUID
+	Method      FileDownloadMethod
+	VariantSize NullFileVariantSize
+	IpAddress   pgtype.Text
+	UserAgent   pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+}
+
+type FileVariant struct {
+	FileID      uuid.UUID
+	Size        FileVariantSize
+	ContentType string
+	ByteSize    int64
+	Width       int32
+	Height      int32
+	StorageKey  string
+	Checksum    string
+	CreatedAt   pgtype.Timestamptz
+}
+
 type Form struct {
-	ID                     uuid.UUID
-	Title                  string
-	DescriptionJson        []byte
-	DescriptionHtml        string
-	PreviewMessage         pgtype.Text
-	MessageAfterSubmission string
-	Status                 Status
-	UnitID                 pgtype.UUID
-	CreatedBy              uuid.UUID
-	LastEditor             uuid.UUID
-	Deadline               pgtype.Timestamptz
-	CreatedAt              pgtype.Timestamptz
-	UpdatedAt              pgtype.Timestamptz
-	Visibility             Visibility
-	GoogleSheetUrl         pgtype.Text
-	PublishTime            pgtype.Timestamptz
-	CoverImageUrl          pgtype.Text
-	DressingColor          pgtype.Text
-	DressingHeaderFont     pgtype.Text
-	DressingQuestionFont   pgtype.Text
-	DressingTextFont       pgtype.Text
-	AllowEditResponse      bool
+	ID                       uuid.UUID
+	Title                    string
+	DescriptionJson          []byte
+	DescriptionHtml          string
+	PreviewMessage           pgtype.Text
+	MessageAfterSubmission   string
+	Status                   Status
+	UnitID                   pgtype.UUID
+	CreatedBy                uuid.UUID
+	LastEditor               uuid.UUID
+	Deadline                 pgtype.Timestamptz
+	CreatedAt                pgtype.Timestamptz
+	UpdatedAt                pgtype.Timestamptz
+	Visibility               Visibility
+	GoogleSheetUrl           pgtype.Text
+	PublishTime              pgtype.Timestamptz
+	CoverImageUrl            pgtype.Text
+	DressingColor            pgtype.Text
+	DressingHeaderFont       pgtype.Text
+	DressingQuestionFont     pgtype.Text
+	DressingTextFont         pgtype.Text
+	AllowEditResponse        bool
+	DeadlineRemindersEnabled bool
+	DeadlineReminderHours    []int32
 }
 
 type FormCover struct {
 	FormID    uuid.UUID
-	ImageData []byte
+	FileID    uuid.UUID
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
 }
 
+type FormDeadlineReminder struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	HoursBefore int32
+	Deadline    pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+}
+
+type FormDeadlineReminderRecipient struct {
+	ReminderID uuid.UUID
+	UserID     uuid.UUID
+	RemindedAt pgtype.Timestamptz
+}
+
 type FormHighlight struct {
 	ID           uuid.UUID
 	FormID       uuid.UUID
@@ -526,13 +940,38 @@ type FormHighlight struct {
 }
 
 type FormResponse struct {
-	ID          uuid.UUID
-	FormID      uuid.UUID
-	SubmittedBy uuid.UUID
-	SubmittedAt pgtype.Timestamptz
-	Progress    ResponseProgress
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
+	ID                uuid.UUID
+	FormID            uuid.UUID
+	SubmittedBy       uuid.UUID
+	SubmittedAt       pgtype.Timestamptz
+	Progress          ResponseProgress
+	WorkflowVersionID pgtype.UUID
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type FormResponseDigest struct {
+	FormID        uuid.UUID
+	DigestedUntil pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type FormSheetSync struct {
+	FormID       uuid.UUID
+	SheetUrl     string
+	Status       SheetSyncStatus
+	Error        pgtype.Text
+	LastSyncedAt pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	LockedUntil  pgtype.Timestamptz
+}
+
+type FormSheetSyncRow struct {
+	FormID     uuid.UUID
+	ResponseID uuid.UUID
+	RowNumber  int32
+	Struck     bool
 }
 
 type InboxMessage struct {
@@ -544,6 +983,24 @@ type InboxMessage struct {
 	UpdatedAt pgtype.Timestamp
 }
 
+type Notification struct {
+	ID            uuid.UUID
+	Kind          string
+	RecipientID   pgtype.UUID
+	ToAddress     string
+	Subject       string
+	TextBody      string
+	HtmlBody      string
+	DedupeKey     string
+	Status        NotificationStatus
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	Error         pgtype.Text
+	SentAt        pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
 type Question struct {
 	ID              uuid.UUID
 	SectionID       uuid.UUID
@@ -566,6 +1023,25 @@ type RefreshToken struct {
 	ExpirationDate pgtype.Timestamptz
 }
 
+type ResponseExportJob struct {
+	ID             uuid.UUID
+	FormID         uuid.UUID
+	RequestedBy    uuid.UUID
+	QuestionIds    []uuid.UUID
+	Format         string
+	Filter         []byte
+	Status         ExportJobStatus
+	ProcessedCount int32
+	TotalCount     int32
+	Attempts       int32
+	FileID         pgtype.UUID
+	Error          pgtype.Text
+	StartedAt      pgtype.Timestamptz
+	CompletedAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
 type Section struct {
 	ID              uuid.UUID
 	FormID          uuid.UUID
@@ -648,15 +1124,45 @@ type UserWithEmails struct {
 }
 
 type View struct {
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	Title      string
+	Locked     bool
+	Order      int32
+	Definition []byte
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Webhook struct {
 	ID        uuid.UUID
-	FormID    uuid.UUID
-	Title     string
-	Locked    bool
-	Order     int32
+	UnitID    uuid.UUID
+	FormID    pgtype.UUID
+	Url       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedBy pgtype.UUID
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
 }
 
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	Event          string
+	Payload        []byte
+	Status         WebhookDeliveryStatus
+	Attempts       int32
+	NextAttemptAt  pgtype.Timestamptz
+	ResponseStatus pgtype.Int4
+	Error          pgtype.Text
+	DeliveredAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	EventID        pgtype.UUID
+}
+
 type WorkflowVersion struct {
 	ID         uuid.UUID
 	FormID     uuid.UUID
@@ -356,6 +356,8 @@ func (s *Service) downloadAndSaveAvatar(ctx context.Context, avatarURL string, u
 	validationOpts := []file.ValidatorOption{
 		file.WithMaxSize(maxAvatarSize),
 		file.WithImageFormats(), // Accept JPEG, PNG, or WebP
+		file.WithImageVariants(),
+		file.WithPublicAccess(),
 	}
 
 	// Use file service to download and save avatar
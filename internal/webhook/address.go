@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+)
+
+// nonPublicPrefixes are the address ranges a webhook is never delivered to: the special-purpose
+// ranges of the IANA registries, which reach the backend itself, its internal network, or no single
+// public host.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, including cloud metadata services
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, including broadcast
+	netip.MustParsePrefix("::/128"),          // unspecified
+	netip.MustParsePrefix("::1/128"),         // loopback
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/32"),       // Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("3fff::/20"),       // documentation
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+var (
+	// nat64Prefix is the well-known NAT64 prefix; its last 32 bits are the IPv4 address reached
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	// sixToFourPrefix is the 6to4 prefix; the 32 bits after it are the IPv4 address reached
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// isPublicAddress reports whether a webhook may be delivered to addr. An IPv6 address that embeds
+// an IPv4 address reaches the embedded address, so that one is checked instead.
+func isPublicAddress(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+
+	// A prefix never contains an address with a zone
+	addr = embeddedIPv4(addr.Unmap().WithZone(""))
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// embeddedIPv4 returns the IPv4 address a NAT64 or 6to4 address reaches, or addr itself
+func embeddedIPv4(addr netip.Addr) netip.Addr {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16]))
+	case sixToFourPrefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6]))
+	}
+	return addr
+}
+
+// checkHost resolves the host of an endpoint and checks that every address it resolves to is public
+func checkHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", internal.ErrInvalidWebhookURL, err)
+	}
+
+	for _, addr := range addrs {
+		if !isPublicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", internal.ErrWebhookAddressNotAllowed, host, addr)
+		}
+	}
+	return nil
+}
+
+// refuseNonPublic is the Control of the delivery dialer. It runs after the host of the endpoint was
+// resolved, so an endpoint whose DNS record changed since it was registered cannot reach a
+// non-public address either.
+func refuseNonPublic(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", internal.ErrWebhookAddressNotAllowed, address)
+	}
+	if !isPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", internal.ErrWebhookAddressNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
+
+// newDeliveryClient returns the client deliveries are POSTed with. control checks every address the
+// client dials; deliveries do not go through a proxy, whose address would be checked instead.
+func newDeliveryClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   deliveryTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		// A redirect is reported as a failed attempt rather than followed, so deliveries only go
+		// to the URL the admins registered
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
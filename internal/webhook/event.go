@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is a change of a form or response that webhooks subscribe to
+type EventType string
+
+const (
+	EventFormPublished     EventType = "form.published"
+	EventFormClosed        EventType = "form.closed"
+	EventResponseSubmitted EventType = "response.submitted"
+	EventResponseCancelled EventType = "response.cancelled"
+	EventResponseDeleted   EventType = "response.deleted"
+)
+
+// Events lists every event a webhook can subscribe to
+var Events = []EventType{
+	EventFormPublished,
+	EventFormClosed,
+	EventResponseSubmitted,
+	EventResponseCancelled,
+	EventResponseDeleted,
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	Event      EventType `json:"event"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Form       FormData  `json:"form"`
+	// Response is set for response events
+	Response *ResponseData `json:"response,omitempty"`
+}
+
+type FormData struct {
+	ID uuid.UUID `json:"id"`
+	// Status is the status of the form when the delivery was queued, shortly after the event: DRAFT,
+	// PUBLISHED, CLOSED or ARCHIVED
+	Status   string     `json:"status"`
+	Title    string     `json:"title"`
+	UnitID   *uuid.UUID `json:"unitId"`
+	Deadline *time.Time `json:"deadline"`
+}
+
+type ResponseData struct {
+	ID          uuid.UUID  `json:"id"`
+	SubmittedBy uuid.UUID  `json:"submittedBy"`
+	SubmittedAt *time.Time `json:"submittedAt"`
+}
+
+func formData(summary GetFormSummaryRow) FormData {
+	data := FormData{
+		ID:     summary.ID,
+		Status: strings.ToUpper(string(summary.Status)),
+		Title:  summary.Title,
+	}
+	if summary.UnitID.Valid {
+		unitID := uuid.UUID(summary.UnitID.Bytes)
+		data.UnitID = &unitID
+	}
+	if summary.Deadline.Valid {
+		deadline := summary.Deadline.Time
+		data.Deadline = &deadline
+	}
+	return data
+}
+
+// ParseEvents checks the events a webhook subscribes to and drops duplicates
+func ParseEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: a webhook must subscribe to at least one event", internal.ErrInvalidWebhookEvent)
+	}
+
+	parsed := make([]string, 0, len(events))
+	for _, event := range events {
+		if !slices.Contains(Events, EventType(event)) {
+			return nil, fmt.Errorf("%w: %q", internal.ErrInvalidWebhookEvent, event)
+		}
+		if !slices.Contains(parsed, event) {
+			parsed = append(parsed, event)
+		}
+	}
+
+	return parsed, nil
+}
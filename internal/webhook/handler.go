@@ -0,0 +1,406 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Request struct {
+	URL string `json:"url" validate:"required"`
+	// Events are the events the webhook subscribes to, such as form.published or response.submitted
+	Events []string `json:"events" validate:"required,min=1"`
+}
+
+type UpdateRequest struct {
+	URL    string   `json:"url" validate:"required"`
+	Events []string `json:"events" validate:"required,min=1"`
+	Active *bool    `json:"active" validate:"required"`
+}
+
+type Response struct {
+	ID     string `json:"id"`
+	UnitID string `json:"unitId"`
+	// FormID is set for webhooks of one form
+	FormID string   `json:"formId,omitempty"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// Secret signs the deliveries of the webhook; it is only returned when the webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type DeliveryResponse struct {
+	ID        string          `json:"id"`
+	WebhookID string          `json:"webhookId"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+	// Status is PENDING, DELIVERING, SUCCEEDED or FAILED
+	Status   string `json:"status"`
+	Attempts int32  `json:"attempts"`
+	// NextAttemptAt is when a pending delivery is attempted next
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	// ResponseStatus and Error describe the last attempt
+	ResponseStatus *int32     `json:"responseStatus"`
+	Error          string     `json:"error,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type Store interface {
+	CreateForOrg(ctx context.Context, orgID uuid.UUID, createdBy uuid.UUID, endpoint string, events []string) (Webhook, error)
+	CreateForForm(ctx context.Context, formID uuid.UUID, createdBy uuid.UUID, endpoint string, events []string) (Webhook, error)
+	Get(ctx context.Context, id uuid.UUID) (Webhook, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]Webhook, error)
+	ListByForm(ctx context.Context, formID uuid.UUID) ([]Webhook, error)
+	Update(ctx context.Context, id uuid.UUID, endpoint string, events []string, active bool) (Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID) ([]WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) (WebhookDelivery, error)
+}
+
+type TenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type Handler struct {
+	logger        *zap.Logger
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+	store         Store
+	tenantStore   TenantStore
+	tracer        trace.Tracer
+}
+
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, tenantStore TenantStore) *Handler {
+	return &Handler{
+		logger:        logger,
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+		tracer:        otel.Tracer("webhook/handler"),
+	}
+}
+
+func ToResponse(webhook Webhook) Response {
+	resp := Response{
+		ID:        webhook.ID.String(),
+		UnitID:    webhook.UnitID.String(),
+		URL:       webhook.Url,
+		Events:    webhook.Events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt.Time,
+		UpdatedAt: webhook.UpdatedAt.Time,
+	}
+	if webhook.FormID.Valid {
+		resp.FormID = uuid.UUID(webhook.FormID.Bytes).String()
+	}
+	return resp
+}
+
+func ToDeliveryResponse(delivery WebhookDelivery) DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:            delivery.ID.String(),
+		WebhookID:     delivery.WebhookID.String(),
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        strings.ToUpper(string(delivery.Status)),
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt.Time,
+		CreatedAt:     delivery.CreatedAt.Time,
+	}
+	if delivery.ResponseStatus.Valid {
+		responseStatus := delivery.ResponseStatus.Int32
+		resp.ResponseStatus = &responseStatus
+	}
+	if delivery.Error.Valid {
+		resp.Error = delivery.Error.String
+	}
+	if delivery.DeliveredAt.Valid {
+		deliveredAt := delivery.DeliveredAt.Time
+		resp.DeliveredAt = &deliveredAt
+	}
+	return resp
+}
+
+// withSecret returns the response of a new webhook, the only one that carries its secret
+func withSecret(webhook Webhook) Response {
+	resp := ToResponse(webhook)
+	resp.Secret = webhook.Secret
+	return resp
+}
+
+// CreateForOrg registers a webhook for every form of an organization (requires org admin permission)
+func (h *Handler) CreateForOrg(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateForOrg")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.orgID(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	created, err := h.store.CreateForOrg(traceCtx, orgID, currentUser.ID, req.URL, req.Events)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, withSecret(created))
+}
+
+// ListByOrg lists the webhooks of an organization (requires org admin permission)
+func (h *Handler) ListByOrg(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListByOrg")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, err := h.orgID(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	webhooks, err := h.store.ListByOrg(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, toResponses(webhooks))
+}
+
+// CreateForForm registers a webhook for one form (requires unit admin permission)
+func (h *Handler) CreateForForm(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateForForm")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	var req Request
+	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	created, err := h.store.CreateForForm(traceCtx, formID, currentUser.ID, req.URL, req.Events)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, withSecret(created))
+}
+
+// ListByForm lists the webhooks of a form (requires unit admin permission)
+func (h *Handler) ListByForm(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListByForm")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	webhooks, err := h.store.ListByForm(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, toResponses(webhooks))
+}
+
+// Get returns a webhook (requires admin permission of its unit)
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := handlerutil.ParseUUID(r.PathValue("webhookId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	found, err := h.store.Get(traceCtx, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(found))
+}
+
+// Update changes the endpoint and events of a webhook, and pauses or resumes it (requires admin
+// permission of its unit)
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Update")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := handlerutil.ParseUUID(r.PathValue("webhookId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req UpdateRequest
+	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	updated, err := h.store.Update(traceCtx, id, req.URL, req.Events, *req.Active)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(updated))
+}
+
+// Delete removes a webhook with its deliveries (requires admin permission of its unit)
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := handlerutil.ParseUUID(r.PathValue("webhookId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first (requires admin permission of its unit)
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListDeliveries")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := handlerutil.ParseUUID(r.PathValue("webhookId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	deliveries, err := h.store.ListDeliveries(traceCtx, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	resp := make([]DeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, ToDeliveryResponse(delivery))
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
+}
+
+// Redeliver queues the event of a delivery again (requires admin permission of its unit)
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Redeliver")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := handlerutil.ParseUUID(r.PathValue("webhookId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	deliveryID, err := handlerutil.ParseUUID(r.PathValue("deliveryId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	delivery, err := h.store.Redeliver(traceCtx, id, deliveryID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusAccepted, ToDeliveryResponse(delivery))
+}
+
+// orgID returns the organization of the slug in the request path
+func (h *Handler) orgID(ctx context.Context) (uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return orgID, nil
+}
+
+func toResponses(webhooks []Webhook) []Response {
+	resp := make([]Response, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, ToResponse(webhook))
+	}
+	return resp
+}
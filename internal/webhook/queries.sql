@@ -0,0 +1,114 @@
+-- name: CreateForOrg :one
+INSERT INTO webhooks (unit_id, url, secret, events, created_by)
+VALUES (@unit_id, @url, @secret, @events, @created_by::uuid)
+RETURNING *;
+
+-- name: CreateForForm :one
+-- Creates a webhook of a form, managed by the admins of the form's unit
+INSERT INTO webhooks (unit_id, form_id, url, secret, events, created_by)
+SELECT f.unit_id, f.id, @url::text, @secret::text, @events::text[], @created_by::uuid
+FROM forms f
+WHERE f.id = @form_id AND f.unit_id IS NOT NULL
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM webhooks WHERE id = @id;
+
+-- name: GetUnitID :one
+SELECT unit_id FROM webhooks WHERE id = @id;
+
+-- name: ListByOrg :many
+SELECT * FROM webhooks
+WHERE unit_id = @unit_id AND form_id IS NULL
+ORDER BY created_at;
+
+-- name: ListByForm :many
+SELECT * FROM webhooks
+WHERE form_id = @form_id
+ORDER BY created_at;
+
+-- name: Update :one
+UPDATE webhooks
+SET url = @url, events = @events, active = @active, updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: Delete :exec
+DELETE FROM webhooks WHERE id = @id;
+
+-- name: GetFormSummary :one
+-- Returns the fields of a form sent with its events, and the organization whose webhooks receive them
+SELECT f.id, f.title, f.status, f.deadline, f.unit_id, COALESCE(u.org_id, u.id) AS org_id
+FROM forms f
+LEFT JOIN units u ON u.id = f.unit_id
+WHERE f.id = @id;
+
+-- name: CreateDeliveries :many
+-- Queues a delivery of an event of a form to every active webhook of the form or of its organization
+-- that subscribes to the event. Webhooks that were already queued a delivery of the outbox event are
+-- skipped, so dispatching the event again queues nothing.
+INSERT INTO webhook_deliveries (webhook_id, event, payload, event_id)
+SELECT w.id, @event::text, @payload::jsonb, @event_id::uuid
+FROM webhooks w
+WHERE w.active
+  AND @event::text = ANY(w.events)
+  AND (w.form_id = @form_id::uuid OR (w.form_id IS NULL AND w.unit_id = sqlc.narg(org_id)))
+ON CONFLICT (webhook_id, event_id) DO NOTHING
+RETURNING *;
+
+-- name: ListDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = @webhook_id
+ORDER BY created_at DESC
+LIMIT @row_limit;
+
+-- name: Redeliver :one
+-- Queues a new delivery with the event and payload of an earlier one
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+SELECT d.webhook_id, d.event, d.payload
+FROM webhook_deliveries d
+WHERE d.id = @id AND d.webhook_id = @webhook_id
+RETURNING *;
+
+-- name: Claim :one
+-- Claims the pending delivery that is due the longest. SKIP LOCKED lets the workers of every replica
+-- claim concurrently without waiting on each other or claiming the same delivery.
+UPDATE webhook_deliveries d
+SET status = 'delivering', attempts = d.attempts + 1, updated_at = now()
+FROM webhooks w
+WHERE w.id = d.webhook_id AND d.id = (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, w.url, w.secret, w.active;
+
+-- name: Succeed :execrows
+-- Only the worker of the current attempt can end it; a worker whose delivery was requeued as stale
+-- updates no row
+UPDATE webhook_deliveries
+SET status = 'succeeded', response_status = @response_status, error = NULL, delivered_at = now(), updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'delivering';
+
+-- name: Retry :execrows
+UPDATE webhook_deliveries
+SET status = 'pending', response_status = @response_status, error = @error, next_attempt_at = @next_attempt_at, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'delivering';
+
+-- name: Fail :execrows
+UPDATE webhook_deliveries
+SET status = 'failed', response_status = @response_status, error = @error, updated_at = now()
+WHERE id = @id AND attempts = @attempts AND status = 'delivering';
+
+-- name: RequeueStale :many
+-- Puts deliveries whose worker stopped while delivering them back in the queue, and fails those that
+-- have already been attempted max_attempts times
+UPDATE webhook_deliveries
+SET status = CASE WHEN attempts >= @max_attempts::int THEN 'failed'::webhook_delivery_status ELSE 'pending'::webhook_delivery_status END,
+    error = CASE WHEN attempts >= @max_attempts::int THEN 'delivery was interrupted too many times' ELSE error END,
+    next_attempt_at = now(),
+    updated_at = now()
+WHERE status = 'delivering' AND updated_at < @stale_before
+RETURNING id, status;
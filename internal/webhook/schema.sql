@@ -0,0 +1,51 @@
+-- Endpoints that are sent the events of the forms of an organization, or of one form when form_id is set
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Organization of the webhook, or unit of its form; its admins manage the webhook
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    form_id UUID REFERENCES forms(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    -- Key of the HMAC-SHA256 signature of every delivery
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_unit_id ON webhooks(unit_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_form_id ON webhooks(form_id);
+
+CREATE TYPE webhook_delivery_status AS ENUM(
+    'pending',
+    'delivering',
+    'succeeded',
+    'failed'
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    -- Request body sent on every attempt
+    payload JSONB NOT NULL,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    -- When a pending delivery is attempted next
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- HTTP status and error of the last attempt
+    response_status INTEGER,
+    error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Touched when an attempt starts, so a delivering row whose updated_at is old was left by a crashed worker
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Outbox event the delivery was queued for; a webhook is queued one delivery per event however often the
+    -- event is dispatched. Redeliveries have none.
+    event_id UUID REFERENCES events(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id_event_id ON webhook_deliveries(webhook_id, event_id);
@@ -0,0 +1,602 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/queue"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// deliveryTimeout is how long an endpoint has to respond to a delivery
+	deliveryTimeout = 10 * time.Second
+	// deliveryListLimit is how many of the latest deliveries of a webhook are listed
+	deliveryListLimit = 100
+	// maxResponseBodySize is how much of an endpoint's response is read before the connection is reused
+	maxResponseBodySize = 64 << 10
+)
+
+// policy retries a failed delivery 8 times, waiting from 30 seconds up to an hour. StaleAfter is well
+// above deliveryTimeout, so only deliveries left by a crashed worker are requeued.
+var policy = queue.Policy{
+	MaxAttempts:    8,
+	BaseRetryDelay: 30 * time.Second,
+	MaxRetryDelay:  time.Hour,
+	StaleAfter:     time.Minute,
+	PollInterval:   5 * time.Second,
+}
+
+// errWebhookDisabled fails the deliveries of a webhook that was disabled after they were queued
+var errWebhookDisabled = errors.New("webhook is disabled")
+
+type Querier interface {
+	CreateForOrg(ctx context.Context, arg CreateForOrgParams) (Webhook, error)
+	CreateForForm(ctx context.Context, arg CreateForFormParams) (Webhook, error)
+	GetByID(ctx context.Context, id uuid.UUID) (Webhook, error)
+	GetUnitID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	ListByOrg(ctx context.Context, unitID uuid.UUID) ([]Webhook, error)
+	ListByForm(ctx context.Context, formID pgtype.UUID) ([]Webhook, error)
+	Update(ctx context.Context, arg UpdateParams) (Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetFormSummary(ctx context.Context, id uuid.UUID) (GetFormSummaryRow, error)
+	CreateDeliveries(ctx context.Context, arg CreateDeliveriesParams) ([]WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, arg ListDeliveriesParams) ([]WebhookDelivery, error)
+	Redeliver(ctx context.Context, arg RedeliverParams) (WebhookDelivery, error)
+	Claim(ctx context.Context) (ClaimRow, error)
+	Succeed(ctx context.Context, arg SucceedParams) (int64, error)
+	Retry(ctx context.Context, arg RetryParams) (int64, error)
+	Fail(ctx context.Context, arg FailParams) (int64, error)
+	RequeueStale(ctx context.Context, arg RequeueStaleParams) ([]RequeueStaleRow, error)
+}
+
+// Service manages the webhooks of organizations and forms and delivers their events.
+//
+// HandleEvent subscribes to the outbox events of forms and responses and queues a delivery of each
+// to the webhooks subscribed to it, so a delivery is queued if and only if the change is committed. Run
+// then POSTs each delivery to its endpoint, retrying failed attempts with exponential backoff.
+//
+// Every replica of the backend runs its own worker. Deliveries are claimed with FOR UPDATE SKIP LOCKED,
+// so each attempt is made by one worker.
+//
+// Endpoints must resolve to public addresses, both when they are registered and when a delivery is
+// sent, unless allowPrivate is set for development.
+type Service struct {
+	logger       *zap.Logger
+	tracer       trace.Tracer
+	queries      Querier
+	client       *http.Client
+	allowPrivate bool
+}
+
+func NewService(logger *zap.Logger, db DBTX, allowPrivate bool) *Service {
+	control := refuseNonPublic
+	if allowPrivate {
+		control = nil
+	}
+
+	return &Service{
+		logger:       logger,
+		tracer:       otel.Tracer("webhook/service"),
+		queries:      New(db),
+		client:       newDeliveryClient(control),
+		allowPrivate: allowPrivate,
+	}
+}
+
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{
+		logger:       s.logger,
+		tracer:       s.tracer,
+		queries:      New(tx),
+		client:       s.client,
+		allowPrivate: s.allowPrivate,
+	}
+}
+
+// CreateForOrg registers a webhook that receives the events of every form of an organization
+func (s *Service) CreateForOrg(ctx context.Context, orgID uuid.UUID, createdBy uuid.UUID, endpoint string, events []string) (Webhook, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CreateForOrg")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	events, secret, err := s.prepareCreate(traceCtx, endpoint, events)
+	if err != nil {
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	created, err := s.queries.CreateForOrg(traceCtx, CreateForOrgParams{
+		UnitID:    orgID,
+		Url:       endpoint,
+		Secret:    secret,
+		Events:    events,
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "unit_id", orgID.String(), logger, "create webhook")
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	logger.Info("Created webhook", zap.String("webhook_id", created.ID.String()), zap.String("org_id", orgID.String()))
+	return created, nil
+}
+
+// CreateForForm registers a webhook that receives the events of one form
+func (s *Service) CreateForForm(ctx context.Context, formID uuid.UUID, createdBy uuid.UUID, endpoint string, events []string) (Webhook, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CreateForForm")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	events, secret, err := s.prepareCreate(traceCtx, endpoint, events)
+	if err != nil {
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	created, err := s.queries.CreateForForm(traceCtx, CreateForFormParams{
+		Url:       endpoint,
+		Secret:    secret,
+		Events:    events,
+		CreatedBy: createdBy,
+		FormID:    formID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, internal.ErrFormNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "form_id", formID.String(), logger, "create webhook")
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	logger.Info("Created webhook", zap.String("webhook_id", created.ID.String()), zap.String("form_id", formID.String()))
+	return created, nil
+}
+
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	found, err := s.queries.GetByID(traceCtx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, internal.ErrWebhookNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "id", id.String(), logger, "get webhook")
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	return found, nil
+}
+
+// GetUnitID returns the unit whose admins manage a webhook
+func (s *Service) GetUnitID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetUnitID")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	unitID, err := s.queries.GetUnitID(traceCtx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, internal.ErrWebhookNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "id", id.String(), logger, "get webhook unit id")
+		span.RecordError(err)
+		return uuid.Nil, err
+	}
+
+	return unitID, nil
+}
+
+// ListByOrg returns the webhooks of an organization, without those of its forms
+func (s *Service) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]Webhook, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListByOrg")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	webhooks, err := s.queries.ListByOrg(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "unit_id", orgID.String(), logger, "list webhooks of org")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (s *Service) ListByForm(ctx context.Context, formID uuid.UUID) ([]Webhook, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListByForm")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	webhooks, err := s.queries.ListByForm(traceCtx, pgtype.UUID{Bytes: formID, Valid: true})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "form_id", formID.String(), logger, "list webhooks of form")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Update changes the endpoint and events of a webhook, and pauses or resumes it; the secret is kept
+func (s *Service) Update(ctx context.Context, id uuid.UUID, endpoint string, events []string, active bool) (Webhook, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.validateURL(traceCtx, endpoint)
+	if err != nil {
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	events, err = ParseEvents(events)
+	if err != nil {
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	updated, err := s.queries.Update(traceCtx, UpdateParams{
+		Url:    endpoint,
+		Events: events,
+		Active: active,
+		ID:     id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, internal.ErrWebhookNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "id", id.String(), logger, "update webhook")
+		span.RecordError(err)
+		return Webhook{}, err
+	}
+
+	return updated, nil
+}
+
+// Delete removes a webhook with its deliveries
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.queries.Delete(traceCtx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhooks", "id", id.String(), logger, "delete webhook")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook, newest first
+func (s *Service) ListDeliveries(ctx context.Context, webhookID uuid.UUID) ([]WebhookDelivery, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListDeliveries")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	deliveries, err := s.queries.ListDeliveries(traceCtx, ListDeliveriesParams{
+		WebhookID: webhookID,
+		RowLimit:  deliveryListLimit,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhook_deliveries", "webhook_id", webhookID.String(), logger, "list webhook deliveries")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver queues a new delivery of the event of an earlier delivery of a webhook, whatever the outcome
+// of the earlier one
+func (s *Service) Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) (WebhookDelivery, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Redeliver")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	delivery, err := s.queries.Redeliver(traceCtx, RedeliverParams{
+		ID:        deliveryID,
+		WebhookID: webhookID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WebhookDelivery{}, internal.ErrWebhookDeliveryNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhook_deliveries", "id", deliveryID.String(), logger, "redeliver webhook delivery")
+		span.RecordError(err)
+		return WebhookDelivery{}, err
+	}
+
+	logger.Info("Queued webhook redelivery",
+		zap.String("webhook_id", webhookID.String()),
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("redelivery_of", deliveryID.String()),
+	)
+	return delivery, nil
+}
+
+// HandleEvent queues a delivery of an outbox event of a form or response to every webhook subscribed
+// to it. An event dispatched again queues no delivery to the webhooks it was already queued for.
+func (s *Service) HandleEvent(ctx context.Context, event outbox.Event) error {
+	traceCtx, span := s.tracer.Start(ctx, "HandleEvent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	eventType := EventType(event.Type)
+	if !slices.Contains(Events, eventType) {
+		return fmt.Errorf("%w: %q", internal.ErrInvalidWebhookEvent, event.Type)
+	}
+
+	var formID uuid.UUID
+	var responseData *ResponseData
+	switch event.AggregateType {
+	case outbox.AggregateForm:
+		var payload outbox.FormPayload
+		err := json.Unmarshal(event.Payload, &payload)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to decode %s event payload: %w", event.Type, err)
+		}
+		formID = payload.FormID
+	case outbox.AggregateResponse:
+		var payload outbox.ResponsePayload
+		err := json.Unmarshal(event.Payload, &payload)
+		if err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to decode %s event payload: %w", event.Type, err)
+		}
+		formID = payload.FormID
+		responseData = &ResponseData{
+			ID:          payload.ResponseID,
+			SubmittedBy: payload.SubmittedBy,
+			SubmittedAt: payload.SubmittedAt,
+		}
+	default:
+		return fmt.Errorf("%w: %q of a %s", internal.ErrInvalidWebhookEvent, event.Type, event.AggregateType)
+	}
+
+	err := s.emit(traceCtx, event, eventType, formID, responseData)
+	if errors.Is(err, internal.ErrFormNotFound) {
+		// The form was deleted since; there is no organization to deliver the event to
+		logger.Warn("Dropped webhook event of a deleted form", zap.String("event_id", event.ID.String()), zap.String("form_id", formID.String()))
+		return nil
+	}
+	return err
+}
+
+func (s *Service) emit(ctx context.Context, event outbox.Event, eventType EventType, formID uuid.UUID, responseData *ResponseData) error {
+	traceCtx, span := s.tracer.Start(ctx, "Emit")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	summary, err := s.queries.GetFormSummary(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return internal.ErrFormNotFound
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form of webhook event")
+		span.RecordError(err)
+		return err
+	}
+
+	payload, err := json.Marshal(Payload{
+		Event:      eventType,
+		OccurredAt: event.CreatedAt.Time.UTC(),
+		Form:       formData(summary),
+		Response:   responseData,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	deliveries, err := s.queries.CreateDeliveries(traceCtx, CreateDeliveriesParams{
+		Event:   string(eventType),
+		Payload: payload,
+		EventID: event.ID,
+		FormID:  formID,
+		OrgID:   summary.OrgID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "webhook_deliveries", "form_id", formID.String(), logger, "queue webhook deliveries")
+		span.RecordError(err)
+		return err
+	}
+
+	if len(deliveries) > 0 {
+		logger.Info("Queued webhook deliveries",
+			zap.String("event", string(eventType)),
+			zap.String("form_id", formID.String()),
+			zap.Int("count", len(deliveries)),
+		)
+	}
+
+	return nil
+}
+
+// Run requeues the deliveries left by stopped workers, then delivers due deliveries until ctx is canceled
+func (s *Service) Run(ctx context.Context) {
+	s.worker().Run(ctx, 1, nil)
+}
+
+// RequeueStale puts deliveries that a stopped worker was delivering back in the queue, or fails them
+// once they have been attempted policy.MaxAttempts times
+func (s *Service) RequeueStale(ctx context.Context) int {
+	return s.worker().RequeueStale(ctx)
+}
+
+// ProcessNext claims the due delivery that has waited the longest and attempts it; it returns false if
+// there was no delivery to claim. A failed attempt is retried after backoff until policy.MaxAttempts
+// attempts have been made; a delivery of a disabled webhook is failed at once.
+func (s *Service) ProcessNext(ctx context.Context) bool {
+	return s.worker().ProcessNext(ctx)
+}
+
+// deliveryAttempt is a claimed delivery and the HTTP status its endpoint responded with
+type deliveryAttempt struct {
+	ClaimRow
+	responseStatus pgtype.Int4
+}
+
+// worker delivers the queued webhook deliveries
+func (s *Service) worker() *queue.Worker[*deliveryAttempt] {
+	return &queue.Worker[*deliveryAttempt]{
+		Name:   "webhook deliveries",
+		Logger: s.logger,
+		Tracer: s.tracer,
+		Policy: policy,
+		Claim: func(ctx context.Context) (*deliveryAttempt, error) {
+			delivery, err := s.queries.Claim(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &deliveryAttempt{ClaimRow: delivery}, nil
+		},
+		Attempts: func(attempt *deliveryAttempt) int32 { return attempt.Attempts },
+		Fields: func(attempt *deliveryAttempt) []zap.Field {
+			return []zap.Field{
+				zap.String("delivery_id", attempt.ID.String()),
+				zap.String("webhook_id", attempt.WebhookID.String()),
+				zap.String("event", attempt.Event),
+			}
+		},
+		Attempt: func(ctx context.Context, attempt *deliveryAttempt) error {
+			if !attempt.Active {
+				return errWebhookDisabled
+			}
+			responseStatus, err := s.deliver(ctx, attempt.ClaimRow)
+			attempt.responseStatus = pgtype.Int4{Int32: int32(responseStatus), Valid: responseStatus != 0}
+			return err
+		},
+		Permanent: func(err error) bool { return errors.Is(err, errWebhookDisabled) },
+		Succeed: func(ctx context.Context, attempt *deliveryAttempt) (int64, error) {
+			return s.queries.Succeed(ctx, SucceedParams{ResponseStatus: attempt.responseStatus, ID: attempt.ID, Attempts: attempt.Attempts})
+		},
+		Retry: func(ctx context.Context, attempt *deliveryAttempt, deliveryErr error, nextAttemptAt time.Time) (int64, error) {
+			return s.queries.Retry(ctx, RetryParams{
+				ResponseStatus: attempt.responseStatus,
+				Error:          pgtype.Text{String: deliveryErr.Error(), Valid: true},
+				NextAttemptAt:  pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+				ID:             attempt.ID,
+				Attempts:       attempt.Attempts,
+			})
+		},
+		Fail: func(ctx context.Context, attempt *deliveryAttempt, deliveryErr error) (int64, error) {
+			return s.queries.Fail(ctx, FailParams{
+				ResponseStatus: attempt.responseStatus,
+				Error:          pgtype.Text{String: deliveryErr.Error(), Valid: true},
+				ID:             attempt.ID,
+				Attempts:       attempt.Attempts,
+			})
+		},
+		Requeue: func(ctx context.Context, maxAttempts int32, staleBefore time.Time) ([]queue.Stale, error) {
+			deliveries, err := s.queries.RequeueStale(ctx, RequeueStaleParams{
+				MaxAttempts: maxAttempts,
+				StaleBefore: pgtype.Timestamptz{Time: staleBefore, Valid: true},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			stale := make([]queue.Stale, 0, len(deliveries))
+			for _, delivery := range deliveries {
+				stale = append(stale, queue.Stale{ID: delivery.ID, Failed: delivery.Status == WebhookDeliveryStatusFailed})
+			}
+			return stale, nil
+		},
+	}
+}
+
+// deliver POSTs a delivery to its endpoint and returns the HTTP status of the response, or 0 if there
+// was none. Any status other than 2xx is an error.
+func (s *Service) deliver(ctx context.Context, delivery ClaimRow) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "core-system-webhook")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodySize))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// prepareCreate checks the endpoint and events of a new webhook and generates its secret
+func (s *Service) prepareCreate(ctx context.Context, endpoint string, events []string) ([]string, string, error) {
+	err := s.validateURL(ctx, endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+
+	events, err = ParseEvents(events)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	return events, secret, nil
+}
+
+// validateURL checks that a webhook endpoint is an absolute http or https URL whose host resolves
+// to public addresses only
+func (s *Service) validateURL(ctx context.Context, endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %s", internal.ErrInvalidWebhookURL, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: %q", internal.ErrInvalidWebhookURL, endpoint)
+	}
+	if s.allowPrivate {
+		return nil
+	}
+	return checkHost(ctx, parsed.Hostname())
+}
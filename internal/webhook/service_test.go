@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// deliveryQuerier hands out one claimed delivery and records how its attempt ended
+type deliveryQuerier struct {
+	Querier
+	claimed ClaimRow
+
+	succeeded *SucceedParams
+	retried   *RetryParams
+	failed    *FailParams
+}
+
+func (q *deliveryQuerier) Claim(_ context.Context) (ClaimRow, error) {
+	return q.claimed, nil
+}
+
+func (q *deliveryQuerier) Succeed(_ context.Context, arg SucceedParams) (int64, error) {
+	q.succeeded = &arg
+	return 1, nil
+}
+
+func (q *deliveryQuerier) Retry(_ context.Context, arg RetryParams) (int64, error) {
+	q.retried = &arg
+	return 1, nil
+}
+
+func (q *deliveryQuerier) Fail(_ context.Context, arg FailParams) (int64, error) {
+	q.failed = &arg
+	return 1, nil
+}
+
+func TestService_ProcessNext(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+
+	testCases := []struct {
+		name     string
+		status   int
+		attempts int32
+		active   bool
+		validate func(t *testing.T, q *deliveryQuerier, got *received)
+	}{
+		{
+			name:     "Signed delivery accepted by the endpoint",
+			status:   http.StatusNoContent,
+			attempts: 1,
+			active:   true,
+			validate: func(t *testing.T, q *deliveryQuerier, got *received) {
+				require.NotNil(t, q.succeeded)
+				require.Equal(t, int32(http.StatusNoContent), q.succeeded.ResponseStatus.Int32)
+				require.Equal(t, q.claimed.Attempts, q.succeeded.Attempts)
+
+				require.NotNil(t, got)
+				require.Equal(t, string(EventResponseSubmitted), got.header.Get(HeaderEvent))
+				require.Equal(t, q.claimed.ID.String(), got.header.Get(HeaderDelivery))
+				timestamp, err := strconv.ParseInt(got.header.Get(HeaderTimestamp), 10, 64)
+				require.NoError(t, err)
+				require.True(t, Verify("secret", timestamp, got.body, got.header.Get(HeaderSignature)))
+				require.Equal(t, q.claimed.Payload, got.body)
+			},
+		},
+		{
+			name:     "Failed attempt is retried after backoff",
+			status:   http.StatusInternalServerError,
+			attempts: 2,
+			active:   true,
+			validate: func(t *testing.T, q *deliveryQuerier, _ *received) {
+				require.Nil(t, q.succeeded)
+				require.NotNil(t, q.retried)
+				require.Equal(t, int32(http.StatusInternalServerError), q.retried.ResponseStatus.Int32)
+				require.Contains(t, q.retried.Error.String, "500")
+				require.WithinDuration(t, time.Now().Add(policy.Backoff(2)), q.retried.NextAttemptAt.Time, 5*time.Second)
+				require.Equal(t, int32(2), q.retried.Attempts)
+			},
+		},
+		{
+			name:     "Last failed attempt fails the delivery",
+			status:   http.StatusBadGateway,
+			attempts: policy.MaxAttempts,
+			active:   true,
+			validate: func(t *testing.T, q *deliveryQuerier, _ *received) {
+				require.Nil(t, q.retried)
+				require.NotNil(t, q.failed)
+				require.Equal(t, int32(http.StatusBadGateway), q.failed.ResponseStatus.Int32)
+				require.Equal(t, policy.MaxAttempts, q.failed.Attempts)
+			},
+		},
+		{
+			name:     "Delivery of a disabled webhook is failed without being sent",
+			status:   http.StatusOK,
+			attempts: 1,
+			active:   false,
+			validate: func(t *testing.T, q *deliveryQuerier, got *received) {
+				require.Nil(t, got)
+				require.NotNil(t, q.failed)
+				require.Equal(t, "webhook is disabled", q.failed.Error.String)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got *received
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				got = &received{header: r.Header.Clone(), body: body}
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			q := &deliveryQuerier{claimed: ClaimRow{
+				ID:        uuid.New(),
+				WebhookID: uuid.New(),
+				Event:     string(EventResponseSubmitted),
+				Payload:   []byte(`{"event":"response.submitted"}`),
+				Attempts:  tc.attempts,
+				Url:       server.URL,
+				Secret:    "secret",
+				Active:    tc.active,
+			}}
+			service := NewService(zap.NewNop(), nil, true)
+			service.queries = q
+
+			require.True(t, service.ProcessNext(context.Background()))
+			tc.validate(t, q, got)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		attempts int32
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 4, expected: 4 * time.Minute},
+		{attempts: 7, expected: 32 * time.Minute},
+		{attempts: 8, expected: time.Hour},
+		{attempts: 30, expected: time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(int(tc.attempts)), func(t *testing.T) {
+			require.Equal(t, tc.expected, policy.Backoff(tc.attempts))
+		})
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	testCases := []struct {
+		name        string
+		events      []string
+		expected    []string
+		expectedErr error
+	}{
+		{name: "Known events", events: []string{"form.published", "response.submitted"}, expected: []string{"form.published", "response.submitted"}},
+		{name: "Duplicates are dropped", events: []string{"form.closed", "form.closed"}, expected: []string{"form.closed"}},
+		{name: "Unknown event", events: []string{"form.deleted"}, expectedErr: internal.ErrInvalidWebhookEvent},
+		{name: "No event", events: []string{}, expectedErr: internal.ErrInvalidWebhookEvent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := ParseEvents(tc.events)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, events)
+		})
+	}
+}
+
+func TestService_ValidateURL(t *testing.T) {
+	testCases := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		expectedErr  error
+	}{
+		{name: "Public address", url: "https://93.184.215.14/hooks/forms"},
+		{name: "Public IPv6 address", url: "https://[2606:4700:4700::1111]/hooks/forms"},
+		{name: "Loopback host name", url: "http://localhost:8080/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Loopback address", url: "http://127.0.0.1:8080/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "IPv6 loopback address", url: "http://[::1]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Private address", url: "http://10.0.0.5/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "IPv4-mapped private address", url: "http://[::ffff:192.168.1.1]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Link-local metadata address", url: "http://169.254.169.254/latest/meta-data", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Unspecified address", url: "http://0.0.0.0/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "This network address", url: "http://0.1.2.3/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Carrier-grade NAT address", url: "http://100.64.0.1/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Benchmarking address", url: "http://198.18.0.1/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Broadcast address", url: "http://255.255.255.255/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "Multicast address", url: "http://239.255.255.250/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "IPv6 global multicast address", url: "http://[ff0e::1]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "IPv6 link-local address with a zone", url: "http://[fe80::1%25eth0]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "IPv6 unique local address", url: "http://[fd00::1]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "NAT64 address of a private address", url: "http://[64:ff9b::a00:1]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "NAT64 address of a public address", url: "http://[64:ff9b::5db8:d70e]/hooks/forms"},
+		{name: "6to4 address of a loopback address", url: "http://[2002:7f00:1::1]/webhook", expectedErr: internal.ErrWebhookAddressNotAllowed},
+		{name: "6to4 address of a public address", url: "http://[2002:5db8:d70e::1]/hooks/forms"},
+		{name: "Private address allowed for development", url: "http://localhost:8080/webhook", allowPrivate: true},
+		{name: "Other scheme", url: "ftp://example.com/webhook", expectedErr: internal.ErrInvalidWebhookURL},
+		{name: "Relative path", url: "/relative/path", expectedErr: internal.ErrInvalidWebhookURL},
+		{name: "Missing host", url: "https://", expectedErr: internal.ErrInvalidWebhookURL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := NewService(zap.NewNop(), nil, tc.allowPrivate)
+
+			err := service.validateURL(context.Background(), tc.url)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_ProcessNext_PrivateAddress(t *testing.T) {
+	received := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The endpoint was public when it was registered, but now resolves to the loopback address
+	q := &deliveryQuerier{claimed: ClaimRow{
+		ID:        uuid.New(),
+		WebhookID: uuid.New(),
+		Event:     string(EventResponseSubmitted),
+		Payload:   []byte(`{"event":"response.submitted"}`),
+		Attempts:  1,
+		Url:       server.URL,
+		Secret:    "secret",
+		Active:    true,
+	}}
+	service := NewService(zap.NewNop(), nil, false)
+	service.queries = q
+
+	require.True(t, service.ProcessNext(context.Background()))
+	require.False(t, received)
+	require.NotNil(t, q.retried)
+	require.False(t, q.retried.ResponseStatus.Valid)
+	require.Contains(t, q.retried.Error.String, internal.ErrWebhookAddressNotAllowed.Error())
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"form.published"}`)
+	signature := Sign("secret", 1700000000, body)
+	require.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+
+	require.True(t, Verify("secret", 1700000000, body, signature))
+	require.False(t, Verify("other secret", 1700000000, body, signature))
+	require.False(t, Verify("secret", 1700000001, body, signature))
+	require.False(t, Verify("secret", 1700000000, []byte(`{"event":"form.closed"}`), signature))
+}
@@ -0,0 +1,63 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileService_CanAccess(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	service := file.NewService(logger, db, blobStore, nil)
+	ctx := context.Background()
+
+	uploader := uuid.New()
+	stranger := uuid.New()
+
+	private, err := service.SaveFile(ctx, strings.NewReader("resume"), "resume.txt", "text/plain", &uploader)
+	require.NoError(t, err)
+	require.False(t, private.IsPublic)
+
+	public, err := service.SaveFile(ctx, strings.NewReader("cover"), "cover.txt", "text/plain", nil, file.WithPublicAccess())
+	require.NoError(t, err)
+	require.True(t, public.IsPublic)
+
+	testCases := []struct {
+		name     string
+		file     file.File
+		userID   uuid.UUID
+		expected bool
+	}{
+		{name: "Uploader", file: private, userID: uploader, expected: true},
+		{name: "Other user without attachments granting access", file: private, userID: stranger, expected: false},
+		{name: "Public file", file: public, userID: stranger, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, err := service.CanAccess(ctx, tc.file, tc.userID)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, allowed)
+		})
+	}
+
+	// Downloads are recorded even without a user
+	require.NoError(t, service.RecordDownload(ctx, file.CreateDownloadParams{
+		FileID: public.ID,
+		Method: file.FileDownloadMethodPublic,
+	}))
+}
@@ -0,0 +1,135 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileService_BlobStore(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		blobStore func() (file.BlobStore, error)
+	}{
+		{
+			name:      "Filesystem",
+			blobStore: resourceManager.SetupBlobStore,
+		},
+		{
+			name: "S3-compatible (MinIO)",
+			blobStore: func() (file.BlobStore, error) {
+				return resourceManager.SetupMinIO()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(t, err)
+			defer rollback()
+
+			blobStore, err := tc.blobStore()
+			require.NoError(t, err)
+
+			service := file.NewService(logger, db, blobStore, nil)
+			ctx := context.Background()
+
+			content := bytes.Repeat([]byte("0123456789"), 1000)
+			savedFile, err := service.SaveFile(ctx, bytes.NewReader(content), "digits.txt", "text/plain", nil)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(content)), savedFile.Size)
+			require.True(t, savedFile.StorageKey.Valid)
+			require.True(t, savedFile.Checksum.Valid)
+			require.Nil(t, savedFile.Data, "the content should not be kept in the database")
+
+			// Read a range, as a download with a Range header would
+			reader, err := service.Open(ctx, savedFile)
+			require.NoError(t, err)
+			_, err = reader.Seek(9995, io.SeekStart)
+			require.NoError(t, err)
+			tail, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, "56789", string(tail))
+			require.NoError(t, reader.Close())
+
+			// Rejected uploads leave nothing behind
+			_, err = service.SaveFile(ctx, bytes.NewReader(content), "digits.txt", "text/plain", nil, file.WithMaxSize(100))
+			require.ErrorIs(t, err, internal.ErrFileTooLarge)
+
+			require.NoError(t, service.Delete(ctx, savedFile.ID))
+			_, err = blobStore.Open(ctx, savedFile.StorageKey.String)
+			require.ErrorIs(t, err, internal.ErrFileNotFound)
+		})
+	}
+}
+
+func TestFileService_MigrateBlobs(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupMinIO()
+	require.NoError(t, err)
+
+	service := file.NewService(logger, db, blobStore, nil)
+	ctx := context.Background()
+
+	// Files created before contents moved to the blob store
+	legacyIDs := make([]uuid.UUID, 0, 3)
+	for i := 0; i < 3; i++ {
+		var id uuid.UUID
+		err := db.QueryRow(ctx,
+			`INSERT INTO files (original_filename, content_type, size, data) VALUES ($1, 'text/plain', 5, $2) RETURNING id`,
+			"legacy.txt", []byte{'a', 'b', 'c', 'd', byte('0' + i)},
+		).Scan(&id)
+		require.NoError(t, err)
+		legacyIDs = append(legacyIDs, id)
+	}
+
+	// Legacy files are served from their row until they are moved
+	legacyFile, err := service.Get(ctx, legacyIDs[0])
+	require.NoError(t, err)
+	require.False(t, legacyFile.StorageKey.Valid)
+	reader, err := service.Open(ctx, legacyFile)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "abcd0", string(data))
+
+	moved, err := service.MigrateBlobs(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, moved)
+
+	for i, id := range legacyIDs {
+		movedFile, err := service.Get(ctx, id)
+		require.NoError(t, err)
+		require.True(t, movedFile.StorageKey.Valid)
+		require.Nil(t, movedFile.Data)
+
+		reader, err := service.Open(ctx, movedFile)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		require.Equal(t, []byte{'a', 'b', 'c', 'd', byte('0' + i)}, data)
+	}
+
+	// Running it again finds nothing left to move
+	moved, err = service.MigrateBlobs(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, 0, moved)
+}
@@ -0,0 +1,88 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubScanner reports content containing "MALWARE" as infected and fails while unavailable is set
+type stubScanner struct {
+	unavailable bool
+}
+
+func (s *stubScanner) Scan(_ context.Context, r io.Reader) (string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if s.unavailable {
+		return "", errors.New("scanner unavailable")
+	}
+	if strings.Contains(string(content), "MALWARE") {
+		return "Test-Signature", nil
+	}
+	return "", nil
+}
+
+func TestFileService_Scan(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	scanner := &stubScanner{}
+	service := file.NewService(logger, db, blobStore, scanner)
+	ctx := context.Background()
+
+	// Clean files can be read
+	clean, err := service.SaveFile(ctx, strings.NewReader("hello world"), "clean.txt", "text/plain", nil)
+	require.NoError(t, err)
+	require.Equal(t, file.FileScanStatusClean, clean.ScanStatus)
+	require.True(t, clean.ScannedAt.Valid)
+
+	reader, err := service.Open(ctx, clean)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	// Infected files are rejected and not kept
+	_, err = service.SaveFile(ctx, strings.NewReader("MALWARE"), "infected.txt", "text/plain", nil)
+	require.ErrorIs(t, err, internal.ErrFileInfected)
+	require.ErrorContains(t, err, "Test-Signature")
+
+	// Files that could not be scanned are kept quarantined
+	scanner.unavailable = true
+	failed, err := service.SaveFile(ctx, bytes.NewReader([]byte("report")), "report.txt", "text/plain", nil)
+	require.NoError(t, err)
+	require.Equal(t, file.FileScanStatusFailed, failed.ScanStatus)
+
+	_, err = service.Open(ctx, failed)
+	require.ErrorIs(t, err, internal.ErrFileQuarantined)
+
+	// and released once a rescan finds them clean
+	scanner.unavailable = false
+	cleared, err := service.RescanQuarantined(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, cleared)
+
+	rescanned, err := service.Get(ctx, failed.ID)
+	require.NoError(t, err)
+	require.Equal(t, file.FileScanStatusClean, rescanned.ScanStatus)
+
+	reader, err = service.Open(ctx, rescanned)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+}
@@ -0,0 +1,106 @@
+package file
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// photoWithLocation returns a 2000x1000 JPEG whose EXIF data says it has to be turned clockwise and
+// holds a GPS location
+func photoWithLocation(t *testing.T) []byte {
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 2000, 1000)), nil))
+	photo := encoded.Bytes()
+
+	payload := append([]byte("Exif\x00\x00"),
+		'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00,
+		0x01, 0x00,
+		0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	)
+	payload = append(payload, "GPS 24.7868N 120.9967E"...)
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+
+	return bytes.Join([][]byte{photo[:2], segment, payload, photo[2:]}, nil)
+}
+
+func TestFileService_ImageVariants(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	service := file.NewService(logger, db, blobStore, nil)
+	ctx := context.Background()
+
+	savedFile, err := service.SaveFile(ctx, bytes.NewReader(photoWithLocation(t)), "photo.jpg", "image/jpeg", nil,
+		file.WithImageFormats(),
+		file.WithImageVariants(),
+	)
+	require.NoError(t, err)
+
+	// The original is stored without its location
+	reader, err := service.Open(ctx, savedFile)
+	require.NoError(t, err)
+	stored, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	require.NotContains(t, string(stored), "GPS")
+	require.Equal(t, int64(len(stored)), savedFile.Size)
+
+	// Variants are turned upright
+	testCases := []struct {
+		size           file.FileVariantSize
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{size: file.FileVariantSizeLarge, expectedWidth: 640, expectedHeight: 1280},
+		{size: file.FileVariantSizeMedium, expectedWidth: 320, expectedHeight: 640},
+		{size: file.FileVariantSizeSmall, expectedWidth: 80, expectedHeight: 160},
+	}
+
+	variants := make([]file.FileVariant, 0, len(testCases))
+	for _, tc := range testCases {
+		variant, err := service.GetVariant(ctx, savedFile.ID, tc.size)
+		require.NoError(t, err)
+		require.Equal(t, "image/jpeg", variant.ContentType)
+		variants = append(variants, variant)
+
+		reader, err := service.OpenVariant(ctx, variant)
+		require.NoError(t, err)
+		config, err := jpeg.DecodeConfig(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		require.Equal(t, tc.expectedWidth, config.Width, string(tc.size))
+		require.Equal(t, tc.expectedHeight, config.Height, string(tc.size))
+	}
+
+	// Content that is not what it claims to be is rejected
+	_, err = service.SaveFile(ctx, bytes.NewReader(stored), "photo.png", "image/png", nil)
+	require.ErrorIs(t, err, internal.ErrContentTypeMismatch)
+
+	// Deleting the file removes its variants
+	require.NoError(t, service.Delete(ctx, savedFile.ID))
+	for _, variant := range variants {
+		_, err = blobStore.Open(ctx, variant.StorageKey)
+		require.ErrorIs(t, err, internal.ErrFileNotFound)
+	}
+	_, err = service.GetVariant(ctx, savedFile.ID, file.FileVariantSizeSmall)
+	require.ErrorIs(t, err, internal.ErrFileVariantNotFound)
+}
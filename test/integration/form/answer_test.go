@@ -0,0 +1,143 @@
+package form
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/file/blobstore"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/answer"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/test/integration"
+	questionbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/question"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
+	"bytes"
+	"context"
+	"io/fs"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadedFile is a file part of a multipart upload
+type uploadedFile struct {
+	filename    string
+	contentType string
+	content     string
+}
+
+// newFileHeaders encodes files as a multipart form and returns their headers as a handler receives them
+func newFileHeaders(t *testing.T, files ...uploadedFile) []*multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, f := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="files"; filename="`+f.filename+`"`)
+		header.Set("Content-Type", f.contentType)
+		part, err := writer.CreatePart(header)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	multipartForm, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = multipartForm.RemoveAll()
+	})
+
+	return multipartForm.File["files"]
+}
+
+// countBlobs returns how many blobs are stored under the root of a filesystem blob store
+func countBlobs(t *testing.T, root string) int {
+	t.Helper()
+
+	count := 0
+	err := filepath.WalkDir(root, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	return count
+}
+
+func TestAnswerService_UploadFiles_Rejected(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	// A blob store of its own, so that it can be checked for leftovers
+	blobRoot := t.TempDir()
+	blobStore, err := blobstore.NewFilesystem(blobRoot)
+	require.NoError(t, err)
+
+	builder := workflowbuilder.New(t, db)
+	data := builder.SetupTestData("upload-org", "upload-unit")
+	workflowJSON, _, sectionID, _ := builder.CreateStartSectionEndWorkflow()
+	builder.CreateSectionRecord(sectionID, data.FormRow.ID, "Uploads")
+	builder.CreateActiveWorkflow(data.FormRow.ID, data.User, workflowJSON)
+
+	metadata, err := question.GenerateUploadFileMetadata(question.UploadFileOption{
+		AllowedFileTypes: []string{"pdf"},
+		MaxFileAmount:    3,
+		MaxFileSizeLimit: 1048576,
+	})
+	require.NoError(t, err)
+	uploadQuestion := questionbuilder.New(t, db).Create(sectionID,
+		questionbuilder.WithType(question.QuestionTypeUploadFile),
+		questionbuilder.WithMetadata(metadata),
+	)
+	formResponse := responsebuilder.New(t, db).Create(data.FormRow.ID, data.User)
+
+	md := markdown.NewService(logger)
+	formService := form.NewService(logger, db, md)
+	questionService := question.NewService(logger, db, formService, md)
+	workflowService := workflow.NewService(logger, db, formService, questionService)
+	fileService := file.NewService(logger, db, blobStore, nil)
+	service := answer.NewService(logger, db, questionService, fileService, workflowService)
+	ctx := context.Background()
+
+	// The first file is stored before the second one is rejected, and is deleted with it
+	files := newFileHeaders(t,
+		uploadedFile{filename: "notes.txt", contentType: "text/plain", content: "hello world"},
+		uploadedFile{filename: "photo.png", contentType: "image/png", content: "not an image"},
+	)
+	_, _, _, err = service.UploadFiles(ctx, data.FormRow.ID, formResponse.ID, uploadQuestion.ID, files, data.User)
+	require.ErrorIs(t, err, internal.ErrContentTypeMismatch)
+	require.Zero(t, countBlobs(t, blobRoot))
+
+	_, err = answer.New(db).GetByResponseIDAndQuestionID(ctx, answer.GetByResponseIDAndQuestionIDParams{
+		ResponseID: formResponse.ID,
+		QuestionID: uploadQuestion.ID,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows, "the rejected upload must not be answered")
+
+	// An upload of valid files keeps them
+	files = newFileHeaders(t, uploadedFile{filename: "notes.txt", contentType: "text/plain", content: "hello world"})
+	entries, _, _, err := service.UploadFiles(ctx, data.FormRow.ID, formResponse.ID, uploadQuestion.ID, files, data.User)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, 1, countBlobs(t, blobRoot))
+
+	_, err = fileService.Get(ctx, entries[0].FileID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,95 @@
+package form
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/test/integration"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	"archive/zip"
+	"bytes"
+	"context"
+	"path"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseService_FileArchive(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	dataset := seedExportDataset(t, db, 2, 2)
+	builder := responsebuilder.New(t, db)
+	builder.CreateAnswerFile(blobStore, dataset.responseIDs[0], dataset.questionIDs[0], dataset.userID, "cv.pdf", []byte("first cv"))
+	builder.CreateAnswerFile(blobStore, dataset.responseIDs[1], dataset.questionIDs[0], dataset.userID, "cv.pdf", []byte("second cv"))
+	builder.CreateAnswerFile(blobStore, dataset.responseIDs[0], dataset.questionIDs[1], dataset.userID, "photo.png", []byte("photo"))
+
+	service := newExportResponseService(logger, db, blobStore)
+
+	testCases := []struct {
+		name          string
+		selection     response.FileSelection
+		expectedFiles []string
+		expectedErr   error
+	}{
+		{
+			name:          "Every file of the form",
+			selection:     response.FileSelection{},
+			expectedFiles: []string{"Question 1/cv.pdf", "Question 2/photo.png", "Question 1/cv (2).pdf"},
+		},
+		{
+			name:          "Files of one question",
+			selection:     response.FileSelection{QuestionIDs: []uuid.UUID{dataset.questionIDs[1]}},
+			expectedFiles: []string{"Question 2/photo.png"},
+		},
+		{
+			name:          "Files of some responses",
+			selection:     response.FileSelection{ResponseIDs: []uuid.UUID{dataset.responseIDs[1]}},
+			expectedFiles: []string{"Question 1/cv.pdf"},
+		},
+		{
+			name:          "No response selected",
+			selection:     response.FileSelection{ResponseIDs: []uuid.UUID{}},
+			expectedFiles: []string{},
+		},
+		{
+			name:        "Question outside the form",
+			selection:   response.FileSelection{QuestionIDs: []uuid.UUID{uuid.New()}},
+			expectedErr: internal.ErrQuestionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			archive, err := service.PrepareFileArchive(context.Background(), dataset.formID, tc.selection)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, service.WriteFileArchive(context.Background(), archive, &buf))
+
+			reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+			require.Len(t, reader.File, len(tc.expectedFiles)+1)
+
+			files := make([]string, 0, len(reader.File)-1)
+			for _, entry := range reader.File[1:] {
+				// Entries are below the directory of the submitter
+				dir, name := path.Split(entry.Name)
+				files = append(files, path.Join(path.Base(dir), name))
+			}
+			require.ElementsMatch(t, tc.expectedFiles, files)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package form
+
+import (
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	questionbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/question"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	benchmarkExportResponses = 30000
+	benchmarkExportQuestions = 10
+	// maxExportHeapGrowth bounds the heap an export may grow by while it is written. Holding the benchmark
+	// dataset in memory takes several times this, so an export that stays below it does not grow with the
+	// number of responses.
+	maxExportHeapGrowth = 64 << 20
+)
+
+type exportDataset struct {
+	formID      uuid.UUID
+	userID      uuid.UUID
+	questionIDs []uuid.UUID
+	responseIDs []uuid.UUID
+}
+
+func newExportResponseService(logger *zap.Logger, db dbbuilder.DBTX, blobStore file.BlobStore) *response.Service {
+	md := markdown.NewService(logger)
+	formService := form.NewService(logger, db, md)
+	questionService := question.NewService(logger, db, formService, md)
+	workflowService := workflow.NewService(logger, db, formService, questionService)
+
+	return response.NewService(logger, db, nil, questionService, workflowService, formService, nil, file.NewService(logger, db, blobStore, nil), outbox.NewService(logger, db))
+}
+
+// seedExportDataset creates a form with one section of short text questions and the given number of
+// submitted responses, each answering every question
+func seedExportDataset(tb testing.TB, db dbbuilder.DBTX, responses, questions int) exportDataset {
+	tb.Helper()
+
+	builder := workflowbuilder.New(tb, db)
+	data := builder.SetupTestData("export-org", "export-unit")
+
+	workflowJSON, _, sectionID, _ := builder.CreateStartSectionEndWorkflow()
+	builder.CreateSectionRecord(sectionID, data.FormRow.ID, "Answers")
+	builder.CreateActiveWorkflow(data.FormRow.ID, data.User, workflowJSON)
+
+	questionBuilder := questionbuilder.New(tb, db)
+	questionIDs := make([]uuid.UUID, 0, questions)
+	for i := 0; i < questions; i++ {
+		questionRow := questionBuilder.Create(sectionID,
+			questionbuilder.WithTitle(fmt.Sprintf("Question %d", i+1)),
+			questionbuilder.WithOrder(int32(i+1)),
+		)
+		questionIDs = append(questionIDs, questionRow.ID)
+	}
+
+	responseBuilder := responsebuilder.New(tb, db)
+	responseIDs := responseBuilder.CreateSubmittedBulk(data.FormRow.ID, data.User, responses)
+	for i, questionID := range questionIDs {
+		value := fmt.Sprintf(`{"value":"answer %d %s"}`, i+1, strings.Repeat("x", 100))
+		responseBuilder.CreateAnswers(responseIDs, questionID, []byte(value))
+	}
+
+	return exportDataset{
+		formID:      data.FormRow.ID,
+		userID:      data.User,
+		questionIDs: questionIDs,
+		responseIDs: responseIDs,
+	}
+}
+
+func TestResponseService_WriteExport(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	dataset := seedExportDataset(t, db, 3, 2)
+
+	// Drafts are left out of exports unless the filter selects them
+	draft := responsebuilder.New(t, db).Create(dataset.formID, dataset.userID)
+	responsebuilder.New(t, db).CreateAnswers([]uuid.UUID{draft.ID}, dataset.questionIDs[0], []byte(`{"value":"draft"}`))
+
+	service := newExportResponseService(logger, db, blobStore)
+	plan, err := service.PrepareExport(context.Background(), dataset.formID, dataset.questionIDs, response.ListFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []response.ExportHeader{
+		{ID: dataset.questionIDs[0].String(), Title: "Question 1"},
+		{ID: dataset.questionIDs[1].String(), Title: "Question 2"},
+	}, plan.Headers)
+
+	var buf bytes.Buffer
+	err = service.WriteExport(context.Background(), plan, response.ExportFormatCSV, &buf)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\xEF\xBB\xBF"))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	exportedIDs := make([]string, 0, len(records)-1)
+	for i, record := range records[1:] {
+		exportedIDs = append(exportedIDs, record[0])
+		require.True(t, strings.HasPrefix(record[5], "answer 1 "))
+		require.True(t, strings.HasPrefix(record[6], "answer 2 "))
+
+		// Responses are exported oldest first
+		if i > 0 {
+			require.LessOrEqual(t, records[i][3], record[3])
+		}
+	}
+
+	expectedIDs := make([]string, 0, len(dataset.responseIDs))
+	for _, id := range dataset.responseIDs {
+		expectedIDs = append(expectedIDs, id.String())
+	}
+	require.ElementsMatch(t, expectedIDs, exportedIDs)
+}
+
+func TestResponseService_ExportPreview_Progress(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	dataset := seedExportDataset(t, db, 2, 1)
+	draft := responsebuilder.New(t, db).Create(dataset.formID, dataset.userID)
+	responsebuilder.New(t, db).CreateAnswers([]uuid.UUID{draft.ID}, dataset.questionIDs[0], []byte(`{"value":"draft"}`))
+
+	draftProgress := response.ResponseProgressDraft
+	submittedProgress := response.ResponseProgressSubmitted
+
+	testCases := []struct {
+		name        string
+		filter      response.ListFilter
+		expectedIDs []uuid.UUID
+	}{
+		{
+			name:        "Default export only has submitted responses",
+			filter:      response.ListFilter{},
+			expectedIDs: dataset.responseIDs,
+		},
+		{
+			name:        "Submitted responses",
+			filter:      response.ListFilter{Progress: &submittedProgress},
+			expectedIDs: dataset.responseIDs,
+		},
+		{
+			name:        "Drafts when asked for",
+			filter:      response.ListFilter{Progress: &draftProgress},
+			expectedIDs: []uuid.UUID{draft.ID},
+		},
+	}
+
+	service := newExportResponseService(logger, db, blobStore)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preview, err := service.ExportPreview(context.Background(), dataset.formID, dataset.questionIDs, tc.filter)
+			require.NoError(t, err)
+
+			exportedIDs := make([]string, 0, len(preview.Rows))
+			for _, row := range preview.Rows {
+				exportedIDs = append(exportedIDs, row.ID)
+			}
+			expectedIDs := make([]string, 0, len(tc.expectedIDs))
+			for _, id := range tc.expectedIDs {
+				expectedIDs = append(expectedIDs, id.String())
+			}
+			require.ElementsMatch(t, expectedIDs, exportedIDs)
+		})
+	}
+}
+
+// heapSampler records the peak heap allocation while an export is written
+type heapSampler struct {
+	baseline uint64
+	peak     uint64
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func startHeapSampler() *heapSampler {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	s := &heapSampler{baseline: stats.HeapAlloc, peak: stats.HeapAlloc, stop: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				var stats runtime.MemStats
+				runtime.ReadMemStats(&stats)
+				s.peak = max(s.peak, stats.HeapAlloc)
+			}
+		}
+	}()
+	return s
+}
+
+// Stop returns how far the heap grew above its size when sampling started
+func (s *heapSampler) Stop() uint64 {
+	close(s.stop)
+	s.wg.Wait()
+	if s.peak < s.baseline {
+		return 0
+	}
+	return s.peak - s.baseline
+}
+
+func BenchmarkResponseService_WriteExport(b *testing.B) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(b, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(b, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(b, err)
+
+	dataset := seedExportDataset(b, db, benchmarkExportResponses, benchmarkExportQuestions)
+	service := newExportResponseService(logger, db, blobStore)
+
+	for _, format := range []response.ExportFormat{response.ExportFormatCSV, response.ExportFormatJSONLines, response.ExportFormatXLSX} {
+		b.Run(string(format), func(b *testing.B) {
+			b.ReportAllocs()
+
+			var peakGrowth uint64
+			for i := 0; i < b.N; i++ {
+				plan, err := service.PrepareExport(context.Background(), dataset.formID, dataset.questionIDs, response.ListFilter{})
+				require.NoError(b, err)
+
+				sampler := startHeapSampler()
+				err = service.WriteExport(context.Background(), plan, format, io.Discard)
+				growth := sampler.Stop()
+				require.NoError(b, err)
+
+				peakGrowth = max(peakGrowth, growth)
+			}
+
+			b.ReportMetric(float64(peakGrowth), "peak-heap-B")
+			if peakGrowth > maxExportHeapGrowth {
+				b.Fatalf("%s export of %d responses grew the heap by %d bytes, more than %d", format, benchmarkExportResponses, peakGrowth, maxExportHeapGrowth)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package form
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/exportjob"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newExportJobService(logger *zap.Logger, db dbbuilder.DBTX, blobStore file.BlobStore) *exportjob.Service {
+	formService := form.NewService(logger, db, markdown.NewService(logger))
+
+	return exportjob.NewService(logger, db, 1, newExportResponseService(logger, db, blobStore), file.NewService(logger, db, blobStore, nil), formService, inbox.NewService(logger, db))
+}
+
+func TestExportJobService_ProcessNext(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	dataset := seedExportDataset(t, db, 3, 2)
+	service := newExportJobService(logger, db, blobStore)
+
+	job, err := service.Create(context.Background(), dataset.formID, dataset.userID, dataset.questionIDs, response.ListFilter{}, response.ExportFormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, exportjob.ExportJobStatusPending, job.Status)
+
+	require.True(t, service.ProcessNext(context.Background()))
+	require.False(t, service.ProcessNext(context.Background()), "the queue should be empty once the job is built")
+
+	job, err = service.GetByFormID(context.Background(), dataset.formID, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, exportjob.ExportJobStatusCompleted, job.Status)
+	require.Equal(t, int32(3), job.ProcessedCount)
+	require.Equal(t, int32(3), job.TotalCount)
+	require.True(t, job.FileID.Valid)
+	require.True(t, job.CompletedAt.Valid)
+
+	fileService := file.NewService(logger, db, blobStore, nil)
+	savedFile, err := fileService.Get(context.Background(), job.FileID.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, response.ExportFormatCSV.ContentType(), savedFile.ContentType)
+
+	content, err := fileService.Open(context.Background(), savedFile)
+	require.NoError(t, err)
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "answer 1 ")
+
+	messages := inboxbuilder.New(t, db).GetUserInboxMessages(dataset.userID)
+	require.Len(t, messages, 1)
+	require.Equal(t, inbox.ContentTypeExport, messages[0].Type)
+	require.Equal(t, job.ID, messages[0].ContentID)
+}
+
+func TestExportJobService_Create(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	dataset := seedExportDataset(t, db, 1, 1)
+	service := newExportJobService(logger, db, blobStore)
+
+	// Questions outside the form are rejected before a job is queued
+	_, err = service.Create(context.Background(), dataset.formID, dataset.userID, []uuid.UUID{uuid.New()}, response.ListFilter{}, response.ExportFormatCSV)
+	require.ErrorIs(t, err, internal.ErrQuestionNotFound)
+	require.False(t, service.ProcessNext(context.Background()))
+}
+
+func TestExportJobService_RequeueStale(t *testing.T) {
+	testCases := []struct {
+		name           string
+		attempts       int32
+		expectedStatus exportjob.ExportJobStatus
+	}{
+		{
+			name:           "Requeue a running job whose worker stopped",
+			attempts:       1,
+			expectedStatus: exportjob.ExportJobStatusPending,
+		},
+		{
+			name:           "Fail a running job that was interrupted too many times",
+			attempts:       3,
+			expectedStatus: exportjob.ExportJobStatusFailed,
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(t, err)
+			defer rollback()
+
+			blobStore, err := resourceManager.SetupBlobStore()
+			require.NoError(t, err)
+
+			dataset := seedExportDataset(t, db, 1, 1)
+			service := newExportJobService(logger, db, blobStore)
+
+			job, err := service.Create(context.Background(), dataset.formID, dataset.userID, dataset.questionIDs, response.ListFilter{}, response.ExportFormatCSV)
+			require.NoError(t, err)
+
+			// Leave the job as a crashed worker would: running, without a recent heartbeat
+			_, err = db.Exec(context.Background(),
+				`UPDATE response_export_jobs SET status = 'running', attempts = $2, updated_at = now() - interval '1 hour' WHERE id = $1`,
+				job.ID, tc.attempts,
+			)
+			require.NoError(t, err)
+
+			require.Equal(t, 1, service.RequeueStale(context.Background()))
+
+			job, err = service.GetByFormID(context.Background(), dataset.formID, job.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStatus, job.Status)
+		})
+	}
+}
+
+func TestExportJobQueries_StaleWorker(t *testing.T) {
+	testCases := []struct {
+		name string
+		end  func(queries *exportjob.Queries, job exportjob.ResponseExportJob) error
+	}{
+		{
+			name: "Complete",
+			end: func(queries *exportjob.Queries, job exportjob.ResponseExportJob) error {
+				_, err := queries.Complete(context.Background(), exportjob.CompleteParams{ID: job.ID, Attempts: job.Attempts})
+				return err
+			},
+		},
+		{
+			name: "Fail",
+			end: func(queries *exportjob.Queries, job exportjob.ResponseExportJob) error {
+				_, err := queries.Fail(context.Background(), exportjob.FailParams{ID: job.ID, Attempts: job.Attempts})
+				return err
+			},
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(t, err)
+			defer rollback()
+
+			blobStore, err := resourceManager.SetupBlobStore()
+			require.NoError(t, err)
+
+			dataset := seedExportDataset(t, db, 1, 1)
+			service := newExportJobService(logger, db, blobStore)
+			queries := exportjob.New(db)
+
+			_, err = service.Create(context.Background(), dataset.formID, dataset.userID, dataset.questionIDs, response.ListFilter{}, response.ExportFormatCSV)
+			require.NoError(t, err)
+			stale, err := queries.Claim(context.Background())
+			require.NoError(t, err)
+
+			// The first worker stops sending heartbeats, and the job is requeued and claimed by another
+			_, err = db.Exec(context.Background(), `UPDATE response_export_jobs SET updated_at = now() - interval '1 hour' WHERE id = $1`, stale.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, service.RequeueStale(context.Background()))
+			current, err := queries.Claim(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, stale.Attempts+1, current.Attempts)
+
+			// The first worker cannot end the attempt of the second
+			err = tc.end(queries, stale)
+			require.ErrorIs(t, err, pgx.ErrNoRows)
+
+			job, err := service.GetByFormID(context.Background(), dataset.formID, current.ID)
+			require.NoError(t, err)
+			require.Equal(t, exportjob.ExportJobStatusRunning, job.Status)
+
+			require.NoError(t, tc.end(queries, current))
+		})
+	}
+}
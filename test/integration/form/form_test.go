@@ -2,18 +2,23 @@ package form
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
 	"NYCU-SDC/core-system-backend/internal/form"
 	"NYCU-SDC/core-system-backend/internal/form/response"
 	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/outbox"
 	"NYCU-SDC/core-system-backend/test/integration"
 	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
 	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
 	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
 	"context"
+	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
@@ -129,7 +134,7 @@ func TestResponseService_CancelSubmission(t *testing.T) {
 			}
 
 			formService := form.NewService(logger, db, markdown.NewService(logger))
-			responseService := response.NewService(logger, db, nil, nil, nil, formService, nil)
+			responseService := response.NewService(logger, db, nil, nil, nil, formService, nil, nil, outbox.NewService(logger, db))
 			queries := response.New(db)
 
 			tc.setup(t, &params, db, responseService, queries)
@@ -309,3 +314,58 @@ func TestFormService_ListExcludeExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestFormService_CoverImage(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fileService := file.NewService(logger, db, blobStore, nil)
+	formService := form.NewService(logger, db, markdown.NewService(logger))
+
+	user := userbuilder.New(t, db).Create()
+	created := formbuilder.New(t, db).Create(formbuilder.WithLastEditor(user.ID))
+
+	// A form without a cover has no cover file
+	_, err = formService.GetCoverFileID(ctx, created.ID)
+	require.ErrorIs(t, err, handlerutil.ErrNotFound)
+
+	first, err := fileService.SaveFile(ctx, strings.NewReader("first cover"), "first.webp", "image/webp", nil, file.WithPublicAccess())
+	require.NoError(t, err)
+	second, err := fileService.SaveFile(ctx, strings.NewReader("second cover"), "second.webp", "image/webp", nil, file.WithPublicAccess())
+	require.NoError(t, err)
+
+	require.NoError(t, formService.UploadCoverImage(ctx, created.ID, first.ID, "/api/files/"+first.ID.String()))
+	require.NoError(t, formService.UploadCoverImage(ctx, created.ID, second.ID, "/api/files/"+second.ID.String()))
+
+	// The cover is the last uploaded file, whose contents are served by the file service
+	fileID, err := formService.GetCoverFileID(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, fileID)
+
+	saved, err := formService.Get(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "/api/files/"+second.ID.String(), saved.CoverImageUrl.String)
+
+	cover, err := fileService.Get(ctx, fileID)
+	require.NoError(t, err)
+	content, err := fileService.Open(ctx, cover)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, content.Close())
+	}()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.Equal(t, "second cover", string(data))
+
+	// Covering a missing form is not found
+	err = formService.UploadCoverImage(ctx, uuid.New(), second.ID, "/api/files/"+second.ID.String())
+	require.ErrorIs(t, err, handlerutil.ErrNotFound)
+}
@@ -0,0 +1,263 @@
+package inbox
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pagutil "github.com/NYCU-SDC/summer/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newInboxMux registers the inbox handlers with the same patterns as cmd/backend/main.go.
+// The authentication middleware is replaced by injecting the user into the request context.
+func newInboxMux(logger *zap.Logger, db dbbuilder.DBTX) *http.ServeMux {
+	formService := form.NewService(logger, db, markdown.NewService(logger))
+	unitService := unit.NewService(logger, db, tenant.NewService(logger, db))
+	handler := inbox.NewHandler(logger, internal.NewValidator(), internal.NewProblemWriter(), inbox.NewService(logger, db), formService, unitService)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/inbox", handler.ListHandler)
+	mux.HandleFunc("GET /api/inbox/count", handler.CountHandler)
+	mux.HandleFunc("GET /api/inbox/{id}", handler.GetHandler)
+	mux.HandleFunc("PATCH /api/inbox/{id}", handler.UpdateHandler)
+
+	return mux
+}
+
+// inboxFixture holds one user with three inbox messages: one unread, one read and starred, and one archived.
+type inboxFixture struct {
+	userID   uuid.UUID
+	unread   uuid.UUID
+	starred  uuid.UUID
+	archived uuid.UUID
+	formID   uuid.UUID
+}
+
+func setupInboxFixture(t *testing.T, db dbbuilder.DBTX) inboxFixture {
+	unitBuilder := unitbuilder.New(t, db)
+	userBuilder := userbuilder.New(t, db)
+	formBuilder := formbuilder.New(t, db)
+	inboxBuilder := inboxbuilder.New(t, db)
+
+	org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("handler-org"))
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("handler-unit"))
+	member := userBuilder.Create()
+
+	form1 := formBuilder.Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(member.ID), formbuilder.WithTitle("Club Registration"))
+	form2 := formBuilder.Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(member.ID), formbuilder.WithTitle("Workshop Feedback"))
+	form3 := formBuilder.Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(member.ID), formbuilder.WithTitle("Old Survey"))
+
+	unread := inboxBuilder.CreateUserInboxMessage(member.ID, inboxBuilder.CreateMessage(inbox.ContentTypeForm, form1.ID, unitRow.ID).ID)
+	starred := inboxBuilder.CreateUserInboxMessage(member.ID, inboxBuilder.CreateMessage(inbox.ContentTypeForm, form2.ID, unitRow.ID).ID)
+	archived := inboxBuilder.CreateUserInboxMessage(member.ID, inboxBuilder.CreateMessage(inbox.ContentTypeForm, form3.ID, unitRow.ID).ID)
+
+	inboxBuilder.UpdateUserInboxMessage(starred.ID, member.ID, inbox.UserInboxMessageFilter{IsRead: true, IsStarred: true})
+	inboxBuilder.UpdateUserInboxMessage(archived.ID, member.ID, inbox.UserInboxMessageFilter{IsArchived: true})
+
+	return inboxFixture{
+		userID:   member.ID,
+		unread:   unread.ID,
+		starred:  starred.ID,
+		archived: archived.ID,
+		formID:   form1.ID,
+	}
+}
+
+func TestInboxHandler_Routes(t *testing.T) {
+	type Params struct {
+		method string
+		path   string
+		body   string
+		userID uuid.UUID
+	}
+	testCases := []struct {
+		name           string
+		params         Params
+		setup          func(t *testing.T, params *Params, fixture inboxFixture)
+		validate       func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte)
+		expectedStatus int
+	}{
+		{
+			name:   "List returns non-archived messages with pagination metadata",
+			params: Params{method: http.MethodGet, path: "/api/inbox?page=0&size=1"},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response pagutil.Response[inbox.Response]
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Len(t, response.Items, 1)
+				require.Equal(t, 2, response.TotalItems)
+				require.Equal(t, 2, response.TotalPages)
+				require.True(t, response.HasNextPage)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "List applies the starred filter",
+			params: Params{method: http.MethodGet, path: "/api/inbox?isStarred=true"},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response pagutil.Response[inbox.Response]
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Len(t, response.Items, 1)
+				require.Equal(t, fixture.starred.String(), response.Items[0].ID)
+				require.True(t, response.Items[0].IsStarred)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "List applies the search filter",
+			params: Params{method: http.MethodGet, path: "/api/inbox?search=Registration"},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response pagutil.Response[inbox.Response]
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Len(t, response.Items, 1)
+				require.Equal(t, fixture.unread.String(), response.Items[0].ID)
+				require.Equal(t, "Club Registration", response.Items[0].Message.Title)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "List rejects an invalid filter value",
+			params:         Params{method: http.MethodGet, path: "/api/inbox?isRead=maybe"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Count returns the number of unread messages",
+			params: Params{method: http.MethodGet, path: "/api/inbox/count?isRead=false"},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response inbox.CountResponse
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Equal(t, int64(1), response.Count)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Count returns zero for a user without messages",
+			params: Params{method: http.MethodGet, path: "/api/inbox/count"},
+			setup: func(t *testing.T, params *Params, fixture inboxFixture) {
+				params.userID = uuid.New()
+			},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response inbox.CountResponse
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Equal(t, int64(0), response.Count)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Get returns the message with its form content",
+			params: Params{method: http.MethodGet},
+			setup: func(t *testing.T, params *Params, fixture inboxFixture) {
+				params.path = "/api/inbox/" + fixture.unread.String()
+			},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response struct {
+					ID      string                    `json:"id"`
+					Message inbox.FormMessageResponse `json:"message"`
+					Content form.Response             `json:"content"`
+				}
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Equal(t, fixture.unread.String(), response.ID)
+				require.Equal(t, fixture.formID.String(), response.Message.ContentID)
+				require.Equal(t, fixture.formID.String(), response.Content.ID)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Get returns not found for a message owned by another user",
+			params: Params{method: http.MethodGet},
+			setup: func(t *testing.T, params *Params, fixture inboxFixture) {
+				params.path = "/api/inbox/" + fixture.unread.String()
+				params.userID = uuid.New()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Get rejects a malformed id",
+			params:         Params{method: http.MethodGet, path: "/api/inbox/not-a-uuid"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Patch updates the read, starred and archived flags",
+			params: Params{method: http.MethodPatch, body: `{"isRead":true,"isStarred":true,"isArchived":false}`},
+			setup: func(t *testing.T, params *Params, fixture inboxFixture) {
+				params.path = "/api/inbox/" + fixture.unread.String()
+			},
+			validate: func(t *testing.T, fixture inboxFixture, db dbbuilder.DBTX, body []byte) {
+				var response inbox.Response
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.True(t, response.IsRead)
+				require.True(t, response.IsStarred)
+				require.False(t, response.IsArchived)
+
+				stored := inboxbuilder.New(t, db).GetUserInboxMessage(fixture.unread, fixture.userID)
+				require.True(t, stored.IsRead)
+				require.True(t, stored.IsStarred)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Patch returns not found for an unknown message",
+			params: Params{method: http.MethodPatch, body: `{"isRead":true}`},
+			setup: func(t *testing.T, params *Params, fixture inboxFixture) {
+				params.path = "/api/inbox/" + uuid.New().String()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			if err != nil {
+				t.Fatalf("failed to setup postgres: %v", err)
+			}
+			defer rollback()
+
+			fixture := setupInboxFixture(t, db)
+			params := tc.params
+			params.userID = fixture.userID
+			if tc.setup != nil {
+				tc.setup(t, &params, fixture)
+			}
+
+			req := httptest.NewRequest(params.method, params.path, strings.NewReader(params.body))
+			if params.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			ctx := context.WithValue(req.Context(), internal.UserContextKey, &user.User{ID: params.userID})
+			req = req.WithContext(ctx)
+
+			recorder := httptest.NewRecorder()
+			newInboxMux(logger, db).ServeHTTP(recorder, req)
+
+			require.Equal(t, tc.expectedStatus, recorder.Code, strings.TrimSpace(recorder.Body.String()))
+
+			if tc.validate != nil {
+				tc.validate(t, fixture, db, recorder.Body.Bytes())
+			}
+		})
+	}
+}
@@ -44,6 +44,7 @@ func TestInboxService_Create(t *testing.T) {
 		unitID         uuid.UUID
 		title          string
 		previewMessage string
+		messageID      uuid.UUID
 	}
 	testCases := []struct {
 		name        string
@@ -113,6 +114,54 @@ func TestInboxService_Create(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		{
+			name: "Reuse existing message and skip users who already have it",
+			params: Params{
+				contentType:    inbox.ContentTypeForm,
+				title:          "test-title",
+				previewMessage: "test-preview-message",
+			},
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX, logger any) context.Context {
+				unitBuilder := unitbuilder.New(t, db)
+				userBuilder := userbuilder.New(t, db)
+				formBuilder := formbuilder.New(t, db)
+				inboxBuilder := inboxbuilder.New(t, db)
+
+				org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("reuse-org"))
+				unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("reuse-unit"))
+
+				userA := userBuilder.Create()
+				userB := userBuilder.Create()
+
+				formRow := formBuilder.Create(
+					formbuilder.WithUnitID(unitRow.ID),
+					formbuilder.WithLastEditor(userA.ID),
+					formbuilder.WithTitle(params.title),
+					formbuilder.WithPreviewMessage(params.previewMessage),
+				)
+
+				message := inboxBuilder.CreateMessage(inbox.ContentTypeForm, formRow.ID, unitRow.ID)
+				inboxBuilder.CreateUserInboxMessage(userA.ID, message.ID)
+
+				params.contentID = formRow.ID
+				params.recipients = []uuid.UUID{userA.ID, userB.ID}
+				params.unitID = unitRow.ID
+				params.messageID = message.ID
+
+				return context.Background()
+			},
+			validate: func(t *testing.T, params Params, db dbbuilder.DBTX, result uuid.UUID) {
+				require.Equal(t, params.messageID, result)
+
+				inboxBuilder := inboxbuilder.New(t, db)
+				for _, recipientID := range params.recipients {
+					rows := inboxBuilder.GetUserInboxMessages(recipientID)
+					require.Len(t, rows, 1)
+					require.Equal(t, params.messageID, rows[0].MessageID)
+				}
+			},
+			expectedErr: false,
+		},
 		{
 			name: "Fail when user ID does not exist in recipients for user inbox",
 			params: Params{
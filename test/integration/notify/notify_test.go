@@ -0,0 +1,299 @@
+package notify
+
+import (
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/notify"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/test/integration"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer records the emails it sends and fails those sent to failFor
+type recordingMailer struct {
+	failFor string
+	sent    []notify.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg notify.Message) error {
+	if msg.To == m.failFor {
+		return errors.New("mailbox temporarily unavailable")
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestNotifyService_Enqueue(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	userBuilder := userbuilder.New(t, db)
+	alice := userBuilder.Create(userbuilder.WithName("Alice"))
+	userBuilder.CreateEmail(alice.ID, "alice@example.com")
+	noEmail := userBuilder.Create()
+
+	service := notify.NewService(logger, db, markdown.NewService(logger), &recordingMailer{}, time.UTC, time.Hour)
+	email := func(recipientID uuid.UUID, dedupeKey string) notify.Email {
+		return notify.Email{
+			Kind:        notify.KindDeadlineReminder,
+			RecipientID: recipientID,
+			DedupeKey:   dedupeKey,
+			Vars:        map[string]string{"formTitle": "Club Recruitment", "deadline": "2026-03-01 23:59 UTC"},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		email    notify.Email
+		expected bool
+	}{
+		{name: "Email to a user with an email address is queued", email: email(alice.ID, "reminder:1"), expected: true},
+		{name: "Email with a queued dedupe key is not queued again", email: email(alice.ID, "reminder:1"), expected: false},
+		{name: "Email with another dedupe key is queued", email: email(alice.ID, "reminder:2"), expected: true},
+		{name: "Email to a user without an email address is skipped", email: email(noEmail.ID, "reminder:3"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			queued, err := service.Enqueue(ctx, tc.email)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, queued)
+		})
+	}
+
+	notification, err := notify.New(db).GetByDedupeKey(ctx, "reminder:1")
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", notification.ToAddress)
+	require.Equal(t, "Reminder: Club Recruitment closes on 2026-03-01 23:59 UTC", notification.Subject)
+	require.Contains(t, notification.TextBody, "Hi Alice,")
+	require.Equal(t, notify.NotificationStatusPending, notification.Status)
+}
+
+func TestNotifyService_SubmissionReceiptAndDigest(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	org := unitbuilder.New(t, db).Create(unit.UnitTypeOrganization, unitbuilder.WithName("notify-org"))
+	userBuilder := userbuilder.New(t, db)
+	owner := userBuilder.Create(userbuilder.WithName("Owner"))
+	userBuilder.CreateEmail(owner.ID, "owner@example.com")
+	respondent := userBuilder.Create(userbuilder.WithName("Respondent"))
+	userBuilder.CreateEmail(respondent.ID, "respondent@example.com")
+
+	formRow := formbuilder.New(t, db).Create(
+		formbuilder.WithUnitID(org.ID),
+		formbuilder.WithCreatedBy(owner.ID),
+		formbuilder.WithLastEditor(owner.ID),
+		formbuilder.WithTitle("Club Recruitment"),
+		formbuilder.WithMessageAfterSubmission("See you at the interview!"),
+	)
+
+	responseBuilder := responsebuilder.New(t, db)
+	first := responseBuilder.CreateSubmitted(formRow.ID, respondent.ID)
+	responseBuilder.CreateSubmitted(formRow.ID, respondent.ID)
+
+	mailer := &recordingMailer{}
+	service := notify.NewService(logger, db, markdown.NewService(logger), mailer, time.UTC, time.Hour)
+
+	// The receipt is queued once however often the event is handled
+	event, err := outbox.NewService(logger, db).Publish(ctx, outbox.AggregateResponse, first.ID, outbox.TypeResponseSubmitted,
+		outbox.NewResponsePayload(first.ID, formRow.ID, respondent.ID, first.SubmittedAt))
+	require.NoError(t, err)
+	require.NoError(t, service.HandleResponseSubmitted(ctx, event))
+	require.NoError(t, service.HandleResponseSubmitted(ctx, event))
+
+	receipt, err := notify.New(db).GetByDedupeKey(ctx, "submission_receipt:"+event.ID.String())
+	require.NoError(t, err)
+	require.Equal(t, "respondent@example.com", receipt.ToAddress)
+	require.Equal(t, "Your response to Club Recruitment has been received", receipt.Subject)
+	require.Contains(t, receipt.TextBody, "See you at the interview!")
+
+	// The first digest reports both responses; the next one waits for the digest interval
+	require.Equal(t, 1, service.QueueDigests(ctx))
+	require.Equal(t, 0, service.QueueDigests(ctx))
+
+	// A response submitted after the digest is reported by the next due digest
+	late := responseBuilder.CreateSubmitted(formRow.ID, respondent.ID)
+	_, err = db.Exec(ctx, "UPDATE form_responses SET submitted_at = clock_timestamp() WHERE id = $1", late.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, notify.NewService(logger, db, markdown.NewService(logger), mailer, time.UTC, 0).QueueDigests(ctx))
+
+	rows, err := db.Query(ctx, "SELECT text_body FROM notifications WHERE kind = 'response_digest' AND to_address = 'owner@example.com'")
+	require.NoError(t, err)
+	var digests []string
+	for rows.Next() {
+		var body string
+		require.NoError(t, rows.Scan(&body))
+		digests = append(digests, body)
+	}
+	require.NoError(t, rows.Err())
+	require.Len(t, digests, 2)
+	require.True(t, containsAll(digests, "New responses: 2", "Submitted responses in total: 2"))
+	require.True(t, containsAll(digests, "New responses: 1", "Submitted responses in total: 3"))
+
+	// Every queued email is sent
+	for service.ProcessNext(ctx) {
+	}
+	require.Len(t, mailer.sent, 3)
+}
+
+func TestNotifyService_QueueDigestsConcurrently(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	// Every replica queues digests on a connection of its own, so the data must be committed
+	db, err := resourceManager.SetupPostgresPool()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	org := unitbuilder.New(t, db).Create(unit.UnitTypeOrganization, unitbuilder.WithName("digest-org"))
+	userBuilder := userbuilder.New(t, db)
+	owner := userBuilder.Create(userbuilder.WithName("Owner"))
+	userBuilder.CreateEmail(owner.ID, "digest-owner@example.com")
+	respondent := userBuilder.Create()
+
+	formRow := formbuilder.New(t, db).Create(
+		formbuilder.WithUnitID(org.ID),
+		formbuilder.WithCreatedBy(owner.ID),
+		formbuilder.WithLastEditor(owner.ID),
+	)
+	t.Cleanup(func() {
+		for _, statement := range []string{
+			"DELETE FROM notifications WHERE recipient_id = $1",
+			"DELETE FROM forms WHERE created_by = $1",
+			"DELETE FROM units WHERE id = $2",
+			"DELETE FROM users WHERE id IN ($1, $3)",
+		} {
+			_, err := db.Exec(ctx, statement, owner.ID, org.ID, respondent.ID)
+			require.NoError(t, err)
+		}
+	})
+
+	responseBuilder := responsebuilder.New(t, db)
+	responseBuilder.CreateSubmitted(formRow.ID, respondent.ID)
+
+	// Two replicas list the same due digest; only one of them queues it
+	var (
+		wg     sync.WaitGroup
+		queued [2]int
+	)
+	for i := range queued {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service := notify.NewService(logger, db, markdown.NewService(logger), &recordingMailer{}, time.UTC, time.Hour)
+			queued[i] = service.QueueDigests(ctx)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, queued[0]+queued[1])
+
+	var digests int
+	err = db.QueryRow(ctx, "SELECT COUNT(*) FROM notifications WHERE kind = 'response_digest' AND recipient_id = $1", owner.ID).Scan(&digests)
+	require.NoError(t, err)
+	require.Equal(t, 1, digests)
+
+	// and so do they for the next digest
+	responseBuilder.CreateSubmitted(formRow.ID, respondent.ID)
+	for i := range queued {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service := notify.NewService(logger, db, markdown.NewService(logger), &recordingMailer{}, time.UTC, 0)
+			queued[i] = service.QueueDigests(ctx)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, queued[0]+queued[1])
+
+	err = db.QueryRow(ctx, "SELECT COUNT(*) FROM notifications WHERE kind = 'response_digest' AND recipient_id = $1", owner.ID).Scan(&digests)
+	require.NoError(t, err)
+	require.Equal(t, 2, digests)
+}
+
+func TestNotifyService_ProcessNextRetriesFailedEmail(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	userBuilder := userbuilder.New(t, db)
+	alice := userBuilder.Create()
+	userBuilder.CreateEmail(alice.ID, "alice@example.com")
+
+	mailer := &recordingMailer{failFor: "alice@example.com"}
+	service := notify.NewService(logger, db, markdown.NewService(logger), mailer, time.UTC, time.Hour)
+	queued, err := service.Enqueue(ctx, notify.Email{
+		Kind:        notify.KindDeadlineReminder,
+		RecipientID: alice.ID,
+		DedupeKey:   "reminder:alice",
+		Vars:        map[string]string{"formTitle": "Club Recruitment", "deadline": "2026-03-01 23:59 UTC"},
+	})
+	require.NoError(t, err)
+	require.True(t, queued)
+
+	// The failed attempt is retried later, so the email is not claimed again right away
+	require.True(t, service.ProcessNext(ctx))
+	require.False(t, service.ProcessNext(ctx))
+
+	queries := notify.New(db)
+	retried, err := queries.GetByDedupeKey(ctx, "reminder:alice")
+	require.NoError(t, err)
+	require.Equal(t, notify.NotificationStatusPending, retried.Status)
+	require.Equal(t, int32(1), retried.Attempts)
+	require.Equal(t, "mailbox temporarily unavailable", retried.Error.String)
+	require.True(t, retried.NextAttemptAt.Time.After(retried.CreatedAt.Time))
+
+	// Once the retry is due and the mailbox is back, the email is sent
+	_, err = db.Exec(ctx, "UPDATE notifications SET next_attempt_at = now() WHERE id = $1", retried.ID)
+	require.NoError(t, err)
+	mailer.failFor = ""
+	require.True(t, service.ProcessNext(ctx))
+
+	sent, err := queries.GetByDedupeKey(ctx, "reminder:alice")
+	require.NoError(t, err)
+	require.Equal(t, notify.NotificationStatusSent, sent.Status)
+	require.Equal(t, int32(2), sent.Attempts)
+	require.False(t, sent.Error.Valid)
+	require.True(t, sent.SentAt.Valid)
+	require.Len(t, mailer.sent, 1)
+}
+
+// containsAll reports whether one of bodies contains every one of lines
+func containsAll(bodies []string, lines ...string) bool {
+	for _, body := range bodies {
+		all := true
+		for _, line := range lines {
+			all = all && strings.Contains(body, line)
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
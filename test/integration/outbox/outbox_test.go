@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox_PublishInTransaction(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	service := outbox.NewService(logger, db)
+	queries := outbox.New(db)
+
+	testCases := []struct {
+		name      string
+		commit    bool
+		expectErr error
+	}{
+		{name: "Event of a committed change is kept", commit: true},
+		{name: "Event of a rolled back change is discarded", commit: false, expectErr: pgx.ErrNoRows},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx, err := db.Begin(ctx)
+			require.NoError(t, err)
+
+			event, err := service.WithTx(tx).Publish(ctx, outbox.AggregateResponse, uuid.New(), outbox.TypeResponseSubmitted, outbox.ResponsePayload{ResponseID: uuid.New()})
+			require.NoError(t, err)
+			require.Equal(t, outbox.EventStatusPending, event.Status)
+
+			if tc.commit {
+				require.NoError(t, tx.Commit(ctx))
+			} else {
+				require.NoError(t, tx.Rollback(ctx))
+			}
+
+			_, err = queries.GetByID(ctx, event.ID)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOutbox_RetryKeepsAggregateOrder(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	service := outbox.NewService(logger, db)
+	queries := outbox.New(db)
+
+	first, second := uuid.New(), uuid.New()
+	firstSubmitted, err := service.Publish(ctx, outbox.AggregateResponse, first, outbox.TypeResponseSubmitted, outbox.ResponsePayload{ResponseID: first})
+	require.NoError(t, err)
+	firstCancelled, err := service.Publish(ctx, outbox.AggregateResponse, first, outbox.TypeResponseCancelled, outbox.ResponsePayload{ResponseID: first})
+	require.NoError(t, err)
+	secondSubmitted, err := service.Publish(ctx, outbox.AggregateResponse, second, outbox.TypeResponseSubmitted, outbox.ResponsePayload{ResponseID: second})
+	require.NoError(t, err)
+
+	// The subscriber fails the first time it is handed the first event
+	var handled []uuid.UUID
+	failed := false
+	handler := func(_ context.Context, event outbox.Event) error {
+		handled = append(handled, event.ID)
+		if event.ID == firstSubmitted.ID && !failed {
+			failed = true
+			return errors.New("temporarily unavailable")
+		}
+		return nil
+	}
+	service.Subscribe(outbox.TypeResponseSubmitted, "test", handler)
+	service.Subscribe(outbox.TypeResponseCancelled, "test", handler)
+
+	// The failed event is retried later and holds back the later event of its aggregate, but not the
+	// events of other aggregates
+	require.True(t, service.DispatchNext(ctx))
+	require.True(t, service.DispatchNext(ctx))
+	require.False(t, service.DispatchNext(ctx))
+
+	retried, err := queries.GetByID(ctx, firstSubmitted.ID)
+	require.NoError(t, err)
+	require.Equal(t, outbox.EventStatusPending, retried.Status)
+	require.Equal(t, int32(1), retried.Attempts)
+	require.Equal(t, "test: temporarily unavailable", retried.Error.String)
+	require.True(t, retried.NextAttemptAt.Time.After(retried.CreatedAt.Time))
+
+	// Make the retry due
+	_, err = db.Exec(ctx, "UPDATE events SET next_attempt_at = now() WHERE id = $1", firstSubmitted.ID)
+	require.NoError(t, err)
+
+	for service.DispatchNext(ctx) {
+	}
+
+	require.Equal(t, []uuid.UUID{firstSubmitted.ID, secondSubmitted.ID, firstSubmitted.ID, firstCancelled.ID}, handled)
+
+	for _, id := range []uuid.UUID{firstSubmitted.ID, firstCancelled.ID, secondSubmitted.ID} {
+		event, err := queries.GetByID(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, outbox.EventStatusDispatched, event.Status)
+		require.False(t, event.Error.Valid)
+	}
+}
+
+func TestOutbox_CrashedDispatcher(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	service := outbox.NewService(logger, db)
+	queries := outbox.New(db)
+
+	aggregateID := uuid.New()
+	submitted, err := service.Publish(ctx, outbox.AggregateResponse, aggregateID, outbox.TypeResponseSubmitted, outbox.ResponsePayload{ResponseID: aggregateID})
+	require.NoError(t, err)
+	cancelled, err := service.Publish(ctx, outbox.AggregateResponse, aggregateID, outbox.TypeResponseCancelled, outbox.ResponsePayload{ResponseID: aggregateID})
+	require.NoError(t, err)
+
+	var handled []uuid.UUID
+	handler := func(_ context.Context, event outbox.Event) error {
+		handled = append(handled, event.ID)
+		return nil
+	}
+	service.Subscribe(outbox.TypeResponseSubmitted, "test", handler)
+	service.Subscribe(outbox.TypeResponseCancelled, "test", handler)
+
+	// A dispatcher claims the first event and crashes before finishing it
+	claimed, err := queries.Claim(ctx)
+	require.NoError(t, err)
+	require.Equal(t, submitted.ID, claimed.ID)
+
+	// Neither the claimed event nor the later event of its aggregate can be dispatched meanwhile, and the
+	// claim is not requeued while it is recent
+	require.False(t, service.DispatchNext(ctx))
+	require.Equal(t, 0, service.RequeueStale(ctx))
+
+	_, err = db.Exec(ctx, "UPDATE events SET updated_at = now() - interval '1 hour' WHERE id = $1", submitted.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, service.RequeueStale(ctx))
+
+	for service.DispatchNext(ctx) {
+	}
+	require.Equal(t, []uuid.UUID{submitted.ID, cancelled.ID}, handled)
+
+	redispatched, err := queries.GetByID(ctx, submitted.ID)
+	require.NoError(t, err)
+	require.Equal(t, outbox.EventStatusDispatched, redispatched.Status)
+	require.Equal(t, int32(2), redispatched.Attempts)
+
+	// The crashed dispatcher can no longer end the attempt it had claimed
+	updated, err := queries.MarkDispatched(ctx, outbox.MarkDispatchedParams{ID: claimed.ID, Attempts: claimed.Attempts})
+	require.NoError(t, err)
+	require.Zero(t, updated)
+}
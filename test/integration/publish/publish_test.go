@@ -0,0 +1,206 @@
+package publish
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/distribute"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/publish"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	resourceManager, _, err := integration.GetOrInitResource()
+	if err != nil {
+		panic(err)
+	}
+
+	_, rollback, err := resourceManager.SetupPostgres()
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	rollback()
+	resourceManager.Cleanup()
+
+	os.Exit(code)
+}
+
+func newPublishService(logger *zap.Logger, db dbbuilder.DBTX) *publish.Service {
+	md := markdown.NewService(logger)
+	formService := form.NewService(logger, db, md)
+	questionService := question.NewService(logger, db, formService, md)
+	workflowService := workflow.NewService(logger, db, formService, questionService)
+	unitService := unit.NewService(logger, db, tenant.NewService(logger, db))
+	distributeService := distribute.NewService(logger, unitService)
+	inboxService := inbox.NewService(logger, db)
+
+	return publish.NewService(logger, db, distributeService, formService, inboxService, workflowService, outbox.NewService(logger, db))
+}
+
+func TestPublishService_PublishForm(t *testing.T) {
+	type Params struct {
+		formID     uuid.UUID
+		editor     uuid.UUID
+		selection  publish.Selection
+		recipients []uuid.UUID
+		messageID  uuid.UUID
+	}
+	testCases := []struct {
+		name        string
+		params      Params
+		setup       func(t *testing.T, params *Params, db dbbuilder.DBTX) context.Context
+		validate    func(t *testing.T, params Params, db dbbuilder.DBTX, result form.Visibility)
+		expectedErr error
+	}{
+		{
+			name: "Publish form and deliver it to every member of the selected units",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) context.Context {
+				builder := workflowbuilder.New(t, db)
+				data := builder.SetupTestData("publish-org", "publish-unit")
+
+				workflowJSON, _, _ := builder.CreateStartEndWorkflow()
+				builder.CreateDraftWorkflow(data.FormRow.ID, data.User, workflowJSON)
+
+				userBuilder := userbuilder.New(t, db)
+				unitBuilder := unitbuilder.New(t, db)
+				member := userBuilder.Create()
+				userBuilder.CreateEmail(member.ID, "member@example.com")
+				unitBuilder.AddMember(data.UnitRow.ID, "user@example.com")
+				unitBuilder.AddMember(data.UnitRow.ID, "member@example.com")
+
+				params.formID = data.FormRow.ID
+				params.editor = data.User
+				params.selection = publish.Selection{UnitIDs: []uuid.UUID{data.UnitRow.ID}}
+				params.recipients = []uuid.UUID{data.User, member.ID}
+
+				return context.Background()
+			},
+			validate: func(t *testing.T, params Params, db dbbuilder.DBTX, result form.Visibility) {
+				status, err := form.New(db).GetStatus(context.Background(), params.formID)
+				require.NoError(t, err)
+				require.Equal(t, form.StatusPublished, status)
+
+				inboxBuilder := inboxbuilder.New(t, db)
+				for _, recipientID := range params.recipients {
+					rows := inboxBuilder.GetUserInboxMessages(recipientID)
+					require.Len(t, rows, 1)
+					require.Equal(t, inbox.ContentTypeForm, rows[0].Type)
+					require.Equal(t, params.formID, rows[0].ContentID)
+				}
+			},
+		},
+		{
+			name: "Skip recipients who already have the form in their inbox",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) context.Context {
+				builder := workflowbuilder.New(t, db)
+				data := builder.SetupTestData("republish-org", "republish-unit")
+
+				workflowJSON, _, _ := builder.CreateStartEndWorkflow()
+				builder.CreateDraftWorkflow(data.FormRow.ID, data.User, workflowJSON)
+
+				userBuilder := userbuilder.New(t, db)
+				unitBuilder := unitbuilder.New(t, db)
+				member := userBuilder.Create()
+				userBuilder.CreateEmail(member.ID, "member@example.com")
+				unitBuilder.AddMember(data.UnitRow.ID, "user@example.com")
+				unitBuilder.AddMember(data.UnitRow.ID, "member@example.com")
+
+				inboxBuilder := inboxbuilder.New(t, db)
+				message := inboxBuilder.CreateMessage(inbox.ContentTypeForm, data.FormRow.ID, data.UnitRow.ID)
+				inboxBuilder.CreateUserInboxMessage(data.User, message.ID)
+
+				params.formID = data.FormRow.ID
+				params.editor = data.User
+				params.selection = publish.Selection{UnitIDs: []uuid.UUID{data.UnitRow.ID}}
+				params.recipients = []uuid.UUID{data.User, member.ID}
+				params.messageID = message.ID
+
+				return context.Background()
+			},
+			validate: func(t *testing.T, params Params, db dbbuilder.DBTX, result form.Visibility) {
+				inboxBuilder := inboxbuilder.New(t, db)
+				for _, recipientID := range params.recipients {
+					rows := inboxBuilder.GetUserInboxMessages(recipientID)
+					require.Len(t, rows, 1)
+					require.Equal(t, params.messageID, rows[0].MessageID)
+				}
+			},
+		},
+		{
+			name: "Reject form that is not in draft status",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) context.Context {
+				builder := workflowbuilder.New(t, db)
+				data := builder.SetupTestData("published-org", "published-unit")
+
+				_, err := form.New(db).SetStatus(context.Background(), form.SetStatusParams{
+					ID:         data.FormRow.ID,
+					Status:     form.StatusPublished,
+					LastEditor: data.User,
+				})
+				require.NoError(t, err)
+
+				params.formID = data.FormRow.ID
+				params.editor = data.User
+				params.selection = publish.Selection{UnitIDs: []uuid.UUID{data.UnitRow.ID}}
+
+				return context.Background()
+			},
+			expectedErr: internal.ErrFormNotDraft,
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			if err != nil {
+				t.Fatalf("failed to setup postgres: %v", err)
+			}
+			defer rollback()
+
+			ctx := context.Background()
+			params := tc.params
+			if tc.setup != nil {
+				ctx = tc.setup(t, &params, db)
+			}
+
+			service := newPublishService(logger, db)
+
+			result, err := service.PublishForm(ctx, params.formID, params.editor, params.selection)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.validate != nil {
+				tc.validate(t, params, db, result)
+			}
+		})
+	}
+}
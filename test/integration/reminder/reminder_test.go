@@ -0,0 +1,259 @@
+package reminder
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/reminder"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/notify"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newReminderService(logger *zap.Logger, db dbbuilder.DBTX) *reminder.Service {
+	notifyService := notify.NewService(logger, db, markdown.NewService(logger), nil, time.UTC, time.Hour)
+	return reminder.NewService(logger, db, inbox.NewService(logger, db), notifyService)
+}
+
+// reminderFixture is a published form delivered to three users: one who submitted a response, one
+// whose response is a draft and one who has not started
+type reminderFixture struct {
+	formID    uuid.UUID
+	submitted uuid.UUID
+	draft     uuid.UUID
+	missing   uuid.UUID
+	outsider  uuid.UUID
+}
+
+func createReminderFixture(t *testing.T, db dbbuilder.DBTX, logger *zap.Logger, deadline time.Time, reminders *form.DeadlineRemindersRequest) reminderFixture {
+	ctx := context.Background()
+	unitBuilder := unitbuilder.New(t, db)
+	org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("reminder-org"))
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("reminder-unit"))
+
+	userBuilder := userbuilder.New(t, db)
+	owner := userBuilder.Create()
+	fixture := reminderFixture{
+		submitted: userBuilder.Create().ID,
+		draft:     userBuilder.Create().ID,
+		missing:   userBuilder.Create().ID,
+		outsider:  userBuilder.Create().ID,
+	}
+	for _, id := range []uuid.UUID{fixture.submitted, fixture.draft, fixture.missing, fixture.outsider} {
+		userBuilder.CreateEmail(id, id.String()+"@example.com")
+	}
+
+	formRow := formbuilder.New(t, db).Create(
+		formbuilder.WithUnitID(unitRow.ID),
+		formbuilder.WithCreatedBy(owner.ID),
+		formbuilder.WithLastEditor(owner.ID),
+		formbuilder.WithDeadline(pgtype.Timestamptz{Time: deadline, Valid: true}),
+	)
+	fixture.formID = formRow.ID
+
+	formService := form.NewService(logger, db, markdown.NewService(logger))
+	_, err := formService.Patch(ctx, formRow.ID, form.PatchRequest{DeadlineReminders: reminders}, owner.ID)
+	require.NoError(t, err)
+	_, err = formService.SetStatus(ctx, formRow.ID, form.StatusPublished, owner.ID)
+	require.NoError(t, err)
+
+	inboxBuilder := inboxbuilder.New(t, db)
+	message := inboxBuilder.CreateMessage(inbox.ContentTypeForm, formRow.ID, unitRow.ID)
+	inboxBuilder.CreateUserInboxBulk([]uuid.UUID{fixture.submitted, fixture.draft, fixture.missing}, message.ID)
+
+	responseBuilder := responsebuilder.New(t, db)
+	responseBuilder.CreateSubmitted(formRow.ID, fixture.submitted)
+	responseBuilder.Create(formRow.ID, fixture.draft)
+
+	return fixture
+}
+
+// reminderOf returns the deadline reminder in a user's inbox, if any
+func reminderOf(t *testing.T, db dbbuilder.DBTX, userID uuid.UUID) (inbox.GetReminderContentRow, bool) {
+	var reminders []inbox.GetReminderContentRow
+	for _, message := range inboxbuilder.New(t, db).GetUserInboxMessages(userID) {
+		if message.Type != inbox.ContentTypeReminder {
+			continue
+		}
+		content, err := inbox.New(db).GetReminderContent(context.Background(), message.ContentID)
+		require.NoError(t, err)
+		reminders = append(reminders, content)
+	}
+	require.LessOrEqual(t, len(reminders), 1)
+	if len(reminders) == 0 {
+		return inbox.GetReminderContentRow{}, false
+	}
+	return reminders[0], true
+}
+
+func TestReminderService_SendDue(t *testing.T) {
+	enabled := true
+	disabled := false
+	testCases := []struct {
+		name                string
+		deadline            time.Duration
+		reminders           *form.DeadlineRemindersRequest
+		expectedHoursBefore int32
+		expectedCount       int
+	}{
+		{
+			name:                "Remind users without a submitted response once the reminder is due",
+			deadline:            48 * time.Hour,
+			reminders:           &form.DeadlineRemindersRequest{Enabled: &enabled},
+			expectedHoursBefore: 72,
+			expectedCount:       2,
+		},
+		{
+			name:                "Send only the due reminder closest to the deadline",
+			deadline:            12 * time.Hour,
+			reminders:           &form.DeadlineRemindersRequest{Enabled: &enabled},
+			expectedHoursBefore: 24,
+			expectedCount:       2,
+		},
+		{
+			name:                "Use the offsets set by the form owner",
+			deadline:            12 * time.Hour,
+			reminders:           &form.DeadlineRemindersRequest{Enabled: &enabled, OffsetHours: []int32{48, 6}},
+			expectedHoursBefore: 48,
+			expectedCount:       2,
+		},
+		{
+			name:          "Skip forms whose reminders are not due yet",
+			deadline:      100 * time.Hour,
+			reminders:     &form.DeadlineRemindersRequest{Enabled: &enabled},
+			expectedCount: 0,
+		},
+		{
+			name:          "Skip forms with reminders turned off",
+			deadline:      12 * time.Hour,
+			reminders:     &form.DeadlineRemindersRequest{Enabled: &disabled},
+			expectedCount: 0,
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(t, err)
+			defer rollback()
+
+			fixture := createReminderFixture(t, db, logger, time.Now().Add(tc.deadline), tc.reminders)
+
+			count := newReminderService(logger, db).SendDue(context.Background())
+			require.Equal(t, tc.expectedCount, count)
+
+			for _, userID := range []uuid.UUID{fixture.submitted, fixture.outsider} {
+				_, ok := reminderOf(t, db, userID)
+				require.False(t, ok)
+			}
+
+			for _, userID := range []uuid.UUID{fixture.draft, fixture.missing} {
+				content, ok := reminderOf(t, db, userID)
+				require.Equal(t, tc.expectedCount > 0, ok)
+				if !ok {
+					continue
+				}
+				require.Equal(t, fixture.formID, content.FormID)
+				require.Equal(t, tc.expectedHoursBefore, content.HoursBefore)
+
+				email, err := notify.New(db).GetByDedupeKey(context.Background(), "deadline_reminder:"+content.ID.String()+":"+userID.String())
+				require.NoError(t, err)
+				require.Equal(t, userID.String()+"@example.com", email.ToAddress)
+			}
+		})
+	}
+}
+
+func TestReminderService_SendDueOnce(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	enabled := true
+	fixture := createReminderFixture(t, db, logger, time.Now().Add(12*time.Hour), &form.DeadlineRemindersRequest{Enabled: &enabled})
+	service := newReminderService(logger, db)
+
+	require.Equal(t, 2, service.SendDue(ctx))
+	require.Equal(t, 0, service.SendDue(ctx))
+
+	// A user the form is delivered to later still gets the reminder, once
+	late := userbuilder.New(t, db).Create()
+	message, err := inbox.New(db).GetMessageByContent(ctx, inbox.GetMessageByContentParams{Type: inbox.ContentTypeForm, ContentID: fixture.formID})
+	require.NoError(t, err)
+	inboxbuilder.New(t, db).CreateUserInboxMessage(late.ID, message.ID)
+
+	require.Equal(t, 1, service.SendDue(ctx))
+	require.Equal(t, 0, service.SendDue(ctx))
+
+	for _, userID := range []uuid.UUID{fixture.draft, fixture.missing, late.ID} {
+		_, ok := reminderOf(t, db, userID)
+		require.True(t, ok)
+	}
+}
+
+func TestReminderService_SendDue_MovedDeadline(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	enabled := true
+	fixture := createReminderFixture(t, db, logger, time.Now().Add(12*time.Hour), &form.DeadlineRemindersRequest{Enabled: &enabled})
+	service := newReminderService(logger, db)
+
+	require.Equal(t, 2, service.SendDue(ctx))
+
+	// Moving the deadline sends the reminder of the new one, once
+	moved := time.Now().Add(20 * time.Hour).Truncate(time.Microsecond)
+	_, err = db.Exec(ctx, "UPDATE forms SET deadline = $2 WHERE id = $1", fixture.formID, moved)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, service.SendDue(ctx))
+	require.Equal(t, 0, service.SendDue(ctx))
+
+	rows, err := db.Query(ctx, "SELECT id, deadline FROM form_deadline_reminders WHERE form_id = $1 ORDER BY deadline", fixture.formID)
+	require.NoError(t, err)
+	var reminderIDs []uuid.UUID
+	var deadlines []time.Time
+	for rows.Next() {
+		var id uuid.UUID
+		var deadline time.Time
+		require.NoError(t, rows.Scan(&id, &deadline))
+		reminderIDs = append(reminderIDs, id)
+		deadlines = append(deadlines, deadline)
+	}
+	require.NoError(t, rows.Err())
+	require.Len(t, reminderIDs, 2)
+	require.True(t, moved.Equal(deadlines[1]))
+
+	for _, userID := range []uuid.UUID{fixture.draft, fixture.missing} {
+		for _, reminderID := range reminderIDs {
+			_, err := notify.New(db).GetByDedupeKey(ctx, "deadline_reminder:"+reminderID.String()+":"+userID.String())
+			require.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package scheduler
+
+import (
+	"NYCU-SDC/core-system-backend/internal/distribute"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/reminder"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/notify"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/publish"
+	"NYCU-SDC/core-system-backend/internal/scheduler"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	resourceManager, _, err := integration.GetOrInitResource()
+	if err != nil {
+		panic(err)
+	}
+
+	_, rollback, err := resourceManager.SetupPostgres()
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	rollback()
+	resourceManager.Cleanup()
+
+	os.Exit(code)
+}
+
+func newSchedulerService(logger *zap.Logger, db dbbuilder.DBTX) *scheduler.Service {
+	md := markdown.NewService(logger)
+	formService := form.NewService(logger, db, md)
+	questionService := question.NewService(logger, db, formService, md)
+	workflowService := workflow.NewService(logger, db, formService, questionService)
+	unitService := unit.NewService(logger, db, tenant.NewService(logger, db))
+	distributeService := distribute.NewService(logger, unitService)
+	events := outbox.NewService(logger, db)
+	inboxService := inbox.NewService(logger, db)
+	publishService := publish.NewService(logger, db, distributeService, formService, inboxService, workflowService, events)
+	reminderService := reminder.NewService(logger, db, inboxService, notify.NewService(logger, db, md, nil, time.UTC, time.Hour))
+
+	return scheduler.NewService(logger, db, time.Minute, formService, publishService, events, reminderService)
+}
+
+func timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+// scheduleFixture creates a unit with one member and returns the unit and member IDs
+func scheduleFixture(t *testing.T, db dbbuilder.DBTX) (uuid.UUID, uuid.UUID) {
+	unitBuilder := unitbuilder.New(t, db)
+	userBuilder := userbuilder.New(t, db)
+
+	org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("schedule-org"))
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("schedule-unit"))
+	member := userBuilder.Create()
+	userBuilder.CreateEmail(member.ID, "schedule@example.com")
+	unitBuilder.AddMember(unitRow.ID, "schedule@example.com")
+
+	return unitRow.ID, member.ID
+}
+
+func TestSchedulerService_PublishDue(t *testing.T) {
+	type Params struct {
+		unitID   uuid.UUID
+		memberID uuid.UUID
+		due      []uuid.UUID
+		notDue   []uuid.UUID
+	}
+	testCases := []struct {
+		name          string
+		params        Params
+		setup         func(t *testing.T, params *Params, db dbbuilder.DBTX)
+		validate      func(t *testing.T, params Params, db dbbuilder.DBTX)
+		expectedCount int
+	}{
+		{
+			name: "Publish draft form whose publish time has passed and deliver it to the unit members",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) {
+				formBuilder := formbuilder.New(t, db)
+				dueForm := formBuilder.Create(
+					formbuilder.WithUnitID(params.unitID),
+					formbuilder.WithLastEditor(params.memberID),
+					formbuilder.WithPublishTime(timestamptz(time.Now().Add(-time.Minute))),
+				)
+				params.due = []uuid.UUID{dueForm.ID}
+			},
+			validate: func(t *testing.T, params Params, db dbbuilder.DBTX) {
+				rows := inboxbuilder.New(t, db).GetUserInboxMessages(params.memberID)
+				require.Len(t, rows, 1)
+				require.Equal(t, params.due[0], rows[0].ContentID)
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "Leave draft forms without a publish time or with a future publish time untouched",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) {
+				formBuilder := formbuilder.New(t, db)
+				futureForm := formBuilder.Create(
+					formbuilder.WithUnitID(params.unitID),
+					formbuilder.WithLastEditor(params.memberID),
+					formbuilder.WithPublishTime(timestamptz(time.Now().Add(time.Hour))),
+				)
+				unscheduledForm := formBuilder.Create(
+					formbuilder.WithUnitID(params.unitID),
+					formbuilder.WithLastEditor(params.memberID),
+				)
+				params.notDue = []uuid.UUID{futureForm.ID, unscheduledForm.ID}
+			},
+			validate: func(t *testing.T, params Params, db dbbuilder.DBTX) {
+				rows := inboxbuilder.New(t, db).GetUserInboxMessages(params.memberID)
+				require.Empty(t, rows)
+			},
+			expectedCount: 0,
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			if err != nil {
+				t.Fatalf("failed to setup postgres: %v", err)
+			}
+			defer rollback()
+
+			params := tc.params
+			params.unitID, params.memberID = scheduleFixture(t, db)
+			if tc.setup != nil {
+				tc.setup(t, &params, db)
+			}
+
+			service := newSchedulerService(logger, db)
+			count := service.PublishDue(context.Background())
+			require.Equal(t, tc.expectedCount, count)
+
+			queries := form.New(db)
+			for _, id := range params.due {
+				status, err := queries.GetStatus(context.Background(), id)
+				require.NoError(t, err)
+				require.Equal(t, form.StatusPublished, status)
+			}
+			for _, id := range params.notDue {
+				status, err := queries.GetStatus(context.Background(), id)
+				require.NoError(t, err)
+				require.Equal(t, form.StatusDraft, status)
+			}
+
+			if tc.validate != nil {
+				tc.validate(t, params, db)
+			}
+		})
+	}
+}
+
+func TestSchedulerService_CloseExpired(t *testing.T) {
+	type Params struct {
+		expected map[uuid.UUID]form.Status
+	}
+	testCases := []struct {
+		name          string
+		setup         func(t *testing.T, params *Params, db dbbuilder.DBTX)
+		expectedCount int
+	}{
+		{
+			name: "Close published form whose deadline has passed",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) {
+				unitID, memberID := scheduleFixture(t, db)
+				expired := formbuilder.New(t, db).Create(
+					formbuilder.WithUnitID(unitID),
+					formbuilder.WithLastEditor(memberID),
+					formbuilder.WithDeadline(timestamptz(time.Now().Add(-time.Minute))),
+				)
+				_, err := form.New(db).SetStatus(context.Background(), form.SetStatusParams{ID: expired.ID, Status: form.StatusPublished, LastEditor: memberID})
+				require.NoError(t, err)
+
+				params.expected[expired.ID] = form.StatusClosed
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "Keep published form before its deadline and draft form past its deadline",
+			setup: func(t *testing.T, params *Params, db dbbuilder.DBTX) {
+				unitID, memberID := scheduleFixture(t, db)
+				formBuilder := formbuilder.New(t, db)
+				open := formBuilder.Create(
+					formbuilder.WithUnitID(unitID),
+					formbuilder.WithLastEditor(memberID),
+					formbuilder.WithDeadline(timestamptz(time.Now().Add(time.Hour))),
+				)
+				_, err := form.New(db).SetStatus(context.Background(), form.SetStatusParams{ID: open.ID, Status: form.StatusPublished, LastEditor: memberID})
+				require.NoError(t, err)
+				draft := formBuilder.Create(
+					formbuilder.WithUnitID(unitID),
+					formbuilder.WithLastEditor(memberID),
+					formbuilder.WithDeadline(timestamptz(time.Now().Add(-time.Minute))),
+				)
+
+				params.expected[open.ID] = form.StatusPublished
+				params.expected[draft.ID] = form.StatusDraft
+			},
+			expectedCount: 0,
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			if err != nil {
+				t.Fatalf("failed to setup postgres: %v", err)
+			}
+			defer rollback()
+
+			params := Params{expected: map[uuid.UUID]form.Status{}}
+			tc.setup(t, &params, db)
+
+			service := newSchedulerService(logger, db)
+			count := service.CloseExpired(context.Background())
+			require.Equal(t, tc.expectedCount, count)
+
+			queries := form.New(db)
+			for id, expectedStatus := range params.expected {
+				status, err := queries.GetStatus(context.Background(), id)
+				require.NoError(t, err)
+				require.Equal(t, expectedStatus, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package sheetsync
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/sheetsync"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	questionbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/question"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const sheetURL = "https://docs.google.com/spreadsheets/d/spreadsheet-1/edit#gid=789"
+
+// fakeSheets is a Google Sheet kept in memory. Every call fails with failWith, if it is set.
+type fakeSheets struct {
+	rows     [][]string
+	struck   map[int32]bool
+	failWith error
+}
+
+func newFakeSheets() *fakeSheets {
+	return &fakeSheets{struck: make(map[int32]bool)}
+}
+
+func (f *fakeSheets) GetSheet(_ context.Context, spreadsheetID string, sheetID int64) (sheetsync.Sheet, error) {
+	if f.failWith != nil {
+		return sheetsync.Sheet{}, f.failWith
+	}
+	if spreadsheetID != "spreadsheet-1" || sheetID != 789 {
+		return sheetsync.Sheet{}, errors.New("sheet not found")
+	}
+	return sheetsync.Sheet{SpreadsheetID: spreadsheetID, ID: sheetID, Title: "Responses"}, nil
+}
+
+func (f *fakeSheets) Append(_ context.Context, _ sheetsync.Sheet, cells []string) (int32, error) {
+	if f.failWith != nil {
+		return 0, f.failWith
+	}
+	f.rows = append(f.rows, cells)
+	return int32(len(f.rows)), nil
+}
+
+func (f *fakeSheets) Update(_ context.Context, _ sheetsync.Sheet, firstRow int32, rows [][]string) error {
+	if f.failWith != nil {
+		return f.failWith
+	}
+	for i, cells := range rows {
+		index := int(firstRow) - 1 + i
+		for len(f.rows) <= index {
+			f.rows = append(f.rows, nil)
+		}
+		f.rows[index] = cells
+	}
+	return nil
+}
+
+func (f *fakeSheets) Clear(_ context.Context, _ sheetsync.Sheet) error {
+	if f.failWith != nil {
+		return f.failWith
+	}
+	f.rows = nil
+	f.struck = make(map[int32]bool)
+	return nil
+}
+
+func (f *fakeSheets) Strike(_ context.Context, _ sheetsync.Sheet, row int32, struck bool) error {
+	if f.failWith != nil {
+		return f.failWith
+	}
+	f.struck[row] = struck
+	return nil
+}
+
+// lastCells returns the last cell of every row of the sheet after the header, which is the answer to the
+// only question of the form
+func (f *fakeSheets) lastCells() []string {
+	cells := make([]string, 0, len(f.rows))
+	for _, row := range f.rows[1:] {
+		cells = append(cells, row[len(row)-1])
+	}
+	return cells
+}
+
+func newResponseService(logger *zap.Logger, db dbbuilder.DBTX, blobStore file.BlobStore, formService *form.Service, events *outbox.Service) *response.Service {
+	md := markdown.NewService(logger)
+	questionService := question.NewService(logger, db, formService, md)
+	workflowService := workflow.NewService(logger, db, formService, questionService)
+
+	return response.NewService(logger, db, nil, questionService, workflowService, formService, nil, file.NewService(logger, db, blobStore, nil), events)
+}
+
+// sheetFixture is a form linked to a sheet, with one question, and a user who responds to it
+type sheetFixture struct {
+	formID     uuid.UUID
+	userID     uuid.UUID
+	questionID uuid.UUID
+}
+
+func createSheetFixture(t *testing.T, db dbbuilder.DBTX) sheetFixture {
+	builder := workflowbuilder.New(t, db)
+	data := builder.SetupTestData("sheetsync-org", "sheetsync-unit")
+
+	workflowJSON, _, sectionID, _ := builder.CreateStartSectionEndWorkflow()
+	builder.CreateSectionRecord(sectionID, data.FormRow.ID, "Answers")
+	builder.CreateActiveWorkflow(data.FormRow.ID, data.User, workflowJSON)
+	questionRow := questionbuilder.New(t, db).Create(sectionID, questionbuilder.WithTitle("Nickname"), questionbuilder.WithOrder(1))
+
+	_, err := db.Exec(context.Background(), "UPDATE forms SET google_sheet_url = $2 WHERE id = $1", data.FormRow.ID, sheetURL)
+	require.NoError(t, err)
+
+	return sheetFixture{
+		formID:     data.FormRow.ID,
+		userID:     data.User,
+		questionID: questionRow.ID,
+	}
+}
+
+func TestSheetSyncService_ResponseEvents(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fixture := createSheetFixture(t, db)
+
+	sheet := newFakeSheets()
+	events := outbox.NewService(logger, db)
+	formService := form.NewService(logger, db, markdown.NewService(logger))
+	responseService := newResponseService(logger, db, blobStore, formService, events)
+	service := sheetsync.NewService(logger, db, sheet, formService, responseService, events)
+	for _, eventType := range []string{outbox.TypeResponseSubmitted, outbox.TypeResponseCancelled, outbox.TypeResponseDeleted} {
+		events.Subscribe(eventType, "sheetsync.response", service.HandleResponseEvent)
+	}
+	events.Subscribe(outbox.TypeFormSheetResyncRequested, "sheetsync.resync", service.HandleResyncRequested)
+
+	_, err = service.Get(ctx, fixture.formID)
+	require.ErrorIs(t, err, internal.ErrSheetSyncNotFound)
+
+	responseBuilder := responsebuilder.New(t, db)
+	submit := func(responseID uuid.UUID, nickname string) {
+		responseBuilder.CreateAnswers([]uuid.UUID{responseID}, fixture.questionID, []byte(`{"value":"`+nickname+`"}`))
+		submitted, err := responseService.UpdateSubmitted(ctx, responseID)
+		require.NoError(t, err)
+		err = events.PublishResponseEvent(ctx, outbox.TypeResponseSubmitted,
+			outbox.NewResponsePayload(submitted.ID, submitted.FormID, submitted.SubmittedBy, submitted.SubmittedAt))
+		require.NoError(t, err)
+	}
+	dispatchAll := func() {
+		for events.DispatchNext(ctx) {
+		}
+	}
+
+	// The first event of a form that was never synced writes the whole sheet
+	first := responseBuilder.Create(fixture.formID, fixture.userID)
+	submit(first.ID, "alice")
+	dispatchAll()
+
+	sync, err := service.Get(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusSynced, sync.Status)
+	require.True(t, sync.LastSyncedAt.Valid)
+	require.Len(t, sheet.rows, 2)
+	require.Equal(t, "Nickname", sheet.rows[0][len(sheet.rows[0])-1])
+	require.Equal(t, first.ID.String(), sheet.rows[1][0])
+	require.Equal(t, []string{"alice"}, sheet.lastCells())
+
+	// Later submissions are appended, with answers that look like formulas written as they are
+	second := responseBuilder.Create(fixture.formID, fixture.userID)
+	submit(second.ID, "=bob")
+	dispatchAll()
+	require.Equal(t, []string{"alice", "=bob"}, sheet.lastCells())
+	require.Equal(t, second.ID.String(), sheet.rows[2][0])
+
+	// The row of a cancelled response is struck through and rewritten when it is submitted again
+	require.NoError(t, responseService.CancelSubmission(ctx, first.ID, fixture.userID))
+	dispatchAll()
+	require.True(t, sheet.struck[2])
+	require.Len(t, sheet.rows, 3)
+
+	submit(first.ID, "alicia")
+	dispatchAll()
+	require.False(t, sheet.struck[2])
+	require.Equal(t, []string{"alicia", "=bob"}, sheet.lastCells())
+
+	// The row of a deleted response is struck through
+	require.NoError(t, responseService.Delete(ctx, second.ID))
+	dispatchAll()
+	require.True(t, sheet.struck[3])
+
+	// A response cancelled before its submission was synced is not written, and neither is a draft
+	cancelled := responseBuilder.Create(fixture.formID, fixture.userID)
+	submit(cancelled.ID, "dave")
+	require.NoError(t, responseService.CancelSubmission(ctx, cancelled.ID, fixture.userID))
+	draft := responseBuilder.Create(fixture.formID, fixture.userID)
+	responseBuilder.CreateAnswers([]uuid.UUID{draft.ID}, fixture.questionID, []byte(`{"value":"carol"}`))
+	dispatchAll()
+	require.Equal(t, []string{"alicia", "=bob"}, sheet.lastCells())
+
+	// A resync rewrites the sheet with the submitted responses only
+	sync, err = service.RequestResync(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusPending, sync.Status)
+	dispatchAll()
+
+	sync, err = service.Get(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusSynced, sync.Status)
+	require.Equal(t, []string{"alicia"}, sheet.lastCells())
+	require.Empty(t, sheet.struck)
+}
+
+func TestSheetSyncService_Failure(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	blobStore, err := resourceManager.SetupBlobStore()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fixture := createSheetFixture(t, db)
+
+	sheet := newFakeSheets()
+	events := outbox.NewService(logger, db)
+	formService := form.NewService(logger, db, markdown.NewService(logger))
+	responseService := newResponseService(logger, db, blobStore, formService, events)
+	service := sheetsync.NewService(logger, db, sheet, formService, responseService, events)
+
+	responseBuilder := responsebuilder.New(t, db)
+	first := responseBuilder.CreateSubmitted(fixture.formID, fixture.userID)
+	responseBuilder.CreateAnswers([]uuid.UUID{first.ID}, fixture.questionID, []byte(`{"value":"alice"}`))
+	firstEvent, err := events.Publish(ctx, outbox.AggregateResponse, first.ID, outbox.TypeResponseSubmitted,
+		outbox.NewResponsePayload(first.ID, first.FormID, first.SubmittedBy, first.SubmittedAt))
+	require.NoError(t, err)
+
+	// A failed sync is recorded with its error and retried by the outbox
+	sheet.failWith = errors.New("google sheets returned 503 UNAVAILABLE: The service is currently unavailable")
+	require.Error(t, service.HandleResponseEvent(ctx, firstEvent))
+
+	sync, err := service.Get(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusFailed, sync.Status)
+	require.Contains(t, sync.Error.String, "UNAVAILABLE")
+	require.False(t, sync.LastSyncedAt.Valid)
+	require.False(t, sync.LockedUntil.Valid)
+
+	// A sheet that is not shared with the service account is recorded as failed without a retry
+	sheet.failWith = fmt.Errorf("%w: google sheets returned 403 PERMISSION_DENIED: The caller does not have permission", internal.ErrGoogleSheetUnavailable)
+	require.NoError(t, service.HandleResponseEvent(ctx, firstEvent))
+
+	sync, err = service.Get(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusFailed, sync.Status)
+	require.Contains(t, sync.Error.String, "PERMISSION_DENIED")
+	require.False(t, sync.LockedUntil.Valid)
+
+	// An event is retried while another sync holds the lock of the sheet. Once the lock expires, the next
+	// event writes the whole sheet, including the responses whose events failed.
+	sheet.failWith = nil
+	_, err = db.Exec(ctx, "UPDATE form_sheet_syncs SET locked_until = now() + interval '1 minute' WHERE form_id = $1", fixture.formID)
+	require.NoError(t, err)
+	require.Error(t, service.HandleResponseEvent(ctx, firstEvent))
+	require.Empty(t, sheet.rows)
+
+	sync, err = service.Get(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusFailed, sync.Status)
+
+	_, err = db.Exec(ctx, "UPDATE form_sheet_syncs SET locked_until = now() - interval '1 minute' WHERE form_id = $1", fixture.formID)
+	require.NoError(t, err)
+	require.NoError(t, service.HandleResponseEvent(ctx, firstEvent))
+	require.Equal(t, []string{"alice"}, sheet.lastCells())
+
+	// Later events are applied to the synced sheet
+	second := responseBuilder.CreateSubmitted(fixture.formID, fixture.userID)
+	responseBuilder.CreateAnswers([]uuid.UUID{second.ID}, fixture.questionID, []byte(`{"value":"bob"}`))
+	secondEvent, err := events.Publish(ctx, outbox.AggregateResponse, second.ID, outbox.TypeResponseSubmitted,
+		outbox.NewResponsePayload(second.ID, second.FormID, second.SubmittedBy, second.SubmittedAt))
+	require.NoError(t, err)
+	require.NoError(t, service.HandleResponseEvent(ctx, secondEvent))
+
+	sync, err = service.Get(ctx, fixture.formID)
+	require.NoError(t, err)
+	require.Equal(t, sheetsync.SheetSyncStatusSynced, sync.Status)
+	require.False(t, sync.Error.Valid)
+	require.Equal(t, []string{"alice", "bob"}, sheet.lastCells())
+
+	// Forms without a sheet are skipped
+	_, err = db.Exec(ctx, "UPDATE forms SET google_sheet_url = NULL WHERE id = $1", fixture.formID)
+	require.NoError(t, err)
+	sheet.failWith = errors.New("unexpected call")
+	require.NoError(t, service.HandleResponseEvent(ctx, secondEvent))
+	_, err = service.RequestResync(ctx, fixture.formID)
+	require.ErrorIs(t, err, internal.ErrGoogleSheetNotLinked)
+}
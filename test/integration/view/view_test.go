@@ -0,0 +1,212 @@
+package view
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/view"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	questionbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/question"
+	responsebuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/response"
+	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newViewService(logger *zap.Logger, db dbbuilder.DBTX) *view.Service {
+	md := markdown.NewService(logger)
+	formService := form.NewService(logger, db, md)
+	return view.NewService(logger, db, question.NewService(logger, db, formService, md))
+}
+
+// viewFixture is a form with a name and a score question and three responses: Alice submitted in
+// January with score 9, Bob submitted in March with score 10 and Carol's draft without a score
+type viewFixture struct {
+	formID  uuid.UUID
+	nameID  uuid.UUID
+	scoreID uuid.UUID
+	alice   uuid.UUID
+	bob     uuid.UUID
+	carol   uuid.UUID
+}
+
+func createViewFixture(t *testing.T, db dbbuilder.DBTX) viewFixture {
+	ctx := context.Background()
+	builder := workflowbuilder.New(t, db)
+	data := builder.SetupTestData("view-org", "view-unit")
+
+	workflowJSON, _, sectionID, _ := builder.CreateStartSectionEndWorkflow()
+	builder.CreateSectionRecord(sectionID, data.FormRow.ID, "Answers")
+	builder.CreateActiveWorkflow(data.FormRow.ID, data.User, workflowJSON)
+
+	questionBuilder := questionbuilder.New(t, db)
+	fixture := viewFixture{
+		formID:  data.FormRow.ID,
+		nameID:  questionBuilder.Create(sectionID, questionbuilder.WithTitle("Name"), questionbuilder.WithOrder(1)).ID,
+		scoreID: questionBuilder.Create(sectionID, questionbuilder.WithTitle("Score"), questionbuilder.WithOrder(2)).ID,
+	}
+
+	responseBuilder := responsebuilder.New(t, db)
+	submit := func(name, score string, submittedAt time.Time) uuid.UUID {
+		responseID := responseBuilder.CreateSubmitted(fixture.formID, data.User).ID
+		_, err := db.Exec(ctx, "UPDATE form_responses SET submitted_at = $2 WHERE id = $1", responseID, submittedAt)
+		require.NoError(t, err)
+		responseBuilder.CreateAnswers([]uuid.UUID{responseID}, fixture.nameID, []byte(`{"value":"`+name+`"}`))
+		responseBuilder.CreateAnswers([]uuid.UUID{responseID}, fixture.scoreID, []byte(`{"value":"`+score+`"}`))
+		return responseID
+	}
+	fixture.alice = submit("Alice", "9", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	fixture.bob = submit("Bob", "10", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	fixture.carol = responseBuilder.Create(fixture.formID, data.User).ID
+	responseBuilder.CreateAnswers([]uuid.UUID{fixture.carol}, fixture.nameID, []byte(`{"value":"Carol"}`))
+
+	return fixture
+}
+
+func TestViewService_ListResponses(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	fixture := createViewFixture(t, db)
+	service := newViewService(logger, db)
+
+	testCases := []struct {
+		name       string
+		definition view.Definition
+		limit      int
+		offset     int
+		expected   []uuid.UUID
+		total      int
+	}{
+		{
+			name:       "Default sort is submission time, newest first, drafts last",
+			definition: view.Definition{},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.bob, fixture.alice, fixture.carol},
+			total:      3,
+		},
+		{
+			name:       "Page of the sorted responses",
+			definition: view.Definition{},
+			limit:      1,
+			offset:     1,
+			expected:   []uuid.UUID{fixture.alice},
+			total:      3,
+		},
+		{
+			name:       "Progress filter",
+			definition: view.Definition{Filters: []view.Filter{{Field: view.FieldProgress, Operator: question.OperatorEq, Value: json.RawMessage(`"draft"`)}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.carol},
+			total:      1,
+		},
+		{
+			name:       "SubmittedAt range",
+			definition: view.Definition{Filters: []view.Filter{{Field: view.FieldSubmittedAt, Operator: question.OperatorLt, Value: json.RawMessage(`"2026-02-01T00:00:00Z"`)}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.alice},
+			total:      1,
+		},
+		{
+			name:       "Missing answer only matches isEmpty",
+			definition: view.Definition{Filters: []view.Filter{{Field: view.FieldAnswer, QuestionID: &fixture.scoreID, Operator: question.OperatorIsEmpty}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.carol},
+			total:      1,
+		},
+		{
+			name:       "Missing answer does not match neq",
+			definition: view.Definition{Filters: []view.Filter{{Field: view.FieldAnswer, QuestionID: &fixture.scoreID, Operator: question.OperatorNeq, Value: json.RawMessage(`"9"`)}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.bob},
+			total:      1,
+		},
+		{
+			name:       "Text answer contains",
+			definition: view.Definition{Filters: []view.Filter{{Field: view.FieldAnswer, QuestionID: &fixture.nameID, Operator: question.OperatorContains, Value: json.RawMessage(`"o"`)}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.bob, fixture.carol},
+			total:      2,
+		},
+		{
+			name:       "Numeric answers sort as numbers with missing answers last",
+			definition: view.Definition{Sort: []view.SortKey{{Field: view.FieldAnswer, QuestionID: &fixture.scoreID, Direction: view.DirectionAsc}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.alice, fixture.bob, fixture.carol},
+			total:      3,
+		},
+		{
+			name:       "Text answers sort descending",
+			definition: view.Definition{Sort: []view.SortKey{{Field: view.FieldAnswer, QuestionID: &fixture.nameID, Direction: view.DirectionDesc}}},
+			limit:      10,
+			expected:   []uuid.UUID{fixture.carol, fixture.bob, fixture.alice},
+			total:      3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			created, err := service.Create(ctx, fixture.formID)
+			require.NoError(t, err)
+			_, err = service.UpdateDefinition(ctx, fixture.formID, created.ID, tc.definition)
+			require.NoError(t, err)
+
+			page, err := service.ListResponses(ctx, fixture.formID, created.ID, tc.limit, tc.offset)
+			require.NoError(t, err)
+			require.Equal(t, tc.total, page.Total)
+
+			responseIDs := make([]uuid.UUID, 0, len(page.Responses))
+			for _, row := range page.Responses {
+				responseIDs = append(responseIDs, row.Response.ID)
+			}
+			require.Equal(t, tc.expected, responseIDs)
+
+			selection, err := service.Select(ctx, fixture.formID, created.ID)
+			require.NoError(t, err)
+			require.Len(t, selection.ResponseIDs, tc.total)
+		})
+	}
+}
+
+func TestViewService_ListResponses_Answers(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	fixture := createViewFixture(t, db)
+	service := newViewService(logger, db)
+
+	ctx := context.Background()
+	created, err := service.Create(ctx, fixture.formID)
+	require.NoError(t, err)
+
+	page, err := service.ListResponses(ctx, fixture.formID, created.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Columns, 2)
+	require.Len(t, page.Responses, 3)
+
+	bob := page.Responses[0]
+	require.Equal(t, fixture.bob, bob.Response.ID)
+	require.Equal(t, "Bob", *bob.Answers[fixture.nameID.String()])
+	require.Equal(t, "10", *bob.Answers[fixture.scoreID.String()])
+
+	carol := page.Responses[2]
+	require.Equal(t, fixture.carol, carol.Response.ID)
+	require.Equal(t, "Carol", *carol.Answers[fixture.nameID.String()])
+	require.Nil(t, carol.Answers[fixture.scoreID.String()])
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal/outbox"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"NYCU-SDC/core-system-backend/test/integration"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService_EmitAndDeliver(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	// secrets are filled in once the webhooks exist; every delivery must be signed with one of them
+	var secrets []string
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+
+		signed := false
+		for _, secret := range secrets {
+			signed = signed || webhook.Verify(secret, timestamp, body, r.Header.Get(webhook.HeaderSignature))
+		}
+		require.True(t, signed)
+		received++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	unitBuilder := unitbuilder.New(t, db)
+	org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("webhook-org"))
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("webhook-unit"))
+	admin := userbuilder.New(t, db).Create()
+	target := formbuilder.New(t, db).Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(admin.ID))
+	other := formbuilder.New(t, db).Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(admin.ID))
+
+	service := webhook.NewService(logger, db, true)
+
+	orgHook, err := service.CreateForOrg(ctx, org.ID, admin.ID, server.URL, []string{"form.closed", "response.submitted"})
+	require.NoError(t, err)
+	require.NotEmpty(t, orgHook.Secret)
+
+	formHook, err := service.CreateForForm(ctx, target.ID, admin.ID, server.URL, []string{"form.closed"})
+	require.NoError(t, err)
+	require.Equal(t, unitRow.ID, formHook.UnitID)
+
+	paused, err := service.CreateForOrg(ctx, org.ID, admin.ID, server.URL, []string{"form.closed"})
+	require.NoError(t, err)
+	_, err = service.Update(ctx, paused.ID, paused.Url, paused.Events, false)
+	require.NoError(t, err)
+
+	secrets = []string{orgHook.Secret, formHook.Secret}
+
+	// Webhooks subscribe to the outbox events of forms and responses
+	events := outbox.NewService(logger, db)
+	for _, eventType := range webhook.Events {
+		events.Subscribe(string(eventType), "webhook.delivery", service.HandleEvent)
+	}
+
+	require.NoError(t, events.PublishFormEvent(ctx, outbox.TypeFormClosed, target.ID))
+	require.NoError(t, events.PublishFormEvent(ctx, outbox.TypeFormClosed, other.ID))
+	require.NoError(t, events.PublishFormEvent(ctx, outbox.TypeFormPublished, target.ID))
+	submitted, err := events.Publish(ctx, outbox.AggregateResponse, uuid.New(), outbox.TypeResponseSubmitted,
+		outbox.ResponsePayload{ResponseID: uuid.New(), FormID: target.ID, SubmittedBy: admin.ID})
+	require.NoError(t, err)
+	for events.DispatchNext(ctx) {
+	}
+
+	// An event dispatched again, e.g. because another subscriber failed, queues no further delivery
+	require.NoError(t, service.HandleEvent(ctx, submitted))
+
+	testCases := []struct {
+		name     string
+		webhook  webhook.Webhook
+		expected int
+	}{
+		{name: "Org webhook receives the subscribed events of every form of the org", webhook: orgHook, expected: 3},
+		{name: "Form webhook receives the subscribed events of its form only", webhook: formHook, expected: 1},
+		{name: "Paused webhook receives nothing", webhook: paused, expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deliveries, err := service.ListDeliveries(ctx, tc.webhook.ID)
+			require.NoError(t, err)
+			require.Len(t, deliveries, tc.expected)
+			for _, delivery := range deliveries {
+				require.Equal(t, webhook.WebhookDeliveryStatusPending, delivery.Status)
+			}
+		})
+	}
+
+	// The worker delivers every queued delivery
+	delivered := 0
+	for service.ProcessNext(ctx) {
+		delivered++
+	}
+	require.Equal(t, 4, delivered)
+	require.Equal(t, 4, received)
+
+	deliveries, err := service.ListDeliveries(ctx, formHook.ID)
+	require.NoError(t, err)
+	require.Equal(t, webhook.WebhookDeliveryStatusSucceeded, deliveries[0].Status)
+	require.Equal(t, int32(1), deliveries[0].Attempts)
+
+	var payload webhook.Payload
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &payload))
+	require.Equal(t, webhook.EventFormClosed, payload.Event)
+	require.Equal(t, target.ID, payload.Form.ID)
+
+	// A delivery can be sent again
+	redelivery, err := service.Redeliver(ctx, formHook.ID, deliveries[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, webhook.WebhookDeliveryStatusPending, redelivery.Status)
+	require.JSONEq(t, string(deliveries[0].Payload), string(redelivery.Payload))
+}
+
+func TestWebhookQueries_StaleWorker(t *testing.T) {
+	testCases := []struct {
+		name string
+		end  func(queries *webhook.Queries, delivery webhook.ClaimRow) (int64, error)
+	}{
+		{
+			name: "Succeed",
+			end: func(queries *webhook.Queries, delivery webhook.ClaimRow) (int64, error) {
+				return queries.Succeed(context.Background(), webhook.SucceedParams{ID: delivery.ID, Attempts: delivery.Attempts})
+			},
+		},
+		{
+			name: "Retry",
+			end: func(queries *webhook.Queries, delivery webhook.ClaimRow) (int64, error) {
+				return queries.Retry(context.Background(), webhook.RetryParams{ID: delivery.ID, Attempts: delivery.Attempts})
+			},
+		},
+		{
+			name: "Fail",
+			end: func(queries *webhook.Queries, delivery webhook.ClaimRow) (int64, error) {
+				return queries.Fail(context.Background(), webhook.FailParams{ID: delivery.ID, Attempts: delivery.Attempts})
+			},
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(t, err)
+			defer rollback()
+
+			ctx := context.Background()
+			unitBuilder := unitbuilder.New(t, db)
+			org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("stale-webhook-org"))
+			unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("stale-webhook-unit"))
+			admin := userbuilder.New(t, db).Create()
+			target := formbuilder.New(t, db).Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(admin.ID))
+
+			service := webhook.NewService(logger, db, true)
+			queries := webhook.New(db)
+
+			created, err := service.CreateForForm(ctx, target.ID, admin.ID, "http://localhost:8080/webhook", []string{"form.closed"})
+			require.NoError(t, err)
+			closed, err := outbox.NewService(logger, db).Publish(ctx, outbox.AggregateForm, target.ID, outbox.TypeFormClosed, outbox.FormPayload{FormID: target.ID})
+			require.NoError(t, err)
+			require.NoError(t, service.HandleEvent(ctx, closed))
+			stale, err := queries.Claim(ctx)
+			require.NoError(t, err)
+
+			// The first worker stops, and the delivery is requeued and claimed by another
+			_, err = db.Exec(ctx, `UPDATE webhook_deliveries SET updated_at = now() - interval '1 hour' WHERE id = $1`, stale.ID)
+			require.NoError(t, err)
+			require.Equal(t, 1, service.RequeueStale(ctx))
+			current, err := queries.Claim(ctx)
+			require.NoError(t, err)
+			require.Equal(t, stale.Attempts+1, current.Attempts)
+
+			// The first worker cannot end the attempt of the second
+			updated, err := tc.end(queries, stale)
+			require.NoError(t, err)
+			require.Zero(t, updated)
+
+			deliveries, err := service.ListDeliveries(ctx, created.ID)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 1)
+			require.Equal(t, webhook.WebhookDeliveryStatusDelivering, deliveries[0].Status)
+
+			updated, err = tc.end(queries, current)
+			require.NoError(t, err)
+			require.Equal(t, int64(1), updated)
+		})
+	}
+}
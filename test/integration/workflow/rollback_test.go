@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/markdown"
+	"NYCU-SDC/core-system-backend/test/integration"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowService_Rollback(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	require.NoError(t, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(t, err)
+	defer rollback()
+
+	ctx := context.Background()
+	builder := workflowbuilder.New(t, db)
+	data := builder.SetupTestData("rollback-org", "rollback-unit")
+
+	oldJSON, _, sectionID, _ := builder.CreateStartSectionEndWorkflow()
+	builder.CreateSectionRecord(sectionID, data.FormRow.ID, "Section")
+	builder.CreateActiveWorkflow(data.FormRow.ID, data.User, oldJSON)
+	oldVersionID := builder.GetActiveVersionID(data.FormRow.ID)
+
+	latestJSON, _, latestSectionID, _ := builder.CreateStartSectionEndWorkflow()
+	builder.CreateSectionRecord(latestSectionID, data.FormRow.ID, "Latest section")
+	builder.CreateDraftWorkflow(data.FormRow.ID, data.User, latestJSON)
+
+	markdownService := markdown.NewService(logger)
+	formService := form.NewService(logger, db, markdownService)
+	questionService := question.NewService(logger, db, formService, markdownService)
+	workflowService := workflow.NewService(logger, db, formService, questionService)
+
+	editor := userbuilder.New(t, db).Create()
+	created, err := workflowService.Rollback(ctx, data.FormRow.ID, oldVersionID, editor.ID)
+	require.Error(t, err, "nodes were replaced since the old version")
+	require.Empty(t, created)
+
+	// Once the latest version has the same nodes again, the old workflow is copied into a new draft
+	builder.CreateDraftWorkflow(data.FormRow.ID, data.User, oldJSON)
+	created, err = workflowService.Rollback(ctx, data.FormRow.ID, oldVersionID, editor.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(oldJSON), string(created.Workflow))
+	require.Equal(t, editor.ID, created.LastEditor)
+	require.False(t, created.IsActive)
+	require.NotEqual(t, oldVersionID, created.ID)
+
+	latest, err := workflowService.Get(ctx, data.FormRow.ID)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, latest.ID)
+
+	formRow, err := formService.Get(ctx, data.FormRow.ID)
+	require.NoError(t, err)
+	require.Equal(t, editor.ID, formRow.LastEditor)
+}
@@ -0,0 +1,56 @@
+package questionbuilder
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/test/testdata"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type Builder struct {
+	t  testing.TB
+	db dbbuilder.DBTX
+}
+
+func New(t testing.TB, db dbbuilder.DBTX) *Builder {
+	return &Builder{t: t, db: db}
+}
+
+func (b Builder) Queries() *question.Queries {
+	return question.New(b.db)
+}
+
+// Create creates a question in the given section, a short text question unless WithType is given
+func (b Builder) Create(sectionID uuid.UUID, opts ...Option) question.CreateRow {
+	queries := b.Queries()
+
+	p := &FactoryParams{
+		Type:     question.QuestionTypeShortText,
+		Title:    testdata.RandomName(),
+		Metadata: []byte("{}"),
+		Order:    1,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	questionRow, err := queries.Create(context.Background(), question.CreateParams{
+		SectionID:       sectionID,
+		Required:        p.Required,
+		Type:            p.Type,
+		Title:           pgtype.Text{String: p.Title, Valid: true},
+		DescriptionJson: []byte(`{"type":"doc","content":[{"type":"paragraph"}]}`),
+		DescriptionHtml: "",
+		Metadata:        p.Metadata,
+		Order:           p.Order,
+		SourceID:        pgtype.UUID{},
+	})
+	require.NoError(b.t, err)
+
+	return questionRow
+}
@@ -0,0 +1,35 @@
+package questionbuilder
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+)
+
+type Option func(*FactoryParams)
+
+type FactoryParams struct {
+	Type     question.QuestionType
+	Title    string
+	Required bool
+	Metadata []byte
+	Order    int32
+}
+
+func WithType(questionType question.QuestionType) Option {
+	return func(p *FactoryParams) { p.Type = questionType }
+}
+
+func WithTitle(title string) Option {
+	return func(p *FactoryParams) { p.Title = title }
+}
+
+func WithRequired(required bool) Option {
+	return func(p *FactoryParams) { p.Required = required }
+}
+
+func WithMetadata(metadata []byte) Option {
+	return func(p *FactoryParams) { p.Metadata = metadata }
+}
+
+func WithOrder(order int32) Option {
+	return func(p *FactoryParams) { p.Order = order }
+}
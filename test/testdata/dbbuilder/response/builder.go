@@ -0,0 +1,121 @@
+package responsebuilder
+
+import (
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/form/answer"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type Builder struct {
+	t  testing.TB
+	db dbbuilder.DBTX
+}
+
+func New(t testing.TB, db dbbuilder.DBTX) *Builder {
+	return &Builder{t: t, db: db}
+}
+
+func (b Builder) Queries() *response.Queries {
+	return response.New(b.db)
+}
+
+// Create creates a draft response to a form
+func (b Builder) Create(formID, submittedBy uuid.UUID) response.FormResponse {
+	queries := b.Queries()
+	responseRow, err := queries.Create(context.Background(), response.CreateParams{
+		FormID:      formID,
+		SubmittedBy: submittedBy,
+	})
+	require.NoError(b.t, err)
+	return responseRow
+}
+
+// CreateSubmitted creates a submitted response to a form
+func (b Builder) CreateSubmitted(formID, submittedBy uuid.UUID) response.FormResponse {
+	queries := b.Queries()
+	created := b.Create(formID, submittedBy)
+	submitted, err := queries.UpdateSubmitted(context.Background(), created.ID)
+	require.NoError(b.t, err)
+	return submitted
+}
+
+// CreateSubmittedBulk creates count submitted responses to a form in a single statement, one second
+// apart, for datasets too large to create one response at a time
+func (b Builder) CreateSubmittedBulk(formID, submittedBy uuid.UUID, count int) []uuid.UUID {
+	rows, err := b.db.Query(context.Background(),
+		`INSERT INTO form_responses (form_id, submitted_by, submitted_at, progress)
+		SELECT $1, $2, now() - n * interval '1 second', 'submitted'
+		FROM generate_series(1, $3::int) AS n
+		RETURNING id`,
+		formID, submittedBy, count)
+	require.NoError(b.t, err)
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0, count)
+	for rows.Next() {
+		var id uuid.UUID
+		require.NoError(b.t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(b.t, rows.Err())
+	return ids
+}
+
+// CreateAnswers stores the same stored answer value for a question in each of the given responses
+func (b Builder) CreateAnswers(responseIDs []uuid.UUID, questionID uuid.UUID, value []byte) {
+	questionIDs := make([]uuid.UUID, len(responseIDs))
+	values := make([][]byte, len(responseIDs))
+	for i := range responseIDs {
+		questionIDs[i] = questionID
+		values[i] = value
+	}
+
+	_, err := answer.New(b.db).BatchUpsert(context.Background(), answer.BatchUpsertParams{
+		ResponseIds: responseIDs,
+		QuestionIds: questionIDs,
+		Values:      values,
+	})
+	require.NoError(b.t, err)
+}
+
+// CreateAnswerFile stores a file in blobStore as the upload_file answer of a response to a question and
+// attaches it to the answer, as uploading it through the answer service would
+func (b Builder) CreateAnswerFile(blobStore file.BlobStore, responseID, questionID, uploadedBy uuid.UUID, filename string, data []byte) file.File {
+	savedFile, err := file.NewService(zap.NewNop(), b.db, blobStore, nil).SaveFile(context.Background(), bytes.NewReader(data), filename, "application/octet-stream", &uploadedBy)
+	require.NoError(b.t, err)
+
+	value, err := json.Marshal(shared.UploadFileAnswer{Files: []shared.UploadFileEntry{{
+		FileID:           savedFile.ID,
+		OriginalFilename: savedFile.OriginalFilename,
+		ContentType:      savedFile.ContentType,
+		Size:             savedFile.Size,
+	}}})
+	require.NoError(b.t, err)
+
+	answers, err := answer.New(b.db).BatchUpsert(context.Background(), answer.BatchUpsertParams{
+		ResponseIds: []uuid.UUID{responseID},
+		QuestionIds: []uuid.UUID{questionID},
+		Values:      [][]byte{value},
+	})
+	require.NoError(b.t, err)
+
+	_, err = file.New(b.db).CreateAttachment(context.Background(), file.CreateAttachmentParams{
+		FileID:       savedFile.ID,
+		ResourceType: file.ResourceTypeFormAnswer,
+		ResourceID:   answers[0].ID,
+		CreatedBy:    uploadedBy,
+	})
+	require.NoError(b.t, err)
+
+	return savedFile
+}
@@ -0,0 +1,89 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/file/blobstore"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/ory/dockertest/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	minioAccessKey = "minioadmin"
+	minioSecretKey = "minioadmin"
+)
+
+// setupMinIO starts a MinIO server as a local stand-in for an S3-compatible service and returns its endpoint
+func setupMinIO(pool dockertest.ClosablePool, logger *zap.Logger) (string, func(), error) {
+	ctx := context.Background()
+	resource, err := pool.Run(ctx, "minio/minio",
+		dockertest.WithTag("latest"),
+		dockertest.WithCmd([]string{"server", "/data"}),
+		dockertest.WithEnv([]string{
+			"MINIO_ROOT_USER=" + minioAccessKey,
+			"MINIO_ROOT_PASSWORD=" + minioSecretKey,
+		}),
+		dockertest.WithHostConfig(func(config *container.HostConfig) {
+			config.AutoRemove = true
+			config.RestartPolicy = container.RestartPolicy{Name: "no"}
+		}),
+	)
+	if err != nil {
+		logger.Error("Could not start MinIO", zap.Error(err))
+		return "", nil, err
+	}
+
+	endpoint := fmt.Sprintf("http://localhost:%s", resource.GetPort("9000/tcp"))
+	logger.Info("Launching MinIO", zap.String("endpoint", endpoint))
+
+	cleanup := func() {
+		err := resource.Close(ctx)
+		if err != nil {
+			logger.Error("Failed to close MinIO resource", zap.Error(err))
+		}
+	}
+
+	err = pool.Retry(ctx, 60*time.Second, func() error {
+		resp, err := http.Get(endpoint + "/minio/health/ready")
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("minio not ready: %s", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		logger.Error("Could not connect to MinIO", zap.Error(err))
+		return "", nil, err
+	}
+
+	return endpoint, cleanup, nil
+}
+
+// newMinIOStore returns a store on a new bucket of the MinIO server at endpoint
+func newMinIOStore(endpoint, bucket string) (*blobstore.S3, error) {
+	store, err := blobstore.NewS3(blobstore.S3Config{
+		Endpoint:  endpoint,
+		Bucket:    bucket,
+		AccessKey: minioAccessKey,
+		SecretKey: minioSecretKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.CreateBucket(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
@@ -3,9 +3,14 @@ package setup
 import (
 	"context"
 	"errors"
+	"os"
 	"sync"
 	"testing"
 
+	"NYCU-SDC/core-system-backend/internal/file"
+	"NYCU-SDC/core-system-backend/internal/file/blobstore"
+
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ory/dockertest/v4"
@@ -17,8 +22,10 @@ type ResourceManager struct {
 	mu     sync.Mutex
 	logger *zap.Logger
 
-	pool     dockertest.ClosablePool
-	postgres *pgxpool.Pool
+	pool          dockertest.ClosablePool
+	postgres      *pgxpool.Pool
+	blobStore     file.BlobStore
+	minioEndpoint string
 
 	cleanups []func()
 }
@@ -33,20 +40,12 @@ type ResourceManager struct {
 //	tx, rollback, err := rm.SetupPostgres()
 //	defer rollback()
 func (r *ResourceManager) SetupPostgres() (pgx.Tx, func(), error) {
-	if r.postgres == nil {
-		r.mu.Lock()
-		defer r.mu.Unlock()
-
-		pool, _, cleanup, err := setupPostgresWithMigrations(r.pool, r.logger, "file://../../../internal/database/migrations")
-		if err != nil {
-			return nil, nil, err
-		}
-
-		r.postgres = pool
-		r.cleanups = append(r.cleanups, cleanup)
+	pool, err := r.SetupPostgresPool()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	tx, err := r.postgres.Begin(context.Background())
+	tx, err := pool.Begin(context.Background())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -61,6 +60,26 @@ func (r *ResourceManager) SetupPostgres() (pgx.Tx, func(), error) {
 	return tx, cleanup, nil
 }
 
+// SetupPostgresPool ensures that a PostgreSQL container is running and returns its connection pool,
+// for tests that need several connections at once. What is written through the pool is committed, so
+// the test must delete it.
+func (r *ResourceManager) SetupPostgresPool() (*pgxpool.Pool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.postgres == nil {
+		pool, _, cleanup, err := setupPostgresWithMigrations(r.pool, r.logger, "file://../../../internal/database/migrations")
+		if err != nil {
+			return nil, err
+		}
+
+		r.postgres = pool
+		r.cleanups = append(r.cleanups, cleanup)
+	}
+
+	return r.postgres, nil
+}
+
 // WithPostgresTx provides a convenient way to run a test within a PostgreSQL transaction.
 //
 // It automatically begins a new transaction from the shared pgx pool, passes it to the
@@ -87,6 +106,51 @@ func (r *ResourceManager) WithPostgresTx(t *testing.T, fn func(tx pgx.Tx)) {
 	fn(tx)
 }
 
+// SetupBlobStore returns a filesystem blob store in a temporary directory, shared by every test of the
+// process and removed by Cleanup. Storage keys are never reused, so tests do not see each other's blobs.
+func (r *ResourceManager) SetupBlobStore() (file.BlobStore, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.blobStore == nil {
+		dir, err := os.MkdirTemp("", "core-system-blobs-*")
+		if err != nil {
+			return nil, err
+		}
+
+		store, err := blobstore.NewFilesystem(dir)
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, err
+		}
+
+		r.blobStore = store
+		r.cleanups = append(r.cleanups, func() {
+			_ = os.RemoveAll(dir)
+		})
+	}
+
+	return r.blobStore, nil
+}
+
+// SetupMinIO ensures that a MinIO container is running and returns an S3 blob store on a new bucket
+func (r *ResourceManager) SetupMinIO() (*blobstore.S3, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.minioEndpoint == "" {
+		endpoint, cleanup, err := setupMinIO(r.pool, r.logger)
+		if err != nil {
+			return nil, err
+		}
+
+		r.minioEndpoint = endpoint
+		r.cleanups = append(r.cleanups, cleanup)
+	}
+
+	return newMinIOStore(r.minioEndpoint, uuid.New().String())
+}
+
 func (r *ResourceManager) Cleanup() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -100,6 +164,8 @@ func (r *ResourceManager) Cleanup() {
 		r.cleanups[i]()
 	}
 	r.cleanups = nil
+	r.blobStore = nil
+	r.minioEndpoint = ""
 
 	if r.pool != nil {
 		if err := r.pool.Close(context.Background()); err != nil {